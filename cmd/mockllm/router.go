@@ -532,9 +532,8 @@ func lastUserMessage(msgs []requestMsg) string {
 }
 
 // toolCallResponse picks a tool from the request's tools list.
-// Priority: bash (for file writes and reads) > first tool.
-// All file operations are expressed as bash commands since write_file,
-// read_file, and list_directory have been removed from the tool registry.
+// Priority: bash (for file writes and reads) > write_file > first tool.
+// Apprentice agents have the file tools but not bash.
 func toolCallResponse(tools []toolDef) chatResponse {
 	name := tools[0].Function.Name
 	arguments := `{"command":"ls -la"}`
@@ -548,6 +547,9 @@ func toolCallResponse(tools []toolDef) chatResponse {
 	if toolNames["bash"] {
 		name = "bash"
 		arguments = `{"command":"cat <<'MOCKEOF' > solution.py\n# Mock solution\nimport json\n\ndef analyze(data):\n    return {\"summary\": \"processed\", \"count\": len(data)}\n\nif __name__ == \"__main__\":\n    print(analyze([1,2,3]))\nMOCKEOF"}`
+	} else if toolNames["write_file"] {
+		name = "write_file"
+		arguments = `{"path":"solution.py","content":"# Mock solution\nimport json\n\ndef analyze(data):\n    return {\"summary\": \"processed\", \"count\": len(data)}\n"}`
 	}
 
 	return namedToolCallResponse(name, arguments)
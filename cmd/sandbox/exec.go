@@ -11,6 +11,12 @@ import (
 // execCommand runs a shell command in the given directory with timeout enforcement.
 // Returns stdout, stderr, exit code, and whether the command timed out.
 func execCommand(ctx context.Context, workDir, command string, timeout time.Duration, maxOutput int) ExecResponse {
+	return execCommandEnv(ctx, workDir, command, nil, timeout, maxOutput)
+}
+
+// execCommandEnv is execCommand with extra environment variables appended to
+// the minimal toolchain environment.
+func execCommandEnv(ctx context.Context, workDir, command string, env map[string]string, timeout time.Duration, maxOutput int) ExecResponse {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
@@ -26,6 +32,9 @@ func execCommand(ctx context.Context, workDir, command string, timeout time.Dura
 		"NODE_PATH=/usr/local/lib/node_modules",
 		"JAVA_HOME=/usr/lib/jvm/java-21-openjdk-amd64",
 	}
+	for name, value := range env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
 
 	// Set process group so we can kill the entire tree on timeout.
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
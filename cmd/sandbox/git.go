@@ -106,15 +106,16 @@ func (s *Server) handleGitLog(w http.ResponseWriter, r *http.Request) {
 
 	// Pretty format: hash|author|date|subject
 	cmd := fmt.Sprintf("git log --pretty=format:'%%H|%%an|%%ai|%%s' -n %d", limit)
-	res := execCommand(r.Context(), dir, cmd, 15*time.Second, s.maxOutputBytes)
+	res := execCommand(r.Context(), dir, cmd, 15*time.Second, s.maxOutputBytes+s.secretSlack(questID))
 	if res.ExitCode != 0 {
 		writeError(w, http.StatusInternalServerError,
-			fmt.Sprintf("git log: %s", res.Stderr))
+			fmt.Sprintf("git log: %s", s.maskSecrets(questID, res.Stderr)))
 		return
 	}
+	out, _ := s.maskCapped(questID, res.Stdout, s.maxOutputBytes)
 
 	var commits []GitLogEntry
-	for _, line := range strings.Split(strings.TrimSpace(res.Stdout), "\n") {
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
 		line = strings.Trim(line, "'")
 		if line == "" {
 			continue
@@ -150,14 +151,15 @@ func (s *Server) handleGitDiff(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res := execCommand(r.Context(), dir, "git diff HEAD", 15*time.Second, s.maxOutputBytes)
+	res := execCommand(r.Context(), dir, "git diff HEAD", 15*time.Second, s.maxOutputBytes+s.secretSlack(questID))
 	if res.ExitCode != 0 {
-		writeError(w, http.StatusInternalServerError, fmt.Sprintf("git diff: %s", res.Stderr))
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("git diff: %s", s.maskSecrets(questID, res.Stderr)))
 		return
 	}
+	diff, _ := s.maskCapped(questID, res.Stdout, s.maxOutputBytes)
 
-	s.logger.Info("git diff", "quest_id", questID, "diff_bytes", len(res.Stdout))
-	writeJSON(w, http.StatusOK, GitDiffResponse{Diff: res.Stdout})
+	s.logger.Info("git diff", "quest_id", questID, "diff_bytes", len(diff))
+	writeJSON(w, http.StatusOK, GitDiffResponse{Diff: diff})
 }
 
 // handleGitStatus returns the working-tree status in the quest workspace.
@@ -169,11 +171,11 @@ func (s *Server) handleGitStatus(w http.ResponseWriter, r *http.Request) {
 
 	res := execCommand(r.Context(), dir, "git status --short", 15*time.Second, s.maxOutputBytes)
 	if res.ExitCode != 0 {
-		writeError(w, http.StatusInternalServerError, fmt.Sprintf("git status: %s", res.Stderr))
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("git status: %s", s.maskSecrets(questID, res.Stderr)))
 		return
 	}
 
-	status := strings.TrimSpace(res.Stdout)
+	status := strings.TrimSpace(s.maskSecrets(questID, res.Stdout))
 	s.logger.Info("git status", "quest_id", questID, "clean", status == "")
 	writeJSON(w, http.StatusOK, GitStatusResponse{
 		Status: status,
@@ -200,18 +202,25 @@ func (s *Server) handleGitCommitAll(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "message is required")
 		return
 	}
+	if s.containsSecret(questID, req.Message) {
+		writeError(w, http.StatusUnprocessableEntity, "commit message contains a secret")
+		return
+	}
 
 	// Stage everything.
 	res := execCommand(r.Context(), dir, "git add -A", 15*time.Second, s.maxOutputBytes)
 	if res.ExitCode != 0 {
-		writeError(w, http.StatusInternalServerError, fmt.Sprintf("git add: %s", res.Stderr))
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("git add: %s", s.maskSecrets(questID, res.Stderr)))
+		return
+	}
+	if !s.refuseSecretCommit(w, r, questID, dir, "") {
 		return
 	}
 
 	// Check if there is anything staged.
 	statusRes := execCommand(r.Context(), dir, "git status --porcelain", 10*time.Second, s.maxOutputBytes)
 	if statusRes.ExitCode != 0 {
-		writeError(w, http.StatusInternalServerError, fmt.Sprintf("git status: %s", statusRes.Stderr))
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("git status: %s", s.maskSecrets(questID, statusRes.Stderr)))
 		return
 	}
 	if strings.TrimSpace(statusRes.Stdout) == "" {
@@ -228,7 +237,7 @@ func (s *Server) handleGitCommitAll(w http.ResponseWriter, r *http.Request) {
 	commitCmd := fmt.Sprintf("git commit -m %s", shellQuote(req.Message))
 	commitRes := execCommand(r.Context(), dir, commitCmd, 30*time.Second, s.maxOutputBytes)
 	if commitRes.ExitCode != 0 {
-		writeError(w, http.StatusInternalServerError, fmt.Sprintf("git commit: %s", commitRes.Stderr))
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("git commit: %s", s.maskSecrets(questID, commitRes.Stderr)))
 		return
 	}
 
@@ -271,6 +280,17 @@ func (s *Server) handleMergeToMain(w http.ResponseWriter, r *http.Request) {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
+	// Stage the worktree and refuse the merge if anything the quest branch
+	// would bring into main contains a secret, committed or not.
+	addRes := execCommand(r.Context(), dir, "git add -A", 15*time.Second, s.maxOutputBytes)
+	if addRes.ExitCode != 0 {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("git add: %s", s.maskSecrets(questID, addRes.Stderr)))
+		return
+	}
+	if !s.refuseSecretCommit(w, r, questID, dir, "main") {
+		return
+	}
+
 	// Best-effort auto-commit any uncommitted work in the worktree before merging.
 	autoCommitCmd := `git add -A && git diff --cached --quiet || git commit -m "auto-commit before merge"`
 	execCommand(r.Context(), dir, autoCommitCmd, 30*time.Second, s.maxOutputBytes) //nolint:errcheck // best-effort
@@ -279,7 +299,7 @@ func (s *Server) handleMergeToMain(w http.ResponseWriter, r *http.Request) {
 	checkoutRes := execCommand(r.Context(), repoPath, "git checkout main", 15*time.Second, s.maxOutputBytes)
 	if checkoutRes.ExitCode != 0 {
 		writeError(w, http.StatusInternalServerError,
-			fmt.Sprintf("git checkout main: %s", checkoutRes.Stderr))
+			fmt.Sprintf("git checkout main: %s", s.maskSecrets(questID, checkoutRes.Stderr)))
 		return
 	}
 
@@ -291,7 +311,7 @@ func (s *Server) handleMergeToMain(w http.ResponseWriter, r *http.Request) {
 		// Abort the failed merge to leave the repo clean.
 		execCommand(r.Context(), repoPath, "git merge --abort", 10*time.Second, s.maxOutputBytes) //nolint:errcheck // best-effort
 		writeJSON(w, http.StatusConflict, ErrorResponse{
-			Error: fmt.Sprintf("merge conflict: %s", mergeRes.Stderr),
+			Error: fmt.Sprintf("merge conflict: %s", s.maskSecrets(questID, mergeRes.Stderr)),
 		})
 		return
 	}
@@ -307,7 +327,7 @@ func (s *Server) handleMergeToMain(w http.ResponseWriter, r *http.Request) {
 	var filesChanged []string
 	for _, line := range strings.Split(strings.TrimSpace(diffTreeRes.Stdout), "\n") {
 		if line != "" {
-			filesChanged = append(filesChanged, line)
+			filesChanged = append(filesChanged, s.maskSecrets(questID, line))
 		}
 	}
 	if filesChanged == nil {
@@ -328,6 +348,25 @@ func (s *Server) handleMergeToMain(w http.ResponseWriter, r *http.Request) {
 // INTERNAL HELPERS
 // =============================================================================
 
+// refuseSecretCommit scans the staged changes against base for the quest's
+// secrets. When one is found it unstages everything, writes a 422 naming the
+// files and returns false.
+func (s *Server) refuseSecretCommit(w http.ResponseWriter, r *http.Request, questID, dir, base string) bool {
+	leaked, err := s.stagedSecretFiles(r.Context(), questID, dir, base)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if len(leaked) == 0 {
+		return true
+	}
+	execCommand(r.Context(), dir, "git reset -q", 10*time.Second, s.maxOutputBytes) //nolint:errcheck // best-effort
+	s.logger.Warn("refused to commit secrets", "quest_id", questID, "files", len(leaked))
+	writeError(w, http.StatusUnprocessableEntity,
+		fmt.Sprintf("staged changes contain a secret; remove it before committing: %s", strings.Join(leaked, ", ")))
+	return false
+}
+
 // resolveWorktree validates the questID path parameter, ensures the workspace
 // directory exists, and returns (questID, absDir, true) on success.
 // On failure it writes the error response and returns (_, _, false).
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// SECRET MASKING
+// =============================================================================
+// The executor resolves {{secret:name}} placeholders and sends the values in
+// the exec request's env, listing them in Redact. The sandbox masks them in
+// command output and remembers them per quest, so a secret a command wrote to
+// disk is also masked when the file is read back or served as an artifact.
+// Output is masked before it is cut to the size cap, so a secret crossing the
+// cap is never returned half-visible. Commits and merges are refused while the
+// staged changes contain a secret. Values live only in memory and are dropped
+// with the workspace.
+// =============================================================================
+
+// secretMask replaces secret values in output leaving the sandbox.
+const secretMask = "[redacted]"
+
+// rememberSecrets records secret values used by a quest's commands.
+func (s *Server) rememberSecrets(questID string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.questSecrets == nil {
+		s.questSecrets = make(map[string][]string)
+	}
+	known := slices.Clone(s.questSecrets[questID])
+	for _, v := range values {
+		if v != "" && !slices.Contains(known, v) {
+			known = append(known, v)
+		}
+	}
+	// Longest first so a value containing another is masked whole.
+	sort.Slice(known, func(i, j int) bool { return len(known[i]) > len(known[j]) })
+	s.questSecrets[questID] = known
+}
+
+// secretValues returns the quest's remembered secret values, longest first.
+func (s *Server) secretValues(questID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.questSecrets[questID]
+}
+
+// maskSecrets replaces every remembered secret value of the quest in text.
+func (s *Server) maskSecrets(questID, text string) string {
+	for _, v := range s.secretValues(questID) {
+		text = strings.ReplaceAll(text, v, secretMask)
+	}
+	return text
+}
+
+// secretSlack is how many bytes past an output cap must be captured so that
+// a secret starting before the cap is read whole and can be masked.
+func (s *Server) secretSlack(questID string) int {
+	if values := s.secretValues(questID); len(values) > 0 {
+		return len(values[0])
+	}
+	return 0
+}
+
+// maskCapped masks the quest's secrets in text, then cuts it to maxLen bytes.
+// text should be captured with secretSlack extra bytes. Reports whether it
+// was cut.
+func (s *Server) maskCapped(questID, text string, maxLen int) (string, bool) {
+	text = s.maskSecrets(questID, text)
+	if len(text) <= maxLen {
+		return text, false
+	}
+	return text[:maxLen], true
+}
+
+// stagedSecretFiles returns the files staged in dir, compared with base ("" for
+// HEAD), whose content contains one of the quest's secrets. Staged content is
+// read from the work tree, which matches the index right after "git add -A".
+func (s *Server) stagedSecretFiles(ctx context.Context, questID, dir, base string) ([]string, error) {
+	values := s.secretValues(questID)
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	cmd := "git diff --cached --name-only -z"
+	if base != "" {
+		cmd += " " + shellQuote(base)
+	}
+	res := execCommand(ctx, dir, cmd, 15*time.Second, 4<<20)
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("git diff: %s", s.maskSecrets(questID, res.Stderr))
+	}
+
+	var leaked []string
+	for _, name := range strings.Split(res.Stdout, "\x00") {
+		if name == "" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		// Deleted files stage no content; symlinks stage only their target.
+		if info, err := os.Lstat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read staged file %s: %w", name, err)
+		}
+		for _, v := range values {
+			if bytes.Contains(data, []byte(v)) {
+				leaked = append(leaked, s.maskSecrets(questID, name))
+				break
+			}
+		}
+	}
+	return leaked, nil
+}
+
+// containsSecret reports whether text contains one of the quest's secrets.
+func (s *Server) containsSecret(questID, text string) bool {
+	for _, v := range s.secretValues(questID) {
+		if strings.Contains(text, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -12,6 +12,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/c360studio/semdragons/internal/realpath"
 )
 
 // repoMutex holds a per-repo mutex used to serialise worktree and merge
@@ -38,6 +40,10 @@ type Server struct {
 	// repoMutexes holds a per-repo mutex to serialise git worktree and merge
 	// operations. Protected by mu (acquired briefly only to read/insert).
 	repoMutexes map[string]*repoMutex
+
+	// questSecrets maps quest ID → secret values its commands received, so
+	// output that leaves the sandbox can be masked. Protected by mu.
+	questSecrets map[string][]string
 }
 
 // =============================================================================
@@ -59,15 +65,18 @@ type FileReadRequest struct {
 
 // FileResponse is returned from GET /file.
 type FileResponse struct {
-	Content string `json:"content"`
-	Size    int    `json:"size"`
+	Content   string `json:"content"`
+	Size      int    `json:"size"`
+	Truncated bool   `json:"truncated,omitempty"` // Content was capped at the output limit
 }
 
 // ExecRequest is the body for POST /exec.
 type ExecRequest struct {
-	QuestID   string `json:"quest_id"`
-	Command   string `json:"command"`
-	TimeoutMs int    `json:"timeout_ms,omitempty"` // 0 = use default
+	QuestID   string            `json:"quest_id"`
+	Command   string            `json:"command"`
+	TimeoutMs int               `json:"timeout_ms,omitempty"` // 0 = use default
+	Env       map[string]string `json:"env,omitempty"`        // Extra environment variables
+	Redact    []string          `json:"redact,omitempty"`     // Secret values to mask in output
 }
 
 // ExecResponse is returned from POST /exec.
@@ -130,6 +139,8 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /workspace/{questID}", s.handleCreateWorkspace)
 	mux.HandleFunc("DELETE /workspace/{questID}", s.handleDeleteWorkspace)
 	mux.HandleFunc("GET /workspace/{questID}", s.handleListWorkspaceFiles)
+	mux.HandleFunc("POST /workspace/{questID}/test", s.handleRunTests)
+	mux.HandleFunc("GET /workspace/{questID}/test-report", s.handleGetTestReport)
 
 	// Git / repo endpoints.
 	mux.HandleFunc("GET /repos", s.handleListRepos)
@@ -209,13 +220,18 @@ func (s *Server) handleReadFile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Cap read size to prevent huge responses.
-	if len(data) > s.maxOutputBytes {
-		data = data[:s.maxOutputBytes]
+	limit := s.maxOutputBytes + s.secretSlack(questID)
+	over := len(data) > limit
+	if over {
+		data = data[:limit]
 	}
+	content, truncated := s.maskCapped(questID, string(data), s.maxOutputBytes)
+	truncated = truncated || over
 
 	writeJSON(w, http.StatusOK, FileResponse{
-		Content: string(data),
-		Size:    len(data),
+		Content:   content,
+		Size:      len(content),
+		Truncated: truncated,
 	})
 }
 
@@ -245,7 +261,10 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 		timeout = requested
 	}
 
-	result := execCommand(r.Context(), workDir, req.Command, timeout, s.maxOutputBytes)
+	s.rememberSecrets(req.QuestID, req.Redact)
+	result := execCommandEnv(r.Context(), workDir, req.Command, req.Env, timeout, s.maxOutputBytes+s.secretSlack(req.QuestID))
+	result.Stdout, _ = s.maskCapped(req.QuestID, result.Stdout, s.maxOutputBytes)
+	result.Stderr, _ = s.maskCapped(req.QuestID, result.Stderr, s.maxOutputBytes)
 
 	s.logger.Info("command executed",
 		"quest_id", req.QuestID,
@@ -297,7 +316,7 @@ func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
 			size = info.Size()
 		}
 		result = append(result, ListEntry{
-			Name:  e.Name(),
+			Name:  s.maskSecrets(req.QuestID, e.Name()),
 			IsDir: e.IsDir(),
 			Size:  size,
 		})
@@ -328,21 +347,32 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Build grep command. Use grep -rn for recursive search with line numbers.
+	// Grep from the quest root with a relative path so matches are reported
+	// as workspace-relative paths, the same form the file endpoints accept.
+	questRoot := filepath.Join(s.workspace, req.QuestID)
+	relPath, err := filepath.Rel(questRoot, absPath)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	// Build grep command. Use grep -rnE for recursive extended-regex search
+	// with line numbers.
 	// Shell-quote user-supplied pattern and glob to prevent injection.
-	args := []string{"grep", "-rn"}
+	args := []string{"grep", "-rnE", "--exclude-dir=.git"}
 	if req.ContextLines > 0 {
 		args = append(args, fmt.Sprintf("-C%d", req.ContextLines))
 	}
 	if req.FileGlob != "" {
 		args = append(args, "--include="+shellQuote(req.FileGlob))
 	}
-	args = append(args, "--", shellQuote(req.Pattern), shellQuote(absPath))
+	args = append(args, "--", shellQuote(req.Pattern), shellQuote(relPath))
 
 	cmd := strings.Join(args, " ")
-	result := execCommand(r.Context(), filepath.Join(s.workspace, req.QuestID), cmd, 10*time.Second, s.maxOutputBytes)
+	result := execCommand(r.Context(), questRoot, cmd, 10*time.Second, s.maxOutputBytes+s.secretSlack(req.QuestID))
+	output, _ := s.maskCapped(req.QuestID, result.Stdout, s.maxOutputBytes)
 
-	writeJSON(w, http.StatusOK, SearchResponse{Output: result.Stdout})
+	writeJSON(w, http.StatusOK, SearchResponse{Output: output})
 }
 
 func (s *Server) handleCreateWorkspace(w http.ResponseWriter, r *http.Request) {
@@ -490,8 +520,11 @@ func (s *Server) removeWorkspace(ctx context.Context, questID string) error {
 	if hasRepo {
 		delete(s.questRepos, questID)
 	}
+	delete(s.questSecrets, questID)
 	s.mu.Unlock()
 
+	os.Remove(s.testReportPath(questID)) //nolint:errcheck // best-effort
+
 	dir := filepath.Join(s.workspace, questID)
 	branch := questBranch(questID)
 
@@ -561,7 +594,7 @@ func (s *Server) handleListWorkspaceFiles(w http.ResponseWriter, r *http.Request
 			size = info.Size()
 		}
 		files = append(files, ListEntry{
-			Name:  rel,
+			Name:  s.maskSecrets(questID, rel),
 			IsDir: d.IsDir(),
 			Size:  size,
 		})
@@ -601,23 +634,22 @@ func (s *Server) resolveQuestPath(questID, relPath string) (string, error) {
 
 	// Resolve symlinks to prevent symlink-based workspace escape.
 	// An agent could create a symlink pointing outside the workspace and then
-	// read/write through it via the file API. EvalSymlinks resolves the real
-	// target and the prefix check below catches escapes.
+	// read/write through it via the file API. realpath.Of resolves the real
+	// location — for a path that does not exist yet, where a write would
+	// create it — and the prefix check below catches escapes.
 	// Skip for the quest root itself (path == ".") — no symlink risk.
 	if absPath != questRoot {
-		realPath, err := filepath.EvalSymlinks(absPath)
-		if err == nil {
-			// File/symlink exists — verify the real target is within the workspace.
-			realRoot, rootErr := filepath.EvalSymlinks(questRoot)
-			if rootErr != nil {
-				realRoot = questRoot
-			}
-			if !strings.HasPrefix(realPath, realRoot+string(filepath.Separator)) && realPath != realRoot {
-				return "", fmt.Errorf("symlink escapes quest workspace")
-			}
+		realPath, err := realpath.Of(absPath)
+		if err != nil {
+			return "", fmt.Errorf("resolve path: %w", err)
+		}
+		realRoot, err := realpath.Of(questRoot)
+		if err != nil {
+			return "", fmt.Errorf("resolve quest workspace: %w", err)
+		}
+		if !strings.HasPrefix(realPath, realRoot+string(filepath.Separator)) && realPath != realRoot {
+			return "", fmt.Errorf("symlink escapes quest workspace")
 		}
-		// If EvalSymlinks fails, the file doesn't exist yet (write path).
-		// The original prefix check above already validated the string path.
 	}
 
 	return absPath, nil
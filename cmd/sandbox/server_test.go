@@ -9,8 +9,11 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/c360studio/semdragons/processor/executor/testreport"
 )
 
 func newTestServer(t *testing.T) (*Server, string) {
@@ -240,6 +243,89 @@ func TestExec_SimpleCommand(t *testing.T) {
 	}
 }
 
+func TestExec_EnvAndSecretMasking(t *testing.T) {
+	_, mux, workspace := setupHTTP(t)
+	os.MkdirAll(filepath.Join(workspace, "q1"), 0o755)
+
+	w := doRequest(t, mux, "POST", "/exec", ExecRequest{
+		QuestID: "q1",
+		Command: `echo "user=$REG_USER token=$REG_TOKEN"; echo "$REG_TOKEN" > .npmrc`,
+		Env:     map[string]string{"REG_USER": "bot", "REG_TOKEN": "s3cr3t-value"},
+		Redact:  []string{"s3cr3t-value"},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	resp := decodeResponse[ExecResponse](t, w)
+	if resp.Stdout != "user=bot token=[redacted]\n" {
+		t.Errorf("stdout = %q; want env applied and secret masked", resp.Stdout)
+	}
+
+	// The value reached disk, but reads and searches mask it.
+	data, _ := os.ReadFile(filepath.Join(workspace, "q1", ".npmrc"))
+	if string(data) != "s3cr3t-value\n" {
+		t.Fatalf("command did not see the secret: %q", data)
+	}
+	w = doRequest(t, mux, "GET", "/file?quest_id=q1&path=.npmrc", nil)
+	if file := decodeResponse[FileResponse](t, w); file.Content != "[redacted]\n" {
+		t.Errorf("file content = %q; want masked", file.Content)
+	}
+	w = doRequest(t, mux, "POST", "/search", SearchRequest{QuestID: "q1", Pattern: "s3cr3t"})
+	if out := decodeResponse[SearchResponse](t, w).Output; strings.Contains(out, "s3cr3t-value") {
+		t.Errorf("search output leaked the secret: %q", out)
+	}
+}
+
+func TestSecretMasking_AtOutputCap(t *testing.T) {
+	srv, mux, workspace := setupHTTP(t)
+	srv.maxOutputBytes = 8
+	os.MkdirAll(filepath.Join(workspace, "q1"), 0o755)
+	srv.rememberSecrets("q1", []string{"s3cr3t-value"})
+	os.WriteFile(filepath.Join(workspace, "q1", "f.txt"), []byte("abcds3cr3t-value tail"), 0o644)
+
+	// The secret crosses the 8-byte cap: no prefix of it may survive the cut.
+	w := doRequest(t, mux, "GET", "/file?quest_id=q1&path=f.txt", nil)
+	if file := decodeResponse[FileResponse](t, w); file.Content != "abcd[red" || !file.Truncated {
+		t.Errorf("file = %q truncated=%v; want masked before the cut", file.Content, file.Truncated)
+	}
+	w = doRequest(t, mux, "POST", "/exec", ExecRequest{QuestID: "q1", Command: "cat f.txt"})
+	if resp := decodeResponse[ExecResponse](t, w); resp.Stdout != "abcd[red" {
+		t.Errorf("stdout = %q; want masked before the cut", resp.Stdout)
+	}
+}
+
+func TestGitCommitAll_RefusesSecrets(t *testing.T) {
+	srv, mux, workspace := setupHTTP(t)
+	dir := filepath.Join(workspace, "q1")
+	os.MkdirAll(dir, 0o755)
+	if res := execCommand(t.Context(), dir, "git init -q && git config user.email t@example.com && git config user.name t",
+		10*time.Second, 1024); res.ExitCode != 0 {
+		t.Skipf("git unavailable: %s", res.Stderr)
+	}
+	srv.rememberSecrets("q1", []string{"s3cr3t-value"})
+	os.WriteFile(filepath.Join(dir, "ok.txt"), []byte("fine\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, ".npmrc"), []byte("token=s3cr3t-value\n"), 0o644)
+
+	w := doRequest(t, mux, "POST", "/workspace/q1/git/commit-all", CommitAllRequest{Message: "add config"})
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), ".npmrc") {
+		t.Fatalf("expected 422 naming .npmrc, got %d: %s", w.Code, w.Body.String())
+	}
+	if res := execCommand(t.Context(), dir, "git diff --cached --name-only", 10*time.Second, 1024); res.Stdout != "" {
+		t.Errorf("refused commit left files staged: %q", res.Stdout)
+	}
+
+	w = doRequest(t, mux, "POST", "/workspace/q1/git/commit-all", CommitAllRequest{Message: "token s3cr3t-value"})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("secret in message: expected 422, got %d", w.Code)
+	}
+
+	os.Remove(filepath.Join(dir, ".npmrc"))
+	w = doRequest(t, mux, "POST", "/workspace/q1/git/commit-all", CommitAllRequest{Message: "add ok"})
+	if resp := decodeResponse[CommitAllResponse](t, w); resp.CommitHash == "" || resp.FilesChanged != 1 {
+		t.Errorf("clean commit = %+v (status %d)", resp, w.Code)
+	}
+}
+
 func TestExec_NonZeroExit(t *testing.T) {
 	_, mux, workspace := setupHTTP(t)
 	os.MkdirAll(filepath.Join(workspace, "q1"), 0o755)
@@ -340,6 +426,98 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestSearch_RelativePaths(t *testing.T) {
+	_, mux, workspace := setupHTTP(t)
+	questDir := filepath.Join(workspace, "q1")
+	os.MkdirAll(filepath.Join(questDir, "pkg"), 0o755)
+	os.WriteFile(filepath.Join(questDir, "pkg", "a.go"), []byte("package pkg\nfunc Needle() {}\n"), 0o644)
+
+	w := doRequest(t, mux, "POST", "/search", SearchRequest{QuestID: "q1", Pattern: "Needle"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	resp := decodeResponse[SearchResponse](t, w)
+	if resp.Output != "./pkg/a.go:2:func Needle() {}\n" {
+		t.Errorf("unexpected output: %q", resp.Output)
+	}
+}
+
+func TestReadFile_Truncated(t *testing.T) {
+	srv, mux, workspace := setupHTTP(t)
+	srv.maxOutputBytes = 4
+	os.MkdirAll(filepath.Join(workspace, "q1"), 0o755)
+	os.WriteFile(filepath.Join(workspace, "q1", "big.txt"), []byte("0123456789"), 0o644)
+
+	w := doRequest(t, mux, "GET", "/file?quest_id=q1&path=big.txt", nil)
+	resp := decodeResponse[FileResponse](t, w)
+	if resp.Content != "0123" || !resp.Truncated {
+		t.Errorf("got content %q truncated=%v, want \"0123\" truncated", resp.Content, resp.Truncated)
+	}
+}
+
+// =============================================================================
+// TEST RUNS
+// =============================================================================
+
+func TestRunTests_ReportSavedAndDeleted(t *testing.T) {
+	_, mux, workspace := setupHTTP(t)
+	os.MkdirAll(filepath.Join(workspace, "q1"), 0o755)
+	os.WriteFile(filepath.Join(workspace, "q1", "Cargo.toml"), []byte("[package]\n"), 0o644)
+
+	// Whatever the runner does here (cargo is usually absent in tests), the
+	// endpoint must return a parsed report rather than an error.
+	w := doRequest(t, mux, "POST", "/workspace/q1/test", TestRequest{TimeoutMs: 5000})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	report := decodeResponse[testreport.Report](t, w)
+	if report.Framework != "cargo" || report.Command == "" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	w = doRequest(t, mux, "GET", "/workspace/q1/test-report", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected saved report, got %d: %s", w.Code, w.Body.String())
+	}
+
+	doRequest(t, mux, "DELETE", "/workspace/q1", nil)
+	w = doRequest(t, mux, "GET", "/workspace/q1/test-report", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after workspace delete, got %d", w.Code)
+	}
+}
+
+func TestRunTests_Errors(t *testing.T) {
+	_, mux, workspace := setupHTTP(t)
+	os.MkdirAll(filepath.Join(workspace, "q1"), 0o755)
+	os.WriteFile(filepath.Join(workspace, "q1", "README.md"), []byte("hi"), 0o644)
+
+	tests := []struct {
+		name string
+		path string
+		req  TestRequest
+		want int
+	}{
+		{"missing workspace", "/workspace/nope/test", TestRequest{}, http.StatusNotFound},
+		{"undetectable", "/workspace/q1/test", TestRequest{}, http.StatusUnprocessableEntity},
+		{"unknown framework", "/workspace/q1/test", TestRequest{Framework: "maven"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(t, mux, "POST", tt.path, tt.req)
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	w := doRequest(t, mux, "GET", "/workspace/q1/test-report", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 before any run, got %d", w.Code)
+	}
+}
+
 // =============================================================================
 // WORKSPACE FILE LISTING (recursive)
 // =============================================================================
@@ -453,4 +631,34 @@ func TestResolveQuestPath(t *testing.T) {
 			t.Error("expected symlink escape to be rejected, got nil error")
 		}
 	})
+
+	// Paths that do not exist yet are checked through their nearest existing
+	// ancestor, and dangling symlinks through their target.
+	t.Run("symlink escape blocked for new paths", func(t *testing.T) {
+		questDir := filepath.Join(workspace, "q2")
+		if err := os.MkdirAll(questDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		outside := t.TempDir()
+		if err := os.Symlink(outside, filepath.Join(questDir, "out")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join(outside, "missing", "file.go"), filepath.Join(questDir, "dangling")); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, path := range []string{"out/new.go", "out/newdir/new.go", "dangling"} {
+			if _, err := srv.resolveQuestPath("q2", path); err == nil {
+				t.Errorf("resolveQuestPath(%q): expected symlink escape to be rejected", path)
+			}
+		}
+		for _, path := range []string{"new.go", "newdir/new.go"} {
+			if _, err := srv.resolveQuestPath("q2", path); err != nil {
+				t.Errorf("resolveQuestPath(%q): unexpected error: %v", path, err)
+			}
+		}
+		if _, err := srv.resolveQuestPath("q-not-created", "src/main.go"); err != nil {
+			t.Errorf("new quest workspace: unexpected error: %v", err)
+		}
+	})
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/c360studio/semdragons/processor/executor/testreport"
+)
+
+// maxTestOutputBytes caps runner output for test runs. Reports are parsed
+// server-side, so this is larger than the exec output cap: a truncated
+// JUnit or jest report cannot be parsed at all.
+const maxTestOutputBytes = 8 << 20
+
+// testReportsDir holds the latest report per quest, outside the quest
+// workspace so it is never committed to the quest branch.
+const testReportsDir = ".test-reports"
+
+// TestRequest is the body for POST /workspace/{questID}/test.
+type TestRequest struct {
+	Framework string `json:"framework,omitempty"` // "" or "auto" = detect from workspace files
+	Target    string `json:"target,omitempty"`
+	Run       string `json:"run,omitempty"`
+	TimeoutMs int    `json:"timeout_ms,omitempty"` // 0 = use max timeout
+}
+
+// handleRunTests runs the quest's test suite and returns a structured report.
+// The report is also kept as the quest's latest test report.
+func (s *Server) handleRunTests(w http.ResponseWriter, r *http.Request) {
+	questID := r.PathValue("questID")
+	if !isValidQuestID(questID) {
+		writeError(w, http.StatusBadRequest, "invalid quest ID")
+		return
+	}
+	var req TestRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	workDir := filepath.Join(s.workspace, questID)
+	entries, err := os.ReadDir(workDir)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("workspace %q does not exist", questID))
+		return
+	}
+
+	fw, err := testreport.ParseFramework(req.Framework)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if fw == "" {
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.Name()
+		}
+		if fw = testreport.Detect(names); fw == "" {
+			writeError(w, http.StatusUnprocessableEntity, "could not detect a test framework; pass framework explicitly")
+			return
+		}
+	}
+
+	command, err := testreport.Command(fw, testreport.Options{Target: req.Target, Run: req.Run})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Test suites routinely outlast the default exec timeout.
+	timeout := s.maxTimeout
+	if req.TimeoutMs > 0 {
+		timeout = min(time.Duration(req.TimeoutMs)*time.Millisecond, s.maxTimeout)
+	}
+
+	result := execCommand(r.Context(), workDir, command, timeout, maxTestOutputBytes+s.secretSlack(questID))
+	stdout, _ := s.maskCapped(questID, result.Stdout, maxTestOutputBytes)
+	stderr, _ := s.maskCapped(questID, result.Stderr, maxTestOutputBytes)
+	report := testreport.Parse(fw, stdout+stderr, result.ExitCode)
+	report.Command = command
+	report.TimedOut = result.TimedOut
+	report.RanAt = time.Now().UTC()
+	report.Relativize(workDir)
+
+	if err := s.saveTestReport(questID, report); err != nil {
+		s.logger.Warn("save test report failed", "quest_id", questID, "error", err)
+	}
+
+	s.logger.Info("tests executed",
+		"quest_id", questID,
+		"framework", fw,
+		"passed", report.Passed,
+		"failed", report.Failed,
+		"skipped", report.Skipped,
+		"timed_out", report.TimedOut,
+	)
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleGetTestReport returns the quest's latest test report.
+func (s *Server) handleGetTestReport(w http.ResponseWriter, r *http.Request) {
+	questID := r.PathValue("questID")
+	if !isValidQuestID(questID) {
+		writeError(w, http.StatusBadRequest, "invalid quest ID")
+		return
+	}
+
+	data, err := os.ReadFile(s.testReportPath(questID))
+	if errors.Is(err, os.ErrNotExist) {
+		writeError(w, http.StatusNotFound, "no test report for quest")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("read test report: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data) //nolint:errcheck // best-effort response
+}
+
+func (s *Server) testReportPath(questID string) string {
+	return filepath.Join(s.workspace, testReportsDir, questID+".json")
+}
+
+func (s *Server) saveTestReport(questID string, report *testreport.Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	path := s.testReportPath(questID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
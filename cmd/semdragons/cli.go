@@ -92,9 +92,13 @@ func printHelp() {
 	_, _ = fmt.Fprintf(os.Stderr, `%s - Agentic Workflow Coordination Framework
 
 Usage: %s [options]
+       %s dag (--quest=ID | --input=snapshot.json) [--format=json|mermaid|dot]
+
+Commands:
+  dag    Render a party quest's execution DAG (see "%s dag --help")
 
 Options:
-`, appName, os.Args[0])
+`, appName, os.Args[0], os.Args[0], os.Args[0])
 	flag.PrintDefaults()
 	_, _ = fmt.Fprintf(os.Stderr, `
 Examples:
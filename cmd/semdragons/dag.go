@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	semdragons "github.com/c360studio/semdragons"
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/questdagexec"
+)
+
+// runDAG implements the "dag" subcommand: render a party quest's execution
+// DAG as JSON, Mermaid or DOT. With --quest it reads the live graph; with
+// --input it re-renders a JSON snapshot saved earlier, without NATS.
+func runDAG(args []string) error {
+	fs := flag.NewFlagSet("dag", flag.ContinueOnError)
+	configPath := fs.String("config", getEnv("SEMDRAGONS_CONFIG", "config/semdragons.json"),
+		"Path to configuration file (env: SEMDRAGONS_CONFIG)")
+	questID := fs.String("quest", "", "Parent quest ID (full entity ID or instance suffix)")
+	input := fs.String("input", "", "Re-render a saved JSON snapshot instead of reading the graph")
+	format := fs.String("format", questdagexec.DAGFormatMermaid, "Output format: json, mermaid, dot")
+	out := fs.String("out", "", "Write output to this file instead of stdout")
+	timeout := fs.Duration("timeout", 30*time.Second, "Graph read timeout")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, `Usage: %s dag (--quest=ID | --input=snapshot.json) [options]
+
+Render a party quest's execution DAG with node states, assignees, retries,
+durations, token spend and the critical path.
+
+Options:
+`, os.Args[0])
+		fs.PrintDefaults()
+		_, _ = fmt.Fprintf(os.Stderr, `
+Examples:
+  # Snapshot a live DAG as JSON
+  %s dag --quest=local.dev.game.board1.quest.abc --format=json --out=dag.json
+
+  # Render the snapshot offline as Graphviz
+  %s dag --input=dag.json --format=dot | dot -Tsvg > dag.svg
+`, os.Args[0], os.Args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if (*questID == "") == (*input == "") {
+		fs.Usage()
+		return errors.New("exactly one of --quest or --input is required")
+	}
+
+	var view *questdagexec.DAGView
+	var err error
+	if *input != "" {
+		view, err = readDAGSnapshot(*input)
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		view, err = loadLiveDAG(ctx, *configPath, *questID)
+	}
+	if err != nil {
+		return err
+	}
+
+	data, _, err := questdagexec.RenderDAGView(view, *format)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", *out, err)
+	}
+	return nil
+}
+
+// readDAGSnapshot loads a DAG view previously exported with --format=json.
+func readDAGSnapshot(path string) (*questdagexec.DAGView, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot %s: %w", path, err)
+	}
+	var view questdagexec.DAGView
+	if err := json.Unmarshal(data, &view); err != nil {
+		return nil, fmt.Errorf("parse snapshot %s: %w", path, err)
+	}
+	if len(view.Nodes) == 0 {
+		return nil, fmt.Errorf("snapshot %s: %w", path, questdagexec.ErrNoDAG)
+	}
+	return &view, nil
+}
+
+// loadLiveDAG connects to NATS and builds the DAG view from the graph.
+func loadLiveDAG(ctx context.Context, configPath, questID string) (*questdagexec.DAGView, error) {
+	cfg, _, err := loadConfig(configPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	boardCfg, err := extractBoardConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Connect quietly: stdout may be carrying the rendered diagram.
+	natsClient, err := createNATSClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create NATS client: %w", err)
+	}
+	if err := natsClient.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("connect to NATS: %w", err)
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		natsClient.Close(closeCtx)
+	}()
+	if err := natsClient.WaitForConnection(ctx); err != nil {
+		return nil, fmt.Errorf("NATS connection timeout: %w", err)
+	}
+
+	id := domain.QuestID(questID)
+	if domain.ExtractInstance(questID) == questID {
+		id = domain.QuestID(boardCfg.QuestEntityID(questID))
+	}
+	return questdagexec.LoadDAGView(ctx, semdragons.NewGraphClient(natsClient, boardCfg), id)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c360studio/semdragons/processor/questdagexec"
+)
+
+func TestRunDAG_RendersSnapshotOffline(t *testing.T) {
+	dir := t.TempDir()
+	snapshot := questdagexec.DAGView{
+		Title: "Ship it",
+		Nodes: []questdagexec.NodeView{
+			{ID: "a", Objective: "first", State: questdagexec.NodeCompleted, Critical: true},
+			{ID: "b", Objective: "second", State: questdagexec.NodePending, DependsOn: []string{"a"}, Critical: true},
+		},
+		Edges:        []questdagexec.DAGEdge{{From: "a", To: "b", Kind: questdagexec.EdgeDependsOn}},
+		CriticalPath: []string{"a", "b"},
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "dag.json")
+	if err := os.WriteFile(input, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "dag.dot")
+	if err := runDAG([]string{"--input=" + input, "--format=dot", "--out=" + out}); err != nil {
+		t.Fatalf("runDAG() error: %v", err)
+	}
+	rendered, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(rendered), `"a" -> "b"`) {
+		t.Errorf("expected edge in DOT output, got:\n%s", rendered)
+	}
+}
+
+func TestRunDAG_RequiresExactlyOneSource(t *testing.T) {
+	if err := runDAG(nil); err == nil {
+		t.Error("expected error without --quest or --input")
+	}
+	if err := runDAG([]string{"--quest=q1", "--input=dag.json"}); err == nil {
+		t.Error("expected error with both --quest and --input")
+	}
+}
+
+func TestReadDAGSnapshot_RejectsEmptyDAG(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "empty.json")
+	if err := os.WriteFile(input, []byte(`{"nodes":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readDAGSnapshot(input); err == nil {
+		t.Error("expected error for snapshot without nodes")
+	}
+}
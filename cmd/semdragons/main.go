@@ -68,6 +68,11 @@ func run() error {
 	// in production environments that inject credentials via the OS environment.
 	_ = godotenv.Load()
 
+	// Subcommands run standalone, without the banner or the full stack.
+	if len(os.Args) > 1 && os.Args[1] == "dag" {
+		return runDAG(os.Args[2:])
+	}
+
 	// 1. Print banner
 	printBanner()
 
@@ -30,6 +30,7 @@ const (
 	EntityTypeBattle     = "battle"
 	EntityTypeStoreItem  = "storeitem"
 	EntityTypePeerReview = "peerreview"
+	EntityTypeBlueprint  = "blueprint"
 )
 
 // BoardConfig holds the configuration for a quest board instance.
@@ -153,6 +154,11 @@ func (c *BoardConfig) PeerReviewEntityID(instance string) string {
 	return c.EntityID(EntityTypePeerReview, instance)
 }
 
+// BlueprintEntityID generates a DAG blueprint entity ID.
+func (c *BoardConfig) BlueprintEntityID(instance string) string {
+	return c.EntityID(EntityTypeBlueprint, instance)
+}
+
 // BucketName returns the KV bucket name for entity state.
 // Uses the standard ENTITY_STATES bucket shared with the semstreams graph pipeline
 // (graph-ingest, graph-index, graph-query, graph-gateway). Six-part entity IDs
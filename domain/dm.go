@@ -274,6 +274,7 @@ const (
 	ApprovalAutonomyGuildCreate ApprovalType = "autonomy_guild_create"
 	ApprovalAutonomyUse        ApprovalType = "autonomy_use"
 	ApprovalFailureTriage      ApprovalType = "failure_triage"
+	ApprovalToolCall           ApprovalType = "tool_call"
 )
 
 // ApprovalRequest represents a request for human approval.
@@ -339,15 +340,17 @@ const (
 
 // QuestHints provides optional guidance for quest creation.
 type QuestHints struct {
-	SuggestedDifficulty *QuestDifficulty `json:"suggested_difficulty,omitempty"`
-	SuggestedSkills     []SkillTag       `json:"suggested_skills,omitempty"`
-	PreferGuild         *GuildID         `json:"prefer_guild,omitempty"`
-	RequireHumanReview  bool             `json:"require_human_review"`
-	ReviewLevel         *ReviewLevel     `json:"review_level,omitempty"`
-	Budget              float64          `json:"budget"`
-	Deadline            string           `json:"deadline,omitempty"`
-	PartyRequired       bool             `json:"party_required"`
-	MinPartySize        *int             `json:"min_party_size,omitempty"`
+	SuggestedDifficulty *QuestDifficulty  `json:"suggested_difficulty,omitempty"`
+	SuggestedSkills     []SkillTag        `json:"suggested_skills,omitempty"`
+	PreferGuild         *GuildID          `json:"prefer_guild,omitempty"`
+	RequireHumanReview  bool              `json:"require_human_review"`
+	ReviewLevel         *ReviewLevel      `json:"review_level,omitempty"`
+	Budget              float64           `json:"budget"`
+	Deadline            string            `json:"deadline,omitempty"`
+	PartyRequired       bool              `json:"party_required"`
+	MinPartySize        *int              `json:"min_party_size,omitempty"`
+	Blueprint           string            `json:"blueprint,omitempty"`      // DAG blueprint the party lead should start from
+	BlueprintArgs       map[string]string `json:"blueprint_args,omitempty"` // Values for the blueprint's parameters
 }
 
 // =============================================================================
@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+// EFFECT HANDLERS — What a consumable actually does
+// =============================================================================
+// Consumables bought in the store become active effects on the agent entity.
+// Each effect type registers an EffectHandler declaring which hooks it uses;
+// the processors that own each hook point (questbridge for prompts, bossbattle
+// for judging, questboard for retries, agentstore for cooldowns,
+// agentprogression for XP) look the handler up by type. New store items can
+// reuse any registered effect type with their own magnitude and duration
+// without code changes.
+
+// Built-in effect types. The store's ConsumableType values mirror these.
+const (
+	EffectRetryToken    = "retry_token"
+	EffectCooldownSkip  = "cooldown_skip"
+	EffectXPBoost       = "xp_boost"
+	EffectQualityShield = "quality_shield"
+	EffectInsightScroll = "insight_scroll"
+)
+
+// EffectTrigger determines when an active effect counts down one use.
+type EffectTrigger string
+
+// Effect trigger values.
+const (
+	// EffectOnUse effects apply immediately when the consumable is used and
+	// never become active effects.
+	EffectOnUse EffectTrigger = "use"
+	// EffectOnQuestEnd effects count down whenever a quest reaches a terminal state.
+	EffectOnQuestEnd EffectTrigger = "quest_end"
+	// EffectOnCompletion effects count down only when a quest completes.
+	EffectOnCompletion EffectTrigger = "quest_completed"
+	// EffectOnFailure effects count down only when they absorb a failure.
+	EffectOnFailure EffectTrigger = "quest_failed"
+)
+
+// EffectContext is passed to effect hooks.
+type EffectContext struct {
+	Magnitude float64 // Item-defined strength (0 = handler default)
+	Quest     *Quest  // Quest the effect is applied to, when known
+}
+
+// EffectHandler declares the hooks an effect type participates in. Nil func
+// hooks and false flags mean the effect does not take part in that hook.
+type EffectHandler struct {
+	Type        string
+	Description string
+	ConsumedOn  EffectTrigger
+
+	// PromptInjection returns text added to the agent's system prompt.
+	PromptInjection func(ctx EffectContext) string
+
+	// AdjustJudgement may relax criterion thresholds or forgive failed
+	// results after the judges have scored a boss battle. It returns the
+	// adjusted results and must not mutate the input slice.
+	AdjustJudgement func(ctx EffectContext, criteria []ReviewCriterion, results []ReviewResult) []ReviewResult
+
+	// GrantsRetry converts a terminal quest failure into one more attempt
+	// with no failure penalty.
+	GrantsRetry bool
+
+	// SkipsCooldown ends an active cooldown when the consumable is used.
+	SkipsCooldown bool
+
+	// XPMultiplier scales XP awarded on quest completion.
+	XPMultiplier func(ctx EffectContext) float64
+}
+
+// effectRegistry holds registered handlers keyed by effect type.
+var effectRegistry = struct {
+	mu       sync.RWMutex
+	handlers map[string]*EffectHandler
+}{handlers: builtinEffectHandlers()}
+
+// RegisterEffect adds or replaces the handler for h.Type.
+func RegisterEffect(h *EffectHandler) {
+	if h == nil || h.Type == "" {
+		return
+	}
+	effectRegistry.mu.Lock()
+	defer effectRegistry.mu.Unlock()
+	effectRegistry.handlers[h.Type] = h
+}
+
+// LookupEffect returns the handler registered for effectType.
+func LookupEffect(effectType string) (*EffectHandler, bool) {
+	effectRegistry.mu.RLock()
+	defer effectRegistry.mu.RUnlock()
+	h, ok := effectRegistry.handlers[effectType]
+	return h, ok
+}
+
+// EffectTypes returns all registered effect types, sorted.
+func EffectTypes() []string {
+	effectRegistry.mu.RLock()
+	defer effectRegistry.mu.RUnlock()
+	types := make([]string, 0, len(effectRegistry.handlers))
+	for t := range effectRegistry.handlers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// builtinEffectHandlers returns the handlers for the default consumables.
+func builtinEffectHandlers() map[string]*EffectHandler {
+	handlers := []*EffectHandler{
+		{
+			Type:        EffectRetryToken,
+			Description: "Terminal failure is reposted for one more attempt with no XP penalty",
+			ConsumedOn:  EffectOnFailure,
+			GrantsRetry: true,
+		},
+		{
+			Type:          EffectCooldownSkip,
+			Description:   "Ends the agent's cooldown immediately",
+			ConsumedOn:    EffectOnUse,
+			SkipsCooldown: true,
+		},
+		{
+			Type:         EffectXPBoost,
+			Description:  "Multiplies XP on the next completed quest",
+			ConsumedOn:   EffectOnCompletion,
+			XPMultiplier: xpBoostMultiplier,
+		},
+		{
+			Type:            EffectQualityShield,
+			Description:     "Forgives failed review criteria during the boss battle",
+			ConsumedOn:      EffectOnQuestEnd,
+			AdjustJudgement: qualityShieldAdjust,
+		},
+		{
+			Type:            EffectInsightScroll,
+			Description:     "Injects difficulty and history hints for the quest into the prompt",
+			ConsumedOn:      EffectOnQuestEnd,
+			PromptInjection: insightScrollHints,
+		},
+	}
+	m := make(map[string]*EffectHandler, len(handlers))
+	for _, h := range handlers {
+		m[h.Type] = h
+	}
+	return m
+}
+
+// xpBoostMultiplier defaults to double XP.
+func xpBoostMultiplier(ctx EffectContext) float64 {
+	if ctx.Magnitude > 0 {
+		return ctx.Magnitude
+	}
+	return 2.0
+}
+
+// qualityShieldAdjust flips the closest-to-passing failed criteria to passed.
+// Magnitude is the number of criteria forgiven (default 1).
+func qualityShieldAdjust(ctx EffectContext, criteria []ReviewCriterion, results []ReviewResult) []ReviewResult {
+	forgive := max(int(ctx.Magnitude), 1)
+
+	thresholds := make(map[string]float64, len(criteria))
+	for _, c := range criteria {
+		thresholds[c.Name] = c.Threshold
+	}
+
+	adjusted := make([]ReviewResult, len(results))
+	copy(adjusted, results)
+
+	var failed []int
+	for i, r := range adjusted {
+		if !r.Passed {
+			failed = append(failed, i)
+		}
+	}
+	// Smallest shortfall first — the shield saves the nearest miss.
+	sort.SliceStable(failed, func(a, b int) bool {
+		ra, rb := adjusted[failed[a]], adjusted[failed[b]]
+		return thresholds[ra.CriterionName]-ra.Score < thresholds[rb.CriterionName]-rb.Score
+	})
+	for _, i := range failed[:min(forgive, len(failed))] {
+		adjusted[i].Passed = true
+		adjusted[i].Reasoning = strings.TrimSpace(adjusted[i].Reasoning + " [forgiven by quality shield]")
+	}
+	return adjusted
+}
+
+// difficultyNames labels QuestDifficulty values for prompt hints.
+var difficultyNames = map[QuestDifficulty]string{
+	DifficultyTrivial:   "Trivial",
+	DifficultyEasy:      "Easy",
+	DifficultyModerate:  "Moderate",
+	DifficultyHard:      "Hard",
+	DifficultyEpic:      "Epic",
+	DifficultyLegendary: "Legendary",
+}
+
+// insightScrollHints summarizes what is known about the quest's difficulty.
+func insightScrollHints(ctx EffectContext) string {
+	q := ctx.Quest
+	if q == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("INSIGHT SCROLL — Quest intel:\n")
+	fmt.Fprintf(&b, "- Difficulty: %s (%d/5), minimum tier %d\n", difficultyNames[q.Difficulty], int(q.Difficulty), int(q.MinTier))
+	if len(q.RequiredSkills) > 0 {
+		skills := make([]string, len(q.RequiredSkills))
+		for i, s := range q.RequiredSkills {
+			skills[i] = string(s)
+		}
+		fmt.Fprintf(&b, "- Skills tested: %s\n", strings.Join(skills, ", "))
+	}
+	if n := len(q.Acceptance) + len(q.Requirements); n > 0 {
+		fmt.Fprintf(&b, "- Acceptance checks: %d — satisfy every one explicitly\n", n)
+	}
+	if q.Attempts > 1 {
+		fmt.Fprintf(&b, "- Attempt %d of %d", q.Attempts, q.MaxAttempts)
+		if q.FailureReason != "" {
+			fmt.Fprintf(&b, "; last failure: %s", q.FailureReason)
+		}
+		b.WriteString("\n")
+	}
+	if q.Constraints.ReviewLevel >= ReviewStrict {
+		b.WriteString("- Review is strict: expect every criterion to be checked\n")
+	}
+	return b.String()
+}
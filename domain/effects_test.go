@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuiltinEffectsRegistered(t *testing.T) {
+	for _, typ := range []string{EffectRetryToken, EffectCooldownSkip, EffectXPBoost, EffectQualityShield, EffectInsightScroll} {
+		if _, ok := LookupEffect(typ); !ok {
+			t.Errorf("builtin effect %q not registered", typ)
+		}
+	}
+	if _, ok := LookupEffect("no_such_effect"); ok {
+		t.Error("unknown effect should not be found")
+	}
+}
+
+func TestRegisterEffect_Custom(t *testing.T) {
+	RegisterEffect(&EffectHandler{Type: "test_focus", ConsumedOn: EffectOnQuestEnd})
+	t.Cleanup(func() {
+		effectRegistry.mu.Lock()
+		delete(effectRegistry.handlers, "test_focus")
+		effectRegistry.mu.Unlock()
+	})
+
+	h, ok := LookupEffect("test_focus")
+	if !ok || h.ConsumedOn != EffectOnQuestEnd {
+		t.Fatalf("custom effect not registered: %+v", h)
+	}
+	found := false
+	for _, typ := range EffectTypes() {
+		found = found || typ == "test_focus"
+	}
+	if !found {
+		t.Error("EffectTypes() missing custom effect")
+	}
+}
+
+func TestXPBoostMultiplier(t *testing.T) {
+	h, _ := LookupEffect(EffectXPBoost)
+	if got := h.XPMultiplier(EffectContext{}); got != 2.0 {
+		t.Errorf("default multiplier = %v, want 2", got)
+	}
+	if got := h.XPMultiplier(EffectContext{Magnitude: 1.5}); got != 1.5 {
+		t.Errorf("multiplier = %v, want 1.5", got)
+	}
+}
+
+func TestQualityShieldForgivesNearestMiss(t *testing.T) {
+	h, _ := LookupEffect(EffectQualityShield)
+	criteria := []ReviewCriterion{
+		{Name: "correctness", Threshold: 0.7},
+		{Name: "style", Threshold: 0.6},
+	}
+	results := []ReviewResult{
+		{CriterionName: "correctness", Score: 0.3, Passed: false},
+		{CriterionName: "style", Score: 0.55, Passed: false},
+	}
+
+	adjusted := h.AdjustJudgement(EffectContext{}, criteria, results)
+
+	if results[1].Passed {
+		t.Fatal("input slice must not be mutated")
+	}
+	if adjusted[0].Passed {
+		t.Error("far miss should not be forgiven with magnitude 1")
+	}
+	if !adjusted[1].Passed || !strings.Contains(adjusted[1].Reasoning, "quality shield") {
+		t.Errorf("nearest miss not forgiven: %+v", adjusted[1])
+	}
+
+	all := h.AdjustJudgement(EffectContext{Magnitude: 5}, criteria, results)
+	for _, r := range all {
+		if !r.Passed {
+			t.Errorf("magnitude 5 should forgive all, %s still failed", r.CriterionName)
+		}
+	}
+}
+
+func TestInsightScrollHints(t *testing.T) {
+	h, _ := LookupEffect(EffectInsightScroll)
+	if got := h.PromptInjection(EffectContext{}); got != "" {
+		t.Errorf("no quest should yield no hints, got %q", got)
+	}
+
+	q := &Quest{
+		Difficulty:     DifficultyHard,
+		RequiredSkills: []SkillTag{SkillCodeGen},
+		Acceptance:     []string{"tests pass"},
+		Attempts:       2,
+		MaxAttempts:    3,
+		FailureReason:  "missing tests",
+	}
+	got := h.PromptInjection(EffectContext{Quest: q})
+	for _, want := range []string{"INSIGHT SCROLL", "Hard", string(SkillCodeGen), "Acceptance checks: 1", "Attempt 2 of 3", "missing tests"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("hints missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -110,10 +110,21 @@ type Quest struct {
 	DAGCompletedNodes any    `json:"dag_completed_nodes,omitempty"` // []string
 	DAGFailedNodes    any    `json:"dag_failed_nodes,omitempty"`    // []string
 	DAGNodeRetries    any    `json:"dag_node_retries,omitempty"`    // map[string]int
+	DAGPartyID        string `json:"dag_party_id,omitempty"`        // Sub-party running a nested DAG (empty = PartyID)
+	DAGAmendments     any    `json:"dag_amendments,omitempty"`      // []DAGAmendment history from amend_dag
+	DAGBlueprint      string `json:"dag_blueprint,omitempty"`       // Blueprint suggested by the DM, then the one the lead decomposed from
+	DAGBlueprintArgs  any    `json:"dag_blueprint_args,omitempty"`  // map[string]string blueprint parameter values
+	DAGSwaps          any    `json:"dag_swaps,omitempty"`           // []NodeSwap history of hot-swapped assignees
+	DAGDiagram        string `json:"dag_diagram,omitempty"`         // Mermaid rendering of the executed DAG (attach_dag_diagram)
 
 	// Sub-quest DAG fields:
 	DAGNodeID         string `json:"dag_node_id,omitempty"`
 	DAGClarifications any    `json:"dag_clarifications,omitempty"` // []ClarificationExchange
+	DAGHandoverFrom   string `json:"dag_handover_from,omitempty"`  // Agent whose partial work this assignee took over
+	DAGDecomposable   bool   `json:"dag_decomposable,omitempty"`   // Assignee may decompose into a nested DAG
+	DAGLevel          int    `json:"dag_level,omitempty"`          // Nesting depth: 0 top-level, 1 sub-quest, 2 nested sub-quest...
+	DAGOutputSchema   any    `json:"dag_output_schema,omitempty"`  // JSON Schema submit_work output must satisfy
+	DAGTypedOutput    any    `json:"dag_typed_output,omitempty"`   // Validated structured output from submit_work
 
 	// DM clarification exchanges (non-DAG quests or parent party quests).
 	// Stored as any to keep domain package free of processor-type imports.
@@ -124,6 +135,7 @@ type Quest struct {
 	ContextTokenCount int      `json:"context_token_count,omitempty"`
 	ContextSources    []string `json:"context_sources,omitempty"`  // Fragment IDs used in assembly
 	ContextEntities   []string `json:"context_entities,omitempty"` // Entity IDs referenced in context
+	InjectedLessons   []string `json:"injected_lessons,omitempty"` // Guild lesson IDs shown to the agent
 
 	// Artifact tracking — git-backed workspace integration.
 	ArtifactsMerged  string   `json:"artifacts_merged,omitempty"`   // Merge commit hash (after boss battle victory)
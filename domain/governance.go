@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// =============================================================================
+// GUILD GOVERNANCE — Proposals decided by rank-weighted member votes
+// =============================================================================
+// Members open proposals to accept an applicant, promote or demote a member,
+// or disband the guild. Votes are weighted by GuildRank. A proposal passes
+// once approving weight is a majority of the guild's total voting weight, or
+// at its deadline if quorum was reached and approvals outnumber rejections.
+
+// ProposalKind identifies what a guild proposal decides.
+type ProposalKind string
+
+// Proposal kinds.
+const (
+	ProposalAcceptApplicant ProposalKind = "accept_applicant"
+	ProposalPromote         ProposalKind = "promote"
+	ProposalDemote          ProposalKind = "demote"
+	ProposalDisband         ProposalKind = "disband"
+)
+
+// ProposalStatus is the lifecycle state of a guild proposal.
+type ProposalStatus string
+
+// Proposal statuses.
+const (
+	ProposalOpen     ProposalStatus = "open"
+	ProposalPassed   ProposalStatus = "passed"
+	ProposalRejected ProposalStatus = "rejected"
+	ProposalExpired  ProposalStatus = "expired" // Deadline passed without quorum
+)
+
+// MaxClosedProposals bounds the resolved proposals kept on the guild entity.
+const MaxClosedProposals = 50
+
+// GuildVote is one member's vote on a proposal. Weight is fixed at the
+// voter's rank when the vote is cast.
+type GuildVote struct {
+	VoterID AgentID   `json:"voter_id"`
+	Approve bool      `json:"approve"`
+	Weight  float64   `json:"weight"`
+	Reason  string    `json:"reason,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// GuildProposal is a decision put to a guild vote.
+type GuildProposal struct {
+	ID            string         `json:"id"`
+	GuildID       GuildID        `json:"guild_id"`
+	Kind          ProposalKind   `json:"kind"`
+	ProposerID    AgentID        `json:"proposer_id"`
+	TargetID      AgentID        `json:"target_id,omitempty"`      // Applicant or member affected
+	ApplicationID string         `json:"application_id,omitempty"` // For accept_applicant
+	NewRank       GuildRank      `json:"new_rank,omitempty"`       // For promote/demote
+	Reason        string         `json:"reason,omitempty"`
+	Status        ProposalStatus `json:"status"`
+	Votes         []GuildVote    `json:"votes"`
+	Quorum        float64        `json:"quorum"` // Fraction of total voting weight that must vote
+	CreatedAt     time.Time      `json:"created_at"`
+	Deadline      time.Time      `json:"deadline"`
+	ResolvedAt    *time.Time     `json:"resolved_at,omitempty"`
+	Outcome       string         `json:"outcome,omitempty"` // Result of executing the decision
+}
+
+// VoteWeight returns the weight of a vote cast at this rank.
+func (r GuildRank) VoteWeight() float64 {
+	switch r {
+	case GuildRankMember:
+		return 2
+	case GuildRankVeteran:
+		return 3
+	case GuildRankOfficer:
+		return 4
+	case GuildRankMaster:
+		return 5
+	default:
+		return 1
+	}
+}
+
+// Order returns the rank's position from initiate (0) to guildmaster (4),
+// or -1 for an unknown rank.
+func (r GuildRank) Order() int {
+	switch r {
+	case GuildRankInitiate:
+		return 0
+	case GuildRankMember:
+		return 1
+	case GuildRankVeteran:
+		return 2
+	case GuildRankOfficer:
+		return 3
+	case GuildRankMaster:
+		return 4
+	default:
+		return -1
+	}
+}
+
+// TotalVoteWeight returns the combined voting weight of all members.
+func (g *Guild) TotalVoteWeight() float64 {
+	var total float64
+	for _, m := range g.Members {
+		total += m.Rank.VoteWeight()
+	}
+	return total
+}
+
+// Proposal returns the proposal with the given ID, or nil.
+func (g *Guild) Proposal(id string) *GuildProposal {
+	for i := range g.Proposals {
+		if g.Proposals[i].ID == id {
+			return &g.Proposals[i]
+		}
+	}
+	return nil
+}
+
+// PruneProposals drops the oldest resolved proposals beyond MaxClosedProposals.
+// Open proposals are always kept.
+func (g *Guild) PruneProposals() {
+	closed := 0
+	for _, p := range g.Proposals {
+		if p.Status != ProposalOpen {
+			closed++
+		}
+	}
+	drop := closed - MaxClosedProposals
+	if drop <= 0 {
+		return
+	}
+	kept := g.Proposals[:0]
+	for _, p := range g.Proposals {
+		if p.Status != ProposalOpen && drop > 0 {
+			drop--
+			continue
+		}
+		kept = append(kept, p)
+	}
+	g.Proposals = kept
+}
+
+// VoteBy returns the vote cast by the agent, or nil. Matches on the entity
+// instance so short and full agent IDs are interchangeable.
+func (p *GuildProposal) VoteBy(agentID AgentID) *GuildVote {
+	instance := ExtractInstance(string(agentID))
+	for i := range p.Votes {
+		if ExtractInstance(string(p.Votes[i].VoterID)) == instance {
+			return &p.Votes[i]
+		}
+	}
+	return nil
+}
+
+// Tally returns the approving and rejecting vote weight.
+func (p *GuildProposal) Tally() (yes, no float64) {
+	for _, v := range p.Votes {
+		if v.Approve {
+			yes += v.Weight
+		} else {
+			no += v.Weight
+		}
+	}
+	return yes, no
+}
+
+// Decide returns the status the proposal should move to given the guild's
+// total voting weight, or ProposalOpen while the outcome is still undecided.
+func (p *GuildProposal) Decide(totalWeight float64, now time.Time) ProposalStatus {
+	yes, no := p.Tally()
+	quorumMet := yes+no >= p.Quorum*totalWeight
+
+	switch {
+	case quorumMet && yes > totalWeight/2:
+		return ProposalPassed
+	case no >= totalWeight/2:
+		// Approvals can no longer reach a majority.
+		return ProposalRejected
+	case yes+no >= totalWeight:
+		// Everyone has voted.
+		if yes > no {
+			return ProposalPassed
+		}
+		return ProposalRejected
+	case !now.Before(p.Deadline):
+		if !quorumMet {
+			return ProposalExpired
+		}
+		if yes > no {
+			return ProposalPassed
+		}
+		return ProposalRejected
+	default:
+		return ProposalOpen
+	}
+}
+
+// CopyProposals returns a deep copy of the proposals, including votes.
+func CopyProposals(proposals []GuildProposal) []GuildProposal {
+	if proposals == nil {
+		return nil
+	}
+	out := make([]GuildProposal, len(proposals))
+	for i, p := range proposals {
+		p.Votes = append([]GuildVote(nil), p.Votes...)
+		out[i] = p
+	}
+	return out
+}
+
+// AsGuildProposals converts a triple Object to []GuildProposal.
+// Handles both in-process typed slices and JSON-deserialized []any from KV.
+func AsGuildProposals(obj any) []GuildProposal {
+	if obj == nil {
+		return nil
+	}
+	if proposals, ok := obj.([]GuildProposal); ok {
+		return proposals
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	var proposals []GuildProposal
+	if err := json.Unmarshal(raw, &proposals); err != nil {
+		return nil
+	}
+	return proposals
+}
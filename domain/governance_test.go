@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/c360studio/semstreams/graph"
+)
+
+func TestGuildProposal_Decide(t *testing.T) {
+	now := time.Now()
+	deadline := now.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		votes []GuildVote
+		at    time.Time
+		want  ProposalStatus
+	}{
+		{"majority approves early", []GuildVote{{Approve: true, Weight: 5}, {Approve: true, Weight: 1}}, now, ProposalPassed},
+		{"half rejects early", []GuildVote{{Approve: false, Weight: 5}}, now, ProposalRejected},
+		{"undecided before deadline", []GuildVote{{Approve: true, Weight: 2}}, now, ProposalOpen},
+		{"quorum met at deadline", []GuildVote{{Approve: true, Weight: 3}, {Approve: false, Weight: 2}}, deadline, ProposalPassed},
+		{"tie at deadline", []GuildVote{{Approve: true, Weight: 2}, {Approve: false, Weight: 2}}, deadline, ProposalRejected},
+		{"no quorum at deadline", []GuildVote{{Approve: true, Weight: 2}}, deadline, ProposalExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &GuildProposal{Votes: tt.votes, Quorum: 0.4, Deadline: deadline}
+			if got := p.Decide(10, tt.at); got != tt.want {
+				t.Errorf("Decide = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuildRank_VoteWeightAndOrder(t *testing.T) {
+	ranks := []GuildRank{GuildRankInitiate, GuildRankMember, GuildRankVeteran, GuildRankOfficer, GuildRankMaster}
+	for i := 1; i < len(ranks); i++ {
+		if ranks[i].VoteWeight() <= ranks[i-1].VoteWeight() {
+			t.Errorf("%s should outweigh %s", ranks[i], ranks[i-1])
+		}
+		if ranks[i].Order() != i {
+			t.Errorf("%s.Order() = %d, want %d", ranks[i], ranks[i].Order(), i)
+		}
+	}
+	if GuildRank("unknown").Order() != -1 {
+		t.Error("unknown rank should have order -1")
+	}
+}
+
+func TestGuild_PruneProposalsKeepsOpen(t *testing.T) {
+	g := &Guild{}
+	g.Proposals = append(g.Proposals, GuildProposal{ID: "open-0", Status: ProposalOpen})
+	for i := range MaxClosedProposals + 3 {
+		g.Proposals = append(g.Proposals, GuildProposal{ID: string(rune('a' + i%26)), Status: ProposalPassed})
+	}
+	g.PruneProposals()
+	if len(g.Proposals) != MaxClosedProposals+1 {
+		t.Fatalf("len = %d, want %d", len(g.Proposals), MaxClosedProposals+1)
+	}
+	if g.Proposal("open-0") == nil {
+		t.Error("open proposal was pruned")
+	}
+}
+
+func TestGuildRoundTrip_WithProposals(t *testing.T) {
+	original := &Guild{
+		ID:     GuildID("c360.prod.game.board1.guild.g1"),
+		Name:   "Code Crafters",
+		Status: GuildActive,
+		Proposals: []GuildProposal{{
+			ID:         "p1",
+			Kind:       ProposalPromote,
+			ProposerID: "a1",
+			TargetID:   "a2",
+			NewRank:    GuildRankVeteran,
+			Status:     ProposalOpen,
+			Votes:      []GuildVote{{VoterID: "a1", Approve: true, Weight: 5}},
+			Quorum:     0.5,
+		}},
+	}
+
+	// Simulate the KV round-trip: blob objects come back as []any.
+	triples := original.Triples()
+	for i := range triples {
+		if triples[i].Predicate == PredicateGuildProposals {
+			raw, _ := json.Marshal(triples[i].Object)
+			var generic any
+			_ = json.Unmarshal(raw, &generic)
+			triples[i].Object = generic
+		}
+	}
+
+	restored := GuildFromEntityState(&graph.EntityState{ID: string(original.ID), Triples: triples})
+	if restored == nil || len(restored.Proposals) != 1 {
+		t.Fatalf("restored proposals = %+v", restored)
+	}
+	p := restored.Proposals[0]
+	if p.Kind != ProposalPromote || p.NewRank != GuildRankVeteran || len(p.Votes) != 1 || p.VoteBy("a1") == nil {
+		t.Errorf("proposal = %+v", p)
+	}
+}
@@ -331,6 +331,43 @@ func (q *Quest) Triples() []message.Triple {
 		})
 	}
 
+	if q.DAGPartyID != "" {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "quest.dag.party_id", Object: q.DAGPartyID,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+	if q.DAGAmendments != nil {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "quest.dag.amendments", Object: q.DAGAmendments,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+	if q.DAGBlueprint != "" {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "quest.dag.blueprint", Object: q.DAGBlueprint,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+	if q.DAGDiagram != "" {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "quest.dag.diagram", Object: q.DAGDiagram,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+	if q.DAGBlueprintArgs != nil {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "quest.dag.blueprint_args", Object: q.DAGBlueprintArgs,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+	if q.DAGSwaps != nil {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "quest.dag.swaps", Object: q.DAGSwaps,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+
 	// DAG sub-quest fields
 	if q.DAGNodeID != "" {
 		triples = append(triples, message.Triple{
@@ -344,6 +381,36 @@ func (q *Quest) Triples() []message.Triple {
 			Source: source, Timestamp: now, Confidence: 1.0,
 		})
 	}
+	if q.DAGHandoverFrom != "" {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "quest.dag.handover_from", Object: q.DAGHandoverFrom,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+	if q.DAGDecomposable {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "quest.dag.decomposable", Object: q.DAGDecomposable,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+	if q.DAGLevel > 0 {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "quest.dag.level", Object: q.DAGLevel,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+	if q.DAGOutputSchema != nil {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "quest.dag.output_schema", Object: q.DAGOutputSchema,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+	if q.DAGTypedOutput != nil {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "quest.dag.typed_output", Object: q.DAGTypedOutput,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
 	if q.DMClarifications != nil {
 		triples = append(triples, message.Triple{
 			Subject: entityID, Predicate: "quest.dm.clarifications", Object: q.DMClarifications,
@@ -370,6 +437,12 @@ func (q *Quest) Triples() []message.Triple {
 			Source: source, Timestamp: now, Confidence: 1.0,
 		})
 	}
+	if len(q.InjectedLessons) > 0 {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "quest.context.lessons", Object: q.InjectedLessons,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
 
 	// Execution context
 	if q.Repo != "" {
@@ -465,6 +538,9 @@ func (g *Guild) Triples() []message.Triple {
 		{Subject: entityID, Predicate: "guild.stats.quests_failed", Object: g.QuestsFailed, Source: source, Timestamp: now, Confidence: 1.0},
 		{Subject: entityID, Predicate: "guild.stats.success_rate", Object: g.SuccessRate, Source: source, Timestamp: now, Confidence: 1.0},
 
+		// Treasury
+		{Subject: entityID, Predicate: PredicateGuildTreasuryBalance, Object: g.Treasury, Source: source, Timestamp: now, Confidence: 1.0},
+
 		// Lifecycle
 		{Subject: entityID, Predicate: "guild.lifecycle.created_at", Object: g.CreatedAt.Format(time.RFC3339), Source: source, Timestamp: now, Confidence: 1.0},
 	}
@@ -588,6 +664,22 @@ func (g *Guild) Triples() []message.Triple {
 		})
 	}
 
+	// Treasury ledger (single JSON blob)
+	if len(g.TreasuryLedger) > 0 {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: PredicateGuildTreasuryLedger, Object: g.TreasuryLedger,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+
+	// Governance proposals (single JSON blob)
+	if len(g.Proposals) > 0 {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: PredicateGuildProposals, Object: g.Proposals,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+
 	return triples
 }
 
@@ -627,6 +719,13 @@ func (pr *PeerReview) Triples() []message.Triple {
 		})
 	}
 
+	if pr.IsMemberToMember {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "review.config.member_to_member", Object: true,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+
 	// Leader's review of member
 	if pr.LeaderReview != nil {
 		triples = append(triples,
@@ -641,6 +740,12 @@ func (pr *PeerReview) Triples() []message.Triple {
 				Source: source, Timestamp: now, Confidence: 1.0,
 			})
 		}
+		if pr.LeaderReview.Anomaly != "" {
+			triples = append(triples, message.Triple{
+				Subject: entityID, Predicate: "review.leader.anomaly", Object: string(pr.LeaderReview.Anomaly),
+				Source: source, Timestamp: now, Confidence: 1.0,
+			})
+		}
 	}
 
 	// Member's review of leader
@@ -657,6 +762,12 @@ func (pr *PeerReview) Triples() []message.Triple {
 				Source: source, Timestamp: now, Confidence: 1.0,
 			})
 		}
+		if pr.MemberReview.Anomaly != "" {
+			triples = append(triples, message.Triple{
+				Subject: entityID, Predicate: "review.member.anomaly", Object: string(pr.MemberReview.Anomaly),
+				Source: source, Timestamp: now, Confidence: 1.0,
+			})
+		}
 	}
 
 	// Computed averages (when completed)
@@ -667,6 +778,13 @@ func (pr *PeerReview) Triples() []message.Triple {
 		)
 	}
 
+	if pr.Aggregated {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "review.result.aggregated", Object: true,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+
 	if pr.CompletedAt != nil {
 		triples = append(triples, message.Triple{
 			Subject: entityID, Predicate: "review.lifecycle.completed_at", Object: pr.CompletedAt.Format(time.RFC3339),
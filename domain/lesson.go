@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // =============================================================================
 // LESSON TYPES — Structured knowledge from quest reviews
 // =============================================================================
@@ -48,4 +50,16 @@ type Lesson struct {
 	DiscoveredBy AgentID        `json:"discovered_by"`           // Agent who found this
 	GuildID      GuildID        `json:"guild_id"`                // Guild that owns this lesson
 	RedTeamQuest *QuestID       `json:"red_team_quest,omitempty"` // Red-team quest that surfaced it
+
+	// Curation state (see lesson_curation.go).
+	Reinforcements int       `json:"reinforcements,omitempty"`  // Near-duplicates merged into this lesson
+	Confirmations  int       `json:"confirmations,omitempty"`   // Later outcomes that agreed with it
+	Contradictions int       `json:"contradictions,omitempty"`  // Later outcomes that disagreed with it
+	Upvotes        int       `json:"upvotes,omitempty"`
+	Downvotes      int       `json:"downvotes,omitempty"`
+	Voters         []AgentID `json:"voters,omitempty"`          // Members who already voted
+	Retired        bool      `json:"retired,omitempty"`         // Proven wrong; never injected
+	RetiredReason  string    `json:"retired_reason,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastSeenAt     time.Time `json:"last_seen_at"`               // Last discovery or reinforcement
 }
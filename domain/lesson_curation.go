@@ -0,0 +1,310 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// =============================================================================
+// LESSON CURATION — Dedup, reinforcement, ranking and retirement
+// =============================================================================
+// Guild lessons are curated rather than kept FIFO. A newly discovered lesson
+// that nearly duplicates an existing one (same skill and category, similar
+// summary) reinforces it instead of being stored again. Lessons are ranked by
+// severity × reinforcement × recency, adjusted by member votes and by how
+// later quests turned out. Lessons that outcomes keep contradicting are
+// retired: kept for the record, but never injected into prompts again.
+
+// Lesson curation defaults.
+const (
+	// MaxGuildLessons bounds the lessons stored on a guild entity.
+	MaxGuildLessons = 100
+	// MaxPromptLessons is how many lessons are injected into a quest prompt.
+	MaxPromptLessons = 10
+	// LessonDuplicateThreshold is the summary similarity at which two lessons
+	// on the same skill and category are treated as the same lesson.
+	LessonDuplicateThreshold = 0.6
+	// LessonHalfLife is how long it takes a lesson's recency weight to halve
+	// since it was last discovered or reinforced.
+	LessonHalfLife = 14 * 24 * time.Hour
+	// DefaultLessonRetireAfter is how many contradictions (or net downvotes)
+	// retire a lesson.
+	DefaultLessonRetireAfter = 3
+)
+
+// ErrLessonAlreadyVoted is returned when a member votes twice on a lesson.
+var ErrLessonAlreadyVoted = errors.New("agent already voted on this lesson")
+
+// ErrLessonNotFound is returned when a lesson ID is not on the guild.
+var ErrLessonNotFound = errors.New("lesson not found")
+
+// LessonCurationPolicy controls how a guild's lessons are curated.
+type LessonCurationPolicy struct {
+	MaxLessons  int // Stored lessons cap; lowest-ranked are evicted
+	RetireAfter int // Contradictions or net downvotes that retire a lesson
+}
+
+// DefaultLessonCurationPolicy returns the standard curation policy.
+func DefaultLessonCurationPolicy() LessonCurationPolicy {
+	return LessonCurationPolicy{
+		MaxLessons:  MaxGuildLessons,
+		RetireAfter: DefaultLessonRetireAfter,
+	}
+}
+
+// LessonCurationResult summarizes what a curation pass did.
+type LessonCurationResult struct {
+	Added        int // New lessons stored
+	Reinforced   int // Incoming lessons merged into an existing one
+	Contradicted int // Existing lessons contradicted by an opposite finding
+	Retired      int // Lessons retired during this pass
+	Evicted      int // Lowest-ranked lessons dropped to stay under the cap
+}
+
+// Weight returns the ranking weight of a severity.
+func (s LessonSeverity) Weight() float64 {
+	switch s {
+	case LessonSeverityCritical:
+		return 4
+	case LessonSeverityWarning:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// LessonSimilarity returns the Jaccard similarity of two lesson summaries
+// over their lower-cased word tokens, from 0 (disjoint) to 1 (same words).
+func LessonSimilarity(a, b string) float64 {
+	ta, tb := lessonTokens(a), lessonTokens(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// lessonTokens splits a summary into a set of lower-cased words, ignoring
+// punctuation and one-letter words.
+func lessonTokens(s string) map[string]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		if len(w) > 1 {
+			set[w] = struct{}{}
+		}
+	}
+	return set
+}
+
+// SameTopic reports whether two lessons cover the same skill and category
+// with near-identical summaries, regardless of polarity.
+func (l *Lesson) SameTopic(other *Lesson) bool {
+	return l.Skill == other.Skill &&
+		l.Category == other.Category &&
+		LessonSimilarity(l.Summary, other.Summary) >= LessonDuplicateThreshold
+}
+
+// Score ranks the lesson for storage and prompt injection. Retired lessons
+// score zero.
+func (l *Lesson) Score(now time.Time) float64 {
+	if l.Retired {
+		return 0
+	}
+	reinforcement := 1 + math.Log2(1+float64(l.Reinforcements))
+
+	// Lessons stored before curation have no timestamps; treat them as one
+	// half-life old.
+	recency := 0.5
+	seen := l.LastSeenAt
+	if seen.IsZero() {
+		seen = l.CreatedAt
+	}
+	if !seen.IsZero() {
+		age := max(now.Sub(seen), 0)
+		recency = math.Pow(0.5, float64(age)/float64(LessonHalfLife))
+	}
+
+	trust := float64(1+l.Upvotes+l.Confirmations) / float64(1+l.Downvotes+l.Contradictions)
+
+	return l.Severity.Weight() * reinforcement * recency * trust
+}
+
+// RecordOutcome records a later quest outcome for which this lesson was in
+// context. It returns true when the outcome retires the lesson.
+func (l *Lesson) RecordOutcome(confirmed bool, retireAfter int) bool {
+	if confirmed {
+		l.Confirmations++
+		return false
+	}
+	l.Contradictions++
+	if !l.Retired && retireAfter > 0 &&
+		l.Contradictions >= retireAfter && l.Contradictions > l.Confirmations {
+		l.retire("contradicted by later quest outcomes")
+		return true
+	}
+	return false
+}
+
+// Vote records a member's up- or down-vote. Each member votes once per
+// lesson. A lesson whose net downvotes reach retireAfter is retired.
+func (l *Lesson) Vote(voterID AgentID, up bool, retireAfter int) error {
+	instance := ExtractInstance(string(voterID))
+	for _, v := range l.Voters {
+		if ExtractInstance(string(v)) == instance {
+			return ErrLessonAlreadyVoted
+		}
+	}
+	l.Voters = append(l.Voters, voterID)
+	if up {
+		l.Upvotes++
+		return nil
+	}
+	l.Downvotes++
+	if !l.Retired && retireAfter > 0 && l.Downvotes-l.Upvotes >= retireAfter {
+		l.retire("voted down by guild members")
+	}
+	return nil
+}
+
+func (l *Lesson) retire(reason string) {
+	l.Retired = true
+	l.RetiredReason = reason
+}
+
+// CurateLessons merges incoming lessons into a guild's existing lessons.
+// Near-duplicates with the same polarity reinforce the existing lesson;
+// near-duplicates with the opposite polarity count as a contradiction of it
+// and are stored as new lessons. When the result exceeds the policy cap the
+// lowest-ranked lessons are evicted (retired lessons first), so a critical
+// lesson is never pushed out by a burst of trivial ones.
+func CurateLessons(existing, incoming []Lesson, now time.Time, policy LessonCurationPolicy) ([]Lesson, LessonCurationResult) {
+	var result LessonCurationResult
+	lessons := make([]Lesson, len(existing), len(existing)+len(incoming))
+	copy(lessons, existing)
+
+	for _, in := range incoming {
+		if in.CreatedAt.IsZero() {
+			in.CreatedAt = now
+		}
+		if in.LastSeenAt.IsZero() {
+			in.LastSeenAt = now
+		}
+
+		match := -1
+		best := 0.0
+		for i := range lessons {
+			if lessons[i].Retired || !lessons[i].SameTopic(&in) {
+				continue
+			}
+			if sim := LessonSimilarity(lessons[i].Summary, in.Summary); sim > best {
+				match, best = i, sim
+			}
+		}
+
+		switch {
+		case match >= 0 && lessons[match].Positive == in.Positive:
+			m := &lessons[match]
+			m.Reinforcements++
+			m.LastSeenAt = now
+			if in.Severity.Weight() > m.Severity.Weight() {
+				m.Severity = in.Severity
+			}
+			if m.Detail == "" {
+				m.Detail = in.Detail
+			}
+			result.Reinforced++
+			continue
+		case match >= 0:
+			result.Contradicted++
+			if lessons[match].RecordOutcome(false, policy.RetireAfter) {
+				result.Retired++
+			}
+		}
+
+		lessons = append(lessons, in)
+		result.Added++
+	}
+
+	if policy.MaxLessons > 0 && len(lessons) > policy.MaxLessons {
+		result.Evicted = len(lessons) - policy.MaxLessons
+		lessons = evictLowestRanked(lessons, result.Evicted, now)
+	}
+	return lessons, result
+}
+
+// evictLowestRanked removes the n lowest-scoring lessons, preferring the
+// oldest on ties, and keeps the remaining lessons in their original order.
+func evictLowestRanked(lessons []Lesson, n int, now time.Time) []Lesson {
+	order := make([]int, len(lessons))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return lessons[order[a]].Score(now) < lessons[order[b]].Score(now)
+	})
+	drop := make(map[int]struct{}, n)
+	for _, idx := range order[:n] {
+		drop[idx] = struct{}{}
+	}
+	kept := make([]Lesson, 0, len(lessons)-n)
+	for i, l := range lessons {
+		if _, ok := drop[i]; !ok {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
+
+// SelectLessons returns up to limit active lessons matching any of the
+// skills, best-ranked first.
+func SelectLessons(lessons []Lesson, skills []SkillTag, now time.Time, limit int) []Lesson {
+	wanted := make(map[SkillTag]struct{}, len(skills))
+	for _, s := range skills {
+		wanted[s] = struct{}{}
+	}
+
+	var relevant []Lesson
+	for _, l := range lessons {
+		if l.Retired {
+			continue
+		}
+		if _, ok := wanted[l.Skill]; ok {
+			relevant = append(relevant, l)
+		}
+	}
+
+	// Newest first on ties, matching the previous recency-only behavior.
+	for i, j := 0, len(relevant)-1; i < j; i, j = i+1, j-1 {
+		relevant[i], relevant[j] = relevant[j], relevant[i]
+	}
+	sort.SliceStable(relevant, func(a, b int) bool {
+		return relevant[a].Score(now) > relevant[b].Score(now)
+	})
+
+	if limit > 0 && len(relevant) > limit {
+		relevant = relevant[:limit]
+	}
+	return relevant
+}
+
+// Lesson returns the guild lesson with the given ID, or nil.
+func (g *Guild) Lesson(id string) *Lesson {
+	for i := range g.Lessons {
+		if g.Lessons[i].ID == id {
+			return &g.Lessons[i]
+		}
+	}
+	return nil
+}
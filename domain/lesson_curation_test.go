@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLessonSimilarity(t *testing.T) {
+	if got := LessonSimilarity("Validate user input before SQL queries", "validate user input before sql queries!"); got != 1 {
+		t.Errorf("identical summaries = %v, want 1", got)
+	}
+	if got := LessonSimilarity("Validate user input before SQL queries", "Always validate user input before SQL queries"); got < LessonDuplicateThreshold {
+		t.Errorf("near-duplicate similarity = %v, want >= %v", got, LessonDuplicateThreshold)
+	}
+	if got := LessonSimilarity("Validate user input", "Prefer table-driven tests"); got != 0 {
+		t.Errorf("unrelated similarity = %v, want 0", got)
+	}
+}
+
+func TestCurateLessons_MergesNearDuplicates(t *testing.T) {
+	now := time.Now()
+	existing := []Lesson{{
+		ID: "l1", Skill: SkillCodeGen, Category: LessonSecurity,
+		Summary: "Validate user input before SQL queries", Severity: LessonSeverityWarning,
+	}}
+	incoming := []Lesson{{
+		ID: "l2", Skill: SkillCodeGen, Category: LessonSecurity,
+		Summary: "Always validate user input before SQL queries", Severity: LessonSeverityCritical,
+	}}
+
+	got, res := CurateLessons(existing, incoming, now, DefaultLessonCurationPolicy())
+	if len(got) != 1 || res.Reinforced != 1 || res.Added != 0 {
+		t.Fatalf("lessons = %d, result = %+v", len(got), res)
+	}
+	if got[0].Reinforcements != 1 || got[0].Severity != LessonSeverityCritical || !got[0].LastSeenAt.Equal(now) {
+		t.Errorf("merged lesson = %+v", got[0])
+	}
+	if existing[0].Reinforcements != 0 {
+		t.Error("CurateLessons mutated its input")
+	}
+}
+
+func TestCurateLessons_OppositePolarityContradicts(t *testing.T) {
+	existing := []Lesson{{
+		ID: "l1", Skill: SkillCodeGen, Category: LessonQuality,
+		Summary: "Global mutable state keeps handlers simple", Positive: true,
+		Contradictions: DefaultLessonRetireAfter - 1,
+	}}
+	incoming := []Lesson{{
+		ID: "l2", Skill: SkillCodeGen, Category: LessonQuality,
+		Summary: "Global mutable state keeps handlers simple", Positive: false,
+	}}
+
+	got, res := CurateLessons(existing, incoming, time.Now(), DefaultLessonCurationPolicy())
+	if len(got) != 2 || res.Contradicted != 1 || res.Retired != 1 {
+		t.Fatalf("lessons = %d, result = %+v", len(got), res)
+	}
+	if !got[0].Retired {
+		t.Error("contradicted lesson should be retired")
+	}
+}
+
+func TestCurateLessons_EvictsLowestRankedNotOldest(t *testing.T) {
+	now := time.Now()
+	critical := Lesson{
+		ID: "critical", Skill: SkillCodeGen, Category: LessonSecurity,
+		Summary: "Never log credentials", Severity: LessonSeverityCritical,
+		CreatedAt: now.Add(-time.Hour), LastSeenAt: now.Add(-time.Hour),
+	}
+	var incoming []Lesson
+	for i := range 10 {
+		incoming = append(incoming, Lesson{
+			ID: fmt.Sprintf("style-%d", i), Skill: SkillCodeGen, Category: LessonQuality,
+			Summary: fmt.Sprintf("style nit number%d", i), Severity: LessonSeverityInfo,
+		})
+	}
+
+	policy := LessonCurationPolicy{MaxLessons: 5, RetireAfter: 3}
+	got, res := CurateLessons([]Lesson{critical}, incoming, now, policy)
+	if len(got) != 5 || res.Evicted != 6 {
+		t.Fatalf("lessons = %d, result = %+v", len(got), res)
+	}
+	if got[0].ID != "critical" {
+		t.Errorf("critical lesson evicted; kept %v", got[0].ID)
+	}
+}
+
+func TestLesson_ScoreOrdering(t *testing.T) {
+	now := time.Now()
+	base := Lesson{Severity: LessonSeverityWarning, LastSeenAt: now}
+
+	reinforced := base
+	reinforced.Reinforcements = 3
+	if reinforced.Score(now) <= base.Score(now) {
+		t.Error("reinforcement should raise the score")
+	}
+
+	stale := base
+	stale.LastSeenAt = now.Add(-2 * LessonHalfLife)
+	if stale.Score(now) >= base.Score(now) {
+		t.Error("older lessons should score lower")
+	}
+
+	downvoted := base
+	downvoted.Downvotes = 2
+	if downvoted.Score(now) >= base.Score(now) {
+		t.Error("downvotes should lower the score")
+	}
+
+	retired := base
+	retired.Retired = true
+	if retired.Score(now) != 0 {
+		t.Error("retired lessons should score zero")
+	}
+}
+
+func TestLesson_RecordOutcome(t *testing.T) {
+	l := Lesson{Confirmations: 1}
+	for range 2 {
+		if l.RecordOutcome(false, 3) {
+			t.Fatal("retired too early")
+		}
+	}
+	if !l.RecordOutcome(false, 3) || !l.Retired {
+		t.Error("third contradiction should retire the lesson")
+	}
+
+	confirmed := Lesson{Confirmations: 5}
+	for range 3 {
+		confirmed.RecordOutcome(false, 3)
+	}
+	if confirmed.Retired {
+		t.Error("a well-confirmed lesson should survive a few contradictions")
+	}
+}
+
+func TestLesson_Vote(t *testing.T) {
+	l := Lesson{}
+	if err := l.Vote("c360.prod.game.board1.agent.a1", true, 2); err != nil {
+		t.Fatalf("first vote: %v", err)
+	}
+	if err := l.Vote("a1", false, 2); !errors.Is(err, ErrLessonAlreadyVoted) {
+		t.Errorf("duplicate vote error = %v, want ErrLessonAlreadyVoted", err)
+	}
+	_ = l.Vote("a2", false, 2)
+	_ = l.Vote("a3", false, 2)
+	_ = l.Vote("a4", false, 2)
+	if !l.Retired || l.Upvotes != 1 || l.Downvotes != 3 {
+		t.Errorf("lesson after votes = %+v", l)
+	}
+}
+
+func TestSelectLessons(t *testing.T) {
+	now := time.Now()
+	lessons := []Lesson{
+		{ID: "old-critical", Skill: SkillCodeGen, Severity: LessonSeverityCritical, LastSeenAt: now.Add(-24 * time.Hour)},
+		{ID: "retired", Skill: SkillCodeGen, Severity: LessonSeverityCritical, LastSeenAt: now, Retired: true},
+		{ID: "other-skill", Skill: SkillAnalysis, Severity: LessonSeverityCritical, LastSeenAt: now},
+		{ID: "new-info", Skill: SkillCodeGen, Severity: LessonSeverityInfo, LastSeenAt: now},
+	}
+
+	got := SelectLessons(lessons, []SkillTag{SkillCodeGen}, now, MaxPromptLessons)
+	if len(got) != 2 || got[0].ID != "old-critical" || got[1].ID != "new-info" {
+		t.Errorf("selected = %+v", got)
+	}
+	if got := SelectLessons(lessons, []SkillTag{SkillCodeGen}, now, 1); len(got) != 1 {
+		t.Errorf("limit ignored: %d lessons", len(got))
+	}
+}
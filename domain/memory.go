@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// =============================================================================
+// QUEST MEMORY — Per-agent long-term recall of past quests
+// =============================================================================
+// A QuestMemory is a compact record an agent keeps about a quest it finished
+// (or failed). Memories are stored on the agent entity and the most relevant
+// ones are injected into the agent's prompt when a new quest arrives, so
+// agents stop starting every quest cold.
+
+// MemoryOutcome records how the remembered quest ended.
+type MemoryOutcome string
+
+// Memory outcome constants.
+const (
+	MemoryOutcomeSuccess MemoryOutcome = "success"
+	MemoryOutcomeFailure MemoryOutcome = "failure"
+)
+
+// QuestMemory is a single remembered quest. Fields are deliberately short —
+// the whole record is meant to fit in a few lines of prompt.
+type QuestMemory struct {
+	ID            string        `json:"id"`
+	QuestID       QuestID       `json:"quest_id"`
+	Objective     string        `json:"objective"`                // What the quest asked for
+	Approach      string        `json:"approach,omitempty"`       // How the agent tackled it
+	Outcome       MemoryOutcome `json:"outcome"`                  // success or failure
+	QualityScore  float64       `json:"quality_score,omitempty"`  // Boss battle score, when judged
+	Skills        []SkillTag    `json:"skills,omitempty"`         // Quest required skills
+	Repo          string        `json:"repo,omitempty"`           // Target repository
+	KeyFiles      []string      `json:"key_files,omitempty"`      // Files the agent touched
+	JudgeFeedback string        `json:"judge_feedback,omitempty"` // Verdict feedback or failure reason
+	RecordedAt    time.Time     `json:"recorded_at"`
+}
+
+// AsQuestMemories converts a triple Object to []QuestMemory.
+// Handles both in-process typed slices and JSON-deserialized []any from KV.
+func AsQuestMemories(obj any) []QuestMemory {
+	if obj == nil {
+		return nil
+	}
+	if memories, ok := obj.([]QuestMemory); ok {
+		return memories
+	}
+	// After KV round-trip the blob is []any of map[string]any — re-marshal
+	// rather than hand-decoding every field.
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	var memories []QuestMemory
+	if err := json.Unmarshal(raw, &memories); err != nil {
+		return nil
+	}
+	valid := memories[:0]
+	for _, m := range memories {
+		if m.ID != "" && m.Objective != "" {
+			valid = append(valid, m)
+		}
+	}
+	return valid
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// AsQuestMemories — typed and KV round-trip decoding
+// =============================================================================
+
+func TestAsQuestMemories_TypedSlice(t *testing.T) {
+	in := []QuestMemory{{ID: "mem-1", Objective: "Do the thing"}}
+	got := AsQuestMemories(in)
+	if len(got) != 1 || got[0].ID != "mem-1" {
+		t.Errorf("AsQuestMemories typed slice = %+v, want passthrough", got)
+	}
+}
+
+func TestAsQuestMemories_KVRoundTrip(t *testing.T) {
+	recorded := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	original := []QuestMemory{
+		{
+			ID:            "mem-q1",
+			QuestID:       QuestID("c360.prod.game.board1.quest.q1"),
+			Objective:     "Add retry to HTTP client",
+			Approach:      "Wrapped transport with backoff",
+			Outcome:       MemoryOutcomeSuccess,
+			QualityScore:  0.85,
+			Skills:        []SkillTag{SkillCodeGen},
+			Repo:          "api",
+			KeyFiles:      []string{"client/retry.go"},
+			JudgeFeedback: "Good coverage",
+			RecordedAt:    recorded,
+		},
+		{ID: "", Objective: "missing id is dropped"},
+	}
+
+	// Simulate the KV round-trip: typed slice → JSON → []any of map[string]any.
+	raw, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	got := AsQuestMemories(decoded)
+	if len(got) != 1 {
+		t.Fatalf("AsQuestMemories returned %d memories, want 1", len(got))
+	}
+	m := got[0]
+	if m.QuestID != original[0].QuestID || m.Outcome != MemoryOutcomeSuccess || m.QualityScore != 0.85 {
+		t.Errorf("decoded memory mismatch: %+v", m)
+	}
+	if len(m.KeyFiles) != 1 || m.KeyFiles[0] != "client/retry.go" {
+		t.Errorf("KeyFiles = %v, want [client/retry.go]", m.KeyFiles)
+	}
+	if !m.RecordedAt.Equal(recorded) {
+		t.Errorf("RecordedAt = %v, want %v", m.RecordedAt, recorded)
+	}
+}
+
+func TestAsQuestMemories_Invalid(t *testing.T) {
+	for _, obj := range []any{nil, "not a slice", 42} {
+		if got := AsQuestMemories(obj); len(got) != 0 {
+			t.Errorf("AsQuestMemories(%v) = %v, want empty", obj, got)
+		}
+	}
+}
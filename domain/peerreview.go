@@ -42,23 +42,36 @@ type ReviewSubmission struct {
 	Ratings     ReviewRatings   `json:"ratings"`
 	Explanation string          `json:"explanation,omitempty"`
 	SubmittedAt time.Time       `json:"submitted_at"`
+	// Anomaly is set on member-to-member submissions that 360 aggregation
+	// flagged as collusion or retaliation. Flagged submissions are excluded
+	// from peer scores.
+	Anomaly ReviewAnomalyKind `json:"anomaly,omitempty"`
 }
 
 // PeerReview is the entity tracking bidirectional review between two agents.
+//
+// Member-to-member reviews (IsMemberToMember) pair two party members who
+// worked on dependent DAG nodes. LeaderID and MemberID then hold the two
+// peers: LeaderReview is LeaderID's review of MemberID and MemberReview the
+// reverse, both with ReviewDirectionMemberToMember.
 type PeerReview struct {
-	ID              PeerReviewID      `json:"id"`
-	Status          PeerReviewStatus  `json:"status"`
-	QuestID         QuestID           `json:"quest_id"`
-	PartyID         *PartyID          `json:"party_id,omitempty"`
-	LeaderID        AgentID           `json:"leader_id"`
-	MemberID        AgentID           `json:"member_id"`
-	IsSoloTask      bool              `json:"is_solo_task"`
-	LeaderReview    *ReviewSubmission `json:"leader_review,omitempty"`
-	MemberReview    *ReviewSubmission `json:"member_review,omitempty"`
-	LeaderAvgRating float64           `json:"leader_avg_rating"`
-	MemberAvgRating float64           `json:"member_avg_rating"`
-	CreatedAt       time.Time         `json:"created_at"`
-	CompletedAt     *time.Time        `json:"completed_at,omitempty"`
+	ID               PeerReviewID      `json:"id"`
+	Status           PeerReviewStatus  `json:"status"`
+	QuestID          QuestID           `json:"quest_id"`
+	PartyID          *PartyID          `json:"party_id,omitempty"`
+	LeaderID         AgentID           `json:"leader_id"`
+	MemberID         AgentID           `json:"member_id"`
+	IsSoloTask       bool              `json:"is_solo_task"`
+	IsMemberToMember bool              `json:"is_member_to_member,omitempty"`
+	LeaderReview     *ReviewSubmission `json:"leader_review,omitempty"`
+	MemberReview     *ReviewSubmission `json:"member_review,omitempty"`
+	LeaderAvgRating  float64           `json:"leader_avg_rating"`
+	MemberAvgRating  float64           `json:"member_avg_rating"`
+	CreatedAt        time.Time         `json:"created_at"`
+	CompletedAt      *time.Time        `json:"completed_at,omitempty"`
+	// Aggregated is set once a member-to-member review has been folded into
+	// its party's 360 aggregation.
+	Aggregated bool `json:"aggregated,omitempty"`
 }
 
 // LeaderToMemberQuestions are the review questions for leader reviewing member.
@@ -68,6 +81,14 @@ var LeaderToMemberQuestions = [3]string{
 	"Autonomy — did they work independently without excessive hand-holding?",
 }
 
+// MemberToMemberQuestions are the review questions for a party member
+// reviewing a peer who worked on a dependent node.
+var MemberToMemberQuestions = [3]string{
+	"Handoff quality — did their output fit what your node needed?",
+	"Communication — did they share interface changes and blockers?",
+	"Reliability — could you build on their work without rework?",
+}
+
 // MemberToLeaderQuestions are the review questions for member reviewing leader.
 var MemberToLeaderQuestions = [3]string{
 	"Clarity — was the task well-defined with clear acceptance criteria?",
@@ -0,0 +1,245 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// =============================================================================
+// 360 PEER REVIEW AGGREGATION — Weighted scores and anomaly detection
+// =============================================================================
+// At party disband, members who worked on dependent DAG nodes review each
+// other. A party's member-to-member reviews are aggregated together once all
+// of them are in: each rating is weighted by the reviewer's level and by how
+// well their past ratings matched consensus, and reciprocal pairs are checked
+// for collusion (both inflating each other) and retaliation (a low rating
+// that nobody else backs up, answered by a low rating in return). Flagged
+// submissions count against the reviewer's calibration but not toward the
+// reviewee's score.
+
+// ReviewAnomalyKind classifies a suspicious member-to-member submission.
+type ReviewAnomalyKind string
+
+// Review anomaly kinds.
+const (
+	ReviewAnomalyCollusion   ReviewAnomalyKind = "collusion"
+	ReviewAnomalyRetaliation ReviewAnomalyKind = "retaliation"
+)
+
+// 360 aggregation thresholds.
+const (
+	// CollusionMinRating is the average rating both sides of a pair must
+	// give each other to be checked for collusion.
+	CollusionMinRating = 4.5
+	// RetaliationMaxRating is the average rating both sides of a pair must
+	// stay at or below to be checked for retaliation.
+	RetaliationMaxRating = 2.0
+	// ReviewAnomalyGap is how far a rating must sit from the reviewee's
+	// consensus before a reciprocal pattern is flagged.
+	ReviewAnomalyGap = 1.5
+	// DefaultReviewCalibration is assumed for reviewers with no history.
+	DefaultReviewCalibration = 0.75
+)
+
+// ReviewerProfile describes a reviewer for weighting purposes.
+type ReviewerProfile struct {
+	Level int
+	// Calibration is 0–1: 1 means the reviewer's past ratings matched the
+	// consensus exactly. Ignored until CalibrationSamples > 0.
+	Calibration        float64
+	CalibrationSamples int
+}
+
+// Weight returns the reviewer's weight in a 360 aggregation. Level scales
+// the weight from 0.55 (level 1) to 1.5 (level 20); calibration scales it
+// from 0.25 (always off consensus) to 1.0.
+func (p ReviewerProfile) Weight() float64 {
+	level := min(max(p.Level, 1), 20)
+	calibration := DefaultReviewCalibration
+	if p.CalibrationSamples > 0 {
+		calibration = min(max(p.Calibration, 0), 1)
+	}
+	return (0.5 + float64(level)/20) * (0.25 + 0.75*calibration)
+}
+
+// UpdateCalibration folds a new consensus deviation (on the 1–5 scale) into
+// a reviewer's running calibration.
+func UpdateCalibration(calibration float64, samples int, deviation float64) float64 {
+	sample := 1 - min(math.Abs(deviation), 4)/4
+	if samples <= 0 {
+		return sample
+	}
+	return (calibration*float64(samples) + sample) / float64(samples+1)
+}
+
+// ReviewAnomaly is a flagged member-to-member submission.
+type ReviewAnomaly struct {
+	Kind       ReviewAnomalyKind `json:"kind"`
+	ReviewID   PeerReviewID      `json:"review_id"`
+	ReviewerID AgentID           `json:"reviewer_id"`
+	RevieweeID AgentID           `json:"reviewee_id"`
+	Rating     float64           `json:"rating"`
+	Consensus  float64           `json:"consensus"`
+}
+
+// PeerReviewAggregate is the outcome of a party's 360 aggregation.
+type PeerReviewAggregate struct {
+	// Scores is each reviewee's weighted 1–5 peer score, from unflagged
+	// submissions only.
+	Scores map[AgentID]float64
+	// Deviations is each reviewer's mean absolute distance from consensus,
+	// for submissions where a consensus existed.
+	Deviations map[AgentID]float64
+	Anomalies  []ReviewAnomaly
+}
+
+// Flagged returns the anomaly kind recorded for reviewer's rating of
+// reviewee, or "".
+func (a *PeerReviewAggregate) Flagged(reviewer, reviewee AgentID) ReviewAnomalyKind {
+	for _, an := range a.Anomalies {
+		if an.ReviewerID == reviewer && an.RevieweeID == reviewee {
+			return an.Kind
+		}
+	}
+	return ""
+}
+
+// AggregatePeerReviews aggregates a party's completed member-to-member
+// reviews. profiles supplies reviewer weights (missing reviewers get a
+// level-1, uncalibrated profile). baseline optionally supplies an outside
+// view of each reviewee, such as the lead's review average, which counts as
+// one unit-weight opinion in every consensus.
+func AggregatePeerReviews(reviews []PeerReview, profiles map[AgentID]ReviewerProfile, baseline map[AgentID]float64) PeerReviewAggregate {
+	type rating struct {
+		reviewID PeerReviewID
+		reviewer AgentID
+		reviewee AgentID
+		value    float64
+		weight   float64
+	}
+
+	var ratings []rating
+	received := make(map[AgentID][]int)
+	pairs := make(map[PeerReviewID][2]int)
+	for _, pr := range reviews {
+		if !pr.IsMemberToMember {
+			continue
+		}
+		idx := [2]int{-1, -1}
+		for side, sub := range []*ReviewSubmission{pr.LeaderReview, pr.MemberReview} {
+			if sub == nil || sub.ReviewerID == "" || sub.ReviewerID == sub.RevieweeID {
+				continue
+			}
+			idx[side] = len(ratings)
+			received[sub.RevieweeID] = append(received[sub.RevieweeID], len(ratings))
+			ratings = append(ratings, rating{
+				reviewID: pr.ID,
+				reviewer: sub.ReviewerID,
+				reviewee: sub.RevieweeID,
+				value:    sub.Ratings.Average(),
+				weight:   profiles[sub.ReviewerID].Weight(),
+			})
+		}
+		if idx[0] >= 0 && idx[1] >= 0 {
+			pairs[pr.ID] = idx
+		}
+	}
+
+	// consensus is the reviewee's weighted rating from everyone but the
+	// rating's own reviewer, plus the baseline.
+	consensus := func(i int) (float64, bool) {
+		r := ratings[i]
+		var sum, weight float64
+		if b, ok := baseline[r.reviewee]; ok {
+			sum, weight = b, 1
+		}
+		for _, j := range received[r.reviewee] {
+			if ratings[j].reviewer != r.reviewer {
+				sum += ratings[j].value * ratings[j].weight
+				weight += ratings[j].weight
+			}
+		}
+		if weight == 0 {
+			return 0, false
+		}
+		return sum / weight, true
+	}
+
+	agg := PeerReviewAggregate{
+		Scores:     make(map[AgentID]float64),
+		Deviations: make(map[AgentID]float64),
+	}
+
+	consensuses := make([]float64, len(ratings))
+	known := make([]bool, len(ratings))
+	devSum := make(map[AgentID]float64)
+	devCount := make(map[AgentID]int)
+	for i, r := range ratings {
+		consensuses[i], known[i] = consensus(i)
+		if known[i] {
+			devSum[r.reviewer] += math.Abs(r.value - consensuses[i])
+			devCount[r.reviewer]++
+		}
+	}
+	for id, n := range devCount {
+		agg.Deviations[id] = devSum[id] / float64(n)
+	}
+
+	flagged := make([]bool, len(ratings))
+	flag := func(i int, kind ReviewAnomalyKind) {
+		flagged[i] = true
+		r := ratings[i]
+		agg.Anomalies = append(agg.Anomalies, ReviewAnomaly{
+			Kind: kind, ReviewID: r.reviewID, ReviewerID: r.reviewer, RevieweeID: r.reviewee,
+			Rating: r.value, Consensus: consensuses[i],
+		})
+	}
+	for _, idx := range pairs {
+		a, b := idx[0], idx[1]
+		va, vb := ratings[a].value, ratings[b].value
+		switch {
+		case va >= CollusionMinRating && vb >= CollusionMinRating:
+			// Both inflated: only flag when neither score is backed up.
+			if known[a] && known[b] &&
+				va-consensuses[a] >= ReviewAnomalyGap && vb-consensuses[b] >= ReviewAnomalyGap {
+				flag(a, ReviewAnomalyCollusion)
+				flag(b, ReviewAnomalyCollusion)
+			}
+		case va <= RetaliationMaxRating && vb <= RetaliationMaxRating:
+			// Mutual low ratings: flag whichever side nobody else backs up.
+			for _, i := range idx {
+				if known[i] && consensuses[i]-ratings[i].value >= ReviewAnomalyGap {
+					flag(i, ReviewAnomalyRetaliation)
+				}
+			}
+		}
+	}
+	sort.Slice(agg.Anomalies, func(i, j int) bool {
+		x, y := agg.Anomalies[i], agg.Anomalies[j]
+		if x.ReviewID != y.ReviewID {
+			return x.ReviewID < y.ReviewID
+		}
+		return x.ReviewerID < y.ReviewerID
+	})
+
+	for reviewee, idxs := range received {
+		var sum, weight float64
+		for _, i := range idxs {
+			if !flagged[i] {
+				sum += ratings[i].value * ratings[i].weight
+				weight += ratings[i].weight
+			}
+		}
+		if weight > 0 {
+			agg.Scores[reviewee] = sum / weight
+		}
+	}
+	return agg
+}
+
+// String renders an anomaly for logs and DM summaries.
+func (a ReviewAnomaly) String() string {
+	return fmt.Sprintf("%s: %s rated %s %.1f against a consensus of %.1f",
+		a.Kind, a.ReviewerID, a.RevieweeID, a.Rating, a.Consensus)
+}
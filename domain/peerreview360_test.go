@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+// m2mReview builds a completed member-to-member review in which a rates b
+// ab on every question and b rates a ba.
+func m2mReview(id string, a, b AgentID, ab, ba int) PeerReview {
+	return PeerReview{
+		ID:               PeerReviewID(id),
+		Status:           PeerReviewCompleted,
+		LeaderID:         a,
+		MemberID:         b,
+		IsMemberToMember: true,
+		LeaderReview: &ReviewSubmission{
+			ReviewerID: a, RevieweeID: b, Direction: ReviewDirectionMemberToMember,
+			Ratings: ReviewRatings{Q1: ab, Q2: ab, Q3: ab},
+		},
+		MemberReview: &ReviewSubmission{
+			ReviewerID: b, RevieweeID: a, Direction: ReviewDirectionMemberToMember,
+			Ratings: ReviewRatings{Q1: ba, Q2: ba, Q3: ba},
+		},
+	}
+}
+
+func TestReviewerProfileWeight(t *testing.T) {
+	novice := ReviewerProfile{Level: 1}.Weight()
+	veteran := ReviewerProfile{Level: 18}.Weight()
+	if veteran <= novice {
+		t.Errorf("level 18 weight %v should exceed level 1 weight %v", veteran, novice)
+	}
+
+	calibrated := ReviewerProfile{Level: 10, Calibration: 1, CalibrationSamples: 5}.Weight()
+	erratic := ReviewerProfile{Level: 10, Calibration: 0, CalibrationSamples: 5}.Weight()
+	uncalibrated := ReviewerProfile{Level: 10}.Weight()
+	if !(calibrated > uncalibrated && uncalibrated > erratic) {
+		t.Errorf("weights calibrated=%v uncalibrated=%v erratic=%v, want descending", calibrated, uncalibrated, erratic)
+	}
+}
+
+func TestUpdateCalibration(t *testing.T) {
+	if got := UpdateCalibration(0, 0, 1); got != 0.75 {
+		t.Errorf("first sample = %v, want 0.75", got)
+	}
+	if got := UpdateCalibration(1, 3, 4); got != 0.75 {
+		t.Errorf("running average = %v, want 0.75", got)
+	}
+	if got := UpdateCalibration(1, 1, -8); got != 0.5 {
+		t.Errorf("deviation beyond scale = %v, want 0.5", got)
+	}
+}
+
+func TestAggregatePeerReviews_WeightedScores(t *testing.T) {
+	reviews := []PeerReview{
+		m2mReview("r1", "a", "c", 5, 4),
+		m2mReview("r2", "b", "c", 3, 4),
+	}
+	profiles := map[AgentID]ReviewerProfile{
+		"a": {Level: 20, Calibration: 1, CalibrationSamples: 10},
+		"b": {Level: 1, Calibration: 0, CalibrationSamples: 10},
+	}
+	agg := AggregatePeerReviews(reviews, profiles, nil)
+
+	wa, wb := profiles["a"].Weight(), profiles["b"].Weight()
+	want := (5*wa + 3*wb) / (wa + wb)
+	if got := agg.Scores["c"]; math.Abs(got-want) > 1e-9 {
+		t.Errorf("score for c = %v, want %v", got, want)
+	}
+	if len(agg.Anomalies) != 0 {
+		t.Errorf("unexpected anomalies: %v", agg.Anomalies)
+	}
+	// a is compared with b's view of c and vice versa.
+	if got := agg.Deviations["a"]; got != 2 {
+		t.Errorf("deviation for a = %v, want 2", got)
+	}
+}
+
+func TestAggregatePeerReviews_Collusion(t *testing.T) {
+	reviews := []PeerReview{
+		m2mReview("r1", "a", "b", 5, 5),
+		m2mReview("r2", "a", "c", 2, 4),
+		m2mReview("r3", "b", "c", 2, 2),
+	}
+	// The lead rated both a and b poorly.
+	baseline := map[AgentID]float64{"a": 2, "b": 2}
+	agg := AggregatePeerReviews(reviews, nil, baseline)
+
+	if got := agg.Flagged("a", "b"); got != ReviewAnomalyCollusion {
+		t.Errorf("a→b flag = %q, want collusion", got)
+	}
+	if got := agg.Flagged("b", "a"); got != ReviewAnomalyCollusion {
+		t.Errorf("b→a flag = %q, want collusion", got)
+	}
+	// b's score now comes only from c's unflagged rating.
+	if got := agg.Scores["b"]; got != 2 {
+		t.Errorf("score for b = %v, want 2", got)
+	}
+}
+
+func TestAggregatePeerReviews_HighPraiseBackedByConsensus(t *testing.T) {
+	reviews := []PeerReview{m2mReview("r1", "a", "b", 5, 5)}
+	baseline := map[AgentID]float64{"a": 5, "b": 4.5}
+	agg := AggregatePeerReviews(reviews, nil, baseline)
+	if len(agg.Anomalies) != 0 {
+		t.Errorf("unexpected anomalies: %v", agg.Anomalies)
+	}
+}
+
+func TestAggregatePeerReviews_Retaliation(t *testing.T) {
+	reviews := []PeerReview{
+		m2mReview("r1", "a", "b", 1, 2),
+		m2mReview("r2", "b", "c", 5, 5),
+	}
+	// Everyone else thinks b did well; a is as poor as b says.
+	baseline := map[AgentID]float64{"a": 2, "b": 5}
+	agg := AggregatePeerReviews(reviews, nil, baseline)
+
+	if got := agg.Flagged("a", "b"); got != ReviewAnomalyRetaliation {
+		t.Errorf("a→b flag = %q, want retaliation", got)
+	}
+	if got := agg.Flagged("b", "a"); got != "" {
+		t.Errorf("b→a flag = %q, want none", got)
+	}
+	if len(agg.Anomalies) != 1 {
+		t.Errorf("anomalies = %v, want exactly one", agg.Anomalies)
+	}
+}
+
+func TestAggregatePeerReviews_IgnoresLeaderReviews(t *testing.T) {
+	lead := m2mReview("r1", "lead", "a", 5, 5)
+	lead.IsMemberToMember = false
+	agg := AggregatePeerReviews([]PeerReview{lead}, nil, nil)
+	if len(agg.Scores) != 0 {
+		t.Errorf("scores = %v, want none", agg.Scores)
+	}
+}
@@ -369,6 +369,37 @@ func TestPeerReviewFromEntityState_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestPeerReviewFromEntityState_MemberToMember(t *testing.T) {
+	original := m2mReview("test.dev.game.board1.peerreview.pr3", "test.dev.game.board1.agent.a", "test.dev.game.board1.agent.b", 5, 5)
+	original.Aggregated = true
+	original.LeaderReview.Anomaly = ReviewAnomalyCollusion
+
+	entity := &graph.EntityState{
+		ID:      string(original.ID),
+		Triples: original.Triples(),
+	}
+	r := PeerReviewFromEntityState(entity)
+
+	if !r.IsMemberToMember || !r.Aggregated {
+		t.Errorf("IsMemberToMember = %v, Aggregated = %v, want both true", r.IsMemberToMember, r.Aggregated)
+	}
+	for _, sub := range []*ReviewSubmission{r.LeaderReview, r.MemberReview} {
+		if sub == nil || sub.Direction != ReviewDirectionMemberToMember {
+			t.Fatalf("submission = %+v, want member_to_member direction", sub)
+		}
+	}
+	if r.LeaderReview.Anomaly != ReviewAnomalyCollusion {
+		t.Errorf("LeaderReview.Anomaly = %q, want collusion", r.LeaderReview.Anomaly)
+	}
+	if r.MemberReview.Anomaly != "" {
+		t.Errorf("MemberReview.Anomaly = %q, want none", r.MemberReview.Anomaly)
+	}
+	if r.MemberReview.ReviewerID != original.MemberID || r.MemberReview.RevieweeID != original.LeaderID {
+		t.Errorf("MemberReview participants = %s→%s, want %s→%s",
+			r.MemberReview.ReviewerID, r.MemberReview.RevieweeID, original.MemberID, original.LeaderID)
+	}
+}
+
 func TestPeerReviewFromEntityState_PartialSubmission(t *testing.T) {
 	now := time.Now().Truncate(time.Second)
 
@@ -192,12 +192,34 @@ func QuestFromEntityState(entity *graph.EntityState) *Quest {
 			q.DAGFailedNodes = triple.Object
 		case "quest.dag.node_retries":
 			q.DAGNodeRetries = triple.Object
+		case "quest.dag.party_id":
+			q.DAGPartyID = AsString(triple.Object)
+		case "quest.dag.amendments":
+			q.DAGAmendments = triple.Object
+		case "quest.dag.blueprint":
+			q.DAGBlueprint = AsString(triple.Object)
+		case "quest.dag.diagram":
+			q.DAGDiagram = AsString(triple.Object)
+		case "quest.dag.blueprint_args":
+			q.DAGBlueprintArgs = triple.Object
+		case "quest.dag.swaps":
+			q.DAGSwaps = triple.Object
 
 		// DAG sub-quest fields
 		case "quest.dag.node_id":
 			q.DAGNodeID = AsString(triple.Object)
 		case "quest.dag.clarifications":
 			q.DAGClarifications = triple.Object
+		case "quest.dag.handover_from":
+			q.DAGHandoverFrom = AsString(triple.Object)
+		case "quest.dag.decomposable":
+			q.DAGDecomposable = AsBool(triple.Object)
+		case "quest.dag.level":
+			q.DAGLevel = AsInt(triple.Object)
+		case "quest.dag.output_schema":
+			q.DAGOutputSchema = triple.Object
+		case "quest.dag.typed_output":
+			q.DAGTypedOutput = triple.Object
 
 		// DM clarification exchanges (standalone/parent quests)
 		case "quest.dm.clarifications":
@@ -210,6 +232,8 @@ func QuestFromEntityState(entity *graph.EntityState) *Quest {
 			q.ContextSources = AsStringSlice(triple.Object)
 		case "quest.context.entities":
 			q.ContextEntities = AsStringSlice(triple.Object)
+		case "quest.context.lessons":
+			q.InjectedLessons = AsStringSlice(triple.Object)
 
 		// Execution context
 		case PredicateQuestRepo:
@@ -420,6 +444,16 @@ func GuildFromEntityState(entity *graph.EntityState) *Guild {
 		case "guild.knowledge.lessons":
 			g.Lessons = asLessonsSlice(triple.Object)
 
+		// Treasury
+		case PredicateGuildTreasuryBalance:
+			g.Treasury = AsInt64(triple.Object)
+		case PredicateGuildTreasuryLedger:
+			g.TreasuryLedger = AsTreasuryLedger(triple.Object)
+
+		// Governance
+		case PredicateGuildProposals:
+			g.Proposals = AsGuildProposals(triple.Object)
+
 		// Routing
 		case "guild.routing.quest_type":
 			g.QuestTypes = append(g.QuestTypes, AsString(triple.Object))
@@ -535,6 +569,10 @@ func PeerReviewFromEntityState(entity *graph.EntityState) *PeerReview {
 			pr.PartyID = &partyID
 		case "review.config.solo_task":
 			pr.IsSoloTask = AsBool(triple.Object)
+		case "review.config.member_to_member":
+			pr.IsMemberToMember = AsBool(triple.Object)
+		case "review.result.aggregated":
+			pr.Aggregated = AsBool(triple.Object)
 		case "review.lifecycle.created_at":
 			pr.CreatedAt = AsTime(triple.Object)
 		case "review.lifecycle.completed_at":
@@ -561,6 +599,9 @@ func PeerReviewFromEntityState(entity *graph.EntityState) *PeerReview {
 		case "review.leader.submitted_at":
 			ensureLeaderReview(pr)
 			pr.LeaderReview.SubmittedAt = AsTime(triple.Object)
+		case "review.leader.anomaly":
+			ensureLeaderReview(pr)
+			pr.LeaderReview.Anomaly = ReviewAnomalyKind(AsString(triple.Object))
 
 		// Member review fields
 		case "review.member.q1":
@@ -578,6 +619,24 @@ func PeerReviewFromEntityState(entity *graph.EntityState) *PeerReview {
 		case "review.member.submitted_at":
 			ensureMemberReview(pr)
 			pr.MemberReview.SubmittedAt = AsTime(triple.Object)
+		case "review.member.anomaly":
+			ensureMemberReview(pr)
+			pr.MemberReview.Anomaly = ReviewAnomalyKind(AsString(triple.Object))
+		}
+	}
+
+	// Submissions may be created before the assignment and config triples
+	// are read, so settle their participants and direction afterwards.
+	if pr.LeaderReview != nil {
+		pr.LeaderReview.ReviewerID, pr.LeaderReview.RevieweeID = pr.LeaderID, pr.MemberID
+		if pr.IsMemberToMember {
+			pr.LeaderReview.Direction = ReviewDirectionMemberToMember
+		}
+	}
+	if pr.MemberReview != nil {
+		pr.MemberReview.ReviewerID, pr.MemberReview.RevieweeID = pr.MemberID, pr.LeaderID
+		if pr.IsMemberToMember {
+			pr.MemberReview.Direction = ReviewDirectionMemberToMember
 		}
 	}
 
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"slices"
 	"testing"
 	"time"
 
@@ -94,6 +95,8 @@ func TestQuestRoundTrip_FullFields(t *testing.T) {
 		MinTier:        TierJourneyman,
 		PartyRequired:  true,
 		LoopID: "quest-test-loop-abc",
+		InjectedLessons: []string{"lesson-q0-1", "lesson-q0-3"},
+		DAGDiagram:      "```mermaid\nflowchart TD\n```",
 		Constraints: QuestConstraints{
 			RequireReview: true,
 			ReviewLevel:   ReviewStrict,
@@ -152,6 +155,12 @@ func TestQuestRoundTrip_FullFields(t *testing.T) {
 	if len(r.RequiredTools) != len(original.RequiredTools) {
 		t.Errorf("RequiredTools len = %d, want %d", len(r.RequiredTools), len(original.RequiredTools))
 	}
+	if !slices.Equal(r.InjectedLessons, original.InjectedLessons) {
+		t.Errorf("InjectedLessons = %v, want %v", r.InjectedLessons, original.InjectedLessons)
+	}
+	if r.DAGDiagram != original.DAGDiagram {
+		t.Errorf("DAGDiagram = %q, want %q", r.DAGDiagram, original.DAGDiagram)
+	}
 }
 
 func TestQuestFromEntityState_NilReturnsNil(t *testing.T) {
@@ -302,6 +311,36 @@ func TestQuestRoundTrip_DAGSubQuestFields(t *testing.T) {
 	}
 }
 
+func TestQuestRoundTrip_NestedDAGFields(t *testing.T) {
+	original := &Quest{
+		ID:              QuestID("test.dev.game.board1.quest.sub2"),
+		Title:           "Decomposable Sub Quest",
+		Status:          QuestInProgress,
+		PostedAt:        time.Now().Truncate(time.Second),
+		DAGNodeID:       "node-2",
+		DAGDecomposable: true,
+		DAGLevel:        2,
+		DAGPartyID:      "test.dev.game.board1.party.sub",
+	}
+
+	entity := &graph.EntityState{
+		ID:      string(original.ID),
+		Triples: original.Triples(),
+	}
+
+	r := QuestFromEntityState(entity)
+
+	if !r.DAGDecomposable {
+		t.Error("DAGDecomposable = false, want true")
+	}
+	if r.DAGLevel != 2 {
+		t.Errorf("DAGLevel = %d, want 2", r.DAGLevel)
+	}
+	if r.DAGPartyID != original.DAGPartyID {
+		t.Errorf("DAGPartyID = %q, want %q", r.DAGPartyID, original.DAGPartyID)
+	}
+}
+
 func TestQuestRoundTrip_DAGFieldsEmpty(t *testing.T) {
 	// Quest without DAG fields should have zero values
 	original := &Quest{
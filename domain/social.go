@@ -41,6 +41,13 @@ type Guild struct {
 	SharedTools []string `json:"shared_tools"` // Tool IDs available to members
 	Lessons     []Lesson `json:"lessons,omitempty"` // Structured lessons learned from quest reviews
 
+	// Treasury - funded by member quest tax, spent by officers
+	Treasury       int64                 `json:"treasury"`
+	TreasuryLedger []TreasuryTransaction `json:"treasury_ledger,omitempty"`
+
+	// Governance - member proposals decided by rank-weighted vote
+	Proposals []GuildProposal `json:"proposals,omitempty"`
+
 	// Quest routing (clients trust certain guilds)
 	QuestTypes       []string `json:"quest_types,omitempty"` // Types of quests they handle
 	PreferredClients []string `json:"preferred_clients,omitempty"`
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// =============================================================================
+// GUILD TREASURY — Shared XP funded by member quest tax
+// =============================================================================
+// Members pay a configurable share of their quest XP into their guild's
+// treasury. Officers and the guildmaster spend it on tools for the guild's
+// shared inventory or on consumables for individual members. Every deposit
+// and withdrawal is recorded in the guild's treasury ledger.
+
+// TreasuryTxKind categorizes treasury transactions.
+type TreasuryTxKind string
+
+// Treasury transaction kinds.
+const (
+	TreasuryTxTax         TreasuryTxKind = "tax"           // Member quest XP tax (deposit)
+	TreasuryTxToolBuy     TreasuryTxKind = "tool_purchase" // Tool bought into the shared inventory
+	TreasuryTxSponsorship TreasuryTxKind = "sponsorship"   // Consumable bought for a member
+)
+
+// MaxTreasuryLedger bounds the transactions kept on the guild entity.
+const MaxTreasuryLedger = 200
+
+// ErrInsufficientTreasury is returned when a withdrawal exceeds the balance.
+var ErrInsufficientTreasury = errors.New("insufficient guild treasury")
+
+// TreasuryTransaction is one entry in a guild's treasury ledger. Amount is
+// positive for deposits and negative for spending.
+type TreasuryTransaction struct {
+	ID          string         `json:"id"`
+	Kind        TreasuryTxKind `json:"kind"`
+	Amount      int64          `json:"amount"`
+	Balance     int64          `json:"balance"`               // Treasury balance after this transaction
+	AgentID     AgentID        `json:"agent_id,omitempty"`    // Taxed member or spending officer
+	Beneficiary AgentID        `json:"beneficiary,omitempty"` // Sponsored member
+	ItemID      string         `json:"item_id,omitempty"`     // Store item bought
+	QuestID     QuestID        `json:"quest_id,omitempty"`    // Quest that was taxed
+	Note        string         `json:"note,omitempty"`
+	At          time.Time      `json:"at"`
+}
+
+// CanSpendTreasury reports whether the rank may spend the guild treasury.
+func (r GuildRank) CanSpendTreasury() bool {
+	return r == GuildRankOfficer || r == GuildRankMaster
+}
+
+// Member returns the guild member with the given ID, or nil. Matches on the
+// entity instance so short and full agent IDs are interchangeable.
+func (g *Guild) Member(agentID AgentID) *GuildMember {
+	instance := ExtractInstance(string(agentID))
+	for i := range g.Members {
+		if ExtractInstance(string(g.Members[i].AgentID)) == instance {
+			return &g.Members[i]
+		}
+	}
+	return nil
+}
+
+// Deposit adds a positive amount to the treasury and records the transaction.
+func (g *Guild) Deposit(tx TreasuryTransaction) TreasuryTransaction {
+	if tx.Amount < 0 {
+		tx.Amount = -tx.Amount
+	}
+	g.Treasury += tx.Amount
+	return g.recordTreasury(tx)
+}
+
+// Withdraw spends from the treasury and records the transaction. The amount
+// may be given with either sign; it is stored as negative.
+func (g *Guild) Withdraw(tx TreasuryTransaction) (TreasuryTransaction, error) {
+	if tx.Amount < 0 {
+		tx.Amount = -tx.Amount
+	}
+	if tx.Amount > g.Treasury {
+		return tx, ErrInsufficientTreasury
+	}
+	g.Treasury -= tx.Amount
+	tx.Amount = -tx.Amount
+	return g.recordTreasury(tx), nil
+}
+
+// HasSharedTool reports whether the tool is in the guild's shared inventory.
+func (g *Guild) HasSharedTool(toolID string) bool {
+	for _, id := range g.SharedTools {
+		if id == toolID {
+			return true
+		}
+	}
+	return false
+}
+
+// recordTreasury stamps and appends a transaction, trimming old entries.
+func (g *Guild) recordTreasury(tx TreasuryTransaction) TreasuryTransaction {
+	if tx.ID == "" {
+		tx.ID = GenerateInstance()
+	}
+	if tx.At.IsZero() {
+		tx.At = time.Now()
+	}
+	tx.Balance = g.Treasury
+	g.TreasuryLedger = append(g.TreasuryLedger, tx)
+	if overflow := len(g.TreasuryLedger) - MaxTreasuryLedger; overflow > 0 {
+		g.TreasuryLedger = g.TreasuryLedger[overflow:]
+	}
+	return tx
+}
+
+// AsTreasuryLedger converts a triple Object to []TreasuryTransaction.
+// Handles both in-process typed slices and JSON-deserialized []any from KV.
+func AsTreasuryLedger(obj any) []TreasuryTransaction {
+	if obj == nil {
+		return nil
+	}
+	if ledger, ok := obj.([]TreasuryTransaction); ok {
+		return ledger
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	var ledger []TreasuryTransaction
+	if err := json.Unmarshal(raw, &ledger); err != nil {
+		return nil
+	}
+	return ledger
+}
@@ -63,6 +63,7 @@ const (
 	ReviewDirectionLeaderToMember ReviewDirection = "leader_to_member"
 	ReviewDirectionMemberToLeader ReviewDirection = "member_to_leader"
 	ReviewDirectionDMToAgent      ReviewDirection = "dm_to_agent"
+	ReviewDirectionMemberToMember ReviewDirection = "member_to_member"
 )
 
 // =============================================================================
@@ -402,6 +403,24 @@ func (r GuildRank) GuildBonusRate() float64 {
 	}
 }
 
+// StoreDiscountRate returns the guild-wide store discount for this rank.
+// Applies to every store item for guild members, on top of any per-item
+// GuildDiscount.
+func (r GuildRank) StoreDiscountRate() float64 {
+	switch r {
+	case GuildRankMember:
+		return 0.02
+	case GuildRankVeteran:
+		return 0.04
+	case GuildRankOfficer:
+		return 0.06
+	case GuildRankMaster:
+		return 0.08
+	default:
+		return 0
+	}
+}
+
 // =============================================================================
 // TIER PERMISSIONS
 // =============================================================================
@@ -182,6 +182,9 @@ const (
 
 	// PredicatePartyLeft - Agent left a party.
 	PredicatePartyLeft = "party.membership.left"
+
+	// PredicatePartySwapped - Stalled or departed member replaced mid-quest.
+	PredicatePartySwapped = "party.membership.swapped"
 )
 
 // --- Party Coordination Predicates ---
@@ -291,6 +294,60 @@ const (
 
 	// PredicateGuildKnowledgeUpdated - Guild knowledge base updated (batch).
 	PredicateGuildKnowledgeUpdated = "guild.knowledge.updated"
+
+	// PredicateGuildLessonConfirmed - A later quest outcome confirmed guild lessons.
+	PredicateGuildLessonConfirmed = "guild.knowledge.lessonconfirmed"
+
+	// PredicateGuildLessonContradicted - A later quest outcome contradicted guild lessons.
+	PredicateGuildLessonContradicted = "guild.knowledge.lessoncontradicted"
+
+	// PredicateGuildLessonVoted - A guild member voted on a lesson.
+	PredicateGuildLessonVoted = "guild.knowledge.lessonvoted"
+)
+
+// --- Guild Treasury Predicates ---
+
+const (
+	// PredicateGuildTreasuryBalance - Guild treasury balance in XP.
+	PredicateGuildTreasuryBalance = "guild.treasury.balance"
+
+	// PredicateGuildTreasuryLedger - Guild treasury transactions (blob of []TreasuryTransaction).
+	PredicateGuildTreasuryLedger = "guild.treasury.ledger"
+
+	// PredicateGuildTreasuryDeposited - Member quest tax paid into the treasury.
+	PredicateGuildTreasuryDeposited = "guild.treasury.deposited"
+
+	// PredicateGuildTreasurySpent - Officer spent treasury XP in the store.
+	PredicateGuildTreasurySpent = "guild.treasury.spent"
+)
+
+// --- Guild Governance Predicates ---
+
+const (
+	// PredicateGuildProposals - Guild proposals and their votes (blob of []GuildProposal).
+	PredicateGuildProposals = "guild.governance.proposals"
+
+	// PredicateGuildProposalOpened - Member opened a proposal.
+	PredicateGuildProposalOpened = "guild.proposal.opened"
+
+	// PredicateGuildProposalVoted - Member voted on a proposal.
+	PredicateGuildProposalVoted = "guild.proposal.voted"
+
+	// PredicateGuildProposalPassed - Proposal passed and its decision was executed.
+	PredicateGuildProposalPassed = "guild.proposal.passed"
+
+	// PredicateGuildProposalRejected - Proposal was voted down.
+	PredicateGuildProposalRejected = "guild.proposal.rejected"
+
+	// PredicateGuildProposalExpired - Proposal deadline passed without quorum.
+	PredicateGuildProposalExpired = "guild.proposal.expired"
+)
+
+// --- Agent Memory Predicates ---
+
+const (
+	// PredicateAgentMemoryRecords - Agent's long-term quest memories (blob of []QuestMemory).
+	PredicateAgentMemoryRecords = "agent.memory.records"
 )
 
 // --- DM Session Predicates ---
@@ -581,6 +638,10 @@ func RegisterVocabulary() {
 		vocabulary.WithDescription("Agent left a party"),
 		vocabulary.WithDataType("PartyMemberPayload"),
 	)
+	vocabulary.Register(PredicatePartySwapped,
+		vocabulary.WithDescription("Party member replaced by another agent mid-quest"),
+		vocabulary.WithDataType("PartyMemberSwappedPayload"),
+	)
 
 	// Party coordination predicates
 	vocabulary.Register(PredicatePartyQuestDecomposed,
@@ -692,6 +753,57 @@ func RegisterVocabulary() {
 	vocabulary.Register(PredicateGuildKnowledgeUpdated,
 		vocabulary.WithDescription("Guild knowledge base updated with lessons from quest review"),
 	)
+	vocabulary.Register(PredicateGuildLessonConfirmed,
+		vocabulary.WithDescription("Later quest outcome confirmed guild lessons shown to the agent"),
+	)
+	vocabulary.Register(PredicateGuildLessonContradicted,
+		vocabulary.WithDescription("Later quest outcome contradicted guild lessons shown to the agent"),
+	)
+	vocabulary.Register(PredicateGuildLessonVoted,
+		vocabulary.WithDescription("Guild member up- or down-voted a lesson"),
+	)
+
+	// Guild treasury predicates
+	vocabulary.Register(PredicateGuildTreasuryBalance,
+		vocabulary.WithDescription("Guild treasury balance in XP"),
+	)
+	vocabulary.Register(PredicateGuildTreasuryLedger,
+		vocabulary.WithDescription("Guild treasury deposits and spending"),
+		vocabulary.WithDataType("TreasuryTransaction"),
+	)
+	vocabulary.Register(PredicateGuildTreasuryDeposited,
+		vocabulary.WithDescription("Member quest tax paid into the guild treasury"),
+	)
+	vocabulary.Register(PredicateGuildTreasurySpent,
+		vocabulary.WithDescription("Officer spent guild treasury XP in the store"),
+	)
+
+	// Guild governance predicates
+	vocabulary.Register(PredicateGuildProposals,
+		vocabulary.WithDescription("Guild proposals and their votes"),
+		vocabulary.WithDataType("GuildProposal"),
+	)
+	vocabulary.Register(PredicateGuildProposalOpened,
+		vocabulary.WithDescription("Guild member opened a proposal"),
+	)
+	vocabulary.Register(PredicateGuildProposalVoted,
+		vocabulary.WithDescription("Guild member voted on a proposal"),
+	)
+	vocabulary.Register(PredicateGuildProposalPassed,
+		vocabulary.WithDescription("Guild proposal passed and was executed"),
+	)
+	vocabulary.Register(PredicateGuildProposalRejected,
+		vocabulary.WithDescription("Guild proposal was voted down"),
+	)
+	vocabulary.Register(PredicateGuildProposalExpired,
+		vocabulary.WithDescription("Guild proposal expired without quorum"),
+	)
+
+	// Agent memory predicates
+	vocabulary.Register(PredicateAgentMemoryRecords,
+		vocabulary.WithDescription("Agent's long-term memories of past quests"),
+		vocabulary.WithDataType("QuestMemory"),
+	)
 
 	// DM session predicates
 	vocabulary.Register(PredicateSessionStart,
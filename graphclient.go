@@ -4,6 +4,7 @@ package semdragons
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -243,6 +244,47 @@ func (gc *GraphClient) GetGuild(ctx context.Context, guildID domain.GuildID) (*g
 	return gc.GetEntityDirect(ctx, entityID)
 }
 
+// GetGuildWithRevision retrieves a guild and its KV revision for CAS operations.
+func (gc *GraphClient) GetGuildWithRevision(ctx context.Context, guildID domain.GuildID) (*graph.EntityState, uint64, error) {
+	instance := domain.ExtractInstance(string(guildID))
+	entityID := gc.config.GuildEntityID(instance)
+	return gc.GetEntityDirectWithRevision(ctx, entityID)
+}
+
+// guildCASRetries bounds UpdateGuild's read-modify-write attempts.
+const guildCASRetries = 5
+
+// UpdateGuild applies mutate to the current guild and writes it with CAS,
+// re-reading and retrying when another writer changed the guild in between.
+// Guilds are written by several processors (quest tax, treasury purchases,
+// governance), so last-writer-wins updates would drop each other's changes.
+//
+// mutate may run more than once, each time on a freshly read guild. An error
+// from mutate aborts the update and is returned unchanged.
+func (gc *GraphClient) UpdateGuild(ctx context.Context, guildID domain.GuildID, eventType string, mutate func(*domain.Guild) error) (*domain.Guild, error) {
+	for range guildCASRetries {
+		entity, revision, err := gc.GetGuildWithRevision(ctx, guildID)
+		if err != nil {
+			return nil, err
+		}
+		guild := domain.GuildFromEntityState(entity)
+		if guild == nil {
+			return nil, fmt.Errorf("reconstruct guild %s from entity state", guildID)
+		}
+		if err := mutate(guild); err != nil {
+			return nil, err
+		}
+		err = gc.EmitEntityCAS(ctx, guild, eventType, revision)
+		if err == nil {
+			return guild, nil
+		}
+		if !errors.Is(err, natsclient.ErrKVRevisionMismatch) {
+			return nil, fmt.Errorf("CAS write guild: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("guild %s update exhausted %d CAS retries", guildID, guildCASRetries)
+}
+
 // GetBattle retrieves a battle by its battle ID (instance portion).
 func (gc *GraphClient) GetBattle(ctx context.Context, battleID domain.BattleID) (*graph.EntityState, error) {
 	instance := domain.ExtractInstance(string(battleID))
@@ -273,10 +315,34 @@ func (gc *GraphClient) ListPeerReviewsByPrefix(ctx context.Context, limit int) (
 	return gc.ListEntitiesByType(ctx, domain.EntityTypePeerReview, limit)
 }
 
+// GetBlueprint retrieves a DAG blueprint by its blueprint ID (instance portion).
+func (gc *GraphClient) GetBlueprint(ctx context.Context, blueprintID string) (*graph.EntityState, error) {
+	entityID := gc.config.BlueprintEntityID(blueprintID)
+	return gc.GetEntityDirect(ctx, entityID)
+}
+
+// GetBlueprintWithRevision retrieves a DAG blueprint along with its KV
+// revision for use with EmitEntityCAS.
+func (gc *GraphClient) GetBlueprintWithRevision(ctx context.Context, blueprintID string) (*graph.EntityState, uint64, error) {
+	return gc.GetEntityDirectWithRevision(ctx, gc.config.BlueprintEntityID(blueprintID))
+}
+
+// ListBlueprintsByPrefix retrieves all DAG blueprints on this board from KV.
+func (gc *GraphClient) ListBlueprintsByPrefix(ctx context.Context, limit int) ([]graph.EntityState, error) {
+	return gc.ListEntitiesByType(ctx, domain.EntityTypeBlueprint, limit)
+}
+
 // ListEntitiesByType retrieves all entities of a given type directly from the
 // ENTITY_STATES KV bucket. This reads from the source of truth without requiring
 // the graph-ingest query service to be running.
 func (gc *GraphClient) ListEntitiesByType(ctx context.Context, entityType string, limit int) ([]graph.EntityState, error) {
+	return gc.ListEntitiesByIDPrefix(ctx, gc.config.TypePrefix(entityType)+".", limit)
+}
+
+// ListEntitiesByIDPrefix retrieves up to limit entities whose IDs start with
+// prefix, such as entities outside the board namespace (web content persisted
+// by agent tools). A limit of zero or less means 100.
+func (gc *GraphClient) ListEntitiesByIDPrefix(ctx context.Context, prefix string, limit int) ([]graph.EntityState, error) {
 	if limit <= 0 {
 		limit = 100
 	}
@@ -286,10 +352,9 @@ func (gc *GraphClient) ListEntitiesByType(ctx context.Context, entityType string
 		return nil, err
 	}
 
-	prefix := gc.config.TypePrefix(entityType) + "."
 	keys, err := store.KeysByPrefix(ctx, prefix)
 	if err != nil {
-		return nil, fmt.Errorf("list keys for %s: %w", entityType, err)
+		return nil, fmt.Errorf("list keys for %s: %w", prefix, err)
 	}
 
 	entities := make([]graph.EntityState, 0, len(keys))
@@ -0,0 +1,49 @@
+// Package realpath resolves where a path really lives on disk, following
+// symlinks even when the path does not exist yet. The executor's local file
+// tools and the sandbox server both use it to keep file access inside a
+// workspace.
+package realpath
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// maxSymlinkHops bounds how many dangling symlinks Of follows.
+const maxSymlinkHops = 40
+
+// Of resolves symlinks in the longest existing prefix of path and appends
+// the rest, so a path that does not exist yet is checked where it would be
+// created. Dangling symlinks are followed by hand; otherwise a write through
+// one would create its target wherever it points.
+func Of(path string) (string, error) {
+	var rest []string
+	hops := 0
+	for p := path; ; {
+		resolved, err := filepath.EvalSymlinks(p)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		if target, linkErr := os.Readlink(p); linkErr == nil {
+			if hops++; hops > maxSymlinkHops {
+				return "", errors.New("too many levels of symbolic links")
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(p), target)
+			}
+			p = target
+			continue
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return "", err
+		}
+		rest = append([]string{filepath.Base(p)}, rest...)
+		p = parent
+	}
+}
@@ -0,0 +1,56 @@
+package realpath
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	t.Parallel()
+
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(base, "root")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{root, outside} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustSymlink(t, outside, filepath.Join(root, "dirlink"))
+	mustSymlink(t, filepath.Join(outside, "missing.txt"), filepath.Join(root, "dangling"))
+	mustSymlink(t, "dangling", filepath.Join(root, "chain"))
+	mustSymlink(t, "loop", filepath.Join(root, "loop"))
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"existing root", root, root},
+		{"new file", filepath.Join(root, "a", "b.txt"), filepath.Join(root, "a", "b.txt")},
+		{"new file under dir symlink", filepath.Join(root, "dirlink", "new.txt"), filepath.Join(outside, "new.txt")},
+		{"dangling symlink", filepath.Join(root, "dangling"), filepath.Join(outside, "missing.txt")},
+		{"chain to dangling symlink", filepath.Join(root, "chain"), filepath.Join(outside, "missing.txt")},
+	}
+	for _, tt := range tests {
+		got, err := Of(tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: Of() = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := Of(filepath.Join(root, "loop")); err == nil {
+		t.Error("Of() on a symlink loop succeeded, want error")
+	}
+}
+
+func mustSymlink(t *testing.T, target, link string) {
+	t.Helper()
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -56,6 +56,9 @@ type Agent struct {
 	TotalSpent    int64                `json:"total_spent"`
 	ActiveEffects []AgentEffect        `json:"active_effects,omitempty"`
 
+	// Long-term memory of past quests (stored as a single blob triple)
+	Memories []domain.QuestMemory `json:"memories,omitempty"`
+
 	// Stats
 	Stats AgentStats `json:"stats"`
 
@@ -104,6 +107,16 @@ type AgentStats struct {
 	PeerReviewQ2Avg  float64 `json:"peer_review_q2_avg"`
 	PeerReviewQ3Avg  float64 `json:"peer_review_q3_avg"`
 	PeerReviewCount  int     `json:"peer_review_count"`
+
+	// 360 reviews from party peers, weighted by reviewer level and
+	// calibration. Peer360Avg feeds XPContext.PeerReviewScore on party quests.
+	Peer360Avg   float64 `json:"peer_360_avg"`
+	Peer360Count int     `json:"peer_360_count"`
+	// How closely this agent's own peer ratings match consensus (0–1), and
+	// how many of them were flagged as collusion or retaliation.
+	ReviewCalibration        float64 `json:"review_calibration"`
+	ReviewCalibrationSamples int     `json:"review_calibration_samples"`
+	ReviewAnomalies          int     `json:"review_anomalies"`
 }
 
 // OwnedTool tracks an agent's purchased tool (stored as agent entity triples).
@@ -116,9 +129,10 @@ type OwnedTool struct {
 
 // AgentEffect tracks a consumable effect currently active on an agent.
 type AgentEffect struct {
-	EffectType      string            `json:"effect_type"`        // xp_boost, quality_shield, etc.
-	QuestsRemaining int               `json:"quests_remaining"`   // Quests until effect expires
-	QuestID         *domain.QuestID   `json:"quest_id,omitempty"` // Quest that triggered the effect
+	EffectType      string            `json:"effect_type"`         // xp_boost, quality_shield, etc.
+	QuestsRemaining int               `json:"quests_remaining"`    // Quests until effect expires
+	Magnitude       float64           `json:"magnitude,omitempty"` // Item-defined strength (0 = handler default)
+	QuestID         *domain.QuestID   `json:"quest_id,omitempty"`  // Quest that triggered the effect
 }
 
 // =============================================================================
@@ -134,6 +148,13 @@ func (a *Agent) HasSkill(skill domain.SkillTag) bool {
 	return exists
 }
 
+// OwnsTool reports whether the agent holds a usable purchase of the store
+// item: permanent (-1) or a rental with uses left.
+func (a *Agent) OwnsTool(itemID string) bool {
+	tool, ok := a.OwnedTools[itemID]
+	return ok && tool.UsesRemaining != 0
+}
+
 // GetProficiency returns the proficiency for a skill.
 func (a *Agent) GetProficiency(skill domain.SkillTag) domain.SkillProficiency {
 	if a.SkillProficiencies != nil {
@@ -304,6 +325,12 @@ func (a *Agent) Triples() []message.Triple {
 			Subject: entityID, Predicate: prefix + ".remaining", Object: eff.QuestsRemaining,
 			Source: source, Timestamp: now, Confidence: 1.0,
 		})
+		if eff.Magnitude != 0 {
+			triples = append(triples, message.Triple{
+				Subject: entityID, Predicate: prefix + ".magnitude", Object: eff.Magnitude,
+				Source: source, Timestamp: now, Confidence: 1.0,
+			})
+		}
 		if eff.QuestID != nil {
 			triples = append(triples, message.Triple{
 				Subject: entityID, Predicate: prefix + ".quest", Object: string(*eff.QuestID),
@@ -322,6 +349,32 @@ func (a *Agent) Triples() []message.Triple {
 			message.Triple{Subject: entityID, Predicate: "agent.reputation.peer_count", Object: a.Stats.PeerReviewCount, Source: source, Timestamp: now, Confidence: 1.0},
 		)
 	}
+	if a.Stats.Peer360Count > 0 {
+		triples = append(triples,
+			message.Triple{Subject: entityID, Predicate: "agent.reputation.peer_360_avg", Object: a.Stats.Peer360Avg, Source: source, Timestamp: now, Confidence: 1.0},
+			message.Triple{Subject: entityID, Predicate: "agent.reputation.peer_360_count", Object: a.Stats.Peer360Count, Source: source, Timestamp: now, Confidence: 1.0},
+		)
+	}
+	if a.Stats.ReviewCalibrationSamples > 0 {
+		triples = append(triples,
+			message.Triple{Subject: entityID, Predicate: "agent.reputation.review_calibration", Object: a.Stats.ReviewCalibration, Source: source, Timestamp: now, Confidence: 1.0},
+			message.Triple{Subject: entityID, Predicate: "agent.reputation.review_calibration_samples", Object: a.Stats.ReviewCalibrationSamples, Source: source, Timestamp: now, Confidence: 1.0},
+		)
+	}
+	if a.Stats.ReviewAnomalies > 0 {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "agent.reputation.review_anomalies", Object: a.Stats.ReviewAnomalies,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+
+	// Quest memories (stored as blob, like guild lessons)
+	if len(a.Memories) > 0 {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: domain.PredicateAgentMemoryRecords, Object: a.Memories,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
 
 	return triples
 }
@@ -415,6 +468,20 @@ func AgentFromEntityState(entity *graph.EntityState) *Agent {
 			a.Stats.PeerReviewQ3Avg = domain.AsFloat64(triple.Object)
 		case "agent.reputation.peer_count":
 			a.Stats.PeerReviewCount = domain.AsInt(triple.Object)
+		case "agent.reputation.peer_360_avg":
+			a.Stats.Peer360Avg = domain.AsFloat64(triple.Object)
+		case "agent.reputation.peer_360_count":
+			a.Stats.Peer360Count = domain.AsInt(triple.Object)
+		case "agent.reputation.review_calibration":
+			a.Stats.ReviewCalibration = domain.AsFloat64(triple.Object)
+		case "agent.reputation.review_calibration_samples":
+			a.Stats.ReviewCalibrationSamples = domain.AsInt(triple.Object)
+		case "agent.reputation.review_anomalies":
+			a.Stats.ReviewAnomalies = domain.AsInt(triple.Object)
+
+		// Quest memories
+		case domain.PredicateAgentMemoryRecords:
+			a.Memories = domain.AsQuestMemories(triple.Object)
 		}
 
 		// Handle skill proficiencies (dynamic predicates)
@@ -498,6 +565,8 @@ func AgentFromEntityState(entity *graph.EntityState) *Agent {
 				switch suffix {
 				case "remaining":
 					a.ActiveEffects[idx].QuestsRemaining = domain.AsInt(triple.Object)
+				case "magnitude":
+					a.ActiveEffects[idx].Magnitude = domain.AsFloat64(triple.Object)
 				case "quest":
 					questID := domain.QuestID(domain.AsString(triple.Object))
 					a.ActiveEffects[idx].QuestID = &questID
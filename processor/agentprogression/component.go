@@ -65,6 +65,10 @@ type Component struct {
 	// prevent lost writes.
 	agentLocks sync.Map // map[string]*sync.Mutex
 
+	// aggregatedParties guards against aggregating a party's 360 reviews
+	// twice when its last reviews complete concurrently.
+	aggregatedParties sync.Map // map[domain.PartyID]struct{}
+
 	// Internal state
 	running  atomic.Bool
 	mu       sync.RWMutex
@@ -192,6 +196,25 @@ func (c *Component) ConfigSchema() component.ConfigSchema {
 				Minimum:     util.IntPtr(1),
 				Category:    "advanced",
 			},
+			"enable_quest_memory": {
+				Type:        "bool",
+				Description: "Record a compact memory on the agent for each completed or failed quest",
+				Default:     true,
+				Category:    "advanced",
+			},
+			"max_memories": {
+				Type:        "int",
+				Description: "Maximum quest memories retained per agent (oldest evicted first)",
+				Default:     DefaultMaxMemories,
+				Minimum:     util.IntPtr(0),
+				Category:    "advanced",
+			},
+			"guild_tax_rate": {
+				Type:        "float",
+				Description: "Share of each guild member's quest XP paid into the guild treasury (0 disables)",
+				Default:     0.05,
+				Category:    "advanced",
+			},
 		},
 		Required: []string{"org", "platform", "board"},
 	}
@@ -20,6 +20,13 @@ type Config struct {
 	RetryPenaltyRate   float64 `json:"retry_penalty_rate" schema:"type:float,description:XP penalty per retry attempt"`
 	FailurePenaltyRate float64 `json:"failure_penalty_rate" schema:"type:float,description:XP penalty for failures"`
 	LevelDownThreshold int     `json:"level_down_threshold" schema:"type:int,description:Consecutive failures for demotion"`
+
+	// Quest memory — compact per-agent records of finished quests
+	EnableQuestMemory bool `json:"enable_quest_memory" schema:"type:bool,description:Record quest memories on agents (default true)"`
+	MaxMemories       int  `json:"max_memories" schema:"type:int,description:Maximum memories retained per agent"`
+
+	// Guild treasury — share of each member's quest XP paid to their guild
+	GuildTaxRate float64 `json:"guild_tax_rate" schema:"type:float,description:Share of quest XP paid into the member's guild treasury (0-1)"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults.
@@ -34,6 +41,9 @@ func DefaultConfig() Config {
 		RetryPenaltyRate:   0.25,
 		FailurePenaltyRate: 0.5,
 		LevelDownThreshold: 3,
+		EnableQuestMemory:  true,
+		MaxMemories:        DefaultMaxMemories,
+		GuildTaxRate:       0.05,
 	}
 }
 
@@ -78,5 +88,11 @@ func (c *Config) Validate() error {
 	if c.LevelDownThreshold < 1 {
 		return errors.New("level_down_threshold must be at least 1")
 	}
+	if c.MaxMemories < 0 {
+		return errors.New("max_memories must be non-negative")
+	}
+	if c.GuildTaxRate < 0 || c.GuildTaxRate >= 1 {
+		return errors.New("guild_tax_rate must be in [0, 1)")
+	}
 	return nil
 }
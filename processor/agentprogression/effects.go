@@ -0,0 +1,113 @@
+package agentprogression
+
+import (
+	"github.com/c360studio/semdragons/domain"
+)
+
+// =============================================================================
+// ACTIVE EFFECTS - Applying registered effect hooks to an agent
+// =============================================================================
+// Effects are stored on the agent as AgentEffect entries; their behavior comes
+// from the handler registered for the effect type (domain.LookupEffect).
+// These helpers are shared by every processor that owns a hook point.
+// =============================================================================
+
+// AddEffect activates an effect, stacking duration onto an existing effect of
+// the same type (effects are keyed by type in the entity triples).
+func (a *Agent) AddEffect(eff AgentEffect) {
+	for i := range a.ActiveEffects {
+		if a.ActiveEffects[i].EffectType == eff.EffectType {
+			a.ActiveEffects[i].QuestsRemaining += eff.QuestsRemaining
+			if eff.Magnitude > a.ActiveEffects[i].Magnitude {
+				a.ActiveEffects[i].Magnitude = eff.Magnitude
+			}
+			if eff.QuestID != nil {
+				a.ActiveEffects[i].QuestID = eff.QuestID
+			}
+			return
+		}
+	}
+	a.ActiveEffects = append(a.ActiveEffects, eff)
+}
+
+// EffectXPMultiplier returns the combined XP multiplier from active effects.
+func (a *Agent) EffectXPMultiplier(quest *domain.Quest) float64 {
+	mult := 1.0
+	a.eachEffect(func(eff AgentEffect, h *domain.EffectHandler) {
+		if h.XPMultiplier != nil {
+			mult *= h.XPMultiplier(domain.EffectContext{Magnitude: eff.Magnitude, Quest: quest})
+		}
+	})
+	return mult
+}
+
+// EffectPromptInjections returns prompt text contributed by active effects.
+func (a *Agent) EffectPromptInjections(quest *domain.Quest) []string {
+	var out []string
+	a.eachEffect(func(eff AgentEffect, h *domain.EffectHandler) {
+		if h.PromptInjection == nil {
+			return
+		}
+		if text := h.PromptInjection(domain.EffectContext{Magnitude: eff.Magnitude, Quest: quest}); text != "" {
+			out = append(out, text)
+		}
+	})
+	return out
+}
+
+// AdjustJudgement runs judge adjustment hooks over boss battle results and
+// returns the adjusted results plus the effect types that took part.
+func (a *Agent) AdjustJudgement(quest *domain.Quest, criteria []domain.ReviewCriterion, results []domain.ReviewResult) ([]domain.ReviewResult, []string) {
+	var applied []string
+	a.eachEffect(func(eff AgentEffect, h *domain.EffectHandler) {
+		if h.AdjustJudgement == nil {
+			return
+		}
+		results = h.AdjustJudgement(domain.EffectContext{Magnitude: eff.Magnitude, Quest: quest}, criteria, results)
+		applied = append(applied, eff.EffectType)
+	})
+	return results, applied
+}
+
+// HasRetryGrant reports whether an active effect converts a terminal failure
+// into another attempt.
+func (a *Agent) HasRetryGrant() bool {
+	granted := false
+	a.eachEffect(func(_ AgentEffect, h *domain.EffectHandler) {
+		granted = granted || h.GrantsRetry
+	})
+	return granted
+}
+
+// ConsumeEffects counts down every active effect consumed on trigger, removing
+// those that run out. Returns the effect types that were counted down.
+func (a *Agent) ConsumeEffects(trigger domain.EffectTrigger) []string {
+	var consumed []string
+	kept := a.ActiveEffects[:0]
+	for _, eff := range a.ActiveEffects {
+		h, ok := domain.LookupEffect(eff.EffectType)
+		if ok && h.ConsumedOn == trigger {
+			consumed = append(consumed, eff.EffectType)
+			eff.QuestsRemaining--
+			if eff.QuestsRemaining <= 0 {
+				continue
+			}
+		}
+		kept = append(kept, eff)
+	}
+	a.ActiveEffects = kept
+	return consumed
+}
+
+// eachEffect calls fn for each active effect with a registered handler.
+// Effects of unknown type are skipped (e.g., removed from the registry).
+func (a *Agent) eachEffect(fn func(AgentEffect, *domain.EffectHandler)) {
+	for _, eff := range a.ActiveEffects {
+		if eff.QuestsRemaining <= 0 {
+			continue
+		}
+		if h, ok := domain.LookupEffect(eff.EffectType); ok {
+			fn(eff, h)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package agentprogression
+
+import (
+	"testing"
+
+	"github.com/c360studio/semdragons/domain"
+)
+
+// =============================================================================
+// ACTIVE EFFECT UNIT TESTS
+// =============================================================================
+
+func TestAddEffect_StacksSameType(t *testing.T) {
+	a := &Agent{}
+	a.AddEffect(AgentEffect{EffectType: domain.EffectXPBoost, QuestsRemaining: 1, Magnitude: 1.5})
+	a.AddEffect(AgentEffect{EffectType: domain.EffectXPBoost, QuestsRemaining: 2, Magnitude: 2})
+
+	if len(a.ActiveEffects) != 1 {
+		t.Fatalf("effects = %d, want 1", len(a.ActiveEffects))
+	}
+	if got := a.ActiveEffects[0]; got.QuestsRemaining != 3 || got.Magnitude != 2 {
+		t.Errorf("stacked effect = %+v, want 3 quests at magnitude 2", got)
+	}
+}
+
+func TestEffectXPMultiplier(t *testing.T) {
+	a := &Agent{}
+	if got := a.EffectXPMultiplier(nil); got != 1.0 {
+		t.Errorf("no effects multiplier = %v, want 1", got)
+	}
+	a.ActiveEffects = []AgentEffect{{EffectType: domain.EffectXPBoost, QuestsRemaining: 1}}
+	if got := a.EffectXPMultiplier(nil); got != 2.0 {
+		t.Errorf("xp_boost multiplier = %v, want 2", got)
+	}
+}
+
+func TestEffectPromptInjections(t *testing.T) {
+	a := &Agent{ActiveEffects: []AgentEffect{
+		{EffectType: domain.EffectInsightScroll, QuestsRemaining: 2},
+		{EffectType: domain.EffectXPBoost, QuestsRemaining: 1},
+		{EffectType: "unregistered", QuestsRemaining: 1},
+	}}
+	got := a.EffectPromptInjections(&domain.Quest{Difficulty: domain.DifficultyEasy})
+	if len(got) != 1 {
+		t.Fatalf("injections = %d, want 1 (insight scroll only)", len(got))
+	}
+}
+
+func TestAdjustJudgement_ReportsAppliedEffects(t *testing.T) {
+	a := &Agent{ActiveEffects: []AgentEffect{{EffectType: domain.EffectQualityShield, QuestsRemaining: 1}}}
+	criteria := []domain.ReviewCriterion{{Name: "correctness", Threshold: 0.7}}
+	results := []domain.ReviewResult{{CriterionName: "correctness", Score: 0.6}}
+
+	adjusted, applied := a.AdjustJudgement(nil, criteria, results)
+	if len(applied) != 1 || applied[0] != domain.EffectQualityShield {
+		t.Errorf("applied = %v, want [quality_shield]", applied)
+	}
+	if !adjusted[0].Passed {
+		t.Error("shield should forgive the only failed criterion")
+	}
+}
+
+func TestConsumeEffects_ByTrigger(t *testing.T) {
+	a := &Agent{ActiveEffects: []AgentEffect{
+		{EffectType: domain.EffectRetryToken, QuestsRemaining: 1},
+		{EffectType: domain.EffectXPBoost, QuestsRemaining: 2},
+		{EffectType: domain.EffectInsightScroll, QuestsRemaining: 1},
+	}}
+	if !a.HasRetryGrant() {
+		t.Fatal("retry token should grant a retry")
+	}
+
+	consumed := a.ConsumeEffects(domain.EffectOnCompletion)
+	if len(consumed) != 1 || consumed[0] != domain.EffectXPBoost {
+		t.Errorf("consumed = %v, want [xp_boost]", consumed)
+	}
+	a.ConsumeEffects(domain.EffectOnQuestEnd)
+	a.ConsumeEffects(domain.EffectOnFailure)
+
+	if len(a.ActiveEffects) != 1 || a.ActiveEffects[0].EffectType != domain.EffectXPBoost || a.ActiveEffects[0].QuestsRemaining != 1 {
+		t.Errorf("remaining effects = %+v, want xp_boost with 1 quest", a.ActiveEffects)
+	}
+	if a.HasRetryGrant() {
+		t.Error("retry token should be spent")
+	}
+}
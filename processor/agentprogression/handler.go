@@ -2,6 +2,7 @@ package agentprogression
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -188,9 +189,31 @@ func (c *Component) handleQuestCompletedFromKV(entityState *semgraph.EntityState
 		xpCtx.BattleResult = quest.Verdict
 	}
 
+	// Party work is scaled by what the agent's peers said in 360 reviews.
+	if quest.PartyID != nil && fullAgent.Stats.Peer360Count > 0 {
+		score := fullAgent.Stats.Peer360Avg
+		xpCtx.PeerReviewScore = &score
+	}
+
 	// Calculate XP - pure function
 	award := c.xpEngine.CalculateXP(xpCtx)
 
+	// Apply XP multipliers from active consumable effects (e.g., xp_boost)
+	if mult := fullAgent.EffectXPMultiplier(quest); mult != 1.0 {
+		award.TotalXP = int64(float64(award.TotalXP) * mult)
+		award.Breakdown += fmt.Sprintf(" | effect multiplier x%.2f", mult)
+	}
+
+	// Guild tax: a share of the award funds the agent's guild treasury
+	taxGuild := c.loadTaxGuild(ctx, fullAgent, guildForStats)
+	if taxGuild != nil {
+		if tax := GuildTax(award.TotalXP, c.config.GuildTaxRate); tax > 0 {
+			award.TotalXP -= tax
+			award.GuildTax = tax
+			award.Breakdown += fmt.Sprintf(" | guild tax -%d", tax)
+		}
+	}
+
 	// Apply XP to temp agent for level calculation
 	tempAgent := &Agent{
 		Level:     fullAgent.Level,
@@ -208,7 +231,13 @@ func (c *Component) handleQuestCompletedFromKV(entityState *semgraph.EntityState
 	fullAgent.Status = domain.AgentIdle
 	fullAgent.CurrentQuest = nil
 	fullAgent.Stats.QuestsCompleted++
+	if earned := award.TotalXP + award.GuildTax; earned > 0 {
+		fullAgent.Stats.TotalXPEarned += earned
+	}
+	fullAgent.ConsumeEffects(domain.EffectOnCompletion)
+	fullAgent.ConsumeEffects(domain.EffectOnQuestEnd)
 	fullAgent.UpdatedAt = time.Now()
+	c.recordQuestMemory(fullAgent, entityState, domain.MemoryOutcomeSuccess)
 
 	// Write full agent entity (preserves name, skills, guilds, etc.)
 	if err := c.graph.EmitEntityUpdate(ctx, fullAgent, "agent.progression.xp"); err != nil {
@@ -216,9 +245,14 @@ func (c *Component) handleQuestCompletedFromKV(entityState *semgraph.EntityState
 		c.errorsCount.Add(1)
 	}
 
+	// Pay the tax into the treasury.
+	if award.GuildTax > 0 {
+		c.depositGuildTax(ctx, taxGuild.ID, domain.AgentID(agentID), quest.ID, award.GuildTax)
+	}
+
 	// Update guild reputation and member contribution on quest completion (B2)
 	if isGuildQuest && guildForStats != nil {
-		c.updateGuildStatsOnCompletion(ctx, guildForStats, domain.AgentID(agentID), award.TotalXP)
+		c.updateGuildStatsOnCompletion(ctx, guildForStats.ID, domain.AgentID(agentID), award.TotalXP)
 	}
 
 	// Emit level up event if applicable
@@ -342,7 +376,9 @@ func (c *Component) handleQuestFailedFromKV(entityState *semgraph.EntityState) {
 	}
 	fullAgent.CurrentQuest = nil
 	fullAgent.Stats.QuestsFailed++
+	fullAgent.ConsumeEffects(domain.EffectOnQuestEnd)
 	fullAgent.UpdatedAt = time.Now()
+	c.recordQuestMemory(fullAgent, entityState, domain.MemoryOutcomeFailure)
 
 	// Write full agent entity (preserves name, skills, guilds, etc.)
 	if err := c.graph.EmitEntityUpdate(ctx, fullAgent, "agent.progression.xp"); err != nil {
@@ -352,13 +388,7 @@ func (c *Component) handleQuestFailedFromKV(entityState *semgraph.EntityState) {
 
 	// Update guild stats on quest failure (B2)
 	if quest.GuildPriority != nil {
-		guildEntity, guildErr := c.graph.GetGuild(ctx, domain.GuildID(*quest.GuildPriority))
-		if guildErr == nil {
-			guild := domain.GuildFromEntityState(guildEntity)
-			if guild != nil {
-				c.updateGuildStatsOnFailure(ctx, guild)
-			}
-		}
+		c.updateGuildStatsOnFailure(ctx, domain.GuildID(*quest.GuildPriority))
 	}
 
 	c.logger.Debug("processed quest failure",
@@ -372,6 +402,20 @@ func (c *Component) handleQuestFailedFromKV(entityState *semgraph.EntityState) {
 	c.releaseOrphanedAgents(ctx, quest.ID, domain.AgentID(agentID))
 }
 
+// recordQuestMemory appends a memory of the terminal quest to the agent.
+// Uses the full quest reconstruction since the memory needs output, verdict
+// feedback, skills and repo, which the minimal XP decoder skips.
+func (c *Component) recordQuestMemory(agent *Agent, entityState *semgraph.EntityState, outcome domain.MemoryOutcome) {
+	if !c.config.EnableQuestMemory {
+		return
+	}
+	quest := domain.QuestFromEntityState(entityState)
+	if quest == nil || quest.Title == "" {
+		return
+	}
+	agent.RecordMemory(BuildQuestMemory(quest, outcome, time.Now()), c.config.MaxMemories)
+}
+
 // releaseOrphanedAgents finds agents stuck in on_quest for a completed/failed quest
 // and releases them back to idle. This handles agents that lost the CAS claim race
 // but still set their own status to on_quest before the race was resolved.
@@ -454,6 +498,11 @@ func questFromEntityStateTriples(entity *semgraph.EntityState) *domain.Quest {
 				agentID := domain.AgentID(v)
 				quest.ClaimedBy = &agentID
 			}
+		case "quest.assignment.party":
+			if v, ok := triple.Object.(string); ok {
+				partyID := domain.PartyID(v)
+				quest.PartyID = &partyID
+			}
 		case "quest.priority.guild":
 			if v, ok := triple.Object.(string); ok {
 				guildID := domain.GuildID(v)
@@ -580,6 +629,14 @@ func (c *Component) handlePeerReviewStateChange(entry jetstream.KeyValueEntry) {
 		return
 	}
 
+	// Member-to-member reviews are aggregated per party, not one at a time.
+	if review.IsMemberToMember {
+		if !review.Aggregated && review.PartyID != nil {
+			c.aggregatePartyReviews(*review.PartyID)
+		}
+		return
+	}
+
 	// Only react to leader-to-member reviews; DM-to-agent or member-to-leader
 	// reviews use different rating scales and do not feed member reputation.
 	if review.LeaderAvgRating == 0 {
@@ -673,28 +730,30 @@ func (c *Component) BoardConfig() *domain.BoardConfig {
 // =============================================================================
 
 // updateGuildStatsOnCompletion increments guild reputation and member contribution
-// when a guild quest is completed successfully.
-func (c *Component) updateGuildStatsOnCompletion(ctx context.Context, guild *domain.Guild, agentID domain.AgentID, xpEarned int64) {
-	guild.QuestsHandled++
-	if guild.QuestsHandled > 0 {
-		guild.SuccessRate = float64(guild.QuestsHandled-guild.QuestsFailed) / float64(guild.QuestsHandled)
-	}
+// when a guild quest is completed successfully. The update is a CAS write of
+// the current guild so it does not overwrite concurrent treasury changes.
+func (c *Component) updateGuildStatsOnCompletion(ctx context.Context, guildID domain.GuildID, agentID domain.AgentID, xpEarned int64) {
+	_, err := c.graph.UpdateGuild(ctx, guildID, "guild.stats.updated", func(guild *domain.Guild) error {
+		guild.QuestsHandled++
+		if guild.QuestsHandled > 0 {
+			guild.SuccessRate = float64(guild.QuestsHandled-guild.QuestsFailed) / float64(guild.QuestsHandled)
+		}
 
-	// Nudge reputation toward success rate (weighted average favoring recent performance)
-	guild.Reputation = guild.Reputation*0.9 + guild.SuccessRate*0.1
+		// Nudge reputation toward success rate (weighted average favoring recent performance)
+		guild.Reputation = guild.Reputation*0.9 + guild.SuccessRate*0.1
 
-	// Update member contribution
-	for i := range guild.Members {
-		if guild.Members[i].AgentID == agentID {
-			guild.Members[i].Contribution += float64(xpEarned)
-			break
+		// Update member contribution
+		for i := range guild.Members {
+			if guild.Members[i].AgentID == agentID {
+				guild.Members[i].Contribution += float64(xpEarned)
+				break
+			}
 		}
-	}
-
-	// Persist updated guild to KV
-	if err := c.graph.EmitEntity(ctx, guild, "guild.stats.updated"); err != nil {
+		return nil
+	})
+	if err != nil {
 		c.logger.Error("failed to persist guild stats update",
-			"guild_id", guild.ID,
+			"guild_id", guildID,
 			"error", err)
 		c.errorsCount.Add(1)
 	}
@@ -702,20 +761,21 @@ func (c *Component) updateGuildStatsOnCompletion(ctx context.Context, guild *dom
 
 // updateGuildStatsOnFailure increments failure counters and recalculates success rate
 // when a guild quest fails.
-func (c *Component) updateGuildStatsOnFailure(ctx context.Context, guild *domain.Guild) {
-	guild.QuestsHandled++
-	guild.QuestsFailed++
-	if guild.QuestsHandled > 0 {
-		guild.SuccessRate = float64(guild.QuestsHandled-guild.QuestsFailed) / float64(guild.QuestsHandled)
-	}
-
-	// Nudge reputation toward success rate
-	guild.Reputation = guild.Reputation*0.9 + guild.SuccessRate*0.1
+func (c *Component) updateGuildStatsOnFailure(ctx context.Context, guildID domain.GuildID) {
+	_, err := c.graph.UpdateGuild(ctx, guildID, "guild.stats.updated", func(guild *domain.Guild) error {
+		guild.QuestsHandled++
+		guild.QuestsFailed++
+		if guild.QuestsHandled > 0 {
+			guild.SuccessRate = float64(guild.QuestsHandled-guild.QuestsFailed) / float64(guild.QuestsHandled)
+		}
 
-	// Persist updated guild to KV
-	if err := c.graph.EmitEntity(ctx, guild, "guild.stats.updated"); err != nil {
+		// Nudge reputation toward success rate
+		guild.Reputation = guild.Reputation*0.9 + guild.SuccessRate*0.1
+		return nil
+	})
+	if err != nil {
 		c.logger.Error("failed to persist guild stats update on failure",
-			"guild_id", guild.ID,
+			"guild_id", guildID,
 			"error", err)
 		c.errorsCount.Add(1)
 	}
@@ -0,0 +1,277 @@
+package agentprogression
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+)
+
+// =============================================================================
+// QUEST MEMORY - Long-term per-agent recall of finished quests
+// =============================================================================
+// When a quest reaches a terminal state the progression handler records a
+// compact domain.QuestMemory on the agent entity. Questbridge later ranks the
+// stored memories against an incoming quest and hands the best matches to the
+// prompt assembler.
+// =============================================================================
+
+const (
+	// DefaultMaxMemories caps how many memories an agent retains. Oldest are
+	// evicted first once the cap is reached.
+	DefaultMaxMemories = 50
+
+	// maxMemoryApproachChars bounds the approach excerpt taken from quest output.
+	maxMemoryApproachChars = 400
+
+	// maxMemoryObjectiveChars bounds the objective text.
+	maxMemoryObjectiveChars = 240
+
+	// maxMemoryFeedbackChars bounds judge feedback / failure reason.
+	maxMemoryFeedbackChars = 300
+
+	// maxMemoryKeyFiles bounds how many file paths are kept per memory.
+	maxMemoryKeyFiles = 10
+)
+
+// filePathPattern matches path-like tokens in quest output: anything with a
+// directory separator and an extension, or a bare filename with a common
+// source extension.
+var filePathPattern = regexp.MustCompile(
+	`(?:[\w.-]+/)+[\w.-]+\.[A-Za-z0-9]{1,6}\b|\b[\w-]+\.(?:go|py|ts|tsx|js|jsx|rs|java|rb|c|h|cpp|svelte|sql|sh|md|yaml|yml|json|toml)\b`)
+
+// urlPattern strips URLs before file extraction so links are not mistaken
+// for workspace paths.
+var urlPattern = regexp.MustCompile(`\w+://\S+`)
+
+// BuildQuestMemory builds a memory record from a terminal quest.
+func BuildQuestMemory(quest *domain.Quest, outcome domain.MemoryOutcome, now time.Time) domain.QuestMemory {
+	objective := quest.Title
+	if quest.Goal != "" && quest.Goal != quest.Title {
+		objective = quest.Title + " — " + quest.Goal
+	}
+
+	output := outputText(quest.Output)
+
+	mem := domain.QuestMemory{
+		ID:         "mem-" + domain.ExtractInstance(string(quest.ID)),
+		QuestID:    quest.ID,
+		Objective:  truncateRunes(collapseWhitespace(objective), maxMemoryObjectiveChars),
+		Approach:   truncateRunes(collapseWhitespace(output), maxMemoryApproachChars),
+		Outcome:    outcome,
+		Skills:     slices.Clone(quest.RequiredSkills),
+		Repo:       quest.Repo,
+		KeyFiles:   extractKeyFiles(output),
+		RecordedAt: now,
+	}
+
+	if quest.Verdict != nil {
+		mem.QualityScore = quest.Verdict.QualityScore
+		mem.JudgeFeedback = quest.Verdict.Feedback
+	}
+	if mem.JudgeFeedback == "" && outcome == domain.MemoryOutcomeFailure {
+		mem.JudgeFeedback = quest.FailureReason
+	}
+	mem.JudgeFeedback = truncateRunes(collapseWhitespace(mem.JudgeFeedback), maxMemoryFeedbackChars)
+
+	return mem
+}
+
+// RecordMemory adds a memory to the agent, replacing any existing memory for
+// the same quest, and evicts the oldest entries beyond maxMemories.
+func (a *Agent) RecordMemory(mem domain.QuestMemory, maxMemories int) {
+	if maxMemories <= 0 {
+		maxMemories = DefaultMaxMemories
+	}
+	a.Memories = slices.DeleteFunc(a.Memories, func(m domain.QuestMemory) bool {
+		return m.QuestID == mem.QuestID
+	})
+	a.Memories = append(a.Memories, mem)
+	if overflow := len(a.Memories) - maxMemories; overflow > 0 {
+		a.Memories = a.Memories[overflow:]
+	}
+}
+
+// ForgetMemory removes a single memory by ID. Returns false when not found.
+func (a *Agent) ForgetMemory(memoryID string) bool {
+	before := len(a.Memories)
+	a.Memories = slices.DeleteFunc(a.Memories, func(m domain.QuestMemory) bool {
+		return m.ID == memoryID
+	})
+	return len(a.Memories) < before
+}
+
+// MemoryPruneOptions selects which memories PruneMemories removes. Criteria
+// are combined: a memory is removed if it matches any set criterion, and
+// KeepLatest is applied last.
+type MemoryPruneOptions struct {
+	OlderThan  time.Time            // Remove memories recorded before this time (zero = ignore)
+	Outcome    domain.MemoryOutcome // Remove memories with this outcome ("" = ignore)
+	KeepLatest int                  // Keep at most this many most-recent memories (0 = ignore)
+}
+
+// PruneMemories removes memories matching opts and returns how many were removed.
+func (a *Agent) PruneMemories(opts MemoryPruneOptions) int {
+	before := len(a.Memories)
+	a.Memories = slices.DeleteFunc(a.Memories, func(m domain.QuestMemory) bool {
+		if !opts.OlderThan.IsZero() && m.RecordedAt.Before(opts.OlderThan) {
+			return true
+		}
+		return opts.Outcome != "" && m.Outcome == opts.Outcome
+	})
+	if opts.KeepLatest > 0 && len(a.Memories) > opts.KeepLatest {
+		sort.SliceStable(a.Memories, func(i, j int) bool {
+			return a.Memories[i].RecordedAt.Before(a.Memories[j].RecordedAt)
+		})
+		a.Memories = a.Memories[len(a.Memories)-opts.KeepLatest:]
+	}
+	return before - len(a.Memories)
+}
+
+// RelevantMemories ranks memories against a quest by skill overlap, repo match
+// and objective text similarity, returning at most limit entries. Memories of
+// the quest itself and memories with no relevance signal are excluded.
+func RelevantMemories(memories []domain.QuestMemory, quest *domain.Quest, limit int) []domain.QuestMemory {
+	if quest == nil || len(memories) == 0 || limit <= 0 {
+		return nil
+	}
+
+	questTokens := tokenSet(quest.Title + " " + quest.Description + " " + quest.Goal)
+
+	type scored struct {
+		mem   domain.QuestMemory
+		score float64
+	}
+	var ranked []scored
+	for _, m := range memories {
+		if m.QuestID == quest.ID {
+			continue
+		}
+		score := 2 * skillOverlap(m.Skills, quest.RequiredSkills)
+		if m.Repo != "" && m.Repo == quest.Repo {
+			score++
+		}
+		score += jaccard(questTokens, tokenSet(m.Objective+" "+m.Approach))
+		if score <= 0 {
+			continue
+		}
+		ranked = append(ranked, scored{mem: m, score: score})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].mem.RecordedAt.After(ranked[j].mem.RecordedAt)
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	result := make([]domain.QuestMemory, len(ranked))
+	for i, r := range ranked {
+		result[i] = r.mem
+	}
+	return result
+}
+
+// skillOverlap is the Jaccard similarity of two skill sets.
+func skillOverlap(a, b []domain.SkillTag) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	setA := make(map[string]bool, len(a))
+	for _, s := range a {
+		setA[string(s)] = true
+	}
+	setB := make(map[string]bool, len(b))
+	for _, s := range b {
+		setB[string(s)] = true
+	}
+	return jaccard(setA, setB)
+}
+
+// jaccard returns |a ∩ b| / |a ∪ b| for two string sets.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for k := range a {
+		if b[k] {
+			inter++
+		}
+	}
+	union := len(a) + len(b) - inter
+	return float64(inter) / float64(union)
+}
+
+// memoryStopwords are dropped from text similarity — they match everything.
+var memoryStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true,
+	"from": true, "into": true, "are": true, "was": true, "use": true, "using": true,
+}
+
+// tokenSet lowercases text and splits it into a set of words of 3+ chars.
+func tokenSet(text string) map[string]bool {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_')
+	})
+	set := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if len(f) >= 3 && !memoryStopwords[f] {
+			set[f] = true
+		}
+	}
+	return set
+}
+
+// extractKeyFiles returns unique file paths mentioned in quest output.
+func extractKeyFiles(output string) []string {
+	if output == "" {
+		return nil
+	}
+	var files []string
+	seen := make(map[string]bool)
+	for _, match := range filePathPattern.FindAllString(urlPattern.ReplaceAllString(output, " "), -1) {
+		if seen[match] {
+			continue
+		}
+		seen[match] = true
+		files = append(files, match)
+		if len(files) >= maxMemoryKeyFiles {
+			break
+		}
+	}
+	return files
+}
+
+// outputText renders quest output (string or structured) as plain text.
+func outputText(output any) string {
+	switch v := output.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// collapseWhitespace folds runs of whitespace into single spaces.
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncateRunes shortens s to at most n runes, appending an ellipsis.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
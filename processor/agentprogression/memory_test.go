@@ -0,0 +1,149 @@
+package agentprogression
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/c360studio/semstreams/graph"
+
+	"github.com/c360studio/semdragons/domain"
+)
+
+// =============================================================================
+// QUEST MEMORY UNIT TESTS
+// =============================================================================
+
+func TestBuildQuestMemory_Success(t *testing.T) {
+	now := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	quest := &domain.Quest{
+		ID:             "c360.prod.game.board1.quest.q42",
+		Title:          "Add retry to HTTP client",
+		RequiredSkills: []domain.SkillTag{domain.SkillCodeGen},
+		Repo:           "api",
+		Output:         "Created client/retry.go and updated client/client.go. Tests in retry_test.go pass. See https://example.com/docs.html",
+		Verdict:        &domain.BattleVerdict{Passed: true, QualityScore: 0.9, Feedback: "Solid backoff handling"},
+	}
+
+	mem := BuildQuestMemory(quest, domain.MemoryOutcomeSuccess, now)
+
+	if mem.ID != "mem-q42" {
+		t.Errorf("ID = %q, want mem-q42", mem.ID)
+	}
+	if mem.QualityScore != 0.9 || mem.JudgeFeedback != "Solid backoff handling" {
+		t.Errorf("verdict not captured: score=%v feedback=%q", mem.QualityScore, mem.JudgeFeedback)
+	}
+	want := []string{"client/retry.go", "client/client.go", "retry_test.go"}
+	if fmt.Sprint(mem.KeyFiles) != fmt.Sprint(want) {
+		t.Errorf("KeyFiles = %v, want %v", mem.KeyFiles, want)
+	}
+	if !mem.RecordedAt.Equal(now) {
+		t.Errorf("RecordedAt = %v, want %v", mem.RecordedAt, now)
+	}
+}
+
+func TestBuildQuestMemory_FailureUsesReason(t *testing.T) {
+	quest := &domain.Quest{
+		ID:            "c360.prod.game.board1.quest.q7",
+		Title:         "Migrate schema",
+		FailureReason: "migration left table locked",
+	}
+	mem := BuildQuestMemory(quest, domain.MemoryOutcomeFailure, time.Now())
+	if mem.JudgeFeedback != "migration left table locked" {
+		t.Errorf("JudgeFeedback = %q, want failure reason", mem.JudgeFeedback)
+	}
+}
+
+func TestRecordMemory_DedupesAndCaps(t *testing.T) {
+	a := &Agent{}
+	for i := range 5 {
+		a.RecordMemory(domain.QuestMemory{ID: fmt.Sprintf("mem-%d", i), QuestID: domain.QuestID(fmt.Sprintf("q%d", i))}, 3)
+	}
+	if len(a.Memories) != 3 || a.Memories[0].ID != "mem-2" {
+		t.Fatalf("expected oldest evicted, got %+v", a.Memories)
+	}
+
+	a.RecordMemory(domain.QuestMemory{ID: "mem-3b", QuestID: "q3"}, 3)
+	if len(a.Memories) != 3 {
+		t.Fatalf("re-recording a quest should replace, got %d memories", len(a.Memories))
+	}
+	if a.Memories[2].ID != "mem-3b" {
+		t.Errorf("replacement should be newest, got %q", a.Memories[2].ID)
+	}
+}
+
+func TestPruneMemories(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newAgent := func() *Agent {
+		return &Agent{Memories: []domain.QuestMemory{
+			{ID: "m1", Outcome: domain.MemoryOutcomeSuccess, RecordedAt: base},
+			{ID: "m2", Outcome: domain.MemoryOutcomeFailure, RecordedAt: base.Add(time.Hour)},
+			{ID: "m3", Outcome: domain.MemoryOutcomeSuccess, RecordedAt: base.Add(2 * time.Hour)},
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		opts        MemoryPruneOptions
+		wantRemoved int
+	}{
+		{"outcome", MemoryPruneOptions{Outcome: domain.MemoryOutcomeFailure}, 1},
+		{"older than", MemoryPruneOptions{OlderThan: base.Add(90 * time.Minute)}, 2},
+		{"keep latest", MemoryPruneOptions{KeepLatest: 1}, 2},
+		{"nothing", MemoryPruneOptions{}, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newAgent()
+			if got := a.PruneMemories(tc.opts); got != tc.wantRemoved {
+				t.Errorf("removed %d, want %d", got, tc.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestRelevantMemories_Ranking(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	memories := []domain.QuestMemory{
+		{ID: "unrelated", Objective: "Write marketing copy", Skills: []domain.SkillTag{domain.SkillResearch}, RecordedAt: base},
+		{ID: "skill-only", Objective: "Build CLI parser", Skills: []domain.SkillTag{domain.SkillCodeGen}, RecordedAt: base},
+		{ID: "skill-repo-text", Objective: "Add retry to webhook client", Skills: []domain.SkillTag{domain.SkillCodeGen}, Repo: "api", RecordedAt: base},
+		{ID: "self", QuestID: "current", Objective: "Add retry to HTTP client", Skills: []domain.SkillTag{domain.SkillCodeGen}, Repo: "api", RecordedAt: base},
+	}
+	quest := &domain.Quest{
+		ID:             "current",
+		Title:          "Add retry to HTTP client",
+		RequiredSkills: []domain.SkillTag{domain.SkillCodeGen},
+		Repo:           "api",
+	}
+
+	got := RelevantMemories(memories, quest, 5)
+	if len(got) != 2 {
+		t.Fatalf("got %d memories, want 2 (unrelated and self excluded): %+v", len(got), got)
+	}
+	if got[0].ID != "skill-repo-text" || got[1].ID != "skill-only" {
+		t.Errorf("ranking = [%s %s], want [skill-repo-text skill-only]", got[0].ID, got[1].ID)
+	}
+
+	if got := RelevantMemories(memories, quest, 1); len(got) != 1 {
+		t.Errorf("limit not applied, got %d", len(got))
+	}
+	if got := RelevantMemories(memories, quest, 0); got != nil {
+		t.Errorf("limit 0 should disable retrieval, got %v", got)
+	}
+}
+
+func TestAgentMemories_TripleRoundTrip(t *testing.T) {
+	a := &Agent{
+		ID:     "c360.prod.game.board1.agent.a1",
+		Name:   "memo",
+		Status: domain.AgentIdle,
+		Memories: []domain.QuestMemory{
+			{ID: "mem-q1", QuestID: "q1", Objective: "Parse config", Outcome: domain.MemoryOutcomeSuccess},
+		},
+	}
+	restored := AgentFromEntityState(&graph.EntityState{ID: string(a.ID), Triples: a.Triples()})
+	if len(restored.Memories) != 1 || restored.Memories[0].Objective != "Parse config" {
+		t.Errorf("memories not restored from triples: %+v", restored.Memories)
+	}
+}
@@ -44,6 +44,7 @@ type XPAward struct {
 	GuildBonus      int64  `json:"guild_bonus"`
 	AttemptPenalty  int64  `json:"attempt_penalty"`
 	PeerReviewBonus int64  `json:"peer_review_bonus"`
+	GuildTax        int64  `json:"guild_tax"` // Paid to the guild treasury, already deducted from TotalXP
 	TotalXP         int64  `json:"total_xp"`
 	Breakdown       string `json:"breakdown"`
 }
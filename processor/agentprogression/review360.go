@@ -0,0 +1,184 @@
+package agentprogression
+
+import (
+	"context"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+)
+
+// =============================================================================
+// 360 PEER REVIEWS - Party-wide aggregation of member-to-member reviews
+// =============================================================================
+// questdagexec opens member-to-member reviews at party disband. Once every
+// one of a party's reviews has completed, they are aggregated together:
+// reviewees get a weighted Peer360Avg (used as XPContext.PeerReviewScore on
+// later party quests), reviewers get their calibration updated, and
+// collusion or retaliation is flagged on the review entities so guild
+// cohesion scoring can skip those ratings.
+// =============================================================================
+
+// aggregatePartyReviews runs the 360 aggregation for a party if all of its
+// member-to-member reviews are complete and it has not run yet.
+func (c *Component) aggregatePartyReviews(partyID domain.PartyID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entities, err := c.graph.ListPeerReviewsByPrefix(ctx, 0)
+	if err != nil {
+		c.logger.Error("failed to list peer reviews for 360 aggregation",
+			"party_id", partyID, "error", err)
+		c.errorsCount.Add(1)
+		return
+	}
+
+	var peer []domain.PeerReview
+	baselineSum := make(map[domain.AgentID]float64)
+	baselineCount := make(map[domain.AgentID]int)
+	for i := range entities {
+		pr := domain.PeerReviewFromEntityState(&entities[i])
+		if pr == nil || pr.PartyID == nil || *pr.PartyID != partyID {
+			continue
+		}
+		if !pr.IsMemberToMember {
+			// The lead's reviews of the same party anchor each consensus.
+			if pr.Status == domain.PeerReviewCompleted && pr.LeaderAvgRating > 0 && pr.MemberID != "" {
+				baselineSum[pr.MemberID] += pr.LeaderAvgRating
+				baselineCount[pr.MemberID]++
+			}
+			continue
+		}
+		if pr.Status != domain.PeerReviewCompleted || pr.Aggregated {
+			// Wait for the rest, or another instance already ran.
+			return
+		}
+		peer = append(peer, *pr)
+	}
+	if len(peer) == 0 {
+		return
+	}
+	if _, done := c.aggregatedParties.LoadOrStore(partyID, struct{}{}); done {
+		return
+	}
+
+	baseline := make(map[domain.AgentID]float64, len(baselineSum))
+	for id, sum := range baselineSum {
+		baseline[id] = sum / float64(baselineCount[id])
+	}
+
+	agents := make(map[domain.AgentID]*Agent)
+	profiles := make(map[domain.AgentID]domain.ReviewerProfile)
+	for _, pr := range peer {
+		for _, id := range []domain.AgentID{pr.LeaderID, pr.MemberID} {
+			if _, seen := agents[id]; seen {
+				continue
+			}
+			agents[id] = nil
+			entity, err := c.graph.GetAgent(ctx, id)
+			if err != nil {
+				c.logger.Warn("failed to load reviewer for 360 aggregation",
+					"party_id", partyID, "agent_id", id, "error", err)
+				continue
+			}
+			if agent := AgentFromEntityState(entity); agent != nil {
+				agents[id] = agent
+				profiles[id] = domain.ReviewerProfile{
+					Level:              agent.Level,
+					Calibration:        agent.Stats.ReviewCalibration,
+					CalibrationSamples: agent.Stats.ReviewCalibrationSamples,
+				}
+			}
+		}
+	}
+
+	agg := domain.AggregatePeerReviews(peer, profiles, baseline)
+	for _, an := range agg.Anomalies {
+		c.logger.Warn("360 peer review anomaly",
+			"party_id", partyID,
+			"review_id", an.ReviewID,
+			"kind", an.Kind,
+			"reviewer", an.ReviewerID,
+			"reviewee", an.RevieweeID,
+			"rating", an.Rating,
+			"consensus", an.Consensus)
+	}
+
+	anomalies := make(map[domain.AgentID]int)
+	for _, an := range agg.Anomalies {
+		anomalies[an.ReviewerID]++
+	}
+	for id := range agents {
+		c.applyPeer360(ctx, id, agg, anomalies[id])
+	}
+
+	// Record flags on the reviews and mark them aggregated.
+	for i := range peer {
+		pr := &peer[i]
+		for _, sub := range []*domain.ReviewSubmission{pr.LeaderReview, pr.MemberReview} {
+			if sub != nil {
+				sub.Anomaly = agg.Flagged(sub.ReviewerID, sub.RevieweeID)
+			}
+		}
+		pr.Aggregated = true
+		if err := c.graph.EmitEntityUpdate(ctx, pr, domain.PredicateReviewCompleted); err != nil {
+			c.logger.Error("failed to mark peer review aggregated",
+				"review_id", pr.ID, "error", err)
+			c.errorsCount.Add(1)
+		}
+	}
+
+	c.messagesProcessed.Add(1)
+	c.lastActivity.Store(time.Now())
+
+	c.logger.Info("aggregated 360 peer reviews",
+		"party_id", partyID,
+		"reviews", len(peer),
+		"scored", len(agg.Scores),
+		"anomalies", len(agg.Anomalies))
+}
+
+// applyPeer360 folds an agent's 360 score, calibration and anomaly count
+// into its stats. Re-reads the agent under its lock so concurrent quest
+// updates are not lost.
+func (c *Component) applyPeer360(ctx context.Context, agentID domain.AgentID, agg domain.PeerReviewAggregate, anomalies int) {
+	score, scored := agg.Scores[agentID]
+	deviation, calibrated := agg.Deviations[agentID]
+	if !scored && !calibrated && anomalies == 0 {
+		return
+	}
+
+	mu := c.lockAgent(string(agentID))
+	mu.Lock()
+	defer mu.Unlock()
+
+	entity, err := c.graph.GetAgent(ctx, agentID)
+	if err != nil {
+		c.logger.Error("failed to load agent for 360 stat update",
+			"agent_id", agentID, "error", err)
+		c.errorsCount.Add(1)
+		return
+	}
+	agent := AgentFromEntityState(entity)
+	if agent == nil {
+		return
+	}
+
+	if scored {
+		n := float64(agent.Stats.Peer360Count)
+		agent.Stats.Peer360Avg = (agent.Stats.Peer360Avg*n + score) / (n + 1)
+		agent.Stats.Peer360Count++
+	}
+	if calibrated {
+		agent.Stats.ReviewCalibration = domain.UpdateCalibration(
+			agent.Stats.ReviewCalibration, agent.Stats.ReviewCalibrationSamples, deviation)
+		agent.Stats.ReviewCalibrationSamples++
+	}
+	agent.Stats.ReviewAnomalies += anomalies
+	agent.UpdatedAt = time.Now()
+
+	if err := c.graph.EmitEntityUpdate(ctx, agent, "agent.progression.xp"); err != nil {
+		c.logger.Error("failed to emit agent 360 stat update",
+			"agent_id", agentID, "error", err)
+		c.errorsCount.Add(1)
+	}
+}
@@ -0,0 +1,65 @@
+package agentprogression
+
+import (
+	"context"
+
+	"github.com/c360studio/semdragons/domain"
+)
+
+// =============================================================================
+// GUILD TAX - Members fund their guild treasury from quest XP
+// =============================================================================
+// The tax is levied on the member's own guild regardless of which guild (if
+// any) prioritized the quest. Agents whose guild entity cannot be read, or
+// who are no longer on its roster, keep their full award.
+// =============================================================================
+
+// loadTaxGuild returns the agent's active guild when a tax applies. Reuses
+// the already-loaded quest guild when it is the same guild.
+func (c *Component) loadTaxGuild(ctx context.Context, agent *Agent, questGuild *domain.Guild) *domain.Guild {
+	if c.config.GuildTaxRate <= 0 || agent.Guild == "" {
+		return nil
+	}
+
+	guild := questGuild
+	if guild == nil || guild.ID != agent.Guild {
+		entity, err := c.graph.GetGuild(ctx, agent.Guild)
+		if err != nil {
+			c.logger.Debug("could not read guild for tax", "guild_id", agent.Guild, "error", err)
+			return nil
+		}
+		guild = domain.GuildFromEntityState(entity)
+	}
+	if guild == nil || guild.Status != domain.GuildActive || guild.Member(agent.ID) == nil {
+		return nil
+	}
+	return guild
+}
+
+// depositGuildTax records the tax in the guild treasury. The deposit is a
+// CAS update of the current guild, so it cannot overwrite, or be overwritten
+// by, a concurrent purchase or governance change.
+func (c *Component) depositGuildTax(ctx context.Context, guildID domain.GuildID, agentID domain.AgentID, questID domain.QuestID, tax int64) {
+	guild, err := c.graph.UpdateGuild(ctx, guildID, domain.PredicateGuildTreasuryDeposited, func(guild *domain.Guild) error {
+		guild.Deposit(domain.TreasuryTransaction{
+			Kind:    domain.TreasuryTxTax,
+			Amount:  tax,
+			AgentID: agentID,
+			QuestID: questID,
+		})
+		return nil
+	})
+	if err != nil {
+		c.logger.Error("failed to persist guild treasury deposit",
+			"guild_id", guildID,
+			"error", err)
+		c.errorsCount.Add(1)
+		return
+	}
+
+	c.logger.Debug("guild tax deposited",
+		"guild_id", guildID,
+		"agent", agentID,
+		"tax", tax,
+		"treasury", guild.Treasury)
+}
@@ -286,6 +286,14 @@ func expectedDuration(difficulty domain.QuestDifficulty) time.Duration {
 	}
 }
 
+// GuildTax returns the share of a quest award paid to the guild treasury.
+func GuildTax(totalXP int64, rate float64) int64 {
+	if totalXP <= 0 || rate <= 0 {
+		return 0
+	}
+	return int64(float64(totalXP) * rate)
+}
+
 // guildRankMultiplier returns XP bonus multiplier for guild rank.
 func guildRankMultiplier(rank domain.GuildRank) float64 {
 	switch rank {
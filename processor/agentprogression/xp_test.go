@@ -248,3 +248,24 @@ func TestXPCalculation_PeerReviewWithOtherBonuses(t *testing.T) {
 		t.Errorf("Breakdown %q should contain 'PeerReview' when peer bonus is non-zero", award.Breakdown)
 	}
 }
+
+func TestGuildTax(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int64
+		rate  float64
+		want  int64
+	}{
+		{"five percent", 200, 0.05, 10},
+		{"rounds down", 19, 0.05, 0},
+		{"disabled", 200, 0, 0},
+		{"no award", 0, 0.05, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GuildTax(tt.total, tt.rate); got != tt.want {
+				t.Errorf("GuildTax(%d, %v) = %d, want %d", tt.total, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
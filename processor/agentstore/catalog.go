@@ -0,0 +1,155 @@
+package agentstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semstreams/pkg/errs"
+)
+
+// =============================================================================
+// CATALOG LOADING - Defaults, config file, and KV overrides
+// =============================================================================
+// The catalog is built in layers at Start:
+//   1. DefaultCatalog(), or the items in Config.CatalogFile when set
+//   2. storeitem entities already in KV when Config.CatalogFromKV is set
+//      (KV wins on ID collisions, so operators can edit items live)
+// Every item is validated; consumables must reference a registered effect.
+// =============================================================================
+
+// maxCatalogKVItems bounds the storeitem entities read from KV at startup.
+const maxCatalogKVItems = 1000
+
+// LoadCatalogFile reads a JSON array of store items. Items default to in
+// stock when the file omits in_stock.
+func LoadCatalogFile(path string) ([]StoreItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read catalog file: %w", err)
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse catalog file: %w", err)
+	}
+
+	items := make([]StoreItem, 0, len(raw))
+	for i, r := range raw {
+		item := StoreItem{InStock: true}
+		if err := json.Unmarshal(r, &item); err != nil {
+			return nil, fmt.Errorf("parse catalog item %d: %w", i, err)
+		}
+		items = append(items, item)
+	}
+
+	if err := ValidateCatalog(items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ValidateCatalog checks every item and rejects duplicate IDs.
+func ValidateCatalog(items []StoreItem) error {
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if err := ValidateStoreItem(item); err != nil {
+			return err
+		}
+		if seen[item.ID] {
+			return fmt.Errorf("duplicate store item id %q", item.ID)
+		}
+		seen[item.ID] = true
+	}
+	return nil
+}
+
+// ValidateStoreItem checks a single catalog entry.
+func ValidateStoreItem(item StoreItem) error {
+	if item.ID == "" {
+		return errors.New("store item id is required")
+	}
+	if item.Name == "" {
+		return fmt.Errorf("store item %q: name is required", item.ID)
+	}
+	if item.XPCost < 0 {
+		return fmt.Errorf("store item %q: xp_cost must be non-negative", item.ID)
+	}
+	if item.GuildDiscount < 0 || item.GuildDiscount >= 1 {
+		return fmt.Errorf("store item %q: guild_discount must be in [0, 1)", item.ID)
+	}
+	if item.MaxStock < 0 || item.Stock < 0 || (item.MaxStock > 0 && item.Stock > item.MaxStock) {
+		return fmt.Errorf("store item %q: stock must be within [0, max_stock]", item.ID)
+	}
+	if item.RestockAmount < 0 || item.RestockIntervalSec < 0 {
+		return fmt.Errorf("store item %q: restock_amount and restock_interval_sec must be non-negative", item.ID)
+	}
+
+	switch item.ItemType {
+	case ItemTypeTool:
+		if item.ToolID == "" {
+			return fmt.Errorf("store item %q: tool_id is required for tools", item.ID)
+		}
+		if item.PurchaseType == PurchaseRental && item.RentalUses <= 0 {
+			return fmt.Errorf("store item %q: rental_uses must be positive for rentals", item.ID)
+		}
+	case ItemTypeConsumable:
+		if item.Effect == nil || item.Effect.Type == "" {
+			return fmt.Errorf("store item %q: effect is required for consumables", item.ID)
+		}
+		if _, ok := domain.LookupEffect(string(item.Effect.Type)); !ok {
+			return fmt.Errorf("store item %q: no handler registered for effect %q", item.ID, item.Effect.Type)
+		}
+		if item.Effect.Duration < 0 || item.Effect.Magnitude < 0 {
+			return fmt.Errorf("store item %q: effect duration and magnitude must be non-negative", item.ID)
+		}
+	default:
+		return fmt.Errorf("store item %q: unknown item_type %q", item.ID, item.ItemType)
+	}
+	return nil
+}
+
+// loadCatalog populates the in-memory catalog from the configured sources.
+// A bad catalog file fails Start; invalid KV items are skipped with a warning.
+func (c *Component) loadCatalog(ctx context.Context) error {
+	items := DefaultCatalog()
+	if c.config.CatalogFile != "" {
+		fileItems, err := LoadCatalogFile(c.config.CatalogFile)
+		if err != nil {
+			return errs.Wrap(err, "agent_store", "Start", "load catalog file")
+		}
+		items = fileItems
+	}
+	for _, item := range items {
+		item := item // copy for pointer stability
+		item.BoardConfig = c.boardConfig
+		prepareItem(&item)
+		c.catalog.Store(item.ID, &item)
+	}
+
+	if !c.config.CatalogFromKV {
+		return nil
+	}
+	entities, err := c.graph.ListStoreItemsByPrefix(ctx, maxCatalogKVItems)
+	if err != nil {
+		c.logger.Warn("failed to load store items from KV (using configured catalog)", "error", err)
+		return nil
+	}
+	for i := range entities {
+		item := StoreItemFromEntityState(&entities[i])
+		if item == nil {
+			continue
+		}
+		if err := ValidateStoreItem(*item); err != nil {
+			c.logger.Warn("skipping invalid store item from KV", "item_id", item.ID, "error", err)
+			continue
+		}
+		item.BoardConfig = c.boardConfig
+		prepareItem(item)
+		c.catalog.Store(item.ID, item)
+	}
+	return nil
+}
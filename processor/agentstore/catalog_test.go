@@ -0,0 +1,73 @@
+package agentstore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultCatalog_Valid(t *testing.T) {
+	if err := ValidateCatalog(DefaultCatalog()); err != nil {
+		t.Fatalf("default catalog invalid: %v", err)
+	}
+}
+
+func TestLoadCatalogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	data := `[
+		{"id": "mega_boost", "name": "Mega Boost", "item_type": "consumable", "xp_cost": 300,
+		 "effect": {"type": "xp_boost", "magnitude": 3, "duration": 1}},
+		{"id": "linter", "name": "Linter", "item_type": "tool", "purchase_type": "permanent",
+		 "tool_id": "linter", "xp_cost": 40, "in_stock": false}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := LoadCatalogFile(path)
+	if err != nil {
+		t.Fatalf("LoadCatalogFile: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("items = %d, want 2", len(items))
+	}
+	if !items[0].InStock {
+		t.Error("in_stock should default to true when omitted")
+	}
+	if items[1].InStock {
+		t.Error("explicit in_stock=false should be kept")
+	}
+	if items[0].Effect.Magnitude != 3 {
+		t.Errorf("magnitude = %v, want 3", items[0].Effect.Magnitude)
+	}
+}
+
+func TestValidateCatalog_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		item StoreItem
+		want string
+	}{
+		{"missing id", StoreItem{Name: "x", ItemType: ItemTypeTool, ToolID: "x"}, "id is required"},
+		{"unknown type", StoreItem{ID: "x", Name: "x", ItemType: "gadget"}, "unknown item_type"},
+		{"tool without tool id", StoreItem{ID: "x", Name: "x", ItemType: ItemTypeTool}, "tool_id"},
+		{"rental without uses", StoreItem{ID: "x", Name: "x", ItemType: ItemTypeTool, ToolID: "x", PurchaseType: PurchaseRental}, "rental_uses"},
+		{"consumable without effect", StoreItem{ID: "x", Name: "x", ItemType: ItemTypeConsumable}, "effect is required"},
+		{"unregistered effect", StoreItem{ID: "x", Name: "x", ItemType: ItemTypeConsumable, Effect: &ConsumableEffect{Type: "teleport"}}, "no handler"},
+		{"negative cost", StoreItem{ID: "x", Name: "x", ItemType: ItemTypeTool, ToolID: "x", XPCost: -1}, "xp_cost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCatalog([]StoreItem{tt.item})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+
+	dup := DefaultConsumables()[0]
+	if err := ValidateCatalog([]StoreItem{dup, dup}); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("duplicate IDs error = %v", err)
+	}
+}
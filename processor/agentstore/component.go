@@ -39,6 +39,11 @@ type Component struct {
 	agentWatch  jetstream.KeyWatcher
 	watchDoneCh chan struct{}
 
+	// KV watcher for guild entities, refreshing the shared-inventory cache
+	// when any processor changes a guild.
+	guildWatch       jetstream.KeyWatcher
+	guildWatchDoneCh chan struct{}
+
 	// Agent XP cache for affordability queries without caller-supplied state.
 	// Keys are entity ID strings; values are int64 XP totals.
 	agentXPCache sync.Map
@@ -52,6 +57,22 @@ type Component struct {
 	// Active effects - in-memory projection
 	activeEffects sync.Map // map[AgentID][]ActiveEffect
 
+	// Dynamic pricing and stock (see pricing.go). pricingMu serializes
+	// catalog price/stock mutations and guards recentSales.
+	pricingMu   sync.Mutex
+	recentSales map[string][]time.Time // itemID -> purchase times in demand window
+
+	// Economy ledger: XP supply vs store sinks (see economy.go).
+	// agentEarnedCache holds each agent's last-seen Stats.TotalXPEarned.
+	economy          economyLedger
+	agentEarnedCache sync.Map
+
+	// Guild treasury purchases (see guild.go). guildLocks serializes
+	// treasury writes; agentGuilds and guildTools back shared-tool checks.
+	guildLocks  sync.Map // map[domain.GuildID]*sync.Mutex
+	agentGuilds sync.Map // map[instance]domain.GuildID
+	guildTools  sync.Map // map[domain.GuildID][]string
+
 	// Internal state
 	running  atomic.Bool
 	mu       sync.RWMutex
@@ -152,6 +173,72 @@ func (c *Component) ConfigSchema() component.ConfigSchema {
 				Default:     10,
 				Category:    "store",
 			},
+			"catalog_file": {
+				Type:        "string",
+				Description: "Path to a JSON array of store items replacing the default catalog",
+				Default:     "",
+				Category:    "store",
+			},
+			"catalog_from_kv": {
+				Type:        "bool",
+				Description: "Merge store items already in KV over the catalog at startup",
+				Default:     false,
+				Category:    "store",
+			},
+			"dynamic_pricing": {
+				Type:        "bool",
+				Description: "Adjust list prices from recent demand and board XP inflation",
+				Default:     false,
+				Category:    "pricing",
+			},
+			"demand_window_sec": {
+				Type:        "int",
+				Description: "Window in seconds for counting recent purchases",
+				Default:     3600,
+				Category:    "pricing",
+			},
+			"demand_sensitivity": {
+				Type:        "float",
+				Description: "Price increase per recent purchase (0.05 = +5%)",
+				Default:     0.05,
+				Category:    "pricing",
+			},
+			"inflation_baseline_xp": {
+				Type:        "int",
+				Description: "Mean agent XP balance at which prices are neutral",
+				Default:     500,
+				Category:    "pricing",
+			},
+			"inflation_sensitivity": {
+				Type:        "float",
+				Description: "Price response to mean XP balance above the baseline",
+				Default:     0.25,
+				Category:    "pricing",
+			},
+			"max_price_multiplier": {
+				Type:        "float",
+				Description: "Upper bound on list price relative to base price",
+				Default:     3.0,
+				Category:    "pricing",
+			},
+			"reprice_interval_sec": {
+				Type:        "int",
+				Description: "Seconds between restock and repricing passes",
+				Default:     60,
+				Category:    "pricing",
+			},
+			"economy_bucket_sec": {
+				Type:        "int",
+				Description: "Width in seconds of each economy report bucket",
+				Default:     3600,
+				Category:    "advanced",
+			},
+			"economy_history_buckets": {
+				Type:        "int",
+				Description: "Number of economy report buckets retained",
+				Default:     48,
+				Category:    "advanced",
+			},
 		},
 		Required: []string{"org", "platform", "board"},
 	}
@@ -221,6 +308,7 @@ func (c *Component) Initialize() error {
 	c.boardConfig = c.config.ToBoardConfig()
 	c.stopChan = make(chan struct{})
 	c.watchDoneCh = make(chan struct{})
+	c.guildWatchDoneCh = make(chan struct{})
 
 	return nil
 }
@@ -234,16 +322,14 @@ func (c *Component) Start(ctx context.Context) error {
 		return errors.New("component already running")
 	}
 
-	// Load default catalog (tools + consumables) and seed to KV
-	for _, item := range DefaultCatalog() {
-		item := item // copy for pointer stability
-		item.BoardConfig = c.boardConfig
-		c.catalog.Store(item.ID, &item)
-	}
-
 	// Create graph client for entity state reads
 	c.graph = semdragons.NewGraphClient(c.deps.NATSClient, c.boardConfig)
 
+	// Load catalog (defaults or config file, plus KV overrides)
+	if err := c.loadCatalog(ctx); err != nil {
+		return err
+	}
+
 	// Seed catalog items to KV so other processors can read them
 	c.seedCatalogToKV(ctx)
 
@@ -262,6 +348,19 @@ func (c *Component) Start(ctx context.Context) error {
 	c.agentWatch = watcher
 	go c.processAgentWatchUpdates()
 
+	// Watch guilds so shared tools bought, or lost, through any processor
+	// are reflected in HasTool.
+	guildWatcher, err := c.graph.WatchEntityType(ctx, domain.EntityTypeGuild)
+	if err != nil {
+		c.agentWatch.Stop()
+		return errs.Wrap(err, "agent_store", "Start", "watch guild entity type")
+	}
+	c.guildWatch = guildWatcher
+	go c.processGuildWatchUpdates()
+
+	// Restock and reprice on a schedule (demand decays as the window slides)
+	go c.runPricingLoop()
+
 	c.startTime = time.Now()
 	c.running.Store(true)
 	c.lastActivity.Store(time.Now())
@@ -292,16 +391,23 @@ func (c *Component) Stop(timeout time.Duration) error {
 		close(c.stopChan)
 	})
 
-	// Stop KV watcher to unblock the watch goroutine
+	// Stop KV watchers to unblock the watch goroutines
 	if c.agentWatch != nil {
 		c.agentWatch.Stop()
 	}
+	if c.guildWatch != nil {
+		c.guildWatch.Stop()
+	}
 
-	// Wait for watch goroutine to finish with timeout
-	if c.watchDoneCh != nil {
+	// Wait for watch goroutines to finish with timeout
+	deadline := time.After(timeout)
+	for _, done := range []chan struct{}{c.watchDoneCh, c.guildWatchDoneCh} {
+		if done == nil {
+			continue
+		}
 		select {
-		case <-c.watchDoneCh:
-		case <-time.After(timeout):
+		case <-done:
+		case <-deadline:
 			c.logger.Warn("agent_store stop timed out waiting for KV watcher")
 		}
 	}
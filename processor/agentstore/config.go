@@ -18,6 +18,26 @@ type Config struct {
 	// Store settings
 	EnableGuildDiscounts bool `json:"enable_guild_discounts"`
 	DefaultRentalUses    int  `json:"default_rental_uses"`
+
+	// Catalog sources. CatalogFile replaces the built-in catalog with a JSON
+	// array of store items; CatalogFromKV merges storeitem entities already
+	// in KV over it (KV wins), so items can be added without a rebuild.
+	CatalogFile   string `json:"catalog_file,omitempty"`
+	CatalogFromKV bool   `json:"catalog_from_kv"`
+
+	// Dynamic pricing. When enabled, list prices follow recent purchase
+	// demand and board-wide XP inflation, bounded by MaxPriceMultiplier.
+	DynamicPricing       bool    `json:"dynamic_pricing"`
+	DemandWindowSec      int     `json:"demand_window_sec"`     // Window for counting recent purchases
+	DemandSensitivity    float64 `json:"demand_sensitivity"`    // Price increase per recent purchase (0.05 = +5%)
+	InflationBaselineXP  int64   `json:"inflation_baseline_xp"` // Mean agent XP balance at which prices are neutral
+	InflationSensitivity float64 `json:"inflation_sensitivity"` // Price response to XP balance above baseline
+	MaxPriceMultiplier   float64 `json:"max_price_multiplier"`  // Upper bound on list price / base price
+	RepriceIntervalSec   int     `json:"reprice_interval_sec"`  // How often prices and restocks are refreshed
+
+	// Economy report history
+	EconomyBucketSec      int `json:"economy_bucket_sec"`      // Width of each supply/sink bucket
+	EconomyHistoryBuckets int `json:"economy_history_buckets"` // Buckets retained
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -28,6 +48,15 @@ func DefaultConfig() Config {
 		Board:                "main",
 		EnableGuildDiscounts: true,
 		DefaultRentalUses:    10,
+		DemandWindowSec:      3600,
+		DemandSensitivity:    0.05,
+		InflationBaselineXP:  500,
+		InflationSensitivity: 0.25,
+		MaxPriceMultiplier:   3.0,
+		RepriceIntervalSec:   60,
+
+		EconomyBucketSec:      3600,
+		EconomyHistoryBuckets: 48,
 	}
 }
 
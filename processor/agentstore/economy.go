@@ -0,0 +1,174 @@
+package agentstore
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// ECONOMY - XP supply versus sinks over time
+// =============================================================================
+// Supply is XP awarded to agents (observed as growth of each agent's
+// Stats.TotalXPEarned on the KV watch). Sinks are XP spent in the store.
+// Both are bucketed by time so the report shows the trend, not just totals.
+// =============================================================================
+
+// EconomyBucket aggregates XP flows for one time window.
+type EconomyBucket struct {
+	Start      time.Time `json:"start"`
+	XPSupplied int64     `json:"xp_supplied"`
+	XPSpent    int64     `json:"xp_spent"`
+	Purchases  int       `json:"purchases"`
+}
+
+// PricePoint is a recorded list price change.
+type PricePoint struct {
+	ItemID string    `json:"item_id"`
+	XPCost int64     `json:"xp_cost"`
+	At     time.Time `json:"at"`
+}
+
+// EconomyReport summarizes the board's XP economy.
+type EconomyReport struct {
+	GeneratedAt         time.Time       `json:"generated_at"`
+	BucketSeconds       int             `json:"bucket_seconds"`
+	Buckets             []EconomyBucket `json:"buckets"`
+	TotalSupplied       int64           `json:"total_supplied"`
+	TotalSpent          int64           `json:"total_spent"`
+	NetFlow             int64           `json:"net_flow"`   // Supplied - spent
+	SinkRatio           float64         `json:"sink_ratio"` // Spent / supplied (0 when nothing supplied)
+	CirculatingXP       int64           `json:"circulating_xp"`
+	AgentsObserved      int             `json:"agents_observed"`
+	InflationMultiplier float64         `json:"inflation_multiplier"`
+	Prices              []PriceQuote    `json:"prices"`
+	PriceHistory        []PricePoint    `json:"price_history,omitempty"`
+}
+
+// maxPriceHistory bounds the recorded price changes kept for the report.
+const maxPriceHistory = 500
+
+// economyLedger holds bucketed XP flows. The zero value is ready to use.
+type economyLedger struct {
+	mu      sync.Mutex
+	buckets []EconomyBucket
+	prices  []PricePoint
+}
+
+// recordSupply adds awarded XP to the bucket containing at.
+func (l *economyLedger) recordSupply(at time.Time, xp int64, width time.Duration, keep int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.bucketFor(at, width, keep).XPSupplied += xp
+}
+
+// recordSink adds store spending to the bucket containing at.
+func (l *economyLedger) recordSink(at time.Time, xp int64, width time.Duration, keep int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucketFor(at, width, keep)
+	b.XPSpent += xp
+	b.Purchases++
+}
+
+// recordPrice appends a list price change.
+func (l *economyLedger) recordPrice(itemID string, xpCost int64, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prices = append(l.prices, PricePoint{ItemID: itemID, XPCost: xpCost, At: at})
+	if overflow := len(l.prices) - maxPriceHistory; overflow > 0 {
+		l.prices = l.prices[overflow:]
+	}
+}
+
+// bucketFor returns the bucket for at, creating it and evicting the oldest
+// beyond keep. Caller holds mu.
+func (l *economyLedger) bucketFor(at time.Time, width time.Duration, keep int) *EconomyBucket {
+	start := at.Truncate(width)
+	for i := len(l.buckets) - 1; i >= 0; i-- {
+		if l.buckets[i].Start.Equal(start) {
+			return &l.buckets[i]
+		}
+	}
+	l.buckets = append(l.buckets, EconomyBucket{Start: start})
+	sort.Slice(l.buckets, func(i, j int) bool { return l.buckets[i].Start.Before(l.buckets[j].Start) })
+	if overflow := len(l.buckets) - keep; keep > 0 && overflow > 0 {
+		l.buckets = l.buckets[overflow:]
+	}
+	for i := range l.buckets {
+		if l.buckets[i].Start.Equal(start) {
+			return &l.buckets[i]
+		}
+	}
+	// Evicted immediately (older than every retained bucket) — count it in
+	// a throwaway bucket rather than corrupting retained history.
+	return &EconomyBucket{Start: start}
+}
+
+// snapshot copies buckets and price history.
+func (l *economyLedger) snapshot() ([]EconomyBucket, []PricePoint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	buckets := make([]EconomyBucket, len(l.buckets))
+	copy(buckets, l.buckets)
+	prices := make([]PricePoint, len(l.prices))
+	copy(prices, l.prices)
+	return buckets, prices
+}
+
+// economyWindow returns the configured bucket width and retention.
+func (c *Component) economyWindow() (time.Duration, int) {
+	cfg := c.pricingConfig()
+	width := time.Duration(cfg.EconomyBucketSec) * time.Second
+	if width <= 0 {
+		width = time.Hour
+	}
+	return width, cfg.EconomyHistoryBuckets
+}
+
+// recordXPEarned tracks supply from an agent's lifetime XP earned counter.
+func (c *Component) recordXPEarned(key string, totalEarned int64, at time.Time) {
+	prev, hadPrev := c.agentEarnedCache.Swap(key, totalEarned)
+	if !hadPrev {
+		return // First observation is a baseline, not new supply.
+	}
+	if delta := totalEarned - prev.(int64); delta > 0 {
+		width, keep := c.economyWindow()
+		c.economy.recordSupply(at, delta, width, keep)
+	}
+}
+
+// EconomyReport returns XP supply versus sinks over time plus current prices.
+func (c *Component) EconomyReport() EconomyReport {
+	width, _ := c.economyWindow()
+	buckets, history := c.economy.snapshot()
+
+	report := EconomyReport{
+		GeneratedAt:         time.Now(),
+		BucketSeconds:       int(width / time.Second),
+		Buckets:             buckets,
+		InflationMultiplier: c.inflationMultiplier(),
+		PriceHistory:        history,
+	}
+	for _, b := range buckets {
+		report.TotalSupplied += b.XPSupplied
+		report.TotalSpent += b.XPSpent
+	}
+	report.NetFlow = report.TotalSupplied - report.TotalSpent
+	if report.TotalSupplied > 0 {
+		report.SinkRatio = float64(report.TotalSpent) / float64(report.TotalSupplied)
+	}
+
+	c.agentXPCache.Range(func(_, v any) bool {
+		report.CirculatingXP += v.(int64)
+		report.AgentsObserved++
+		return true
+	})
+
+	items := c.Catalog()
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	for i := range items {
+		report.Prices = append(report.Prices, *c.quoteItem(&items[i], 0))
+	}
+	return report
+}
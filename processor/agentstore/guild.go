@@ -0,0 +1,316 @@
+package agentstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/c360studio/semdragons"
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semstreams/pkg/errs"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// =============================================================================
+// GUILD TREASURY PURCHASES - Officers spend shared XP
+// =============================================================================
+// The treasury lives on the guild entity (funded by agentprogression's quest
+// tax). Officers and the guildmaster may spend it on:
+//   - permanent tools for the guild's shared inventory (every member passes
+//     HasTool for them), or
+//   - consumables granted to a specific member (sponsorship).
+// Guild prices include the spender's rank discount. Each purchase is a CAS
+// update of the guild entity, retried on conflict with the other guild
+// writers; a per-guild lock serializes purchases within this process.
+// =============================================================================
+
+// Guild treasury errors.
+var (
+	ErrNotGuildMember  = errors.New("agent is not a member of the guild")
+	ErrNotGuildOfficer = errors.New("only officers and the guildmaster may spend the treasury")
+	ErrAlreadyShared   = errors.New("tool is already in the guild inventory")
+)
+
+// guildLoadTimeout bounds guild reads made from the KV watcher.
+const guildLoadTimeout = 5 * time.Second
+
+// BuyForGuild spends guild treasury XP on a permanent tool for the guild's
+// shared inventory.
+func (c *Component) BuyForGuild(ctx context.Context, guildID domain.GuildID, officerID domain.AgentID, itemID string) (*domain.TreasuryTransaction, error) {
+	return c.spendTreasury(ctx, guildID, officerID, itemID, func(guild *domain.Guild, item *StoreItem) (domain.TreasuryTransaction, error) {
+		if item.ItemType != ItemTypeTool || item.PurchaseType != PurchasePermanent {
+			return domain.TreasuryTransaction{}, errors.New("only permanent tools can be bought for the guild inventory")
+		}
+		if guild.HasSharedTool(item.ID) {
+			return domain.TreasuryTransaction{}, ErrAlreadyShared
+		}
+		guild.SharedTools = append(guild.SharedTools, item.ID)
+		return domain.TreasuryTransaction{Kind: domain.TreasuryTxToolBuy}, nil
+	}, nil)
+}
+
+// SponsorConsumable spends guild treasury XP on a consumable granted to a
+// guild member.
+func (c *Component) SponsorConsumable(ctx context.Context, guildID domain.GuildID, officerID, memberID domain.AgentID, itemID string) (*domain.TreasuryTransaction, error) {
+	var beneficiary domain.AgentID
+	return c.spendTreasury(ctx, guildID, officerID, itemID, func(guild *domain.Guild, item *StoreItem) (domain.TreasuryTransaction, error) {
+		if item.ItemType != ItemTypeConsumable {
+			return domain.TreasuryTransaction{}, errors.New("only consumables can be sponsored")
+		}
+		member := guild.Member(memberID)
+		if member == nil {
+			return domain.TreasuryTransaction{}, ErrNotGuildMember
+		}
+		beneficiary = member.AgentID
+		return domain.TreasuryTransaction{Kind: domain.TreasuryTxSponsorship, Beneficiary: beneficiary}, nil
+	}, func(ctx context.Context, item *StoreItem, now time.Time) {
+		c.grantConsumable(ctx, beneficiary, item, now)
+	})
+}
+
+// spendTreasury runs the shared checks for a treasury purchase. prepare
+// validates the purchase and applies guild-side changes; grant (optional)
+// delivers the item once the guild write succeeded. The guild is updated
+// with CAS, so checks and prepare run again on the fresh guild whenever a
+// concurrent writer (quest tax, governance) got there first. Stock reserved
+// for the purchase is released if it fails.
+func (c *Component) spendTreasury(
+	ctx context.Context,
+	guildID domain.GuildID,
+	officerID domain.AgentID,
+	itemID string,
+	prepare func(*domain.Guild, *StoreItem) (domain.TreasuryTransaction, error),
+	grant func(context.Context, *StoreItem, time.Time),
+) (*domain.TreasuryTransaction, error) {
+	if !c.running.Load() {
+		return nil, errors.New("component not running")
+	}
+
+	mu := c.guildLock(guildID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	item, ok := c.GetItem(itemID)
+	if !ok {
+		return nil, errors.New("item not found")
+	}
+	restocked, err := c.reserveStock(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var (
+		tx        domain.TreasuryTransaction
+		price     int64
+		rejectErr error // set when the purchase itself is refused
+	)
+	guild, err := c.graph.UpdateGuild(ctx, guildID, domain.PredicateGuildTreasurySpent, func(guild *domain.Guild) error {
+		rejectErr = c.prepareTreasurySpend(guild, officerID, item, prepare, now, &tx, &price)
+		return rejectErr
+	})
+	if err != nil {
+		c.releaseStock(itemID)
+		if rejectErr != nil {
+			return nil, rejectErr
+		}
+		c.errorsCount.Add(1)
+		return nil, errs.Wrap(err, "AgentStore", "spendTreasury", "persist guild treasury")
+	}
+	c.guildTools.Store(guild.ID, append([]string(nil), guild.SharedTools...))
+
+	if grant != nil {
+		grant(ctx, item, now)
+	}
+
+	// Economy: treasury spending is a store sink like any other purchase
+	width, keep := c.economyWindow()
+	c.economy.recordSink(now, price, width, keep)
+	if repriced := c.recordSale(itemID, now); repriced != nil {
+		c.relist(ctx, repriced, item.XPCost, "purchase")
+	} else if restocked != nil {
+		c.relist(ctx, restocked, item.XPCost, "purchase")
+	}
+
+	c.purchasesComplete.Add(1)
+	c.lastActivity.Store(now)
+
+	c.logger.Info("guild treasury purchase",
+		"guild_id", guildID,
+		"officer", tx.AgentID,
+		"item_id", itemID,
+		"kind", tx.Kind,
+		"xp_spent", price,
+		"treasury", guild.Treasury)
+
+	return &tx, nil
+}
+
+// prepareTreasurySpend checks the officer and balance against the current
+// guild, applies prepare, and withdraws the price. It fills tx and price on
+// success.
+func (c *Component) prepareTreasurySpend(
+	guild *domain.Guild,
+	officerID domain.AgentID,
+	item *StoreItem,
+	prepare func(*domain.Guild, *StoreItem) (domain.TreasuryTransaction, error),
+	now time.Time,
+	tx *domain.TreasuryTransaction,
+	price *int64,
+) error {
+	if guild.Status != domain.GuildActive {
+		return errors.New("guild not active")
+	}
+	officer := guild.Member(officerID)
+	if officer == nil {
+		return ErrNotGuildMember
+	}
+	if !officer.Rank.CanSpendTreasury() {
+		return ErrNotGuildOfficer
+	}
+
+	quote := c.quoteItem(item, c.discountFor(item, guild.ID, officer.Rank))
+	if quote.Price > guild.Treasury {
+		return domain.ErrInsufficientTreasury
+	}
+
+	prepared, err := prepare(guild, item)
+	if err != nil {
+		return err
+	}
+	prepared.Amount = quote.Price
+	prepared.AgentID = officer.AgentID
+	prepared.ItemID = item.ID
+	prepared.At = now
+	withdrawn, err := guild.Withdraw(prepared)
+	if err != nil {
+		return err
+	}
+	*tx = withdrawn
+	*price = quote.Price
+	return nil
+}
+
+// grantConsumable adds a sponsored consumable to a member's inventory
+// without charging the member.
+func (c *Component) grantConsumable(ctx context.Context, agentID domain.AgentID, item *StoreItem, now time.Time) {
+	inv := c.getOrCreateInventory(agentID)
+	inv.mu.Lock()
+	inv.Consumables[item.ID]++
+	inv.mu.Unlock()
+
+	agentEntity, err := c.graph.GetAgent(ctx, agentID)
+	if err == nil && agentEntity != nil {
+		if agent := agentprogression.AgentFromEntityState(agentEntity); agent != nil {
+			if agent.Consumables == nil {
+				agent.Consumables = make(map[string]int)
+			}
+			agent.Consumables[item.ID]++
+			agent.UpdatedAt = now
+			if writeErr := c.graph.EmitEntityUpdate(ctx, agent, "agent.inventory.sponsored"); writeErr != nil {
+				c.errorsCount.Add(1)
+				c.logger.Error("failed to write agent entity after sponsorship", "error", writeErr)
+			}
+		}
+	}
+
+	if err := SubjectInventoryUpdated.Publish(ctx, c.deps.NATSClient, InventoryUpdatedPayload{
+		AgentID:    agentID,
+		ChangeType: "sponsored",
+		ItemID:     item.ID,
+		ItemName:   item.Name,
+		Quantity:   1,
+		Timestamp:  now,
+	}); err != nil {
+		c.errorsCount.Add(1)
+	}
+}
+
+// guildHasTool reports whether the agent's guild owns the tool. Agents are
+// keyed by instance so full and short IDs resolve alike.
+func (c *Component) guildHasTool(agentID domain.AgentID, toolID string) bool {
+	guildID, ok := c.agentGuilds.Load(domain.ExtractInstance(string(agentID)))
+	if !ok {
+		return false
+	}
+	tools, ok := c.guildTools.Load(guildID)
+	if !ok {
+		return false
+	}
+	for _, id := range tools.([]string) {
+		if id == toolID {
+			return true
+		}
+	}
+	return false
+}
+
+// trackAgentGuild records the agent's guild for shared-tool checks and
+// loads the guild's shared inventory the first time the guild is seen. The
+// guild watcher keeps it current after that.
+func (c *Component) trackAgentGuild(ctx context.Context, agent *agentprogression.Agent) {
+	key := domain.ExtractInstance(string(agent.ID))
+	if agent.Guild == "" {
+		c.agentGuilds.Delete(key)
+		return
+	}
+	c.agentGuilds.Store(key, agent.Guild)
+	if _, ok := c.guildTools.Load(agent.Guild); ok || c.graph == nil {
+		return
+	}
+
+	entity, err := c.graph.GetGuild(ctx, agent.Guild)
+	if err != nil {
+		c.logger.Debug("failed to load guild inventory", "guild_id", agent.Guild, "error", err)
+		return
+	}
+	if guild := domain.GuildFromEntityState(entity); guild != nil {
+		c.guildTools.LoadOrStore(guild.ID, append([]string(nil), guild.SharedTools...))
+	}
+}
+
+// processGuildWatchUpdates keeps the shared-inventory cache in step with
+// guild entities, whichever processor wrote them.
+func (c *Component) processGuildWatchUpdates() {
+	defer close(c.guildWatchDoneCh)
+
+	for {
+		select {
+		case <-c.stopChan:
+			return
+		case entry, ok := <-c.guildWatch.Updates():
+			if !ok {
+				return
+			}
+			if entry == nil {
+				continue // Initial sync complete
+			}
+			c.handleGuildUpdate(entry)
+		}
+	}
+}
+
+// handleGuildUpdate refreshes the cached shared tools of a changed guild, or
+// drops them when the guild is deleted.
+func (c *Component) handleGuildUpdate(entry jetstream.KeyValueEntry) {
+	if op := entry.Operation(); op == jetstream.KeyValueDelete || op == jetstream.KeyValuePurge {
+		c.guildTools.Delete(domain.GuildID(entry.Key()))
+		return
+	}
+	entityState, err := semdragons.DecodeEntityState(entry)
+	if err != nil || entityState == nil {
+		c.logger.Warn("failed to decode guild entity state", "key", entry.Key(), "error", err)
+		return
+	}
+	if guild := domain.GuildFromEntityState(entityState); guild != nil {
+		c.guildTools.Store(guild.ID, append([]string(nil), guild.SharedTools...))
+	}
+}
+
+// guildLock returns the mutex serializing treasury writes for a guild.
+func (c *Component) guildLock(guildID domain.GuildID) *sync.Mutex {
+	val, _ := c.guildLocks.LoadOrStore(guildID, &sync.Mutex{})
+	return val.(*sync.Mutex)
+}
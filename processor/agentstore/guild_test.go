@@ -0,0 +1,84 @@
+package agentstore
+
+import (
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semstreams/graph"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestHasTool_GuildSharedInventory(t *testing.T) {
+	c := &Component{}
+	member := &agentprogression.Agent{ID: "c360.prod.game.board1.agent.a1", Guild: "c360.prod.game.board1.guild.g1"}
+	outsider := &agentprogression.Agent{ID: "c360.prod.game.board1.agent.a2"}
+
+	c.trackAgentGuild(t.Context(), member)
+	c.trackAgentGuild(t.Context(), outsider)
+	c.guildTools.Store(member.Guild, []string{"web_search"})
+
+	if !c.HasTool(member.ID, "web_search") {
+		t.Error("guild member should have the shared tool")
+	}
+	if c.HasTool(member.ID, "deploy") {
+		t.Error("member should not have a tool the guild does not own")
+	}
+	if c.HasTool(outsider.ID, "web_search") {
+		t.Error("unguilded agent should not have the guild's tool")
+	}
+
+	// Leaving the guild revokes shared access.
+	member.Guild = ""
+	c.trackAgentGuild(t.Context(), member)
+	if c.HasTool(member.ID, "web_search") {
+		t.Error("former member should lose shared tool access")
+	}
+}
+
+func TestGuildLock_PerGuild(t *testing.T) {
+	c := &Component{}
+	a := c.guildLock(domain.GuildID("g1"))
+	if a != c.guildLock(domain.GuildID("g1")) {
+		t.Error("same guild should share a lock")
+	}
+	if a == c.guildLock(domain.GuildID("g2")) {
+		t.Error("different guilds should not share a lock")
+	}
+}
+
+// guildEntry is a KV watch entry carrying a guild entity.
+type guildEntry struct {
+	jetstream.KeyValueEntry
+	key   string
+	value []byte
+	op    jetstream.KeyValueOp
+}
+
+func (e guildEntry) Key() string                     { return e.key }
+func (e guildEntry) Value() []byte                   { return e.value }
+func (e guildEntry) Operation() jetstream.KeyValueOp { return e.op }
+
+func TestHandleGuildUpdate_RefreshesSharedTools(t *testing.T) {
+	c := &Component{logger: slog.Default()}
+	member := &agentprogression.Agent{ID: "c360.prod.game.board1.agent.a1", Guild: "c360.prod.game.board1.guild.g1"}
+	c.trackAgentGuild(t.Context(), member)
+	c.guildTools.Store(member.Guild, []string{"web_search"})
+
+	guild := &domain.Guild{ID: member.Guild, Name: "Scribes", Status: domain.GuildActive, SharedTools: []string{"web_search", "deploy"}}
+	data, err := json.Marshal(graph.EntityState{ID: guild.EntityID(), Triples: guild.Triples()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.handleGuildUpdate(guildEntry{key: guild.EntityID(), value: data, op: jetstream.KeyValuePut})
+	if !c.HasTool(member.ID, "deploy") {
+		t.Error("tool bought by another writer should be visible after the guild update")
+	}
+
+	c.handleGuildUpdate(guildEntry{key: guild.EntityID(), op: jetstream.KeyValueDelete})
+	if c.HasTool(member.ID, "web_search") {
+		t.Error("deleted guild should no longer grant shared tools")
+	}
+}
@@ -3,6 +3,7 @@ package agentstore
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	semdragons "github.com/c360studio/semdragons"
@@ -82,6 +83,14 @@ func (c *Component) handleAgentUpdate(entry jetstream.KeyValueEntry) {
 	// processors (autonomy, bossbattle) and rehydrates on first observation.
 	c.syncInventoryFromAgent(agent)
 
+	// Track XP supply for the economy report.
+	c.recordXPEarned(key, agent.Stats.TotalXPEarned, time.Now())
+
+	// Track guild membership for shared-inventory tool access.
+	guildCtx, cancel := context.WithTimeout(context.Background(), guildLoadTimeout)
+	c.trackAgentGuild(guildCtx, agent)
+	cancel()
+
 	// Detect XP changes by diffing against the cached value.
 	prevXP, hadPrev := c.agentXPCache.Load(key)
 	c.agentXPCache.Store(key, agent.XP)
@@ -127,9 +136,11 @@ func (c *Component) loadInitialInventories(ctx context.Context) error {
 
 		// Seed XP cache (keyed by full entity ID, same as the watcher)
 		c.agentXPCache.Store(string(agent.ID), agent.XP)
+		c.agentEarnedCache.Store(string(agent.ID), agent.Stats.TotalXPEarned)
 
 		// Populate inventory and effects caches from agent entity state
 		c.syncInventoryFromAgent(agent)
+		c.trackAgentGuild(ctx, agent)
 		loaded++
 	}
 
@@ -197,7 +208,9 @@ func (c *Component) syncInventoryFromAgent(agent *agentprogression.Agent) {
 				QuestsRemaining: ae.QuestsRemaining,
 				QuestID:         ae.QuestID,
 			}
-			// Look up catalog for full effect details
+			// Look up catalog for full effect details; items with custom IDs
+			// fall back to what the agent entity records.
+			effect.Effect = ConsumableEffect{Type: ConsumableType(ae.EffectType), Magnitude: ae.Magnitude}
 			if val, ok := c.catalog.Load(ae.EffectType); ok {
 				item := val.(*StoreItem)
 				if item.Effect != nil {
@@ -271,6 +284,7 @@ func (c *Component) AddItem(ctx context.Context, item StoreItem) error {
 	}
 
 	item.BoardConfig = c.boardConfig
+	prepareItem(&item)
 	c.catalog.Store(item.ID, &item)
 	now := time.Now()
 
@@ -327,6 +341,7 @@ func (c *Component) SeedCatalog(items []StoreItem) {
 	for i := range items {
 		item := items[i]
 		item.BoardConfig = c.boardConfig
+		prepareItem(&item)
 		c.catalog.Store(item.ID, &item)
 	}
 }
@@ -343,14 +358,17 @@ func (c *Component) Catalog() []StoreItem {
 
 // SetStock sets whether an item is in stock.
 func (c *Component) SetStock(itemID string, inStock bool) error {
+	c.pricingMu.Lock()
+	defer c.pricingMu.Unlock()
+
 	val, ok := c.catalog.Load(itemID)
 	if !ok {
 		return errors.New("item not found")
 	}
 
-	item := val.(*StoreItem)
-	item.InStock = inStock
-	c.catalog.Store(itemID, item)
+	updated := *val.(*StoreItem)
+	updated.InStock = inStock
+	c.catalog.Store(itemID, &updated)
 
 	return nil
 }
@@ -359,40 +377,42 @@ func (c *Component) SetStock(itemID string, inStock bool) error {
 // PURCHASE HANDLERS
 // =============================================================================
 
-// Purchase buys an item for an agent. Reads agent entity from KV, mutates
-// inventory + XP, and writes back via EmitEntityUpdate. If the agent is in any
-// guild and the item has a GuildDiscount > 0, the effective cost is reduced.
+// Purchase buys an item for an agent at the current quote. Reads agent entity
+// from KV, mutates inventory + XP, and writes back via EmitEntityUpdate. Guild
+// members pay less: the item's GuildDiscount plus a rank-based guild discount.
+// Limited-stock items lose one unit; with dynamic pricing the sale raises the
+// item's demand price.
 func (c *Component) Purchase(ctx context.Context, agentID domain.AgentID, itemID string, currentXP int64, currentLevel int, agentGuild domain.GuildID) (*OwnedItem, error) {
 	if !c.running.Load() {
 		return nil, errors.New("component not running")
 	}
 
-	// Get item
-	val, ok := c.catalog.Load(itemID)
-	if !ok {
-		return nil, errors.New("item not found")
+	// Quote the item (list price less guild discounts)
+	quote, err := c.Quote(ctx, itemID, agentID, agentGuild)
+	if err != nil {
+		return nil, err
 	}
-	item := val.(*StoreItem)
-
-	// Check if in stock
-	if !item.InStock {
+	if !quote.Available {
 		return nil, errors.New("item out of stock")
 	}
-
-	// Apply guild discount if the agent is in any guild and the item offers one.
-	effectiveCost := item.XPCost
-	if item.GuildDiscount > 0 && agentGuild != "" {
-		effectiveCost = int64(float64(item.XPCost) * (1.0 - item.GuildDiscount))
-		if effectiveCost < 0 {
-			effectiveCost = 0
-		}
-	}
+	effectiveCost := quote.Price
 
 	// Check affordability
 	if currentXP < effectiveCost {
 		return nil, errors.New("insufficient XP")
 	}
 
+	// Take a unit of limited stock atomically — a concurrent buyer may have
+	// taken the last one since the quote.
+	restocked, err := c.reserveStock(itemID)
+	if err != nil {
+		return nil, err
+	}
+	item, ok := c.GetItem(itemID)
+	if !ok {
+		return nil, errors.New("item not found")
+	}
+
 	now := time.Now()
 	newXP := currentXP - effectiveCost
 
@@ -493,6 +513,15 @@ func (c *Component) Purchase(ctx context.Context, agentID domain.AgentID, itemID
 		// Don't fail the purchase for inventory event failure
 	}
 
+	// Economy: record the sink, bump demand, and re-list on price/stock change
+	width, keep := c.economyWindow()
+	c.economy.recordSink(now, effectiveCost, width, keep)
+	if repriced := c.recordSale(itemID, now); repriced != nil {
+		c.relist(ctx, repriced, item.XPCost, "purchase")
+	} else if restocked != nil {
+		c.relist(ctx, restocked, item.XPCost, "purchase")
+	}
+
 	c.purchasesComplete.Add(1)
 	c.lastActivity.Store(now)
 
@@ -500,7 +529,7 @@ func (c *Component) Purchase(ctx context.Context, agentID domain.AgentID, itemID
 		"agent_id", agentID,
 		"item_id", itemID,
 		"xp_spent", effectiveCost,
-		"guild_discount_applied", item.GuildDiscount > 0 && agentGuild != "")
+		"guild_discount", quote.Discount)
 
 	return owned, nil
 }
@@ -528,10 +557,11 @@ func (c *Component) GetInventory(agentID domain.AgentID) *AgentInventory {
 	return c.getOrCreateInventory(agentID)
 }
 
-// HasTool checks if an agent has access to a tool.
+// HasTool checks if an agent has access to a tool, either owned or in
+// their guild's shared inventory.
 func (c *Component) HasTool(agentID domain.AgentID, toolID string) bool {
 	inv := c.getOrCreateInventory(agentID)
-	return inv.HasTool(toolID)
+	return inv.HasTool(toolID) || c.guildHasTool(agentID, toolID)
 }
 
 // getOrCreateInventory gets or creates an inventory for an agent.
@@ -557,6 +587,20 @@ func (c *Component) UseConsumable(ctx context.Context, agentID domain.AgentID, c
 		return errors.New("component not running")
 	}
 
+	// Get consumable item and its effect handler before touching inventory
+	val, ok := c.catalog.Load(consumableID)
+	if !ok {
+		return errors.New("consumable not found in catalog")
+	}
+	item := val.(*StoreItem)
+	if item.Effect == nil {
+		return errors.New("item is not a consumable")
+	}
+	handler, ok := domain.LookupEffect(string(item.Effect.Type))
+	if !ok {
+		return fmt.Errorf("no handler registered for effect %q", item.Effect.Type)
+	}
+
 	inv := c.getOrCreateInventory(agentID)
 
 	// Check and decrement under lock to prevent races with the watcher.
@@ -569,22 +613,20 @@ func (c *Component) UseConsumable(ctx context.Context, agentID domain.AgentID, c
 	remaining := inv.Consumables[consumableID]
 	inv.mu.Unlock()
 
-	// Get consumable item for effect info
-	val, ok := c.catalog.Load(consumableID)
-	if !ok {
-		return errors.New("consumable not found in catalog")
+	// Instant effects apply now and are only recorded in the local cache;
+	// everything else stays active on the agent for its duration.
+	persistent := handler.ConsumedOn != domain.EffectOnUse
+	duration := item.Effect.Duration
+	if persistent {
+		duration = max(duration, 1)
 	}
-	item := val.(*StoreItem)
-
-	// Create active effect (in-memory cache)
-	effect := ActiveEffect{
+	c.addActiveEffect(agentID, ActiveEffect{
 		ConsumableID:    consumableID,
 		Effect:          *item.Effect,
 		ActivatedAt:     time.Now(),
-		QuestsRemaining: item.Effect.Duration,
+		QuestsRemaining: duration,
 		QuestID:         questID,
-	}
-	c.addActiveEffect(agentID, effect)
+	})
 
 	now := time.Now()
 
@@ -603,22 +645,25 @@ func (c *Component) UseConsumable(ctx context.Context, agentID domain.AgentID, c
 				delete(agent.Consumables, consumableID)
 			}
 
-			// Add active effect to agent entity
-			agent.ActiveEffects = append(agent.ActiveEffects, agentprogression.AgentEffect{
-				EffectType:      string(item.Effect.Type),
-				QuestsRemaining: item.Effect.Duration,
-				QuestID:         questID,
-			})
+			// Add active effect to agent entity (stacks with the same type)
+			if persistent {
+				agent.AddEffect(agentprogression.AgentEffect{
+					EffectType:      string(item.Effect.Type),
+					QuestsRemaining: duration,
+					QuestID:         questID,
+					Magnitude:       item.Effect.Magnitude,
+				})
+			}
 
-			// Handle cooldown_skip: transition agent back to idle
-			if item.Effect.Type == ConsumableCooldownSkip && agent.Status == domain.AgentCooldown {
+			// Cooldown-skipping effects transition the agent back to idle
+			if handler.SkipsCooldown && agent.Status == domain.AgentCooldown {
 				agent.Status = domain.AgentIdle
 				agent.CooldownUntil = nil
 			}
 
 			agent.UpdatedAt = now
 			eventType := "agent.consumable.used"
-			if item.Effect.Type == ConsumableCooldownSkip && agent.Status == domain.AgentIdle {
+			if handler.SkipsCooldown && agent.Status == domain.AgentIdle {
 				eventType = "agent.status.idle"
 			}
 			if writeErr := c.graph.EmitEntityUpdate(ctx, agent, eventType); writeErr != nil {
@@ -50,11 +50,14 @@ type TraceInfo struct {
 // =============================================================================
 
 // StoreItemListedPayload contains data for store.item.listed events.
+// Price and stock changes are re-listed with ListedBy set to the reason
+// ("purchase", "restock", "reprice") and the price before the change.
 type StoreItemListedPayload struct {
-	Item     StoreItem `json:"item"`
-	ListedBy string    `json:"listed_by,omitempty"`
-	ListedAt time.Time `json:"listed_at"`
-	Trace    TraceInfo `json:"trace,omitempty"`
+	Item           StoreItem `json:"item"`
+	ListedBy       string    `json:"listed_by,omitempty"`
+	ListedAt       time.Time `json:"listed_at"`
+	PreviousXPCost int64     `json:"previous_xp_cost,omitempty"`
+	Trace          TraceInfo `json:"trace,omitempty"`
 }
 
 // EntityID returns the entity ID for this event.
@@ -0,0 +1,400 @@
+package agentstore
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+)
+
+// =============================================================================
+// PRICING & STOCK - Demand-driven list prices and finite inventory
+// =============================================================================
+// List price = base price × demand multiplier × inflation multiplier, capped at
+// MaxPriceMultiplier × base. Demand counts purchases of the item inside the
+// demand window; inflation compares the board's mean agent XP balance with
+// InflationBaselineXP. Guild members then get the item's GuildDiscount plus a
+// rank-based guild-wide discount.
+//
+// Catalog entries are replaced copy-on-write under pricingMu so concurrent
+// readers (ListItems, GetItem) never observe a half-updated item. Every price
+// or stock change is re-listed (KV update + StoreItemListed event).
+// =============================================================================
+
+// maxCombinedDiscount caps item + rank guild discounts.
+const maxCombinedDiscount = 0.5
+
+// PriceQuote is the price an agent would pay for an item right now.
+type PriceQuote struct {
+	ItemID              string  `json:"item_id"`
+	BasePrice           int64   `json:"base_price"`
+	ListPrice           int64   `json:"list_price"`
+	DemandMultiplier    float64 `json:"demand_multiplier"`
+	InflationMultiplier float64 `json:"inflation_multiplier"`
+	Discount            float64 `json:"discount"` // Combined guild discount (0-0.5)
+	Price               int64   `json:"price"`    // What the agent pays
+	Available           bool    `json:"available"`
+	Stock               int     `json:"stock"` // -1 = unlimited
+}
+
+// Quote returns the current price of an item for an agent, including guild
+// discounts. An unavailable item still gets a quote with Available=false.
+func (c *Component) Quote(ctx context.Context, itemID string, agentID domain.AgentID, guild domain.GuildID) (*PriceQuote, error) {
+	item, ok := c.GetItem(itemID)
+	if !ok {
+		return nil, errors.New("item not found")
+	}
+	return c.quoteItem(item, c.discountFor(item, guild, c.guildRank(ctx, guild, agentID))), nil
+}
+
+// quoteItem builds a quote from an item snapshot and a resolved discount.
+func (c *Component) quoteItem(item *StoreItem, discount float64) *PriceQuote {
+	base := basePrice(item)
+	q := &PriceQuote{
+		ItemID:              item.ID,
+		BasePrice:           base,
+		ListPrice:           item.XPCost,
+		DemandMultiplier:    1,
+		InflationMultiplier: 1,
+		Discount:            discount,
+		Price:               max(int64(float64(item.XPCost)*(1.0-discount)), 0),
+		Available:           item.InStock && (item.MaxStock == 0 || item.Stock > 0),
+		Stock:               -1,
+	}
+	if item.MaxStock > 0 {
+		q.Stock = item.Stock
+	}
+	if c.pricingConfig().DynamicPricing {
+		now := time.Now()
+		c.pricingMu.Lock()
+		q.DemandMultiplier = c.demandMultiplier(item.ID, now)
+		c.pricingMu.Unlock()
+		q.InflationMultiplier = c.inflationMultiplier()
+	}
+	return q
+}
+
+// discountFor returns the combined guild discount for a guild member.
+func (c *Component) discountFor(item *StoreItem, guild domain.GuildID, rank domain.GuildRank) float64 {
+	if guild == "" || !c.pricingConfig().EnableGuildDiscounts {
+		return 0
+	}
+	return min(item.GuildDiscount+rank.StoreDiscountRate(), maxCombinedDiscount)
+}
+
+// guildRank looks up the agent's rank in its guild. Returns "" when the
+// guild cannot be read — callers then get only the per-item discount.
+func (c *Component) guildRank(ctx context.Context, guildID domain.GuildID, agentID domain.AgentID) domain.GuildRank {
+	if guildID == "" || c.graph == nil {
+		return ""
+	}
+	entity, err := c.graph.GetGuild(ctx, guildID)
+	if err != nil {
+		return ""
+	}
+	guild := domain.GuildFromEntityState(entity)
+	if guild == nil {
+		return ""
+	}
+	instance := domain.ExtractInstance(string(agentID))
+	for _, m := range guild.Members {
+		if domain.ExtractInstance(string(m.AgentID)) == instance {
+			return m.Rank
+		}
+	}
+	return ""
+}
+
+// reserveStock takes one unit of a limited-stock item. Returns the updated
+// snapshot (nil when nothing changed because stock is unlimited).
+func (c *Component) reserveStock(itemID string) (*StoreItem, error) {
+	c.pricingMu.Lock()
+	defer c.pricingMu.Unlock()
+
+	item, ok := c.GetItem(itemID)
+	if !ok {
+		return nil, errors.New("item not found")
+	}
+	if !item.InStock {
+		return nil, errors.New("item out of stock")
+	}
+	if item.MaxStock == 0 {
+		return nil, nil
+	}
+	if item.Stock <= 0 {
+		return nil, errors.New("item out of stock")
+	}
+
+	updated := *item
+	updated.Stock--
+	updated.InStock = updated.Stock > 0
+	c.catalog.Store(itemID, &updated)
+	return &updated, nil
+}
+
+// releaseStock returns a unit taken by reserveStock when the purchase that
+// reserved it fails. No-op for unlimited items.
+func (c *Component) releaseStock(itemID string) {
+	c.pricingMu.Lock()
+	defer c.pricingMu.Unlock()
+
+	item, ok := c.GetItem(itemID)
+	if !ok || item.MaxStock == 0 {
+		return
+	}
+	updated := *item
+	updated.Stock = min(updated.Stock+1, updated.MaxStock)
+	updated.InStock = true
+	c.catalog.Store(itemID, &updated)
+}
+
+// recordSale notes a purchase for demand pricing and reprices the item.
+// Returns the repriced snapshot, or nil when the price did not change.
+func (c *Component) recordSale(itemID string, now time.Time) *StoreItem {
+	c.pricingMu.Lock()
+	defer c.pricingMu.Unlock()
+
+	if c.recentSales == nil {
+		c.recentSales = make(map[string][]time.Time)
+	}
+	c.recentSales[itemID] = append(c.recentSales[itemID], now)
+
+	if !c.pricingConfig().DynamicPricing {
+		return nil
+	}
+	item, ok := c.GetItem(itemID)
+	if !ok {
+		return nil
+	}
+	return c.repriceLocked(item, now)
+}
+
+// repriceLocked recomputes the list price. Caller holds pricingMu.
+// Returns the new snapshot, or nil when unchanged.
+func (c *Component) repriceLocked(item *StoreItem, now time.Time) *StoreItem {
+	cfg := c.pricingConfig()
+	base := basePrice(item)
+	price := listPrice(base, c.demandMultiplier(item.ID, now), c.inflationMultiplier(), cfg.MaxPriceMultiplier)
+	if price == item.XPCost && item.BaseXPCost == base {
+		return nil
+	}
+	updated := *item
+	updated.BaseXPCost = base
+	updated.XPCost = price
+	c.catalog.Store(item.ID, &updated)
+	return &updated
+}
+
+// restockLocked adds units to a limited-stock item whose restock interval
+// has elapsed. Caller holds pricingMu. Returns the new snapshot or nil.
+func (c *Component) restockLocked(item *StoreItem, now time.Time) *StoreItem {
+	if item.MaxStock == 0 || item.RestockIntervalSec <= 0 || item.Stock >= item.MaxStock {
+		return nil
+	}
+	interval := time.Duration(item.RestockIntervalSec) * time.Second
+	if item.LastRestockAt != nil && now.Sub(*item.LastRestockAt) < interval {
+		return nil
+	}
+
+	updated := *item
+	if updated.RestockAmount <= 0 {
+		updated.Stock = updated.MaxStock
+	} else {
+		updated.Stock = min(updated.Stock+updated.RestockAmount, updated.MaxStock)
+	}
+	updated.InStock = updated.Stock > 0
+	restockedAt := now
+	updated.LastRestockAt = &restockedAt
+	c.catalog.Store(item.ID, &updated)
+	return &updated
+}
+
+// refreshCatalog restocks and reprices every item, re-listing those that
+// changed. Runs on the pricing ticker.
+func (c *Component) refreshCatalog(ctx context.Context, now time.Time) {
+	type change struct {
+		item     *StoreItem
+		previous int64
+		reason   string
+	}
+	var changes []change
+
+	dynamic := c.pricingConfig().DynamicPricing
+	c.pricingMu.Lock()
+	c.pruneSalesLocked(now)
+	c.catalog.Range(func(_, value any) bool {
+		item := value.(*StoreItem)
+		previous := item.XPCost
+		reason := ""
+		if restocked := c.restockLocked(item, now); restocked != nil {
+			item = restocked
+			reason = "restock"
+		}
+		if dynamic {
+			if repriced := c.repriceLocked(item, now); repriced != nil {
+				item = repriced
+				if reason == "" {
+					reason = "reprice"
+				}
+			}
+		}
+		if reason != "" {
+			changes = append(changes, change{item: item, previous: previous, reason: reason})
+		}
+		return true
+	})
+	c.pricingMu.Unlock()
+
+	for _, ch := range changes {
+		c.relist(ctx, ch.item, ch.previous, ch.reason)
+	}
+}
+
+// relist writes a changed item to KV and publishes a StoreItemListed update.
+func (c *Component) relist(ctx context.Context, item *StoreItem, previousCost int64, reason string) {
+	now := time.Now()
+	if c.graph != nil {
+		if err := c.graph.EmitEntityUpdate(ctx, item, "store.item."+reason); err != nil {
+			c.logger.Warn("failed to write store item update to KV", "item_id", item.ID, "error", err)
+		}
+	}
+	if c.deps.NATSClient != nil {
+		if err := SubjectStoreItemListed.Publish(ctx, c.deps.NATSClient, StoreItemListedPayload{
+			Item:           *item,
+			ListedBy:       reason,
+			ListedAt:       now,
+			PreviousXPCost: previousCost,
+		}); err != nil {
+			c.errorsCount.Add(1)
+		}
+	}
+	c.economy.recordPrice(item.ID, item.XPCost, now)
+	c.logger.Debug("store item re-listed",
+		"item_id", item.ID, "reason", reason,
+		"xp_cost", item.XPCost, "previous_xp_cost", previousCost, "stock", item.Stock)
+}
+
+// runPricingLoop periodically restocks and reprices the catalog.
+func (c *Component) runPricingLoop() {
+	interval := time.Duration(c.pricingConfig().RepriceIntervalSec) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopChan:
+			return
+		case now := <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			c.refreshCatalog(ctx, now)
+			cancel()
+		}
+	}
+}
+
+// demandMultiplier is 1 + sensitivity × purchases inside the demand window.
+// Caller holds pricingMu.
+func (c *Component) demandMultiplier(itemID string, now time.Time) float64 {
+	cfg := c.pricingConfig()
+	window := time.Duration(cfg.DemandWindowSec) * time.Second
+	recent := 0
+	for _, t := range c.recentSales[itemID] {
+		if now.Sub(t) <= window {
+			recent++
+		}
+	}
+	return 1 + cfg.DemandSensitivity*float64(recent)
+}
+
+// pruneSalesLocked drops sales older than the demand window.
+func (c *Component) pruneSalesLocked(now time.Time) {
+	window := time.Duration(c.pricingConfig().DemandWindowSec) * time.Second
+	for id, sales := range c.recentSales {
+		kept := sales[:0]
+		for _, t := range sales {
+			if now.Sub(t) <= window {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) == 0 {
+			delete(c.recentSales, id)
+		} else {
+			c.recentSales[id] = kept
+		}
+	}
+}
+
+// inflationMultiplier rises as the mean agent XP balance exceeds the
+// configured baseline. Never below 1 — deflation does not discount items.
+func (c *Component) inflationMultiplier() float64 {
+	cfg := c.pricingConfig()
+	if cfg.InflationBaselineXP <= 0 {
+		return 1
+	}
+	mean, agents := c.meanAgentXP()
+	if agents == 0 {
+		return 1
+	}
+	return 1 + cfg.InflationSensitivity*math.Max(0, mean/float64(cfg.InflationBaselineXP)-1)
+}
+
+// meanAgentXP returns the mean cached XP balance and the number of agents.
+func (c *Component) meanAgentXP() (float64, int) {
+	var total int64
+	agents := 0
+	c.agentXPCache.Range(func(_, v any) bool {
+		total += v.(int64)
+		agents++
+		return true
+	})
+	if agents == 0 {
+		return 0, 0
+	}
+	return float64(total) / float64(agents), agents
+}
+
+// listPrice applies multipliers to the base price, capped at maxMultiplier.
+func listPrice(base int64, demand, inflation, maxMultiplier float64) int64 {
+	mult := demand * inflation
+	if maxMultiplier > 0 {
+		mult = math.Min(mult, maxMultiplier)
+	}
+	return int64(math.Round(float64(base) * math.Max(mult, 1)))
+}
+
+// basePrice is the item's list price before dynamic adjustments.
+func basePrice(item *StoreItem) int64 {
+	if item.BaseXPCost > 0 {
+		return item.BaseXPCost
+	}
+	return item.XPCost
+}
+
+// prepareItem normalizes pricing and stock fields when an item enters the
+// catalog: the base price defaults to the catalog price and limited items
+// start fully stocked.
+func prepareItem(item *StoreItem) {
+	if item.BaseXPCost == 0 {
+		item.BaseXPCost = item.XPCost
+	}
+	if item.MaxStock > 0 {
+		if item.Stock == 0 && item.LastRestockAt == nil {
+			item.Stock = item.MaxStock
+		}
+		item.InStock = item.InStock && item.Stock > 0
+	}
+}
+
+// pricingConfig returns the component config, or defaults for a zero-value
+// component (tests seed the catalog without a config).
+func (c *Component) pricingConfig() Config {
+	if c.config == nil {
+		return DefaultConfig()
+	}
+	return *c.config
+}
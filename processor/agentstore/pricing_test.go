@@ -0,0 +1,228 @@
+package agentstore
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+)
+
+func dynamicPricingComponent() *Component {
+	cfg := DefaultConfig()
+	cfg.DynamicPricing = true
+	cfg.DemandSensitivity = 0.1
+	cfg.DemandWindowSec = 60
+	cfg.InflationBaselineXP = 100
+	cfg.InflationSensitivity = 0.5
+	cfg.MaxPriceMultiplier = 2
+	return &Component{config: &cfg, logger: slog.Default()}
+}
+
+func TestListPrice(t *testing.T) {
+	tests := []struct {
+		name                     string
+		base                     int64
+		demand, inflation, limit float64
+		want                     int64
+	}{
+		{"no pressure", 100, 1, 1, 3, 100},
+		{"demand", 100, 1.25, 1, 3, 125},
+		{"demand and inflation compound", 100, 1.2, 1.5, 3, 180},
+		{"capped", 100, 2, 2, 3, 300},
+		{"never below base", 100, 0.5, 1, 3, 100},
+		{"no cap configured", 100, 5, 1, 0, 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := listPrice(tt.base, tt.demand, tt.inflation, tt.limit); got != tt.want {
+				t.Errorf("listPrice = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordSale_RaisesPriceWithDemand(t *testing.T) {
+	c := dynamicPricingComponent()
+	c.SeedCatalog([]StoreItem{{ID: "boost", Name: "Boost", ItemType: ItemTypeConsumable, XPCost: 100, InStock: true}})
+
+	now := time.Now()
+	if got := c.recordSale("boost", now); got == nil || got.XPCost != 110 {
+		t.Fatalf("after 1 sale: %+v, want xp_cost 110", got)
+	}
+	c.recordSale("boost", now)
+	item, _ := c.GetItem("boost")
+	if item.XPCost != 120 || item.BaseXPCost != 100 {
+		t.Errorf("after 2 sales: xp_cost=%d base=%d, want 120/100", item.XPCost, item.BaseXPCost)
+	}
+
+	// Demand decays once sales fall outside the window.
+	c.refreshCatalog(t.Context(), now.Add(2*time.Minute))
+	item, _ = c.GetItem("boost")
+	if item.XPCost != 100 {
+		t.Errorf("after window: xp_cost=%d, want 100", item.XPCost)
+	}
+}
+
+func TestRecordSale_StaticPricingUnchanged(t *testing.T) {
+	c := &Component{}
+	c.SeedCatalog([]StoreItem{{ID: "boost", Name: "Boost", XPCost: 100, InStock: true}})
+	if got := c.recordSale("boost", time.Now()); got != nil {
+		t.Errorf("static pricing should not reprice, got %+v", got)
+	}
+}
+
+func TestInflationMultiplier(t *testing.T) {
+	c := dynamicPricingComponent()
+	if got := c.inflationMultiplier(); got != 1 {
+		t.Errorf("no agents: %v, want 1", got)
+	}
+	c.agentXPCache.Store("a", int64(300))
+	c.agentXPCache.Store("b", int64(100))
+	// Mean 200 against baseline 100 → 1 + 0.5×(2-1).
+	if got := c.inflationMultiplier(); got != 1.5 {
+		t.Errorf("mean 200: %v, want 1.5", got)
+	}
+
+	c.agentXPCache.Store("a", int64(10))
+	c.agentXPCache.Store("b", int64(10))
+	if got := c.inflationMultiplier(); got != 1 {
+		t.Errorf("below baseline: %v, want 1", got)
+	}
+}
+
+func TestReserveStockAndRestock(t *testing.T) {
+	c := &Component{logger: slog.Default()}
+	c.SeedCatalog([]StoreItem{{
+		ID: "rare", Name: "Rare", XPCost: 50, InStock: true,
+		MaxStock: 2, RestockAmount: 1, RestockIntervalSec: 60,
+	}})
+
+	item, _ := c.GetItem("rare")
+	if item.Stock != 2 || !item.InStock {
+		t.Fatalf("limited item should start full: stock=%d in_stock=%v", item.Stock, item.InStock)
+	}
+
+	for range 2 {
+		if _, err := c.reserveStock("rare"); err != nil {
+			t.Fatalf("reserveStock: %v", err)
+		}
+	}
+	item, _ = c.GetItem("rare")
+	if item.Stock != 0 || item.InStock {
+		t.Errorf("after selling out: stock=%d in_stock=%v", item.Stock, item.InStock)
+	}
+	if _, err := c.reserveStock("rare"); err == nil {
+		t.Error("reserveStock should fail when sold out")
+	}
+
+	now := time.Now()
+	c.refreshCatalog(t.Context(), now)
+	item, _ = c.GetItem("rare")
+	if item.Stock != 1 || !item.InStock {
+		t.Errorf("after restock: stock=%d in_stock=%v, want 1/true", item.Stock, item.InStock)
+	}
+
+	// Interval not yet elapsed — no second restock.
+	c.refreshCatalog(t.Context(), now.Add(30*time.Second))
+	item, _ = c.GetItem("rare")
+	if item.Stock != 1 {
+		t.Errorf("restock before interval: stock=%d, want 1", item.Stock)
+	}
+}
+
+func TestReleaseStock(t *testing.T) {
+	c := &Component{}
+	c.SeedCatalog([]StoreItem{{ID: "rare", Name: "Rare", XPCost: 50, InStock: true, MaxStock: 1}})
+	if _, err := c.reserveStock("rare"); err != nil {
+		t.Fatal(err)
+	}
+	c.releaseStock("rare")
+	item, _ := c.GetItem("rare")
+	if item.Stock != 1 || !item.InStock {
+		t.Errorf("after release: stock=%d in_stock=%v, want 1/true", item.Stock, item.InStock)
+	}
+
+	// Release never exceeds the stock cap.
+	c.releaseStock("rare")
+	if item, _ = c.GetItem("rare"); item.Stock != 1 {
+		t.Errorf("release above cap: stock=%d, want 1", item.Stock)
+	}
+}
+
+func TestReserveStock_UnlimitedIsNoop(t *testing.T) {
+	c := &Component{}
+	c.SeedCatalog([]StoreItem{{ID: "tool", Name: "Tool", XPCost: 50, InStock: true}})
+	got, err := c.reserveStock("tool")
+	if err != nil || got != nil {
+		t.Errorf("unlimited reserve = %+v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestDiscountFor_GuildRank(t *testing.T) {
+	c := &Component{}
+	item := &StoreItem{GuildDiscount: 0.1}
+
+	if got := c.discountFor(item, "", domain.GuildRankOfficer); got != 0 {
+		t.Errorf("no guild: %v, want 0", got)
+	}
+	if got := c.discountFor(item, "g1", domain.GuildRankOfficer); got < 0.159 || got > 0.161 {
+		t.Errorf("officer: %v, want 0.16", got)
+	}
+	if got := c.discountFor(&StoreItem{GuildDiscount: 0.49}, "g1", domain.GuildRankMaster); got != maxCombinedDiscount {
+		t.Errorf("capped: %v, want %v", got, maxCombinedDiscount)
+	}
+}
+
+func TestQuote_SoldOutUnavailable(t *testing.T) {
+	c := &Component{}
+	c.SeedCatalog([]StoreItem{{ID: "rare", Name: "Rare", XPCost: 50, InStock: true, MaxStock: 1}})
+	if _, err := c.reserveStock("rare"); err != nil {
+		t.Fatal(err)
+	}
+	q, err := c.Quote(t.Context(), "rare", "a1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Available || q.Stock != 0 || q.Price != 50 {
+		t.Errorf("quote = %+v, want unavailable at 50 with stock 0", q)
+	}
+}
+
+func TestEconomyReport(t *testing.T) {
+	c := &Component{}
+	c.SeedCatalog([]StoreItem{{ID: "tool", Name: "Tool", XPCost: 50, InStock: true}})
+	now := time.Now()
+
+	c.recordXPEarned("agent-1", 100, now) // baseline only
+	c.recordXPEarned("agent-1", 400, now)
+	width, keep := c.economyWindow()
+	c.economy.recordSink(now, 75, width, keep)
+	c.agentXPCache.Store("agent-1", int64(325))
+
+	report := c.EconomyReport()
+	if report.TotalSupplied != 300 || report.TotalSpent != 75 || report.NetFlow != 225 {
+		t.Errorf("totals = %d/%d/%d, want 300/75/225", report.TotalSupplied, report.TotalSpent, report.NetFlow)
+	}
+	if report.SinkRatio != 0.25 {
+		t.Errorf("sink ratio = %v, want 0.25", report.SinkRatio)
+	}
+	if report.CirculatingXP != 325 || report.AgentsObserved != 1 {
+		t.Errorf("circulating = %d over %d agents", report.CirculatingXP, report.AgentsObserved)
+	}
+	if len(report.Prices) != 1 || report.Prices[0].Price != 50 {
+		t.Errorf("prices = %+v", report.Prices)
+	}
+}
+
+func TestEconomyLedger_EvictsOldBuckets(t *testing.T) {
+	var l economyLedger
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		l.recordSupply(start.Add(time.Duration(i)*time.Hour), 10, time.Hour, 3)
+	}
+	buckets, _ := l.snapshot()
+	if len(buckets) != 3 || !buckets[0].Start.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("buckets = %+v, want the 3 newest", buckets)
+	}
+}
@@ -36,16 +36,17 @@ const (
 	PurchaseRental    PurchaseType = "rental"
 )
 
-// ConsumableType identifies specific consumable effects.
+// ConsumableType identifies a consumable's effect. Any type with a handler
+// registered via domain.RegisterEffect is valid; these are the built-ins.
 type ConsumableType string
 
 // Consumable effect kind values.
 const (
-	ConsumableRetryToken    ConsumableType = "retry_token"
-	ConsumableCooldownSkip  ConsumableType = "cooldown_skip"
-	ConsumableXPBoost       ConsumableType = "xp_boost"
-	ConsumableQualityShield ConsumableType = "quality_shield"
-	ConsumableInsightScroll ConsumableType = "insight_scroll"
+	ConsumableRetryToken    ConsumableType = domain.EffectRetryToken
+	ConsumableCooldownSkip  ConsumableType = domain.EffectCooldownSkip
+	ConsumableXPBoost       ConsumableType = domain.EffectXPBoost
+	ConsumableQualityShield ConsumableType = domain.EffectQualityShield
+	ConsumableInsightScroll ConsumableType = domain.EffectInsightScroll
 )
 
 // =============================================================================
@@ -60,8 +61,10 @@ type StoreItem struct {
 	ItemType     ItemType     `json:"item_type"`
 	PurchaseType PurchaseType `json:"purchase_type"`
 
-	// Pricing
+	// Pricing. XPCost is the current list price; with dynamic pricing it is
+	// recomputed from BaseXPCost (defaults to the catalog XPCost).
 	XPCost     int64 `json:"xp_cost"`
+	BaseXPCost int64 `json:"base_xp_cost,omitempty"`
 	RentalUses int   `json:"rental_uses,omitempty"`
 
 	// Gating
@@ -78,6 +81,14 @@ type StoreItem struct {
 	InStock       bool    `json:"in_stock"`
 	GuildDiscount float64 `json:"guild_discount,omitempty"`
 
+	// Limited stock (MaxStock 0 = unlimited). Every RestockIntervalSec the
+	// store adds RestockAmount units (0 = refill to MaxStock).
+	Stock              int        `json:"stock,omitempty"`
+	MaxStock           int        `json:"max_stock,omitempty"`
+	RestockAmount      int        `json:"restock_amount,omitempty"`
+	RestockIntervalSec int        `json:"restock_interval_sec,omitempty"`
+	LastRestockAt      *time.Time `json:"last_restock_at,omitempty"`
+
 	// BoardConfig determines the entity ID prefix for this item.
 	// Set before calling EntityID() or emitting to KV.
 	BoardConfig *domain.BoardConfig `json:"-"`
@@ -207,6 +218,22 @@ func (s *StoreItem) Triples() []message.Triple {
 		{Subject: entityID, Predicate: "store.item.rental_uses", Object: s.RentalUses, Source: source, Timestamp: now, Confidence: 1.0},
 		{Subject: entityID, Predicate: "store.item.min_level", Object: s.MinLevel, Source: source, Timestamp: now, Confidence: 1.0},
 		{Subject: entityID, Predicate: "store.item.guild_discount", Object: s.GuildDiscount, Source: source, Timestamp: now, Confidence: 1.0},
+		{Subject: entityID, Predicate: "store.item.base_xp_cost", Object: s.BaseXPCost, Source: source, Timestamp: now, Confidence: 1.0},
+	}
+
+	if s.MaxStock > 0 {
+		triples = append(triples,
+			message.Triple{Subject: entityID, Predicate: "store.item.stock", Object: s.Stock, Source: source, Timestamp: now, Confidence: 1.0},
+			message.Triple{Subject: entityID, Predicate: "store.item.max_stock", Object: s.MaxStock, Source: source, Timestamp: now, Confidence: 1.0},
+			message.Triple{Subject: entityID, Predicate: "store.item.restock_amount", Object: s.RestockAmount, Source: source, Timestamp: now, Confidence: 1.0},
+			message.Triple{Subject: entityID, Predicate: "store.item.restock_interval_sec", Object: s.RestockIntervalSec, Source: source, Timestamp: now, Confidence: 1.0},
+		)
+		if s.LastRestockAt != nil {
+			triples = append(triples, message.Triple{
+				Subject: entityID, Predicate: "store.item.last_restock_at", Object: s.LastRestockAt.Format(time.RFC3339),
+				Source: source, Timestamp: now, Confidence: 1.0,
+			})
+		}
 	}
 
 	if s.ToolID != "" {
@@ -292,6 +319,20 @@ func StoreItemFromEntityState(entity *graph.EntityState) *StoreItem {
 			s.MinLevel = tripleInt(triple.Object)
 		case "store.item.guild_discount":
 			s.GuildDiscount = tripleFloat64(triple.Object)
+		case "store.item.base_xp_cost":
+			s.BaseXPCost = tripleInt64(triple.Object)
+		case "store.item.stock":
+			s.Stock = tripleInt(triple.Object)
+		case "store.item.max_stock":
+			s.MaxStock = tripleInt(triple.Object)
+		case "store.item.restock_amount":
+			s.RestockAmount = tripleInt(triple.Object)
+		case "store.item.restock_interval_sec":
+			s.RestockIntervalSec = tripleInt(triple.Object)
+		case "store.item.last_restock_at":
+			if t, err := time.Parse(time.RFC3339, tripleString(triple.Object)); err == nil {
+				s.LastRestockAt = &t
+			}
 		case "store.item.effect_type":
 			if s.Effect == nil {
 				s.Effect = &ConsumableEffect{}
@@ -307,7 +307,7 @@ func (c *Component) executeShop(ctx context.Context, agent *agentprogression.Age
 		return nil
 	}
 
-	items := store.ListItems(agent.Tier)
+	items := quotedItems(ctx, store, agent)
 	item := pickBestItem(agent, items, budget)
 	if item == nil {
 		return nil
@@ -384,7 +384,7 @@ func (c *Component) executeShopStrategic(ctx context.Context, agent *agentprogre
 		return nil
 	}
 
-	items := store.ListItems(agent.Tier)
+	items := quotedItems(ctx, store, agent)
 
 	// Priority: quality_shield > xp_boost
 	priorities := []agentstore.ConsumableType{
@@ -450,6 +450,23 @@ func (c *Component) executeShopStrategic(ctx context.Context, agent *agentprogre
 	return nil
 }
 
+// quotedItems lists the items visible to the agent with XPCost replaced by
+// the agent's current quote (demand/inflation pricing and guild discounts).
+// Sold-out items are dropped.
+func quotedItems(ctx context.Context, store AgentStoreRef, agent *agentprogression.Agent) []agentstore.StoreItem {
+	items := store.ListItems(agent.Tier)
+	quoted := items[:0]
+	for _, item := range items {
+		q, err := store.Quote(ctx, item.ID, agent.ID, agent.Guild)
+		if err != nil || !q.Available {
+			continue
+		}
+		item.XPCost = q.Price
+		quoted = append(quoted, item)
+	}
+	return quoted
+}
+
 // consumableForStatus returns the consumable ID to use for a given agent status,
 // or empty string if no consumable applies.
 func consumableForStatus(status domain.AgentStatus) string {
@@ -1009,6 +1026,149 @@ func (c *Component) scoreApplication(founder *agentprogression.Agent, guild *dom
 	return total, reason
 }
 
+// =============================================================================
+// GUILD GOVERNANCE ACTIONS
+// =============================================================================
+
+// disbandReputationThreshold is the guild reputation below which members vote
+// to disband.
+const disbandReputationThreshold = 0.2
+
+// voteGuildProposalsAction returns the action for members to vote on open
+// guild proposals they have not voted on yet.
+func (c *Component) voteGuildProposalsAction() action {
+	return action{
+		name: "vote_guild_proposals",
+		shouldExecute: func(agent *agentprogression.Agent, _ *agentTracker) bool {
+			guilds := c.resolveGuilds()
+			if guilds == nil {
+				return false
+			}
+			if agent.Status != domain.AgentIdle && agent.Status != domain.AgentCooldown {
+				return false
+			}
+			gid := guilds.GetAgentGuild(agent.ID)
+			if gid == "" {
+				return false
+			}
+			for _, p := range guilds.ListProposals(gid) {
+				if p.Status == domain.ProposalOpen && p.VoteBy(agent.ID) == nil {
+					return true
+				}
+			}
+			return false
+		},
+		execute: func(ctx context.Context, agent *agentprogression.Agent, _ *agentTracker) error {
+			return c.executeVoteGuildProposals(ctx, agent)
+		},
+	}
+}
+
+// executeVoteGuildProposals votes on every open proposal the agent can judge.
+func (c *Component) executeVoteGuildProposals(ctx context.Context, agent *agentprogression.Agent) error {
+	guilds := c.resolveGuilds()
+	if guilds == nil {
+		return nil
+	}
+	gid := guilds.GetAgentGuild(agent.ID)
+	if gid == "" {
+		return nil
+	}
+	guild, ok := guilds.GetGuild(gid)
+	if !ok {
+		return nil
+	}
+
+	for i := range guild.Proposals {
+		p := &guild.Proposals[i]
+		if p.Status != domain.ProposalOpen || p.VoteBy(agent.ID) != nil {
+			continue
+		}
+		approve, reason, ok := c.judgeProposal(agent, guild, p)
+		if !ok {
+			continue // Abstain: not enough information about the target
+		}
+		if _, err := guilds.CastVote(ctx, gid, p.ID, agent.ID, approve, reason); err != nil {
+			c.logger.Warn("failed to cast guild vote",
+				"guild_id", gid,
+				"proposal_id", p.ID,
+				"error", err)
+			continue
+		}
+		c.logger.Info("agent voted on guild proposal",
+			"guild_id", gid,
+			"proposal_id", p.ID,
+			"kind", p.Kind,
+			"approve", approve,
+			"reason", reason)
+	}
+	return nil
+}
+
+// judgeProposal decides how the voter votes. Applicants and members up for a
+// rank change are scored with the same heuristic founders use to review
+// applications, blended with the voter's peer-review history with the target
+// when there is one. ok is false when the voter should abstain.
+func (c *Component) judgeProposal(voter *agentprogression.Agent, guild *domain.Guild, p *domain.GuildProposal) (approve bool, reason string, ok bool) {
+	switch p.Kind {
+	case domain.ProposalAcceptApplicant:
+		for i := range guild.Applications {
+			if guild.Applications[i].ID == p.ApplicationID {
+				score, why := c.scoreApplication(voter, guild, &guild.Applications[i])
+				return score >= reviewAcceptThreshold, fmt.Sprintf("score %.2f: %s", score, why), true
+			}
+		}
+		return false, "", false
+
+	case domain.ProposalPromote, domain.ProposalDemote:
+		target := c.trackedAgent(p.TargetID)
+		if target == nil {
+			return false, "", false
+		}
+		// Score the target as if applying, against the rest of the guild.
+		others := *guild
+		others.Members = nil
+		for _, m := range guild.Members {
+			if domain.ExtractInstance(string(m.AgentID)) != domain.ExtractInstance(string(target.ID)) {
+				others.Members = append(others.Members, m)
+			}
+		}
+		profile := &domain.GuildApplication{ApplicantID: target.ID, Level: target.Level, Tier: target.Tier}
+		for skill := range target.SkillProficiencies {
+			profile.Skills = append(profile.Skills, skill)
+		}
+		score, why := c.scoreApplication(voter, &others, profile)
+		if guilds := c.resolveGuilds(); guilds != nil {
+			if peer, known := guilds.PairwisePeerScore(voter.ID, target.ID); known {
+				score = (score + peer) / 2
+				why += fmt.Sprintf(", peer score %.2f", peer)
+			}
+		}
+		merits := score >= reviewAcceptThreshold
+		if p.Kind == domain.ProposalDemote {
+			return !merits, fmt.Sprintf("score %.2f: %s", score, why), true
+		}
+		return merits, fmt.Sprintf("score %.2f: %s", score, why), true
+
+	case domain.ProposalDisband:
+		if guild.Reputation < disbandReputationThreshold {
+			return true, fmt.Sprintf("reputation %.2f", guild.Reputation), true
+		}
+		return false, fmt.Sprintf("reputation %.2f", guild.Reputation), true
+	}
+	return false, "", false
+}
+
+// trackedAgent returns the heartbeat-tracked agent with the given ID, or nil.
+func (c *Component) trackedAgent(id domain.AgentID) *agentprogression.Agent {
+	c.trackersMu.RLock()
+	defer c.trackersMu.RUnlock()
+	if tracker, ok := c.trackers[domain.ExtractInstance(string(id))]; ok {
+		return tracker.agent
+	}
+	return nil
+}
+
 // agentGuildName creates a guild name from the founding agent's display name or name.
 func agentGuildName(agent *agentprogression.Agent) string {
 	name := agent.Name
@@ -1025,3 +1185,151 @@ func levelAbs(x int) int {
 	}
 	return x
 }
+
+// =============================================================================
+// GUILD TREASURY ACTIONS
+// =============================================================================
+
+// guildSpendAction returns the action for officers and guildmasters to spend
+// the guild treasury. Shared tools are preferred since every member benefits;
+// otherwise a cooldown_skip is sponsored for a member stuck on cooldown.
+func (c *Component) guildSpendAction() action {
+	return action{
+		name: "guild_spend",
+		shouldExecute: func(agent *agentprogression.Agent, _ *agentTracker) bool {
+			guilds := c.resolveGuilds()
+			if guilds == nil || c.resolveStore() == nil || agent.Guild == "" {
+				return false
+			}
+			guild, ok := guilds.GetGuild(agent.Guild)
+			if !ok || guild.Status != domain.GuildActive {
+				return false
+			}
+			member := guild.Member(agent.ID)
+			return member != nil && member.Rank.CanSpendTreasury()
+		},
+		execute: func(ctx context.Context, agent *agentprogression.Agent, _ *agentTracker) error {
+			return c.executeGuildSpend(ctx, agent)
+		},
+	}
+}
+
+// executeGuildSpend reads the guild's current treasury and makes at most one
+// purchase per heartbeat.
+func (c *Component) executeGuildSpend(ctx context.Context, agent *agentprogression.Agent) error {
+	store := c.resolveStore()
+	if store == nil || c.graph == nil {
+		return nil
+	}
+
+	// The treasury is maintained on the guild entity, not the in-memory roster.
+	entity, err := c.graph.GetGuild(ctx, agent.Guild)
+	if err != nil {
+		return nil
+	}
+	guild := domain.GuildFromEntityState(entity)
+	if guild == nil || guild.Treasury < c.config.GuildSpendMinTreasury {
+		return nil
+	}
+	budget := int64(float64(guild.Treasury) * c.config.GuildSpendRatio)
+
+	items := quotedItems(ctx, store, agent)
+	if tool := cheapestSharedTool(guild, items, budget); tool != nil {
+		if !c.requestApproval(ctx, domain.ApprovalAutonomyShop,
+			fmt.Sprintf("Officer %s wants to buy %s for %s", agent.Name, tool.Name, guild.Name),
+			fmt.Sprintf("Item: %s (cost: %d XP, treasury: %d XP)", tool.Name, tool.XPCost, guild.Treasury),
+			map[string]any{"agent_id": agent.ID, "guild_id": guild.ID, "item_id": tool.ID, "xp_cost": tool.XPCost},
+		) {
+			c.logger.Info("guild purchase denied by DM", "agent_id", agent.ID, "item_id", tool.ID)
+			return nil
+		}
+		if _, err := store.BuyForGuild(ctx, guild.ID, agent.ID, tool.ID); err != nil {
+			c.logger.Warn("autonomous guild purchase failed",
+				"agent_id", agent.ID,
+				"guild_id", guild.ID,
+				"item_id", tool.ID,
+				"error", err)
+			return err
+		}
+		c.logger.Info("officer bought tool for guild",
+			"agent_id", agent.ID,
+			"guild_id", guild.ID,
+			"item_id", tool.ID,
+			"cost", tool.XPCost)
+		return nil
+	}
+
+	memberID := c.memberNeedingCooldownSkip(guild)
+	if memberID == "" {
+		return nil
+	}
+	for i := range items {
+		item := &items[i]
+		if item.ID != string(agentstore.ConsumableCooldownSkip) || item.XPCost > budget {
+			continue
+		}
+		if !c.requestApproval(ctx, domain.ApprovalAutonomyShop,
+			fmt.Sprintf("Officer %s wants to sponsor %s for %s", agent.Name, item.Name, memberID),
+			fmt.Sprintf("Item: %s (cost: %d XP, treasury: %d XP)", item.Name, item.XPCost, guild.Treasury),
+			map[string]any{"agent_id": agent.ID, "guild_id": guild.ID, "member_id": memberID, "item_id": item.ID},
+		) {
+			c.logger.Info("guild sponsorship denied by DM", "agent_id", agent.ID, "member_id", memberID)
+			return nil
+		}
+		if _, err := store.SponsorConsumable(ctx, guild.ID, agent.ID, memberID, item.ID); err != nil {
+			c.logger.Warn("autonomous guild sponsorship failed",
+				"agent_id", agent.ID,
+				"guild_id", guild.ID,
+				"member_id", memberID,
+				"error", err)
+			return err
+		}
+		c.logger.Info("officer sponsored member consumable",
+			"agent_id", agent.ID,
+			"guild_id", guild.ID,
+			"member_id", memberID,
+			"item_id", item.ID)
+		return nil
+	}
+	return nil
+}
+
+// cheapestSharedTool returns the cheapest permanent tool within budget that
+// the guild does not already share.
+func cheapestSharedTool(guild *domain.Guild, items []agentstore.StoreItem, budget int64) *agentstore.StoreItem {
+	var best *agentstore.StoreItem
+	for i := range items {
+		item := &items[i]
+		if item.ItemType != agentstore.ItemTypeTool || item.PurchaseType != agentstore.PurchasePermanent {
+			continue
+		}
+		if item.XPCost > budget || guild.HasSharedTool(item.ID) {
+			continue
+		}
+		if best == nil || item.XPCost < best.XPCost {
+			best = item
+		}
+	}
+	return best
+}
+
+// memberNeedingCooldownSkip returns a tracked guild member with a long
+// cooldown ahead and no cooldown_skip in hand, or "" when none qualifies.
+func (c *Component) memberNeedingCooldownSkip(guild *domain.Guild) domain.AgentID {
+	c.trackersMu.RLock()
+	defer c.trackersMu.RUnlock()
+
+	for _, tracker := range c.trackers {
+		member := tracker.agent
+		if member == nil || member.Status != domain.AgentCooldown || member.CooldownUntil == nil {
+			continue
+		}
+		if guild.Member(member.ID) == nil || hasConsumable(member, string(agentstore.ConsumableCooldownSkip)) {
+			continue
+		}
+		if time.Until(*member.CooldownUntil) > c.config.CooldownSkipMinRemaining() {
+			return member.ID
+		}
+	}
+	return ""
+}
@@ -34,9 +34,12 @@ import (
 // The concrete *agentstore.Component satisfies this interface.
 type AgentStoreRef interface {
 	ListItems(tier domain.TrustTier) []agentstore.StoreItem
+	Quote(ctx context.Context, itemID string, agentID domain.AgentID, guild domain.GuildID) (*agentstore.PriceQuote, error)
 	Purchase(ctx context.Context, agentID domain.AgentID, itemID string, currentXP int64, currentLevel int, guild domain.GuildID) (*agentstore.OwnedItem, error)
 	UseConsumable(ctx context.Context, agentID domain.AgentID, consumableID string, questID *domain.QuestID) error
 	SeedCatalog(items []agentstore.StoreItem)
+	BuyForGuild(ctx context.Context, guildID domain.GuildID, officerID domain.AgentID, itemID string) (*domain.TreasuryTransaction, error)
+	SponsorConsumable(ctx context.Context, guildID domain.GuildID, officerID, memberID domain.AgentID, itemID string) (*domain.TreasuryTransaction, error)
 }
 
 // GuildFormationRef is the narrow interface autonomy needs from guildformation.
@@ -53,6 +56,8 @@ type GuildFormationRef interface {
 	ListPendingGuilds() []*domain.Guild
 	SubmitApplication(ctx context.Context, guildID domain.GuildID, agent *agentprogression.Agent, message string) error
 	ReviewApplication(ctx context.Context, guildID domain.GuildID, applicationID string, founderID domain.AgentID, accepted bool, reason string) error
+	ListProposals(guildID domain.GuildID) []domain.GuildProposal
+	CastVote(ctx context.Context, guildID domain.GuildID, proposalID string, voterID domain.AgentID, approve bool, reason string) (*domain.GuildProposal, error)
 }
 
 // DMApprovalRef is the narrow interface autonomy needs from dmapproval.
@@ -319,6 +324,18 @@ func (c *Component) ConfigSchema() component.ConfigSchema {
 				Default:     200,
 				Category:    "shopping",
 			},
+			"guild_spend_min_treasury": {
+				Type:        "int",
+				Description: "Minimum guild treasury balance before officers spend it autonomously",
+				Default:     100,
+				Category:    "shopping",
+			},
+			"guild_spend_ratio": {
+				Type:        "float",
+				Description: "Fraction of the guild treasury a single autonomous purchase may use",
+				Default:     0.5,
+				Category:    "shopping",
+			},
 			"cooldown_skip_min_remaining_ms": {
 				Type:        "int",
 				Description: "Minimum remaining cooldown in milliseconds to justify using skip consumable",
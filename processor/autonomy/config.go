@@ -32,6 +32,10 @@ type Config struct {
 	CooldownShopMinXP       int64   `json:"cooldown_shop_min_xp"`        // Min XP to shop during cooldown
 	StrategicShopMaxCost    int64   `json:"strategic_shop_max_cost"`     // Max XP to spend on strategic mid-quest purchase
 
+	// Guild treasury spending (officers and guildmasters)
+	GuildSpendMinTreasury int64   `json:"guild_spend_min_treasury"` // Min treasury balance before officers spend
+	GuildSpendRatio       float64 `json:"guild_spend_ratio"`        // Fraction of the treasury one purchase may use
+
 	// Consumable use thresholds
 	CooldownSkipMinRemainingMs int `json:"cooldown_skip_min_remaining_ms"` // Min remaining cooldown (ms) to justify using skip
 
@@ -59,6 +63,8 @@ func DefaultConfig() Config {
 		MaxShopSpendRatio:          0.5,
 		CooldownShopMinXP:          25,
 		StrategicShopMaxCost:       200,
+		GuildSpendMinTreasury:      100,
+		GuildSpendRatio:            0.5,
 		CooldownSkipMinRemainingMs: 30000,
 
 		DMMode:            domain.DMFullAuto,
@@ -196,9 +196,11 @@ func (c *Component) actionsForState(status domain.AgentStatus) []action {
 	case domain.AgentIdle:
 		return []action{
 			c.reviewGuildApplicationsAction(), // Founders process applications before claiming quests
+			c.voteGuildProposalsAction(),      // Members vote on open guild proposals
 			c.claimQuestAction(),
 			c.joinGuildAction(),   // Boid-driven: fires when boid.guild suggestion of type "join" is cached
 			c.createGuildAction(), // Boid-driven: fires when boid.guild suggestion of type "form" is cached
+			c.guildSpendAction(),  // Officers spend the guild treasury
 			// Store disabled for MVP — consumable effects not wired to gameplay
 		}
 	case domain.AgentOnQuest:
@@ -208,7 +210,9 @@ func (c *Component) actionsForState(status domain.AgentStatus) []action {
 	case domain.AgentCooldown:
 		return []action{
 			c.reviewGuildApplicationsAction(),
-			c.joinGuildAction(), // Boid-driven: fires on cooldown too
+			c.voteGuildProposalsAction(),
+			c.joinGuildAction(),  // Boid-driven: fires on cooldown too
+			c.guildSpendAction(), // Officers spend the guild treasury
 		}
 	default:
 		// Retired or unknown
@@ -70,7 +70,7 @@ func TestActionsForState_Idle(t *testing.T) {
 	c := newTestComponent()
 	actions := c.actionsForState(domain.AgentIdle)
 	// Store disabled for MVP — no use_consumable or shop actions
-	want := []string{"review_guild_applications", "claim_quest", "join_guild", "create_guild"}
+	want := []string{"review_guild_applications", "vote_guild_proposals", "claim_quest", "join_guild", "create_guild", "guild_spend"}
 
 	if len(actions) != len(want) {
 		t.Fatalf("idle actions: got %d, want %d", len(actions), len(want))
@@ -105,7 +105,7 @@ func TestActionsForState_Cooldown(t *testing.T) {
 	c := newTestComponent()
 	actions := c.actionsForState(domain.AgentCooldown)
 	// Store disabled for MVP — no cooldown_skip or shop
-	want := []string{"review_guild_applications", "join_guild"}
+	want := []string{"review_guild_applications", "vote_guild_proposals", "join_guild", "guild_spend"}
 
 	if len(actions) != len(want) {
 		t.Fatalf("cooldown actions: got %d, want %d", len(actions), len(want))
@@ -712,6 +712,24 @@ func TestPickBestItem_SkipsOwnedTools(t *testing.T) {
 	}
 }
 
+func TestCheapestSharedTool(t *testing.T) {
+	guild := &domain.Guild{SharedTools: []string{"shared"}}
+	items := []agentstore.StoreItem{
+		{ID: "shared", ItemType: agentstore.ItemTypeTool, PurchaseType: agentstore.PurchasePermanent, XPCost: 10},
+		{ID: "rental", ItemType: agentstore.ItemTypeTool, PurchaseType: agentstore.PurchaseRental, XPCost: 20},
+		{ID: "pricey", ItemType: agentstore.ItemTypeTool, PurchaseType: agentstore.PurchasePermanent, XPCost: 500},
+		{ID: "cheap", ItemType: agentstore.ItemTypeTool, PurchaseType: agentstore.PurchasePermanent, XPCost: 80},
+		{ID: "cheaper", ItemType: agentstore.ItemTypeTool, PurchaseType: agentstore.PurchasePermanent, XPCost: 60},
+	}
+	result := cheapestSharedTool(guild, items, 100)
+	if result == nil || result.ID != "cheaper" {
+		t.Errorf("cheapestSharedTool should pick the cheapest unshared permanent tool, got %v", result)
+	}
+	if result := cheapestSharedTool(guild, items, 50); result != nil {
+		t.Errorf("cheapestSharedTool should respect the budget, got %v", result)
+	}
+}
+
 func TestPickBestItem_CapsConsumableAt2(t *testing.T) {
 	items := []agentstore.StoreItem{
 		{ID: "stocked_consumable", ItemType: agentstore.ItemTypeConsumable, XPCost: 50},
@@ -1738,3 +1756,51 @@ func TestConfigSchema_ApprovalProperties(t *testing.T) {
 	}
 }
 
+
+// =============================================================================
+// GUILD GOVERNANCE TESTS
+// =============================================================================
+
+func TestJudgeProposal(t *testing.T) {
+	c := newTestComponent()
+	voter := &agentprogression.Agent{ID: "test.local.game.board1.agent.voter", Level: 10}
+	strong := &agentprogression.Agent{
+		ID:                 "test.local.game.board1.agent.strong",
+		Level:              10,
+		Tier:               domain.TierMaster,
+		SkillProficiencies: map[domain.SkillTag]domain.SkillProficiency{domain.SkillCodeGen: {}},
+	}
+	c.trackers["strong"] = &agentTracker{agent: strong}
+
+	guild := &domain.Guild{
+		Reputation: 0.8,
+		Members: []domain.GuildMember{
+			{AgentID: voter.ID, Rank: domain.GuildRankMaster},
+			{AgentID: strong.ID, Rank: domain.GuildRankInitiate},
+		},
+		Applications: []domain.GuildApplication{
+			{ID: "weak-app", Level: 1, Tier: domain.TierApprentice},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		proposal    domain.GuildProposal
+		wantApprove bool
+		wantOK      bool
+	}{
+		{"promote strong member", domain.GuildProposal{Kind: domain.ProposalPromote, TargetID: strong.ID}, true, true},
+		{"demote strong member", domain.GuildProposal{Kind: domain.ProposalDemote, TargetID: strong.ID}, false, true},
+		{"untracked target abstains", domain.GuildProposal{Kind: domain.ProposalPromote, TargetID: "ghost"}, false, false},
+		{"weak applicant", domain.GuildProposal{Kind: domain.ProposalAcceptApplicant, ApplicationID: "weak-app"}, false, true},
+		{"healthy guild stays", domain.GuildProposal{Kind: domain.ProposalDisband}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			approve, reason, ok := c.judgeProposal(voter, guild, &tt.proposal)
+			if approve != tt.wantApprove || ok != tt.wantOK {
+				t.Errorf("judgeProposal = %v/%v (%s), want %v/%v", approve, ok, reason, tt.wantApprove, tt.wantOK)
+			}
+		})
+	}
+}
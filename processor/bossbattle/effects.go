@@ -0,0 +1,88 @@
+package bossbattle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+)
+
+// =============================================================================
+// CONSUMABLE EFFECTS - Judge adjustment and retry grants
+// =============================================================================
+
+// applyJudgementEffects runs the agent's judge-adjusting effects (e.g.,
+// quality_shield) over a failed evaluation and recomputes the verdict.
+// Checklist failures stay fatal — effects only forgive criterion results.
+// Returns true when any effect took part.
+func applyJudgementEffects(result *EvaluationResult, battle *BossBattle, quest *domain.Quest, agent *agentprogression.Agent) bool {
+	if result == nil || result.Pending || result.Verdict.Passed || agent == nil {
+		return false
+	}
+
+	adjusted, applied := agent.AdjustJudgement(quest, battle.Criteria, result.Results)
+	if len(applied) == 0 {
+		return false
+	}
+
+	passed := true
+	for _, r := range adjusted {
+		if !r.Passed {
+			passed = false
+			break
+		}
+	}
+	for _, cl := range result.ChecklistResults {
+		if !cl.Passed {
+			passed = false
+			break
+		}
+	}
+
+	result.Results = adjusted
+	result.Verdict.Passed = passed
+	result.Verdict.Feedback = strings.TrimSpace(result.Verdict.Feedback +
+		fmt.Sprintf(" [effects applied: %s]", strings.Join(applied, ", ")))
+	return true
+}
+
+// loadClaimingAgent returns the agent that claimed quest, or nil.
+func (c *Component) loadClaimingAgent(ctx context.Context, quest *domain.Quest) *agentprogression.Agent {
+	if quest == nil || quest.ClaimedBy == nil {
+		return nil
+	}
+	entity, err := c.graph.GetAgent(ctx, *quest.ClaimedBy)
+	if err != nil {
+		return nil
+	}
+	return agentprogression.AgentFromEntityState(entity)
+}
+
+// absorbTerminalDefeat lets a retry-granting effect (e.g., retry_token) turn
+// a terminal solo defeat into one more attempt. The effect is consumed and
+// the quest's MaxAttempts raised so the normal retry path reposts it.
+func (c *Component) absorbTerminalDefeat(ctx context.Context, quest *domain.Quest) bool {
+	if quest.PartyID != nil || quest.Attempts < quest.MaxAttempts {
+		return false
+	}
+	agent := c.loadClaimingAgent(ctx, quest)
+	if agent == nil || !agent.HasRetryGrant() {
+		return false
+	}
+
+	agent.ConsumeEffects(domain.EffectOnFailure)
+	agent.UpdatedAt = time.Now()
+	if err := c.graph.EmitEntityUpdate(ctx, agent, "agent.effect.consumed"); err != nil {
+		c.errorsCount.Add(1)
+		c.logger.Error("failed to consume retry effect", "agent", agent.ID, "error", err)
+		return false
+	}
+
+	quest.MaxAttempts = max(quest.MaxAttempts+1, 2)
+	c.logger.Info("retry effect absorbed battle defeat",
+		"quest", quest.ID, "agent", agent.ID, "max_attempts", quest.MaxAttempts)
+	return true
+}
@@ -0,0 +1,71 @@
+package bossbattle
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+)
+
+// =============================================================================
+// applyJudgementEffects TESTS
+// =============================================================================
+
+func shieldedAgent() *agentprogression.Agent {
+	return &agentprogression.Agent{ActiveEffects: []agentprogression.AgentEffect{
+		{EffectType: domain.EffectQualityShield, QuestsRemaining: 1},
+	}}
+}
+
+func TestApplyJudgementEffects_ShieldFlipsVerdict(t *testing.T) {
+	battle := &BossBattle{Criteria: []domain.ReviewCriterion{
+		{Name: "correctness", Threshold: 0.7, Weight: 0.5},
+		{Name: "style", Threshold: 0.5, Weight: 0.5},
+	}}
+	result := &EvaluationResult{
+		Results: []domain.ReviewResult{
+			{CriterionName: "correctness", Score: 0.9, Passed: true},
+			{CriterionName: "style", Score: 0.4, Passed: false},
+		},
+		Verdict: domain.BattleVerdict{Passed: false, Feedback: "style lacking"},
+	}
+
+	if !applyJudgementEffects(result, battle, nil, shieldedAgent()) {
+		t.Fatal("shield should apply to a failed verdict")
+	}
+	if !result.Verdict.Passed {
+		t.Error("verdict should pass once the only failed criterion is forgiven")
+	}
+	if !strings.Contains(result.Verdict.Feedback, "quality_shield") {
+		t.Errorf("feedback should note the effect, got %q", result.Verdict.Feedback)
+	}
+}
+
+func TestApplyJudgementEffects_ChecklistStaysFatal(t *testing.T) {
+	battle := &BossBattle{Criteria: []domain.ReviewCriterion{{Name: "style", Threshold: 0.5}}}
+	result := &EvaluationResult{
+		Results:          []domain.ReviewResult{{CriterionName: "style", Score: 0.4}},
+		ChecklistResults: []ChecklistResult{{Name: "tests", Passed: false}},
+	}
+
+	applyJudgementEffects(result, battle, nil, shieldedAgent())
+	if result.Verdict.Passed {
+		t.Error("checklist failures must not be forgiven")
+	}
+}
+
+func TestApplyJudgementEffects_NoopCases(t *testing.T) {
+	battle := &BossBattle{}
+	passed := &EvaluationResult{Verdict: domain.BattleVerdict{Passed: true}}
+	if applyJudgementEffects(passed, battle, nil, shieldedAgent()) {
+		t.Error("passed verdicts need no adjustment")
+	}
+	failed := &EvaluationResult{Results: []domain.ReviewResult{{CriterionName: "x"}}}
+	if applyJudgementEffects(failed, battle, nil, &agentprogression.Agent{}) {
+		t.Error("agent without effects should not adjust")
+	}
+	if applyJudgementEffects(failed, battle, nil, nil) {
+		t.Error("nil agent should not adjust")
+	}
+}
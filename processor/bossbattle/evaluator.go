@@ -13,6 +13,7 @@ import (
 	agenticmodel "github.com/c360studio/semstreams/processor/agentic-model"
 
 	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/executor/testreport"
 	"github.com/c360studio/semdragons/processor/promptmanager"
 	"github.com/c360studio/semdragons/processor/tokenbudget"
 )
@@ -127,7 +128,7 @@ func (e *DefaultBattleEvaluator) Evaluate(ctx context.Context, battle *BossBattl
 		}
 	}
 
-	return computeVerdict(results, battle, "Automated evaluation complete"), nil
+	return computeVerdict(results, battle, "Automated evaluation complete", testChecklist(output)...), nil
 }
 
 var _ BattleEvaluator = (*DefaultBattleEvaluator)(nil)
@@ -222,7 +223,7 @@ func (e *DomainAwareEvaluator) Evaluate(ctx context.Context, battle *BossBattle,
 	)
 
 	// Format the quest output for the user message.
-	userMessage := formatOutputForJudge(output)
+	userMessage := formatOutputForJudge(output) + formatUntrustedForJudge(output)
 
 	// Call the LLM
 	judgeResult, err := e.callLLMJudge(ctx, endpoint, assembled.SystemMessage, userMessage, battle)
@@ -246,7 +247,8 @@ func (e *DomainAwareEvaluator) Evaluate(ctx context.Context, battle *BossBattle,
 		feedback = "LLM judge evaluation complete"
 	}
 
-	verdict := computeVerdict(results, battle, feedback, judgeResult.ChecklistResults...)
+	checklistResults := append(judgeResult.ChecklistResults, testChecklist(output)...)
+	verdict := computeVerdict(results, battle, feedback, checklistResults...)
 	verdict.PeerRatings = judgeResult.PeerRatings
 	verdict.LoopID = judgeResult.LoopID
 	return verdict, nil
@@ -483,11 +485,23 @@ func formatOutputForJudge(output any) string {
 		return "No output was provided."
 	}
 
-	// Check for combined output with red-team findings.
+	// Check for combined output with red-team findings and/or an automated
+	// test run.
 	if m, ok := output.(map[string]any); ok {
 		if questOutput, hasQO := m["quest_output"]; hasQO {
-			if rtFindings, hasRT := m["red_team_findings"]; hasRT {
-				return formatCombinedOutput(questOutput, rtFindings)
+			rtFindings, hasRT := m["red_team_findings"]
+			report, hasTests := m["test_report"].(*testreport.Report)
+			if hasRT || hasTests {
+				var formatted string
+				if hasRT {
+					formatted = formatCombinedOutput(questOutput, rtFindings)
+				} else {
+					formatted = formatOutputForJudge(questOutput)
+				}
+				if hasTests {
+					formatted += formatTestReportForJudge(report)
+				}
+				return formatted
 			}
 		}
 	}
@@ -542,6 +556,43 @@ func formatCombinedOutput(questOutput, rtFindings any) string {
 	return b.String()
 }
 
+// formatUntrustedForJudge lists the untrusted content blocks quoted in the
+// output, so the judge knows which text came from third parties rather than
+// the agent. Returns "" when there are none.
+func formatUntrustedForJudge(output any) string {
+	list := promptmanager.FormatUntrustedSegments(promptmanager.UntrustedSegmentsIn(output))
+	if list == "" {
+		return ""
+	}
+	return "\n\n---\n\n## Untrusted Segments\n\n" + list
+}
+
+// formatTestReportForJudge renders the automated test run as its own
+// section so the judge scores against actual results, not the agent's claims.
+func formatTestReportForJudge(report *testreport.Report) string {
+	return "\n\n---\n\n## Automated Test Run\n\n" +
+		"The workspace's tests were run independently of the agent. " +
+		"Failing tests are an automatic structural failure.\n\n" +
+		"```\n" + report.Format() + "```"
+}
+
+// testChecklist turns an automated test run attached to the battle output
+// into a structural check, so failing tests defeat the battle however the
+// criteria are scored. Runs that neither ran nor failed to build anything
+// (e.g. the runner is not installed) are shown to the judge but not checked.
+func testChecklist(output any) []ChecklistResult {
+	m, _ := output.(map[string]any)
+	report, _ := m["test_report"].(*testreport.Report)
+	if report == nil || (report.Total() == 0 && len(report.Failures) == 0) {
+		return nil
+	}
+	return []ChecklistResult{{
+		Name:      "tests-pass",
+		Passed:    report.OK(),
+		Reasoning: report.Summary(),
+	}}
+}
+
 // clampScore ensures a score is within [0.0, 1.0].
 func clampScore(s float64) float64 {
 	if s < 0 {
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/c360studio/semstreams/agentic"
@@ -17,6 +19,7 @@ import (
 	semdragons "github.com/c360studio/semdragons"
 	"github.com/c360studio/semdragons/domain"
 	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semdragons/processor/executor"
 )
 
 // =============================================================================
@@ -257,6 +260,36 @@ func (c *Component) buildBattleOutput(ctx context.Context, quest *domain.Quest)
 	}
 }
 
+// battleTestTimeout bounds the automated test run before evaluation, leaving
+// the rest of the battle timeout for the judge.
+const battleTestTimeout = 3 * time.Minute
+
+// withTestReport runs the quest workspace's tests in the sandbox and attaches
+// the report to the judge output. Only code quests are tested; when there is
+// no sandbox, no detectable framework or the run fails, output is returned
+// unchanged.
+func (c *Component) withTestReport(ctx context.Context, quest *domain.Quest, output any) any {
+	if c.sandboxClient == nil || !slices.Contains(quest.RequiredSkills, domain.SkillCodeGen) {
+		return output
+	}
+	report, err := c.sandboxClient.RunTests(ctx, string(quest.ID), executor.TestRunRequest{Timeout: battleTestTimeout})
+	if err != nil {
+		c.logger.Debug("no automated test run for battle", "quest", quest.ID, "error", err)
+		return output
+	}
+	c.logger.Info("automated test run for battle",
+		"quest", quest.ID, "passed", report.Passed, "failed", report.Failed, "skipped", report.Skipped)
+
+	wrapped := map[string]any{"quest_output": output}
+	if m, ok := output.(map[string]any); ok {
+		if _, combined := m["quest_output"]; combined {
+			wrapped = maps.Clone(m)
+		}
+	}
+	wrapped["test_report"] = report
+	return wrapped
+}
+
 // findRedTeamOutput loads the red-team quest for the given original quest
 // and returns its output, or nil if none found. Uses the RedTeamQuestID
 // field written by the redteam processor for direct lookup (no scan).
@@ -606,6 +639,9 @@ func (c *Component) runEvaluation(ctx context.Context, ab *activeBattle) {
 		c.activeBattles.Delete(ab.battle.ID)
 	}()
 
+	// Run the workspace's tests first so the judge sees real results.
+	ab.output = c.withTestReport(ctx, ab.quest, ab.output)
+
 	// Run evaluation
 	result, err := c.evaluator.Evaluate(ctx, ab.battle, ab.quest, ab.output)
 
@@ -632,6 +668,14 @@ func (c *Component) runEvaluation(ctx context.Context, ab *activeBattle) {
 			"pending_judge", result.PendingJudge)
 		return
 	} else {
+		// Let the agent's consumable effects adjust a failed judgement.
+		if !result.Verdict.Passed {
+			if agent := c.loadClaimingAgent(ctx, ab.quest); applyJudgementEffects(result, ab.battle, ab.quest, agent) {
+				c.logger.Info("consumable effects adjusted battle verdict",
+					"battle", ab.battle.ID, "passed", result.Verdict.Passed)
+			}
+		}
+
 		// Complete with verdict
 		if result.Verdict.Passed {
 			ab.battle.Status = domain.BattleVictory
@@ -669,6 +713,9 @@ func (c *Component) runEvaluation(ctx context.Context, ab *activeBattle) {
 		// Bridge battle verdict → quest completion/failure
 		// Safe: no other processor modifies a quest while it's in_review.
 		if ab.quest != nil {
+			if !ab.battle.Verdict.Passed {
+				c.absorbTerminalDefeat(persistCtx, ab.quest)
+			}
 			if ab.battle.Verdict.Passed {
 				verdictNow := time.Now()
 				ab.quest.Status = domain.QuestCompleted
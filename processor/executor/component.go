@@ -13,6 +13,7 @@ import (
 	"github.com/c360studio/semdragons/domain"
 	"github.com/c360studio/semdragons/processor/agentprogression"
 	"github.com/c360studio/semdragons/processor/promptmanager"
+	"github.com/c360studio/semdragons/processor/tokenbudget"
 	"github.com/c360studio/semstreams/component"
 	"github.com/c360studio/semstreams/model"
 	"github.com/c360studio/semstreams/pkg/errs"
@@ -37,6 +38,9 @@ type Component struct {
 	registry     model.RegistryReader
 	toolRegistry *ToolRegistry
 	executor     *DefaultExecutor
+	mcpClients   []*MCPClient             // Connected MCP tool servers, closed on Stop
+	tokenLedger  *tokenbudget.TokenLedger // Charged for priced tool calls (nil = not recorded)
+	search       SearchProvider           // web_search provider (nil = not registered)
 
 	// Internal state
 	running  atomic.Bool
@@ -249,7 +253,25 @@ func (c *Component) Start(ctx context.Context) error {
 			c.logger.Warn("web_search tool disabled", "reason", err.Error())
 		} else {
 			c.toolRegistry.RegisterWebSearch(sp)
+			c.search = sp
 			c.logger.Info("web_search tool registered", "provider", c.config.Search.Provider)
+			c.indexWebContent(ctx, sp)
+		}
+	}
+	if c.config.Secrets != nil {
+		vault, err := OpenSecretVault(ctx, c.deps.NATSClient, c.config.Secrets.KeyEnv)
+		if err != nil {
+			c.logger.Warn("secret placeholders disabled", "reason", err.Error())
+		} else {
+			c.toolRegistry.SetSecrets(vault)
+		}
+	}
+	if c.config.WebCache != nil {
+		cache, err := OpenWebCache(ctx, c.deps.NATSClient, *c.config.WebCache)
+		if err != nil {
+			c.logger.Warn("web cache disabled", "reason", err.Error())
+		} else {
+			SetWebCache(cache)
 		}
 	}
 
@@ -261,6 +283,28 @@ func (c *Component) Start(ctx context.Context) error {
 		c.toolRegistry.RegisterGraphQuery(c.buildGraphQueryFunc())
 	}
 
+	// Apply the tool-call policy before any tools are reachable.
+	if c.config.ToolPolicy != nil {
+		if err := c.toolRegistry.SetPolicy(c.config.ToolPolicy); err != nil {
+			return errs.Wrap(err, "Executor", "Start", "apply tool policy")
+		}
+	}
+
+	if err := c.toolRegistry.SetToolLimits(c.config.ToolLimits); err != nil {
+		return errs.Wrap(err, "Executor", "Start", "apply tool limits")
+	}
+	if err := c.toolRegistry.SetContentSafety(c.config.ContentSafety); err != nil {
+		return errs.Wrap(err, "Executor", "Start", "apply content safety")
+	}
+	if c.tokenLedger != nil {
+		c.toolRegistry.SetCostRecorder(c.tokenLedger)
+	}
+
+	// Register tools discovered from configured MCP servers.
+	if len(c.config.MCPServers) > 0 {
+		c.mcpClients = c.toolRegistry.RegisterMCPServers(ctx, c.config.MCPServers, c.logger)
+	}
+
 	// Create prompt assembler if domain catalog is configured
 	opts := []Option{
 		WithMaxTurns(c.config.MaxTurns),
@@ -292,6 +336,35 @@ func (c *Component) Start(ctx context.Context) error {
 	return nil
 }
 
+// SetTokenLedger injects the shared token ledger that priced tool calls are
+// charged to. Takes effect immediately when the component is running.
+func (c *Component) SetTokenLedger(l *tokenbudget.TokenLedger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokenLedger = l
+	if c.toolRegistry != nil && l != nil {
+		c.toolRegistry.SetCostRecorder(l)
+	}
+}
+
+// ToolUsage returns per-tool usage counters. Nil before Start.
+func (c *Component) ToolUsage() []ToolUsageStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.toolRegistry == nil {
+		return nil
+	}
+	return c.toolRegistry.Usage()
+}
+
+// SearchHealth reports the health of each provider behind a multi
+// web_search provider. Nil when web_search does not fan out.
+func (c *Component) SearchHealth() []SearchProviderHealth {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return SearchHealth(c.search)
+}
+
 // Stop gracefully shuts down the component.
 // Timeout is unused: shutdown is non-blocking with no background goroutines to wait for.
 func (c *Component) Stop(_ time.Duration) error {
@@ -304,6 +377,13 @@ func (c *Component) Stop(_ time.Duration) error {
 
 	close(c.stopChan)
 
+	for _, client := range c.mcpClients {
+		if err := client.Close(); err != nil {
+			c.logger.Warn("close mcp server failed", "server", client.Name(), "error", err)
+		}
+	}
+	c.mcpClients = nil
+
 	c.running.Store(false)
 	c.logger.Info("executor component stopped")
 
@@ -317,6 +397,25 @@ func (c *Component) createGraphClient(_ context.Context) error {
 	return nil
 }
 
+// indexWebContent makes persisted web pages searchable when the search
+// provider has a local index, loading pages already in the graph in the
+// background.
+func (c *Component) indexWebContent(ctx context.Context, sp SearchProvider) {
+	index := LocalSearchIndexOf(sp)
+	if index == nil {
+		return
+	}
+	SetWebContentIndex(index)
+	go func() {
+		n, err := index.IndexWebContent(ctx, c.graph)
+		if err != nil {
+			c.logger.Warn("local search: persisted web content not indexed", "error", err)
+			return
+		}
+		c.logger.Info("local search index loaded", "documents", index.Len(), "web_pages", n)
+	}()
+}
+
 // buildGraphQueryFunc returns an EntityQueryFunc that reads entities from the
 // board KV bucket and formats them as a compact text summary for agents.
 func (c *Component) buildGraphQueryFunc() EntityQueryFunc {
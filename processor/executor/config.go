@@ -26,6 +26,30 @@ type Config struct {
 	// Search configures the web_search tool. When nil/empty provider, web_search
 	// is not registered. Supports "brave" (more providers can be added).
 	Search *SearchConfig `json:"search,omitempty"`
+	// Secrets enables {{secret:name}} placeholders in http_request headers and
+	// bash env, resolved from the encrypted TOOL_SECRETS bucket. Nil disables
+	// them.
+	Secrets *SecretsConfig `json:"secrets,omitempty"`
+	// WebCache shares http_request and web_search results across agents
+	// through the WEB_CACHE bucket. Nil disables caching.
+	WebCache *WebCacheConfig `json:"web_cache,omitempty"`
+	// ContentSafety scans http_request, web_search and explore results for
+	// prompt-injection attempts and wraps them in untrusted-content blocks.
+	// Nil disables the stage.
+	ContentSafety *ContentSafetyConfig `json:"content_safety,omitempty"`
+
+	// MCPServers are Model Context Protocol tool servers whose tools are
+	// registered as mcp_<server>_<tool>. A server that fails to connect at
+	// startup is logged and skipped.
+	MCPServers []MCPServerConfig `json:"mcp_servers,omitempty"`
+
+	// ToolPolicy restricts tool calls by tool, arguments, tier, guild and
+	// quest. Nil allows every call that passes the tier and skill gates.
+	ToolPolicy *ToolPolicy `json:"tool_policy,omitempty"`
+
+	// ToolLimits sets rate limits, quotas and per-call costs by tool name or
+	// glob, overriding the defaults tools are registered with.
+	ToolLimits map[string]ToolLimits `json:"tool_limits,omitempty"`
 
 	// Domain prompt catalog (optional). When set, enables domain-aware prompt assembly
 	// instead of legacy string concatenation.
@@ -0,0 +1,987 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/internal/realpath"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semstreams/agentic"
+)
+
+// =============================================================================
+// FILE TOOLS - read_file, write_file, apply_patch, search_code, list_files
+// =============================================================================
+// Structured alternatives to driving everything through bash. Each tool works
+// against a workspaceFS: the local sandbox directory for RegisterBuiltins, or
+// the quest's workspace in the sandbox container for RegisterSandboxTools.
+// Paths are always workspace-relative and confined the same way the sandbox
+// server's resolveQuestPath confines them.
+// =============================================================================
+
+// File tool limits.
+const (
+	// maxReadFileLines caps how many lines a single read_file call returns.
+	maxReadFileLines = 2000
+	// maxWriteFileBytes matches the sandbox server's default -max-file-size.
+	maxWriteFileBytes = 1 << 20
+	// maxListEntries caps list_files output.
+	maxListEntries = 500
+	// maxSearchContextLines caps search_code's context_lines argument.
+	maxSearchContextLines = 10
+)
+
+// errFileNotFound is returned by workspaceFS.ReadFile for missing files.
+var errFileNotFound = errors.New("file not found")
+
+// workspaceEntry is one list_files result.
+type workspaceEntry struct {
+	Path  string `json:"path"`
+	IsDir bool   `json:"is_dir"`
+	Size  int64  `json:"size"`
+}
+
+// codeSearch holds search_code arguments.
+type codeSearch struct {
+	Pattern      string
+	Path         string
+	FileGlob     string
+	ContextLines int
+}
+
+// workspaceFS is the file backend the file tools operate on.
+type workspaceFS interface {
+	// ReadFile returns the file content and whether it was cut short by a
+	// backend size limit. Missing files return errFileNotFound.
+	ReadFile(ctx context.Context, path string) (content string, truncated bool, err error)
+	WriteFile(ctx context.Context, path, content string) error
+	List(ctx context.Context, path string, recursive bool) ([]workspaceEntry, error)
+	// Search returns grep-style "path:line:text" output with
+	// workspace-relative paths.
+	Search(ctx context.Context, q codeSearch) (string, error)
+}
+
+// workspaceFor resolves the workspace a tool call operates on.
+type workspaceFor func(call agentic.ToolCall) (workspaceFS, error)
+
+var readFileSpec = toolSpec{
+	Definition: agentic.ToolDefinition{
+		Name: "read_file",
+		Description: "Read a file from the quest workspace with line numbers. " +
+			"Use start_line/end_line to read part of a large file.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path": map[string]any{
+					"type":        "string",
+					"description": "File path relative to the workspace root",
+				},
+				"start_line": map[string]any{
+					"type":        "integer",
+					"description": "First line to return (1-based). Defaults to 1.",
+				},
+				"end_line": map[string]any{
+					"type":        "integer",
+					"description": "Last line to return (inclusive). Defaults to the end of the file.",
+				},
+			},
+			"required": []any{"path"},
+		},
+	},
+	MinTier:  domain.TierApprentice, // Read-only, confined to the workspace
+	Category: ToolCategoryInspect,
+}
+
+var listFilesSpec = toolSpec{
+	Definition: agentic.ToolDefinition{
+		Name:        "list_files",
+		Description: "List files and directories in the quest workspace.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path": map[string]any{
+					"type":        "string",
+					"description": "Directory relative to the workspace root. Defaults to the root.",
+				},
+				"recursive": map[string]any{
+					"type":        "boolean",
+					"description": "List the whole tree below path instead of one level.",
+				},
+			},
+		},
+	},
+	MinTier:  domain.TierApprentice, // Read-only, confined to the workspace
+	Category: ToolCategoryInspect,
+}
+
+var searchCodeSpec = toolSpec{
+	Definition: agentic.ToolDefinition{
+		Name: "search_code",
+		Description: "Search workspace files for a regular expression. " +
+			"Returns path:line:text for each match.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"pattern": map[string]any{
+					"type":        "string",
+					"description": "Extended regular expression to search for",
+				},
+				"path": map[string]any{
+					"type":        "string",
+					"description": "File or directory to search, relative to the workspace root. Defaults to the root.",
+				},
+				"file_glob": map[string]any{
+					"type":        "string",
+					"description": "Only search files whose name matches this glob (e.g. '*.go')",
+				},
+				"context_lines": map[string]any{
+					"type":        "integer",
+					"description": "Lines of context to show around each match (max 10)",
+				},
+			},
+			"required": []any{"pattern"},
+		},
+	},
+	MinTier:  domain.TierApprentice, // Read-only, confined to the workspace
+	Category: ToolCategoryInspect,
+}
+
+var writeFileSpec = toolSpec{
+	Definition: agentic.ToolDefinition{
+		Name: "write_file",
+		Description: "Create or overwrite a file in the quest workspace. Parent directories " +
+			"are created as needed. Prefer apply_patch for small edits to existing files.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path": map[string]any{
+					"type":        "string",
+					"description": "File path relative to the workspace root",
+				},
+				"content": map[string]any{
+					"type":        "string",
+					"description": "Complete file content",
+				},
+			},
+			"required": []any{"path", "content"},
+		},
+	},
+	MinTier:  domain.TierApprentice, // Confined to the quest workspace
+	Category: ToolCategoryWrite,
+}
+
+var applyPatchSpec = toolSpec{
+	Definition: agentic.ToolDefinition{
+		Name: "apply_patch",
+		Description: "Apply a unified diff (---/+++ headers, @@ hunks) to workspace files. " +
+			"Hunks are located by their context even if line numbers are off, tolerating " +
+			"whitespace differences. Use --- /dev/null to create a file. If any hunk fails, " +
+			"no files are changed and a report shows where each rejected hunk diverged.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"patch": map[string]any{
+					"type":        "string",
+					"description": "Unified diff text covering one or more files",
+				},
+			},
+			"required": []any{"patch"},
+		},
+	},
+	MinTier:  domain.TierApprentice, // Confined to the quest workspace
+	Category: ToolCategoryWrite,
+}
+
+// fileToolSpecs pairs each file tool spec with its handler constructor.
+var fileToolSpecs = []struct {
+	spec    toolSpec
+	handler func(workspaceFor) ToolHandler
+}{
+	{readFileSpec, readFileHandler},
+	{writeFileSpec, writeFileHandler},
+	{applyPatchSpec, applyPatchHandler},
+	{searchCodeSpec, searchCodeHandler},
+	{listFilesSpec, listFilesHandler},
+}
+
+// registerFileTools registers every file tool against the given workspace resolver.
+func (r *ToolRegistry) registerFileTools(ws workspaceFor) {
+	for _, ft := range fileToolSpecs {
+		r.Register(RegisteredTool{
+			Definition: ft.spec.Definition,
+			Handler:    ft.handler(ws),
+			Skills:     ft.spec.Skills,
+			MinTier:    ft.spec.MinTier,
+			Category:   ft.spec.Category,
+		})
+	}
+}
+
+// fileToolCall performs the checks shared by every file tool handler and
+// resolves the call's workspace.
+func fileToolCall(ctx context.Context, call agentic.ToolCall, ws workspaceFor) (workspaceFS, *agentic.ToolResult) {
+	select {
+	case <-ctx.Done():
+		return nil, &agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("operation cancelled: %v", ctx.Err())}
+	default:
+	}
+	fsys, err := ws(call)
+	if err != nil {
+		return nil, &agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+	}
+	return fsys, nil
+}
+
+// cleanWorkspacePath normalizes a workspace-relative path, rejecting absolute
+// paths and paths that climb out of the workspace. The backend still
+// enforces confinement (including symlinks); this only gives a clear error
+// before any I/O.
+func cleanWorkspacePath(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return ".", nil
+	}
+	if filepath.IsAbs(p) {
+		return "", fmt.Errorf("path %q must be relative to the workspace root", p)
+	}
+	p = filepath.ToSlash(filepath.Clean(p))
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return "", fmt.Errorf("path %q escapes the workspace", p)
+	}
+	return p, nil
+}
+
+// intArg reads an integer argument, accepting JSON numbers and numeric strings.
+func intArg(call agentic.ToolCall, name string) int {
+	switch v := call.Arguments[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		var n int
+		if _, err := fmt.Sscanf(v, "%d", &n); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+func readFileHandler(ws workspaceFor) ToolHandler {
+	return func(ctx context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
+		fsys, errResult := fileToolCall(ctx, call, ws)
+		if errResult != nil {
+			return *errResult
+		}
+		rawPath, _ := call.Arguments["path"].(string)
+		if strings.TrimSpace(rawPath) == "" {
+			return agentic.ToolResult{CallID: call.ID, Error: "path argument is required"}
+		}
+		path, err := cleanWorkspacePath(rawPath)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+		}
+
+		content, truncated, err := fsys.ReadFile(ctx, path)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("read %s: %v", path, err)}
+		}
+
+		lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+		if content == "" {
+			lines = nil
+		}
+		total := len(lines)
+
+		start := max(intArg(call, "start_line"), 1)
+		end := intArg(call, "end_line")
+		if end <= 0 || end > total {
+			end = total
+		}
+		if total > 0 && start > total {
+			return agentic.ToolResult{CallID: call.ID,
+				Error: fmt.Sprintf("start_line %d is past the end of %s (%d lines)", start, path, total)}
+		}
+		if end < start && total > 0 {
+			return agentic.ToolResult{CallID: call.ID,
+				Error: fmt.Sprintf("end_line %d is before start_line %d", end, start)}
+		}
+		more := false
+		if end-start+1 > maxReadFileLines {
+			end = start + maxReadFileLines - 1
+			more = true
+		}
+
+		var sb strings.Builder
+		if total == 0 {
+			fmt.Fprintf(&sb, "%s (empty file)\n", path)
+		} else {
+			fmt.Fprintf(&sb, "%s (lines %d-%d of %d)\n", path, start, end, total)
+			for i := start; i <= end; i++ {
+				if sb.Len() >= maxCommandOutput {
+					end, more = i-1, true
+					break
+				}
+				fmt.Fprintf(&sb, "%6d\t%s\n", i, lines[i-1])
+			}
+		}
+		if more {
+			fmt.Fprintf(&sb, "... (output limited; continue with start_line=%d)\n", end+1)
+		}
+		if truncated {
+			sb.WriteString("... (file exceeds the sandbox read limit; later lines are not available)\n")
+		}
+
+		return agentic.ToolResult{
+			CallID:  call.ID,
+			Content: sb.String(),
+			Metadata: map[string]any{
+				"path":        path,
+				"start_line":  start,
+				"end_line":    end,
+				"total_lines": total,
+				"truncated":   truncated,
+			},
+		}
+	}
+}
+
+func writeFileHandler(ws workspaceFor) ToolHandler {
+	return func(ctx context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
+		fsys, errResult := fileToolCall(ctx, call, ws)
+		if errResult != nil {
+			return *errResult
+		}
+		rawPath, _ := call.Arguments["path"].(string)
+		content, ok := call.Arguments["content"].(string)
+		if strings.TrimSpace(rawPath) == "" || !ok {
+			return agentic.ToolResult{CallID: call.ID, Error: "path and content arguments are required"}
+		}
+		path, err := cleanWorkspacePath(rawPath)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+		}
+		if path == "." {
+			return agentic.ToolResult{CallID: call.ID, Error: "path must name a file"}
+		}
+		if len(content) > maxWriteFileBytes {
+			return agentic.ToolResult{CallID: call.ID,
+				Error: fmt.Sprintf("content is %d bytes; the limit is %d", len(content), maxWriteFileBytes)}
+		}
+
+		if err := fsys.WriteFile(ctx, path, content); err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("write %s: %v", path, err)}
+		}
+
+		lines := strings.Count(content, "\n")
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			lines++
+		}
+		return agentic.ToolResult{
+			CallID:  call.ID,
+			Content: fmt.Sprintf("Wrote %s (%d bytes, %d lines)", path, len(content), lines),
+			Metadata: map[string]any{
+				"path":  path,
+				"bytes": len(content),
+				"lines": lines,
+			},
+		}
+	}
+}
+
+// patchFileReport is the apply_patch outcome for one file.
+type patchFileReport struct {
+	Path   string       `json:"path"`
+	Hunks  []hunkResult `json:"hunks"`
+	Reason string       `json:"reason,omitempty"` // Whole-file rejection (e.g. missing file)
+
+	content string
+}
+
+func (p *patchFileReport) rejected() int {
+	if p.Reason != "" {
+		return max(len(p.Hunks), 1)
+	}
+	n := 0
+	for _, h := range p.Hunks {
+		if !h.Applied {
+			n++
+		}
+	}
+	return n
+}
+
+func applyPatchHandler(ws workspaceFor) ToolHandler {
+	return func(ctx context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
+		fsys, errResult := fileToolCall(ctx, call, ws)
+		if errResult != nil {
+			return *errResult
+		}
+		text, _ := call.Arguments["patch"].(string)
+		if strings.TrimSpace(text) == "" {
+			return agentic.ToolResult{CallID: call.ID, Error: "patch argument is required"}
+		}
+		patches, err := parsePatch(text)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("invalid patch: %v", err)}
+		}
+
+		reports := make([]patchFileReport, 0, len(patches))
+		for i := range patches {
+			reports = append(reports, preparePatch(ctx, fsys, &patches[i]))
+		}
+
+		applied, rejected := 0, 0
+		for i := range reports {
+			rejected += reports[i].rejected()
+			if reports[i].Reason == "" {
+				applied += len(reports[i].Hunks) - reports[i].rejected()
+			}
+		}
+
+		// All or nothing: a partially applied patch leaves the workspace in a
+		// state the agent never described.
+		if rejected == 0 {
+			for i := range reports {
+				if err := fsys.WriteFile(ctx, reports[i].Path, reports[i].content); err != nil {
+					return agentic.ToolResult{CallID: call.ID,
+						Content: formatPatchReport(reports, applied, 0),
+						Error:   fmt.Sprintf("write %s: %v (earlier files in the patch were written)", reports[i].Path, err)}
+				}
+			}
+		}
+
+		files := make([]string, 0, len(reports))
+		for _, rep := range reports {
+			files = append(files, rep.Path)
+		}
+		result := agentic.ToolResult{
+			CallID:  call.ID,
+			Content: formatPatchReport(reports, applied, rejected),
+			Metadata: map[string]any{
+				"files":          files,
+				"hunks_applied":  applied,
+				"hunks_rejected": rejected,
+				"report":         reports,
+			},
+		}
+		if rejected > 0 {
+			result.Error = fmt.Sprintf("patch rejected: %d of %d hunks failed; no files were changed",
+				rejected, applied+rejected)
+		}
+		return result
+	}
+}
+
+// preparePatch reads the target of one file patch and applies its hunks in
+// memory.
+func preparePatch(ctx context.Context, fsys workspaceFS, fp *filePatch) patchFileReport {
+	rep := patchFileReport{Path: fp.Path()}
+	for _, h := range fp.Hunks {
+		rep.Hunks = append(rep.Hunks, hunkResult{Header: h.Header})
+	}
+
+	path, err := cleanWorkspacePath(fp.Path())
+	if err != nil {
+		rep.Reason = err.Error()
+		return rep
+	}
+	rep.Path = path
+
+	switch {
+	case fp.IsDelete():
+		rep.Reason = "deleting files is not supported; use bash to remove it"
+		return rep
+	case !fp.IsNew() && fp.OldPath != fp.NewPath:
+		rep.Reason = fmt.Sprintf("renames are not supported (%s -> %s); patch the file in place", fp.OldPath, fp.NewPath)
+		return rep
+	}
+
+	content, truncated, err := fsys.ReadFile(ctx, path)
+	switch {
+	case errors.Is(err, errFileNotFound):
+		if !fp.IsNew() {
+			rep.Reason = "file not found; use --- /dev/null to create it"
+			return rep
+		}
+		content = ""
+	case err != nil:
+		rep.Reason = fmt.Sprintf("read failed: %v", err)
+		return rep
+	case fp.IsNew():
+		rep.Reason = "patch creates the file but it already exists"
+		return rep
+	case truncated:
+		rep.Reason = "file is larger than the sandbox read limit and cannot be patched safely; use write_file"
+		return rep
+	}
+
+	rep.content, rep.Hunks = applyHunks(content, fp.Hunks)
+	return rep
+}
+
+// formatPatchReport renders apply_patch results for the agent.
+func formatPatchReport(reports []patchFileReport, applied, rejected int) string {
+	var sb strings.Builder
+	if rejected == 0 {
+		fmt.Fprintf(&sb, "Applied %d hunk(s) to %d file(s).\n", applied, len(reports))
+	} else {
+		fmt.Fprintf(&sb, "Patch rejected: %d of %d hunk(s) failed. No files were changed.\n",
+			rejected, applied+rejected)
+	}
+	for _, rep := range reports {
+		if rep.Reason != "" {
+			fmt.Fprintf(&sb, "\n%s: REJECTED — %s\n", rep.Path, rep.Reason)
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s: %d/%d hunk(s) applied\n", rep.Path, len(rep.Hunks)-rep.rejected(), len(rep.Hunks))
+		for _, h := range rep.Hunks {
+			if !h.Applied {
+				fmt.Fprintf(&sb, "  %s REJECTED — %s\n", h.Header, h.Reason)
+				continue
+			}
+			var notes []string
+			if h.Offset != 0 {
+				notes = append(notes, fmt.Sprintf("offset %+d", h.Offset))
+			}
+			switch h.Fuzz {
+			case fuzzTrailingSpace:
+				notes = append(notes, "ignoring trailing whitespace")
+			case fuzzIndentation:
+				notes = append(notes, "ignoring indentation")
+			}
+			line := fmt.Sprintf("  %s applied at line %d", h.Header, h.Line)
+			if len(notes) > 0 {
+				line += " (" + strings.Join(notes, ", ") + ")"
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func searchCodeHandler(ws workspaceFor) ToolHandler {
+	return func(ctx context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
+		fsys, errResult := fileToolCall(ctx, call, ws)
+		if errResult != nil {
+			return *errResult
+		}
+		pattern, _ := call.Arguments["pattern"].(string)
+		if pattern == "" {
+			return agentic.ToolResult{CallID: call.ID, Error: "pattern argument is required"}
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("invalid pattern: %v", err)}
+		}
+		rawPath, _ := call.Arguments["path"].(string)
+		path, err := cleanWorkspacePath(rawPath)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+		}
+		glob, _ := call.Arguments["file_glob"].(string)
+		q := codeSearch{
+			Pattern:      pattern,
+			Path:         path,
+			FileGlob:     glob,
+			ContextLines: min(max(intArg(call, "context_lines"), 0), maxSearchContextLines),
+		}
+
+		output, err := fsys.Search(ctx, q)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("search failed: %v", err)}
+		}
+
+		var matches, hidden int
+		files := make(map[string]bool)
+		var sb strings.Builder
+		for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			line = strings.TrimPrefix(line, "./")
+			if !grepLineAllowed(ctx, line) {
+				hidden++
+				continue
+			}
+			if file, rest, ok := strings.Cut(line, ":"); ok {
+				if n, _, ok := strings.Cut(rest, ":"); ok && isDigits(n) {
+					matches++
+					files[file] = true
+				}
+			}
+			sb.WriteString(line)
+			sb.WriteByte('\n')
+		}
+		if matches == 0 {
+			return agentic.ToolResult{
+				CallID:   call.ID,
+				Content:  fmt.Sprintf("No matches for %q in %s", pattern, path),
+				Metadata: map[string]any{"matches": 0, "files": 0},
+			}
+		}
+		if hidden > 0 {
+			fmt.Fprintf(&sb, "(%d lines from files blocked by the tool policy omitted)\n", hidden)
+		}
+
+		content := sb.String()
+		if len(content) > maxCommandOutput {
+			content = truncate(content, maxCommandOutput) + "\n... (results truncated; narrow path or file_glob)"
+		}
+		return agentic.ToolResult{
+			CallID:   call.ID,
+			Content:  content,
+			Metadata: map[string]any{"matches": matches, "files": len(files)},
+		}
+	}
+}
+
+// grepLineAllowed reports whether the tool policy lets a search_code output
+// line through. Lines are "path:N:text" for matches and "path-N-text" for
+// context. A file name can itself contain "-N-", so every possible path
+// prefix is checked and the line is dropped if any of them is blocked.
+func grepLineAllowed(ctx context.Context, line string) bool {
+	for i := 0; i < len(line); i++ {
+		if line[i] != ':' && line[i] != '-' {
+			continue
+		}
+		j := i + 1
+		for j < len(line) && line[j] >= '0' && line[j] <= '9' {
+			j++
+		}
+		if j > i+1 && j < len(line) && (line[j] == ':' || line[j] == '-') && !pathAllowed(ctx, line[:i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func listFilesHandler(ws workspaceFor) ToolHandler {
+	return func(ctx context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
+		fsys, errResult := fileToolCall(ctx, call, ws)
+		if errResult != nil {
+			return *errResult
+		}
+		rawPath, _ := call.Arguments["path"].(string)
+		path, err := cleanWorkspacePath(rawPath)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+		}
+		recursive, _ := call.Arguments["recursive"].(bool)
+
+		entries, err := fsys.List(ctx, path, recursive)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("list %s: %v", path, err)}
+		}
+		entries = slices.DeleteFunc(entries, func(e workspaceEntry) bool {
+			return !pathAllowed(ctx, e.Path)
+		})
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+		var sb strings.Builder
+		if len(entries) == 0 {
+			fmt.Fprintf(&sb, "%s is empty\n", path)
+		}
+		for i, e := range entries {
+			if i == maxListEntries {
+				fmt.Fprintf(&sb, "... (%d more entries; list a subdirectory)\n", len(entries)-i)
+				break
+			}
+			if e.IsDir {
+				fmt.Fprintf(&sb, "%s/\n", e.Path)
+			} else {
+				fmt.Fprintf(&sb, "%s (%d bytes)\n", e.Path, e.Size)
+			}
+		}
+		return agentic.ToolResult{
+			CallID:   call.ID,
+			Content:  sb.String(),
+			Metadata: map[string]any{"path": path, "entries": len(entries)},
+		}
+	}
+}
+
+// =============================================================================
+// LOCAL WORKSPACE - file tools against the registry's sandbox directory
+// =============================================================================
+
+// localWorkspace implements workspaceFS on a local directory.
+type localWorkspace struct {
+	root string
+}
+
+// localWorkspaceFor resolves the _sandbox_dir injected by Execute.
+func localWorkspaceFor(call agentic.ToolCall) (workspaceFS, error) {
+	dir := getSandboxDir(call)
+	if dir == "" {
+		return nil, errors.New("file tools require a configured sandbox directory")
+	}
+	return &localWorkspace{root: dir}, nil
+}
+
+// resolve mirrors the sandbox server's resolveQuestPath: relative paths only,
+// no escaping the root lexically or through symlinks.
+func (w *localWorkspace) resolve(relPath string) (string, error) {
+	if filepath.IsAbs(relPath) {
+		return "", errors.New("absolute paths are not allowed")
+	}
+	root, err := filepath.Abs(w.root)
+	if err != nil {
+		return "", err
+	}
+	absPath := filepath.Join(root, filepath.Clean(relPath))
+	if !strings.HasPrefix(absPath, root+string(filepath.Separator)) && absPath != root {
+		return "", errors.New("path escapes workspace")
+	}
+	if absPath != root {
+		realPath, err := realpath.Of(absPath)
+		if err != nil {
+			return "", err
+		}
+		realRoot, err := realpath.Of(root)
+		if err != nil {
+			return "", err
+		}
+		if !strings.HasPrefix(realPath, realRoot+string(filepath.Separator)) && realPath != realRoot {
+			return "", errors.New("symlink escapes workspace")
+		}
+	}
+	return absPath, nil
+}
+
+func (w *localWorkspace) ReadFile(_ context.Context, path string) (string, bool, error) {
+	abs, err := w.resolve(path)
+	if err != nil {
+		return "", false, err
+	}
+	data, err := os.ReadFile(abs)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, errFileNotFound
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), false, nil
+}
+
+func (w *localWorkspace) WriteFile(_ context.Context, path, content string) error {
+	abs, err := w.resolve(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(abs, []byte(content), 0o644)
+}
+
+func (w *localWorkspace) List(_ context.Context, path string, recursive bool) ([]workspaceEntry, error) {
+	abs, err := w.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	root, _ := w.resolve(".")
+
+	var entries []workspaceEntry
+	add := func(p string, d fs.DirEntry) {
+		rel, _ := filepath.Rel(root, p)
+		var size int64
+		if info, err := d.Info(); err == nil && !d.IsDir() {
+			size = info.Size()
+		}
+		entries = append(entries, workspaceEntry{Path: filepath.ToSlash(rel), IsDir: d.IsDir(), Size: size})
+	}
+
+	if !recursive {
+		dirEntries, err := os.ReadDir(abs)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dirEntries {
+			add(filepath.Join(abs, d.Name()), d)
+		}
+		return entries, nil
+	}
+
+	err = filepath.WalkDir(abs, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if p == abs {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		add(p, d)
+		return nil
+	})
+	return entries, err
+}
+
+func (w *localWorkspace) Search(ctx context.Context, q codeSearch) (string, error) {
+	re, err := regexp.Compile(q.Pattern)
+	if err != nil {
+		return "", err
+	}
+	abs, err := w.resolve(q.Path)
+	if err != nil {
+		return "", err
+	}
+	root, _ := w.resolve(".")
+
+	var sb strings.Builder
+	err = filepath.WalkDir(abs, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil || ctx.Err() != nil || sb.Len() >= maxCommandOutput {
+			return filepath.SkipAll
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Like grep -r in the sandbox, skip symlinks found while walking:
+		// os.ReadFile would follow them out of the workspace. The search
+		// path itself was checked by resolve.
+		if d.Type()&fs.ModeSymlink != 0 && p != abs {
+			return nil
+		}
+		if q.FileGlob != "" {
+			if ok, _ := filepath.Match(q.FileGlob, d.Name()); !ok {
+				return nil
+			}
+		}
+		data, err := os.ReadFile(p)
+		if err != nil || strings.IndexByte(string(data[:min(len(data), 8000)]), 0) >= 0 {
+			return nil // Unreadable or binary
+		}
+		rel, _ := filepath.Rel(root, p)
+		writeGrepMatches(&sb, filepath.ToSlash(rel), string(data), re, q.ContextLines)
+		return nil
+	})
+	return sb.String(), err
+}
+
+// writeGrepMatches formats matches in one file the way grep -n does:
+// "path:N:text" for matches and "path-N-text" for context, with "--"
+// between non-adjacent groups.
+func writeGrepMatches(sb *strings.Builder, path, content string, re *regexp.Regexp, context int) {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	last := -1 // Last line index written
+	for i, line := range lines {
+		if !re.MatchString(line) {
+			continue
+		}
+		from := max(i-context, last+1)
+		if last >= 0 && from > last+1 && context > 0 {
+			sb.WriteString("--\n")
+		}
+		for j := from; j < i; j++ {
+			fmt.Fprintf(sb, "%s-%d-%s\n", path, j+1, lines[j])
+		}
+		fmt.Fprintf(sb, "%s:%d:%s\n", path, i+1, line)
+		last = i
+		// Trailing context is written lazily so overlapping groups merge.
+		for j := i + 1; j <= min(i+context, len(lines)-1); j++ {
+			if re.MatchString(lines[j]) {
+				break
+			}
+			fmt.Fprintf(sb, "%s-%d-%s\n", path, j+1, lines[j])
+			last = j
+		}
+	}
+}
+
+// =============================================================================
+// SANDBOX WORKSPACE - file tools proxied to the sandbox container
+// =============================================================================
+
+// sandboxWorkspace implements workspaceFS against a quest's sandbox workspace.
+type sandboxWorkspace struct {
+	client  *SandboxClient
+	questID string
+}
+
+// sandboxWorkspaceFor resolves the quest workspace from call metadata.
+func sandboxWorkspaceFor(client *SandboxClient) workspaceFor {
+	return func(call agentic.ToolCall) (workspaceFS, error) {
+		questID, ok := questIDFromCall(call)
+		if !ok {
+			return nil, errors.New("quest_id missing from tool call metadata")
+		}
+		return &sandboxWorkspace{client: client, questID: questID}, nil
+	}
+}
+
+func (w *sandboxWorkspace) ReadFile(ctx context.Context, path string) (string, bool, error) {
+	resp, err := w.client.readFile(ctx, w.questID, path)
+	if err != nil {
+		var statusErr *sandboxStatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == 404 {
+			return "", false, errFileNotFound
+		}
+		return "", false, err
+	}
+	return resp.Content, resp.Truncated, nil
+}
+
+func (w *sandboxWorkspace) WriteFile(ctx context.Context, path, content string) error {
+	return w.client.WriteFile(ctx, w.questID, path, content)
+}
+
+func (w *sandboxWorkspace) List(ctx context.Context, path string, recursive bool) ([]workspaceEntry, error) {
+	if !recursive {
+		dirEntries, err := w.client.ListDir(ctx, w.questID, path)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]workspaceEntry, 0, len(dirEntries))
+		for _, e := range dirEntries {
+			p := e.Name
+			if path != "." {
+				p = path + "/" + e.Name
+			}
+			entries = append(entries, workspaceEntry{Path: p, IsDir: e.IsDir, Size: e.Size})
+		}
+		return entries, nil
+	}
+
+	// The workspace listing is recursive from the root; filter to the subtree.
+	all, err := w.client.ListWorkspaceFiles(ctx, w.questID)
+	if err != nil {
+		return nil, err
+	}
+	var entries []workspaceEntry
+	for _, f := range all {
+		p := filepath.ToSlash(f.Path)
+		if path == "." || strings.HasPrefix(p, path+"/") {
+			entries = append(entries, workspaceEntry{Path: p, Size: f.Size})
+		}
+	}
+	return entries, nil
+}
+
+func (w *sandboxWorkspace) Search(ctx context.Context, q codeSearch) (string, error) {
+	return w.client.Search(ctx, w.questID, q)
+}
@@ -0,0 +1,239 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+)
+
+// newFileToolRegistry returns a registry with builtins over a temp workspace
+// seeded with files (relative path → content).
+func newFileToolRegistry(t *testing.T, files map[string]string) (*ToolRegistry, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		abs := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(abs, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reg := NewToolRegistryWithSandbox(dir)
+	reg.RegisterBuiltins()
+	return reg, dir
+}
+
+func TestReadFileHandler(t *testing.T) {
+	t.Parallel()
+
+	reg, _ := newFileToolRegistry(t, map[string]string{"src/a.txt": "one\ntwo\nthree\nfour\n"})
+	agent := &agentprogression.Agent{Tier: domain.TierApprentice}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		want    []string
+		wantErr string
+	}{
+		{name: "whole file", args: map[string]any{"path": "src/a.txt"},
+			want: []string{"src/a.txt (lines 1-4 of 4)", "     1\tone", "     4\tfour"}},
+		{name: "line range", args: map[string]any{"path": "src/a.txt", "start_line": float64(2), "end_line": float64(3)},
+			want: []string{"(lines 2-3 of 4)", "     2\ttwo", "     3\tthree"}},
+		{name: "start past end", args: map[string]any{"path": "src/a.txt", "start_line": float64(9)}, wantErr: "past the end"},
+		{name: "missing file", args: map[string]any{"path": "nope.txt"}, wantErr: "file not found"},
+		{name: "escape", args: map[string]any{"path": "../etc/passwd"}, wantErr: "escapes the workspace"},
+		{name: "absolute", args: map[string]any{"path": "/etc/passwd"}, wantErr: "relative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := reg.Execute(context.Background(), makeToolCall("read_file", tt.args), &domain.Quest{}, agent)
+			if tt.wantErr != "" {
+				assertContains(t, result.Error, tt.wantErr)
+				return
+			}
+			if result.Error != "" {
+				t.Fatalf("unexpected error: %s", result.Error)
+			}
+			for _, w := range tt.want {
+				assertContains(t, result.Content, w)
+			}
+			if strings.Contains(result.Content, "four") && tt.name == "line range" {
+				t.Errorf("line range returned lines outside the range:\n%s", result.Content)
+			}
+		})
+	}
+}
+
+func TestWriteFileHandler(t *testing.T) {
+	t.Parallel()
+
+	reg, dir := newFileToolRegistry(t, nil)
+	agent := &agentprogression.Agent{Tier: domain.TierApprentice}
+
+	result := reg.Execute(context.Background(), makeToolCall("write_file", map[string]any{
+		"path": "docs/notes.md", "content": "# Notes\nhello\n",
+	}), &domain.Quest{}, agent)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.Metadata["lines"] != 2 {
+		t.Errorf("metadata lines = %v, want 2", result.Metadata["lines"])
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "docs", "notes.md"))
+	if err != nil || string(data) != "# Notes\nhello\n" {
+		t.Errorf("written file = %q, %v", data, err)
+	}
+
+	// Symlinks pointing outside the workspace are refused.
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	result = reg.Execute(context.Background(), makeToolCall("write_file", map[string]any{
+		"path": "link", "content": "x",
+	}), &domain.Quest{}, agent)
+	assertContains(t, result.Error, "symlink escapes")
+
+	// So are new files beneath such a symlink and dangling symlinks whose
+	// target lies outside.
+	if err := os.Symlink(filepath.Join(outside, "created.txt"), filepath.Join(dir, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"link/new.txt", "link/sub/new.txt", "dangling"} {
+		result = reg.Execute(context.Background(), makeToolCall("write_file", map[string]any{
+			"path": path, "content": "x",
+		}), &domain.Quest{}, agent)
+		assertContains(t, result.Error, "symlink escapes")
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Errorf("write escaped the workspace: %v", entries)
+	}
+}
+
+func TestApplyPatchHandler(t *testing.T) {
+	t.Parallel()
+
+	agent := &agentprogression.Agent{Tier: domain.TierApprentice}
+	orig := "package main\n\nfunc old() {}\n"
+
+	t.Run("applies across files", func(t *testing.T) {
+		t.Parallel()
+		reg, dir := newFileToolRegistry(t, map[string]string{"main.go": orig})
+		patch := "--- a/main.go\n+++ b/main.go\n@@ -3,1 +3,1 @@\n-func old() {}\n+func renamed() {}\n" +
+			"--- /dev/null\n+++ b/README.md\n@@ -0,0 +1 @@\n+# Demo\n"
+		result := reg.Execute(context.Background(), makeToolCall("apply_patch", map[string]any{"patch": patch}), &domain.Quest{}, agent)
+		if result.Error != "" {
+			t.Fatalf("unexpected error: %s\n%s", result.Error, result.Content)
+		}
+		assertContains(t, result.Content, "Applied 2 hunk(s) to 2 file(s)")
+		data, _ := os.ReadFile(filepath.Join(dir, "main.go"))
+		if string(data) != "package main\n\nfunc renamed() {}\n" {
+			t.Errorf("main.go = %q", data)
+		}
+		data, _ = os.ReadFile(filepath.Join(dir, "README.md"))
+		if string(data) != "# Demo\n" {
+			t.Errorf("README.md = %q", data)
+		}
+	})
+
+	t.Run("any rejected hunk changes nothing", func(t *testing.T) {
+		t.Parallel()
+		reg, dir := newFileToolRegistry(t, map[string]string{"main.go": orig, "b.go": "package b\n"})
+		patch := "--- a/b.go\n+++ b/b.go\n@@ -1 +1 @@\n-package b\n+package c\n" +
+			"--- a/main.go\n+++ b/main.go\n@@ -3,1 +3,1 @@\n-func missing() {}\n+func renamed() {}\n"
+		result := reg.Execute(context.Background(), makeToolCall("apply_patch", map[string]any{"patch": patch}), &domain.Quest{}, agent)
+		assertContains(t, result.Error, "1 of 2 hunks failed")
+		assertContains(t, result.Content, "main.go: 0/1 hunk(s) applied")
+		assertContains(t, result.Content, "REJECTED")
+		if result.Metadata["hunks_rejected"] != 1 {
+			t.Errorf("hunks_rejected = %v, want 1", result.Metadata["hunks_rejected"])
+		}
+		data, _ := os.ReadFile(filepath.Join(dir, "b.go"))
+		if string(data) != "package b\n" {
+			t.Errorf("b.go changed despite rejection: %q", data)
+		}
+	})
+
+	t.Run("patching a missing file", func(t *testing.T) {
+		t.Parallel()
+		reg, _ := newFileToolRegistry(t, nil)
+		patch := "--- a/gone.go\n+++ b/gone.go\n@@ -1 +1 @@\n-a\n+b\n"
+		result := reg.Execute(context.Background(), makeToolCall("apply_patch", map[string]any{"patch": patch}), &domain.Quest{}, agent)
+		assertContains(t, result.Content, "gone.go: REJECTED — file not found")
+	})
+}
+
+func TestSearchCodeHandler(t *testing.T) {
+	t.Parallel()
+
+	reg, dir := newFileToolRegistry(t, map[string]string{
+		"pkg/a.go":    "package pkg\n\nfunc Needle() {}\n",
+		"pkg/b.txt":   "Needle in text\n",
+		".git/config": "Needle\n",
+	})
+	agent := &agentprogression.Agent{Tier: domain.TierApprentice}
+
+	result := reg.Execute(context.Background(), makeToolCall("search_code", map[string]any{
+		"pattern": "Need(le)", "file_glob": "*.go",
+	}), &domain.Quest{}, agent)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.Content != "pkg/a.go:3:func Needle() {}\n" {
+		t.Errorf("content = %q", result.Content)
+	}
+	if result.Metadata["matches"] != 1 {
+		t.Errorf("matches = %v, want 1", result.Metadata["matches"])
+	}
+
+	result = reg.Execute(context.Background(), makeToolCall("search_code", map[string]any{"pattern": "("}), &domain.Quest{}, agent)
+	assertContains(t, result.Error, "invalid pattern")
+
+	// Symlinks are not followed out of the workspace.
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	if err := os.WriteFile(outside, []byte("Needle outside\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "pkg", "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	result = reg.Execute(context.Background(), makeToolCall("search_code", map[string]any{"pattern": "outside"}), &domain.Quest{}, agent)
+	if result.Error != "" || strings.Contains(result.Content, "link.txt") {
+		t.Errorf("search followed a symlink out of the workspace: %+v", result)
+	}
+}
+
+func TestListFilesHandler(t *testing.T) {
+	t.Parallel()
+
+	reg, _ := newFileToolRegistry(t, map[string]string{
+		"main.go":       "package main\n",
+		"pkg/util.go":   "package pkg\n",
+		".git/HEAD":     "ref\n",
+		"pkg/sub/x.txt": "x",
+	})
+	agent := &agentprogression.Agent{Tier: domain.TierApprentice}
+
+	result := reg.Execute(context.Background(), makeToolCall("list_files", map[string]any{}), &domain.Quest{}, agent)
+	assertContains(t, result.Content, "main.go (13 bytes)")
+	assertContains(t, result.Content, "pkg/\n")
+	if strings.Contains(result.Content, "util.go") {
+		t.Errorf("non-recursive listing descended into pkg:\n%s", result.Content)
+	}
+
+	result = reg.Execute(context.Background(), makeToolCall("list_files", map[string]any{
+		"path": "pkg", "recursive": true,
+	}), &domain.Quest{}, agent)
+	assertContains(t, result.Content, "pkg/sub/x.txt (1 bytes)")
+	if strings.Contains(result.Content, "main.go") {
+		t.Errorf("listing of pkg included the root:\n%s", result.Content)
+	}
+}
@@ -189,6 +189,26 @@ func normalizeWhitespace(s string) string {
 	return sb.String()
 }
 
+// stripTags returns the text of an HTML fragment, such as a search result
+// snippet with <b> highlights, on a single line.
+func stripTags(s string) string {
+	if !strings.ContainsAny(s, "<&") {
+		return strings.TrimSpace(normalizeWhitespace(s))
+	}
+	var sb strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt == html.TextToken {
+			sb.Write(z.Text())
+		}
+	}
+	return strings.TrimSpace(normalizeWhitespace(sb.String()))
+}
+
 // collapseNewlines reduces runs of more than 2 consecutive newlines to 2.
 func collapseNewlines(s string) string {
 	var sb strings.Builder
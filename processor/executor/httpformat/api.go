@@ -3,7 +3,7 @@
 // 2026-04-29 to deliver the markdown/Readability layer that the http_request
 // tool description has long claimed but neither codebase actually shipped.
 //
-// Public API: Render + ParseFormat. Internal converter/formatter machinery
+// Public API: Render + ParseFormat + Extractable. Internal converter/formatter machinery
 // stays unexported so future evolution can change shape without breaking
 // downstream callers.
 package httpformat
@@ -23,10 +23,15 @@ const defaultMaxChars = 20000
 //   - HTML responses (Content-Type contains text/html or application/xhtml)
 //     run through Readability + html-to-markdown, then through the
 //     format-specific renderer.
-//   - All other content types (JSON, XML, plain text, binary) bypass the
-//     markdown pipeline and return the body capped at maxChars. This
-//     preserves agent code paths that POST to JSON APIs and parse the
-//     response as JSON.
+//   - Documents with a dedicated extractor (PDF, RSS/Atom feeds,
+//     OpenAPI/Swagger specs, Jupyter notebooks, JSON) are detected by
+//     content type, URL and sniffing, and rendered as text. JSON that fits
+//     in maxChars is returned unchanged in the markdown view, preserving
+//     agent code paths that POST to JSON APIs and parse the response as
+//     JSON; larger JSON, and JSON in the summary view, is described by
+//     its shape. FormatRaw skips extraction.
+//   - All other content types (XML, plain text, binary) bypass the
+//     markdown pipeline and return the body capped at maxChars.
 //   - On conversion failure (Readability fails to extract anything, parser
 //     panics caught upstream, etc.), falls back to a raw-bytes view.
 //
@@ -38,6 +43,11 @@ func Render(body []byte, contentType, urlStr string, format Format, maxChars int
 	}
 
 	if !isHTMLContentType(contentType) {
+		if format != FormatRaw {
+			if out, ok := renderDocument(body, contentType, urlStr, format, maxChars); ok {
+				return out
+			}
+		}
 		return rawCapped(body, maxChars)
 	}
 
@@ -0,0 +1,217 @@
+package httpformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	nurl "net/url"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// docKind identifies a non-HTML document type that has a dedicated
+// extractor. Bodies of any other type are returned raw.
+type docKind int
+
+const (
+	docNone docKind = iota
+	docPDF
+	docFeed
+	docOpenAPI
+	docNotebook
+	docJSON
+)
+
+// Extractable reports whether responses with this content type or URL may
+// be documents Render extracts text from (PDFs, feeds, API specs,
+// notebooks, JSON). Callers use it to allow such bodies a larger read
+// limit than plain text, since the extracted view is far smaller than the
+// bytes on the wire.
+func Extractable(contentType, urlStr string) bool {
+	ct := mediaType(contentType)
+	switch {
+	case ct == "application/pdf",
+		strings.HasSuffix(ct, "+xml"), strings.HasSuffix(ct, "/xml"),
+		strings.HasSuffix(ct, "json"), strings.Contains(ct, "yaml"),
+		ct == "application/x-ipynb+json":
+		return true
+	}
+	switch urlExt(urlStr) {
+	case ".pdf", ".ipynb", ".json", ".yaml", ".yml", ".rss", ".atom", ".xml":
+		return true
+	}
+	return false
+}
+
+// detectDocument sniffs the body, using the content type and URL as hints.
+// Sniffing matters because servers commonly send PDFs and feeds as
+// application/octet-stream or text/plain.
+func detectDocument(body []byte, contentType, urlStr string) docKind {
+	ct := mediaType(contentType)
+	ext := urlExt(urlStr)
+	trimmed := bytes.TrimSpace(body)
+
+	if ct == "application/pdf" || bytes.HasPrefix(body, []byte("%PDF-")) {
+		return docPDF
+	}
+	if ct == "application/rss+xml" || ct == "application/atom+xml" || xmlRootIsFeed(trimmed) {
+		return docFeed
+	}
+
+	isJSON := len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed)
+	if isJSON {
+		var probe map[string]json.RawMessage
+		if json.Unmarshal(trimmed, &probe) == nil {
+			if _, ok := probe["cells"]; ok && (probe["nbformat"] != nil || ext == ".ipynb") {
+				return docNotebook
+			}
+			if isOpenAPIKeys(probe["openapi"] != nil, probe["swagger"] != nil, probe["paths"] != nil) {
+				return docOpenAPI
+			}
+		}
+		return docJSON
+	}
+
+	if strings.Contains(ct, "yaml") || ext == ".yaml" || ext == ".yml" {
+		if looksLikeOpenAPIYAML(trimmed) {
+			return docOpenAPI
+		}
+	}
+	return docNone
+}
+
+func isOpenAPIKeys(openapi, swagger, paths bool) bool {
+	return (openapi || swagger) && paths
+}
+
+// looksLikeOpenAPIYAML checks for the top-level keys of an OpenAPI or
+// Swagger document without parsing the whole file.
+func looksLikeOpenAPIYAML(body []byte) bool {
+	var openapi, swagger, paths bool
+	for _, line := range strings.Split(string(body), "\n") {
+		switch {
+		case strings.HasPrefix(line, "openapi:"):
+			openapi = true
+		case strings.HasPrefix(line, "swagger:"):
+			swagger = true
+		case strings.HasPrefix(line, "paths:"):
+			paths = true
+		}
+	}
+	return isOpenAPIKeys(openapi, swagger, paths)
+}
+
+// xmlRootIsFeed reports whether the document element is rss, feed (Atom)
+// or RDF (RSS 1.0).
+func xmlRootIsFeed(body []byte) bool {
+	if len(body) == 0 || body[0] != '<' {
+		return false
+	}
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.Strict = false
+	for i := 0; i < 64; i++ {
+		tok, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "rss", "feed", "RDF":
+				return true
+			}
+			return false
+		}
+	}
+	return false
+}
+
+// renderDocument renders body with the extractor for its kind. ok is false
+// when the body has no dedicated extractor or fails to parse, in which case
+// the caller falls back to the raw view.
+//
+// Documents have no link or heading structure of their own, so the links
+// and headings formats get the markdown view.
+func renderDocument(body []byte, contentType, urlStr string, format Format, maxChars int) (string, bool) {
+	if format != FormatSummary {
+		format = FormatMarkdown
+	}
+	switch detectDocument(body, contentType, urlStr) {
+	case docPDF:
+		return renderPDF(body, urlStr, format, maxChars)
+	case docFeed:
+		return renderFeed(body, format, maxChars)
+	case docOpenAPI:
+		return renderOpenAPI(body, format, maxChars)
+	case docNotebook:
+		return renderNotebook(body, format, maxChars)
+	case docJSON:
+		return renderJSON(body, format, maxChars)
+	}
+	return "", false
+}
+
+// mediaType returns the lowercased media type without parameters.
+func mediaType(contentType string) string {
+	ct, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(ct))
+}
+
+// urlExt returns the lowercased extension of the URL path.
+func urlExt(urlStr string) string {
+	u, err := nurl.Parse(urlStr)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(path.Ext(u.Path))
+}
+
+// htmlToText strips markup from an HTML fragment, as found in feed item
+// descriptions and notebook outputs.
+func htmlToText(s string) string {
+	if !strings.ContainsAny(s, "<&") {
+		return strings.TrimSpace(s)
+	}
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	skip := 0
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.TrimSpace(normalizeWhitespace(b.String()))
+		case html.TextToken:
+			if skip == 0 {
+				b.Write(z.Text())
+			}
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "script", "style":
+				skip++
+			case "br", "p", "div", "li", "tr":
+				b.WriteByte(' ')
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if n := string(name); (n == "script" || n == "style") && skip > 0 {
+				skip--
+			}
+		}
+	}
+}
+
+// shorten cuts s to at most n bytes on a rune boundary, marking the cut
+// with an ellipsis. Used for inline fields, where truncateChars' trailing
+// marker line would break the layout.
+func shorten(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return strings.TrimSpace(s[:cut]) + "..."
+}
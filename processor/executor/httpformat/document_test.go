@@ -0,0 +1,245 @@
+package httpformat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDetectDocument(t *testing.T) {
+	tests := []struct {
+		name, body, contentType, url string
+		want                         docKind
+	}{
+		{"pdf by type", "garbage", "application/pdf", "", docPDF},
+		{"rss sniffed", `<?xml version="1.0"?><rss version="2.0"><channel/></rss>`, "text/xml", "", docFeed},
+		{"atom by type", `<feed/>`, "application/atom+xml; charset=utf-8", "", docFeed},
+		{"other xml", `<svg xmlns="http://www.w3.org/2000/svg"/>`, "image/svg+xml", "", docNone},
+		{"notebook", `{"cells":[],"nbformat":4}`, "application/json", "", docNotebook},
+		{"openapi json", `{"openapi":"3.0.0","paths":{}}`, "application/json", "", docOpenAPI},
+		{"swagger yaml", "swagger: \"2.0\"\npaths: {}\n", "text/plain", "https://x/api.yaml", docOpenAPI},
+		{"plain yaml", "name: demo\n", "application/yaml", "", docNone},
+		{"json", `[1,2]`, "application/json", "", docJSON},
+		{"invalid json", `{"a":`, "application/json", "", docNone},
+		{"text", "hello", "text/plain", "", docNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDocument([]byte(tt.body), tt.contentType, tt.url); got != tt.want {
+				t.Errorf("detectDocument = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractable(t *testing.T) {
+	for _, tc := range []struct {
+		ct, url string
+		want    bool
+	}{
+		{"application/pdf", "", true},
+		{"application/rss+xml", "", true},
+		{"application/json; charset=utf-8", "", true},
+		{"application/octet-stream", "https://x/paper.PDF?dl=1", true},
+		{"text/html", "https://x/", false},
+		{"image/png", "https://x/a.png", false},
+	} {
+		if got := Extractable(tc.ct, tc.url); got != tc.want {
+			t.Errorf("Extractable(%q, %q) = %v", tc.ct, tc.url, got)
+		}
+	}
+}
+
+const sampleRSS = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+<channel>
+  <title>Guild News</title>
+  <link>https://guild.example/</link>
+  <atom:link href="https://guild.example/rss" rel="self"/>
+  <description>Updates from the guild</description>
+  <item>
+    <title>Raid schedule</title>
+    <link>https://guild.example/raid</link>
+    <pubDate>Mon, 05 Oct 2026 10:00:00 GMT</pubDate>
+    <description><![CDATA[<p>Raid moves to <b>Friday</b>.</p><script>x()</script>]]></description>
+  </item>
+  <item><title>Second</title><link>https://guild.example/2</link></item>
+</channel>
+</rss>`
+
+func TestRenderFeed(t *testing.T) {
+	out := Render([]byte(sampleRSS), "application/rss+xml", "", FormatMarkdown, 0)
+	for _, want := range []string{
+		"# Guild News", "Updates from the guild", "Site: https://guild.example/", "Feed: 2 items",
+		"1. Raid schedule (Mon, 05 Oct 2026 10:00:00 GMT)\n   https://guild.example/raid\n   Raid moves to Friday.",
+		"2. Second\n   https://guild.example/2",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "x()") {
+		t.Errorf("script text leaked: %s", out)
+	}
+
+	var entries strings.Builder
+	for i := 1; i <= 12; i++ {
+		fmt.Fprintf(&entries, `<entry><title>Post %d</title><link rel="alternate" href="https://blog.example/%d"/><summary>Body %d</summary><updated>2026-10-%02dT00:00:00Z</updated></entry>`, i, i, i, i)
+	}
+	atom := `<feed xmlns="http://www.w3.org/2005/Atom"><title>Blog</title>` + entries.String() + `</feed>`
+	summary := Render([]byte(atom), "text/xml", "", FormatSummary, 0)
+	if !strings.Contains(summary, "Feed: 12 items") || !strings.Contains(summary, "10. Post 10 (2026-10-10T00:00:00Z)\n   https://blog.example/10") {
+		t.Errorf("atom summary = %s", summary)
+	}
+	if strings.Contains(summary, "Post 11") || strings.Contains(summary, "Body 1") || !strings.Contains(summary, "2 more items") {
+		t.Errorf("summary should list 10 items without descriptions: %s", summary)
+	}
+}
+
+const sampleOpenAPI = `openapi: 3.0.3
+info:
+  title: Quest API
+  version: 1.2.0
+servers:
+  - url: https://api.example/v1
+paths:
+  /quests/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: string}
+    get:
+      summary: Get a quest
+      responses:
+        200:
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Quest'}
+        404: {description: Not found}
+  /quests:
+    post:
+      operationId: createQuest
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Quest'}
+      responses:
+        201: {description: Created}
+components:
+  schemas:
+    Quest:
+      type: object
+      required: [id]
+      properties:
+        id: {type: string}
+        tags: {type: array, items: {type: string}}
+`
+
+func TestRenderOpenAPI(t *testing.T) {
+	out := Render([]byte(sampleOpenAPI), "application/yaml", "", FormatMarkdown, 0)
+	for _, want := range []string{
+		"# Quest API (version 1.2.0)", "OpenAPI 3.0.3", "Server: https://api.example/v1",
+		"## Endpoints (2)",
+		"- POST /quests - createQuest\n  body: application/json Quest\n  responses: 201 Created",
+		"- GET /quests/{id} - Get a quest\n  params: id (path, string, required)\n  responses: 200 Quest; 404 Not found",
+		"## Schemas (1)\n- Quest {id* string, tags string[]}",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	swagger := `{"swagger":"2.0","info":{"title":"Legacy"},"host":"legacy.example","basePath":"/api","schemes":["http"],
+		"paths":{"/items":{"get":{"summary":"List","responses":{"200":{"schema":{"type":"array","items":{"$ref":"#/definitions/Item"}}}}}}},
+		"definitions":{"Item":{"type":"object"}}}`
+	summary := Render([]byte(swagger), "application/json", "", FormatSummary, 0)
+	for _, want := range []string{"Swagger 2.0", "Server: http://legacy.example/api", "- GET /items - List\n", "- Item"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("missing %q in:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "responses:") {
+		t.Errorf("summary should omit operation details: %s", summary)
+	}
+}
+
+const sampleNotebook = `{
+ "metadata": {"kernelspec": {"display_name": "Python 3", "language": "python"}, "language_info": {"name": "python"}},
+ "nbformat": 4,
+ "cells": [
+  {"cell_type": "markdown", "source": ["# Loot analysis\n", "Drop rates by tier."]},
+  {"cell_type": "code", "source": "import pandas as pd\ndf = pd.read_csv('loot.csv')", "outputs": [
+   {"output_type": "stream", "name": "stdout", "text": ["loaded 120 rows\n"]},
+   {"output_type": "display_data", "data": {"image/png": "iVBORw0KGgo="}}
+  ]},
+  {"cell_type": "code", "source": ["df.tier.max()"], "outputs": [
+   {"output_type": "execute_result", "data": {"text/plain": ["5"]}},
+   {"output_type": "error", "ename": "KeyError", "evalue": "'rarity'", "traceback": []}
+  ]}
+ ]
+}`
+
+func TestRenderNotebook(t *testing.T) {
+	out := Render([]byte(sampleNotebook), "application/octet-stream", "https://x/loot.ipynb", FormatMarkdown, 0)
+	for _, want := range []string{
+		"Jupyter notebook (Python 3): 3 cells, 2 code, 1 markdown",
+		"### [1] markdown\n# Loot analysis\nDrop rates by tier.",
+		"### [2] code\n```python\nimport pandas as pd\ndf = pd.read_csv('loot.csv')\n```\nOutput:\n```\nloaded 120 rows\n[image/png image]\n```",
+		"```\n5\nKeyError: 'rarity'\n```",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	summary := Render([]byte(sampleNotebook), "application/json", "", FormatSummary, 0)
+	if !strings.Contains(summary, "## Outline\n# Loot analysis") || !strings.Contains(summary, "## Excerpt") {
+		t.Errorf("summary = %s", summary)
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	small := `{"ok":true}`
+	if out := Render([]byte(small), "application/json", "", FormatMarkdown, 0); out != small {
+		t.Errorf("small JSON should pass through unchanged, got %q", out)
+	}
+
+	var items []string
+	for i := 0; i < 40; i++ {
+		extra := ""
+		if i == 7 {
+			extra = `,"note":"late key"`
+		}
+		items = append(items, fmt.Sprintf(`{"id":%d,"name":"item %d","tags":["a","b"]%s}`, i, i, extra))
+	}
+	body := `{"data":[` + strings.Join(items, ",") + `],"meta":{"next page":null,"total":40}}`
+
+	out := Render([]byte(body), "application/json", "", FormatMarkdown, 500)
+	for _, want := range []string{
+		"JSON document, " + fmt.Sprint(len(body)) + " bytes",
+		"$: object (2 keys)",
+		"$.data: array (40 items); later items also have: note",
+		"$.data[0]: object (3 keys)",
+		"$.data[0].id: number = 0",
+		`$.data[0].name: string = "item 0"`,
+		"$.data[0].tags: array (2 items)",
+		`$.meta["next page"]: null`,
+		"[body exceeds 500 characters; showing its shape",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "item 1") {
+		t.Errorf("only the first array item should be sampled: %s", out)
+	}
+
+	if out := Render([]byte(small), "application/json", "", FormatSummary, 0); !strings.Contains(out, "$.ok: boolean = true") {
+		t.Errorf("summary should always show the shape, got %s", out)
+	}
+	if out := Render([]byte(body), "application/json", "", FormatRaw, 100); !strings.HasPrefix(out, `{"data":[{"id":0`) {
+		t.Errorf("raw should return the body, got %s", out)
+	}
+}
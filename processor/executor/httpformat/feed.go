@@ -0,0 +1,145 @@
+package httpformat
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// feedItemDescChars caps each item's description in the markdown view.
+const feedItemDescChars = 500
+
+// feedXML covers RSS 2.0, RSS 1.0 (RDF) and Atom. encoding/xml matches
+// elements by local name, so one struct reads all three.
+type feedXML struct {
+	XMLName xml.Name
+	// RSS 2.0 nests everything in channel; RSS 1.0 puts items beside it.
+	Channel struct {
+		Title       string         `xml:"title"`
+		Link        []feedLinkXML  `xml:"link"`
+		Description string         `xml:"description"`
+		Items       []feedEntryXML `xml:"item"`
+	} `xml:"channel"`
+	Items []feedEntryXML `xml:"item"`
+	// Atom.
+	Title    string         `xml:"title"`
+	Subtitle string         `xml:"subtitle"`
+	Links    []feedLinkXML  `xml:"link"`
+	Entries  []feedEntryXML `xml:"entry"`
+}
+
+type feedLinkXML struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Text string `xml:",chardata"`
+}
+
+type feedEntryXML struct {
+	Title       string        `xml:"title"`
+	Links       []feedLinkXML `xml:"link"`
+	Description string        `xml:"description"`
+	Summary     string        `xml:"summary"`
+	Content     string        `xml:"content"`
+	PubDate     string        `xml:"pubDate"`
+	Date        string        `xml:"date"`
+	Published   string        `xml:"published"`
+	Updated     string        `xml:"updated"`
+}
+
+// feedItem is one normalized RSS item or Atom entry.
+type feedItem struct {
+	title, link, date, desc string
+}
+
+// feedLink picks the alternate link: an RSS link's text, or the Atom link
+// with no rel or rel="alternate".
+func feedLink(links []feedLinkXML) string {
+	for _, l := range links {
+		if text := strings.TrimSpace(l.Text); text != "" {
+			return text
+		}
+		if l.Href != "" && (l.Rel == "" || l.Rel == "alternate") {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// renderFeed renders an RSS or Atom feed as a list of items. Markdown
+// includes each item's description as plain text; summary lists the first
+// items' titles, links and dates.
+func renderFeed(body []byte, format Format, maxChars int) (string, bool) {
+	var f feedXML
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.Strict = false
+	dec.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+	if err := dec.Decode(&f); err != nil {
+		return "", false
+	}
+
+	title, subtitle, site := f.Title, f.Subtitle, feedLink(f.Links)
+	entries := f.Entries
+	if f.XMLName.Local != "feed" {
+		title, subtitle, site = f.Channel.Title, f.Channel.Description, feedLink(f.Channel.Link)
+		entries = append(f.Channel.Items, f.Items...)
+	}
+
+	items := make([]feedItem, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, feedItem{
+			title: normalizeWhitespace(htmlToText(e.Title)),
+			link:  feedLink(e.Links),
+			date:  firstNonEmpty(e.PubDate, e.Published, e.Updated, e.Date),
+			desc:  htmlToText(firstNonEmpty(e.Description, e.Summary, e.Content)),
+		})
+	}
+
+	var b strings.Builder
+	if title = strings.TrimSpace(title); title != "" {
+		fmt.Fprintf(&b, "# %s\n\n", title)
+	}
+	if subtitle = htmlToText(subtitle); subtitle != "" {
+		fmt.Fprintf(&b, "%s\n\n", subtitle)
+	}
+	if site != "" {
+		fmt.Fprintf(&b, "Site: %s\n", site)
+	}
+	fmt.Fprintf(&b, "Feed: %d items\n", len(items))
+
+	shown := items
+	if format == FormatSummary && len(shown) > summaryLinkCount {
+		shown = shown[:summaryLinkCount]
+	}
+	for i, item := range shown {
+		itemTitle := item.title
+		if itemTitle == "" {
+			itemTitle = "(untitled)"
+		}
+		fmt.Fprintf(&b, "\n%d. %s", i+1, itemTitle)
+		if item.date != "" {
+			fmt.Fprintf(&b, " (%s)", item.date)
+		}
+		b.WriteByte('\n')
+		if item.link != "" {
+			fmt.Fprintf(&b, "   %s\n", item.link)
+		}
+		if format != FormatSummary && item.desc != "" {
+			fmt.Fprintf(&b, "   %s\n", shorten(item.desc, feedItemDescChars))
+		}
+	}
+	if len(shown) < len(items) {
+		fmt.Fprintf(&b, "\n... %d more items; use format=markdown for all\n", len(items)-len(shown))
+	}
+	return truncateChars(strings.TrimRight(b.String(), "\n"), maxChars), true
+}
@@ -0,0 +1,209 @@
+package httpformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Limits on the JSON shape view.
+const (
+	jsonShapeMaxDepth  = 8
+	jsonShapeMaxLines  = 200
+	jsonShapeMaxKeys   = 50 // keys listed per object
+	jsonShapeSampleLen = 60 // characters of a sampled string value
+)
+
+// renderJSON renders a JSON body. A body that fits in maxChars is returned
+// unchanged in the markdown view, so agents calling JSON APIs can still
+// parse the result. Larger bodies, and every body in the summary view, are
+// described by their shape instead: one JSONPath line per field with its
+// type and a sample value. Arrays are sampled from their first element,
+// noting keys that only later elements have.
+func renderJSON(body []byte, format Format, maxChars int) (string, bool) {
+	if format != FormatSummary && len(body) <= maxChars {
+		return string(body), true
+	}
+
+	s := &jsonShaper{dec: json.NewDecoder(bytes.NewReader(body))}
+	s.dec.UseNumber()
+	if _, err := s.value("$", 0, true); err != nil {
+		return "", false
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "JSON document, %d bytes\n\n## Shape\n", len(body))
+	b.WriteString(strings.Join(s.lines, "\n"))
+	b.WriteByte('\n')
+	if s.truncated {
+		fmt.Fprintf(&b, "... (shape truncated at %d lines)\n", jsonShapeMaxLines)
+	}
+	if format != FormatSummary {
+		fmt.Fprintf(&b, "\n[body exceeds %d characters; showing its shape. Use format=raw for the body truncated at max_chars]\n", maxChars)
+	}
+	return truncateChars(strings.TrimRight(b.String(), "\n"), maxChars), true
+}
+
+// jsonShaper walks a JSON token stream, recording shape lines.
+type jsonShaper struct {
+	dec       *json.Decoder
+	lines     []string
+	truncated bool
+}
+
+// emitLine appends a line, reporting false once the line budget is spent.
+func (s *jsonShaper) emitLine(line string) bool {
+	if len(s.lines) >= jsonShapeMaxLines {
+		s.truncated = true
+		return false
+	}
+	s.lines = append(s.lines, line)
+	return true
+}
+
+// reserve adds a placeholder line to fill in once a container's size is
+// known, returning its index or -1 when the budget is spent.
+func (s *jsonShaper) reserve() int {
+	if !s.emitLine("") {
+		return -1
+	}
+	return len(s.lines) - 1
+}
+
+// jsonValueInfo summarizes a value for its parent: its kind and, for
+// objects, its keys.
+type jsonValueInfo struct {
+	kind string
+	keys []string
+}
+
+// value reads one value at path. When emit is false the value is consumed
+// without recording lines, apart from the keys its parent needs.
+func (s *jsonShaper) value(path string, depth int, emit bool) (jsonValueInfo, error) {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return jsonValueInfo{}, err
+	}
+	emit = emit && !s.truncated
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			return s.object(path, depth, emit)
+		}
+		return s.array(path, depth, emit)
+	case string:
+		if emit {
+			s.emitLine(fmt.Sprintf("%s: string = %s", path, strconv.Quote(shorten(t, jsonShapeSampleLen))))
+		}
+		return jsonValueInfo{kind: "string"}, nil
+	case json.Number:
+		if emit {
+			s.emitLine(fmt.Sprintf("%s: number = %s", path, t))
+		}
+		return jsonValueInfo{kind: "number"}, nil
+	case bool:
+		if emit {
+			s.emitLine(fmt.Sprintf("%s: boolean = %t", path, t))
+		}
+		return jsonValueInfo{kind: "boolean"}, nil
+	default:
+		if emit {
+			s.emitLine(path + ": null")
+		}
+		return jsonValueInfo{kind: "null"}, nil
+	}
+}
+
+func (s *jsonShaper) object(path string, depth int, emit bool) (jsonValueInfo, error) {
+	at := -1
+	if emit {
+		at = s.reserve()
+	}
+	info := jsonValueInfo{kind: "object"}
+	for s.dec.More() {
+		tok, err := s.dec.Token()
+		if err != nil {
+			return info, err
+		}
+		key, _ := tok.(string)
+		info.keys = append(info.keys, key)
+		childEmit := emit && depth < jsonShapeMaxDepth && len(info.keys) <= jsonShapeMaxKeys
+		if _, err := s.value(path+jsonPathKey(key), depth+1, childEmit); err != nil {
+			return info, err
+		}
+	}
+	if _, err := s.dec.Token(); err != nil { // }
+		return info, err
+	}
+	if at >= 0 {
+		line := fmt.Sprintf("%s: object (%d keys)", path, len(info.keys))
+		if len(info.keys) > jsonShapeMaxKeys {
+			line += fmt.Sprintf(", first %d shown", jsonShapeMaxKeys)
+		} else if depth >= jsonShapeMaxDepth && len(info.keys) > 0 {
+			line += " {" + strings.Join(info.keys, ", ") + "}"
+		}
+		s.lines[at] = line
+	}
+	return info, nil
+}
+
+func (s *jsonShaper) array(path string, depth int, emit bool) (jsonValueInfo, error) {
+	at := -1
+	if emit {
+		at = s.reserve()
+	}
+	n := 0
+	kinds := make(map[string]bool)
+	firstKeys := make(map[string]bool)
+	var extraKeys []string
+	for s.dec.More() {
+		childEmit := emit && n == 0 && depth < jsonShapeMaxDepth
+		item, err := s.value(path+"[0]", depth+1, childEmit)
+		if err != nil {
+			return jsonValueInfo{}, err
+		}
+		kinds[item.kind] = true
+		for _, k := range item.keys {
+			if n == 0 {
+				firstKeys[k] = true
+			} else if !firstKeys[k] {
+				firstKeys[k] = true
+				extraKeys = append(extraKeys, k)
+			}
+		}
+		n++
+	}
+	if _, err := s.dec.Token(); err != nil { // ]
+		return jsonValueInfo{}, err
+	}
+	if at >= 0 {
+		line := fmt.Sprintf("%s: array (%d items)", path, n)
+		if len(kinds) > 1 {
+			names := make([]string, 0, len(kinds))
+			for k := range kinds {
+				names = append(names, k)
+			}
+			sort.Strings(names)
+			line += ", mixed " + strings.Join(names, "/")
+		}
+		if len(extraKeys) > 0 {
+			line += "; later items also have: " + shorten(strings.Join(extraKeys, ", "), 200)
+		}
+		s.lines[at] = line
+	}
+	return jsonValueInfo{kind: "array"}, nil
+}
+
+var jsonIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// jsonPathKey formats an object key as a JSONPath step.
+func jsonPathKey(key string) string {
+	if jsonIdentifier.MatchString(key) {
+		return "." + key
+	}
+	return "[" + strconv.Quote(key) + "]"
+}
@@ -0,0 +1,173 @@
+package httpformat
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// notebookOutputChars caps each cell output, so one cell that prints a
+// large dataframe cannot crowd out the rest of the notebook.
+const notebookOutputChars = 1500
+
+// notebookJSON is the subset of the Jupyter nbformat 4 schema that holds
+// content. Sources and texts are strings or lists of lines.
+type notebookJSON struct {
+	Metadata struct {
+		KernelSpec struct {
+			DisplayName string `json:"display_name"`
+			Language    string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+	Cells []struct {
+		CellType string        `json:"cell_type"`
+		Source   notebookText  `json:"source"`
+		Outputs  []notebookOut `json:"outputs"`
+	} `json:"cells"`
+}
+
+type notebookOut struct {
+	OutputType string                  `json:"output_type"`
+	Name       string                  `json:"name"`
+	Text       notebookText            `json:"text"`
+	Data       map[string]notebookText `json:"data"`
+	EName      string                  `json:"ename"`
+	EValue     string                  `json:"evalue"`
+}
+
+// notebookText accepts nbformat's multiline strings: a string or a list of
+// strings to concatenate.
+type notebookText string
+
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*t = notebookText(s)
+		return nil
+	}
+	var lines []string
+	if json.Unmarshal(data, &lines) == nil {
+		*t = notebookText(strings.Join(lines, ""))
+		return nil
+	}
+	// Other JSON values (application/json outputs) are kept verbatim.
+	*t = notebookText(data)
+	return nil
+}
+
+// renderNotebook flattens a Jupyter notebook into its cells: markdown cells
+// as-is, code cells fenced with the kernel language followed by their text
+// outputs. Images are replaced by a placeholder. Summary gives the cell
+// counts, the markdown headings and an excerpt.
+func renderNotebook(body []byte, format Format, maxChars int) (string, bool) {
+	var nb notebookJSON
+	if err := json.Unmarshal(body, &nb); err != nil {
+		return "", false
+	}
+	lang := firstNonEmpty(nb.Metadata.LanguageInfo.Name, nb.Metadata.KernelSpec.Language)
+
+	var code, markdown int
+	var headings []string
+	var full strings.Builder
+	for i, cell := range nb.Cells {
+		src := strings.TrimRight(string(cell.Source), "\n")
+		switch cell.CellType {
+		case "markdown":
+			markdown++
+			for _, line := range strings.Split(src, "\n") {
+				if strings.HasPrefix(line, "#") {
+					headings = append(headings, strings.TrimSpace(line))
+				}
+			}
+			fmt.Fprintf(&full, "### [%d] markdown\n%s\n\n", i+1, src)
+		case "code":
+			code++
+			fmt.Fprintf(&full, "### [%d] code\n```%s\n%s\n```\n", i+1, lang, src)
+			if out := notebookOutputs(cell.Outputs); out != "" {
+				fmt.Fprintf(&full, "Output:\n```\n%s\n```\n", out)
+			}
+			full.WriteByte('\n')
+		default:
+			fmt.Fprintf(&full, "### [%d] %s\n%s\n\n", i+1, cell.CellType, src)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("Jupyter notebook")
+	if kernel := nb.Metadata.KernelSpec.DisplayName; kernel != "" {
+		fmt.Fprintf(&b, " (%s)", kernel)
+	}
+	fmt.Fprintf(&b, ": %d cells, %d code, %d markdown\n\n", len(nb.Cells), code, markdown)
+
+	if format == FormatSummary {
+		if len(headings) > 0 {
+			b.WriteString("## Outline\n")
+			for _, h := range headings {
+				b.WriteString(h + "\n")
+			}
+			b.WriteByte('\n')
+		}
+		text := strings.TrimSpace(full.String())
+		fmt.Fprintf(&b, "## Excerpt\n%s\n", truncateChars(text, summaryExcerptChars))
+		if len(text) > summaryExcerptChars {
+			b.WriteString("\n[truncated; use format=markdown for all cells]\n")
+		}
+	} else {
+		b.WriteString(full.String())
+	}
+	return truncateChars(strings.TrimRight(b.String(), "\n"), maxChars), true
+}
+
+// notebookOutputs renders a code cell's outputs as text.
+func notebookOutputs(outputs []notebookOut) string {
+	var parts []string
+	for _, out := range outputs {
+		var s string
+		switch out.OutputType {
+		case "stream":
+			s = string(out.Text)
+		case "error":
+			s = fmt.Sprintf("%s: %s", out.EName, out.EValue)
+		case "execute_result", "display_data":
+			s = notebookData(out.Data)
+		}
+		if s = strings.TrimRight(s, "\n"); s != "" {
+			parts = append(parts, shorten(s, notebookOutputChars))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// notebookData picks the most readable representation of a rich output.
+func notebookData(data map[string]notebookText) string {
+	if t, ok := data["text/plain"]; ok {
+		return string(t)
+	}
+	if t, ok := data["text/markdown"]; ok {
+		return string(t)
+	}
+	if t, ok := data["text/html"]; ok {
+		return htmlToText(string(t))
+	}
+	if t, ok := data["application/json"]; ok {
+		return string(t)
+	}
+	types := make([]string, 0, len(data))
+	for mime := range data {
+		types = append(types, mime)
+	}
+	sort.Strings(types)
+	for _, mime := range types {
+		if strings.HasPrefix(mime, "image/") {
+			return fmt.Sprintf("[%s image]", mime)
+		}
+	}
+	if len(types) > 0 {
+		return fmt.Sprintf("[%s output]", types[0])
+	}
+	return ""
+}
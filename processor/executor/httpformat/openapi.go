@@ -0,0 +1,334 @@
+package httpformat
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIMethods are the operation keys of a path item, in display order.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// renderOpenAPI renders an OpenAPI 3 or Swagger 2 document as an endpoint
+// and schema listing. Markdown includes each operation's parameters,
+// request body and responses; summary lists endpoints and schema names.
+func renderOpenAPI(body []byte, format Format, maxChars int) (string, bool) {
+	spec, ok := parseSpec(body)
+	if !ok {
+		return "", false
+	}
+
+	var b strings.Builder
+	info := asMap(spec["info"])
+	title := asString(info["title"])
+	if title == "" {
+		title = "API"
+	}
+	fmt.Fprintf(&b, "# %s", title)
+	if v := asString(info["version"]); v != "" {
+		fmt.Fprintf(&b, " (version %s)", v)
+	}
+	b.WriteString("\n\n")
+	if v := asString(spec["openapi"]); v != "" {
+		fmt.Fprintf(&b, "OpenAPI %s\n", v)
+	} else if v := asString(spec["swagger"]); v != "" {
+		fmt.Fprintf(&b, "Swagger %s\n", v)
+	}
+	if desc := asString(info["description"]); desc != "" && format != FormatSummary {
+		fmt.Fprintf(&b, "%s\n", shorten(strings.TrimSpace(desc), summaryExcerptChars))
+	}
+	for _, server := range specServers(spec) {
+		fmt.Fprintf(&b, "Server: %s\n", server)
+	}
+
+	paths := asMap(spec["paths"])
+	pathNames := sortedKeys(paths)
+	count := 0
+	for _, p := range pathNames {
+		for _, m := range openAPIMethods {
+			if _, ok := asMap(paths[p])[m]; ok {
+				count++
+			}
+		}
+	}
+	fmt.Fprintf(&b, "\n## Endpoints (%d)\n", count)
+	for _, p := range pathNames {
+		item := asMap(paths[p])
+		for _, m := range openAPIMethods {
+			op, ok := item[m]
+			if !ok {
+				continue
+			}
+			writeOperation(&b, strings.ToUpper(m), p, asMap(op), asList(item["parameters"]), format)
+		}
+	}
+
+	schemas := asMap(asMap(spec["components"])["schemas"])
+	if len(schemas) == 0 {
+		schemas = asMap(spec["definitions"])
+	}
+	if len(schemas) > 0 {
+		fmt.Fprintf(&b, "\n## Schemas (%d)\n", len(schemas))
+		for _, name := range sortedKeys(schemas) {
+			if format == FormatSummary {
+				fmt.Fprintf(&b, "- %s\n", name)
+				continue
+			}
+			fmt.Fprintf(&b, "- %s%s\n", name, schemaFields(asMap(schemas[name])))
+		}
+	}
+	return truncateChars(strings.TrimRight(b.String(), "\n"), maxChars), true
+}
+
+// writeOperation writes one endpoint line and, outside summary view, its
+// parameters, request body and responses.
+func writeOperation(b *strings.Builder, method, path string, op map[string]any, pathParams []any, format Format) {
+	fmt.Fprintf(b, "- %s %s", method, path)
+	if s := firstNonEmpty(asString(op["summary"]), asString(op["operationId"])); s != "" {
+		fmt.Fprintf(b, " - %s", shorten(normalizeWhitespace(s), 120))
+	}
+	if deprecated, _ := op["deprecated"].(bool); deprecated {
+		b.WriteString(" (deprecated)")
+	}
+	b.WriteByte('\n')
+	if format == FormatSummary {
+		return
+	}
+
+	var params []string
+	for _, pv := range append(pathParams, asList(op["parameters"])...) {
+		p := asMap(pv)
+		name := asString(p["name"])
+		if name == "" {
+			if ref := asString(p["$ref"]); ref != "" {
+				params = append(params, refName(ref))
+			}
+			continue
+		}
+		if asString(p["in"]) == "body" {
+			// Swagger 2 request body.
+			fmt.Fprintf(b, "  body: %s\n", schemaType(asMap(p["schema"])))
+			continue
+		}
+		s := fmt.Sprintf("%s (%s", name, asString(p["in"]))
+		if t := schemaType(asMap(p["schema"])); t != "" {
+			s += ", " + t
+		} else if t := asString(p["type"]); t != "" {
+			s += ", " + t
+		}
+		if required, _ := p["required"].(bool); required {
+			s += ", required"
+		}
+		params = append(params, s+")")
+	}
+	if len(params) > 0 {
+		fmt.Fprintf(b, "  params: %s\n", strings.Join(params, "; "))
+	}
+
+	if rb := asMap(op["requestBody"]); rb != nil {
+		content := asMap(rb["content"])
+		var parts []string
+		for _, ct := range sortedKeys(content) {
+			parts = append(parts, fmt.Sprintf("%s %s", ct, schemaType(asMap(asMap(content[ct])["schema"]))))
+		}
+		if ref := asString(rb["$ref"]); ref != "" {
+			parts = append(parts, refName(ref))
+		}
+		if len(parts) > 0 {
+			fmt.Fprintf(b, "  body: %s\n", strings.Join(parts, "; "))
+		}
+	}
+
+	responses := asMap(op["responses"])
+	var codes []string
+	for _, code := range sortedKeys(responses) {
+		r := asMap(responses[code])
+		s := code
+		if t := responseType(r); t != "" {
+			s += " " + t
+		} else if d := asString(r["description"]); d != "" {
+			s += " " + shorten(normalizeWhitespace(d), 60)
+		}
+		codes = append(codes, s)
+	}
+	if len(codes) > 0 {
+		fmt.Fprintf(b, "  responses: %s\n", strings.Join(codes, "; "))
+	}
+}
+
+// responseType names the schema of a response: the first content type's
+// schema (OpenAPI 3) or the schema field (Swagger 2).
+func responseType(r map[string]any) string {
+	if s := asMap(r["schema"]); s != nil {
+		return schemaType(s)
+	}
+	content := asMap(r["content"])
+	for _, ct := range sortedKeys(content) {
+		if t := schemaType(asMap(asMap(content[ct])["schema"])); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// schemaType describes a schema compactly: a referenced schema's name,
+// array element types, or the primitive type.
+func schemaType(s map[string]any) string {
+	if s == nil {
+		return ""
+	}
+	if ref := asString(s["$ref"]); ref != "" {
+		return refName(ref)
+	}
+	if t := asString(s["type"]); t == "array" {
+		if inner := schemaType(asMap(s["items"])); inner != "" {
+			return inner + "[]"
+		}
+		return "array"
+	} else if t != "" {
+		return t
+	}
+	for _, key := range []string{"oneOf", "anyOf", "allOf"} {
+		if list := asList(s[key]); len(list) > 0 {
+			var alts []string
+			for _, alt := range list {
+				if t := schemaType(asMap(alt)); t != "" {
+					alts = append(alts, t)
+				}
+			}
+			sep := " | "
+			if key == "allOf" {
+				sep = " & "
+			}
+			return strings.Join(alts, sep)
+		}
+	}
+	if s["properties"] != nil {
+		return "object"
+	}
+	return ""
+}
+
+// schemaFields lists an object schema's properties with their types,
+// marking required ones with "*".
+func schemaFields(s map[string]any) string {
+	props := asMap(s["properties"])
+	if len(props) == 0 {
+		if t := schemaType(s); t != "" && t != "object" {
+			return ": " + t
+		}
+		return ""
+	}
+	required := make(map[string]bool)
+	for _, r := range asList(s["required"]) {
+		required[asString(r)] = true
+	}
+	fields := make([]string, 0, len(props))
+	for _, name := range sortedKeys(props) {
+		f := name
+		if required[name] {
+			f += "*"
+		}
+		if t := schemaType(asMap(props[name])); t != "" {
+			f += " " + t
+		}
+		fields = append(fields, f)
+	}
+	return " {" + strings.Join(fields, ", ") + "}"
+}
+
+// specServers returns the OpenAPI 3 server URLs, or the Swagger 2 base URL
+// built from host, basePath and schemes.
+func specServers(spec map[string]any) []string {
+	var out []string
+	for _, s := range asList(spec["servers"]) {
+		if u := asString(asMap(s)["url"]); u != "" {
+			out = append(out, u)
+		}
+	}
+	if host := asString(spec["host"]); host != "" {
+		scheme := "https"
+		if schemes := asList(spec["schemes"]); len(schemes) > 0 {
+			scheme = asString(schemes[0])
+		}
+		out = append(out, scheme+"://"+host+asString(spec["basePath"]))
+	}
+	return out
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// parseSpec decodes a JSON or YAML document into generic maps.
+func parseSpec(body []byte) (map[string]any, bool) {
+	var spec map[string]any
+	if json.Unmarshal(body, &spec) == nil {
+		return spec, true
+	}
+	var raw any
+	if err := yaml.Unmarshal(body, &raw); err != nil {
+		return nil, false
+	}
+	spec, ok := normalizeYAML(raw).(map[string]any)
+	return spec, ok
+}
+
+// normalizeYAML converts the map[any]any nodes yaml.v3 produces for
+// non-string keys (such as response codes written as integers) into
+// map[string]any, matching what encoding/json produces.
+func normalizeYAML(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, inner := range v {
+			v[k] = normalizeYAML(inner)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, inner := range v {
+			m[fmt.Sprint(k)] = normalizeYAML(inner)
+		}
+		return m
+	case []any:
+		for i, inner := range v {
+			v[i] = normalizeYAML(inner)
+		}
+		return v
+	}
+	return v
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func asList(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+func asString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	case float64, int, bool:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,936 @@
+package httpformat
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// PDF text extraction. This is deliberately a small reader rather than a
+// full PDF implementation: it finds objects by scanning for "N G obj"
+// headers (so truncated files and broken xref tables still yield text),
+// unpacks object streams, follows the page tree, and interprets the text
+// operators of each page's content streams. Fonts with a ToUnicode CMap are
+// decoded through it; simple fonts without one are read as Latin-1. Text
+// drawn inside form XObjects, and images, are not extracted.
+
+// Limits that keep a hostile PDF from costing more than a normal one.
+const (
+	pdfMaxPages       = 2000
+	pdfMaxRefDepth    = 16
+	pdfMaxTreeDepth   = 32
+	pdfMaxStreamBytes = 32 << 20 // decoded size cap per stream
+	pdfMaxCMapRange   = 1 << 16
+)
+
+type (
+	pdfName    string
+	pdfKeyword string
+	pdfString  []byte
+	pdfDict    map[string]any
+	pdfRef     struct{ num, gen int }
+	pdfStream  struct {
+		dict pdfDict
+		raw  []byte
+	}
+)
+
+var errPDFEnd = errors.New("end of PDF data")
+
+// pdfLexer parses PDF objects and content-stream tokens from data.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelim(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	l.pos = min(l.pos, len(l.data))
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFSpace(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// word reads a run of regular (non-space, non-delimiter) characters.
+func (l *pdfLexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelim(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// value parses the next object or, in content streams, operator keyword.
+func (l *pdfLexer) value() (any, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, errPDFEnd
+	}
+	switch c := l.data[l.pos]; {
+	case c == '/':
+		l.pos++
+		return pdfName(decodePDFName(l.word())), nil
+	case c == '(':
+		return l.literalString(), nil
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return l.dict()
+	case c == '<':
+		return l.hexString(), nil
+	case c == '[':
+		l.pos++
+		var arr []any
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return arr, nil
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return arr, nil
+			}
+			v, err := l.value()
+			if err != nil {
+				return arr, err
+			}
+			arr = append(arr, v)
+		}
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		return pdfKeyword(string(c)), nil
+	default:
+		w := l.word()
+		if w == "" {
+			l.pos++
+			return pdfKeyword(string(c)), nil
+		}
+		return l.wordValue(w), nil
+	}
+}
+
+// wordValue interprets a bare word: a number, an "n g R" reference, a
+// boolean, null, or an operator keyword.
+func (l *pdfLexer) wordValue(w string) any {
+	switch w {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	n, err := strconv.ParseFloat(w, 64)
+	if err != nil {
+		return pdfKeyword(w)
+	}
+	// An integer may start an "n g R" reference.
+	if num, err := strconv.Atoi(w); err == nil {
+		save := l.pos
+		l.skipSpace()
+		if gen, err := strconv.Atoi(l.word()); err == nil {
+			l.skipSpace()
+			if l.pos < len(l.data) && l.data[l.pos] == 'R' &&
+				(l.pos+1 == len(l.data) || isPDFSpace(l.data[l.pos+1]) || isPDFDelim(l.data[l.pos+1])) {
+				l.pos++
+				return pdfRef{num: num, gen: gen}
+			}
+		}
+		l.pos = save
+	}
+	return n
+}
+
+func (l *pdfLexer) dict() (pdfDict, error) {
+	d := make(pdfDict)
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return d, nil
+		}
+		if l.data[l.pos] == '>' {
+			l.pos = min(l.pos+2, len(l.data))
+			return d, nil
+		}
+		k, err := l.value()
+		if err != nil {
+			return d, err
+		}
+		key, ok := k.(pdfName)
+		if !ok {
+			continue
+		}
+		v, err := l.value()
+		if err != nil {
+			return d, err
+		}
+		d[string(key)] = v
+	}
+}
+
+func (l *pdfLexer) literalString() pdfString {
+	l.pos++ // (
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (l *pdfLexer) hexString() pdfString {
+	l.pos++ // <
+	end := bytes.IndexByte(l.data[l.pos:], '>')
+	if end < 0 {
+		end = len(l.data) - l.pos
+	}
+	digits := make([]byte, 0, end)
+	for _, c := range l.data[l.pos : l.pos+end] {
+		if !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	l.pos = min(l.pos+end+1, len(l.data))
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out, _ := hex.DecodeString(string(digits))
+	return out
+}
+
+// skipInlineImage moves past inline image data after an ID operator.
+func (l *pdfLexer) skipInlineImage() {
+	for i := l.pos; i+2 < len(l.data); i++ {
+		if isPDFSpace(l.data[i]) && l.data[i+1] == 'E' && l.data[i+2] == 'I' &&
+			(i+3 == len(l.data) || isPDFSpace(l.data[i+3])) {
+			l.pos = i + 3
+			return
+		}
+	}
+	l.pos = len(l.data)
+}
+
+func decodePDFName(s string) string {
+	if !strings.Contains(s, "#") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && i+2 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// pdfFile holds the objects found in a PDF.
+type pdfFile struct {
+	objects map[int]any
+	fonts   map[pdfRef]*pdfFont
+}
+
+var pdfObjHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// parsePDF scans data for indirect objects, including those packed in
+// object streams. Later definitions of an object replace earlier ones, as
+// incremental updates intend.
+func parsePDF(data []byte) *pdfFile {
+	f := &pdfFile{objects: make(map[int]any), fonts: make(map[pdfRef]*pdfFont)}
+	next := 0
+	for _, m := range pdfObjHeader.FindAllSubmatchIndex(data, -1) {
+		if m[0] < next {
+			continue // inside the previous object's stream
+		}
+		num, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+		l := &pdfLexer{data: data, pos: m[1]}
+		v, _ := l.value()
+		l.pos = min(l.pos, len(data))
+		if d, ok := v.(pdfDict); ok {
+			l.skipSpace()
+			if bytes.HasPrefix(data[l.pos:], []byte("stream")) {
+				v = l.stream(d)
+			}
+		}
+		f.objects[num] = v
+		next = l.pos
+	}
+
+	for _, v := range f.objects {
+		s, ok := v.(*pdfStream)
+		if !ok || s.dict["Type"] != pdfName("ObjStm") {
+			continue
+		}
+		f.unpackObjectStream(s)
+	}
+	return f
+}
+
+// stream reads stream data following a stream dictionary.
+func (l *pdfLexer) stream(d pdfDict) *pdfStream {
+	l.pos = min(l.pos+len("stream"), len(l.data))
+	if l.pos < len(l.data) && l.data[l.pos] == '\r' {
+		l.pos++
+	}
+	if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+		l.pos++
+	}
+	start := l.pos
+	if n, ok := d["Length"].(float64); ok && n >= 0 && start+int(n) <= len(l.data) {
+		end := start + int(n)
+		rest := bytes.TrimLeft(l.data[end:min(end+32, len(l.data))], " \r\n")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			l.pos = end
+			return &pdfStream{dict: d, raw: l.data[start:end]}
+		}
+	}
+	// Indirect or wrong Length: fall back to the endstream keyword.
+	end := bytes.Index(l.data[start:], []byte("endstream"))
+	if end < 0 {
+		l.pos = len(l.data)
+		return &pdfStream{dict: d, raw: l.data[start:]}
+	}
+	l.pos = start + end
+	return &pdfStream{dict: d, raw: bytes.TrimRight(l.data[start:start+end], "\r\n")}
+}
+
+func (f *pdfFile) unpackObjectStream(s *pdfStream) {
+	data, err := f.decodeStream(s)
+	if err != nil {
+		return
+	}
+	n, _ := s.dict["N"].(float64)
+	first, _ := s.dict["First"].(float64)
+	if int(first) > len(data) {
+		return
+	}
+	header := &pdfLexer{data: data[:int(first)]}
+	for i := 0; i < int(n); i++ {
+		numV, err1 := header.value()
+		offV, err2 := header.value()
+		num, ok1 := numV.(float64)
+		off, ok2 := offV.(float64)
+		if err1 != nil || err2 != nil || !ok1 || !ok2 {
+			return
+		}
+		if _, exists := f.objects[int(num)]; exists {
+			continue
+		}
+		pos := int(first) + int(off)
+		if pos >= len(data) {
+			continue
+		}
+		l := &pdfLexer{data: data, pos: pos}
+		if v, err := l.value(); err == nil {
+			f.objects[int(num)] = v
+		}
+	}
+}
+
+// resolve follows references to the object they name.
+func (f *pdfFile) resolve(v any) any {
+	for i := 0; i < pdfMaxRefDepth; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = f.objects[ref.num]
+	}
+	return nil
+}
+
+func (f *pdfFile) dictOf(v any) pdfDict {
+	switch v := f.resolve(v).(type) {
+	case pdfDict:
+		return v
+	case *pdfStream:
+		return v.dict
+	}
+	return nil
+}
+
+// decodeStream applies the stream's filters. Only the text-bearing filters
+// are supported; image codecs return an error.
+func (f *pdfFile) decodeStream(s *pdfStream) ([]byte, error) {
+	var filters []any
+	switch v := f.resolve(s.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []any{v}
+	case []any:
+		filters = v
+	}
+	data := s.raw
+	for _, fv := range filters {
+		name, _ := f.resolve(fv).(pdfName)
+		switch name {
+		case "FlateDecode", "Fl":
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			out, err := io.ReadAll(io.LimitReader(zr, pdfMaxStreamBytes))
+			if err != nil && len(out) == 0 {
+				return nil, err
+			}
+			data = out
+		case "ASCIIHexDecode", "AHx":
+			l := &pdfLexer{data: append(append([]byte("<"), data...), '>')}
+			data = l.hexString()
+		case "ASCII85Decode", "A85":
+			if i := bytes.Index(data, []byte("~>")); i >= 0 {
+				data = data[:i]
+			}
+			out := make([]byte, 4*len(data)/5+4)
+			n, _, err := ascii85.Decode(out, bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~")), true)
+			if err != nil {
+				return nil, err
+			}
+			data = out[:n]
+		default:
+			return nil, fmt.Errorf("unsupported PDF filter %s", name)
+		}
+	}
+	return data, nil
+}
+
+// pages returns the page dictionaries in document order, with inherited
+// resources filled in.
+func (f *pdfFile) pages() []pdfDict {
+	var out []pdfDict
+	var walk func(node pdfDict, resources any, depth int)
+	walk = func(node pdfDict, resources any, depth int) {
+		// The depth limit also stops cycles in a malformed tree.
+		if node == nil || depth > pdfMaxTreeDepth || len(out) >= pdfMaxPages {
+			return
+		}
+		if r, ok := node["Resources"]; ok {
+			resources = r
+		}
+		kids, _ := f.resolve(node["Kids"]).([]any)
+		if node["Type"] == pdfName("Page") || kids == nil {
+			page := make(pdfDict, len(node)+1)
+			for k, v := range node {
+				page[k] = v
+			}
+			page["Resources"] = resources
+			out = append(out, page)
+			return
+		}
+		for _, kid := range kids {
+			if d, ok := f.resolve(kid).(pdfDict); ok {
+				walk(d, resources, depth+1)
+			}
+		}
+	}
+
+	for _, v := range f.objects {
+		if d, ok := v.(pdfDict); ok && d["Type"] == pdfName("Catalog") {
+			if root := f.dictOf(d["Pages"]); root != nil {
+				walk(root, nil, 0)
+			}
+			break
+		}
+	}
+	if len(out) > 0 {
+		return out
+	}
+
+	// No usable page tree: take page objects in object-number order.
+	nums := make([]int, 0)
+	for num, v := range f.objects {
+		if d, ok := v.(pdfDict); ok && d["Type"] == pdfName("Page") {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		if len(out) < pdfMaxPages {
+			out = append(out, f.objects[num].(pdfDict))
+		}
+	}
+	return out
+}
+
+// pageContents concatenates a page's decoded content streams.
+func (f *pdfFile) pageContents(page pdfDict) []byte {
+	var streams []any
+	switch v := f.resolve(page["Contents"]).(type) {
+	case *pdfStream:
+		streams = []any{v}
+	case []any:
+		streams = v
+	}
+	var buf bytes.Buffer
+	for _, sv := range streams {
+		s, ok := f.resolve(sv).(*pdfStream)
+		if !ok {
+			continue
+		}
+		if data, err := f.decodeStream(s); err == nil {
+			buf.Write(data)
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// pageFonts returns the decoders for the fonts named in a page's resources.
+func (f *pdfFile) pageFonts(page pdfDict) map[pdfName]*pdfFont {
+	fonts := make(map[pdfName]*pdfFont)
+	resources := f.dictOf(page["Resources"])
+	for name, fv := range f.dictOf(resources["Font"]) {
+		ref, isRef := fv.(pdfRef)
+		if isRef {
+			if cached, ok := f.fonts[ref]; ok {
+				fonts[pdfName(name)] = cached
+				continue
+			}
+		}
+		font := f.loadFont(f.dictOf(fv))
+		if isRef {
+			f.fonts[ref] = font
+		}
+		fonts[pdfName(name)] = font
+	}
+	return fonts
+}
+
+// pdfFont maps character codes in shown strings to text.
+type pdfFont struct {
+	codeLen int               // bytes per character code
+	cmap    map[uint32]string // from the ToUnicode CMap; nil for simple fonts
+}
+
+func (f *pdfFile) loadFont(d pdfDict) *pdfFont {
+	font := &pdfFont{codeLen: 1}
+	if d["Subtype"] == pdfName("Type0") {
+		font.codeLen = 2
+	}
+	if s, ok := f.resolve(d["ToUnicode"]).(*pdfStream); ok {
+		if data, err := f.decodeStream(s); err == nil {
+			font.cmap, font.codeLen = parseToUnicode(data, font.codeLen)
+		}
+	}
+	return font
+}
+
+// decode converts a shown string to text. Composite fonts without a
+// ToUnicode CMap cannot be decoded and yield nothing.
+func (font *pdfFont) decode(s []byte) string {
+	if font == nil {
+		return latin1(s)
+	}
+	if font.cmap == nil {
+		if font.codeLen > 1 {
+			return ""
+		}
+		return latin1(s)
+	}
+	var b strings.Builder
+	for i := 0; i+font.codeLen <= len(s); i += font.codeLen {
+		var code uint32
+		for _, c := range s[i : i+font.codeLen] {
+			code = code<<8 | uint32(c)
+		}
+		if text, ok := font.cmap[code]; ok {
+			b.WriteString(text)
+		} else if font.codeLen == 1 {
+			b.WriteString(latin1(s[i : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// parseToUnicode reads the bfchar and bfrange mappings of a ToUnicode CMap.
+func parseToUnicode(data []byte, codeLen int) (map[uint32]string, int) {
+	cmap := make(map[uint32]string)
+	l := &pdfLexer{data: data}
+	var operands []any
+	for {
+		v, err := l.value()
+		if err != nil {
+			break
+		}
+		kw, ok := v.(pdfKeyword)
+		if !ok {
+			operands = append(operands, v)
+			continue
+		}
+		switch kw {
+		case "endcodespacerange":
+			if len(operands) > 0 {
+				if lo, ok := operands[0].(pdfString); ok && len(lo) > 0 {
+					codeLen = len(lo)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					cmap[codeOf(src)] = utf16BE(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 {
+					continue
+				}
+				start, end := codeOf(lo), codeOf(hi)
+				if end < start || end-start > pdfMaxCMapRange {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					base := []uint16(nil)
+					for j := 0; j+1 < len(dst); j += 2 {
+						base = append(base, uint16(dst[j])<<8|uint16(dst[j+1]))
+					}
+					if len(base) == 0 {
+						continue
+					}
+					for code := start; code <= end; code++ {
+						units := append([]uint16(nil), base...)
+						units[len(units)-1] += uint16(code - start)
+						cmap[code] = string(utf16.Decode(units))
+					}
+				case []any:
+					for j, d := range dst {
+						if s, ok := d.(pdfString); ok && start+uint32(j) <= end {
+							cmap[start+uint32(j)] = utf16BE(s)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	return cmap, codeLen
+}
+
+func codeOf(b []byte) uint32 {
+	var code uint32
+	for _, c := range b {
+		code = code<<8 | uint32(c)
+	}
+	return code
+}
+
+func utf16BE(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func latin1(b []byte) string {
+	runes := make([]rune, 0, len(b))
+	for _, c := range b {
+		if c >= 0x20 || c == '\t' || c == '\n' {
+			runes = append(runes, rune(c))
+		}
+	}
+	return string(runes)
+}
+
+// pdfTextString decodes a text string from the document information
+// dictionary: UTF-16BE with a byte-order mark, otherwise Latin-1.
+func pdfTextString(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		return utf16BE(b[2:])
+	}
+	return latin1(b)
+}
+
+// pageText interprets the text operators in a content stream.
+func pageText(contents []byte, fonts map[pdfName]*pdfFont) string {
+	var b strings.Builder
+	newline := func() {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteByte('\n')
+		}
+	}
+	space := func() {
+		s := b.String()
+		if s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+			b.WriteByte(' ')
+		}
+	}
+	var font *pdfFont
+	show := func(v any) {
+		if s, ok := v.(pdfString); ok {
+			b.WriteString(font.decode(s))
+		}
+	}
+	number := func(v any) float64 {
+		n, _ := v.(float64)
+		return n
+	}
+
+	l := &pdfLexer{data: contents}
+	var operands []any
+	lastY, haveY := 0.0, false
+	for {
+		v, err := l.value()
+		if err != nil {
+			break
+		}
+		kw, ok := v.(pdfKeyword)
+		if !ok {
+			operands = append(operands, v)
+			continue
+		}
+		n := len(operands)
+		switch kw {
+		case "Tf":
+			if n >= 2 {
+				if name, ok := operands[n-2].(pdfName); ok {
+					font = fonts[name]
+				}
+			}
+		case "Tj":
+			if n >= 1 {
+				show(operands[n-1])
+			}
+		case "'", "\"":
+			newline()
+			if n >= 1 {
+				show(operands[n-1])
+			}
+		case "TJ":
+			if n >= 1 {
+				arr, _ := operands[n-1].([]any)
+				for _, item := range arr {
+					if adj, ok := item.(float64); ok {
+						if adj < -150 {
+							space()
+						}
+						continue
+					}
+					show(item)
+				}
+			}
+		case "Td", "TD":
+			if n >= 2 {
+				if number(operands[n-1]) != 0 {
+					newline()
+				} else if number(operands[n-2]) > 0 {
+					space()
+				}
+			}
+		case "T*":
+			newline()
+		case "Tm":
+			if n >= 6 {
+				y := number(operands[n-1])
+				if haveY && y != lastY {
+					newline()
+				} else if haveY {
+					space()
+				}
+				lastY, haveY = y, true
+			}
+		case "ET":
+			space()
+		case "ID":
+			l.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+	return cleanPDFText(b.String())
+}
+
+// cleanPDFText trims trailing spaces and collapses blank-line runs.
+func cleanPDFText(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// pdfDocument is the extracted text of a PDF.
+type pdfDocument struct {
+	title     string
+	pages     []string
+	encrypted bool
+}
+
+// extractPDF returns the title and per-page text of a PDF.
+func extractPDF(body []byte) *pdfDocument {
+	doc := &pdfDocument{}
+	f := parsePDF(body)
+	for _, v := range f.objects {
+		d, ok := v.(pdfDict)
+		if !ok {
+			continue
+		}
+		if d["Filter"] == pdfName("Standard") && d["O"] != nil && d["U"] != nil {
+			doc.encrypted = true
+		}
+		if title, ok := f.resolve(d["Title"]).(pdfString); ok && doc.title == "" &&
+			(d["Producer"] != nil || d["Creator"] != nil || d["Author"] != nil || d["CreationDate"] != nil) {
+			doc.title = strings.TrimSpace(pdfTextString(title))
+		}
+	}
+	for _, page := range f.pages() {
+		doc.pages = append(doc.pages, pageText(f.pageContents(page), f.pageFonts(page)))
+	}
+	return doc
+}
+
+// text joins the pages with page markers.
+func (d *pdfDocument) text() string {
+	var b strings.Builder
+	for i, page := range d.pages {
+		fmt.Fprintf(&b, "--- Page %d ---\n", i+1)
+		if page != "" {
+			b.WriteString(page)
+			b.WriteByte('\n')
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (d *pdfDocument) hasText() bool {
+	for _, p := range d.pages {
+		if p != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// renderPDF renders a PDF's text. Markdown is the full text with page
+// markers; summary is the title, page count and an excerpt. ok is false
+// when the PDF is too malformed to parse, so the caller can fall back to
+// the raw view.
+func renderPDF(body []byte, pageURL string, format Format, maxChars int) (out string, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			out, ok = "", false
+		}
+	}()
+	doc := extractPDF(body)
+
+	var b strings.Builder
+	if doc.title != "" {
+		fmt.Fprintf(&b, "# %s\n\n", doc.title)
+	}
+	if format == FormatSummary && pageURL != "" {
+		fmt.Fprintf(&b, "URL: %s\n\n", pageURL)
+	}
+	fmt.Fprintf(&b, "PDF document, %d page", len(doc.pages))
+	if len(doc.pages) != 1 {
+		b.WriteByte('s')
+	}
+	b.WriteString("\n\n")
+
+	switch {
+	case doc.encrypted:
+		b.WriteString("(the PDF is encrypted; its text cannot be extracted)")
+	case !doc.hasText():
+		b.WriteString("(no extractable text; the PDF may contain scanned images or use unsupported fonts)")
+	case format == FormatSummary:
+		text := doc.text()
+		fmt.Fprintf(&b, "## Excerpt\n%s\n", truncateChars(text, summaryExcerptChars))
+		if len(text) > summaryExcerptChars {
+			b.WriteString("\n[truncated; use format=markdown for full text]\n")
+		}
+	default:
+		b.WriteString(doc.text())
+	}
+	return truncateChars(strings.TrimRight(b.String(), "\n"), maxChars), true
+}
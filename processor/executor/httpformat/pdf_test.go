@@ -0,0 +1,179 @@
+package httpformat
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF assembles a PDF from numbered object bodies, with an xref table
+// and a trailer pointing at object 1 as the catalog. Empty bodies leave
+// their object number free.
+func buildPDF(objects []string, trailerExtra string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		if obj == "" {
+			continue
+		}
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for i, off := range offsets {
+		if objects[i] == "" {
+			b.WriteString("0000000000 65535 f \n")
+			continue
+		}
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R %s>>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailerExtra, xref)
+	return b.Bytes()
+}
+
+func pdfStreamObject(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func flate(t *testing.T, data string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func samplePDF(t *testing.T) []byte {
+	t.Helper()
+	page1 := "BT /F1 12 Tf 72 720 Td (Quarterly \\(draft\\) report) Tj 0 -14 Td [(Revenue) -300 (grew) -250 (th) -10 (ree percent.)] TJ ET"
+	page2 := "BT /F2 10 Tf 72 700 Td <00010002000300040005> Tj T* <0006> Tj ET"
+	cmap := `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar
+<0001> <0048>
+<0006> <00E9>
+endbfchar
+1 beginbfrange
+<0002> <0005> <0065>
+endbfrange
+endcmap
+end end`
+	return buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [8 0 R] >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Custom /ToUnicode 9 0 R >>",
+		pdfStreamObject("", []byte(page1)),
+		pdfStreamObject("/Filter /FlateDecode", flate(t, page2)),
+		pdfStreamObject("/Filter [/FlateDecode]", flate(t, cmap)),
+		"<< /Title (Q3 Report) /Producer (test) >>",
+	}, "/Info 10 0 R ")
+}
+
+func TestRenderPDF_Markdown(t *testing.T) {
+	out := Render(samplePDF(t), "application/pdf", "https://example.com/q3.pdf", FormatMarkdown, 0)
+
+	for _, want := range []string{
+		"# Q3 Report",
+		"PDF document, 2 pages",
+		"--- Page 1 ---\nQuarterly (draft) report\nRevenue grew three percent.",
+		"--- Page 2 ---\nHefgh\né",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderPDF_SniffedAndSummarized(t *testing.T) {
+	// Served with a generic content type; detected from the %PDF- header.
+	out := Render(samplePDF(t), "application/octet-stream", "", FormatSummary, 0)
+	if !strings.Contains(out, "PDF document, 2 pages") || !strings.Contains(out, "## Excerpt\n--- Page 1 ---") {
+		t.Errorf("summary = %s", out)
+	}
+
+	raw := Render(samplePDF(t), "application/pdf", "", FormatRaw, 0)
+	if !strings.HasPrefix(raw, "%PDF-1.7") {
+		t.Errorf("raw format should skip extraction, got %q", raw[:20])
+	}
+}
+
+func TestRenderPDF_NoText(t *testing.T) {
+	encrypted := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		pdfStreamObject("", []byte("\x8f\x01garbled")),
+		"<< /Filter /Standard /V 2 /R 3 /O (owner) /U (user) /P -4 >>",
+	}, "/Encrypt 5 0 R ")
+	if out := Render(encrypted, "application/pdf", "", FormatMarkdown, 0); !strings.Contains(out, "PDF is encrypted") {
+		t.Errorf("encrypted output = %s", out)
+	}
+
+	scanned := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		pdfStreamObject("", []byte("q 612 0 0 792 0 0 cm /Im0 Do Q")),
+	}, "")
+	out := Render(scanned, "application/pdf", "", FormatMarkdown, 0)
+	if !strings.Contains(out, "PDF document, 1 page\n") || !strings.Contains(out, "no extractable text") {
+		t.Errorf("scanned output = %s", out)
+	}
+
+	// Truncated download: objects are found without an xref table.
+	full := samplePDF(t)
+	cut := bytes.Index(full, []byte("xref"))
+	if out := Render(full[:cut], "application/pdf", "", FormatMarkdown, 0); !strings.Contains(out, "Revenue grew") {
+		t.Errorf("truncated PDF output = %s", out)
+	}
+}
+
+func TestRenderPDF_ObjectStream(t *testing.T) {
+	// Page and font dictionaries packed into a compressed object stream, as
+	// PDF 1.5+ writers produce.
+	page := "<< /Type /Page /Parent 2 0 R /Contents 6 0 R >> "
+	header := fmt.Sprintf("3 0 4 %d ", len(page))
+	packed := header + page + "<< /Type /Font /Subtype /Type1 >>"
+	content := "BT /F1 11 Tf (Packed page text) Tj ET"
+	out := Render(buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 4 0 R >> >> >>",
+		"",
+		"",
+		pdfStreamObject(fmt.Sprintf("/Type /ObjStm /N 2 /First %d /Filter /FlateDecode", len(header)), flate(t, packed)),
+		pdfStreamObject("", []byte(content)),
+	}, ""), "application/pdf", "", FormatMarkdown, 0)
+	if !strings.Contains(out, "Packed page text") {
+		t.Errorf("object stream output = %s", out)
+	}
+}
+
+func TestRenderPDF_TruncatedAnywhere(t *testing.T) {
+	// A download cut off at any byte must not panic the parser.
+	full := samplePDF(t)
+	for n := range len(full) {
+		out := Render(full[:n], "application/pdf", "", FormatMarkdown, 0)
+		if n > len("%PDF-") && out == "" {
+			t.Fatalf("truncated at %d: empty output", n)
+		}
+	}
+
+	cut := []byte("1 0 obj\n<< /Type /Catalog /Resources << /Font <")
+	if out := Render(cut, "application/pdf", "", FormatMarkdown, 0); !strings.Contains(out, "PDF document") {
+		t.Errorf("dangling hex string output = %s", out)
+	}
+}
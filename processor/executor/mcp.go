@@ -0,0 +1,774 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semstreams/agentic"
+)
+
+// =============================================================================
+// MCP TOOL SERVERS
+// =============================================================================
+// Model Context Protocol servers expose tools over JSON-RPC 2.0, either as a
+// stdio subprocess (newline-delimited JSON) or over streamable HTTP. Each
+// configured server is connected at startup; its tools are discovered with
+// tools/list and registered as mcp_<server>_<tool> RegisteredTools carrying
+// the server's tier, category, skill, danger and store gates. Calls go
+// through ToolRegistry.Execute like any builtin, so results reach the
+// trajectory through the same questtools path.
+// =============================================================================
+
+// mcpProtocolVersion is the MCP revision sent in initialize. Servers answer
+// with the revision they speak; tools/list and tools/call are unchanged
+// across the revisions we accept.
+const mcpProtocolVersion = "2025-06-18"
+
+const (
+	// mcpDefaultTimeout bounds one tools/call. It matches httpRequestTimeout
+	// so a slow server still leaves questtools time to publish the result.
+	mcpDefaultTimeout = httpRequestTimeout
+	// mcpConnectTimeout bounds initialize plus tool discovery per server.
+	mcpConnectTimeout = 30 * time.Second
+	// maxMCPMessageSize caps one JSON-RPC message read from a server.
+	maxMCPMessageSize = 16 << 20
+	// maxToolNameLength is the longest tool name LLM providers accept.
+	maxToolNameLength = 64
+)
+
+var (
+	mcpServerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	mcpToolNameReplacer  = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+)
+
+// MCPServerConfig configures one MCP tool server. Exactly one of Command
+// (stdio subprocess) or URL (streamable HTTP endpoint) must be set.
+type MCPServerConfig struct {
+	// Name identifies the server; it prefixes its tools as mcp_<name>_<tool>.
+	Name string `json:"name"`
+	// Command and Args start a stdio server. Env adds variables on top of
+	// PATH and HOME; the parent environment is not inherited.
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	// URL is the streamable HTTP endpoint, e.g. "http://localhost:8931/mcp".
+	URL string `json:"url,omitempty"`
+	// Tools lists the server tool names to register. Empty registers all.
+	Tools []string `json:"tools,omitempty"`
+	// MinTier gates every tool from this server. Defaults to Journeyman,
+	// the same tier as the builtin network tools.
+	MinTier *domain.TrustTier `json:"min_tier,omitempty"`
+	// Category groups the tools for questbridge filtering. Defaults to network.
+	Category ToolCategory `json:"category,omitempty"`
+	// Skills, when set, requires the agent to have at least one of them.
+	Skills []domain.SkillTag `json:"skills,omitempty"`
+	// Dangerous tools are only offered to quests whose AllowedTools name them.
+	Dangerous bool `json:"dangerous,omitempty"`
+	// StoreItem is the store item ID agents must own to use these tools.
+	// Sell it as an item_type "tool" catalog entry with tool_id "mcp_<name>".
+	StoreItem string `json:"store_item,omitempty"`
+	// Timeout bounds one tool call (Go duration). Default "25s".
+	Timeout string `json:"timeout,omitempty"`
+	// MaxResultBytes caps the tool result returned to the agent.
+	// Default: the same cap as bash output (100000).
+	MaxResultBytes int `json:"max_result_bytes,omitempty"`
+}
+
+// Validate checks the server configuration.
+func (c *MCPServerConfig) Validate() error {
+	if !mcpServerNamePattern.MatchString(c.Name) {
+		return fmt.Errorf("mcp server name %q must be lowercase letters, digits, '_' or '-'", c.Name)
+	}
+	if (c.Command == "") == (c.URL == "") {
+		return fmt.Errorf("mcp server %s: set exactly one of command or url", c.Name)
+	}
+	if c.MinTier != nil && (*c.MinTier < domain.TierApprentice || *c.MinTier > domain.TierGrandmaster) {
+		return fmt.Errorf("mcp server %s: invalid min_tier %d", c.Name, *c.MinTier)
+	}
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("mcp server %s: invalid timeout %q", c.Name, c.Timeout)
+		}
+	}
+	if c.MaxResultBytes < 0 {
+		return fmt.Errorf("mcp server %s: max_result_bytes must not be negative", c.Name)
+	}
+	return nil
+}
+
+func (c *MCPServerConfig) timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return mcpDefaultTimeout
+}
+
+func (c *MCPServerConfig) maxResultBytes() int {
+	if c.MaxResultBytes > 0 {
+		return c.MaxResultBytes
+	}
+	return maxCommandOutput
+}
+
+func (c *MCPServerConfig) minTier() domain.TrustTier {
+	if c.MinTier != nil {
+		return *c.MinTier
+	}
+	return domain.TierJourneyman
+}
+
+func (c *MCPServerConfig) category() ToolCategory {
+	if c.Category != "" {
+		return c.Category
+	}
+	return ToolCategoryNetwork
+}
+
+// allows reports whether the server's allowlist admits a tool.
+func (c *MCPServerConfig) allows(tool string) bool {
+	return len(c.Tools) == 0 || containsToolName(c.Tools, tool)
+}
+
+// MCPToolName returns the registry name for a server tool: mcp_<server>_<tool>
+// with characters providers reject replaced, capped at 64 characters.
+func MCPToolName(server, tool string) string {
+	name := "mcp_" + server + "_" + mcpToolNameReplacer.ReplaceAllString(tool, "_")
+	if len(name) > maxToolNameLength {
+		name = name[:maxToolNameLength]
+	}
+	return name
+}
+
+// =============================================================================
+// JSON-RPC
+// =============================================================================
+
+type mcpRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      *int64 `json:"id,omitempty"` // nil for notifications
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type mcpMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *mcpError       `json:"error,omitempty"`
+}
+
+type mcpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *mcpError) Error() string { return fmt.Sprintf("%s (code %d)", e.Message, e.Code) }
+
+// isResponseTo reports whether the message answers the request with id.
+func (m *mcpMessage) isResponseTo(id int64) bool {
+	return m.Method == "" && string(m.ID) == fmt.Sprint(id)
+}
+
+// mcpTransport carries JSON-RPC messages to one server. send returns the
+// response for requests and nil for notifications.
+type mcpTransport interface {
+	send(ctx context.Context, req mcpRequest) (*mcpMessage, error)
+	close() error
+}
+
+// MCPTool is a tool advertised by an MCP server.
+type MCPTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema,omitempty"`
+}
+
+// MCPContent is one item of a tool result's content.
+type MCPContent struct {
+	Type     string       `json:"type"` // text, image, audio, resource, resource_link
+	Text     string       `json:"text,omitempty"`
+	MimeType string       `json:"mimeType,omitempty"`
+	URI      string       `json:"uri,omitempty"` // resource_link
+	Resource *MCPResource `json:"resource,omitempty"`
+}
+
+// MCPResource is an embedded resource in a tool result.
+type MCPResource struct {
+	URI  string `json:"uri"`
+	Text string `json:"text,omitempty"`
+}
+
+// MCPToolResult is the result of tools/call.
+type MCPToolResult struct {
+	Content           []MCPContent `json:"content"`
+	StructuredContent any          `json:"structuredContent,omitempty"`
+	IsError           bool         `json:"isError,omitempty"`
+}
+
+// Text flattens the result content into the text an agent sees. Non-text
+// items are summarized; structured content is used when there is no text.
+func (r *MCPToolResult) Text() string {
+	var b strings.Builder
+	for _, c := range r.Content {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		switch {
+		case c.Type == "text":
+			b.WriteString(c.Text)
+		case c.Type == "resource" && c.Resource != nil && c.Resource.Text != "":
+			b.WriteString(c.Resource.Text)
+		case c.Type == "resource" && c.Resource != nil:
+			fmt.Fprintf(&b, "[resource: %s]", c.Resource.URI)
+		case c.Type == "resource_link":
+			fmt.Fprintf(&b, "[resource: %s]", c.URI)
+		default:
+			fmt.Fprintf(&b, "[%s content: %s]", c.Type, c.MimeType)
+		}
+	}
+	if b.Len() == 0 && r.StructuredContent != nil {
+		if data, err := json.Marshal(r.StructuredContent); err == nil {
+			b.Write(data)
+		}
+	}
+	return b.String()
+}
+
+// =============================================================================
+// CLIENT
+// =============================================================================
+
+// MCPClient is a connection to one MCP server. It is safe for concurrent use.
+type MCPClient struct {
+	config    MCPServerConfig
+	transport mcpTransport
+	nextID    atomic.Int64
+}
+
+// NewMCPClient connects to the server and performs the initialize handshake.
+// A stdio server runs until Close.
+func NewMCPClient(ctx context.Context, cfg MCPServerConfig) (*MCPClient, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	var (
+		transport mcpTransport
+		err       error
+	)
+	if cfg.Command != "" {
+		transport, err = newMCPStdioTransport(cfg)
+	} else {
+		transport = newMCPHTTPTransport(cfg.URL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &MCPClient{config: cfg, transport: transport}
+	if err := c.initialize(ctx); err != nil {
+		_ = transport.close()
+		return nil, fmt.Errorf("mcp server %s: initialize: %w", cfg.Name, err)
+	}
+	return c, nil
+}
+
+// Name returns the configured server name.
+func (c *MCPClient) Name() string { return c.config.Name }
+
+// Close ends the session and stops a stdio server.
+func (c *MCPClient) Close() error { return c.transport.close() }
+
+func (c *MCPClient) initialize(ctx context.Context) error {
+	var result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	err := c.call(ctx, "initialize", map[string]any{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "semdragons", "version": "1.0.0"},
+	}, &result)
+	if err != nil {
+		return err
+	}
+	if h, ok := c.transport.(*mcpHTTPTransport); ok {
+		h.setProtocolVersion(result.ProtocolVersion)
+	}
+	_, err = c.transport.send(ctx, mcpRequest{JSONRPC: "2.0", Method: "notifications/initialized"})
+	return err
+}
+
+// ListTools returns every tool the server advertises, following pagination.
+func (c *MCPClient) ListTools(ctx context.Context) ([]MCPTool, error) {
+	var (
+		tools  []MCPTool
+		cursor string
+	)
+	for {
+		var params map[string]any
+		if cursor != "" {
+			params = map[string]any{"cursor": cursor}
+		}
+		var page struct {
+			Tools      []MCPTool `json:"tools"`
+			NextCursor string    `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", params, &page); err != nil {
+			return nil, fmt.Errorf("mcp server %s: tools/list: %w", c.config.Name, err)
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CallTool invokes a server tool by its server-side name.
+func (c *MCPClient) CallTool(ctx context.Context, name string, args map[string]any) (*MCPToolResult, error) {
+	if args == nil {
+		args = map[string]any{}
+	}
+	var result MCPToolResult
+	if err := c.call(ctx, "tools/call", map[string]any{"name": name, "arguments": args}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *MCPClient) call(ctx context.Context, method string, params, out any) error {
+	id := c.nextID.Add(1)
+	resp, err := c.transport.send(ctx, mcpRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if out == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, out); err != nil {
+		return fmt.Errorf("decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// =============================================================================
+// STDIO TRANSPORT
+// =============================================================================
+
+type mcpStdioTransport struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	pending map[string]chan *mcpMessage
+	done    chan struct{} // closed when the server's stdout ends
+	err     error         // why the read loop stopped; set before done closes
+}
+
+func newMCPStdioTransport(cfg MCPServerConfig) (*mcpStdioTransport, error) {
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	cmd.Env = []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + os.Getenv("HOME"),
+	}
+	keys := make([]string, 0, len(cfg.Env))
+	for k := range cfg.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cmd.Env = append(cmd.Env, k+"="+cfg.Env[k])
+	}
+	cmd.Stderr = io.Discard
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("mcp server %s: %w", cfg.Name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("mcp server %s: %w", cfg.Name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("mcp server %s: start %s: %w", cfg.Name, cfg.Command, err)
+	}
+
+	t := &mcpStdioTransport{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[string]chan *mcpMessage),
+		done:    make(chan struct{}),
+	}
+	go t.readLoop(stdout)
+	return t, nil
+}
+
+// readLoop routes responses to their waiting callers and answers server
+// pings. Other server-initiated requests are refused.
+func (t *mcpStdioTransport) readLoop(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxMCPMessageSize)
+	for scanner.Scan() {
+		var msg mcpMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue // servers may log non-protocol lines
+		}
+		switch {
+		case msg.Method != "" && len(msg.ID) > 0:
+			t.answerServerRequest(&msg)
+		case msg.Method == "" && len(msg.ID) > 0:
+			t.mu.Lock()
+			ch, ok := t.pending[string(msg.ID)]
+			delete(t.pending, string(msg.ID))
+			t.mu.Unlock()
+			if ok {
+				ch <- &msg
+			}
+		}
+	}
+	t.err = scanner.Err()
+	if t.err == nil {
+		t.err = errors.New("server exited")
+	}
+	close(t.done)
+}
+
+func (t *mcpStdioTransport) answerServerRequest(msg *mcpMessage) {
+	reply := map[string]any{"jsonrpc": "2.0", "id": msg.ID}
+	if msg.Method == "ping" {
+		reply["result"] = map[string]any{}
+	} else {
+		reply["error"] = mcpError{Code: -32601, Message: "method not supported by client"}
+	}
+	_ = t.write(reply)
+}
+
+func (t *mcpStdioTransport) write(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	_, err = t.stdin.Write(append(data, '\n'))
+	return err
+}
+
+func (t *mcpStdioTransport) send(ctx context.Context, req mcpRequest) (*mcpMessage, error) {
+	select {
+	case <-t.done:
+		return nil, fmt.Errorf("mcp server unavailable: %w", t.err)
+	default:
+	}
+	if req.ID == nil {
+		return nil, t.write(req)
+	}
+
+	key := fmt.Sprint(*req.ID)
+	ch := make(chan *mcpMessage, 1)
+	t.mu.Lock()
+	t.pending[key] = ch
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, key)
+		t.mu.Unlock()
+	}()
+
+	if err := t.write(req); err != nil {
+		return nil, fmt.Errorf("write request: %w", err)
+	}
+	select {
+	case msg := <-ch:
+		return msg, nil
+	case <-t.done:
+		return nil, fmt.Errorf("mcp server unavailable: %w", t.err)
+	case <-ctx.Done():
+		// Tell the server to stop working on it; the reply, if any, is dropped.
+		_ = t.write(mcpRequest{JSONRPC: "2.0", Method: "notifications/cancelled",
+			Params: map[string]any{"requestId": *req.ID, "reason": ctx.Err().Error()}})
+		return nil, ctx.Err()
+	}
+}
+
+func (t *mcpStdioTransport) close() error {
+	_ = t.stdin.Close()
+	select {
+	case <-t.done:
+	case <-time.After(2 * time.Second):
+		_ = t.cmd.Process.Kill()
+	}
+	_ = t.cmd.Wait()
+	return nil
+}
+
+// =============================================================================
+// STREAMABLE HTTP TRANSPORT
+// =============================================================================
+
+type mcpHTTPTransport struct {
+	url        string
+	httpClient *http.Client
+
+	mu              sync.RWMutex
+	sessionID       string
+	protocolVersion string
+}
+
+func newMCPHTTPTransport(url string) *mcpHTTPTransport {
+	// Per-call contexts bound each request; the client timeout is a backstop.
+	return &mcpHTTPTransport{url: url, httpClient: &http.Client{Timeout: 5 * time.Minute}}
+}
+
+func (t *mcpHTTPTransport) setProtocolVersion(v string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.protocolVersion = v
+}
+
+func (t *mcpHTTPTransport) newRequest(ctx context.Context, method string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.url, body)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", t.sessionID)
+	}
+	if t.protocolVersion != "" {
+		req.Header.Set("MCP-Protocol-Version", t.protocolVersion)
+	}
+	return req, nil
+}
+
+func (t *mcpHTTPTransport) send(ctx context.Context, rpc mcpRequest) (*mcpMessage, error) {
+	data, err := json.Marshal(rpc)
+	if err != nil {
+		return nil, err
+	}
+	req, err := t.newRequest(ctx, http.MethodPost, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if id := resp.Header.Get("Mcp-Session-Id"); id != "" {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("mcp endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if rpc.ID == nil {
+		return nil, nil
+	}
+
+	body := io.LimitReader(resp.Body, maxMCPMessageSize)
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return readMCPEventStream(body, *rpc.ID)
+	}
+	var msg mcpMessage
+	if err := json.NewDecoder(body).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &msg, nil
+}
+
+// readMCPEventStream returns the response to id from an SSE body, skipping
+// server notifications sent ahead of it.
+func readMCPEventStream(body io.Reader, id int64) (*mcpMessage, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxMCPMessageSize)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if v, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(v, " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+		var msg mcpMessage
+		if err := json.Unmarshal([]byte(data.String()), &msg); err == nil && msg.isResponseTo(id) {
+			return &msg, nil
+		}
+		data.Reset()
+	}
+	if data.Len() > 0 {
+		var msg mcpMessage
+		if err := json.Unmarshal([]byte(data.String()), &msg); err == nil && msg.isResponseTo(id) {
+			return &msg, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read event stream: %w", err)
+	}
+	return nil, errors.New("event stream ended without a response")
+}
+
+// close ends the HTTP session. Servers that do not support DELETE answer 405,
+// which is fine.
+func (t *mcpHTTPTransport) close() error {
+	t.mu.RLock()
+	session := t.sessionID
+	t.mu.RUnlock()
+	if session == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := t.newRequest(ctx, http.MethodDelete, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// =============================================================================
+// REGISTRATION
+// =============================================================================
+
+// RegisterMCPServers connects to each configured server and registers its
+// allowlisted tools. A server that fails to connect is logged and skipped so
+// one broken server does not take down the rest. The returned clients must
+// be closed on shutdown.
+func (r *ToolRegistry) RegisterMCPServers(ctx context.Context, servers []MCPServerConfig, logger *slog.Logger) []*MCPClient {
+	var clients []*MCPClient
+	for _, cfg := range servers {
+		connectCtx, cancel := context.WithTimeout(ctx, mcpConnectTimeout)
+		client, err := NewMCPClient(connectCtx, cfg)
+		if err != nil {
+			cancel()
+			logger.Warn("mcp server disabled", "server", cfg.Name, "error", err)
+			continue
+		}
+		names, err := r.RegisterMCPTools(connectCtx, client)
+		cancel()
+		if err != nil {
+			_ = client.Close()
+			logger.Warn("mcp server disabled", "server", cfg.Name, "error", err)
+			continue
+		}
+		clients = append(clients, client)
+		logger.Info("mcp tools registered", "server", cfg.Name, "tools", names)
+	}
+	return clients
+}
+
+// RegisterMCPTools discovers the client's tools and registers the ones the
+// server's allowlist admits. Returns the registered tool names.
+func (r *ToolRegistry) RegisterMCPTools(ctx context.Context, client *MCPClient) ([]string, error) {
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &client.config
+	var names []string
+	for _, tool := range tools {
+		if !cfg.allows(tool.Name) {
+			continue
+		}
+		name := MCPToolName(cfg.Name, tool.Name)
+		params := tool.InputSchema
+		if params == nil {
+			params = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+		desc := tool.Description
+		if desc == "" {
+			desc = tool.Name
+		}
+		r.Register(RegisteredTool{
+			Definition: agentic.ToolDefinition{
+				Name:        name,
+				Description: fmt.Sprintf("%s (MCP server %s)", desc, cfg.Name),
+				Parameters:  params,
+			},
+			Handler:   mcpToolHandler(client, tool.Name),
+			Skills:    cfg.Skills,
+			MinTier:   cfg.minTier(),
+			Category:  cfg.category(),
+			Dangerous: cfg.Dangerous,
+			StoreItem: cfg.StoreItem,
+		})
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// mcpToolHandler proxies a call to the server tool, applying the server's
+// timeout and result cap.
+func mcpToolHandler(client *MCPClient, tool string) ToolHandler {
+	return func(ctx context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
+		cfg := &client.config
+		args := make(map[string]any, len(call.Arguments))
+		for k, v := range call.Arguments {
+			if k != "_sandbox_dir" { // registry-internal, never sent to servers
+				args[k] = v
+			}
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, cfg.timeout())
+		defer cancel()
+
+		metadata := map[string]any{"mcp_server": cfg.Name, "mcp_tool": tool}
+		res, err := client.CallTool(callCtx, tool, args)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s", cfg.timeout())
+			}
+			return agentic.ToolResult{
+				CallID:   call.ID,
+				Error:    fmt.Sprintf("mcp server %s: %s: %v", cfg.Name, tool, err),
+				Metadata: metadata,
+			}
+		}
+
+		content := res.Text()
+		if limit := cfg.maxResultBytes(); len(content) > limit {
+			content = content[:limit] + "\n... (output truncated)"
+			metadata["truncated"] = true
+		}
+		result := agentic.ToolResult{CallID: call.ID, Content: content, Metadata: metadata}
+		if res.IsError {
+			result.Error = fmt.Sprintf("mcp tool %s reported an error", tool)
+		}
+		return result
+	}
+}
@@ -0,0 +1,338 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semstreams/agentic"
+)
+
+// fakeMCPReply answers one JSON-RPC message the way a small MCP server with
+// echo, fail, big, slow and secret tools would. Returns nil for notifications.
+func fakeMCPReply(msg mcpMessage, params json.RawMessage) map[string]any {
+	if len(msg.ID) == 0 {
+		return nil
+	}
+	reply := map[string]any{"jsonrpc": "2.0", "id": msg.ID}
+	switch msg.Method {
+	case "initialize":
+		reply["result"] = map[string]any{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "fake", "version": "0"},
+		}
+	case "tools/list":
+		var p struct {
+			Cursor string `json:"cursor"`
+		}
+		_ = json.Unmarshal(params, &p)
+		if p.Cursor == "" {
+			reply["result"] = map[string]any{
+				"tools": []any{
+					map[string]any{"name": "echo", "description": "Echo text", "inputSchema": map[string]any{
+						"type": "object", "properties": map[string]any{"text": map[string]any{"type": "string"}},
+					}},
+					map[string]any{"name": "fail"},
+				},
+				"nextCursor": "page2",
+			}
+		} else {
+			reply["result"] = map[string]any{"tools": []any{
+				map[string]any{"name": "big"},
+				map[string]any{"name": "slow"},
+				map[string]any{"name": "secret.read"},
+			}}
+		}
+	case "tools/call":
+		var p struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		_ = json.Unmarshal(params, &p)
+		text := func(s string) []any { return []any{map[string]any{"type": "text", "text": s}} }
+		switch p.Name {
+		case "echo":
+			if _, leaked := p.Arguments["_sandbox_dir"]; leaked {
+				reply["result"] = map[string]any{"content": text("leaked _sandbox_dir"), "isError": true}
+				break
+			}
+			reply["result"] = map[string]any{"content": text(fmt.Sprint(p.Arguments["text"]))}
+		case "fail":
+			reply["result"] = map[string]any{"content": text("bad input"), "isError": true}
+		case "big":
+			reply["result"] = map[string]any{"content": text(strings.Repeat("x", 500))}
+		case "slow":
+			time.Sleep(500 * time.Millisecond)
+			reply["result"] = map[string]any{"content": text("done")}
+		default:
+			reply["error"] = map[string]any{"code": -32602, "message": "unknown tool " + p.Name}
+		}
+	default:
+		reply["error"] = map[string]any{"code": -32601, "message": "method not found"}
+	}
+	return reply
+}
+
+// newFakeMCPHTTPServer serves the fake MCP server over streamable HTTP.
+// tools/call responses are sent as SSE preceded by a progress notification.
+func newFakeMCPHTTPServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		var msg struct {
+			mcpMessage
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if msg.Method == "initialize" {
+			w.Header().Set("Mcp-Session-Id", "session-1")
+		} else if r.Header.Get("Mcp-Session-Id") != "session-1" {
+			http.Error(w, "missing session", http.StatusBadRequest)
+			return
+		}
+		reply := fakeMCPReply(msg.mcpMessage, msg.Params)
+		if reply == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		data, _ := json.Marshal(reply)
+		if msg.Method == "tools/call" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{}}\n\n")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestMCPHelperProcess is not a real test: it is the stdio MCP server that
+// TestMCPStdio starts as a subprocess.
+func TestMCPHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_MCP_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	out := json.NewEncoder(os.Stdout)
+	fmt.Println("fake mcp server starting") // non-protocol noise is ignored
+	for scanner.Scan() {
+		var msg struct {
+			mcpMessage
+			Params json.RawMessage `json:"params"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &msg) != nil {
+			continue
+		}
+		if reply := fakeMCPReply(msg.mcpMessage, msg.Params); reply != nil {
+			_ = out.Encode(reply)
+		}
+	}
+	os.Exit(0)
+}
+
+func mcpTestLogger() *slog.Logger { return slog.New(slog.NewTextHandler(io.Discard, nil)) }
+
+func TestMCPServerConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tier := domain.TrustTier(9)
+	tests := []struct {
+		name    string
+		cfg     MCPServerConfig
+		wantErr bool
+	}{
+		{"stdio", MCPServerConfig{Name: "fs", Command: "mcp-fs"}, false},
+		{"http", MCPServerConfig{Name: "web-tools", URL: "http://localhost:1/mcp"}, false},
+		{"both transports", MCPServerConfig{Name: "x", Command: "a", URL: "http://b"}, true},
+		{"no transport", MCPServerConfig{Name: "x"}, true},
+		{"bad name", MCPServerConfig{Name: "My Server", Command: "a"}, true},
+		{"bad tier", MCPServerConfig{Name: "x", Command: "a", MinTier: &tier}, true},
+		{"bad timeout", MCPServerConfig{Name: "x", Command: "a", Timeout: "soon"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMCPToolName(t *testing.T) {
+	t.Parallel()
+
+	if got := MCPToolName("docs", "search.pages"); got != "mcp_docs_search_pages" {
+		t.Errorf("MCPToolName = %q", got)
+	}
+	if got := MCPToolName("docs", strings.Repeat("a", 100)); len(got) != maxToolNameLength {
+		t.Errorf("long name length = %d, want %d", len(got), maxToolNameLength)
+	}
+}
+
+func TestMCPHTTP_RegisterAndExecute(t *testing.T) {
+	t.Parallel()
+
+	srv := newFakeMCPHTTPServer(t)
+	expert := domain.TierExpert
+	reg := NewToolRegistryWithSandbox(t.TempDir())
+	clients := reg.RegisterMCPServers(context.Background(), []MCPServerConfig{{
+		Name:           "fake",
+		URL:            srv.URL,
+		Tools:          []string{"echo", "fail", "big", "slow"},
+		MinTier:        &expert,
+		Category:       ToolCategoryKnowledge,
+		MaxResultBytes: 100,
+		Timeout:        "100ms",
+	}}, mcpTestLogger())
+	if len(clients) != 1 {
+		t.Fatalf("clients = %d, want 1", len(clients))
+	}
+	defer clients[0].Close()
+
+	echo := reg.Get("mcp_fake_echo")
+	if echo == nil {
+		t.Fatal("mcp_fake_echo not registered")
+	}
+	if echo.MinTier != domain.TierExpert || echo.Category != ToolCategoryKnowledge {
+		t.Errorf("echo gates = tier %d, category %q", echo.MinTier, echo.Category)
+	}
+	if reg.Get("mcp_fake_big") == nil {
+		t.Error("second tools/list page was not registered")
+	}
+	if reg.Get("mcp_fake_secret_read") != nil {
+		t.Error("tool outside the allowlist was registered")
+	}
+
+	quest := &domain.Quest{}
+	agent := &agentprogression.Agent{Tier: domain.TierExpert}
+	ctx := context.Background()
+
+	res := reg.Execute(ctx, makeToolCall("mcp_fake_echo", map[string]any{"text": "hello"}), quest, agent)
+	if res.Error != "" || res.Content != "hello" {
+		t.Fatalf("echo result = %+v", res)
+	}
+	if res.Metadata["mcp_server"] != "fake" || res.Metadata["mcp_tool"] != "echo" {
+		t.Errorf("metadata = %v", res.Metadata)
+	}
+
+	res = reg.Execute(ctx, makeToolCall("mcp_fake_fail", nil), quest, agent)
+	if res.Error == "" || res.Content != "bad input" {
+		t.Errorf("fail result = %+v, want error with content", res)
+	}
+
+	res = reg.Execute(ctx, makeToolCall("mcp_fake_big", nil), quest, agent)
+	if !strings.HasSuffix(res.Content, "(output truncated)") || res.Metadata["truncated"] != true {
+		t.Errorf("big result not truncated: %d bytes", len(res.Content))
+	}
+
+	res = reg.Execute(ctx, makeToolCall("mcp_fake_slow", nil), quest, agent)
+	if !strings.Contains(res.Error, "timed out") {
+		t.Errorf("slow result = %+v, want timeout", res)
+	}
+
+	res = reg.Execute(ctx, makeToolCall("mcp_fake_echo", nil), quest, &agentprogression.Agent{Tier: domain.TierJourneyman})
+	if !strings.Contains(res.Error, "insufficient trust tier") {
+		t.Errorf("journeyman result = %+v, want tier error", res)
+	}
+}
+
+func TestMCPStdio(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewMCPClient(context.Background(), MCPServerConfig{
+		Name:    "helper",
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestMCPHelperProcess$"},
+		Env:     map[string]string{"GO_WANT_MCP_HELPER": "1"},
+	})
+	if err != nil {
+		t.Fatalf("NewMCPClient: %v", err)
+	}
+	defer client.Close()
+
+	reg := NewToolRegistry()
+	names, err := reg.RegisterMCPTools(context.Background(), client)
+	if err != nil {
+		t.Fatalf("RegisterMCPTools: %v", err)
+	}
+	if len(names) != 5 {
+		t.Errorf("registered %v, want all 5 tools", names)
+	}
+
+	agent := &agentprogression.Agent{Tier: domain.TierJourneyman}
+	res := reg.Execute(context.Background(), makeToolCall("mcp_helper_echo", map[string]any{"text": "over stdio"}), &domain.Quest{}, agent)
+	if res.Error != "" || res.Content != "over stdio" {
+		t.Errorf("echo result = %+v", res)
+	}
+}
+
+func TestMCPNewClient_ConnectFailure(t *testing.T) {
+	t.Parallel()
+
+	reg := NewToolRegistry()
+	clients := reg.RegisterMCPServers(context.Background(), []MCPServerConfig{
+		{Name: "missing", Command: "/nonexistent/mcp-server"},
+		{Name: "down", URL: "http://127.0.0.1:1/mcp"},
+	}, mcpTestLogger())
+	if len(clients) != 0 || len(reg.ListAll()) != 0 {
+		t.Errorf("clients = %d, tools = %d, want none", len(clients), len(reg.ListAll()))
+	}
+}
+
+func TestRegisteredTool_DangerAndStoreGates(t *testing.T) {
+	t.Parallel()
+
+	reg := NewToolRegistry()
+	handler := func(_ context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
+		return agentic.ToolResult{CallID: call.ID, Content: "ok"}
+	}
+	reg.Register(RegisteredTool{Definition: agentic.ToolDefinition{Name: "wipe"}, Handler: handler, Dangerous: true})
+	reg.Register(RegisteredTool{Definition: agentic.ToolDefinition{Name: "premium"}, Handler: handler, StoreItem: "premium_pass"})
+
+	agent := &agentprogression.Agent{Tier: domain.TierMaster}
+	plain := &domain.Quest{}
+	names := toolNames(reg.GetToolsForQuest(plain, agent))
+	assertNotContainsStr(t, names, "wipe")
+	assertNotContainsStr(t, names, "premium")
+
+	if res := reg.Execute(context.Background(), makeToolCall("wipe", nil), plain, agent); !strings.Contains(res.Error, "dangerous") {
+		t.Errorf("dangerous tool ran without allowlist: %+v", res)
+	}
+	if res := reg.Execute(context.Background(), makeToolCall("premium", nil), plain, agent); !strings.Contains(res.Error, "premium_pass") {
+		t.Errorf("store tool ran without purchase: %+v", res)
+	}
+
+	allowed := &domain.Quest{AllowedTools: []string{"wipe", "premium"}}
+	owner := &agentprogression.Agent{Tier: domain.TierMaster, OwnedTools: map[string]agentprogression.OwnedTool{
+		"premium_pass": {StoreItemID: "premium_pass", UsesRemaining: 2},
+	}}
+	names = toolNames(reg.GetToolsForQuest(allowed, owner))
+	assertContainsStr(t, names, "wipe")
+	assertContainsStr(t, names, "premium")
+
+	spent := &agentprogression.Agent{Tier: domain.TierMaster, OwnedTools: map[string]agentprogression.OwnedTool{
+		"premium_pass": {StoreItemID: "premium_pass", UsesRemaining: 0},
+	}}
+	if res := reg.Execute(context.Background(), makeToolCall("premium", nil), allowed, spent); res.Error == "" {
+		t.Error("store tool ran with an exhausted rental")
+	}
+}
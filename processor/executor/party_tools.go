@@ -0,0 +1,222 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semdragons/processor/partycoord"
+	"github.com/c360studio/semstreams/agentic"
+)
+
+// =============================================================================
+// PARTY BLACKBOARD TOOLS (conditional registration)
+// =============================================================================
+// party_note_write, party_note_read and message_member let members of a
+// party coordinate directly instead of only through the lead. They are
+// scoped to the party on the calling quest: the party ID comes from the
+// dispatch metadata, and the caller must be a current party member.
+// =============================================================================
+
+// PartyBlackboard is the blackboard storage the party tools use.
+// *partycoord.BlackboardStore implements it.
+type PartyBlackboard interface {
+	Get(ctx context.Context, partyID domain.PartyID) (*partycoord.Blackboard, error)
+	WriteNote(ctx context.Context, partyID domain.PartyID, author domain.AgentID, key, content string) (*partycoord.BlackboardNote, error)
+	SendMessage(ctx context.Context, partyID domain.PartyID, from, to domain.AgentID, content string) (*partycoord.PartyMessage, error)
+}
+
+// PartyMembersFunc returns the current members of a party, lead included.
+type PartyMembersFunc func(ctx context.Context, partyID domain.PartyID) ([]domain.AgentID, error)
+
+// notePreviewLength bounds note previews in party_note_read listings.
+const notePreviewLength = 200
+
+// RegisterPartyTools adds the blackboard and messaging tools to the registry.
+// Call this only when the PARTY_BLACKBOARD bucket is available.
+func (r *ToolRegistry) RegisterPartyTools(board PartyBlackboard, members PartyMembersFunc) {
+	r.Register(RegisteredTool{
+		Definition: agentic.ToolDefinition{
+			Name:        "party_note_write",
+			Description: "Write a note to your party's shared blackboard. Use it to publish interfaces, decisions and conventions other members must follow. Writing an existing key overwrites it.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"key": map[string]any{
+						"type":        "string",
+						"description": fmt.Sprintf("Short note name, e.g. \"api-contract\" (max %d chars)", partycoord.MaxNoteKeyLength),
+					},
+					"content": map[string]any{
+						"type":        "string",
+						"description": fmt.Sprintf("Note content (max %d bytes)", partycoord.MaxNoteBytes),
+					},
+				},
+				"required": []any{"key", "content"},
+			},
+		},
+		Handler:  partyNoteWriteHandler(board, members),
+		MinTier:  domain.TierApprentice,
+		Category: ToolCategoryParty,
+	})
+
+	r.Register(RegisteredTool{
+		Definition: agentic.ToolDefinition{
+			Name:        "party_note_read",
+			Description: "Read your party's shared blackboard. Without a key, lists every note with a preview; with a key, returns that note in full.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"key": map[string]any{
+						"type":        "string",
+						"description": "Note to read in full. Omit to list all notes.",
+					},
+				},
+				"required": []any{},
+			},
+		},
+		Handler:  partyNoteReadHandler(board, members),
+		MinTier:  domain.TierApprentice,
+		Category: ToolCategoryParty,
+	})
+
+	r.Register(RegisteredTool{
+		Definition: agentic.ToolDefinition{
+			Name: "message_member",
+			Description: fmt.Sprintf("Send a direct message to another member of your party, e.g. to flag an interface change that affects their work. "+
+				"It is delivered into their next loop iteration. Limited to %d messages per minute.", partycoord.MessageRateLimit),
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"to": map[string]any{
+						"type":        "string",
+						"description": "Recipient agent ID or name, as listed under your party members",
+					},
+					"content": map[string]any{
+						"type":        "string",
+						"description": fmt.Sprintf("Message text (max %d bytes)", partycoord.MaxMessageBytes),
+					},
+				},
+				"required": []any{"to", "content"},
+			},
+		},
+		Handler:  messageMemberHandler(board, members),
+		MinTier:  domain.TierApprentice,
+		Category: ToolCategoryParty,
+	})
+}
+
+// partyScope checks that the caller is on a party quest and is a member of
+// that party, and returns the party's members.
+func partyScope(ctx context.Context, members PartyMembersFunc, quest *domain.Quest, agent *agentprogression.Agent) (domain.PartyID, []domain.AgentID, error) {
+	if quest.PartyID == nil || *quest.PartyID == "" {
+		return "", nil, fmt.Errorf("this quest is not part of a party")
+	}
+	partyID := *quest.PartyID
+	ids, err := members(ctx, partyID)
+	if err != nil {
+		return "", nil, fmt.Errorf("load party: %w", err)
+	}
+	for _, id := range ids {
+		if id == agent.ID {
+			return partyID, ids, nil
+		}
+	}
+	return "", nil, fmt.Errorf("you are not a member of party %s", partyID)
+}
+
+func partyNoteWriteHandler(board PartyBlackboard, members PartyMembersFunc) ToolHandler {
+	return func(ctx context.Context, call agentic.ToolCall, quest *domain.Quest, agent *agentprogression.Agent) agentic.ToolResult {
+		partyID, _, err := partyScope(ctx, members, quest, agent)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+		}
+		key, _ := call.Arguments["key"].(string)
+		content, _ := call.Arguments["content"].(string)
+		note, err := board.WriteNote(ctx, partyID, agent.ID, key, content)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+		}
+		return agentic.ToolResult{
+			CallID:  call.ID,
+			Content: fmt.Sprintf("Note %q saved (%d bytes). Party members can read it with party_note_read.", note.Key, len(note.Content)),
+		}
+	}
+}
+
+func partyNoteReadHandler(board PartyBlackboard, members PartyMembersFunc) ToolHandler {
+	return func(ctx context.Context, call agentic.ToolCall, quest *domain.Quest, agent *agentprogression.Agent) agentic.ToolResult {
+		partyID, _, err := partyScope(ctx, members, quest, agent)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+		}
+		bb, err := board.Get(ctx, partyID)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("read blackboard: %v", err)}
+		}
+
+		if key, _ := call.Arguments["key"].(string); strings.TrimSpace(key) != "" {
+			note := bb.Note(strings.TrimSpace(key))
+			if note == nil {
+				return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("no note %q on the party blackboard", key)}
+			}
+			return agentic.ToolResult{
+				CallID:  call.ID,
+				Content: fmt.Sprintf("%s (by %s, %s)\n\n%s", note.Key, note.Author, note.UpdatedAt.Format("15:04:05"), note.Content),
+			}
+		}
+
+		if len(bb.Notes) == 0 {
+			return agentic.ToolResult{CallID: call.ID, Content: "The party blackboard is empty."}
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%d notes on the party blackboard:\n", len(bb.Notes))
+		for _, n := range bb.Notes {
+			preview := truncate(strings.Join(strings.Fields(n.Content), " "), notePreviewLength)
+			fmt.Fprintf(&sb, "- %s (by %s): %s\n", n.Key, n.Author, preview)
+		}
+		return agentic.ToolResult{CallID: call.ID, Content: sb.String()}
+	}
+}
+
+func messageMemberHandler(board PartyBlackboard, members PartyMembersFunc) ToolHandler {
+	return func(ctx context.Context, call agentic.ToolCall, quest *domain.Quest, agent *agentprogression.Agent) agentic.ToolResult {
+		partyID, ids, err := partyScope(ctx, members, quest, agent)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+		}
+		to, _ := call.Arguments["to"].(string)
+		content, _ := call.Arguments["content"].(string)
+
+		recipient := resolvePartyMember(ids, strings.TrimSpace(to))
+		if recipient == "" {
+			return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("%q is not a member of your party", to)}
+		}
+		if recipient == agent.ID {
+			return agentic.ToolResult{CallID: call.ID, Error: "you cannot message yourself; use party_note_write to leave a note"}
+		}
+
+		if _, err := board.SendMessage(ctx, partyID, agent.ID, recipient, content); err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+		}
+		return agentic.ToolResult{
+			CallID:  call.ID,
+			Content: fmt.Sprintf("Message queued for %s. It is delivered into their next loop iteration.", recipient),
+		}
+	}
+}
+
+// resolvePartyMember matches a recipient by full agent ID or by the ID's
+// final segment. Returns "" when nothing matches.
+func resolvePartyMember(members []domain.AgentID, to string) domain.AgentID {
+	if to == "" {
+		return ""
+	}
+	for _, id := range members {
+		if string(id) == to || domain.ExtractInstance(string(id)) == to {
+			return id
+		}
+	}
+	return ""
+}
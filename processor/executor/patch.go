@@ -0,0 +1,312 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// UNIFIED DIFF PATCHING - apply_patch parsing and fuzzy hunk application
+// =============================================================================
+// Model-written diffs are rarely exact: line numbers drift, hunk counts are
+// wrong and whitespace gets normalized. Hunk headers are therefore only used
+// as a position hint. Each hunk's old lines are located by searching outward
+// from that hint, first exactly, then ignoring trailing whitespace, then
+// ignoring indentation. A hunk that cannot be located is rejected with a
+// report of the closest candidate so the agent can fix its patch.
+// =============================================================================
+
+// Fuzz levels for hunk matching, from strictest to loosest.
+const (
+	fuzzExact = iota
+	fuzzTrailingSpace
+	fuzzIndentation
+)
+
+// hunkHeaderRe matches "@@ -12,5 +12,7 @@" with optional counts and trailer.
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// patchHunk is one @@ section of a file patch.
+type patchHunk struct {
+	Header   string
+	OldStart int      // 1-based line hint from the header
+	Lines    []string // Prefixed hunk lines: ' ' context, '-' removed, '+' added
+}
+
+// filePatch is the set of hunks for one file.
+type filePatch struct {
+	OldPath string // "" for a new file
+	NewPath string // "" for a deleted file
+	Hunks   []patchHunk
+}
+
+// Path returns the file the patch writes to.
+func (fp *filePatch) Path() string {
+	if fp.NewPath != "" {
+		return fp.NewPath
+	}
+	return fp.OldPath
+}
+
+// IsNew reports whether the patch creates a file.
+func (fp *filePatch) IsNew() bool { return fp.OldPath == "" }
+
+// IsDelete reports whether the patch deletes a file.
+func (fp *filePatch) IsDelete() bool { return fp.NewPath == "" }
+
+// hunkResult reports how a single hunk fared.
+type hunkResult struct {
+	Header  string `json:"header"`
+	Applied bool   `json:"applied"`
+	Line    int    `json:"line,omitempty"`   // 1-based line the hunk was applied at
+	Offset  int    `json:"offset,omitempty"` // Lines away from the header's position
+	Fuzz    int    `json:"fuzz,omitempty"`   // 0 exact, 1 trailing whitespace, 2 indentation
+	Reason  string `json:"reason,omitempty"` // Why the hunk was rejected
+}
+
+// parsePatch parses a unified diff covering one or more files. "a/" and
+// "b/" path prefixes are stripped; /dev/null marks created and deleted files.
+func parsePatch(text string) ([]filePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var patches []filePatch
+	var cur *filePatch
+	var hunk *patchHunk
+
+	flushHunk := func() {
+		if cur != nil && hunk != nil {
+			// Blank lines trailing a hunk are separators, not context.
+			for len(hunk.Lines) > 0 && hunk.Lines[len(hunk.Lines)-1] == "" {
+				hunk.Lines = hunk.Lines[:len(hunk.Lines)-1]
+			}
+			cur.Hunks = append(cur.Hunks, *hunk)
+		}
+		hunk = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if cur != nil {
+			patches = append(patches, *cur)
+		}
+		cur = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			flushFile()
+			cur = &filePatch{
+				OldPath: patchPath(line[4:], "a/"),
+				NewPath: patchPath(lines[i+1][4:], "b/"),
+			}
+			i++
+		case strings.HasPrefix(line, "@@"):
+			if cur == nil {
+				return nil, errors.New("hunk header before any ---/+++ file header")
+			}
+			flushHunk()
+			m := hunkHeaderRe.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("malformed hunk header %q", line)
+			}
+			start, _ := strconv.Atoi(m[1])
+			hunk = &patchHunk{Header: line, OldStart: start}
+		case hunk != nil:
+			switch {
+			case line == "":
+				// Editors and models often drop the space on blank context lines.
+				hunk.Lines = append(hunk.Lines, "")
+			case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+				hunk.Lines = append(hunk.Lines, line)
+			case line[0] == '\\':
+				// "\ No newline at end of file" — final newline is preserved as-is.
+			default:
+				// Anything else ends the hunk (e.g. "diff --git", "index ...").
+				flushHunk()
+			}
+		}
+	}
+	flushFile()
+
+	if len(patches) == 0 {
+		return nil, errors.New("no file headers found; expected a unified diff with ---/+++ lines")
+	}
+	for _, fp := range patches {
+		if fp.Path() == "" {
+			return nil, errors.New("patch has a file header without a path")
+		}
+		if len(fp.Hunks) == 0 && !fp.IsDelete() {
+			return nil, fmt.Errorf("patch for %s has no hunks", fp.Path())
+		}
+	}
+	return patches, nil
+}
+
+// patchPath extracts a path from a ---/+++ header value, dropping any
+// timestamp and the git-style prefix. Returns "" for /dev/null.
+func patchPath(s, prefix string) string {
+	if tab := strings.IndexByte(s, '\t'); tab >= 0 {
+		s = s[:tab]
+	}
+	s = strings.TrimSpace(s)
+	if s == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(s, prefix)
+}
+
+// applyHunks applies hunks to content in order. Every hunk is attempted so
+// the report covers all of them; the returned content is only meaningful
+// when no hunk was rejected.
+func applyHunks(content string, hunks []patchHunk) (string, []hunkResult) {
+	trailingNewline := content == "" || strings.HasSuffix(content, "\n")
+	var lines []string
+	if content != "" {
+		lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+
+	results := make([]hunkResult, 0, len(hunks))
+	offset := 0 // Net lines added by hunks applied so far
+	floor := 0  // Hunks apply in order and never overlap
+	for _, h := range hunks {
+		var old []string
+		for _, l := range h.Lines {
+			if l == "" || l[0] != '+' {
+				old = append(old, hunkText(l))
+			}
+		}
+
+		want := max(h.OldStart-1, 0) + offset
+		if len(old) == 0 && h.OldStart > 0 {
+			// Pure insertion: "-N,0" means after line N.
+			want++
+		}
+		pos, fuzz := locateHunk(lines, old, want, floor)
+		if pos < 0 {
+			results = append(results, hunkResult{Header: h.Header, Reason: rejectReason(lines, old, want)})
+			continue
+		}
+
+		// Context lines keep the file's own text so fuzzy matches don't
+		// rewrite whitespace the hunk didn't mean to touch.
+		replacement := make([]string, 0, len(h.Lines))
+		at := pos
+		for _, l := range h.Lines {
+			switch {
+			case l != "" && l[0] == '+':
+				replacement = append(replacement, l[1:])
+			case l != "" && l[0] == '-':
+				at++
+			default:
+				replacement = append(replacement, lines[at])
+				at++
+			}
+		}
+
+		tail := append([]string{}, lines[pos+len(old):]...)
+		lines = append(append(lines[:pos], replacement...), tail...)
+
+		results = append(results, hunkResult{
+			Header:  h.Header,
+			Applied: true,
+			Line:    pos + 1,
+			Offset:  pos - want,
+			Fuzz:    fuzz,
+		})
+		offset += pos - want + len(replacement) - len(old)
+		floor = pos + len(replacement)
+	}
+
+	out := strings.Join(lines, "\n")
+	if trailingNewline && len(lines) > 0 {
+		out += "\n"
+	}
+	return out, results
+}
+
+// hunkText strips the one-character prefix from a hunk line.
+func hunkText(l string) string {
+	if l == "" {
+		return ""
+	}
+	return l[1:]
+}
+
+// locateHunk finds where old appears in lines, at or after floor, preferring
+// the strictest fuzz level and then the position closest to want.
+// Returns -1 when there is no match.
+func locateHunk(lines, old []string, want, floor int) (pos, fuzz int) {
+	if len(old) == 0 {
+		return min(max(want, floor), len(lines)), fuzzExact
+	}
+	for fuzz := fuzzExact; fuzz <= fuzzIndentation; fuzz++ {
+		best := -1
+		for start := floor; start+len(old) <= len(lines); start++ {
+			if !linesMatch(lines[start:start+len(old)], old, fuzz) {
+				continue
+			}
+			if best < 0 || abs(start-want) < abs(best-want) {
+				best = start
+			}
+		}
+		if best >= 0 {
+			return best, fuzz
+		}
+	}
+	return -1, 0
+}
+
+// linesMatch compares two equal-length line slices at a fuzz level.
+func linesMatch(have, want []string, fuzz int) bool {
+	for i := range want {
+		a, b := have[i], want[i]
+		switch fuzz {
+		case fuzzTrailingSpace:
+			a, b = strings.TrimRight(a, " \t"), strings.TrimRight(b, " \t")
+		case fuzzIndentation:
+			a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+		}
+		if a != b {
+			return false
+		}
+	}
+	return true
+}
+
+// rejectReason explains a rejected hunk by showing where the closest
+// candidate diverges from the expected lines.
+func rejectReason(lines, old []string, want int) string {
+	bestStart, bestScore := -1, -1
+	for start := 0; start+len(old) <= len(lines); start++ {
+		score := 0
+		for i := range old {
+			if strings.TrimSpace(lines[start+i]) == strings.TrimSpace(old[i]) {
+				score++
+			}
+		}
+		if score > bestScore || (score == bestScore && abs(start-want) < abs(bestStart-want)) {
+			bestStart, bestScore = start, score
+		}
+	}
+	if bestStart < 0 || bestScore == 0 {
+		return fmt.Sprintf("none of the %d context/removed lines were found in the file", len(old))
+	}
+	for i := range old {
+		if strings.TrimSpace(lines[bestStart+i]) != strings.TrimSpace(old[i]) {
+			return fmt.Sprintf("closest match at line %d (%d/%d lines agree); line %d expected %q but file has %q",
+				bestStart+1, bestScore, len(old), bestStart+i+1, old[i], lines[bestStart+i])
+		}
+	}
+	return "context matched but overlaps an earlier hunk"
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,145 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePatch(t *testing.T) {
+	t.Parallel()
+
+	patch := `diff --git a/pkg/a.go b/pkg/a.go
+index 123..456 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -1,3 +1,3 @@ func A()
+ package pkg
+-var x = 1
++var x = 2
+
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1,2 @@
++hello
++world
+`
+	files, err := parsePatch(patch)
+	if err != nil {
+		t.Fatalf("parsePatch: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	if files[0].Path() != "pkg/a.go" || files[0].IsNew() {
+		t.Errorf("first file = %+v", files[0])
+	}
+	if got := files[0].Hunks[0].Lines; len(got) != 3 {
+		t.Errorf("first hunk lines = %q, want trailing blank dropped", got)
+	}
+	if files[1].Path() != "new.txt" || !files[1].IsNew() {
+		t.Errorf("second file = %+v", files[1])
+	}
+
+	for _, bad := range []string{"just some text", "--- a/x\n+++ b/x\n@@ nonsense @@\n"} {
+		if _, err := parsePatch(bad); err == nil {
+			t.Errorf("parsePatch(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestApplyHunks(t *testing.T) {
+	t.Parallel()
+
+	file := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n"
+
+	tests := []struct {
+		name      string
+		patch     string
+		want      string
+		wantFuzz  int
+		wantShift int
+		reject    string
+	}{
+		{
+			name: "exact",
+			patch: "--- a/m.go\n+++ b/m.go\n@@ -5,3 +5,3 @@\n func main() {\n" +
+				"-\tfmt.Println(\"hello\")\n+\tfmt.Println(\"bye\")\n }\n",
+			want: strings.Replace(file, "hello", "bye", 1),
+		},
+		{
+			name: "wrong line numbers",
+			patch: "--- a/m.go\n+++ b/m.go\n@@ -1,3 +1,3 @@\n func main() {\n" +
+				"-\tfmt.Println(\"hello\")\n+\tfmt.Println(\"bye\")\n }\n",
+			want:      strings.Replace(file, "hello", "bye", 1),
+			wantShift: 4,
+		},
+		{
+			name: "indentation differs",
+			patch: "--- a/m.go\n+++ b/m.go\n@@ -5,3 +5,3 @@\n func main() {\n" +
+				"-    fmt.Println(\"hello\")\n+\tfmt.Println(\"bye\")\n }\n",
+			want:     strings.Replace(file, "hello", "bye", 1),
+			wantFuzz: fuzzIndentation,
+		},
+		{
+			name:  "pure insertion",
+			patch: "--- a/m.go\n+++ b/m.go\n@@ -1,0 +2,1 @@\n+// Package main.\n",
+			want:  strings.Replace(file, "package main\n", "package main\n// Package main.\n", 1),
+		},
+		{
+			name: "context mismatch",
+			patch: "--- a/m.go\n+++ b/m.go\n@@ -5,3 +5,3 @@\n func main() {\n" +
+				"-\tfmt.Println(\"goodbye\")\n+\tfmt.Println(\"bye\")\n }\n",
+			reject: `line 6 expected "\tfmt.Println(\"goodbye\")"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			files, err := parsePatch(tt.patch)
+			if err != nil {
+				t.Fatalf("parsePatch: %v", err)
+			}
+			got, results := applyHunks(file, files[0].Hunks)
+			if tt.reject != "" {
+				if results[0].Applied {
+					t.Fatal("hunk applied, want rejection")
+				}
+				if !strings.Contains(results[0].Reason, tt.reject) {
+					t.Errorf("reason = %q, want it to contain %q", results[0].Reason, tt.reject)
+				}
+				return
+			}
+			if !results[0].Applied {
+				t.Fatalf("hunk rejected: %s", results[0].Reason)
+			}
+			if got != tt.want {
+				t.Errorf("content =\n%s\nwant\n%s", got, tt.want)
+			}
+			if results[0].Fuzz != tt.wantFuzz || results[0].Offset != tt.wantShift {
+				t.Errorf("fuzz=%d offset=%d, want fuzz=%d offset=%d",
+					results[0].Fuzz, results[0].Offset, tt.wantFuzz, tt.wantShift)
+			}
+		})
+	}
+}
+
+func TestApplyHunks_MultipleHunksTrackOffset(t *testing.T) {
+	t.Parallel()
+
+	file := "a\nb\nc\nd\ne\nf\ng\n"
+	patch := "--- a/f\n+++ b/f\n" +
+		"@@ -1,2 +1,4 @@\n a\n+a1\n+a2\n b\n" +
+		"@@ -6,2 +8,1 @@\n f\n-g\n"
+	files, err := parsePatch(patch)
+	if err != nil {
+		t.Fatalf("parsePatch: %v", err)
+	}
+	got, results := applyHunks(file, files[0].Hunks)
+	if want := "a\na1\na2\nb\nc\nd\ne\nf\n"; got != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+	if results[1].Line != 8 || results[1].Offset != 0 {
+		t.Errorf("second hunk line=%d offset=%d, want line 8 offset 0", results[1].Line, results[1].Offset)
+	}
+}
@@ -0,0 +1,479 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semstreams/agentic"
+)
+
+// =============================================================================
+// TOOL-CALL POLICY
+// =============================================================================
+// A ToolPolicy is an ordered list of rules evaluated in ToolRegistry.Execute
+// after the tier, skill, danger and store gates. The first rule whose
+// conditions all match decides the call: allow, deny, or require_approval
+// (routed to the DM through a ToolCallApprover). Conditions cover the tool,
+// agent tier and guild, quest difficulty and skills, and the call's
+// arguments: a regex on bash commands, host globs on URLs, path globs on
+// file paths (including every file an apply_patch diff touches), and a
+// regex on any named argument. Every decision is stamped on the result's
+// metadata so it lands in the trajectory.
+// =============================================================================
+
+// PolicyEffect is the outcome of a policy rule.
+type PolicyEffect string
+
+// Policy outcomes.
+const (
+	PolicyAllow           PolicyEffect = "allow"
+	PolicyDeny            PolicyEffect = "deny"
+	PolicyRequireApproval PolicyEffect = "require_approval"
+)
+
+// defaultApprovalTimeout bounds how long a gated call waits for the DM.
+const defaultApprovalTimeout = 5 * time.Minute
+
+// ToolPolicy is an ordered rule set. The zero value allows everything.
+type ToolPolicy struct {
+	// Default applies when no rule matches. Empty means allow.
+	Default PolicyEffect `json:"default,omitempty"`
+	// ApprovalTimeout bounds the wait for a DM decision (Go duration).
+	// Calls still pending when it expires are denied. Default "5m".
+	ApprovalTimeout string       `json:"approval_timeout,omitempty"`
+	Rules           []PolicyRule `json:"rules"`
+
+	compiled bool
+}
+
+// PolicyRule matches tool calls and decides them. Every condition that is
+// set must match; unset conditions match anything.
+type PolicyRule struct {
+	Name   string       `json:"name"`
+	Effect PolicyEffect `json:"effect"`
+	// Reason is shown to the agent on deny and to the DM on approval.
+	Reason string `json:"reason,omitempty"`
+
+	// Tools are tool names or globs ("mcp_*"). Empty matches every tool.
+	Tools []string `json:"tools,omitempty"`
+	// MinTier and MaxTier bound the agent's trust tier, inclusive.
+	MinTier *domain.TrustTier `json:"min_tier,omitempty"`
+	MaxTier *domain.TrustTier `json:"max_tier,omitempty"`
+	// Guilds matches agents in any of these guilds.
+	Guilds []domain.GuildID `json:"guilds,omitempty"`
+	// MinDifficulty and MaxDifficulty bound the quest difficulty, inclusive.
+	MinDifficulty *domain.QuestDifficulty `json:"min_difficulty,omitempty"`
+	MaxDifficulty *domain.QuestDifficulty `json:"max_difficulty,omitempty"`
+	// QuestSkills matches quests requiring any of these skills.
+	QuestSkills []domain.SkillTag `json:"quest_skills,omitempty"`
+
+	// Match holds argument-level conditions.
+	Match PolicyMatch `json:"match,omitzero"`
+}
+
+// PolicyMatch holds argument-level conditions. A condition on an argument
+// the call does not carry does not match.
+type PolicyMatch struct {
+	// Command is a regex searched in the "command" argument (bash).
+	Command string `json:"command,omitempty"`
+	// Hosts are globs matched against the host of the "url" argument,
+	// e.g. "*.internal" or "169.254.*".
+	Hosts []string `json:"hosts,omitempty"`
+	// Paths are globs matched against the "path" argument and the files an
+	// apply_patch diff touches. "*" stays within a directory; "**" spans
+	// directories. Any touched path matching is enough.
+	Paths []string `json:"paths,omitempty"`
+	// Args maps argument names to regexes searched in their string form.
+	Args map[string]string `json:"args,omitempty"`
+
+	command *regexp.Regexp
+	paths   []*regexp.Regexp
+	args    map[string]*regexp.Regexp
+}
+
+// PolicyDecision is the result of evaluating a policy for one call.
+type PolicyDecision struct {
+	Effect PolicyEffect `json:"effect"`
+	Rule   string       `json:"rule,omitempty"` // "" when the default applied
+	Reason string       `json:"reason,omitempty"`
+
+	approvedBy string // set once a require_approval call is approved
+}
+
+// ParseToolPolicy decodes and compiles a JSON policy.
+func ParseToolPolicy(data []byte) (*ToolPolicy, error) {
+	var p ToolPolicy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("decode tool policy: %w", err)
+	}
+	if err := p.Compile(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Compile validates the policy and compiles its patterns. It must be called
+// before Evaluate; SetPolicy and ParseToolPolicy do so.
+func (p *ToolPolicy) Compile() error {
+	if !validEffect(p.Default, true) {
+		return fmt.Errorf("tool policy: invalid default %q", p.Default)
+	}
+	if p.ApprovalTimeout != "" {
+		if d, err := time.ParseDuration(p.ApprovalTimeout); err != nil || d <= 0 {
+			return fmt.Errorf("tool policy: invalid approval_timeout %q", p.ApprovalTimeout)
+		}
+	}
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if !validEffect(rule.Effect, false) {
+			return fmt.Errorf("tool policy rule %s: invalid effect %q", rule.Name, rule.Effect)
+		}
+		for _, t := range rule.Tools {
+			if _, err := path.Match(t, ""); err != nil {
+				return fmt.Errorf("tool policy rule %s: bad tool pattern %q", rule.Name, t)
+			}
+		}
+		for _, h := range rule.Match.Hosts {
+			if _, err := path.Match(h, ""); err != nil {
+				return fmt.Errorf("tool policy rule %s: bad host pattern %q", rule.Name, h)
+			}
+		}
+		if err := rule.Match.compile(); err != nil {
+			return fmt.Errorf("tool policy rule %s: %w", rule.Name, err)
+		}
+	}
+	p.compiled = true
+	return nil
+}
+
+func validEffect(e PolicyEffect, allowEmpty bool) bool {
+	switch e {
+	case PolicyAllow, PolicyDeny, PolicyRequireApproval:
+		return true
+	case "":
+		return allowEmpty
+	}
+	return false
+}
+
+// ApprovalTimeoutDuration returns the approval wait, defaulting to 5m.
+func (p *ToolPolicy) ApprovalTimeoutDuration() time.Duration {
+	if p != nil {
+		if d, err := time.ParseDuration(p.ApprovalTimeout); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultApprovalTimeout
+}
+
+// Evaluate returns the decision of the first matching rule, or the default.
+func (p *ToolPolicy) Evaluate(call agentic.ToolCall, quest *domain.Quest, agent *agentprogression.Agent) PolicyDecision {
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.matches(call, quest, agent) {
+			return PolicyDecision{Effect: rule.Effect, Rule: rule.Name, Reason: rule.Reason}
+		}
+	}
+	effect := p.Default
+	if effect == "" {
+		effect = PolicyAllow
+	}
+	return PolicyDecision{Effect: effect}
+}
+
+func (r *PolicyRule) matches(call agentic.ToolCall, quest *domain.Quest, agent *agentprogression.Agent) bool {
+	if len(r.Tools) > 0 && !matchAnyGlob(r.Tools, call.Name) {
+		return false
+	}
+	if r.MinTier != nil && agent.Tier < *r.MinTier {
+		return false
+	}
+	if r.MaxTier != nil && agent.Tier > *r.MaxTier {
+		return false
+	}
+	if len(r.Guilds) > 0 && !containsGuild(r.Guilds, agent.Guild) {
+		return false
+	}
+	if r.MinDifficulty != nil && (quest == nil || quest.Difficulty < *r.MinDifficulty) {
+		return false
+	}
+	if r.MaxDifficulty != nil && (quest == nil || quest.Difficulty > *r.MaxDifficulty) {
+		return false
+	}
+	if len(r.QuestSkills) > 0 && (quest == nil || !anySkillIn(r.QuestSkills, quest.RequiredSkills)) {
+		return false
+	}
+	return r.Match.matches(call)
+}
+
+func (m *PolicyMatch) compile() error {
+	var err error
+	if m.Command != "" {
+		if m.command, err = regexp.Compile(m.Command); err != nil {
+			return fmt.Errorf("bad command regex: %w", err)
+		}
+	}
+	m.paths = make([]*regexp.Regexp, len(m.Paths))
+	for i, g := range m.Paths {
+		m.paths[i] = globToRegexp(g)
+	}
+	m.args = make(map[string]*regexp.Regexp, len(m.Args))
+	for name, expr := range m.Args {
+		if m.args[name], err = regexp.Compile(expr); err != nil {
+			return fmt.Errorf("bad regex for argument %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (m *PolicyMatch) matches(call agentic.ToolCall) bool {
+	if m.command != nil {
+		cmd, _ := call.Arguments["command"].(string)
+		if cmd == "" || !m.command.MatchString(cmd) {
+			return false
+		}
+	}
+	if len(m.Hosts) > 0 {
+		raw, _ := call.Arguments["url"].(string)
+		u, err := url.Parse(raw)
+		// A trailing dot names the same host, as in SecretScope.AllowsHost.
+		if raw == "" || err != nil || !matchAnyGlob(m.Hosts, strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))) {
+			return false
+		}
+	}
+	if len(m.paths) > 0 && !anyPathMatches(m.paths, callPaths(call)) {
+		return false
+	}
+	for name, re := range m.args {
+		v, ok := call.Arguments[name]
+		if !ok || !re.MatchString(argString(v)) {
+			return false
+		}
+	}
+	return true
+}
+
+// callPaths returns the workspace paths a call names: the "path" argument
+// and every file in an apply_patch diff.
+func callPaths(call agentic.ToolCall) []string {
+	var paths []string
+	if p, ok := call.Arguments["path"].(string); ok && p != "" {
+		paths = append(paths, p)
+	}
+	if text, ok := call.Arguments["patch"].(string); ok && text != "" {
+		if patches, err := parsePatch(text); err == nil {
+			for i := range patches {
+				paths = append(paths, patches[i].Path())
+			}
+		}
+	}
+	for i, p := range paths {
+		paths[i] = strings.TrimPrefix(path.Clean(p), "./")
+	}
+	return paths
+}
+
+// pathFilterKey carries the per-file policy check for search_code and
+// list_files.
+type pathFilterKey struct{}
+
+// withPathFilter lets tools that reach many files through one directory
+// argument (search_code, list_files) hide the files path rules would block.
+// A file is hidden when evaluating the call with its path lands on a
+// different rule than the call itself did, and that rule denies or needs
+// approval. Files the call's own decision covers stay visible.
+func withPathFilter(ctx context.Context, policy *ToolPolicy, call agentic.ToolCall, quest *domain.Quest, agent *agentprogression.Agent, decision PolicyDecision) context.Context {
+	allowed := func(file string) bool {
+		perFile := call
+		perFile.Arguments = maps.Clone(call.Arguments)
+		if perFile.Arguments == nil {
+			perFile.Arguments = make(map[string]any, 1)
+		}
+		perFile.Arguments["path"] = file
+		d := policy.Evaluate(perFile, quest, agent)
+		return d.Effect == PolicyAllow || d.Rule == decision.Rule
+	}
+	return context.WithValue(ctx, pathFilterKey{}, allowed)
+}
+
+// pathAllowed reports whether the tool policy lets the current call show
+// file. It is true when no policy applies.
+func pathAllowed(ctx context.Context, file string) bool {
+	allowed, _ := ctx.Value(pathFilterKey{}).(func(string) bool)
+	return allowed == nil || allowed(file)
+}
+
+func anyPathMatches(globs []*regexp.Regexp, paths []string) bool {
+	for _, p := range paths {
+		for _, g := range globs {
+			if g.MatchString(p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// globToRegexp converts a path glob to an anchored regex: "**" spans
+// directories, "*" and "?" stay within one segment.
+func globToRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			i++
+			if i+1 < len(glob) && glob[i+1] == '/' {
+				i++
+				b.WriteString("(?:.*/)?") // "**/" also matches zero directories
+			} else {
+				b.WriteString(".*")
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+func matchAnyGlob(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsGuild(guilds []domain.GuildID, g domain.GuildID) bool {
+	if g == "" {
+		return false
+	}
+	for _, id := range guilds {
+		if id == g {
+			return true
+		}
+	}
+	return false
+}
+
+func anySkillIn(want, have []domain.SkillTag) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if w == h {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func argString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// =============================================================================
+// APPROVAL
+// =============================================================================
+
+// ToolCallApprover asks the DM to decide a require_approval call. It blocks
+// until the DM responds or ctx ends. The *dmapproval.Component satisfies it
+// once the caller fills in the session ID.
+type ToolCallApprover interface {
+	RequestApproval(ctx context.Context, req domain.ApprovalRequest) (*domain.ApprovalResponse, error)
+}
+
+// requestToolApproval asks the approver about a call and reports whether it
+// may run. The second return value is the reason shown on denial.
+func requestToolApproval(ctx context.Context, approver ToolCallApprover, timeout time.Duration, call agentic.ToolCall, quest *domain.Quest, agent *agentprogression.Agent, decision PolicyDecision) (*domain.ApprovalResponse, string) {
+	if approver == nil {
+		return nil, "no approver is configured"
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := make(map[string]any, len(call.Arguments))
+	for k, v := range call.Arguments {
+		if !strings.HasPrefix(k, "_") {
+			args[k] = v
+		}
+	}
+	argJSON, _ := json.MarshalIndent(args, "", "  ")
+	details := fmt.Sprintf("Policy rule %s requires approval", decision.Rule)
+	if decision.Reason != "" {
+		details += ": " + decision.Reason
+	}
+	details += fmt.Sprintf("\n\nArguments:\n%s", argJSON)
+
+	var questID string
+	if quest != nil {
+		questID = string(quest.ID)
+	}
+	resp, err := approver.RequestApproval(ctx, domain.ApprovalRequest{
+		Type:    domain.ApprovalToolCall,
+		Title:   fmt.Sprintf("Agent %s wants to call %s", agent.ID, call.Name),
+		Details: details,
+		Payload: map[string]any{"tool": call.Name, "arguments": args, "rule": decision.Rule},
+		Options: []domain.ApprovalOption{
+			{ID: "approve", Label: "Approve"},
+			{ID: "deny", Label: "Deny", IsDefault: true},
+		},
+		Metadata: map[string]string{
+			"tool":     call.Name,
+			"rule":     decision.Rule,
+			"agent_id": string(agent.ID),
+			"quest_id": questID,
+			"call_id":  call.ID,
+		},
+	})
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return nil, fmt.Sprintf("no DM decision within %s", timeout)
+	case err != nil:
+		return nil, fmt.Sprintf("approval request failed: %v", err)
+	case !resp.Approved:
+		reason := "denied by DM"
+		if resp.Reason != "" {
+			reason += ": " + resp.Reason
+		}
+		return resp, reason
+	}
+	return resp, ""
+}
+
+// stampPolicyDecision records a decision on a result's metadata.
+func stampPolicyDecision(result *agentic.ToolResult, d PolicyDecision) {
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]any)
+	}
+	result.Metadata["policy_effect"] = string(d.Effect)
+	if d.Rule != "" {
+		result.Metadata["policy_rule"] = d.Rule
+	}
+	if d.approvedBy != "" {
+		result.Metadata["policy_approved"] = true
+		result.Metadata["policy_approved_by"] = d.approvedBy
+	}
+}
@@ -0,0 +1,368 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semstreams/agentic"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func mustParsePolicy(t *testing.T, doc string) *ToolPolicy {
+	t.Helper()
+	p, err := ParseToolPolicy([]byte(doc))
+	if err != nil {
+		t.Fatalf("ParseToolPolicy: %v", err)
+	}
+	return p
+}
+
+func TestToolPolicy_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	policy := mustParsePolicy(t, `{
+		"default": "allow",
+		"rules": [
+			{"name": "no-force-push", "effect": "deny", "tools": ["bash"],
+			 "match": {"command": "git\\s+push\\s+.*--force"}},
+			{"name": "no-metadata", "effect": "deny", "tools": ["http_request"],
+			 "match": {"hosts": ["169.254.*", "*.internal"]}},
+			{"name": "ci-files", "effect": "require_approval",
+			 "match": {"paths": [".github/**", "**/*.secret"]}},
+			{"name": "mcp-writes", "effect": "deny", "tools": ["mcp_*"],
+			 "match": {"args": {"mode": "^write$"}}},
+			{"name": "apprentice-network", "effect": "deny", "tools": ["http_request"], "max_tier": 0},
+			{"name": "ops-hard", "effect": "require_approval", "tools": ["bash"],
+			 "guilds": ["guild-ops"], "min_difficulty": 3},
+			{"name": "security-quests", "effect": "deny", "tools": ["web_search"],
+			 "quest_skills": ["security"]}
+		]
+	}`)
+
+	expert := &agentprogression.Agent{ID: "a1", Tier: domain.TierExpert}
+	apprentice := &agentprogression.Agent{ID: "a2", Tier: domain.TierApprentice}
+	opsAgent := &agentprogression.Agent{ID: "a3", Tier: domain.TierExpert, Guild: "guild-ops"}
+	quest := &domain.Quest{ID: "q1", Difficulty: domain.DifficultyModerate}
+	hardQuest := &domain.Quest{ID: "q2", Difficulty: domain.DifficultyHard}
+	secQuest := &domain.Quest{ID: "q3", RequiredSkills: []domain.SkillTag{"security"}}
+
+	tests := []struct {
+		name   string
+		call   agentic.ToolCall
+		quest  *domain.Quest
+		agent  *agentprogression.Agent
+		effect PolicyEffect
+		rule   string
+	}{
+		{"command regex", makeToolCall("bash", map[string]any{"command": "git push origin main --force"}), quest, expert, PolicyDeny, "no-force-push"},
+		{"command regex miss", makeToolCall("bash", map[string]any{"command": "git push origin main"}), quest, expert, PolicyAllow, ""},
+		{"host glob", makeToolCall("http_request", map[string]any{"url": "http://169.254.169.254/latest"}), quest, expert, PolicyDeny, "no-metadata"},
+		{"host glob subdomain", makeToolCall("http_request", map[string]any{"url": "https://API.corp.internal/x"}), quest, expert, PolicyDeny, "no-metadata"},
+		{"host trailing dot", makeToolCall("http_request", map[string]any{"url": "https://api.corp.internal./x"}), quest, expert, PolicyDeny, "no-metadata"},
+		{"host glob miss", makeToolCall("http_request", map[string]any{"url": "https://example.com"}), quest, expert, PolicyAllow, ""},
+		{"path doublestar", makeToolCall("write_file", map[string]any{"path": "./.github/workflows/ci.yml"}), quest, expert, PolicyRequireApproval, "ci-files"},
+		{"path doublestar at root", makeToolCall("read_file", map[string]any{"path": "db.secret"}), quest, expert, PolicyRequireApproval, "ci-files"},
+		{"path miss", makeToolCall("write_file", map[string]any{"path": "src/github.go"}), quest, expert, PolicyAllow, ""},
+		{"apply_patch files", makeToolCall("apply_patch", map[string]any{
+			"patch": "--- a/main.go\n+++ b/main.go\n@@ -1,1 +1,1 @@\n-a\n+b\n" +
+				"--- /dev/null\n+++ b/.github/CODEOWNERS\n@@ -0,0 +1 @@\n+* @me\n",
+		}), quest, expert, PolicyRequireApproval, "ci-files"},
+		{"tool glob and args", makeToolCall("mcp_docs_edit", map[string]any{"mode": "write"}), quest, expert, PolicyDeny, "mcp-writes"},
+		{"args miss", makeToolCall("mcp_docs_edit", map[string]any{"mode": "read"}), quest, expert, PolicyAllow, ""},
+		{"args absent", makeToolCall("mcp_docs_edit", nil), quest, expert, PolicyAllow, ""},
+		{"max tier", makeToolCall("http_request", map[string]any{"url": "https://example.com"}), quest, apprentice, PolicyDeny, "apprentice-network"},
+		{"guild and difficulty", makeToolCall("bash", map[string]any{"command": "ls"}), hardQuest, opsAgent, PolicyRequireApproval, "ops-hard"},
+		{"guild but easy quest", makeToolCall("bash", map[string]any{"command": "ls"}), quest, opsAgent, PolicyAllow, ""},
+		{"quest skills", makeToolCall("web_search", map[string]any{"query": "x"}), secQuest, expert, PolicyDeny, "security-quests"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.Evaluate(tt.call, tt.quest, tt.agent)
+			if got.Effect != tt.effect || got.Rule != tt.rule {
+				t.Errorf("Evaluate = %s/%q; want %s/%q", got.Effect, got.Rule, tt.effect, tt.rule)
+			}
+		})
+	}
+}
+
+func TestToolPolicy_DefaultAndFirstMatch(t *testing.T) {
+	t.Parallel()
+
+	policy := mustParsePolicy(t, `{
+		"default": "deny",
+		"rules": [
+			{"name": "reads", "effect": "allow", "tools": ["read_file", "list_directory"]},
+			{"effect": "deny", "tools": ["read_file"]}
+		]
+	}`)
+	agent := &agentprogression.Agent{Tier: domain.TierApprentice}
+
+	if d := policy.Evaluate(makeToolCall("read_file", nil), &domain.Quest{}, agent); d.Effect != PolicyAllow || d.Rule != "reads" {
+		t.Errorf("first matching rule should win, got %+v", d)
+	}
+	if d := policy.Evaluate(makeToolCall("bash", nil), &domain.Quest{}, agent); d.Effect != PolicyDeny || d.Rule != "" {
+		t.Errorf("unmatched call should get the default, got %+v", d)
+	}
+	if policy.Rules[1].Name != "rule-2" {
+		t.Errorf("unnamed rule = %q; want rule-2", policy.Rules[1].Name)
+	}
+	if got := (&ToolPolicy{}).Evaluate(makeToolCall("bash", nil), nil, agent); got.Effect != PolicyAllow {
+		t.Errorf("empty policy = %s; want allow", got.Effect)
+	}
+}
+
+func TestParseToolPolicy_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"bad json", `{"rules": [`, "decode tool policy"},
+		{"bad default", `{"default": "maybe"}`, "invalid default"},
+		{"bad effect", `{"rules": [{"name": "r", "effect": "block"}]}`, "invalid effect"},
+		{"missing effect", `{"rules": [{"name": "r"}]}`, "invalid effect"},
+		{"bad command regex", `{"rules": [{"name": "r", "effect": "deny", "match": {"command": "("}}]}`, "bad command regex"},
+		{"bad arg regex", `{"rules": [{"name": "r", "effect": "deny", "match": {"args": {"q": "["}}}]}`, "argument q"},
+		{"bad tool glob", `{"rules": [{"name": "r", "effect": "deny", "tools": ["["]}]}`, "bad tool pattern"},
+		{"bad timeout", `{"approval_timeout": "soon"}`, "approval_timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseToolPolicy([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseToolPolicy error = %v; want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// fakeApprover records requests and answers with a fixed response.
+type fakeApprover struct {
+	resp *domain.ApprovalResponse
+	err  error
+	reqs []domain.ApprovalRequest
+}
+
+func (f *fakeApprover) RequestApproval(_ context.Context, req domain.ApprovalRequest) (*domain.ApprovalResponse, error) {
+	f.reqs = append(f.reqs, req)
+	return f.resp, f.err
+}
+
+func newPolicyTestRegistry(t *testing.T, doc string) *ToolRegistry {
+	t.Helper()
+	reg := NewToolRegistry()
+	reg.Register(RegisteredTool{
+		Definition: agentic.ToolDefinition{Name: "bash"},
+		Handler: func(_ context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
+			return agentic.ToolResult{CallID: call.ID, Content: "ran"}
+		},
+	})
+	if err := reg.SetPolicy(mustParsePolicy(t, doc)); err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+	return reg
+}
+
+func TestToolRegistry_ExecutePolicy(t *testing.T) {
+	t.Parallel()
+
+	const doc = `{
+		"approval_timeout": "1s",
+		"rules": [
+			{"name": "no-rm", "effect": "deny", "reason": "destructive", "match": {"command": "rm -rf"}},
+			{"name": "deploys", "effect": "require_approval", "reason": "touches prod", "match": {"command": "^deploy"}}
+		]
+	}`
+	agent := &agentprogression.Agent{ID: "agent-1", Tier: domain.TierExpert}
+	quest := &domain.Quest{ID: "quest-1"}
+	bash := func(cmd string) agentic.ToolCall {
+		return makeToolCall("bash", map[string]any{"command": cmd, "_sandbox_dir": "/tmp/ws"})
+	}
+
+	t.Run("allowed calls are stamped", func(t *testing.T) {
+		reg := newPolicyTestRegistry(t, doc)
+		res := reg.Execute(context.Background(), bash("ls"), quest, agent)
+		if res.Error != "" || res.Content != "ran" {
+			t.Fatalf("allowed call failed: %+v", res)
+		}
+		if res.Metadata["policy_effect"] != "allow" {
+			t.Errorf("policy_effect = %v; want allow", res.Metadata["policy_effect"])
+		}
+	})
+
+	t.Run("deny blocks the handler", func(t *testing.T) {
+		reg := newPolicyTestRegistry(t, doc)
+		res := reg.Execute(context.Background(), bash("rm -rf /"), quest, agent)
+		if res.Content == "ran" {
+			t.Fatal("denied call reached the handler")
+		}
+		assertContains(t, res.Error, "denied by policy rule no-rm: destructive")
+		if res.Metadata["policy_effect"] != "deny" || res.Metadata["policy_rule"] != "no-rm" {
+			t.Errorf("metadata = %v", res.Metadata)
+		}
+	})
+
+	t.Run("approved call runs and records the approver", func(t *testing.T) {
+		reg := newPolicyTestRegistry(t, doc)
+		approver := &fakeApprover{resp: &domain.ApprovalResponse{Approved: true, RespondedBy: "dm-alice"}}
+		reg.SetApprover(approver)
+
+		res := reg.Execute(context.Background(), bash("deploy api"), quest, agent)
+		if res.Error != "" || res.Content != "ran" {
+			t.Fatalf("approved call failed: %+v", res)
+		}
+		if res.Metadata["policy_approved"] != true || res.Metadata["policy_approved_by"] != "dm-alice" {
+			t.Errorf("metadata = %v", res.Metadata)
+		}
+		if len(approver.reqs) != 1 {
+			t.Fatalf("approver called %d times; want 1", len(approver.reqs))
+		}
+		req := approver.reqs[0]
+		if req.Type != domain.ApprovalToolCall || req.Metadata["rule"] != "deploys" || req.Metadata["quest_id"] != "quest-1" {
+			t.Errorf("request = %+v", req)
+		}
+		if strings.Contains(req.Details, "_sandbox_dir") {
+			t.Error("internal arguments leaked into the approval request")
+		}
+	})
+
+	t.Run("DM denial blocks the call", func(t *testing.T) {
+		reg := newPolicyTestRegistry(t, doc)
+		reg.SetApprover(&fakeApprover{resp: &domain.ApprovalResponse{Approved: false, Reason: "not today"}})
+
+		res := reg.Execute(context.Background(), bash("deploy api"), quest, agent)
+		if res.Content == "ran" {
+			t.Fatal("denied call reached the handler")
+		}
+		assertContains(t, res.Error, "denied by DM: not today")
+		if res.Metadata["policy_approved"] != false {
+			t.Errorf("policy_approved = %v; want false", res.Metadata["policy_approved"])
+		}
+	})
+
+	t.Run("approval timeout denies", func(t *testing.T) {
+		reg := newPolicyTestRegistry(t, doc)
+		reg.SetApprover(&fakeApprover{err: context.DeadlineExceeded})
+
+		res := reg.Execute(context.Background(), bash("deploy api"), quest, agent)
+		assertContains(t, res.Error, "no DM decision within 1s")
+	})
+
+	t.Run("approval error denies", func(t *testing.T) {
+		reg := newPolicyTestRegistry(t, doc)
+		reg.SetApprover(&fakeApprover{err: errors.New("dmapproval component not available")})
+
+		res := reg.Execute(context.Background(), bash("deploy api"), quest, agent)
+		assertContains(t, res.Error, "dmapproval component not available")
+	})
+
+	t.Run("no approver denies", func(t *testing.T) {
+		reg := newPolicyTestRegistry(t, doc)
+		res := reg.Execute(context.Background(), bash("deploy api"), quest, agent)
+		assertContains(t, res.Error, "no approver is configured")
+	})
+
+	t.Run("removing the policy allows everything", func(t *testing.T) {
+		reg := newPolicyTestRegistry(t, doc)
+		if err := reg.SetPolicy(nil); err != nil {
+			t.Fatal(err)
+		}
+		res := reg.Execute(context.Background(), bash("rm -rf /"), quest, agent)
+		if res.Content != "ran" {
+			t.Errorf("call without policy failed: %+v", res)
+		}
+		if _, ok := res.Metadata["policy_effect"]; ok {
+			t.Error("result stamped without a policy")
+		}
+	})
+}
+
+// fakePolicyEntry is a minimal jetstream.KeyValueEntry.
+type fakePolicyEntry struct {
+	value []byte
+	op    jetstream.KeyValueOp
+}
+
+func (e fakePolicyEntry) Bucket() string                  { return PolicyBucket }
+func (e fakePolicyEntry) Key() string                     { return PolicyKey }
+func (e fakePolicyEntry) Value() []byte                   { return e.value }
+func (e fakePolicyEntry) Revision() uint64                { return 1 }
+func (e fakePolicyEntry) Created() time.Time              { return time.Time{} }
+func (e fakePolicyEntry) Delta() uint64                   { return 0 }
+func (e fakePolicyEntry) Operation() jetstream.KeyValueOp { return e.op }
+
+func TestToolRegistry_PathRulesFilterSearchAndList(t *testing.T) {
+	t.Parallel()
+
+	reg, _ := newFileToolRegistry(t, map[string]string{
+		"src/main.go":       "token := load()\n",
+		"secrets/key":       "token=hunter2\n",
+		"secrets/deploy-1-": "token=again\n",
+	})
+	if err := reg.SetPolicy(mustParsePolicy(t, `{
+		"rules": [{"name": "no-secrets", "effect": "deny", "match": {"paths": ["secrets/**"]}}]
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	agent := &agentprogression.Agent{Tier: domain.TierExpert}
+
+	res := reg.Execute(context.Background(), makeToolCall("read_file", map[string]any{"path": "secrets/key"}), &domain.Quest{}, agent)
+	if res.Error == "" {
+		t.Fatal("read_file on a denied path succeeded")
+	}
+
+	res = reg.Execute(context.Background(), makeToolCall("search_code", map[string]any{"pattern": "token", "path": "."}), &domain.Quest{}, agent)
+	if res.Error != "" || !strings.Contains(res.Content, "src/main.go:1:") {
+		t.Fatalf("search_code = %+v; want the allowed match", res)
+	}
+	if strings.Contains(res.Content, "hunter2") || strings.Contains(res.Content, "again") {
+		t.Errorf("search_code leaked a denied file:\n%s", res.Content)
+	}
+
+	res = reg.Execute(context.Background(), makeToolCall("list_files", map[string]any{"path": ".", "recursive": true}), &domain.Quest{}, agent)
+	if res.Error != "" || !strings.Contains(res.Content, "src/main.go") {
+		t.Fatalf("list_files = %+v; want the allowed file", res)
+	}
+	if strings.Contains(res.Content, "secrets/key") || strings.Contains(res.Content, "secrets/deploy") {
+		t.Errorf("list_files listed a denied file:\n%s", res.Content)
+	}
+}
+
+func TestPolicyWatcher_Apply(t *testing.T) {
+	t.Parallel()
+
+	fallback := mustParsePolicy(t, `{"rules": [{"name": "fallback", "effect": "deny", "tools": ["bash"]}]}`)
+	reg := NewToolRegistry()
+	if err := reg.SetPolicy(fallback); err != nil {
+		t.Fatal(err)
+	}
+	w := NewPolicyWatcher(nil, reg, fallback, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	agent := &agentprogression.Agent{Tier: domain.TierExpert}
+	ruleFor := func(tool string) string {
+		return reg.EvaluatePolicy(makeToolCall(tool, nil), &domain.Quest{}, agent).Rule
+	}
+
+	w.apply(fakePolicyEntry{value: []byte(`{"rules": [{"name": "live", "effect": "deny", "tools": ["web_search"]}]}`), op: jetstream.KeyValuePut})
+	if ruleFor("web_search") != "live" || ruleFor("bash") != "" {
+		t.Errorf("KV policy not installed: web_search=%q bash=%q", ruleFor("web_search"), ruleFor("bash"))
+	}
+
+	w.apply(fakePolicyEntry{value: []byte(`{"rules": [{"effect": "nope"}]}`), op: jetstream.KeyValuePut})
+	if ruleFor("web_search") != "live" {
+		t.Error("invalid KV policy replaced the policy in force")
+	}
+
+	w.apply(fakePolicyEntry{op: jetstream.KeyValueDelete})
+	if ruleFor("bash") != "fallback" || ruleFor("web_search") != "" {
+		t.Error("deleting the KV policy did not restore the configured one")
+	}
+}
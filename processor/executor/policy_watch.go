@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/c360studio/semstreams/natsclient"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// PolicyBucket is the KV bucket holding the live tool-call policy.
+const PolicyBucket = "TOOL_POLICY"
+
+// PolicyKey is the key of the policy document in PolicyBucket.
+const PolicyKey = "policy"
+
+// EnsurePolicyBucket creates the TOOL_POLICY KV bucket if it doesn't exist.
+func EnsurePolicyBucket(ctx context.Context, nats *natsclient.Client) (jetstream.KeyValue, error) {
+	return nats.CreateKeyValueBucket(ctx, jetstream.KeyValueConfig{
+		Bucket:      PolicyBucket,
+		Description: "Tool-call policy rules",
+		History:     10,
+	})
+}
+
+// PolicyWatcher keeps a registry's policy in sync with the TOOL_POLICY
+// bucket. A policy stored in KV replaces the configured one; deleting the
+// key restores it. A document that does not compile is logged and ignored,
+// so a bad edit never drops the policy in force.
+type PolicyWatcher struct {
+	bucket   jetstream.KeyValue
+	registry *ToolRegistry
+	fallback *ToolPolicy
+	logger   *slog.Logger
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewPolicyWatcher creates a watcher. fallback is the configured policy
+// (nil for none) used whenever KV holds no policy.
+func NewPolicyWatcher(bucket jetstream.KeyValue, registry *ToolRegistry, fallback *ToolPolicy, logger *slog.Logger) *PolicyWatcher {
+	return &PolicyWatcher{
+		bucket:   bucket,
+		registry: registry,
+		fallback: fallback,
+		logger:   logger,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start loads the stored policy synchronously, then watches for changes.
+func (w *PolicyWatcher) Start(ctx context.Context) error {
+	entry, err := w.bucket.Get(ctx, PolicyKey)
+	switch {
+	case errors.Is(err, jetstream.ErrKeyNotFound):
+	case err != nil:
+		return fmt.Errorf("load tool policy: %w", err)
+	default:
+		w.apply(entry)
+	}
+
+	watcher, err := w.bucket.Watch(ctx, PolicyKey, jetstream.UpdatesOnly())
+	if err != nil {
+		return fmt.Errorf("watch tool policy: %w", err)
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer watcher.Stop() //nolint:errcheck // best-effort teardown
+		w.watchLoop(watcher.Updates())
+	}()
+	return nil
+}
+
+// Stop tears down the watcher goroutine.
+func (w *PolicyWatcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stopChan) })
+	w.wg.Wait()
+}
+
+func (w *PolicyWatcher) watchLoop(updates <-chan jetstream.KeyValueEntry) {
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case entry, ok := <-updates:
+			if !ok {
+				return
+			}
+			if entry != nil {
+				w.apply(entry)
+			}
+		}
+	}
+}
+
+// apply installs the policy from a KV entry.
+func (w *PolicyWatcher) apply(entry jetstream.KeyValueEntry) {
+	if op := entry.Operation(); op == jetstream.KeyValueDelete || op == jetstream.KeyValuePurge {
+		_ = w.registry.SetPolicy(w.fallback) // compiled at startup
+		w.logger.Info("tool policy removed from KV, using configured policy")
+		return
+	}
+	policy, err := ParseToolPolicy(entry.Value())
+	if err != nil {
+		w.logger.Warn("ignoring invalid tool policy from KV", "revision", entry.Revision(), "error", err)
+		return
+	}
+	_ = w.registry.SetPolicy(policy) // already compiled
+	w.logger.Info("tool policy loaded from KV", "revision", entry.Revision(), "rules", len(policy.Rules))
+}
@@ -0,0 +1,345 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semstreams/agentic"
+)
+
+// =============================================================================
+// TOOL QUOTAS AND USAGE
+// =============================================================================
+// ToolLimits cap how often a tool may be called: a per-agent rate over a
+// rolling minute, a per-agent quota over a rolling hour, and a per-quest
+// total. Limits come from the tool's registration and can be overridden per
+// tool (or tool glob) in config, with per-tier replacements. A call over a
+// limit fails with a tool error that tells the agent what ran out, so a
+// runaway loop stops instead of spinning. Every executed call is counted —
+// calls, errors, latency, bytes returned and cost — and priced calls are
+// charged to the CostRecorder (the shared token ledger).
+// =============================================================================
+
+// ToolLimits caps calls to one tool. Zero fields are unlimited.
+type ToolLimits struct {
+	// PerMinute caps one agent's calls in any rolling minute.
+	PerMinute int `json:"per_minute,omitempty"`
+	// PerHour caps one agent's calls in any rolling hour.
+	PerHour int `json:"per_hour,omitempty"`
+	// PerQuest caps calls made for one quest, across all agents.
+	PerQuest int `json:"per_quest,omitempty"`
+	// CostUSD is charged to the token ledger for every executed call.
+	CostUSD float64 `json:"cost_usd,omitempty"`
+	// Tiers replaces the caps above for agents at a trust tier, keyed by
+	// tier name ("apprentice" ... "grandmaster"). CostUSD still applies.
+	Tiers map[string]TierLimits `json:"tiers,omitempty"`
+}
+
+// TierLimits are the caps for one trust tier. Zero fields are unlimited.
+type TierLimits struct {
+	PerMinute int `json:"per_minute,omitempty"`
+	PerHour   int `json:"per_hour,omitempty"`
+	PerQuest  int `json:"per_quest,omitempty"`
+}
+
+// forTier returns the caps that apply to an agent at tier.
+func (l ToolLimits) forTier(tier domain.TrustTier) TierLimits {
+	if t, ok := l.Tiers[tier.String()]; ok {
+		return t
+	}
+	return TierLimits{PerMinute: l.PerMinute, PerHour: l.PerHour, PerQuest: l.PerQuest}
+}
+
+// CostRecorder receives the cost of priced tool calls.
+// Implemented by tokenbudget.TokenLedger.
+type CostRecorder interface {
+	RecordCost(ctx context.Context, costUSD float64, source string)
+}
+
+// ToolUsageStats is one tool's usage since the registry started.
+type ToolUsageStats struct {
+	Tool         string  `json:"tool"`
+	Calls        int64   `json:"calls"`
+	Errors       int64   `json:"errors"`
+	ErrorRate    float64 `json:"error_rate"`
+	Throttled    int64   `json:"throttled"` // calls refused by a rate limit or quota
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	Bytes        int64   `json:"bytes"` // result content returned to agents
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// toolCounters accumulates usage for one tool.
+type toolCounters struct {
+	calls, errors, throttled, bytes int64
+	latency                         time.Duration
+	costUSD                         float64
+}
+
+type agentToolKey struct {
+	tool  string
+	agent domain.AgentID
+}
+
+type questToolKey struct {
+	tool  string
+	quest domain.QuestID
+}
+
+// toolUsage tracks quota windows and usage counters for a registry.
+type toolUsage struct {
+	mu       sync.Mutex
+	recent   map[agentToolKey][]time.Time // call times within the last hour
+	perQuest map[questToolKey]int
+	counters map[string]*toolCounters
+	now      func() time.Time
+}
+
+func newToolUsage() *toolUsage {
+	return &toolUsage{
+		recent:   make(map[agentToolKey][]time.Time),
+		perQuest: make(map[questToolKey]int),
+		counters: make(map[string]*toolCounters),
+		now:      time.Now,
+	}
+}
+
+// admit reserves a call slot and returns its time, or returns a refusal
+// result when a cap is reached. Refused calls do not use up any quota.
+func (u *toolUsage) admit(call agentic.ToolCall, limits TierLimits, quest *domain.Quest, agent *agentprogression.Agent) (time.Time, *agentic.ToolResult) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := u.now()
+	akey := agentToolKey{tool: call.Name, agent: agent.ID}
+	times := pruneBefore(u.recent[akey], now.Add(-time.Hour))
+	u.recent[akey] = times
+
+	var qkey questToolKey
+	if quest != nil {
+		qkey = questToolKey{tool: call.Name, quest: quest.ID}
+	}
+
+	var msg, limit string
+	switch {
+	case limits.PerMinute > 0 && countSince(times, now.Add(-time.Minute)) >= limits.PerMinute:
+		wait := oldestSince(times, now.Add(-time.Minute)).Add(time.Minute).Sub(now)
+		msg = fmt.Sprintf("tool %s rate limit reached: %d calls per minute; wait %s before calling it again",
+			call.Name, limits.PerMinute, wait.Round(time.Second))
+		limit = "per_minute"
+	case limits.PerHour > 0 && len(times) >= limits.PerHour:
+		wait := times[0].Add(time.Hour).Sub(now)
+		msg = fmt.Sprintf("tool %s hourly quota exhausted: %d calls per hour; it is available again in %s, so continue without it",
+			call.Name, limits.PerHour, wait.Round(time.Minute))
+		limit = "per_hour"
+	case quest != nil && limits.PerQuest > 0 && u.perQuest[qkey] >= limits.PerQuest:
+		msg = fmt.Sprintf("tool %s quota exhausted for this quest: all %d calls used; continue with what you have",
+			call.Name, limits.PerQuest)
+		limit = "per_quest"
+	}
+	if msg != "" {
+		u.counter(call.Name).throttled++
+		return time.Time{}, &agentic.ToolResult{
+			CallID:   call.ID,
+			Error:    msg,
+			Metadata: map[string]any{"quota_exceeded": limit},
+		}
+	}
+
+	u.recent[akey] = append(times, now)
+	if quest != nil {
+		u.perQuest[qkey]++
+	}
+	return now, nil
+}
+
+// refund gives back a slot admit reserved at for a call that never ran,
+// such as one the policy denied or the DM did not approve.
+func (u *toolUsage) refund(call agentic.ToolCall, quest *domain.Quest, agent *agentprogression.Agent, at time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	akey := agentToolKey{tool: call.Name, agent: agent.ID}
+	times := u.recent[akey]
+	for i := len(times) - 1; i >= 0; i-- {
+		if times[i].Equal(at) {
+			u.recent[akey] = append(times[:i:i], times[i+1:]...)
+			break
+		}
+	}
+	if len(u.recent[akey]) == 0 {
+		delete(u.recent, akey)
+	}
+
+	if quest != nil {
+		qkey := questToolKey{tool: call.Name, quest: quest.ID}
+		if u.perQuest[qkey] <= 1 {
+			delete(u.perQuest, qkey)
+		} else {
+			u.perQuest[qkey]--
+		}
+	}
+}
+
+// forgetQuest drops the per-quest counts for a quest that has finished.
+func (u *toolUsage) forgetQuest(questID domain.QuestID) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for key := range u.perQuest {
+		if key.quest == questID {
+			delete(u.perQuest, key)
+		}
+	}
+}
+
+// record counts an executed call.
+func (u *toolUsage) record(tool string, result agentic.ToolResult, latency time.Duration, costUSD float64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	c := u.counter(tool)
+	c.calls++
+	if result.Error != "" {
+		c.errors++
+	}
+	c.bytes += int64(len(result.Content))
+	c.latency += latency
+	c.costUSD += costUSD
+}
+
+// counter returns the counters for tool, creating them. Callers hold mu.
+func (u *toolUsage) counter(tool string) *toolCounters {
+	c, ok := u.counters[tool]
+	if !ok {
+		c = &toolCounters{}
+		u.counters[tool] = c
+	}
+	return c
+}
+
+// snapshot returns usage for every tool that has been called, by name.
+func (u *toolUsage) snapshot() []ToolUsageStats {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	stats := make([]ToolUsageStats, 0, len(u.counters))
+	for tool, c := range u.counters {
+		s := ToolUsageStats{
+			Tool:      tool,
+			Calls:     c.calls,
+			Errors:    c.errors,
+			Throttled: c.throttled,
+			Bytes:     c.bytes,
+			CostUSD:   c.costUSD,
+		}
+		if c.calls > 0 {
+			s.ErrorRate = float64(c.errors) / float64(c.calls)
+			s.AvgLatencyMs = float64(c.latency) / float64(time.Millisecond) / float64(c.calls)
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Tool < stats[j].Tool })
+	return stats
+}
+
+// MergeToolUsage combines usage reported by several registries, summing
+// counters for tools that appear in more than one.
+func MergeToolUsage(sets ...[]ToolUsageStats) []ToolUsageStats {
+	byTool := make(map[string]*ToolUsageStats)
+	latencyMs := make(map[string]float64)
+	for _, set := range sets {
+		for _, s := range set {
+			m, ok := byTool[s.Tool]
+			if !ok {
+				m = &ToolUsageStats{Tool: s.Tool}
+				byTool[s.Tool] = m
+			}
+			m.Calls += s.Calls
+			m.Errors += s.Errors
+			m.Throttled += s.Throttled
+			m.Bytes += s.Bytes
+			m.CostUSD += s.CostUSD
+			latencyMs[s.Tool] += s.AvgLatencyMs * float64(s.Calls)
+		}
+	}
+	merged := make([]ToolUsageStats, 0, len(byTool))
+	for tool, m := range byTool {
+		if m.Calls > 0 {
+			m.ErrorRate = float64(m.Errors) / float64(m.Calls)
+			m.AvgLatencyMs = latencyMs[tool] / float64(m.Calls)
+		}
+		merged = append(merged, *m)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Tool < merged[j].Tool })
+	return merged
+}
+
+// pruneBefore drops times before cutoff. times is in ascending order.
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := sort.Search(len(times), func(i int) bool { return !times[i].Before(cutoff) })
+	return times[i:]
+}
+
+func countSince(times []time.Time, cutoff time.Time) int {
+	return len(pruneBefore(times, cutoff))
+}
+
+func oldestSince(times []time.Time, cutoff time.Time) time.Time {
+	return pruneBefore(times, cutoff)[0]
+}
+
+// lookupLimits picks the limits for a tool: an exact config entry, then the
+// most specific matching glob, then the tool's registered defaults.
+func lookupLimits(configured map[string]ToolLimits, tool RegisteredTool) (ToolLimits, bool) {
+	name := tool.Definition.Name
+	if l, ok := configured[name]; ok {
+		return l, true
+	}
+	best := ""
+	for pattern := range configured {
+		if ok, _ := path.Match(pattern, name); ok && (len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best)) {
+			best = pattern
+		}
+	}
+	if best != "" {
+		return configured[best], true
+	}
+	if tool.Limits != nil {
+		return *tool.Limits, true
+	}
+	return ToolLimits{}, false
+}
+
+// ValidateToolLimits reports configured limits with unknown tier names or
+// negative caps.
+func ValidateToolLimits(limits map[string]ToolLimits) error {
+	for tool, l := range limits {
+		if _, err := path.Match(tool, ""); err != nil {
+			return fmt.Errorf("tool_limits %q: bad tool pattern: %w", tool, err)
+		}
+		if l.PerMinute < 0 || l.PerHour < 0 || l.PerQuest < 0 || l.CostUSD < 0 {
+			return fmt.Errorf("tool_limits %q: limits and cost must not be negative", tool)
+		}
+		for tier, t := range l.Tiers {
+			if !validTierName(tier) {
+				return fmt.Errorf("tool_limits %q: unknown tier %q", tool, tier)
+			}
+			if t.PerMinute < 0 || t.PerHour < 0 || t.PerQuest < 0 {
+				return fmt.Errorf("tool_limits %q tier %s: limits must not be negative", tool, tier)
+			}
+		}
+	}
+	return nil
+}
+
+func validTierName(name string) bool {
+	for t := domain.TierApprentice; t <= domain.TierGrandmaster; t++ {
+		if t.String() == name {
+			return true
+		}
+	}
+	return false
+}
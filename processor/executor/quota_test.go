@@ -0,0 +1,278 @@
+package executor
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semstreams/agentic"
+)
+
+type fakeCostRecorder struct {
+	total   float64
+	sources []string
+}
+
+func (f *fakeCostRecorder) RecordCost(_ context.Context, costUSD float64, source string) {
+	f.total += costUSD
+	f.sources = append(f.sources, source)
+}
+
+// newQuotaTestRegistry registers a "search" tool with the given default
+// limits and a clock the test controls.
+func newQuotaTestRegistry(limits *ToolLimits) (*ToolRegistry, *time.Time) {
+	reg := NewToolRegistry()
+	reg.Register(RegisteredTool{
+		Definition: agentic.ToolDefinition{Name: "search"},
+		Handler: func(_ context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
+			if call.Arguments["fail"] == true {
+				return agentic.ToolResult{CallID: call.ID, Error: "boom"}
+			}
+			return agentic.ToolResult{CallID: call.ID, Content: "results"}
+		},
+		Limits: limits,
+	})
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	reg.usage.now = func() time.Time { return now }
+	return reg, &now
+}
+
+func TestToolRegistry_RateLimits(t *testing.T) {
+	t.Parallel()
+
+	agent := &agentprogression.Agent{ID: "agent-1", Tier: domain.TierJourneyman}
+	other := &agentprogression.Agent{ID: "agent-2", Tier: domain.TierJourneyman}
+	quest := &domain.Quest{ID: "quest-1"}
+	call := makeToolCall("search", nil)
+
+	t.Run("per minute", func(t *testing.T) {
+		reg, now := newQuotaTestRegistry(&ToolLimits{PerMinute: 2})
+		for i := range 2 {
+			if res := reg.Execute(context.Background(), call, quest, agent); res.Error != "" {
+				t.Fatalf("call %d refused: %s", i, res.Error)
+			}
+		}
+		res := reg.Execute(context.Background(), call, quest, agent)
+		if !strings.Contains(res.Error, "rate limit reached") || !strings.Contains(res.Error, "wait 1m0s") {
+			t.Errorf("third call error = %q", res.Error)
+		}
+		if res.Metadata["quota_exceeded"] != "per_minute" {
+			t.Errorf("quota_exceeded = %v", res.Metadata["quota_exceeded"])
+		}
+		if res := reg.Execute(context.Background(), call, quest, other); res.Error != "" {
+			t.Errorf("limit should be per agent, other agent got %q", res.Error)
+		}
+		*now = now.Add(61 * time.Second)
+		if res := reg.Execute(context.Background(), call, quest, agent); res.Error != "" {
+			t.Errorf("call after window refused: %s", res.Error)
+		}
+	})
+
+	t.Run("per hour", func(t *testing.T) {
+		reg, now := newQuotaTestRegistry(&ToolLimits{PerHour: 2})
+		reg.Execute(context.Background(), call, quest, agent)
+		*now = now.Add(30 * time.Minute)
+		reg.Execute(context.Background(), call, quest, agent)
+		res := reg.Execute(context.Background(), call, quest, agent)
+		if !strings.Contains(res.Error, "hourly quota exhausted") || !strings.Contains(res.Error, "30m0s") {
+			t.Errorf("error = %q", res.Error)
+		}
+		*now = now.Add(31 * time.Minute)
+		if res := reg.Execute(context.Background(), call, quest, agent); res.Error != "" {
+			t.Errorf("call after oldest expired refused: %s", res.Error)
+		}
+	})
+
+	t.Run("per quest across agents", func(t *testing.T) {
+		reg, _ := newQuotaTestRegistry(&ToolLimits{PerQuest: 2})
+		reg.Execute(context.Background(), call, quest, agent)
+		reg.Execute(context.Background(), call, quest, other)
+		res := reg.Execute(context.Background(), call, quest, agent)
+		if !strings.Contains(res.Error, "quota exhausted for this quest") {
+			t.Errorf("error = %q", res.Error)
+		}
+		if res := reg.Execute(context.Background(), call, &domain.Quest{ID: "quest-2"}, agent); res.Error != "" {
+			t.Errorf("other quest refused: %s", res.Error)
+		}
+	})
+
+	t.Run("tier replaces caps", func(t *testing.T) {
+		reg, _ := newQuotaTestRegistry(&ToolLimits{
+			PerMinute: 1,
+			Tiers:     map[string]TierLimits{"master": {}},
+		})
+		master := &agentprogression.Agent{ID: "agent-3", Tier: domain.TierMaster}
+		for i := range 3 {
+			if res := reg.Execute(context.Background(), call, quest, master); res.Error != "" {
+				t.Fatalf("master call %d refused: %s", i, res.Error)
+			}
+		}
+		reg.Execute(context.Background(), call, quest, agent)
+		if res := reg.Execute(context.Background(), call, quest, agent); res.Error == "" {
+			t.Error("journeyman should keep the base limit")
+		}
+	})
+}
+
+func TestToolRegistry_BlockedCallsRefundQuota(t *testing.T) {
+	t.Parallel()
+
+	agent := &agentprogression.Agent{ID: "agent-1", Tier: domain.TierJourneyman}
+	quest := &domain.Quest{ID: "quest-1"}
+	reg, _ := newQuotaTestRegistry(&ToolLimits{PerMinute: 1, PerQuest: 1})
+	if err := reg.SetPolicy(mustParsePolicy(t, `{
+		"approval_timeout": "1s",
+		"rules": [
+			{"name": "no-secrets", "effect": "deny", "match": {"args": {"query": "secret"}}},
+			{"name": "ask", "effect": "require_approval", "match": {"args": {"query": "ask"}}}
+		]
+	}`)); err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+	reg.SetApprover(&fakeApprover{resp: &domain.ApprovalResponse{Approved: false, Reason: "no"}})
+
+	for _, query := range []string{"secret", "ask"} {
+		res := reg.Execute(context.Background(), makeToolCall("search", map[string]any{"query": query}), quest, agent)
+		if res.Error == "" || res.Metadata["quota_exceeded"] != nil {
+			t.Fatalf("%s call: want a policy refusal, got %+v", query, res)
+		}
+	}
+	if res := reg.Execute(context.Background(), makeToolCall("search", map[string]any{"query": "ok"}), quest, agent); res.Error != "" {
+		t.Errorf("blocked calls used up the quota: %s", res.Error)
+	}
+}
+
+func TestToolRegistry_ForgetQuest(t *testing.T) {
+	t.Parallel()
+
+	agent := &agentprogression.Agent{ID: "agent-1", Tier: domain.TierJourneyman}
+	quest := &domain.Quest{ID: "quest-1"}
+	reg, _ := newQuotaTestRegistry(&ToolLimits{PerQuest: 1})
+	reg.Execute(context.Background(), makeToolCall("search", nil), quest, agent)
+	reg.Execute(context.Background(), makeToolCall("search", nil), &domain.Quest{ID: "quest-2"}, agent)
+
+	reg.ForgetQuest(quest.ID)
+	if n := len(reg.usage.perQuest); n != 1 {
+		t.Errorf("per-quest entries after ForgetQuest = %d, want 1", n)
+	}
+	if res := reg.Execute(context.Background(), makeToolCall("search", nil), &domain.Quest{ID: "quest-2"}, agent); res.Error == "" {
+		t.Error("other quest's count should be kept")
+	}
+}
+
+func TestToolRegistry_ConfiguredLimitsOverrideDefaults(t *testing.T) {
+	t.Parallel()
+
+	agent := &agentprogression.Agent{ID: "agent-1", Tier: domain.TierJourneyman}
+	call := makeToolCall("search", nil)
+
+	reg, _ := newQuotaTestRegistry(&ToolLimits{PerMinute: 1})
+	if err := reg.SetToolLimits(map[string]ToolLimits{
+		"*":      {PerMinute: 5},
+		"sear*":  {PerMinute: 3},
+		"search": {PerMinute: 2},
+	}); err != nil {
+		t.Fatalf("SetToolLimits: %v", err)
+	}
+	refusedAt := 0
+	for i := 1; i <= 5; i++ {
+		if res := reg.Execute(context.Background(), call, nil, agent); res.Error != "" {
+			refusedAt = i
+			break
+		}
+	}
+	if refusedAt != 3 {
+		t.Errorf("exact entry should apply: refused at call %d, want 3", refusedAt)
+	}
+
+	limits, ok := lookupLimits(map[string]ToolLimits{"*": {PerMinute: 5}, "sear*": {PerMinute: 3}}, *reg.Get("search"))
+	if !ok || limits.PerMinute != 3 {
+		t.Errorf("most specific glob should apply, got %+v", limits)
+	}
+	limits, ok = lookupLimits(nil, *reg.Get("search"))
+	if !ok || limits.PerMinute != 1 {
+		t.Errorf("registered default should apply, got %+v", limits)
+	}
+}
+
+func TestToolRegistry_UsageAndCost(t *testing.T) {
+	t.Parallel()
+
+	agent := &agentprogression.Agent{ID: "agent-1", Tier: domain.TierJourneyman}
+	reg, _ := newQuotaTestRegistry(&ToolLimits{PerQuest: 3, CostUSD: 0.005})
+	costs := &fakeCostRecorder{}
+	reg.SetCostRecorder(costs)
+
+	quest := &domain.Quest{ID: "quest-1"}
+	reg.Execute(context.Background(), makeToolCall("search", nil), quest, agent)
+	reg.Execute(context.Background(), makeToolCall("search", nil), quest, agent)
+	reg.Execute(context.Background(), makeToolCall("search", map[string]any{"fail": true}), quest, agent)
+	reg.Execute(context.Background(), makeToolCall("search", nil), quest, agent) // throttled
+
+	usage := reg.Usage()
+	if len(usage) != 1 {
+		t.Fatalf("usage = %+v", usage)
+	}
+	u := usage[0]
+	if u.Tool != "search" || u.Calls != 3 || u.Errors != 1 || u.Throttled != 1 || u.Bytes != int64(2*len("results")) {
+		t.Errorf("counters = %+v", u)
+	}
+	if math.Abs(u.ErrorRate-1.0/3) > 1e-9 {
+		t.Errorf("error rate = %f", u.ErrorRate)
+	}
+	if math.Abs(u.CostUSD-0.015) > 1e-9 || math.Abs(costs.total-0.015) > 1e-9 {
+		t.Errorf("cost: usage %f, recorded %f; want 0.015", u.CostUSD, costs.total)
+	}
+	if len(costs.sources) == 0 || costs.sources[0] != "tool:search" {
+		t.Errorf("cost sources = %v", costs.sources)
+	}
+}
+
+func TestMergeToolUsage(t *testing.T) {
+	t.Parallel()
+
+	merged := MergeToolUsage(
+		[]ToolUsageStats{{Tool: "web_search", Calls: 2, Errors: 1, AvgLatencyMs: 100, Bytes: 10, CostUSD: 0.01}},
+		[]ToolUsageStats{{Tool: "web_search", Calls: 2, AvgLatencyMs: 300, Bytes: 5}, {Tool: "bash", Calls: 1}},
+	)
+	if len(merged) != 2 || merged[0].Tool != "bash" {
+		t.Fatalf("merged = %+v", merged)
+	}
+	ws := merged[1]
+	if ws.Calls != 4 || ws.Errors != 1 || ws.Bytes != 15 || ws.ErrorRate != 0.25 || ws.AvgLatencyMs != 200 {
+		t.Errorf("web_search = %+v", ws)
+	}
+}
+
+func TestValidateToolLimits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		limits  map[string]ToolLimits
+		wantErr string
+	}{
+		{"valid", map[string]ToolLimits{"mcp_*": {PerMinute: 5, Tiers: map[string]TierLimits{"expert": {PerMinute: 20}}}}, ""},
+		{"negative", map[string]ToolLimits{"bash": {PerQuest: -1}}, "must not be negative"},
+		{"unknown tier", map[string]ToolLimits{"bash": {Tiers: map[string]TierLimits{"wizard": {}}}}, "unknown tier"},
+		{"bad glob", map[string]ToolLimits{"[": {}}, "bad tool pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateToolLimits(tt.limits)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v; want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
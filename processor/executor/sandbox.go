@@ -4,16 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/c360studio/semdragons/domain"
 	"github.com/c360studio/semdragons/processor/agentprogression"
 	"github.com/c360studio/semdragons/processor/executor/httpformat"
+	"github.com/c360studio/semdragons/processor/executor/testreport"
 	"github.com/c360studio/semstreams/agentic"
 )
 
@@ -152,7 +156,7 @@ func (c *SandboxClient) doJSON(ctx context.Context, method, path string, body, o
 
 	if resp.StatusCode >= 300 {
 		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return fmt.Errorf("sandbox returned %d: %s", resp.StatusCode, string(errBody))
+		return &sandboxStatusError{StatusCode: resp.StatusCode, Body: string(errBody)}
 	}
 
 	if out != nil {
@@ -166,6 +170,17 @@ func (c *SandboxClient) doJSON(ctx context.Context, method, path string, body, o
 	return nil
 }
 
+// sandboxStatusError is returned by doJSON when the sandbox answers with a
+// non-2xx status, so callers can tell a missing file from a failed request.
+type sandboxStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *sandboxStatusError) Error() string {
+	return fmt.Sprintf("sandbox returned %d: %s", e.StatusCode, e.Body)
+}
+
 // =============================================================================
 // Sandbox API request/response types
 // These mirror the types in cmd/sandbox/server.go but are defined locally to
@@ -173,14 +188,40 @@ func (c *SandboxClient) doJSON(ctx context.Context, method, path string, body, o
 // =============================================================================
 
 type sandboxReadFileResp struct {
+	Content   string `json:"content"`
+	Size      int    `json:"size"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+type sandboxWriteFileReq struct {
+	QuestID string `json:"quest_id"`
+	Path    string `json:"path"`
 	Content string `json:"content"`
-	Size    int    `json:"size"`
+}
+
+type sandboxListReq struct {
+	QuestID string `json:"quest_id"`
+	Path    string `json:"path"`
+}
+
+type sandboxSearchReq struct {
+	QuestID      string `json:"quest_id"`
+	Pattern      string `json:"pattern"`
+	Path         string `json:"path"`
+	FileGlob     string `json:"file_glob,omitempty"`
+	ContextLines int    `json:"context_lines,omitempty"`
+}
+
+type sandboxSearchResp struct {
+	Output string `json:"output"`
 }
 
 type sandboxExecReq struct {
-	QuestID   string `json:"quest_id"`
-	Command   string `json:"command"`
-	TimeoutMS int    `json:"timeout_ms"`
+	QuestID   string            `json:"quest_id"`
+	Command   string            `json:"command"`
+	TimeoutMS int               `json:"timeout_ms"`
+	Env       map[string]string `json:"env,omitempty"`
+	Redact    []string          `json:"redact,omitempty"` // secret values the sandbox masks in its output
 }
 
 type sandboxExecResp struct {
@@ -221,12 +262,103 @@ func (c *SandboxClient) ListWorkspaceFiles(ctx context.Context, questID string)
 
 // ReadFile reads a single file from the workspace. Returns the raw content bytes.
 func (c *SandboxClient) ReadFile(ctx context.Context, questID, path string) ([]byte, error) {
+	resp, err := c.readFile(ctx, questID, path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(resp.Content), nil
+}
+
+// readFile is ReadFile with the full response, including whether the
+// sandbox truncated the content.
+func (c *SandboxClient) readFile(ctx context.Context, questID, path string) (*sandboxReadFileResp, error) {
 	fileURL := "/file?" + url.Values{"quest_id": {questID}, "path": {path}}.Encode()
 	var resp sandboxReadFileResp
 	if err := c.doJSON(ctx, http.MethodGet, fileURL, nil, &resp); err != nil {
 		return nil, fmt.Errorf("read file %s: %w", path, err)
 	}
-	return []byte(resp.Content), nil
+	return &resp, nil
+}
+
+// WriteFile creates or overwrites a file in the workspace, creating parent
+// directories as needed.
+func (c *SandboxClient) WriteFile(ctx context.Context, questID, path, content string) error {
+	req := sandboxWriteFileReq{QuestID: questID, Path: path, Content: content}
+	if err := c.doJSON(ctx, http.MethodPut, "/file", req, nil); err != nil {
+		return fmt.Errorf("write file %s: %w", path, err)
+	}
+	return nil
+}
+
+// ListDir lists one directory level of the workspace.
+func (c *SandboxClient) ListDir(ctx context.Context, questID, path string) ([]sandboxDirEntry, error) {
+	var resp sandboxListResp
+	if err := c.doJSON(ctx, http.MethodPost, "/list", sandboxListReq{QuestID: questID, Path: path}, &resp); err != nil {
+		return nil, fmt.Errorf("list %s: %w", path, err)
+	}
+	return resp.Entries, nil
+}
+
+// Search runs a recursive grep in the workspace and returns its
+// "path:line:text" output.
+func (c *SandboxClient) Search(ctx context.Context, questID string, q codeSearch) (string, error) {
+	req := sandboxSearchReq{
+		QuestID:      questID,
+		Pattern:      q.Pattern,
+		Path:         q.Path,
+		FileGlob:     q.FileGlob,
+		ContextLines: q.ContextLines,
+	}
+	var resp sandboxSearchResp
+	if err := c.doJSON(ctx, http.MethodPost, "/search", req, &resp); err != nil {
+		return "", fmt.Errorf("search: %w", err)
+	}
+	return resp.Output, nil
+}
+
+// TestRunRequest selects the tests RunTests runs. An empty Framework is
+// detected from the workspace; a zero Timeout uses the sandbox maximum.
+type TestRunRequest struct {
+	Framework testreport.Framework
+	Options   testreport.Options
+	Timeout   time.Duration
+}
+
+type sandboxTestReq struct {
+	Framework string `json:"framework,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Run       string `json:"run,omitempty"`
+	TimeoutMs int    `json:"timeout_ms,omitempty"`
+}
+
+// RunTests runs the quest workspace's tests and returns the parsed report.
+// The sandbox keeps it as the quest's latest report (see TestReport).
+func (c *SandboxClient) RunTests(ctx context.Context, questID string, req TestRunRequest) (*testreport.Report, error) {
+	body := sandboxTestReq{
+		Framework: string(req.Framework),
+		Target:    req.Options.Target,
+		Run:       req.Options.Run,
+		TimeoutMs: int(req.Timeout / time.Millisecond),
+	}
+	var report testreport.Report
+	if err := c.doJSON(ctx, http.MethodPost, fmt.Sprintf("/workspace/%s/test", questID), body, &report); err != nil {
+		return nil, fmt.Errorf("run tests: %w", err)
+	}
+	return &report, nil
+}
+
+// TestReport returns the quest's latest test report. Returns (nil, nil)
+// when no tests have been run in the workspace.
+func (c *SandboxClient) TestReport(ctx context.Context, questID string) (*testreport.Report, error) {
+	var report testreport.Report
+	if err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/workspace/%s/test-report", questID), nil, &report); err != nil {
+		var statusErr *sandboxStatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get test report: %w", err)
+	}
+	return &report, nil
 }
 
 // WorkspaceFileEntry describes a file in a sandbox workspace.
@@ -261,8 +393,8 @@ func questIDFromCall(call agentic.ToolCall) (string, bool) {
 // identical to the builtin versions so the agent sees no change.
 // =============================================================================
 
-// RegisterSandboxTools registers sandbox-proxied handlers for execution and
-// HTTP tools. Only call this after RegisterBuiltins so that terminal and
+// RegisterSandboxTools registers sandbox-proxied handlers for execution,
+// HTTP and file tools. Only call this after RegisterBuiltins so that terminal and
 // DAG tool registrations are preserved.
 func (r *ToolRegistry) RegisterSandboxTools(client *SandboxClient) {
 	r.Register(RegisteredTool{
@@ -271,6 +403,7 @@ func (r *ToolRegistry) RegisterSandboxTools(client *SandboxClient) {
 		Skills:     runCommandSpec.Skills,
 		MinTier:    runCommandSpec.MinTier,
 		Category:   runCommandSpec.Category,
+		SecretArgs: runCommandSpec.SecretArgs,
 	})
 
 	r.Register(RegisteredTool{
@@ -279,7 +412,12 @@ func (r *ToolRegistry) RegisterSandboxTools(client *SandboxClient) {
 		Skills:     httpRequestSpec.Skills,
 		MinTier:    httpRequestSpec.MinTier,
 		Category:   httpRequestSpec.Category,
+		SecretArgs: httpRequestSpec.SecretArgs,
+		Limits:     httpRequestSpec.Limits,
 	})
+
+	r.registerFileTools(sandboxWorkspaceFor(client))
+	r.registerTestTool(sandboxTestRunner(client))
 }
 
 // makeSandboxExecHandler builds a handler that proxies a shell command to the
@@ -315,10 +453,17 @@ func makeSandboxExecHandler(
 			}
 		}
 
+		env, err := commandEnv(call)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+		}
+
 		req := sandboxExecReq{
 			QuestID:   questID,
 			Command:   command,
 			TimeoutMS: int(timeout.Milliseconds()),
+			Env:       env,
+			Redact:    secretValuesFrom(ctx).list(),
 		}
 		var resp sandboxExecResp
 		if err := client.doJSON(ctx, http.MethodPost, "/exec", req, &resp); err != nil {
@@ -416,6 +561,18 @@ func makeSandboxHTTPRequestHandler(client *SandboxClient) ToolHandler {
 		formatArg, _ := call.Arguments["format"].(string)
 		format := httpformat.ParseFormat(formatArg)
 
+		headers, err := requestHeaders(call)
+		if err != nil {
+			return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+		}
+
+		// Fresh cached pages skip the sandbox entirely. curl does not report
+		// validators, so stale entries are refetched rather than revalidated.
+		pc := lookupPageCache(ctx, method, urlStr, string(format), headers)
+		if pc.fresh() {
+			return pc.hit(call)
+		}
+
 		// Build a curl command that mimics the local httpRequestHandler.
 		// -s: silent, -S: show errors, -L: follow redirects,
 		// -m: timeout matching httpRequestTimeout,
@@ -447,8 +604,26 @@ func makeSandboxHTTPRequestHandler(client *SandboxClient) ToolHandler {
 		const curlSentinel = "\n__SEMDRAGONS_CURL_META__\n"
 		writeOut := curlSentinel + `%{http_code}` + "\n" + `%{content_type}`
 
+		cmdParts = append(cmdParts, "-H", "User-Agent: semdragons-agent/1.0")
+
+		// Extra headers travel as environment variables so their values
+		// (often secrets) never appear in the command line the sandbox logs.
+		var env map[string]string
+		if len(headers) > 0 {
+			names := make([]string, 0, len(headers))
+			for name := range headers {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			env = make(map[string]string, len(names))
+			for i, name := range names {
+				envName := fmt.Sprintf("SEMDRAGONS_HEADER_%d", i)
+				env[envName] = name + ": " + headers[name]
+				cmdParts = append(cmdParts, "-H", `"$`+envName+`"`)
+			}
+		}
+
 		cmdParts = append(cmdParts,
-			"-H", "User-Agent: semdragons-agent/1.0",
 			"-w", fmt.Sprintf("%q", writeOut),
 			fmt.Sprintf("%q", urlStr),
 		)
@@ -459,6 +634,8 @@ func makeSandboxHTTPRequestHandler(client *SandboxClient) ToolHandler {
 			QuestID:   questID,
 			Command:   command,
 			TimeoutMS: int(httpRequestTimeout.Milliseconds()) + 5000, // small buffer over curl's own timeout
+			Env:       env,
+			Redact:    secretValuesFrom(ctx).list(),
 		}
 		var resp sandboxExecResp
 		if err := client.doJSON(ctx, http.MethodPost, "/exec", req, &resp); err != nil {
@@ -499,9 +676,12 @@ func makeSandboxHTTPRequestHandler(client *SandboxClient) ToolHandler {
 		// HTML. Non-HTML responses pass through with a length cap only.
 		rendered := httpformat.Render([]byte(body), contentType, urlStr, format, httpTextMaxSize)
 
-		return agentic.ToolResult{
+		result := agentic.ToolResult{
 			CallID:  call.ID,
 			Content: fmt.Sprintf("HTTP %s\n\n%s", statusCode, rendered),
 		}
+		status, _ := strconv.Atoi(statusCode)
+		pc.storeResponse(ctx, status, nil, result.Content, "", &result)
+		return result
 	}
 }
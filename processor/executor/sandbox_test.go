@@ -47,6 +47,10 @@ func newSandboxTestServer(t *testing.T) (*httptest.Server, *executor.SandboxClie
 		if path == "patchable.go" {
 			content = "package main\n\nfunc old() {}\n"
 		}
+		if path == "missing.go" {
+			http.Error(w, `{"error":"file not found"}`, http.StatusNotFound)
+			return
+		}
 
 		json.NewEncoder(w).Encode(map[string]any{
 			"content": content,
@@ -140,6 +144,29 @@ func newSandboxTestServer(t *testing.T) (*httptest.Server, *executor.SandboxClie
 		})
 	})
 
+	// POST /workspace/{id}/test — return a canned failing report
+	mux.HandleFunc("POST /workspace/{id}/test", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Framework string `json:"framework"`
+			Run       string `json:"run"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error":"bad json"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"framework": "go",
+			"exit_code": 1,
+			"passed":    3,
+			"failed":    1,
+			"skipped":   0,
+			"failures": []map[string]any{{
+				"name": "TestAdd", "suite": "example.com/m", "message": "got 3, want 4 (run=" + req.Run + ")",
+				"file": "math_test.go", "line": 12,
+			}},
+		})
+	})
+
 	srv := httptest.NewServer(mux)
 	t.Cleanup(srv.Close)
 
@@ -288,3 +315,87 @@ func TestSandboxRunCommand_ServerError(t *testing.T) {
 	}
 }
 
+
+// =============================================================================
+// Sandbox file tool tests
+// =============================================================================
+
+func TestSandboxReadFile(t *testing.T) {
+	_, client := newSandboxTestServer(t)
+	reg := executor.NewToolRegistry()
+	reg.RegisterSandboxTools(client)
+
+	call := testCall("read_file", map[string]any{"path": "multi-line.go", "start_line": float64(5), "end_line": float64(6)})
+	result := reg.Execute(context.Background(), call, testQuest, testAgent(domain.TierApprentice))
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if !strings.Contains(result.Content, "     5\tfunc main() {") || strings.Contains(result.Content, "import") {
+		t.Errorf("unexpected content:\n%s", result.Content)
+	}
+}
+
+func TestSandboxApplyPatch(t *testing.T) {
+	_, client := newSandboxTestServer(t)
+	reg := executor.NewToolRegistry()
+	reg.RegisterSandboxTools(client)
+
+	patch := "--- a/patchable.go\n+++ b/patchable.go\n@@ -3 +3 @@\n-func old() {}\n+func updated() {}\n"
+	result := reg.Execute(context.Background(), testCall("apply_patch", map[string]any{"patch": patch}),
+		testQuest, testAgent(domain.TierApprentice))
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s\n%s", result.Error, result.Content)
+	}
+
+	// A 404 from the sandbox is reported as a missing file, not a request failure.
+	patch = "--- a/missing.go\n+++ b/missing.go\n@@ -1 +1 @@\n-a\n+b\n"
+	result = reg.Execute(context.Background(), testCall("apply_patch", map[string]any{"patch": patch}),
+		testQuest, testAgent(domain.TierApprentice))
+	if !strings.Contains(result.Content, "missing.go: REJECTED — file not found") {
+		t.Errorf("unexpected report:\n%s", result.Content)
+	}
+}
+
+func TestSandboxSearchAndList(t *testing.T) {
+	_, client := newSandboxTestServer(t)
+	reg := executor.NewToolRegistry()
+	reg.RegisterSandboxTools(client)
+	agent := testAgent(domain.TierApprentice)
+
+	result := reg.Execute(context.Background(), testCall("search_code", map[string]any{"pattern": "main"}), testQuest, agent)
+	if result.Error != "" || result.Metadata["matches"] != 2 {
+		t.Errorf("search_code: error=%q metadata=%v", result.Error, result.Metadata)
+	}
+
+	result = reg.Execute(context.Background(), testCall("list_files", map[string]any{"path": "src", "recursive": true}), testQuest, agent)
+	if result.Error != "" {
+		t.Fatalf("list_files: %s", result.Error)
+	}
+	if !strings.Contains(result.Content, "src/lib_test.go (150 bytes)") || strings.Contains(result.Content, "README.md") {
+		t.Errorf("unexpected listing:\n%s", result.Content)
+	}
+}
+
+func TestSandboxRunTests(t *testing.T) {
+	_, client := newSandboxTestServer(t)
+	reg := executor.NewToolRegistry()
+	reg.RegisterSandboxTools(client)
+
+	call := testCall("run_tests", map[string]any{"run": "TestAdd"})
+	result := reg.Execute(context.Background(), call, testQuest, testAgent(domain.TierJourneyman))
+	if !strings.Contains(result.Error, "go: 3 passed, 1 failed, 0 skipped") {
+		t.Errorf("unexpected error: %q", result.Error)
+	}
+	if !strings.Contains(result.Content, "FAIL example.com/m TestAdd (math_test.go:12)") ||
+		!strings.Contains(result.Content, "got 3, want 4 (run=TestAdd)") {
+		t.Errorf("unexpected content:\n%s", result.Content)
+	}
+	if result.Metadata["failed"] != 1 {
+		t.Errorf("metadata failed = %v, want 1", result.Metadata["failed"])
+	}
+
+	result = reg.Execute(context.Background(), call, testQuest, testAgent(domain.TierApprentice))
+	if result.Error == "" {
+		t.Error("apprentice ran run_tests, want tier rejection")
+	}
+}
@@ -0,0 +1,228 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// FAN-OUT SEARCH
+// =============================================================================
+// The multi provider queries several providers at once, interleaves their
+// results round-robin in config order and drops duplicate URLs, so one
+// provider's blind spots are covered by another. Each member's health is
+// tracked: after three consecutive failures it is skipped for a cooldown
+// that doubles with every further failure, so a dead instance does not slow
+// every search down to its timeout.
+// =============================================================================
+
+// Fan-out health thresholds.
+const (
+	searchUnhealthyAfter = 3
+	searchRetryBase      = time.Minute
+	searchRetryMax       = 15 * time.Minute
+)
+
+// SearchProviderHealth reports one fan-out member's recent behavior.
+type SearchProviderHealth struct {
+	Provider            string     `json:"provider"`
+	Healthy             bool       `json:"healthy"`
+	Successes           int64      `json:"successes"`
+	Failures            int64      `json:"failures"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastLatencyMs       int64      `json:"last_latency_ms"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"` // set while the provider is skipped
+}
+
+// providerHealth tracks one member. Guarded by fanOutSearchProvider.mu.
+type providerHealth struct {
+	successes, failures int64
+	consecutive         int
+	lastErr             string
+	lastLatency         time.Duration
+	retryAt             time.Time
+}
+
+// fanOutSearchProvider queries several providers and merges their results.
+type fanOutSearchProvider struct {
+	providers []SearchProvider
+	name      string
+
+	mu     sync.Mutex
+	health []providerHealth // parallel to providers
+	now    func() time.Time
+}
+
+func newFanOutSearchProvider(configs []SearchConfig) (*fanOutSearchProvider, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("search provider multi requires providers")
+	}
+	f := &fanOutSearchProvider{now: time.Now}
+	names := make([]string, 0, len(configs))
+	for i, cfg := range configs {
+		if cfg.Provider == "multi" {
+			return nil, fmt.Errorf("search providers[%d]: multi providers cannot be nested", i)
+		}
+		p, err := NewSearchProvider(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("search providers[%d]: %w", i, err)
+		}
+		f.providers = append(f.providers, p)
+		names = append(names, p.Name())
+	}
+	f.name = strings.Join(names, "+")
+	f.health = make([]providerHealth, len(f.providers))
+	return f, nil
+}
+
+func (f *fanOutSearchProvider) Name() string { return f.name }
+
+func (f *fanOutSearchProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	maxResults = searchLimit(maxResults)
+	active := f.activeProviders()
+
+	lists := make([][]SearchResult, len(f.providers))
+	errs := make([]error, len(f.providers))
+	var wg sync.WaitGroup
+	for _, i := range active {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := time.Now()
+			lists[i], errs[i] = f.providers[i].Search(ctx, query, maxResults)
+			f.report(i, errs[i], time.Since(start))
+		}(i)
+	}
+	wg.Wait()
+
+	var failed []error
+	for _, i := range active {
+		if errs[i] != nil {
+			failed = append(failed, fmt.Errorf("%s: %w", f.providers[i].Name(), errs[i]))
+		}
+	}
+	if len(failed) == len(active) {
+		return nil, errors.Join(failed...)
+	}
+	return interleaveResults(lists, maxResults), nil
+}
+
+// activeProviders returns the members not cooling down. When every member
+// is cooling down, all of them are tried rather than failing outright.
+func (f *fanOutSearchProvider) activeProviders() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.now()
+	active := make([]int, 0, len(f.providers))
+	for i := range f.providers {
+		if f.health[i].retryAt.IsZero() || !now.Before(f.health[i].retryAt) {
+			active = append(active, i)
+		}
+	}
+	if len(active) == 0 {
+		for i := range f.providers {
+			active = append(active, i)
+		}
+	}
+	return active
+}
+
+// report records the outcome of one member's search.
+func (f *fanOutSearchProvider) report(i int, err error, latency time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h := &f.health[i]
+	h.lastLatency = latency
+	if err == nil {
+		if h.consecutive >= searchUnhealthyAfter {
+			slog.Info("search provider recovered", "provider", f.providers[i].Name())
+		}
+		h.successes++
+		h.consecutive = 0
+		h.retryAt = time.Time{}
+		return
+	}
+
+	h.failures++
+	h.consecutive++
+	h.lastErr = err.Error()
+	if h.consecutive >= searchUnhealthyAfter {
+		backoff := searchRetryBase << min(h.consecutive-searchUnhealthyAfter, 4)
+		h.retryAt = f.now().Add(min(backoff, searchRetryMax))
+		slog.Warn("search provider unhealthy, skipping it",
+			"provider", f.providers[i].Name(), "failures", h.consecutive,
+			"retry_at", h.retryAt, "error", err)
+	}
+}
+
+// Health reports each member's health in config order.
+func (f *fanOutSearchProvider) Health() []SearchProviderHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]SearchProviderHealth, len(f.providers))
+	for i, p := range f.providers {
+		h := f.health[i]
+		out[i] = SearchProviderHealth{
+			Provider:            p.Name(),
+			Healthy:             h.consecutive < searchUnhealthyAfter,
+			Successes:           h.successes,
+			Failures:            h.failures,
+			ConsecutiveFailures: h.consecutive,
+			LastError:           h.lastErr,
+			LastLatencyMs:       h.lastLatency.Milliseconds(),
+		}
+		if !h.retryAt.IsZero() {
+			retryAt := h.retryAt
+			out[i].RetryAt = &retryAt
+		}
+	}
+	return out
+}
+
+// SearchHealth returns member health for a multi provider, or nil for
+// providers that do not fan out.
+func SearchHealth(p SearchProvider) []SearchProviderHealth {
+	if f, ok := p.(*fanOutSearchProvider); ok {
+		return f.Health()
+	}
+	return nil
+}
+
+// interleaveResults merges result lists round-robin, keeping the first
+// occurrence of each URL, up to limit results.
+func interleaveResults(lists [][]SearchResult, limit int) []SearchResult {
+	seen := make(map[string]bool)
+	var merged []SearchResult
+	for rank := 0; len(merged) < limit; rank++ {
+		more := false
+		for _, list := range lists {
+			if rank >= len(list) {
+				continue
+			}
+			more = true
+			r := list[rank]
+			key, ok := normalizeCacheURL(r.URL)
+			if !ok {
+				key = r.URL
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, r)
+			if len(merged) == limit {
+				break
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return merged
+}
@@ -0,0 +1,397 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	semdragons "github.com/c360studio/semdragons"
+	"github.com/c360studio/semdragons/processor/executor/httpformat"
+)
+
+// =============================================================================
+// LOCAL SEARCH INDEX
+// =============================================================================
+// The local search provider answers web_search from an in-memory full-text
+// index, so offline and air-gapped boards still have search. The index holds
+// documents from configured paths (an exported wiki, a docs tree) and web
+// pages persisted to the graph by http_request — both those already in the
+// graph at startup and those fetched while the board runs. Results are
+// ranked with BM25, with title matches weighted above body matches.
+// =============================================================================
+
+// Local index limits.
+const (
+	maxIndexedFileSize = 2 << 20 // larger files are skipped
+	maxIndexedTextLen  = 200_000 // characters of a document's text that are indexed
+	maxIndexedWebPages = 5000    // persisted pages loaded at startup
+	titleTermWeight    = 3
+	bm25K1             = 1.2
+	bm25B              = 0.75
+)
+
+// indexedExtensions are the file types AddPath indexes.
+var indexedExtensions = map[string]bool{
+	".md": true, ".markdown": true, ".txt": true, ".rst": true, ".html": true, ".htm": true,
+}
+
+// indexStopWords are too common to help ranking.
+var indexStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "how": true, "in": true, "is": true, "it": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "this": true, "to": true,
+	"was": true, "what": true, "with": true,
+}
+
+// LocalDocument is one document in a LocalSearchIndex, identified by URL.
+type LocalDocument struct {
+	URL   string
+	Title string
+	Text  string
+}
+
+// indexedDoc is a document plus what is needed to score and remove it.
+type indexedDoc struct {
+	LocalDocument
+	length int      // weighted term count
+	terms  []string // distinct terms, for removal
+}
+
+// LocalSearchIndex is an in-memory full-text index. Safe for concurrent use.
+type LocalSearchIndex struct {
+	mu       sync.RWMutex
+	docs     map[string]*indexedDoc
+	postings map[string]map[string]int // term -> URL -> weighted frequency
+	totalLen int
+}
+
+// NewLocalSearchIndex creates an empty index.
+func NewLocalSearchIndex() *LocalSearchIndex {
+	return &LocalSearchIndex{
+		docs:     make(map[string]*indexedDoc),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+// webContentIndex receives pages persisted by http_request. Nil (the
+// default) means fetched pages are not indexed.
+var webContentIndex *LocalSearchIndex
+
+// SetWebContentIndex makes persisted web pages searchable through index.
+// Pass nil to stop indexing them.
+func SetWebContentIndex(index *LocalSearchIndex) { webContentIndex = index }
+
+// Len returns the number of indexed documents.
+func (x *LocalSearchIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.docs)
+}
+
+// Add indexes doc, replacing any document with the same URL.
+func (x *LocalSearchIndex) Add(doc LocalDocument) {
+	if doc.URL == "" {
+		return
+	}
+	if len(doc.Text) > maxIndexedTextLen {
+		doc.Text = doc.Text[:maxIndexedTextLen]
+	}
+
+	freq := make(map[string]int)
+	length := 0
+	for _, t := range tokenizeForIndex(doc.Title) {
+		freq[t] += titleTermWeight
+		length += titleTermWeight
+	}
+	for _, t := range tokenizeForIndex(doc.Text) {
+		freq[t]++
+		length++
+	}
+	if length == 0 {
+		return
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.removeLocked(doc.URL)
+	d := &indexedDoc{LocalDocument: doc, length: length, terms: make([]string, 0, len(freq))}
+	for term, n := range freq {
+		p, ok := x.postings[term]
+		if !ok {
+			p = make(map[string]int)
+			x.postings[term] = p
+		}
+		p[doc.URL] = n
+		d.terms = append(d.terms, term)
+	}
+	x.docs[doc.URL] = d
+	x.totalLen += length
+}
+
+// removeLocked drops the document at url. Callers hold mu.
+func (x *LocalSearchIndex) removeLocked(url string) {
+	d, ok := x.docs[url]
+	if !ok {
+		return
+	}
+	for _, term := range d.terms {
+		delete(x.postings[term], url)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	x.totalLen -= d.length
+	delete(x.docs, url)
+}
+
+// Search returns up to maxResults documents ranked by BM25 against query.
+func (x *LocalSearchIndex) Search(query string, maxResults int) []SearchResult {
+	terms := uniqueTerms(tokenizeForIndex(query))
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if len(terms) == 0 || len(x.docs) == 0 {
+		return nil
+	}
+
+	n := float64(len(x.docs))
+	avgLen := float64(x.totalLen) / n
+	scores := make(map[string]float64)
+	for _, term := range terms {
+		p := x.postings[term]
+		df := float64(len(p))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for url, tf := range p {
+			dl := float64(x.docs[url].length)
+			f := float64(tf)
+			scores[url] += idf * f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*dl/avgLen))
+		}
+	}
+
+	urls := make([]string, 0, len(scores))
+	for url := range scores {
+		urls = append(urls, url)
+	}
+	sort.Slice(urls, func(i, j int) bool {
+		if scores[urls[i]] != scores[urls[j]] {
+			return scores[urls[i]] > scores[urls[j]]
+		}
+		return urls[i] < urls[j]
+	})
+	if len(urls) > maxResults {
+		urls = urls[:maxResults]
+	}
+
+	results := make([]SearchResult, 0, len(urls))
+	for _, url := range urls {
+		d := x.docs[url]
+		results = append(results, SearchResult{
+			Title:       d.Title,
+			URL:         d.URL,
+			Description: indexSnippet(d.Text, terms),
+		})
+	}
+	return results
+}
+
+// AddPath indexes a file, or every indexable file under a directory, and
+// returns how many documents were added. Hidden directories, unreadable
+// files and files over 2 MiB are skipped.
+func (x *LocalSearchIndex) AddPath(root string) (int, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return 0, err
+	}
+	if !info.IsDir() {
+		if !x.addFile(root) {
+			return 0, fmt.Errorf("%s: not an indexable document", root)
+		}
+		return 1, nil
+	}
+
+	added := 0
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if x.addFile(path) {
+			added++
+		}
+		return nil
+	})
+	return added, err
+}
+
+// addFile indexes one file, reporting whether it was added.
+func (x *LocalSearchIndex) addFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if !indexedExtensions[ext] {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxIndexedFileSize {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+
+	doc := LocalDocument{URL: "file://" + filepath.ToSlash(abs), Text: string(data)}
+	switch ext {
+	case ".html", ".htm":
+		doc.Title = extractTitle(bytes.NewReader(data))
+		doc.Text = httpformat.Render(data, "text/html", doc.URL, httpformat.FormatMarkdown, maxIndexedTextLen)
+	case ".md", ".markdown":
+		doc.Title = markdownTitle(doc.Text)
+	}
+	if doc.Title == "" {
+		doc.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	x.Add(doc)
+	return true
+}
+
+// IndexWebContent loads web pages already persisted to the graph into the
+// index and returns how many were added.
+func (x *LocalSearchIndex) IndexWebContent(ctx context.Context, gc *semdragons.GraphClient) (int, error) {
+	entities, err := gc.ListEntitiesByIDPrefix(ctx, webContentIDPrefix(gc.Config()), maxIndexedWebPages)
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, entity := range entities {
+		var doc LocalDocument
+		for _, t := range entity.Triples {
+			value, _ := t.Object.(string)
+			switch t.Predicate {
+			case "source.doc.url":
+				doc.URL = value
+			case "dc.terms.title":
+				doc.Title = value
+			case "source.doc.content":
+				doc.Text = value
+			}
+		}
+		if doc.URL != "" && doc.Text != "" {
+			x.Add(doc)
+			added++
+		}
+	}
+	return added, nil
+}
+
+// markdownTitle returns the text of the first level-one heading.
+func markdownTitle(text string) string {
+	for _, line := range strings.SplitN(text, "\n", 50) {
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+	return ""
+}
+
+// tokenizeForIndex lowercases s and splits it into words, dropping single
+// characters and stop words.
+func tokenizeForIndex(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) > 1 && !indexStopWords[w] {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	out := terms[:0]
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// indexSnippet returns about 40 words of text around the first query term.
+func indexSnippet(text string, terms []string) string {
+	words := strings.Fields(text)
+	match := 0
+	for i, w := range words {
+		if wordMatches(w, terms) {
+			match = i
+			break
+		}
+	}
+	start := max(0, match-10)
+	end := min(len(words), start+40)
+	snippet := strings.Join(words[start:end], " ")
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(words) {
+		snippet += "..."
+	}
+	return snippet
+}
+
+func wordMatches(word string, terms []string) bool {
+	for _, t := range tokenizeForIndex(word) {
+		for _, term := range terms {
+			if t == term {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// localSearchProvider answers searches from a LocalSearchIndex.
+type localSearchProvider struct {
+	index *LocalSearchIndex
+}
+
+func (l *localSearchProvider) Name() string { return "local" }
+
+func (l *localSearchProvider) Search(_ context.Context, query string, maxResults int) ([]SearchResult, error) {
+	return l.index.Search(query, searchLimit(maxResults)), nil
+}
+
+// LocalSearchIndexOf returns the index behind a local provider, looking
+// inside multi providers, or nil when p does not use one.
+func LocalSearchIndexOf(p SearchProvider) *LocalSearchIndex {
+	switch p := p.(type) {
+	case *localSearchProvider:
+		return p.index
+	case *fanOutSearchProvider:
+		for _, member := range p.providers {
+			if index := LocalSearchIndexOf(member); index != nil {
+				return index
+			}
+		}
+	}
+	return nil
+}
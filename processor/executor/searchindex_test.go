@@ -0,0 +1,89 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalSearchIndex_Ranking(t *testing.T) {
+	t.Parallel()
+
+	index := NewLocalSearchIndex()
+	index.Add(LocalDocument{URL: "wiki://deploy", Title: "Deploying the board",
+		Text: "Run make deploy. The board needs NATS and the graph gateway."})
+	index.Add(LocalDocument{URL: "wiki://nats", Title: "NATS operations",
+		Text: "Streams, KV buckets and how to back up NATS JetStream."})
+	index.Add(LocalDocument{URL: "wiki://misc", Title: "Misc",
+		Text: "Lunch menu and parking."})
+
+	results := index.Search("NATS backup", 5)
+	if len(results) != 2 || results[0].URL != "wiki://nats" {
+		t.Fatalf("results = %+v; want the NATS page first", results)
+	}
+	if !strings.Contains(results[0].Description, "back up NATS") {
+		t.Errorf("snippet = %q", results[0].Description)
+	}
+	if got := index.Search("the and", 5); got != nil {
+		t.Errorf("stop-word query should match nothing, got %+v", got)
+	}
+
+	// Re-adding a URL replaces the document.
+	index.Add(LocalDocument{URL: "wiki://nats", Title: "Moved", Text: "See the ops handbook."})
+	if got := index.Search("jetstream", 5); len(got) != 0 {
+		t.Errorf("stale terms still match: %+v", got)
+	}
+	if index.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", index.Len())
+	}
+}
+
+func TestLocalSearchIndex_AddPath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"guide.md":              "# Onboarding Guide\n\nAsk the quartermaster for access.",
+		"notes/faq.txt":         "The quartermaster handles badges.",
+		"page.html":             "<html><head><title>Quartermaster</title></head><body><p>Office hours are Tuesday.</p></body></html>",
+		"image.png":             "quartermaster",
+		".git/quartermaster.md": "# Hidden",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	index := NewLocalSearchIndex()
+	n, err := index.AddPath(dir)
+	if err != nil || n != 3 {
+		t.Fatalf("AddPath = %d, %v; want 3 documents", n, err)
+	}
+	p, _ := NewSearchProvider(SearchConfig{Provider: "local", IndexPaths: []string{dir}})
+	results, err := p.Search(context.Background(), "quartermaster", 10)
+	if err != nil || len(results) != 3 {
+		t.Fatalf("results = %+v, %v", results, err)
+	}
+	titles := map[string]bool{}
+	for _, r := range results {
+		titles[r.Title] = true
+		if !strings.HasPrefix(r.URL, "file://") {
+			t.Errorf("URL = %q", r.URL)
+		}
+	}
+	for _, want := range []string{"Onboarding Guide", "faq", "Quartermaster"} {
+		if !titles[want] {
+			t.Errorf("missing title %q in %v", want, titles)
+		}
+	}
+	if LocalSearchIndexOf(p) == nil {
+		t.Error("LocalSearchIndexOf should find the provider's index")
+	}
+}
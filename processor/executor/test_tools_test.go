@@ -0,0 +1,42 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+)
+
+func TestRunTestsHandler_Local(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not on PATH")
+	}
+	t.Parallel()
+
+	reg, _ := newFileToolRegistry(t, map[string]string{
+		"go.mod":  "module example.com/m\n\ngo 1.21\n",
+		"math.go": "package m\n\nfunc Add(a, b int) int { return a - b }\n",
+		"math_test.go": "package m\n\nimport \"testing\"\n\n" +
+			"func TestAdd(t *testing.T) {\n\tif got := Add(1, 2); got != 3 {\n\t\tt.Errorf(\"Add(1, 2) = %d, want 3\", got)\n\t}\n}\n\n" +
+			"func TestZero(t *testing.T) {\n\tif Add(0, 0) != 0 {\n\t\tt.Fatal(\"zero\")\n\t}\n}\n",
+	})
+	agent := &agentprogression.Agent{Tier: domain.TierJourneyman}
+
+	result := reg.Execute(context.Background(), makeToolCall("run_tests", map[string]any{}), &domain.Quest{}, agent)
+	assertContains(t, result.Error, "go: 1 passed, 1 failed, 0 skipped")
+	assertContains(t, result.Content, "FAIL example.com/m TestAdd (math_test.go:7)")
+	assertContains(t, result.Content, "Add(1, 2) = -1, want 3")
+
+	result = reg.Execute(context.Background(), makeToolCall("run_tests", map[string]any{"run": "TestZero"}), &domain.Quest{}, agent)
+	if result.Error != "" {
+		t.Fatalf("filtered run failed: %s\n%s", result.Error, result.Content)
+	}
+	if result.Metadata["passed"] != 1 || result.Metadata["failed"] != 0 {
+		t.Errorf("metadata = %v", result.Metadata)
+	}
+
+	result = reg.Execute(context.Background(), makeToolCall("run_tests", map[string]any{"framework": "maven"}), &domain.Quest{}, agent)
+	assertContains(t, result.Error, "unsupported test framework")
+}
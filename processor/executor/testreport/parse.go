@@ -0,0 +1,565 @@
+package testreport
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// go test -json
+// =============================================================================
+
+// goTestEvent is one line of go test -json (test2json) output.
+type goTestEvent struct {
+	Action     string `json:"Action"`
+	Package    string `json:"Package"`
+	Test       string `json:"Test"`
+	Output     string `json:"Output"`
+	ImportPath string `json:"ImportPath"` // build-output / build-fail events
+}
+
+// goFailLocRe matches t.Error output: "    foo_test.go:12: message".
+var goFailLocRe = regexp.MustCompile(`^\s+([\w./\-]+\.go):(\d+): (.*)$`)
+
+// goFrameRe matches a stack frame file line: "\t/path/foo.go:12 +0x1d".
+var goFrameRe = regexp.MustCompile(`^\s+(\S+\.go):(\d+)`)
+
+// goCompileErrRe matches a compiler error: "./foo.go:12:5: undefined: x".
+var goCompileErrRe = regexp.MustCompile(`^(\S+\.go):(\d+):(?:\d+:)? (.*)$`)
+
+func parseGoTestJSON(output string, exitCode int) *Report {
+	r := &Report{}
+
+	type testKey struct{ pkg, test string }
+	testOutput := make(map[testKey]*strings.Builder)
+	pkgOutput := make(map[string]*strings.Builder)
+	buildOutput := make(map[string]*strings.Builder)
+	var failed []testKey
+	failedPkgs := make(map[string]bool)
+	pkgHasFailedTest := make(map[string]bool)
+	var buildFailed []string
+	var stray strings.Builder
+
+	appendTo := func(m map[testKey]*strings.Builder, k testKey, s string) {
+		b := m[k]
+		if b == nil {
+			b = &strings.Builder{}
+			m[k] = b
+		}
+		if b.Len() < 4*MaxFailureOutput {
+			b.WriteString(s)
+		}
+	}
+	appendStr := func(m map[string]*strings.Builder, k, s string) {
+		b := m[k]
+		if b == nil {
+			b = &strings.Builder{}
+			m[k] = b
+		}
+		if b.Len() < 4*MaxFailureOutput {
+			b.WriteString(s)
+		}
+	}
+
+	sc := bufio.NewScanner(strings.NewReader(output))
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		var ev goTestEvent
+		if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &ev) != nil {
+			// Older toolchains print compile errors outside the JSON stream.
+			stray.WriteString(line + "\n")
+			continue
+		}
+		switch ev.Action {
+		case "build-output":
+			appendStr(buildOutput, ev.ImportPath, ev.Output)
+		case "build-fail":
+			buildFailed = append(buildFailed, ev.ImportPath)
+		case "output":
+			if ev.Test != "" {
+				appendTo(testOutput, testKey{ev.Package, ev.Test}, ev.Output)
+			} else {
+				appendStr(pkgOutput, ev.Package, ev.Output)
+			}
+		case "pass":
+			if ev.Test != "" {
+				r.Passed++
+			}
+		case "skip":
+			if ev.Test != "" {
+				r.Skipped++
+			}
+		case "fail":
+			if ev.Test != "" {
+				failed = append(failed, testKey{ev.Package, ev.Test})
+				pkgHasFailedTest[ev.Package] = true
+			} else {
+				failedPkgs[ev.Package] = true
+			}
+		}
+	}
+
+	// A parent test fails whenever a subtest does; report only the leaves.
+	for _, k := range failed {
+		parent := false
+		for _, other := range failed {
+			if other.pkg == k.pkg && strings.HasPrefix(other.test, k.test+"/") {
+				parent = true
+				break
+			}
+		}
+		if parent {
+			continue
+		}
+		r.Failed++
+		out := ""
+		if b := testOutput[k]; b != nil {
+			out = b.String()
+		}
+		f := Failure{Name: k.test, Suite: k.pkg, Output: goTrimTestOutput(out)}
+		f.Message, f.File, f.Line = goFailureDetail(out)
+		r.addFailure(f)
+	}
+
+	for _, pkg := range buildFailed {
+		r.Failed++
+		out := ""
+		if b := buildOutput[pkg]; b != nil {
+			out = b.String()
+		}
+		r.addFailure(goBuildFailure(pkg, out))
+	}
+
+	// Package failures with no failing test: setup failed, TestMain exited,
+	// or the package did not build on a toolchain without build events.
+	for pkg := range failedPkgs {
+		if pkgHasFailedTest[pkg] || containsStr(buildFailed, pkg) {
+			continue
+		}
+		r.Failed++
+		out := ""
+		if b := pkgOutput[pkg]; b != nil {
+			out = b.String()
+		}
+		if strings.Contains(out, "[build failed]") || strings.Contains(out, "[setup failed]") {
+			r.addFailure(goBuildFailure(pkg, out))
+			continue
+		}
+		f := Failure{Name: "(package)", Suite: pkg, Output: out}
+		f.Message, f.File, f.Line = goFailureDetail(out)
+		if f.Message == "" {
+			f.Message = firstLine(out)
+		}
+		r.addFailure(f)
+	}
+
+	if exitCode != 0 && r.Failed == 0 {
+		if s := strings.TrimSpace(stray.String()); s != "" {
+			r.Failed++
+			r.addFailure(goBuildFailure("", s))
+		} else if r.Passed+r.Skipped == 0 {
+			r.setRaw(output)
+		}
+	}
+	return r
+}
+
+// goBuildFailure describes a package that failed to compile.
+func goBuildFailure(pkg, out string) Failure {
+	f := Failure{Name: "(build)", Suite: pkg, Output: out}
+	for _, line := range strings.Split(out, "\n") {
+		if m := goCompileErrRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			f.File = strings.TrimPrefix(m[1], "./")
+			f.Line, _ = strconv.Atoi(m[2])
+			f.Message = m[3]
+			return f
+		}
+	}
+	f.Message = firstLine(out)
+	return f
+}
+
+// goFailureDetail extracts the first assertion message and location from a
+// test's output, falling back to the panic value and first user frame.
+func goFailureDetail(out string) (msg, file string, line int) {
+	lines := strings.Split(out, "\n")
+	for i, l := range lines {
+		m := goFailLocRe.FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+		file, msg = m[1], m[3]
+		line, _ = strconv.Atoi(m[2])
+		// Multi-line t.Errorf messages continue with deeper indentation.
+		lead := len(l) - len(strings.TrimLeft(l, " \t"))
+		for _, next := range lines[i+1:] {
+			if strings.TrimSpace(next) == "" || len(next)-len(strings.TrimLeft(next, " \t")) <= lead {
+				break
+			}
+			msg += "\n" + strings.TrimSpace(next)
+		}
+		return msg, file, line
+	}
+
+	for i, l := range lines {
+		if !strings.HasPrefix(l, "panic: ") {
+			continue
+		}
+		msg = strings.TrimSpace(l)
+		for _, frame := range lines[i+1:] {
+			m := goFrameRe.FindStringSubmatch(frame)
+			if m == nil || strings.Contains(m[1], "/src/runtime/") || strings.Contains(m[1], "/src/testing/") {
+				continue
+			}
+			file = m[1]
+			line, _ = strconv.Atoi(m[2])
+			break
+		}
+		return msg, file, line
+	}
+	return "", "", 0
+}
+
+// goTrimTestOutput drops the "=== RUN" / "--- FAIL" framing lines.
+func goTrimTestOutput(out string) string {
+	var kept []string
+	for _, l := range strings.Split(out, "\n") {
+		t := strings.TrimSpace(l)
+		if strings.HasPrefix(t, "=== ") || strings.HasPrefix(t, "--- FAIL") || strings.HasPrefix(t, "--- PASS") {
+			continue
+		}
+		kept = append(kept, l)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// =============================================================================
+// JUnit XML (pytest --junitxml)
+// =============================================================================
+
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Cases  []junitCase  `xml:"testcase"`
+	Suites []junitSuite `xml:"testsuite"`
+}
+
+type junitCase struct {
+	Name      string       `xml:"name,attr"`
+	Classname string       `xml:"classname,attr"`
+	File      string       `xml:"file,attr"`
+	Line      int          `xml:"line,attr"`
+	Failure   *junitResult `xml:"failure"`
+	Error     *junitResult `xml:"error"`
+	Skipped   *junitResult `xml:"skipped"`
+	SystemOut string       `xml:"system-out"`
+	SystemErr string       `xml:"system-err"`
+}
+
+type junitResult struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// pyLocRe matches a pytest traceback location: "tests/test_x.py:12: AssertionError".
+var pyLocRe = regexp.MustCompile(`(?m)^(\S+\.py):(\d+): `)
+
+func parseJUnitXML(output string, exitCode int) *Report {
+	r := &Report{}
+	start := strings.Index(output, "<testsuite")
+	if start < 0 {
+		if exitCode != 0 {
+			r.setRaw(output)
+		}
+		return r
+	}
+	body := output[start:]
+
+	var root junitSuite
+	if strings.HasPrefix(body, "<testsuites") {
+		if err := xml.Unmarshal([]byte(body), &root); err != nil {
+			r.setRaw(output)
+			return r
+		}
+	} else {
+		var suite junitSuite
+		if err := xml.Unmarshal([]byte(body), &suite); err != nil {
+			r.setRaw(output)
+			return r
+		}
+		root.Suites = []junitSuite{suite}
+	}
+
+	var walk func(s junitSuite)
+	walk = func(s junitSuite) {
+		for _, c := range s.Cases {
+			switch {
+			case c.Failure != nil || c.Error != nil:
+				r.Failed++
+				res := c.Failure
+				if res == nil {
+					res = c.Error
+				}
+				r.addFailure(junitFailure(c, res))
+			case c.Skipped != nil:
+				r.Skipped++
+			default:
+				r.Passed++
+			}
+		}
+		for _, child := range s.Suites {
+			walk(child)
+		}
+	}
+	walk(root)
+	return r
+}
+
+func junitFailure(c junitCase, res *junitResult) Failure {
+	f := Failure{
+		Name:    c.Name,
+		Suite:   c.Classname,
+		Message: res.Message,
+		File:    c.File,
+		Line:    c.Line,
+		Output:  strings.TrimSpace(res.Text),
+	}
+	// The deepest traceback location is where the assertion fired.
+	if m := pyLocRe.FindAllStringSubmatch(res.Text, -1); len(m) > 0 {
+		last := m[len(m)-1]
+		f.File = last[1]
+		f.Line, _ = strconv.Atoi(last[2])
+	}
+	if f.Message == "" {
+		for _, l := range strings.Split(res.Text, "\n") {
+			if strings.HasPrefix(l, "E ") {
+				f.Message = strings.TrimSpace(strings.TrimPrefix(l, "E"))
+				break
+			}
+		}
+	}
+	if extra := strings.TrimSpace(c.SystemOut + "\n" + c.SystemErr); extra != "" {
+		f.Output += "\n--- captured ---\n" + extra
+	}
+	return f
+}
+
+// =============================================================================
+// jest --json
+// =============================================================================
+
+type jestReport struct {
+	NumPassedTests  int `json:"numPassedTests"`
+	NumFailedTests  int `json:"numFailedTests"`
+	NumPendingTests int `json:"numPendingTests"`
+	NumTodoTests    int `json:"numTodoTests"`
+	TestResults     []struct {
+		Name             string `json:"name"`
+		Status           string `json:"status"`
+		Message          string `json:"message"`
+		AssertionResults []struct {
+			FullName        string   `json:"fullName"`
+			Status          string   `json:"status"`
+			FailureMessages []string `json:"failureMessages"`
+			Location        *struct {
+				Line int `json:"line"`
+			} `json:"location"`
+		} `json:"assertionResults"`
+	} `json:"testResults"`
+}
+
+var (
+	ansiRe    = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	jsFrameRe = regexp.MustCompile(`\(?([^\s()]+\.(?:[cm]?[jt]sx?)):(\d+):\d+\)?`)
+)
+
+func parseJestJSON(output string, exitCode int) *Report {
+	r := &Report{}
+	start := strings.Index(output, "{")
+	var jr jestReport
+	if start < 0 || json.Unmarshal([]byte(output[start:]), &jr) != nil {
+		if exitCode != 0 {
+			r.setRaw(output)
+		}
+		return r
+	}
+	r.Passed = jr.NumPassedTests
+	r.Skipped = jr.NumPendingTests + jr.NumTodoTests
+
+	for _, file := range jr.TestResults {
+		failedHere := 0
+		for _, a := range file.AssertionResults {
+			if a.Status != "failed" {
+				continue
+			}
+			failedHere++
+			r.Failed++
+			text := ansiRe.ReplaceAllString(strings.Join(a.FailureMessages, "\n"), "")
+			f := Failure{Name: a.FullName, Suite: file.Name, Output: text}
+			f.Message, f.File, f.Line = jsFailureDetail(text)
+			if f.File == "" {
+				f.File = file.Name
+				if a.Location != nil {
+					f.Line = a.Location.Line
+				}
+			}
+			r.addFailure(f)
+		}
+		// A suite that failed to load has no assertion results.
+		if file.Status == "failed" && failedHere == 0 {
+			r.Failed++
+			text := ansiRe.ReplaceAllString(file.Message, "")
+			f := Failure{Name: "(suite)", Suite: file.Name, Output: text}
+			f.Message, f.File, f.Line = jsFailureDetail(text)
+			if f.File == "" {
+				f.File = file.Name
+			}
+			r.addFailure(f)
+		}
+	}
+	return r
+}
+
+// jsFailureDetail splits a jest failure message into the assertion text
+// (everything before the stack) and the first non-node_modules frame.
+func jsFailureDetail(text string) (msg, file string, line int) {
+	var msgLines []string
+	for _, l := range strings.Split(text, "\n") {
+		t := strings.TrimSpace(l)
+		if strings.HasPrefix(t, "at ") {
+			if file == "" && !strings.Contains(t, "node_modules") {
+				if m := jsFrameRe.FindStringSubmatch(t); m != nil {
+					file = m[1]
+					line, _ = strconv.Atoi(m[2])
+				}
+			}
+			continue
+		}
+		if file == "" && t != "" {
+			msgLines = append(msgLines, t)
+		}
+	}
+	return strings.Join(msgLines, "\n"), file, line
+}
+
+// =============================================================================
+// cargo test (libtest text output)
+// =============================================================================
+
+var (
+	cargoResultRe = regexp.MustCompile(`^test (.+?) \.\.\. (ok|FAILED|ignored)`)
+	cargoBlockRe  = regexp.MustCompile(`^---- (.+?) stdout ----$`)
+	// Rust 1.73+: "thread 'x' panicked at src/lib.rs:10:5:" with the message on the next line.
+	cargoPanicRe = regexp.MustCompile(`panicked at (\S+?):(\d+):\d+:$`)
+	// Older: "thread 'x' panicked at 'message', src/lib.rs:10:5".
+	cargoPanicOldRe = regexp.MustCompile(`panicked at '(.*)', (\S+?):(\d+):\d+`)
+	cargoErrLocRe   = regexp.MustCompile(`-->\s+(\S+?):(\d+):\d+`)
+)
+
+func parseCargoTest(output string, exitCode int) *Report {
+	r := &Report{}
+	lines := strings.Split(output, "\n")
+
+	blocks := make(map[string][]string)
+	var current string
+	var failedNames []string
+	for _, l := range lines {
+		if m := cargoResultRe.FindStringSubmatch(l); m != nil {
+			switch m[2] {
+			case "ok":
+				r.Passed++
+			case "ignored":
+				r.Skipped++
+			case "FAILED":
+				failedNames = append(failedNames, m[1])
+			}
+			current = ""
+			continue
+		}
+		if m := cargoBlockRe.FindStringSubmatch(l); m != nil {
+			current = m[1]
+			continue
+		}
+		if current != "" {
+			if l == "failures:" || strings.HasPrefix(l, "test result:") {
+				current = ""
+				continue
+			}
+			blocks[current] = append(blocks[current], l)
+		}
+	}
+
+	for _, name := range failedNames {
+		r.Failed++
+		block := blocks[name]
+		f := Failure{Name: name, Output: strings.Join(block, "\n")}
+		for i, l := range block {
+			if m := cargoPanicRe.FindStringSubmatch(l); m != nil {
+				f.File = m[1]
+				f.Line, _ = strconv.Atoi(m[2])
+				f.Message = strings.Join(block[i+1:min(i+4, len(block))], "\n")
+				break
+			}
+			if m := cargoPanicOldRe.FindStringSubmatch(l); m != nil {
+				f.Message, f.File = m[1], m[2]
+				f.Line, _ = strconv.Atoi(m[3])
+				break
+			}
+		}
+		r.addFailure(f)
+	}
+
+	if exitCode != 0 && r.Failed == 0 {
+		// No test failed, so the crate did not compile (or the runner is missing).
+		var errs []string
+		f := Failure{Name: "(build)"}
+		for _, l := range lines {
+			if strings.HasPrefix(l, "error") {
+				errs = append(errs, l)
+				if f.Message == "" {
+					f.Message = l
+				}
+			}
+			if f.File == "" {
+				if m := cargoErrLocRe.FindStringSubmatch(l); m != nil {
+					f.File = m[1]
+					f.Line, _ = strconv.Atoi(m[2])
+				}
+			}
+		}
+		if len(errs) > 0 {
+			r.Failed++
+			f.Output = strings.Join(errs, "\n")
+			r.addFailure(f)
+		} else {
+			r.setRaw(output)
+		}
+	}
+	return r
+}
+
+// =============================================================================
+// helpers
+// =============================================================================
+
+func firstLine(s string) string {
+	for _, l := range strings.Split(s, "\n") {
+		if t := strings.TrimSpace(l); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+func containsStr(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package testreport
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGoTestJSON(t *testing.T) {
+	t.Parallel()
+
+	output := `{"Action":"run","Package":"example.com/m","Test":"TestAdd"}
+{"Action":"output","Package":"example.com/m","Test":"TestAdd","Output":"=== RUN   TestAdd\n"}
+{"Action":"pass","Package":"example.com/m","Test":"TestAdd"}
+{"Action":"run","Package":"example.com/m","Test":"TestSub"}
+{"Action":"output","Package":"example.com/m","Test":"TestSub/neg","Output":"    math_test.go:14: Sub(1, 2) = 3, want -1\n"}
+{"Action":"fail","Package":"example.com/m","Test":"TestSub/neg"}
+{"Action":"fail","Package":"example.com/m","Test":"TestSub"}
+{"Action":"skip","Package":"example.com/m","Test":"TestSlow"}
+{"Action":"fail","Package":"example.com/m"}
+{"ImportPath":"example.com/m/broken","Action":"build-output","Output":"# example.com/m/broken\n"}
+{"ImportPath":"example.com/m/broken","Action":"build-output","Output":"broken/b.go:3:9: undefined: x\n"}
+{"ImportPath":"example.com/m/broken","Action":"build-fail"}
+`
+	r := Parse(FrameworkGo, output, 1)
+	if r.Passed != 1 || r.Failed != 2 || r.Skipped != 1 {
+		t.Fatalf("counts = %d/%d/%d, want 1/2/1", r.Passed, r.Failed, r.Skipped)
+	}
+	f := r.Failures[0]
+	if f.Name != "TestSub/neg" || f.Location() != "math_test.go:14" || f.Message != "Sub(1, 2) = 3, want -1" {
+		t.Errorf("test failure = %+v", f)
+	}
+	b := r.Failures[1]
+	if b.Name != "(build)" || b.Location() != "broken/b.go:3" || b.Message != "undefined: x" {
+		t.Errorf("build failure = %+v", b)
+	}
+	if r.OK() {
+		t.Error("OK() = true for a failing run")
+	}
+}
+
+func TestParseGoTestJSON_Panic(t *testing.T) {
+	t.Parallel()
+
+	output := `{"Action":"output","Package":"m","Test":"TestBoom","Output":"panic: runtime error: index out of range [3] with length 1\n"}
+{"Action":"output","Package":"m","Test":"TestBoom","Output":"\t/usr/local/go/src/testing/testing.go:1690 +0x1d\n"}
+{"Action":"output","Package":"m","Test":"TestBoom","Output":"\t/work/m/boom_test.go:8 +0x2a\n"}
+{"Action":"fail","Package":"m","Test":"TestBoom"}
+`
+	r := Parse(FrameworkGo, output, 2)
+	r.Relativize("/work/m")
+	if len(r.Failures) != 1 {
+		t.Fatalf("failures = %+v", r.Failures)
+	}
+	f := r.Failures[0]
+	if !strings.HasPrefix(f.Message, "panic: runtime error") || f.Location() != "boom_test.go:8" {
+		t.Errorf("failure = %+v", f)
+	}
+}
+
+func TestParseJUnitXML(t *testing.T) {
+	t.Parallel()
+
+	output := `<?xml version="1.0" encoding="utf-8"?><testsuites><testsuite name="pytest" tests="4">
+<testcase classname="tests.test_math" name="test_add" file="tests/test_math.py" line="3"/>
+<testcase classname="tests.test_math" name="test_div" file="tests/test_math.py" line="7"><failure message="assert 2 == 3">def test_div():
+&gt;       assert div(6, 3) == 3
+E       assert 2 == 3
+
+tests/test_math.py:9: AssertionError</failure></testcase>
+<testcase classname="tests.test_math" name="test_io"><error message="">fixture 'tmp' not found
+E       fixture 'tmp' not found</error></testcase>
+<testcase classname="tests.test_math" name="test_skip"><skipped message="later"/></testcase>
+</testsuite></testsuites>`
+
+	r := Parse(FrameworkPytest, output, 1)
+	if r.Passed != 1 || r.Failed != 2 || r.Skipped != 1 {
+		t.Fatalf("counts = %d/%d/%d, want 1/2/1", r.Passed, r.Failed, r.Skipped)
+	}
+	if f := r.Failures[0]; f.Location() != "tests/test_math.py:9" || f.Message != "assert 2 == 3" {
+		t.Errorf("failure = %+v", f)
+	}
+	if f := r.Failures[1]; f.Message != "fixture 'tmp' not found" {
+		t.Errorf("error message = %q", f.Message)
+	}
+}
+
+func TestParseJestJSON(t *testing.T) {
+	t.Parallel()
+
+	output := `{"numPassedTests":3,"numFailedTests":1,"numPendingTests":1,"numTodoTests":0,"testResults":[
+{"name":"/w/src/sum.test.js","status":"failed","message":"","assertionResults":[
+{"fullName":"sum adds","status":"failed","failureMessages":["Error: \u001b[2mexpect(\u001b[22mreceived).toBe(expected)\n\nExpected: 4\nReceived: 5\n    at Object.<anonymous> (/w/node_modules/expect/build/index.js:1:1)\n    at Object.<anonymous> (/w/src/sum.test.js:5:17)"]}]},
+{"name":"/w/src/broken.test.js","status":"failed","message":"SyntaxError: Unexpected token\n    at Runtime (/w/src/broken.test.js:2:3)","assertionResults":[]}]}`
+
+	r := Parse(FrameworkJest, output, 1)
+	r.Relativize("/w")
+	if r.Passed != 3 || r.Failed != 2 || r.Skipped != 1 {
+		t.Fatalf("counts = %d/%d/%d, want 3/2/1", r.Passed, r.Failed, r.Skipped)
+	}
+	f := r.Failures[0]
+	if f.Location() != "src/sum.test.js:5" || !strings.Contains(f.Message, "Expected: 4") || strings.Contains(f.Message, "\x1b") {
+		t.Errorf("failure = %+v", f)
+	}
+	if s := r.Failures[1]; s.Name != "(suite)" || s.Location() != "src/broken.test.js:2" {
+		t.Errorf("suite failure = %+v", s)
+	}
+}
+
+func TestParseCargoTest(t *testing.T) {
+	t.Parallel()
+
+	output := `running 3 tests
+test tests::adds ... ok
+test tests::ignored_one ... ignored
+test tests::subtracts ... FAILED
+
+failures:
+
+---- tests::subtracts stdout ----
+thread 'tests::subtracts' panicked at src/lib.rs:12:9:
+assertion ` + "`left == right`" + ` failed
+  left: 1
+ right: -1
+
+failures:
+    tests::subtracts
+
+test result: FAILED. 1 passed; 1 failed; 1 ignored; 0 measured; 0 filtered out
+`
+	r := Parse(FrameworkCargo, output, 101)
+	if r.Passed != 1 || r.Failed != 1 || r.Skipped != 1 {
+		t.Fatalf("counts = %d/%d/%d, want 1/1/1", r.Passed, r.Failed, r.Skipped)
+	}
+	f := r.Failures[0]
+	if f.Location() != "src/lib.rs:12" || !strings.Contains(f.Message, "left == right") {
+		t.Errorf("failure = %+v", f)
+	}
+
+	build := Parse(FrameworkCargo, "error[E0425]: cannot find value `x`\n --> src/lib.rs:3:5\n", 101)
+	if len(build.Failures) != 1 || build.Failures[0].Location() != "src/lib.rs:3" {
+		t.Errorf("build failures = %+v", build.Failures)
+	}
+}
+
+func TestParse_UnparseableOutputKeptAsRaw(t *testing.T) {
+	t.Parallel()
+
+	r := Parse(FrameworkJest, "sh: npx: command not found", 127)
+	if r.OK() || r.Raw == "" {
+		t.Fatalf("report = %+v, want failing report with raw output", r)
+	}
+	if !strings.Contains(r.Format(), "npx: command not found") {
+		t.Errorf("Format() dropped the raw output:\n%s", r.Format())
+	}
+}
+
+func TestFailureOutputTruncatedPerFailure(t *testing.T) {
+	t.Parallel()
+
+	r := &Report{}
+	r.addFailure(Failure{Name: "noisy", Output: strings.Repeat("x", 3*MaxFailureOutput)})
+	r.addFailure(Failure{Name: "quiet", Output: "short"})
+	if got := len(r.Failures[0].Output); got > MaxFailureOutput+40 {
+		t.Errorf("noisy output length = %d, want about %d", got, MaxFailureOutput)
+	}
+	if r.Failures[1].Output != "short" {
+		t.Errorf("quiet output = %q", r.Failures[1].Output)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		files []string
+		want  Framework
+	}{
+		{[]string{"go.mod", "main.go"}, FrameworkGo},
+		{[]string{"Cargo.toml", "package.json"}, FrameworkCargo},
+		{[]string{"package.json", "src"}, FrameworkJest},
+		{[]string{"app.py"}, FrameworkPytest},
+		{[]string{"pyproject.toml"}, FrameworkPytest},
+		{[]string{"README.md"}, ""},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.files); got != tt.want {
+			t.Errorf("Detect(%v) = %q, want %q", tt.files, got, tt.want)
+		}
+	}
+}
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	cmd, err := Command(FrameworkGo, Options{Run: "TestA|TestB"})
+	if err != nil || cmd != "go test -json -run 'TestA|TestB' './...' 2>&1" {
+		t.Errorf("go command = %q, %v", cmd, err)
+	}
+	cmd, _ = Command(FrameworkPytest, Options{Target: "tests/it's.py"})
+	if !strings.Contains(cmd, `'tests/it'\''s.py'`) || !strings.Contains(cmd, "exit $rc") {
+		t.Errorf("pytest command = %q", cmd)
+	}
+	if _, err := Command("maven", Options{}); err == nil {
+		t.Error("Command(maven) succeeded, want error")
+	}
+	if _, err := ParseFramework("maven"); err == nil {
+		t.Error("ParseFramework(maven) succeeded, want error")
+	}
+}
@@ -0,0 +1,208 @@
+// Package testreport runs and parses test suites into a structured report.
+//
+// It is shared by the run_tests tool (local and sandbox), the sandbox
+// server's test endpoint and the boss battle's automated test check, so an
+// agent, a judge and the quest artifacts all see the same pass/fail picture.
+//
+// Each supported framework is run in a machine-readable mode: go test -json,
+// pytest --junitxml, jest --json. cargo has no stable machine-readable
+// output, so its libtest text output is parsed instead.
+package testreport
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Framework identifies a test runner.
+type Framework string
+
+// Supported frameworks.
+const (
+	FrameworkGo     Framework = "go"
+	FrameworkPytest Framework = "pytest"
+	FrameworkJest   Framework = "jest"
+	FrameworkCargo  Framework = "cargo"
+)
+
+// Frameworks lists the supported frameworks in detection priority order.
+var Frameworks = []Framework{FrameworkCargo, FrameworkGo, FrameworkJest, FrameworkPytest}
+
+// Output limits. Truncation is per failure so one noisy test cannot push
+// every other failure out of the report.
+const (
+	// MaxFailureOutput caps the captured output kept for each failure.
+	MaxFailureOutput = 2000
+	// MaxFailureMessage caps each failure's assertion message.
+	MaxFailureMessage = 500
+	// MaxFailures caps how many failures a report keeps.
+	MaxFailures = 50
+	// maxRawOutput caps the raw runner output kept when nothing could be parsed.
+	maxRawOutput = 4000
+)
+
+// Failure is a single failing test, or a build/collection error that kept
+// tests from running.
+type Failure struct {
+	Name    string `json:"name"`
+	Suite   string `json:"suite,omitempty"`   // Package, class or test file
+	Message string `json:"message,omitempty"` // Assertion or error message
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Output  string `json:"output,omitempty"` // Captured output, truncated to MaxFailureOutput
+}
+
+// Location returns "file:line", "file" or "".
+func (f Failure) Location() string {
+	switch {
+	case f.File == "":
+		return ""
+	case f.Line > 0:
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	default:
+		return f.File
+	}
+}
+
+// Report is the structured outcome of one test run.
+type Report struct {
+	Framework Framework `json:"framework"`
+	Command   string    `json:"command,omitempty"`
+	ExitCode  int       `json:"exit_code"`
+	TimedOut  bool      `json:"timed_out,omitempty"`
+	Passed    int       `json:"passed"`
+	Failed    int       `json:"failed"`
+	Skipped   int       `json:"skipped"`
+	Failures  []Failure `json:"failures,omitempty"`
+	// Omitted counts failures dropped beyond MaxFailures.
+	Omitted int `json:"omitted,omitempty"`
+	// Raw holds the tail of the runner output when it produced no
+	// parseable report (e.g. the runner is not installed).
+	Raw   string    `json:"raw,omitempty"`
+	RanAt time.Time `json:"ran_at,omitzero"`
+}
+
+// OK reports whether the run completed with no failures.
+func (r *Report) OK() bool {
+	return r.Failed == 0 && r.ExitCode == 0 && !r.TimedOut
+}
+
+// Total is the number of tests that ran or were skipped.
+func (r *Report) Total() int {
+	return r.Passed + r.Failed + r.Skipped
+}
+
+// Summary is a one-line count summary.
+func (r *Report) Summary() string {
+	s := fmt.Sprintf("%s: %d passed, %d failed, %d skipped", r.Framework, r.Passed, r.Failed, r.Skipped)
+	switch {
+	case r.TimedOut:
+		s += " (timed out)"
+	case r.ExitCode != 0:
+		s += fmt.Sprintf(" (exit %d)", r.ExitCode)
+	}
+	return s
+}
+
+// Format renders the report for an agent or judge: the summary line, then
+// each failure with its location, message and truncated output.
+func (r *Report) Format() string {
+	var sb strings.Builder
+	sb.WriteString(r.Summary())
+	sb.WriteByte('\n')
+	if r.OK() && len(r.Failures) == 0 {
+		if r.Total() == 0 {
+			sb.WriteString("No tests were found.\n")
+		}
+		return sb.String()
+	}
+
+	for _, f := range r.Failures {
+		sb.WriteString("\nFAIL ")
+		if f.Suite != "" {
+			sb.WriteString(f.Suite + " ")
+		}
+		sb.WriteString(f.Name)
+		if loc := f.Location(); loc != "" {
+			sb.WriteString(" (" + loc + ")")
+		}
+		sb.WriteByte('\n')
+		if f.Message != "" {
+			sb.WriteString(indent(f.Message, "  "))
+		}
+		if f.Output != "" && f.Output != f.Message {
+			sb.WriteString("  --- output ---\n")
+			sb.WriteString(indent(f.Output, "  "))
+		}
+	}
+	if r.Omitted > 0 {
+		fmt.Fprintf(&sb, "\n... and %d more failure(s)\n", r.Omitted)
+	}
+	if r.Raw != "" {
+		sb.WriteString("\n--- runner output ---\n")
+		sb.WriteString(r.Raw)
+		if !strings.HasSuffix(r.Raw, "\n") {
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// Relativize rewrites absolute failure paths under root as root-relative.
+func (r *Report) Relativize(root string) {
+	root = strings.TrimSuffix(root, "/")
+	if root == "" {
+		return
+	}
+	for i := range r.Failures {
+		if rel, ok := strings.CutPrefix(r.Failures[i].File, root+"/"); ok {
+			r.Failures[i].File = rel
+		}
+		if rel, ok := strings.CutPrefix(r.Failures[i].Suite, root+"/"); ok {
+			r.Failures[i].Suite = rel
+		}
+	}
+}
+
+// addFailure appends a failure, enforcing the per-failure and per-report
+// limits.
+func (r *Report) addFailure(f Failure) {
+	if len(r.Failures) >= MaxFailures {
+		r.Omitted++
+		return
+	}
+	f.Message = clip(strings.TrimSpace(f.Message), MaxFailureMessage)
+	f.Output = clip(strings.TrimRight(f.Output, "\n"), MaxFailureOutput)
+	f.File = path.Clean(f.File)
+	if f.File == "." {
+		f.File = ""
+	}
+	r.Failures = append(r.Failures, f)
+}
+
+// setRaw keeps the tail of unparseable runner output.
+func (r *Report) setRaw(output string) {
+	output = strings.TrimSpace(output)
+	if len(output) > maxRawOutput {
+		output = "..." + output[len(output)-maxRawOutput:]
+	}
+	r.Raw = output
+}
+
+// clip truncates s to max bytes, noting how much was dropped.
+func clip(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + fmt.Sprintf("\n... (%d more bytes)", len(s)-max)
+}
+
+func indent(s, prefix string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = prefix + l
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,151 @@
+package testreport
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Report files written by runners that cannot stream a machine-readable
+// report to stdout. They are removed again by the generated command.
+const (
+	pytestReportFile = ".run-tests-report.xml"
+	jestReportFile   = ".run-tests-report.json"
+	runnerLogFile    = ".run-tests.log"
+)
+
+// Options selects what to run.
+type Options struct {
+	// Target narrows the run: a package pattern for go (default ./...), a
+	// file or directory for pytest and jest, a package name for cargo.
+	Target string `json:"target,omitempty"`
+	// Run filters tests by name: go -run, pytest -k, jest -t, cargo's
+	// positional filter.
+	Run string `json:"run,omitempty"`
+}
+
+// ParseFramework validates a framework name. "" and "auto" return "".
+func ParseFramework(s string) (Framework, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "", "auto":
+		return "", nil
+	}
+	for _, fw := range Frameworks {
+		if string(fw) == s {
+			return fw, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported test framework %q (supported: go, pytest, jest, cargo)", s)
+}
+
+// Detect picks a framework from the names of files at the workspace root.
+// Returns "" when nothing recognizable is present.
+func Detect(files []string) Framework {
+	has := make(map[string]bool, len(files))
+	python := false
+	for _, f := range files {
+		name := path.Base(f)
+		has[name] = true
+		if strings.HasSuffix(name, ".py") {
+			python = true
+		}
+	}
+	switch {
+	case has["Cargo.toml"]:
+		return FrameworkCargo
+	case has["go.mod"]:
+		return FrameworkGo
+	case has["package.json"]:
+		return FrameworkJest
+	case python || has["pyproject.toml"] || has["pytest.ini"] || has["setup.py"] ||
+		has["setup.cfg"] || has["requirements.txt"] || has["tests"]:
+		return FrameworkPytest
+	}
+	return ""
+}
+
+// Command builds the shell command for a framework. Its stdout is what Parse
+// expects; it is run from the workspace root.
+func Command(fw Framework, opts Options) (string, error) {
+	switch fw {
+	case FrameworkGo:
+		target := opts.Target
+		if target == "" {
+			target = "./..."
+		}
+		cmd := "go test -json"
+		if opts.Run != "" {
+			cmd += " -run " + shellQuote(opts.Run)
+		}
+		return cmd + " " + shellQuote(target) + " 2>&1", nil
+
+	case FrameworkPytest:
+		cmd := "PY=python3; [ -x .venv/bin/python3 ] && PY=.venv/bin/python3; " +
+			"$PY -m pytest -q -p no:cacheprovider --junitxml=" + pytestReportFile
+		if opts.Run != "" {
+			cmd += " -k " + shellQuote(opts.Run)
+		}
+		if opts.Target != "" {
+			cmd += " " + shellQuote(opts.Target)
+		}
+		return withReportFile(cmd, pytestReportFile), nil
+
+	case FrameworkJest:
+		cmd := "npx --no-install jest --ci --json --outputFile=" + jestReportFile
+		if opts.Run != "" {
+			cmd += " -t " + shellQuote(opts.Run)
+		}
+		if opts.Target != "" {
+			cmd += " " + shellQuote(opts.Target)
+		}
+		return withReportFile(cmd, jestReportFile), nil
+
+	case FrameworkCargo:
+		cmd := "cargo test --no-fail-fast"
+		if opts.Target != "" {
+			cmd += " -p " + shellQuote(opts.Target)
+		}
+		if opts.Run != "" {
+			cmd += " " + shellQuote(opts.Run)
+		}
+		return cmd + " 2>&1", nil
+	}
+	return "", fmt.Errorf("unsupported test framework %q", fw)
+}
+
+// withReportFile wraps a runner that writes its report to a file: runner
+// output goes to a log, the report (or the log tail if no report was
+// written) goes to stdout, and the runner's exit code is preserved.
+func withReportFile(cmd, report string) string {
+	return fmt.Sprintf("rm -f %[2]s; %[1]s > %[3]s 2>&1; rc=$?; "+
+		"if [ -s %[2]s ]; then cat %[2]s; else tail -c 20000 %[3]s; fi; "+
+		"rm -f %[2]s %[3]s; exit $rc", cmd, report, runnerLogFile)
+}
+
+// Parse builds a report from a framework's command output and exit code.
+// Output that does not parse is kept as Raw so the caller still sees why
+// the run failed.
+func Parse(fw Framework, output string, exitCode int) *Report {
+	var r *Report
+	switch fw {
+	case FrameworkGo:
+		r = parseGoTestJSON(output, exitCode)
+	case FrameworkPytest:
+		r = parseJUnitXML(output, exitCode)
+	case FrameworkJest:
+		r = parseJestJSON(output, exitCode)
+	case FrameworkCargo:
+		r = parseCargoTest(output, exitCode)
+	default:
+		r = &Report{}
+		r.setRaw(output)
+	}
+	r.Framework = fw
+	r.ExitCode = exitCode
+	return r
+}
+
+// shellQuote wraps s in single quotes for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
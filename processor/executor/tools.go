@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -25,6 +26,7 @@ import (
 	"github.com/c360studio/semstreams/agentic"
 	"github.com/c360studio/semstreams/graph"
 	"github.com/c360studio/semstreams/message"
+	"golang.org/x/net/http/httpguts"
 )
 
 // =============================================================================
@@ -38,6 +40,10 @@ import (
 const (
 	// maxHTTPResponseSize is the maximum bytes to return from an HTTP response.
 	maxHTTPResponseSize = 100000
+	// maxHTTPDocumentSize is the read limit for responses httpformat extracts
+	// text from (PDFs, feeds, API specs, notebooks, JSON), whose extracted
+	// view is much smaller than the bytes on the wire.
+	maxHTTPDocumentSize = 10 << 20
 	// maxGraphResponseSize is the maximum bytes for graph search responses.
 	// Larger than HTTP because community summaries + entity lists are verbose.
 	maxGraphResponseSize = 500000
@@ -91,6 +97,14 @@ func SetHTTPGraphPersist(gc *semdragons.GraphClient, cfg *domain.BoardConfig) {
 	httpGraphPersist = &graphPersister{graph: gc, config: cfg}
 }
 
+// dagBlueprints resolves the blueprints party leads name in decompose_quest.
+// Nil means leads must always provide nodes (the default).
+var dagBlueprints questdagexec.BlueprintSource
+
+// SetDAGBlueprints enables blueprint instantiation in decompose_quest.
+// Call this from the component's Start method; pass nil to disable.
+func SetDAGBlueprints(src questdagexec.BlueprintSource) { dagBlueprints = src }
+
 // =============================================================================
 
 // ToolHandler executes a tool and returns the result.
@@ -104,16 +118,20 @@ type ToolCategory string
 const (
 	// ToolCategoryCore groups terminal tools: submit_work, ask_clarification.
 	ToolCategoryCore ToolCategory = "core"
-	// ToolCategoryWrite is retained for questbridge category filtering (currently unused by tools).
+	// ToolCategoryWrite groups workspace writes: write_file, apply_patch.
 	ToolCategoryWrite ToolCategory = "write"
 	// ToolCategoryNetwork groups external access tools: http_request, web_search.
 	ToolCategoryNetwork ToolCategory = "network"
-	// ToolCategoryInspect groups the bash tool.
+	// ToolCategoryInspect groups bash and the read-only file tools:
+	// read_file, search_code, list_files.
 	ToolCategoryInspect ToolCategory = "inspect"
 	// ToolCategoryKnowledge groups graph tools: graph_query, graph_search, graph_summary.
 	ToolCategoryKnowledge ToolCategory = "knowledge"
 	// ToolCategoryPartyLead groups DAG tools: decompose, review, answer_clarification.
 	ToolCategoryPartyLead ToolCategory = "party_lead"
+	// ToolCategoryParty groups member coordination tools: party_note_write,
+	// party_note_read, message_member.
+	ToolCategoryParty ToolCategory = "party"
 )
 
 // RegisteredTool wraps a tool definition with its handler and access controls.
@@ -123,6 +141,28 @@ type RegisteredTool struct {
 	Skills     []domain.SkillTag      // Required skills to use this tool
 	MinTier    domain.TrustTier       // Minimum trust tier to use
 	Category   ToolCategory           // Tool category for quest-based filtering
+	Dangerous  bool                   // Only offered when the quest's AllowedTools names it
+	StoreItem  string                 // Store item the agent must own to use it ("" = free)
+	SecretArgs []string               // Object arguments whose string values may hold {{secret:name}}
+	Limits     *ToolLimits            // Default rate limits and quotas (nil = unlimited)
+}
+
+// Unlocked reports whether the quest and agent pass the tool's danger and
+// store gates. Tier and skill gates are checked separately by callers.
+func (t RegisteredTool) Unlocked(quest *domain.Quest, agent *agentprogression.Agent) bool {
+	return t.lockReason(quest, agent) == ""
+}
+
+// lockReason explains why Unlocked is false, or returns "".
+func (t RegisteredTool) lockReason(quest *domain.Quest, agent *agentprogression.Agent) string {
+	name := t.Definition.Name
+	if t.Dangerous && (quest == nil || !containsToolName(quest.AllowedTools, name)) {
+		return fmt.Sprintf("tool %s is marked dangerous and must be listed in the quest's allowed tools", name)
+	}
+	if t.StoreItem != "" && !agent.OwnsTool(t.StoreItem) {
+		return fmt.Sprintf("tool %s requires store item %s", name, t.StoreItem)
+	}
+	return ""
 }
 
 // toolSpec holds the shared metadata for a tool registration.
@@ -133,6 +173,8 @@ type toolSpec struct {
 	MinTier    domain.TrustTier
 	Skills     []domain.SkillTag
 	Category   ToolCategory
+	SecretArgs []string
+	Limits     *ToolLimits
 }
 
 // Shared tool specs — single source of truth for definition, tier, and skills.
@@ -142,7 +184,7 @@ type toolSpec struct {
 var runCommandSpec = toolSpec{
 	Definition: agentic.ToolDefinition{
 		Name:        "bash",
-		Description: "Run a shell command. Use for ALL operations: read (cat), write (cat <<'EOF' > file), search (grep -rn), list (ls -la), tests, builds, git, deps. Supports heredocs and pipes.",
+		Description: "Run a shell command: tests, builds, git, deps, and anything the file tools (read_file, write_file, apply_patch, search_code, list_files) don't cover. Supports heredocs and pipes.",
 		Parameters: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -150,12 +192,18 @@ var runCommandSpec = toolSpec{
 					"type":        "string",
 					"description": "The shell command to execute",
 				},
+				"env": map[string]any{
+					"type":                 "object",
+					"description":          "Extra environment variables for the command. Values may reference secrets as {{secret:name}}; read them in the command as $NAME and never echo them.",
+					"additionalProperties": map[string]any{"type": "string"},
+				},
 			},
 			"required": []any{"command"},
 		},
 	},
-	MinTier:  domain.TierJourneyman, // Level 6+ — sandbox is the security boundary, not the tier gate
-	Category: ToolCategoryInspect,
+	MinTier:    domain.TierJourneyman, // Level 6+ — sandbox is the security boundary, not the tier gate
+	Category:   ToolCategoryInspect,
+	SecretArgs: []string{"env"},
 }
 
 var httpRequestSpec = toolSpec{
@@ -163,8 +211,9 @@ var httpRequestSpec = toolSpec{
 		Name: "http_request",
 		Description: "Fetch a URL and return its content. HTML pages run through Readability " +
 			"and are converted to markdown by default — agents see the main article without " +
-			"navigation/footer boilerplate. JSON, plain text, and other non-HTML responses " +
-			"pass through unchanged so APIs work as expected. " +
+			"navigation/footer boilerplate. PDFs, RSS/Atom feeds, OpenAPI specs and Jupyter " +
+			"notebooks are extracted to text; JSON passes through unchanged unless it is too " +
+			"large, in which case its shape is summarized. Plain text passes through unchanged. " +
 			"Use format=raw to bypass markdown conversion, format=summary for a short " +
 			"title+excerpt+top-links view, format=links for just the URLs, or format=headings " +
 			"for an outline. For binary downloads, use bash with curl instead.",
@@ -190,28 +239,43 @@ var httpRequestSpec = toolSpec{
 				},
 				"format": map[string]any{
 					"type":        "string",
-					"description": "Response shape: markdown (default, HTML→Readability→markdown), summary, links, headings, or raw. Non-HTML responses ignore this and return as-is.",
+					"description": "Response shape: markdown (default, HTML→Readability→markdown), summary, links, headings, or raw. Documents (PDF, feeds, specs, notebooks, JSON) support markdown, summary and raw; other non-HTML responses return as-is.",
 					"enum":        []any{"markdown", "summary", "links", "headings", "raw"},
 				},
+				"headers": map[string]any{
+					"type":                 "object",
+					"description":          "Extra request headers. Values may reference secrets as {{secret:name}} (e.g. {\"Authorization\": \"Bearer {{secret:api_token}}\"}).",
+					"additionalProperties": map[string]any{"type": "string"},
+				},
 			},
 			"required": []any{"url"},
 		},
 	},
-	MinTier:  domain.TierJourneyman, // Level 6+ — network access requires trust
-	Category: ToolCategoryNetwork,
+	MinTier:    domain.TierJourneyman, // Level 6+ — network access requires trust
+	Category:   ToolCategoryNetwork,
+	SecretArgs: []string{"headers"},
+	Limits:     &ToolLimits{PerMinute: 30, PerQuest: 200},
 }
 
 // ToolRegistry manages available tools for agent execution.
 type ToolRegistry struct {
 	mu         sync.RWMutex
 	tools      map[string]RegisteredTool
-	sandboxDir string // Base directory for file operations (empty = current dir)
+	sandboxDir string                // Base directory for file operations (empty = current dir)
+	policy     *ToolPolicy           // Evaluated before every call (nil = allow all)
+	approver   ToolCallApprover      // Decides require_approval calls (nil = deny them)
+	secrets    SecretResolver        // Resolves {{secret:name}} placeholders (nil = none)
+	limits     map[string]ToolLimits // Configured limits by tool name or glob
+	costs      CostRecorder          // Charged for priced calls (nil = not recorded)
+	usage      *toolUsage            // Quota windows and usage counters
+	safety     *ContentSafetyConfig  // Quarantines fetched content (nil = off)
 }
 
 // NewToolRegistry creates a new empty tool registry.
 func NewToolRegistry() *ToolRegistry {
 	return &ToolRegistry{
 		tools: make(map[string]RegisteredTool),
+		usage: newToolUsage(),
 	}
 }
 
@@ -221,6 +285,7 @@ func NewToolRegistryWithSandbox(sandboxDir string) *ToolRegistry {
 	return &ToolRegistry{
 		tools:      make(map[string]RegisteredTool),
 		sandboxDir: sandboxDir,
+		usage:      newToolUsage(),
 	}
 }
 
@@ -238,6 +303,109 @@ func (r *ToolRegistry) GetSandboxDir() string {
 	return r.sandboxDir
 }
 
+// SetPolicy installs the tool-call policy, replacing any previous one.
+// Pass nil to remove it. Returns an error if the policy does not compile.
+func (r *ToolRegistry) SetPolicy(p *ToolPolicy) error {
+	if p != nil && !p.compiled {
+		if err := p.Compile(); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policy = p
+	return nil
+}
+
+// Policy returns the current tool-call policy, or nil.
+func (r *ToolRegistry) Policy() *ToolPolicy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.policy
+}
+
+// SetApprover sets who decides require_approval calls.
+func (r *ToolRegistry) SetApprover(a ToolCallApprover) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.approver = a
+}
+
+// SetSecrets sets the resolver for {{secret:name}} placeholders.
+func (r *ToolRegistry) SetSecrets(s SecretResolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.secrets = s
+}
+
+// SetToolLimits sets configured rate limits and quotas, keyed by tool name
+// or glob. They override the limits tools were registered with.
+func (r *ToolRegistry) SetToolLimits(limits map[string]ToolLimits) error {
+	if err := ValidateToolLimits(limits); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits = limits
+	return nil
+}
+
+// SetCostRecorder sets where the cost of priced tool calls is recorded.
+func (r *ToolRegistry) SetCostRecorder(c CostRecorder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.costs = c
+}
+
+// SetContentSafety enables the content-safety stage for fetched content.
+// Pass nil to disable it. Returns an error if the config is invalid.
+func (r *ToolRegistry) SetContentSafety(cfg *ContentSafetyConfig) error {
+	if cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		withDefaults := cfg.withDefaults()
+		cfg = &withDefaults
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.safety = cfg
+	return nil
+}
+
+// QuarantineResult applies the content-safety stage to a result produced
+// outside Execute, such as the explore tool's sub-agent summary. It is a
+// no-op when the stage is off or does not cover the tool.
+func (r *ToolRegistry) QuarantineResult(call agentic.ToolCall, result *agentic.ToolResult) {
+	r.mu.RLock()
+	safety := r.safety
+	r.mu.RUnlock()
+	if safety != nil && safety.covers(call.Name) {
+		safety.quarantine(call, result)
+	}
+}
+
+// Usage returns per-tool usage counters, sorted by tool name.
+func (r *ToolRegistry) Usage() []ToolUsageStats {
+	return r.usage.snapshot()
+}
+
+// ForgetQuest drops per-quest quota counts for a quest that has finished,
+// so they do not accumulate for the life of the registry.
+func (r *ToolRegistry) ForgetQuest(questID domain.QuestID) {
+	r.usage.forgetQuest(questID)
+}
+
+// EvaluatePolicy returns the policy decision for a call without running it.
+// Callers use it to route require_approval calls off short-lived contexts.
+func (r *ToolRegistry) EvaluatePolicy(call agentic.ToolCall, quest *domain.Quest, agent *agentprogression.Agent) PolicyDecision {
+	policy := r.Policy()
+	if policy == nil {
+		return PolicyDecision{Effect: PolicyAllow}
+	}
+	return policy.Evaluate(call, quest, agent)
+}
+
 // Register adds a tool to the registry.
 func (r *ToolRegistry) Register(tool RegisteredTool) {
 	r.mu.Lock()
@@ -273,6 +441,7 @@ func (r *ToolRegistry) ListAll() []RegisteredTool {
 // - Quest's AllowedTools list (if specified)
 // - Agent's trust tier
 // - Agent's skills
+// - Danger flag and store ownership (see RegisteredTool.Unlocked)
 func (r *ToolRegistry) GetToolsForQuest(quest *domain.Quest, agent *agentprogression.Agent) []agentic.ToolDefinition {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -295,6 +464,11 @@ func (r *ToolRegistry) GetToolsForQuest(quest *domain.Quest, agent *agentprogres
 			continue
 		}
 
+		// Check danger and store ownership gates
+		if !tool.Unlocked(quest, agent) {
+			continue
+		}
+
 		available = append(available, tool.Definition)
 	}
 
@@ -306,6 +480,9 @@ func (r *ToolRegistry) Execute(ctx context.Context, call agentic.ToolCall, quest
 	r.mu.RLock()
 	tool, ok := r.tools[call.Name]
 	sandboxDir := r.sandboxDir
+	policy, approver, secrets := r.policy, r.approver, r.secrets
+	limits, hasLimits := lookupLimits(r.limits, tool)
+	costs, safety := r.costs, r.safety
 	r.mu.RUnlock()
 
 	if !ok {
@@ -331,6 +508,54 @@ func (r *ToolRegistry) Execute(ctx context.Context, call agentic.ToolCall, quest
 		}
 	}
 
+	// Verify danger and store ownership gates
+	if reason := tool.lockReason(quest, agent); reason != "" {
+		return agentic.ToolResult{CallID: call.ID, Error: reason}
+	}
+
+	// Enforce rate limits and quotas before the policy, so the DM is never
+	// asked to approve a call that would be refused anyway. The slot is
+	// refunded if the call is then blocked before it runs.
+	var admitted time.Time
+	if hasLimits {
+		var refused *agentic.ToolResult
+		if admitted, refused = r.usage.admit(call, limits.forTier(agent.Tier), quest, agent); refused != nil {
+			return *refused
+		}
+	}
+	blocked := func(result agentic.ToolResult) agentic.ToolResult {
+		if hasLimits {
+			r.usage.refund(call, quest, agent, admitted)
+		}
+		return result
+	}
+
+	// Apply the tool-call policy. Arguments are matched before the sandbox
+	// directory is injected so rules only see what the agent sent.
+	var decision PolicyDecision
+	if policy != nil {
+		decision = policy.Evaluate(call, quest, agent)
+		if denied := r.enforcePolicy(ctx, call, quest, agent, policy, approver, &decision); denied != nil {
+			return blocked(*denied)
+		}
+		ctx = withPathFilter(ctx, policy, call, quest, agent, decision)
+	}
+
+	// Expand secret placeholders last, so the policy and the DM only ever
+	// see placeholders. The expanded call is a copy; the caller's arguments
+	// keep the placeholders.
+	var values secretValues
+	if len(tool.SecretArgs) > 0 {
+		var err error
+		call, values, err = expandSecrets(ctx, secrets, tool.SecretArgs, call, quest, agent)
+		if err != nil {
+			return blocked(agentic.ToolResult{CallID: call.ID, Error: err.Error()})
+		}
+		if len(values) > 0 {
+			ctx = withSecretValues(ctx, values)
+		}
+	}
+
 	// Inject sandbox directory into call metadata for handlers
 	if sandboxDir != "" {
 		if call.Arguments == nil {
@@ -340,7 +565,64 @@ func (r *ToolRegistry) Execute(ctx context.Context, call agentic.ToolCall, quest
 	}
 
 	// Execute the handler
-	return tool.Handler(ctx, call, quest, agent)
+	start := time.Now()
+	result := tool.Handler(ctx, call, quest, agent)
+	r.usage.record(call.Name, result, time.Since(start), limits.CostUSD)
+	if costs != nil && limits.CostUSD > 0 {
+		costs.RecordCost(ctx, limits.CostUSD, "tool:"+call.Name)
+	}
+	// Quarantine fetched content before secrets are redacted from it, so
+	// the scan sees what the tool actually returned.
+	if safety != nil && safety.covers(call.Name) {
+		safety.quarantine(call, &result)
+	}
+	if policy != nil {
+		stampPolicyDecision(&result, decision)
+	}
+	if len(values) > 0 {
+		values.redactResult(&result)
+		if result.Metadata == nil {
+			result.Metadata = make(map[string]any)
+		}
+		result.Metadata["secrets_used"] = values.names()
+	}
+	return result
+}
+
+// enforcePolicy returns a denial result when the decision blocks the call.
+// require_approval waits for the approver; an approval is recorded on the
+// decision so the trajectory shows who let the call through.
+func (r *ToolRegistry) enforcePolicy(ctx context.Context, call agentic.ToolCall, quest *domain.Quest, agent *agentprogression.Agent, policy *ToolPolicy, approver ToolCallApprover, decision *PolicyDecision) *agentic.ToolResult {
+	switch decision.Effect {
+	case PolicyDeny:
+		msg := fmt.Sprintf("tool call denied by policy rule %s", decision.Rule)
+		if decision.Rule == "" {
+			msg = "tool call denied by the default policy"
+		}
+		if decision.Reason != "" {
+			msg += ": " + decision.Reason
+		}
+		result := agentic.ToolResult{CallID: call.ID, Error: msg}
+		stampPolicyDecision(&result, *decision)
+		return &result
+
+	case PolicyRequireApproval:
+		resp, reason := requestToolApproval(ctx, approver, policy.ApprovalTimeoutDuration(), call, quest, agent, *decision)
+		if reason != "" {
+			result := agentic.ToolResult{
+				CallID: call.ID,
+				Error:  fmt.Sprintf("tool call needs DM approval under policy rule %s and was not approved: %s", decision.Rule, reason),
+			}
+			stampPolicyDecision(&result, *decision)
+			result.Metadata["policy_approved"] = false
+			return &result
+		}
+		decision.approvedBy = resp.RespondedBy
+		if decision.approvedBy == "" {
+			decision.approvedBy = "dm"
+		}
+	}
+	return nil
 }
 
 // agentHasAnySkill returns true if the agent has at least one of the given skills.
@@ -382,6 +664,7 @@ func (r *ToolRegistry) RegisterBuiltins() {
 		Skills:     runCommandSpec.Skills,
 		MinTier:    runCommandSpec.MinTier,
 		Category:   runCommandSpec.Category,
+		SecretArgs: runCommandSpec.SecretArgs,
 	})
 
 	r.Register(RegisteredTool{
@@ -390,8 +673,13 @@ func (r *ToolRegistry) RegisterBuiltins() {
 		Skills:     httpRequestSpec.Skills,
 		MinTier:    httpRequestSpec.MinTier,
 		Category:   httpRequestSpec.Category,
+		SecretArgs: httpRequestSpec.SecretArgs,
+		Limits:     httpRequestSpec.Limits,
 	})
 
+	r.registerFileTools(localWorkspaceFor)
+	r.registerTestTool(localTestRunner)
+
 	// Terminal tools — these stop the agentic loop on successful execution.
 	// submit_work replaces [INTENT: work_product] tags.
 	// ask_clarification replaces [INTENT: clarification] tags.
@@ -410,6 +698,10 @@ func (r *ToolRegistry) RegisterBuiltins() {
 						"type":        "string",
 						"description": "Optional inline content for non-file work (analysis, research findings). Omit this when your work is in files — they are captured automatically.",
 					},
+					"output": map[string]any{
+						"type":        "object",
+						"description": "Structured result. Required when your quest declares an output contract — it must match the contract's JSON Schema.",
+					},
 				},
 				"required": []any{},
 			},
@@ -444,7 +736,7 @@ func (r *ToolRegistry) RegisterBuiltins() {
 		r.Register(RegisteredTool{
 			Definition: def,
 			Handler: func(ctx context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
-				result, err := decomposeExec.Execute(ctx, call)
+				result, err := decomposeExec.WithBlueprints(dagBlueprints).Execute(ctx, call)
 				if err != nil {
 					return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("decompose_quest internal error: %v", err)}
 				}
@@ -497,6 +789,28 @@ func (r *ToolRegistry) RegisterBuiltins() {
 		})
 	}
 
+	amendExec := questdagexec.NewAmendExecutor()
+	for _, def := range amendExec.ListTools() {
+		r.Register(RegisteredTool{
+			Definition: def,
+			Handler: func(ctx context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
+				result, err := amendExec.Execute(ctx, call)
+				if err != nil {
+					return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("amend_dag internal error: %v", err)}
+				}
+				// Stop the lead loop after the amendment. The amendment JSON in
+				// result.Content is the loop's final output; questdagexec applies
+				// it and re-dispatches the interrupted review or clarification.
+				if result.Error == "" {
+					result.StopLoop = true
+				}
+				return result
+			},
+			MinTier:  domain.TierMaster, // Level 16+ — only party leads (Master+) can amend a DAG
+			Category: ToolCategoryPartyLead,
+		})
+	}
+
 	// submit_findings is the terminal tool for explore sub-agents.
 	// It works identically to submit_work but is scoped to read-only research loops.
 	r.Register(RegisteredTool{
@@ -628,6 +942,18 @@ func makeWebSearchHandler(provider SearchProvider) ToolHandler {
 			maxResults = int(mr)
 		}
 
+		// Identical searches are shared across agents while fresh.
+		cache := webCache
+		var cacheKey string
+		if cache != nil && cache.searchTTL > 0 {
+			cacheKey = searchCacheKey(provider.Name(), query, maxResults)
+			if entry, content := cache.lookup(ctx, cacheKey); entry != nil && cache.fresh(entry) {
+				result := agentic.ToolResult{CallID: call.ID, Content: content}
+				stampCache(&result, CacheHit, entry, cache.now())
+				return result
+			}
+		}
+
 		reqCtx, cancel := context.WithTimeout(ctx, httpRequestTimeout)
 		defer cancel()
 
@@ -636,10 +962,20 @@ func makeWebSearchHandler(provider SearchProvider) ToolHandler {
 			return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("web search failed: %v", err)}
 		}
 
-		return agentic.ToolResult{
+		result := agentic.ToolResult{
 			CallID:  call.ID,
 			Content: formatSearchResults(results, query),
 		}
+		if cacheKey != "" {
+			stampCache(&result, CacheMiss, nil, cache.now())
+			// Empty result sets are often transient; only cache real answers.
+			if len(results) > 0 {
+				if err := cache.store(ctx, webCacheEntry{Key: cacheKey}, result.Content, cache.searchTTL); err != nil {
+					slog.Debug("failed to store web search cache entry", "query", query, "error", err)
+				}
+			}
+		}
+		return result
 	}
 }
 
@@ -647,16 +983,41 @@ func makeWebSearchHandler(provider SearchProvider) ToolHandler {
 // TERMINAL TOOL HANDLERS (submit_work, ask_clarification)
 // =============================================================================
 
-func submitWorkProductHandler(_ context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
+func submitWorkProductHandler(_ context.Context, call agentic.ToolCall, quest *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
 	summary, _ := call.Arguments["summary"].(string)
 	deliverable, _ := call.Arguments["deliverable"].(string)
 
+	// DAG sub-quests with an output contract must submit structured output
+	// that matches it. Rejecting here keeps the loop running so the member
+	// fixes the output without spending a lead review.
+	var output any
+	if quest != nil && quest.DAGOutputSchema != nil {
+		schema := questdagexec.OutputSchemaFromAny(quest.DAGOutputSchema)
+		output = submittedOutput(call.Arguments, deliverable)
+		if output == nil {
+			return agentic.ToolResult{CallID: call.ID, Error: "this quest declares an output contract — pass your structured result in the output argument, matching this JSON Schema: " + schemaJSON(schema)}
+		}
+		if errs := questdagexec.ValidateOutput(schema, output); len(errs) > 0 {
+			return agentic.ToolResult{
+				CallID: call.ID,
+				Error: "output does not match the quest's output contract:\n- " + strings.Join(errs, "\n- ") +
+					"\nFix the output and call submit_work again. Schema: " + schemaJSON(schema),
+			}
+		}
+		// The serialized output doubles as the deliverable the lead reviews.
+		if deliverable == "" {
+			if data, err := json.Marshal(output); err == nil {
+				deliverable = string(data)
+			}
+		}
+	}
+
 	if summary == "" && deliverable == "" {
 		return agentic.ToolResult{CallID: call.ID, Error: "at least one of summary or deliverable is required"}
 	}
 
 	// When deliverable is provided, check if it's actually a question.
-	if deliverable != "" && looksLikeQuestion(deliverable) {
+	if deliverable != "" && output == nil && looksLikeQuestion(deliverable) {
 		return agentic.ToolResult{
 			CallID: call.ID,
 			Error: "Your deliverable appears to be a question or request for information, not completed work. " +
@@ -674,7 +1035,7 @@ func submitWorkProductHandler(_ context.Context, call agentic.ToolCall, _ *domai
 		}
 	}
 
-	result := map[string]string{
+	result := map[string]any{
 		"type": "work_product",
 	}
 	if deliverable != "" {
@@ -683,6 +1044,9 @@ func submitWorkProductHandler(_ context.Context, call agentic.ToolCall, _ *domai
 	if summary != "" {
 		result["summary"] = summary
 	}
+	if output != nil {
+		result["output"] = output
+	}
 
 	jsonBytes, _ := json.Marshal(result)
 	return agentic.ToolResult{
@@ -692,6 +1056,40 @@ func submitWorkProductHandler(_ context.Context, call agentic.ToolCall, _ *domai
 	}
 }
 
+// submittedOutput returns the structured output of a submit_work call: the
+// output argument when present, otherwise the deliverable if it parses as
+// JSON. Models often put the JSON in the deliverable instead of the output
+// argument; accepting both avoids a pointless bounce.
+func submittedOutput(args map[string]any, deliverable string) any {
+	if output, ok := args["output"]; ok && output != nil {
+		// Some providers send nested objects as JSON strings.
+		if s, isString := output.(string); isString {
+			var parsed any
+			if json.Unmarshal([]byte(s), &parsed) == nil {
+				return parsed
+			}
+		}
+		return output
+	}
+	if deliverable == "" {
+		return nil
+	}
+	var parsed any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(deliverable)), &parsed); err != nil {
+		return nil
+	}
+	return parsed
+}
+
+// schemaJSON renders a schema compactly for inclusion in tool error messages.
+func schemaJSON(schema map[string]any) string {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
 // looksLikeQuestion detects deliverables that are actually questions or requests
 // for information rather than completed work. This catches agents (especially
 // smaller models) that use submit_work instead of ask_clarification.
@@ -818,14 +1216,32 @@ var httpToolClient = &http.Client{
 			return (&net.Dialer{Timeout: 10 * time.Second}).DialContext(ctx, network, net.JoinHostPort(host, port))
 		},
 	},
-	CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
 		if len(via) >= 5 {
 			return fmt.Errorf("stopped after 5 redirects")
 		}
+		// Go forwards custom headers such as X-Api-Key across hosts, and
+		// 307/308 resend the body, so a request carrying secrets stays on
+		// the host its secrets were scoped to, and on https if it started
+		// there.
+		if len(secretValuesFrom(req.Context())) > 0 {
+			first := via[0].URL
+			if !sameHost(req.URL, first) || (first.Scheme == "https" && req.URL.Scheme != "https") {
+				return fmt.Errorf("redirect to %s://%s refused: the request carries secrets scoped to %s",
+					req.URL.Scheme, req.URL.Host, first.Host)
+			}
+		}
 		return nil
 	},
 }
 
+// sameHost reports whether a and b name the same host, ignoring case and a
+// trailing dot.
+func sameHost(a, b *url.URL) bool {
+	normalize := func(u *url.URL) string { return strings.ToLower(strings.TrimSuffix(u.Hostname(), ".")) }
+	return normalize(a) == normalize(b) && a.Port() == b.Port()
+}
+
 func httpRequestHandler(ctx context.Context, call agentic.ToolCall, _ *domain.Quest, _ *agentprogression.Agent) agentic.ToolResult {
 	select {
 	case <-ctx.Done():
@@ -849,11 +1265,26 @@ func httpRequestHandler(ctx context.Context, call agentic.ToolCall, _ *domain.Qu
 		return agentic.ToolResult{CallID: call.ID, Error: "method must be GET or POST"}
 	}
 
+	headers, err := requestHeaders(call)
+	if err != nil {
+		return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+	}
+
 	var reqBody io.Reader
 	if body, ok := call.Arguments["body"].(string); ok && body != "" {
 		reqBody = strings.NewReader(body)
 	}
 
+	formatArg, _ := call.Arguments["format"].(string)
+	format := httpformat.ParseFormat(formatArg)
+
+	// Plain GETs are served from the shared cache while fresh. Requests with
+	// extra headers may be personalized (or carry secrets), so they bypass it.
+	pc := lookupPageCache(ctx, method, urlStr, string(format), headers)
+	if pc.fresh() {
+		return pc.hit(call)
+	}
+
 	reqCtx, cancel := context.WithTimeout(ctx, httpRequestTimeout)
 	defer cancel()
 
@@ -871,6 +1302,10 @@ func httpRequestHandler(ctx context.Context, call agentic.ToolCall, _ *domain.Qu
 		}
 		req.Header.Set("Content-Type", contentType)
 	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	pc.setValidators(req)
 
 	resp, err := httpToolClient.Do(req)
 	if err != nil {
@@ -878,19 +1313,26 @@ func httpRequestHandler(ctx context.Context, call agentic.ToolCall, _ *domain.Qu
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseSize+1))
-	if err != nil {
-		return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("failed to read response: %v", err)}
+	if pc.notModified(resp) {
+		return pc.revalidated(ctx, call)
 	}
 
 	contentType := resp.Header.Get("Content-Type")
-	formatArg, _ := call.Arguments["format"].(string)
-	format := httpformat.ParseFormat(formatArg)
+
+	readLimit := int64(maxHTTPResponseSize)
+	if format != httpformat.FormatRaw && httpformat.Extractable(contentType, urlStr) {
+		readLimit = maxHTTPDocumentSize
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, readLimit+1))
+	if err != nil {
+		return agentic.ToolResult{CallID: call.ID, Error: fmt.Sprintf("failed to read response: %v", err)}
+	}
 
 	// httpformat.Render handles HTML→Readability→markdown for text/html
-	// responses and passes everything else through untouched, capped at
-	// httpTextMaxSize. When the LLM agent passes format=raw or the
-	// content is non-HTML, the body is returned with only a length cap.
+	// responses, extracts text from documents it recognizes (PDF, feeds,
+	// OpenAPI specs, notebooks, JSON), and passes everything else through
+	// untouched, capped at httpTextMaxSize. When the LLM agent passes
+	// format=raw, the body is returned with only a length cap.
 	result := httpformat.Render(body, contentType, urlStr, format, httpTextMaxSize)
 
 	// Knowledge-graph persistence path is preserved: only HTML responses
@@ -898,18 +1340,27 @@ func httpRequestHandler(ctx context.Context, call agentic.ToolCall, _ *domain.Qu
 	// indexing. The persist payload is the markdown view (format=markdown)
 	// regardless of what view the agent requested for this call — the
 	// graph store is shared, the agent's per-call view is not.
+	// Cached pages are persisted the same way, so the cache entry can name
+	// the graph entity holding the page.
+	var graphEntity string
 	if httpGraphPersist != nil && strings.Contains(strings.ToLower(contentType), "text/html") {
 		title := extractTitle(bytes.NewReader(body))
-		persistText := httpformat.Render(body, contentType, urlStr, httpformat.FormatMarkdown, httpTextMaxSize)
+		// Pages fetched with a secret header may echo it; the graph is
+		// shared, so redact before persisting.
+		persistText := secretValuesFrom(ctx).redact(
+			httpformat.Render(body, contentType, urlStr, httpformat.FormatMarkdown, httpTextMaxSize))
 		if len(persistText) >= minHTTPPersistLength {
+			graphEntity, _ = webContentEntityID(httpGraphPersist.config, urlStr, title)
 			go persistWebContent(httpGraphPersist, urlStr, title, persistText, call)
 		}
 	}
 
-	return agentic.ToolResult{
+	toolResult := agentic.ToolResult{
 		CallID:  call.ID,
 		Content: fmt.Sprintf("HTTP %d %s\n\n%s", resp.StatusCode, resp.Status, result),
 	}
+	pc.storeResponse(ctx, resp.StatusCode, resp.Header, toolResult.Content, graphEntity, &toolResult)
+	return toolResult
 }
 
 // webContentEntity is a thin Graphable wrapper for a fetched web document.
@@ -927,22 +1378,7 @@ func (e *webContentEntity) Triples() []message.Triple { return e.triples }
 // source.doc entity. This runs in a goroutine; failures are logged and ignored
 // so they never block or surface to the agent.
 func persistWebContent(p *graphPersister, rawURL, title, content string, call agentic.ToolCall) {
-	urlHash := fmt.Sprintf("%x", sha256.Sum256([]byte(rawURL)))[:6]
-
-	effectiveTitle := title
-	if effectiveTitle == "" {
-		if u, err := url.Parse(rawURL); err == nil {
-			effectiveTitle = u.Host + u.Path
-		} else {
-			effectiveTitle = rawURL
-		}
-	}
-
-	slug := slugify(effectiveTitle, 40)
-	instance := slug + "-" + urlHash
-
-	entityID := fmt.Sprintf("%s.%s.web.agent.doc.%s",
-		p.config.Org, p.config.Platform, instance)
+	entityID, effectiveTitle := webContentEntityID(p.config, rawURL, title)
 
 	summary := content
 	if len(summary) > 200 {
@@ -977,6 +1413,34 @@ func persistWebContent(p *graphPersister, rawURL, title, content string, call ag
 		slog.Debug("failed to persist web content to graph",
 			"url", rawURL, "entity_id", entityID, "error", err)
 	}
+	if index := webContentIndex; index != nil {
+		index.Add(LocalDocument{URL: rawURL, Title: effectiveTitle, Text: content})
+	}
+}
+
+// webContentEntityID returns the source.doc entity ID for a fetched page and
+// the title it is stored under (the page title, or host and path).
+func webContentEntityID(cfg *domain.BoardConfig, rawURL, title string) (string, string) {
+	urlHash := fmt.Sprintf("%x", sha256.Sum256([]byte(rawURL)))[:6]
+
+	effectiveTitle := title
+	if effectiveTitle == "" {
+		if u, err := url.Parse(rawURL); err == nil {
+			effectiveTitle = u.Host + u.Path
+		} else {
+			effectiveTitle = rawURL
+		}
+	}
+
+	slug := slugify(effectiveTitle, 40)
+	instance := slug + "-" + urlHash
+
+	return webContentIDPrefix(cfg) + instance, effectiveTitle
+}
+
+// webContentIDPrefix is the entity ID prefix shared by persisted web pages.
+func webContentIDPrefix(cfg *domain.BoardConfig) string {
+	return fmt.Sprintf("%s.%s.web.agent.doc.", cfg.Org, cfg.Platform)
 }
 
 // cappedWriter limits how many bytes are buffered in memory.
@@ -1000,6 +1464,62 @@ func (w *cappedWriter) Write(p []byte) (int, error) {
 func (w *cappedWriter) String() string { return w.buf.String() }
 func (w *cappedWriter) Len() int       { return w.buf.Len() }
 
+// envNamePattern matches portable environment variable names.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// commandEnv returns the bash "env" argument, validating variable names.
+func commandEnv(call agentic.ToolCall) (map[string]string, error) {
+	env, err := stringMapArg(call, "env")
+	if err != nil {
+		return nil, err
+	}
+	for name := range env {
+		if !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment variable name %q", name)
+		}
+	}
+	return env, nil
+}
+
+// requestHeaders returns the http_request "headers" argument, validating
+// header names and values.
+func requestHeaders(call agentic.ToolCall) (map[string]string, error) {
+	headers, err := stringMapArg(call, "headers")
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		if !httpguts.ValidHeaderFieldValue(value) {
+			return nil, fmt.Errorf("invalid value for header %s", name)
+		}
+	}
+	return headers, nil
+}
+
+// stringMapArg returns an object argument whose values must all be strings.
+func stringMapArg(call agentic.ToolCall, name string) (map[string]string, error) {
+	raw, ok := call.Arguments[name]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	fields, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object of strings", name)
+	}
+	out := make(map[string]string, len(fields))
+	for k, v := range fields {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s.%s must be a string", name, k)
+		}
+		out[k] = s
+	}
+	return out, nil
+}
+
 // runShellCommand executes a shell command in the sandbox directory.
 func runShellCommand(ctx context.Context, call agentic.ToolCall, timeout time.Duration) agentic.ToolResult {
 	select {
@@ -1018,23 +1538,32 @@ func runShellCommand(ctx context.Context, call agentic.ToolCall, timeout time.Du
 		return agentic.ToolResult{CallID: call.ID, Error: "shell commands require a configured sandbox directory"}
 	}
 
+	env, err := commandEnv(call)
+	if err != nil {
+		return agentic.ToolResult{CallID: call.ID, Error: err.Error()}
+	}
+
 	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(cmdCtx, "sh", "-c", command)
 	cmd.Dir = sandboxDir
-	// Clean environment — only pass through PATH and HOME for basic operation.
+	// Clean environment — only pass through PATH and HOME for basic operation,
+	// plus any variables the call asked for.
 	cmd.Env = []string{
 		"PATH=" + os.Getenv("PATH"),
 		"HOME=" + os.Getenv("HOME"),
 	}
+	for name, value := range env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
 
 	stdout := &cappedWriter{max: maxCommandOutput}
 	stderr := &cappedWriter{max: maxCommandOutput}
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
-	err := cmd.Run()
+	err = cmd.Run()
 
 	var result strings.Builder
 	if stdout.Len() > 0 {
@@ -1179,6 +1708,7 @@ func (r *ToolRegistry) RegisterWebSearch(provider SearchProvider) {
 		Skills:   []domain.SkillTag{domain.SkillResearch},
 		MinTier:  domain.TierApprentice,
 		Category: ToolCategoryNetwork,
+		Limits:   &ToolLimits{PerMinute: 10, PerQuest: 50},
 	})
 }
 
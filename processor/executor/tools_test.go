@@ -38,14 +38,23 @@ func TestBuiltinToolTierAlignment(t *testing.T) {
 		{tool: "ask_clarification", wantTier: domain.TierApprentice, reason: "all tiers can ask questions"},
 		{tool: "submit_findings", wantTier: domain.TierApprentice, reason: "all tiers can submit explore findings"},
 
+		// Apprentice — file tools are confined to the quest workspace.
+		{tool: "read_file", wantTier: domain.TierApprentice, reason: "workspace-confined read"},
+		{tool: "list_files", wantTier: domain.TierApprentice, reason: "workspace-confined listing"},
+		{tool: "search_code", wantTier: domain.TierApprentice, reason: "workspace-confined search"},
+		{tool: "write_file", wantTier: domain.TierApprentice, reason: "workspace-confined write"},
+		{tool: "apply_patch", wantTier: domain.TierApprentice, reason: "workspace-confined edit"},
+
 		// Journeyman — network access and shell execution require demonstrated trust.
 		{tool: "http_request", wantTier: domain.TierJourneyman, reason: "network access requires level 6+"},
 		{tool: "bash", wantTier: domain.TierJourneyman, reason: "sandbox-constrained shell execution"},
+		{tool: "run_tests", wantTier: domain.TierJourneyman, reason: "executes workspace code, same trust as bash"},
 
 		// Master — party-lead DAG operations require level 16+.
 		{tool: "decompose_quest", wantTier: domain.TierMaster, reason: "only party leads (Master+) can decompose quests"},
 		{tool: "review_sub_quest", wantTier: domain.TierMaster, reason: "only party leads (Master+) can review sub-quests"},
 		{tool: "answer_clarification", wantTier: domain.TierMaster, reason: "only party leads (Master+) can answer clarifications"},
+		{tool: "amend_dag", wantTier: domain.TierMaster, reason: "only party leads (Master+) can amend a DAG"},
 	}
 
 	reg := NewToolRegistry()
@@ -82,14 +91,16 @@ func TestBuiltinToolCount(t *testing.T) {
 
 	// RegisterBuiltins registers:
 	//   submit_work, ask_clarification, submit_findings — 3 terminal tools (Apprentice)
-	//   http_request, bash                              — 2 Journeyman tools
+	//   read_file, write_file, apply_patch,
+	//   search_code, list_files                         — 5 file tools (Apprentice)
+	//   http_request, bash, run_tests                   — 3 Journeyman tools
 	//   decompose_quest, review_sub_quest,
-	//   answer_clarification                            — 3 DAG tools (Master)
+	//   answer_clarification, amend_dag                 — 4 DAG tools (Master)
 	//
 	// web_search is excluded — registered conditionally via RegisterWebSearch.
 	// graph_query is excluded — requires a live EntityQueryFunc (RegisterGraphQuery).
 	// explore is excluded — registered separately via RegisterExplore (questtools Start).
-	const wantCount = 8
+	const wantCount = 15
 
 	reg := NewToolRegistry()
 	reg.RegisterBuiltins()
@@ -295,6 +306,83 @@ func TestSubmitWorkProductHandler(t *testing.T) {
 	}
 }
 
+// TestSubmitWorkProductHandler_OutputContract verifies that DAG sub-quests
+// with an output contract must submit conforming structured output: failures
+// keep the loop running with the validation errors, successes carry the
+// output in the work_product envelope.
+func TestSubmitWorkProductHandler_OutputContract(t *testing.T) {
+	t.Parallel()
+
+	reg := NewToolRegistry()
+	reg.RegisterBuiltins()
+
+	agent := &agentprogression.Agent{Tier: domain.TierApprentice}
+	quest := &domain.Quest{DAGOutputSchema: map[string]any{
+		"type":     "object",
+		"required": []any{"apis"},
+		"properties": map[string]any{
+			"apis": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	}}
+
+	cases := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{
+			name: "output argument",
+			args: map[string]any{"summary": "Listed APIs", "output": map[string]any{"apis": []any{"billing"}}},
+		},
+		{
+			name: "JSON deliverable",
+			args: map[string]any{"deliverable": `{"apis":["billing","users"]}`},
+		},
+		{
+			name:    "missing output",
+			args:    map[string]any{"summary": "Listed the APIs"},
+			wantErr: "output argument",
+		},
+		{
+			name:    "non-conforming output",
+			args:    map[string]any{"output": map[string]any{"apis": []any{1.0}}},
+			wantErr: "$.apis[0]: expected string, got number",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := reg.Execute(context.Background(), makeToolCall("submit_work", tc.args), quest, agent)
+			if tc.wantErr != "" {
+				assertContains(t, result.Error, tc.wantErr)
+				if result.StopLoop {
+					t.Error("rejected submission must not stop the loop")
+				}
+				return
+			}
+
+			if result.Error != "" {
+				t.Fatalf("unexpected error: %s", result.Error)
+			}
+			var payload struct {
+				Deliverable string         `json:"deliverable"`
+				Output      map[string]any `json:"output"`
+			}
+			if err := json.Unmarshal([]byte(result.Content), &payload); err != nil {
+				t.Fatalf("Content is not valid JSON: %v", err)
+			}
+			if payload.Output["apis"] == nil {
+				t.Errorf("output missing from envelope: %s", result.Content)
+			}
+			if payload.Deliverable == "" {
+				t.Error("deliverable should default to the serialized output")
+			}
+		})
+	}
+}
+
 // TestAskClarificationHandler verifies the ask_clarification terminal tool:
 // valid questions produce JSON with type=clarification and StopLoop=true;
 // missing or empty question returns an error.
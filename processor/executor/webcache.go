@@ -0,0 +1,388 @@
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c360studio/semstreams/agentic"
+	"github.com/c360studio/semstreams/natsclient"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// =============================================================================
+// WEB CACHE
+// =============================================================================
+// The web cache shares http_request and web_search results across agents.
+// Entries are keyed by a normalized request (URL + format, or provider +
+// query) and point at content stored under its SHA-256, so identical
+// responses are stored once. Fresh entries are served directly; stale page
+// entries with an ETag or Last-Modified are revalidated with a conditional
+// request, and a 304 renews them without re-downloading. Cached results
+// carry cache metadata so the trajectory shows what was served from cache.
+// =============================================================================
+
+// WebCacheBucket is the KV bucket holding cache entries and content.
+const WebCacheBucket = "WEB_CACHE"
+
+// Web cache defaults.
+const (
+	defaultWebCacheTTL       = time.Hour
+	defaultSearchCacheTTL    = 6 * time.Hour
+	defaultWebCacheRetention = 7 * 24 * time.Hour
+)
+
+// Cache status values stamped on results as "cache" metadata.
+const (
+	CacheHit         = "hit"
+	CacheMiss        = "miss"
+	CacheRevalidated = "revalidated"
+)
+
+// WebCacheConfig configures the shared web cache. All durations are Go
+// duration strings.
+type WebCacheConfig struct {
+	// TTL is how long fetched pages are served without revalidation. Default "1h".
+	TTL string `json:"ttl,omitempty"`
+	// SearchTTL is how long web_search results are reused. Default "6h".
+	SearchTTL string `json:"search_ttl,omitempty"`
+	// DomainTTL overrides TTL for hosts matching a glob ("docs.python.org",
+	// "*.github.io"). "0" disables caching for matching hosts.
+	DomainTTL map[string]string `json:"domain_ttl,omitempty"`
+	// Retention is how long entries are kept for revalidation after they go
+	// stale. Default "168h".
+	Retention string `json:"retention,omitempty"`
+}
+
+// webCacheEntry describes one cached request.
+type webCacheEntry struct {
+	Key          string    `json:"key"` // normalized request
+	ContentHash  string    `json:"content_hash"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	GraphEntity  string    `json:"graph_entity,omitempty"` // source.doc entity for persisted pages
+	FetchedAt    time.Time `json:"fetched_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// domainTTL is a parsed DomainTTL override.
+type domainTTL struct {
+	pattern string
+	ttl     time.Duration
+}
+
+// WebCache is a TTL cache for web tool results backed by a KV bucket.
+type WebCache struct {
+	bucket    jetstream.KeyValue
+	ttl       time.Duration
+	searchTTL time.Duration
+	domains   []domainTTL // most specific pattern first
+	now       func() time.Time
+}
+
+// webCache is the process-wide cache used by http_request and web_search.
+// Nil disables caching (the default).
+var webCache *WebCache
+
+// SetWebCache enables the shared web cache. Pass nil to disable it.
+func SetWebCache(c *WebCache) { webCache = c }
+
+// NewWebCache creates a cache over an existing bucket.
+func NewWebCache(bucket jetstream.KeyValue, cfg WebCacheConfig) (*WebCache, error) {
+	ttl, err := parseCacheDuration(cfg.TTL, defaultWebCacheTTL, "ttl")
+	if err != nil {
+		return nil, err
+	}
+	searchTTL, err := parseCacheDuration(cfg.SearchTTL, defaultSearchCacheTTL, "search_ttl")
+	if err != nil {
+		return nil, err
+	}
+	c := &WebCache{bucket: bucket, ttl: ttl, searchTTL: searchTTL, now: time.Now}
+	for pattern, raw := range cfg.DomainTTL {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("web cache domain_ttl %q: bad pattern: %w", pattern, err)
+		}
+		d, err := parseCacheDuration(raw, 0, "domain_ttl "+pattern)
+		if err != nil {
+			return nil, err
+		}
+		c.domains = append(c.domains, domainTTL{pattern: strings.ToLower(pattern), ttl: d})
+	}
+	sort.Slice(c.domains, func(i, j int) bool {
+		if len(c.domains[i].pattern) != len(c.domains[j].pattern) {
+			return len(c.domains[i].pattern) > len(c.domains[j].pattern)
+		}
+		return c.domains[i].pattern < c.domains[j].pattern
+	})
+	return c, nil
+}
+
+// OpenWebCache creates or attaches to the WEB_CACHE bucket and returns a
+// cache over it. Entries older than the retention period are dropped by the
+// bucket itself.
+func OpenWebCache(ctx context.Context, nats *natsclient.Client, cfg WebCacheConfig) (*WebCache, error) {
+	retention, err := parseCacheDuration(cfg.Retention, defaultWebCacheRetention, "retention")
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := nats.CreateKeyValueBucket(ctx, jetstream.KeyValueConfig{
+		Bucket:      WebCacheBucket,
+		Description: "Shared http_request and web_search results",
+		History:     1,
+		TTL:         retention,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("web cache bucket: %w", err)
+	}
+	return NewWebCache(bucket, cfg)
+}
+
+func parseCacheDuration(raw string, def time.Duration, field string) (time.Duration, error) {
+	if raw == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("web cache %s: invalid duration %q", field, raw)
+	}
+	return d, nil
+}
+
+// pageTTL returns how long a page from host stays fresh. Zero means the
+// host is not cached.
+func (c *WebCache) pageTTL(host string) time.Duration {
+	host = strings.ToLower(host)
+	for _, d := range c.domains {
+		if ok, _ := path.Match(d.pattern, host); ok {
+			return d.ttl
+		}
+	}
+	return c.ttl
+}
+
+// lookup returns the entry and content for key. A missing entry, or one
+// whose content has been dropped, returns nil.
+func (c *WebCache) lookup(ctx context.Context, key string) (*webCacheEntry, string) {
+	kv, err := c.bucket.Get(ctx, entryKey(key))
+	if err != nil {
+		return nil, ""
+	}
+	var entry webCacheEntry
+	if json.Unmarshal(kv.Value(), &entry) != nil || entry.Key != key {
+		return nil, ""
+	}
+	blob, err := c.bucket.Get(ctx, "blob."+entry.ContentHash)
+	if err != nil {
+		return nil, ""
+	}
+	return &entry, string(blob.Value())
+}
+
+// fresh reports whether entry can be served without revalidation.
+func (c *WebCache) fresh(entry *webCacheEntry) bool {
+	return c.now().Before(entry.ExpiresAt)
+}
+
+// store saves content and points key at it, fresh for ttl.
+func (c *WebCache) store(ctx context.Context, entry webCacheEntry, content string, ttl time.Duration) error {
+	sum := sha256.Sum256([]byte(content))
+	entry.ContentHash = hex.EncodeToString(sum[:])
+	if _, err := c.bucket.Put(ctx, "blob."+entry.ContentHash, []byte(content)); err != nil {
+		return fmt.Errorf("store cached content: %w", err)
+	}
+	return c.renew(ctx, entry, ttl)
+}
+
+// renew writes entry with a fresh expiry.
+func (c *WebCache) renew(ctx context.Context, entry webCacheEntry, ttl time.Duration) error {
+	entry.FetchedAt = c.now()
+	entry.ExpiresAt = entry.FetchedAt.Add(ttl)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := c.bucket.Put(ctx, entryKey(entry.Key), data); err != nil {
+		return fmt.Errorf("store cache entry: %w", err)
+	}
+	return nil
+}
+
+// entryKey maps a normalized request onto a KV-safe key.
+func entryKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "entry." + hex.EncodeToString(sum[:])
+}
+
+// pageCacheKey is the cache key for a GET of rawURL rendered in format.
+func pageCacheKey(rawURL, format string) (string, bool) {
+	u, ok := normalizeCacheURL(rawURL)
+	if !ok {
+		return "", false
+	}
+	return "page " + format + " " + u, true
+}
+
+// searchCacheKey is the cache key for a web_search call.
+func searchCacheKey(provider, query string, maxResults int) string {
+	query = strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	return fmt.Sprintf("search %s %d %s", provider, maxResults, query)
+}
+
+// normalizeCacheURL lowercases the scheme and host, drops default ports
+// and fragments, and sorts query parameters so equivalent URLs share an
+// entry.
+func normalizeCacheURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.RawQuery = u.Query().Encode()
+	return u.String(), true
+}
+
+// stampCache records how a result was served.
+func stampCache(result *agentic.ToolResult, status string, entry *webCacheEntry, now time.Time) {
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]any)
+	}
+	result.Metadata["cache"] = status
+	if entry == nil {
+		return
+	}
+	if status != CacheMiss {
+		result.Metadata["cache_age_s"] = int(now.Sub(entry.FetchedAt).Seconds())
+	}
+	if entry.GraphEntity != "" {
+		result.Metadata["graph_entity"] = entry.GraphEntity
+	}
+}
+
+// cacheableResponse reports whether Cache-Control allows a shared cache to
+// keep the response.
+func cacheableResponse(cacheControl string) bool {
+	for _, d := range strings.Split(strings.ToLower(cacheControl), ",") {
+		switch strings.TrimSpace(d) {
+		case "no-store", "private":
+			return false
+		}
+	}
+	return true
+}
+
+// =============================================================================
+// PAGE LOOKUPS
+// =============================================================================
+
+// pageLookup is the cache state for one http_request call. A nil
+// *pageLookup means the call is not cacheable; every method is nil-safe.
+type pageLookup struct {
+	cache   *WebCache
+	key     string
+	ttl     time.Duration
+	entry   *webCacheEntry // nil on a miss
+	content string
+}
+
+// lookupPageCache returns the cache state for a request, or nil when the
+// cache is off or the request is not a plain GET to a cached host.
+func lookupPageCache(ctx context.Context, method, rawURL, format string, headers map[string]string) *pageLookup {
+	c := webCache
+	if c == nil || method != http.MethodGet || len(headers) > 0 {
+		return nil
+	}
+	key, ok := pageCacheKey(rawURL, format)
+	if !ok {
+		return nil
+	}
+	u, _ := url.Parse(rawURL)
+	ttl := c.pageTTL(u.Hostname())
+	if ttl <= 0 {
+		return nil
+	}
+	p := &pageLookup{cache: c, key: key, ttl: ttl}
+	p.entry, p.content = c.lookup(ctx, key)
+	return p
+}
+
+// fresh reports whether the cached content can be served as is.
+func (p *pageLookup) fresh() bool {
+	return p != nil && p.entry != nil && p.cache.fresh(p.entry)
+}
+
+// hit returns the cached content as the call's result.
+func (p *pageLookup) hit(call agentic.ToolCall) agentic.ToolResult {
+	result := agentic.ToolResult{CallID: call.ID, Content: p.content}
+	stampCache(&result, CacheHit, p.entry, p.cache.now())
+	return result
+}
+
+// setValidators makes req conditional on the stale entry's validators.
+func (p *pageLookup) setValidators(req *http.Request) {
+	if p == nil || p.entry == nil {
+		return
+	}
+	if p.entry.ETag != "" {
+		req.Header.Set("If-None-Match", p.entry.ETag)
+	}
+	if p.entry.LastModified != "" {
+		req.Header.Set("If-Modified-Since", p.entry.LastModified)
+	}
+}
+
+// notModified reports whether resp confirmed the stale entry.
+func (p *pageLookup) notModified(resp *http.Response) bool {
+	return p != nil && p.entry != nil && resp.StatusCode == http.StatusNotModified
+}
+
+// revalidated renews the entry after a 304 and returns its content.
+func (p *pageLookup) revalidated(ctx context.Context, call agentic.ToolCall) agentic.ToolResult {
+	result := agentic.ToolResult{CallID: call.ID, Content: p.content}
+	stampCache(&result, CacheRevalidated, p.entry, p.cache.now())
+	// Re-storing the content also renews it against the bucket's retention.
+	if err := p.cache.store(ctx, *p.entry, p.content, p.ttl); err != nil {
+		slog.Debug("failed to renew web cache entry", "key", p.key, "error", err)
+	}
+	return result
+}
+
+// storeResponse caches a successful response's content and stamps the
+// result as a miss. header may be nil when response headers are unknown,
+// in which case the entry has no validators and is refetched once stale.
+func (p *pageLookup) storeResponse(ctx context.Context, status int, header http.Header, content, graphEntity string, result *agentic.ToolResult) {
+	if p == nil {
+		return
+	}
+	entry := webCacheEntry{Key: p.key, GraphEntity: graphEntity}
+	if header != nil {
+		entry.ETag = header.Get("ETag")
+		entry.LastModified = header.Get("Last-Modified")
+	}
+	stampCache(result, CacheMiss, &entry, p.cache.now())
+	if status < 200 || status > 299 || (header != nil && !cacheableResponse(header.Get("Cache-Control"))) {
+		return
+	}
+	if err := p.cache.store(ctx, entry, content, p.ttl); err != nil {
+		slog.Debug("failed to store web cache entry", "key", p.key, "error", err)
+	}
+}
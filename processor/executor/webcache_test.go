@@ -0,0 +1,197 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// useTestWebCache installs a web cache over an in-memory bucket and a plain
+// HTTP client that can reach httptest servers, restoring both afterwards.
+// Tests that call it must not run in parallel.
+func useTestWebCache(t *testing.T, cfg WebCacheConfig) (*WebCache, *time.Time) {
+	t.Helper()
+	cache, err := NewWebCache(newMemKV(), cfg)
+	if err != nil {
+		t.Fatalf("NewWebCache: %v", err)
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	prevCache, prevClient := webCache, httpToolClient
+	SetWebCache(cache)
+	httpToolClient = &http.Client{Timeout: 5 * time.Second}
+	t.Cleanup(func() {
+		SetWebCache(prevCache)
+		httpToolClient = prevClient
+	})
+	return cache, &now
+}
+
+func TestHTTPRequest_WebCache(t *testing.T) {
+	var requests, conditional atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/private" {
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("release notes v1"))
+	}))
+	defer srv.Close()
+
+	_, now := useTestWebCache(t, WebCacheConfig{TTL: "10m"})
+	get := func(url string, extra map[string]any) map[string]any {
+		t.Helper()
+		args := map[string]any{"url": url}
+		for k, v := range extra {
+			args[k] = v
+		}
+		res := httpRequestHandler(context.Background(), makeToolCall("http_request", args), nil, nil)
+		if res.Error != "" {
+			t.Fatalf("GET %s: %s", url, res.Error)
+		}
+		assertContains(t, res.Content, "release notes v1")
+		return res.Metadata
+	}
+
+	if md := get(srv.URL+"/notes?b=2&a=1", nil); md["cache"] != CacheMiss {
+		t.Errorf("first fetch cache = %v, want miss", md["cache"])
+	}
+	// Reordered query parameters and a fragment normalize to the same entry.
+	md := get(srv.URL+"/notes?a=1&b=2#top", nil)
+	if md["cache"] != CacheHit || requests.Load() != 1 {
+		t.Errorf("second fetch cache = %v after %d requests, want a hit after 1", md["cache"], requests.Load())
+	}
+	if md := get(srv.URL+"/notes?a=1&b=2", map[string]any{"format": "raw"}); md["cache"] != CacheMiss {
+		t.Errorf("another format should be cached separately, got %v", md["cache"])
+	}
+
+	*now = now.Add(11 * time.Minute)
+	md = get(srv.URL+"/notes?a=1&b=2", nil)
+	if md["cache"] != CacheRevalidated || conditional.Load() != 1 {
+		t.Errorf("stale fetch cache = %v with %d conditional requests, want revalidated", md["cache"], conditional.Load())
+	}
+	if md := get(srv.URL+"/notes?a=1&b=2", nil); md["cache"] != CacheHit {
+		t.Errorf("revalidation should renew the entry, got %v", md["cache"])
+	}
+
+	before := requests.Load()
+	if md := get(srv.URL+"/notes?a=1&b=2", map[string]any{"headers": map[string]any{"Accept": "text/plain"}}); md["cache"] != nil {
+		t.Errorf("requests with headers should bypass the cache, got %v", md["cache"])
+	}
+	get(srv.URL+"/private", nil)
+	get(srv.URL+"/private", nil)
+	if got := requests.Load() - before; got != 3 {
+		t.Errorf("bypassed and private fetches made %d requests, want 3", got)
+	}
+}
+
+type countingSearchProvider struct {
+	calls atomic.Int32
+}
+
+func (p *countingSearchProvider) Name() string { return "counting" }
+
+func (p *countingSearchProvider) Search(_ context.Context, query string, _ int) ([]SearchResult, error) {
+	p.calls.Add(1)
+	return []SearchResult{{Title: "Result for " + query, URL: "https://example.com/a"}}, nil
+}
+
+func TestWebSearch_WebCache(t *testing.T) {
+	_, now := useTestWebCache(t, WebCacheConfig{SearchTTL: "1h"})
+	provider := &countingSearchProvider{}
+	handler := makeWebSearchHandler(provider)
+	search := func(query string) string {
+		t.Helper()
+		res := handler(context.Background(), makeToolCall("web_search", map[string]any{"query": query}), nil, nil)
+		if res.Error != "" {
+			t.Fatalf("search %q: %s", query, res.Error)
+		}
+		status, _ := res.Metadata["cache"].(string)
+		return status
+	}
+
+	if got := search("Go generics"); got != CacheMiss {
+		t.Errorf("first search = %q, want miss", got)
+	}
+	if got := search("  go   GENERICS "); got != CacheHit {
+		t.Errorf("equivalent query = %q, want hit", got)
+	}
+	if provider.calls.Load() != 1 {
+		t.Errorf("provider called %d times, want 1", provider.calls.Load())
+	}
+	*now = now.Add(2 * time.Hour)
+	if got := search("go generics"); got != CacheMiss || provider.calls.Load() != 2 {
+		t.Errorf("expired search = %q after %d provider calls, want a miss", got, provider.calls.Load())
+	}
+}
+
+func TestWebCache_PageTTL(t *testing.T) {
+	t.Parallel()
+
+	cache, err := NewWebCache(newMemKV(), WebCacheConfig{
+		TTL:       "30m",
+		DomainTTL: map[string]string{"*.example.com": "24h", "live.example.com": "0"},
+	})
+	if err != nil {
+		t.Fatalf("NewWebCache: %v", err)
+	}
+	tests := []struct {
+		host string
+		want time.Duration
+	}{
+		{"docs.example.com", 24 * time.Hour},
+		{"LIVE.example.com", 0},
+		{"example.org", 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := cache.pageTTL(tt.host); got != tt.want {
+			t.Errorf("pageTTL(%q) = %s, want %s", tt.host, got, tt.want)
+		}
+	}
+
+	for _, cfg := range []WebCacheConfig{
+		{TTL: "soon"},
+		{DomainTTL: map[string]string{"[": "1h"}},
+		{SearchTTL: "-1h"},
+	} {
+		if _, err := NewWebCache(newMemKV(), cfg); err == nil {
+			t.Errorf("NewWebCache(%+v) should fail", cfg)
+		}
+	}
+}
+
+func TestNormalizeCacheURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, want string
+	}{
+		{"HTTPS://Example.COM:443/Docs?b=2&a=1#frag", "https://example.com/Docs?a=1&b=2"},
+		{"http://example.com", "http://example.com/"},
+		{"http://user:pw@example.com:8080/x", "http://example.com:8080/x"},
+	}
+	for _, tt := range tests {
+		got, ok := normalizeCacheURL(tt.in)
+		if !ok || got != tt.want {
+			t.Errorf("normalizeCacheURL(%q) = %q, %v; want %q", tt.in, got, ok, tt.want)
+		}
+	}
+	if _, ok := normalizeCacheURL("not a url"); ok {
+		t.Error("URL without a host should not normalize")
+	}
+	if !strings.HasPrefix(searchCacheKey("brave", " A  b ", 5), "search brave 5 a b") {
+		t.Errorf("search key = %q", searchCacheKey("brave", " A  b ", 5))
+	}
+}
@@ -3,13 +3,16 @@ package executor
 import (
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // =============================================================================
@@ -35,22 +38,80 @@ type SearchResult struct {
 
 // SearchConfig holds the configuration for web search providers.
 type SearchConfig struct {
-	// Provider is the search provider type (e.g. "brave").
+	// Provider is the search provider type: "brave", "searxng", "yacy",
+	// "opensearch", "local", or "multi" to fan out over Providers.
 	Provider string `json:"provider"`
 	// APIKeyEnv is the name of the environment variable that holds the API key.
 	// The actual key value is never stored in config — only the variable name.
+	// Required for brave; unused by the other providers.
 	APIKeyEnv string `json:"api_key_env"`
-	// BaseURL overrides the default API endpoint (optional).
+	// BaseURL overrides the default API endpoint for brave. For searxng and
+	// yacy it is the instance root URL; for opensearch it is the URL of the
+	// OpenSearch description document. Required for those three.
 	BaseURL string `json:"base_url,omitempty"`
+	// IndexPaths are files and directories indexed by the local provider.
+	IndexPaths []string `json:"index_paths,omitempty"`
+	// Providers are the providers a multi provider queries, in the order
+	// their results are interleaved.
+	Providers []SearchConfig `json:"providers,omitempty"`
 }
 
 // NewSearchProvider creates a SearchProvider from configuration.
 // Returns an error if the provider type is unknown or config is invalid.
-// The API key is resolved from the environment variable named by APIKeyEnv.
+// For brave, the API key is resolved from the environment variable named by
+// APIKeyEnv.
 func NewSearchProvider(cfg SearchConfig) (SearchProvider, error) {
-	if cfg.Provider == "" {
+	switch cfg.Provider {
+	case "":
 		return nil, fmt.Errorf("search provider type is required")
+	case "brave":
+		return newBraveSearchProvider(cfg)
+	case "searxng", "yacy", "opensearch":
+		baseURL := strings.TrimRight(cfg.BaseURL, "/")
+		if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+			return nil, fmt.Errorf("search provider %s requires an http(s) base_url", cfg.Provider)
+		}
+		switch cfg.Provider {
+		case "searxng":
+			return &searxngSearchProvider{baseURL: baseURL}, nil
+		case "yacy":
+			return &yacySearchProvider{baseURL: baseURL}, nil
+		default:
+			return &openSearchProvider{descriptionURL: baseURL}, nil
+		}
+	case "local":
+		index := NewLocalSearchIndex()
+		for _, p := range cfg.IndexPaths {
+			if _, err := index.AddPath(p); err != nil {
+				return nil, fmt.Errorf("local search index: %w", err)
+			}
+		}
+		return &localSearchProvider{index: index}, nil
+	case "multi":
+		return newFanOutSearchProvider(cfg.Providers)
+	default:
+		return nil, fmt.Errorf("unknown search provider: %q (supported: brave, searxng, yacy, opensearch, local, multi)", cfg.Provider)
+	}
+}
+
+// searchLimit clamps a requested result count to 1-10, defaulting to 5.
+func searchLimit(maxResults int) int {
+	if maxResults <= 0 {
+		return 5
+	}
+	if maxResults > 10 {
+		return 10
 	}
+	return maxResults
+}
+
+// =============================================================================
+// BRAVE SEARCH PROVIDER
+// =============================================================================
+
+const braveDefaultBaseURL = "https://api.search.brave.com/res/v1/web/search"
+
+func newBraveSearchProvider(cfg SearchConfig) (SearchProvider, error) {
 	if cfg.APIKeyEnv == "" {
 		return nil, fmt.Errorf("search api_key_env is required (set to the env var name holding the API key)")
 	}
@@ -60,27 +121,16 @@ func NewSearchProvider(cfg SearchConfig) (SearchProvider, error) {
 		return nil, fmt.Errorf("search API key env var %q is not set", cfg.APIKeyEnv)
 	}
 
-	switch cfg.Provider {
-	case "brave":
-		baseURL := cfg.BaseURL
-		if baseURL == "" {
-			baseURL = braveDefaultBaseURL
-		}
-		return &braveSearchProvider{
-			apiKey:  apiKey,
-			baseURL: baseURL,
-		}, nil
-	default:
-		return nil, fmt.Errorf("unknown search provider: %q (supported: brave)", cfg.Provider)
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = braveDefaultBaseURL
 	}
+	return &braveSearchProvider{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+	}, nil
 }
 
-// =============================================================================
-// BRAVE SEARCH PROVIDER
-// =============================================================================
-
-const braveDefaultBaseURL = "https://api.search.brave.com/res/v1/web/search"
-
 // braveSearchProvider implements SearchProvider using the Brave Search API.
 type braveSearchProvider struct {
 	apiKey  string
@@ -90,12 +140,7 @@ type braveSearchProvider struct {
 func (b *braveSearchProvider) Name() string { return "brave" }
 
 func (b *braveSearchProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
-	if maxResults <= 0 {
-		maxResults = 5
-	}
-	if maxResults > 10 {
-		maxResults = 10
-	}
+	maxResults = searchLimit(maxResults)
 
 	params := url.Values{}
 	params.Set("q", query)
@@ -156,6 +201,272 @@ type braveSearchResponse struct {
 	} `json:"web"`
 }
 
+// =============================================================================
+// SELF-HOSTED SEARCH PROVIDERS
+// =============================================================================
+
+// searchHTTPClient fetches from operator-configured search endpoints. Unlike
+// httpToolClient it may reach private addresses: self-hosted SearXNG, YaCy
+// and OpenSearch servers usually live on the board's own network, and agents
+// never choose these URLs.
+var searchHTTPClient = &http.Client{Timeout: httpRequestTimeout}
+
+// fetchSearchResponse GETs reqURL and returns the body of a 200 response.
+func fetchSearchResponse(ctx context.Context, reqURL, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := searchHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("search request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("search API returned %d: %s", resp.StatusCode, truncate(string(body), 200))
+	}
+	return body, nil
+}
+
+// searxngSearchProvider queries a SearXNG instance's JSON API. The instance
+// must have "json" enabled under search.formats in its settings.
+type searxngSearchProvider struct {
+	baseURL string
+}
+
+func (s *searxngSearchProvider) Name() string { return "searxng" }
+
+func (s *searxngSearchProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("format", "json")
+
+	body, err := fetchSearchResponse(ctx, s.baseURL+"/search?"+params.Encode(), "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		if len(results) == searchLimit(maxResults) {
+			break
+		}
+		results = append(results, SearchResult{Title: r.Title, URL: r.URL, Description: r.Content})
+	}
+	return results, nil
+}
+
+// yacySearchProvider queries a YaCy peer's yacysearch.json API.
+type yacySearchProvider struct {
+	baseURL string
+}
+
+func (y *yacySearchProvider) Name() string { return "yacy" }
+
+func (y *yacySearchProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	maxResults = searchLimit(maxResults)
+
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("maximumRecords", strconv.Itoa(maxResults))
+
+	body, err := fetchSearchResponse(ctx, y.baseURL+"/yacysearch.json?"+params.Encode(), "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Channels []struct {
+			Items []struct {
+				Title       string `json:"title"`
+				Link        string `json:"link"`
+				Description string `json:"description"`
+			} `json:"items"`
+		} `json:"channels"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+
+	var results []SearchResult
+	for _, ch := range resp.Channels {
+		for _, item := range ch.Items {
+			if len(results) == maxResults {
+				return results, nil
+			}
+			results = append(results, SearchResult{
+				Title:       item.Title,
+				URL:         item.Link,
+				Description: stripTags(item.Description),
+			})
+		}
+	}
+	return results, nil
+}
+
+// openSearchProvider queries any engine that publishes an OpenSearch
+// description document with an RSS or Atom results URL. The description
+// is fetched on first use and reused afterwards.
+type openSearchProvider struct {
+	descriptionURL string
+
+	mu       sync.Mutex
+	template string // results URL template from the description
+}
+
+func (o *openSearchProvider) Name() string { return "opensearch" }
+
+func (o *openSearchProvider) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	maxResults = searchLimit(maxResults)
+
+	template, err := o.resultsTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqURL, err := expandOpenSearchTemplate(template, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+	body, err := fetchSearchResponse(ctx, reqURL, "application/rss+xml, application/atom+xml")
+	if err != nil {
+		return nil, err
+	}
+
+	// One struct covers both formats: RSS items sit under channel, Atom
+	// entries directly under the feed root.
+	var feed struct {
+		Items []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+		} `xml:"channel>item"`
+		Entries []struct {
+			Title string `xml:"title"`
+			Links []struct {
+				Href string `xml:"href,attr"`
+				Rel  string `xml:"rel,attr"`
+			} `xml:"link"`
+			Summary string `xml:"summary"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+
+	var results []SearchResult
+	for _, item := range feed.Items {
+		results = append(results, SearchResult{
+			Title:       strings.TrimSpace(item.Title),
+			URL:         strings.TrimSpace(item.Link),
+			Description: stripTags(item.Description),
+		})
+	}
+	for _, entry := range feed.Entries {
+		r := SearchResult{Title: strings.TrimSpace(entry.Title), Description: stripTags(entry.Summary)}
+		if r.Description == "" {
+			r.Description = stripTags(entry.Content)
+		}
+		for _, l := range entry.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				r.URL = l.Href
+				break
+			}
+		}
+		results = append(results, r)
+	}
+	if len(results) > maxResults {
+		results = results[:maxResults]
+	}
+	return results, nil
+}
+
+// resultsTemplate returns the RSS or Atom results URL template from the
+// description document, fetching it on first use.
+func (o *openSearchProvider) resultsTemplate(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.template != "" {
+		return o.template, nil
+	}
+
+	body, err := fetchSearchResponse(ctx, o.descriptionURL, "application/opensearchdescription+xml")
+	if err != nil {
+		return "", fmt.Errorf("opensearch description: %w", err)
+	}
+	var desc struct {
+		URLs []struct {
+			Type     string `xml:"type,attr"`
+			Rel      string `xml:"rel,attr"`
+			Template string `xml:"template,attr"`
+		} `xml:"Url"`
+	}
+	if err := xml.Unmarshal(body, &desc); err != nil {
+		return "", fmt.Errorf("failed to parse opensearch description: %w", err)
+	}
+	for _, u := range desc.URLs {
+		if u.Rel != "" && u.Rel != "results" {
+			continue
+		}
+		if strings.Contains(u.Type, "rss+xml") || strings.Contains(u.Type, "atom+xml") {
+			o.template = u.Template
+			return o.template, nil
+		}
+	}
+	return "", fmt.Errorf("opensearch description at %s has no RSS or Atom results URL", o.descriptionURL)
+}
+
+// openSearchParam matches {name} and {name?} template parameters.
+var openSearchParam = regexp.MustCompile(`\{([^{}]+?)(\?)?\}`)
+
+// expandOpenSearchTemplate fills in an OpenSearch URL template. Optional
+// parameters without a value are left empty; an unknown required parameter
+// is an error.
+func expandOpenSearchTemplate(template, query string, count int) (string, error) {
+	values := map[string]string{
+		"searchTerms":    strings.ReplaceAll(url.QueryEscape(query), "+", "%20"),
+		"count":          strconv.Itoa(count),
+		"startIndex":     "1",
+		"startPage":      "1",
+		"language":       "*",
+		"inputEncoding":  "UTF-8",
+		"outputEncoding": "UTF-8",
+	}
+	var missing string
+	out := openSearchParam.ReplaceAllStringFunc(template, func(m string) string {
+		parts := openSearchParam.FindStringSubmatch(m)
+		if v, ok := values[parts[1]]; ok {
+			return v
+		}
+		if parts[2] == "" && missing == "" {
+			missing = parts[1]
+		}
+		return ""
+	})
+	if missing != "" {
+		return "", fmt.Errorf("opensearch template needs unsupported parameter %q", missing)
+	}
+	return out, nil
+}
+
 // =============================================================================
 // RESULT FORMATTING
 // =============================================================================
@@ -0,0 +1,210 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSearchProvider_Config(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     SearchConfig
+		want    string
+		wantErr string
+	}{
+		{"searxng", SearchConfig{Provider: "searxng", BaseURL: "http://searx:8080/"}, "searxng", ""},
+		{"yacy", SearchConfig{Provider: "yacy", BaseURL: "http://yacy:8090"}, "yacy", ""},
+		{"opensearch", SearchConfig{Provider: "opensearch", BaseURL: "https://wiki.example.com/osd.xml"}, "opensearch", ""},
+		{"local without paths", SearchConfig{Provider: "local"}, "local", ""},
+		{"multi", SearchConfig{Provider: "multi", Providers: []SearchConfig{
+			{Provider: "searxng", BaseURL: "http://searx"}, {Provider: "local"},
+		}}, "searxng+local", ""},
+		{"missing base url", SearchConfig{Provider: "yacy"}, "", "requires an http(s) base_url"},
+		{"brave without key", SearchConfig{Provider: "brave"}, "", "api_key_env is required"},
+		{"empty multi", SearchConfig{Provider: "multi"}, "", "requires providers"},
+		{"nested multi", SearchConfig{Provider: "multi", Providers: []SearchConfig{{Provider: "multi"}}}, "", "cannot be nested"},
+		{"bad member", SearchConfig{Provider: "multi", Providers: []SearchConfig{{Provider: "bing"}}}, "", "providers[0]: unknown search provider"},
+		{"missing index path", SearchConfig{Provider: "local", IndexPaths: []string{"/does/not/exist"}}, "", "local search index"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewSearchProvider(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v; want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Name() != tt.want {
+				t.Errorf("Name() = %q, want %q", p.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSelfHostedSearchProviders(t *testing.T) {
+	t.Parallel()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/search":
+			if q.Get("format") != "json" || q.Get("q") != "raft consensus" {
+				http.Error(w, "bad query", http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"results":[
+				{"title":"Raft","url":"https://raft.github.io/","content":"Understandable consensus"},
+				{"title":"Paper","url":"https://raft.github.io/raft.pdf","content":"In search of"}]}`))
+		case "/yacysearch.json":
+			if q.Get("maximumRecords") != "1" {
+				http.Error(w, "bad count", http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"channels":[{"items":[
+				{"title":"Raft","link":"https://raft.github.io/","description":"<b>Raft</b> consensus &amp; more"}]}]}`))
+		case "/osd.xml":
+			_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<OpenSearchDescription xmlns="http://a9.com/-/spec/opensearch/1.1/">
+  <ShortName>Wiki</ShortName>
+  <Url type="text/html" template="` + srv.URL + `/html?q={searchTerms}"/>
+  <Url type="application/atom+xml" template="` + srv.URL + `/feed?q={searchTerms}&amp;n={count}&amp;p={startPage?}&amp;x={custom:thing?}"/>
+</OpenSearchDescription>`))
+		case "/feed":
+			if q.Get("q") != "raft consensus" || q.Get("n") != "5" {
+				http.Error(w, "bad feed query", http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom">
+  <entry><title>Raft notes</title><link rel="alternate" href="https://wiki.example.com/raft"/><summary>Team notes on &lt;i&gt;Raft&lt;/i&gt;</summary></entry>
+</feed>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	searx, _ := NewSearchProvider(SearchConfig{Provider: "searxng", BaseURL: srv.URL})
+	results, err := searx.Search(ctx, "raft consensus", 1)
+	if err != nil || len(results) != 1 || results[0].Description != "Understandable consensus" {
+		t.Errorf("searxng = %+v, %v", results, err)
+	}
+
+	yacy, _ := NewSearchProvider(SearchConfig{Provider: "yacy", BaseURL: srv.URL})
+	results, err = yacy.Search(ctx, "raft consensus", 1)
+	if err != nil || len(results) != 1 || results[0].URL != "https://raft.github.io/" || results[0].Description != "Raft consensus & more" {
+		t.Errorf("yacy = %+v, %v", results, err)
+	}
+
+	osd, _ := NewSearchProvider(SearchConfig{Provider: "opensearch", BaseURL: srv.URL + "/osd.xml"})
+	results, err = osd.Search(ctx, "raft consensus", 5)
+	if err != nil || len(results) != 1 || results[0].URL != "https://wiki.example.com/raft" || results[0].Description != "Team notes on Raft" {
+		t.Errorf("opensearch = %+v, %v", results, err)
+	}
+
+	bad, _ := NewSearchProvider(SearchConfig{Provider: "opensearch", BaseURL: srv.URL + "/missing.xml"})
+	if _, err := bad.Search(ctx, "raft", 5); err == nil || !strings.Contains(err.Error(), "opensearch description") {
+		t.Errorf("missing description error = %v", err)
+	}
+}
+
+func TestExpandOpenSearchTemplate(t *testing.T) {
+	t.Parallel()
+
+	got, err := expandOpenSearchTemplate("https://x/s/{searchTerms}?n={count?}&l={language}&t={time:start?}", "a&b c", 3)
+	if err != nil || got != "https://x/s/a%26b%20c?n=3&l=*&t=" {
+		t.Errorf("expand = %q, %v", got, err)
+	}
+	if _, err := expandOpenSearchTemplate("https://x/?k={apiKey}&q={searchTerms}", "q", 3); err == nil {
+		t.Error("unknown required parameter should fail")
+	}
+}
+
+// stubSearchProvider returns canned results or an error.
+type stubSearchProvider struct {
+	name    string
+	results []SearchResult
+	err     error
+	calls   int
+}
+
+func (s *stubSearchProvider) Name() string { return s.name }
+
+func (s *stubSearchProvider) Search(_ context.Context, _ string, _ int) ([]SearchResult, error) {
+	s.calls++
+	return s.results, s.err
+}
+
+func TestFanOutSearchProvider(t *testing.T) {
+	t.Parallel()
+
+	a := &stubSearchProvider{name: "a", results: []SearchResult{
+		{Title: "A1", URL: "https://one.example/"}, {Title: "A2", URL: "https://two.example/"},
+	}}
+	b := &stubSearchProvider{name: "b", results: []SearchResult{
+		{Title: "B1", URL: "https://ONE.example"}, {Title: "B2", URL: "https://three.example/"},
+	}}
+	down := &stubSearchProvider{name: "down", err: errors.New("connection refused")}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	f := &fanOutSearchProvider{
+		providers: []SearchProvider{a, b, down},
+		name:      "a+b+down",
+		health:    make([]providerHealth, 3),
+		now:       func() time.Time { return now },
+	}
+
+	results, err := f.Search(context.Background(), "q", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	var titles []string
+	for _, r := range results {
+		titles = append(titles, r.Title)
+	}
+	if got := strings.Join(titles, ","); got != "A1,A2,B2" {
+		t.Errorf("merged = %s; want interleaved A1,A2,B2 with B1 deduplicated", got)
+	}
+
+	f.Search(context.Background(), "q", 10)
+	f.Search(context.Background(), "q", 10)
+	health := f.Health()
+	if health[2].Healthy || health[2].ConsecutiveFailures != 3 || health[2].RetryAt == nil || health[2].LastError != "connection refused" {
+		t.Errorf("down health = %+v", health[2])
+	}
+	if !health[0].Healthy || health[0].Successes != 3 {
+		t.Errorf("a health = %+v", health[0])
+	}
+
+	f.Search(context.Background(), "q", 10)
+	if down.calls != 3 {
+		t.Errorf("unhealthy provider called %d times during cooldown, want 3", down.calls)
+	}
+	now = now.Add(2 * time.Minute)
+	down.err = nil
+	f.Search(context.Background(), "q", 10)
+	if down.calls != 4 || !f.Health()[2].Healthy {
+		t.Errorf("provider should be retried after cooldown and recover: calls=%d health=%+v", down.calls, f.Health()[2])
+	}
+
+	allDown := &fanOutSearchProvider{
+		providers: []SearchProvider{&stubSearchProvider{name: "x", err: errors.New("boom")}},
+		health:    make([]providerHealth, 1),
+		now:       time.Now,
+	}
+	if _, err := allDown.Search(context.Background(), "q", 5); err == nil || !strings.Contains(err.Error(), "x: boom") {
+		t.Errorf("all-failed error = %v", err)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/c360studio/semdragons/domain"
 	"github.com/c360studio/semdragons/processor/partycoord"
 	"github.com/c360studio/semstreams/component"
+	"github.com/c360studio/semstreams/pkg/errs"
 )
 
 // =============================================================================
@@ -172,6 +173,18 @@ func (c *Component) ConfigSchema() component.ConfigSchema {
 				Default:     300,
 				Category:    "guild",
 			},
+			"proposal_duration_sec": {
+				Type:        "int",
+				Description: "Seconds members have to vote on a guild proposal",
+				Default:     3600,
+				Category:    "governance",
+			},
+			"proposal_quorum": {
+				Type:        "float",
+				Description: "Fraction of the guild's total vote weight that must vote for a proposal to pass at its deadline",
+				Default:     0.5,
+				Category:    "governance",
+			},
 		},
 		Required: []string{"org", "platform", "board"},
 	}
@@ -354,6 +367,38 @@ func (c *Component) guildMutex(id domain.GuildID) *sync.Mutex {
 	return val.(*sync.Mutex)
 }
 
+// updateGuild applies change to the guild as stored in KV, writes it back with
+// CAS and refreshes the cached copy. The cache only sees this component's own
+// writes, while the treasury, store and lessons write the same entity, so
+// changes are never made to the cached guild directly. The guild mutex is held
+// for the whole update. change may run more than once; an error from it aborts
+// the update and is returned unchanged.
+func (c *Component) updateGuild(ctx context.Context, guildID domain.GuildID, predicate string, change func(*domain.Guild) error) (*domain.Guild, error) {
+	mu := c.guildMutex(guildID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	var changeErr error
+	guild, err := c.graph.UpdateGuild(ctx, guildID, predicate, func(g *domain.Guild) error {
+		changeErr = change(g)
+		return changeErr
+	})
+	if changeErr != nil {
+		return nil, changeErr
+	}
+	if err != nil {
+		c.errorsCount.Add(1)
+		return nil, errs.Wrap(err, "GuildFormation", "updateGuild", "persist guild")
+	}
+
+	// Copy into the cached guild rather than replacing it, so the entry stays
+	// the same pointer for callers iterating the cache.
+	if val, loaded := c.guilds.LoadOrStore(guildID, guild); loaded {
+		*val.(*domain.Guild) = *guild
+	}
+	return guild, nil
+}
+
 // =============================================================================
 // SHARED WINS CACHE — Bootstrap, Incremental Watchers, Public Accessors
 // =============================================================================
@@ -417,15 +462,9 @@ func (c *Component) bootstrapSharedWins(ctx context.Context) {
 	} else {
 		for i := range reviews {
 			pr := domain.PeerReviewFromEntityState(&reviews[i])
-			if pr == nil || pr.Status != domain.PeerReviewCompleted {
+			if pr == nil || !c.recordPeerReviewScores(pr) {
 				continue
 			}
-			if pr.LeaderAvgRating > 0 {
-				c.sharedWins.RecordPeerReview(pr.LeaderID, pr.MemberID, pr.LeaderAvgRating)
-			}
-			if pr.MemberAvgRating > 0 {
-				c.sharedWins.RecordPeerReview(pr.MemberID, pr.LeaderID, pr.MemberAvgRating)
-			}
 			reviewCount++
 		}
 	}
@@ -572,19 +611,41 @@ func (c *Component) handleReviewCohesionUpdate(entry jetstream.KeyValueEntry) {
 	}
 
 	pr := domain.PeerReviewFromEntityState(entityState)
-	if pr == nil || pr.Status != domain.PeerReviewCompleted {
+	if pr == nil || !c.recordPeerReviewScores(pr) {
 		return
 	}
 
+	c.logger.Debug("cohesion watcher: recorded peer review",
+		"review_id", pr.ID, "leader", pr.LeaderID, "member", pr.MemberID)
+}
+
+// recordPeerReviewScores records a completed review's ratings in the shared
+// wins cache and reports whether it did. Member-to-member reviews wait for
+// their party's 360 aggregation, and ratings it flagged as collusion or
+// retaliation are skipped.
+func (c *Component) recordPeerReviewScores(pr *domain.PeerReview) bool {
+	if pr.Status != domain.PeerReviewCompleted {
+		return false
+	}
+	if pr.IsMemberToMember {
+		if !pr.Aggregated {
+			return false
+		}
+		for _, sub := range []*domain.ReviewSubmission{pr.LeaderReview, pr.MemberReview} {
+			if sub != nil && sub.Anomaly == "" {
+				c.sharedWins.RecordPeerReview(sub.ReviewerID, sub.RevieweeID, sub.Ratings.Average())
+			}
+		}
+		return true
+	}
+
 	if pr.LeaderAvgRating > 0 {
 		c.sharedWins.RecordPeerReview(pr.LeaderID, pr.MemberID, pr.LeaderAvgRating)
 	}
 	if pr.MemberAvgRating > 0 {
 		c.sharedWins.RecordPeerReview(pr.MemberID, pr.LeaderID, pr.MemberAvgRating)
 	}
-
-	c.logger.Debug("cohesion watcher: recorded peer review",
-		"review_id", pr.ID, "leader", pr.LeaderID, "member", pr.MemberID)
+	return true
 }
 
 // SharedWins returns how many quests agents a and b completed together in the same party.
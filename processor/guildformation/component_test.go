@@ -556,6 +556,119 @@ func TestComponent_PromoteMember(t *testing.T) {
 	t.Error("Promoted member not found in guild")
 }
 
+func TestComponent_ProposalPromotesByVote(t *testing.T) {
+	testClient := natsclient.NewTestClient(t, natsclient.WithKV(), natsclient.WithFileStorage(), natsclient.WithKVBuckets(graph.BucketEntityStates))
+	client := testClient.Client
+	ctx := context.Background()
+
+	comp := setupGuildComponent(t, client, "proposalvote")
+	defer comp.Stop(5 * time.Second)
+
+	founderID := makeAgentID(t, comp.BoardConfig(), "vote-founder")
+	memberA := makeAgentID(t, comp.BoardConfig(), "vote-a")
+	memberB := makeAgentID(t, comp.BoardConfig(), "vote-b")
+
+	guild, err := comp.CreateGuild(ctx, CreateGuildParams{Name: "The Assembly", FounderID: founderID})
+	if err != nil {
+		t.Fatalf("CreateGuild failed: %v", err)
+	}
+	guildID := domain.GuildID(guild.ID)
+	for _, id := range []domain.AgentID{memberA, memberB} {
+		if err := comp.JoinGuild(ctx, guildID, id); err != nil {
+			t.Fatalf("JoinGuild failed: %v", err)
+		}
+	}
+
+	// Total weight 5+1+1: member A's own vote (1) is not a majority.
+	proposal, err := comp.OpenProposal(ctx, guildID, OpenProposalParams{
+		Kind:       domain.ProposalPromote,
+		ProposerID: memberA,
+		TargetID:   memberB,
+		NewRank:    domain.GuildRankMember,
+	})
+	if err != nil {
+		t.Fatalf("OpenProposal failed: %v", err)
+	}
+	if proposal.Status != domain.ProposalOpen {
+		t.Fatalf("Status = %s, want open", proposal.Status)
+	}
+
+	// The guildmaster's weight carries the vote.
+	proposal, err = comp.CastVote(ctx, guildID, proposal.ID, founderID, true, "earned it")
+	if err != nil {
+		t.Fatalf("CastVote failed: %v", err)
+	}
+	if proposal.Status != domain.ProposalPassed || proposal.Outcome != "executed" {
+		t.Fatalf("after vote: status=%s outcome=%q", proposal.Status, proposal.Outcome)
+	}
+
+	updated, _ := comp.GetGuild(guildID)
+	if m := updated.Member(memberB); m == nil || m.Rank != domain.GuildRankMember {
+		t.Errorf("member B rank = %+v, want member", m)
+	}
+	if _, err := comp.CastVote(ctx, guildID, proposal.ID, memberB, false, ""); err != ErrProposalClosed {
+		t.Errorf("vote on closed proposal: err = %v, want ErrProposalClosed", err)
+	}
+}
+
+func TestComponent_GovernanceKeepsExternalGuildWrites(t *testing.T) {
+	testClient := natsclient.NewTestClient(t, natsclient.WithKV(), natsclient.WithFileStorage(), natsclient.WithKVBuckets(graph.BucketEntityStates))
+	client := testClient.Client
+	ctx := context.Background()
+
+	comp := setupGuildComponent(t, client, "externalwrites")
+	defer comp.Stop(5 * time.Second)
+
+	founderID := makeAgentID(t, comp.BoardConfig(), "ext-founder")
+	memberID := makeAgentID(t, comp.BoardConfig(), "ext-member")
+
+	guild, err := comp.CreateGuild(ctx, CreateGuildParams{Name: "The Ledger", FounderID: founderID})
+	if err != nil {
+		t.Fatalf("CreateGuild failed: %v", err)
+	}
+	guildID := domain.GuildID(guild.ID)
+
+	// Another processor deposits into the treasury; the component's cache
+	// never sees this write.
+	if _, err := comp.graph.UpdateGuild(ctx, guildID, domain.PredicateGuildTreasuryDeposited, func(g *domain.Guild) error {
+		g.Deposit(domain.TreasuryTransaction{Kind: domain.TreasuryTxTax, Amount: 40, AgentID: founderID})
+		return nil
+	}); err != nil {
+		t.Fatalf("external deposit failed: %v", err)
+	}
+
+	if err := comp.JoinGuild(ctx, guildID, memberID); err != nil {
+		t.Fatalf("JoinGuild failed: %v", err)
+	}
+	proposal, err := comp.OpenProposal(ctx, guildID, OpenProposalParams{
+		Kind:       domain.ProposalPromote,
+		ProposerID: founderID,
+		TargetID:   memberID,
+		NewRank:    domain.GuildRankMember,
+	})
+	if err != nil {
+		t.Fatalf("OpenProposal failed: %v", err)
+	}
+	if proposal.Status != domain.ProposalPassed {
+		t.Fatalf("Status = %s, want passed", proposal.Status)
+	}
+
+	entity, err := comp.graph.GetGuild(ctx, guildID)
+	if err != nil {
+		t.Fatalf("GetGuild failed: %v", err)
+	}
+	stored := domain.GuildFromEntityState(entity)
+	if stored.Treasury != 40 {
+		t.Errorf("stored treasury = %d, want 40", stored.Treasury)
+	}
+	if m := stored.Member(memberID); m == nil || m.Rank != domain.GuildRankMember {
+		t.Errorf("stored member = %+v, want rank member", m)
+	}
+	if cached, _ := comp.GetGuild(guildID); cached.Treasury != 40 {
+		t.Errorf("cached treasury = %d, want 40", cached.Treasury)
+	}
+}
+
 func TestComponent_PromoteMemberNotFound(t *testing.T) {
 	testClient := natsclient.NewTestClient(t, natsclient.WithKV(), natsclient.WithFileStorage(), natsclient.WithKVBuckets(graph.BucketEntityStates))
 	client := testClient.Client
@@ -23,6 +23,10 @@ type Config struct {
 	EnableQuorumFormation bool `json:"enable_quorum_formation"`
 	MinFoundingMembers    int  `json:"min_founding_members"`
 	FormationTimeoutSec   int  `json:"formation_timeout_sec"`
+
+	// Governance settings
+	ProposalDurationSec int     `json:"proposal_duration_sec"` // Voting window for guild proposals
+	ProposalQuorum      float64 `json:"proposal_quorum"`       // Fraction of total vote weight that must vote
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -36,6 +40,8 @@ func DefaultConfig() Config {
 		EnableQuorumFormation:  false,
 		MinFoundingMembers:     3,
 		FormationTimeoutSec:    300,
+		ProposalDurationSec:    3600,
+		ProposalQuorum:         0.5,
 	}
 }
 
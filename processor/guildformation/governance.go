@@ -0,0 +1,380 @@
+package guildformation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+)
+
+// =============================================================================
+// GOVERNANCE — Member proposals decided by rank-weighted vote
+// =============================================================================
+// Proposals live on the guild entity. A proposal is settled as soon as its
+// outcome is certain (see domain.GuildProposal.Decide) or at its deadline,
+// which the formation timeout loop sweeps. Passed decisions execute through
+// the same handlers a founder or admin would call directly.
+// =============================================================================
+
+// Governance errors.
+var (
+	ErrNotMember           = errors.New("not a member")
+	ErrProposalNotFound    = errors.New("proposal not found")
+	ErrProposalClosed      = errors.New("proposal is no longer open")
+	ErrAlreadyVoted        = errors.New("already voted on this proposal")
+	ErrDuplicateProposal   = errors.New("an open proposal already covers this decision")
+	ErrInvalidProposal     = errors.New("invalid proposal")
+	ErrGuildmasterRankLock = errors.New("the guildmaster's rank cannot be changed by vote")
+)
+
+// OpenProposalParams holds parameters for opening a guild proposal.
+type OpenProposalParams struct {
+	Kind          domain.ProposalKind
+	ProposerID    domain.AgentID
+	TargetID      domain.AgentID   // Member to promote or demote
+	ApplicationID string           // Application to accept
+	NewRank       domain.GuildRank // Rank for promote/demote
+	Reason        string
+}
+
+// OpenProposal puts a decision to a guild vote. The proposer's approving vote
+// is cast automatically.
+func (c *Component) OpenProposal(ctx context.Context, guildID domain.GuildID, params OpenProposalParams) (*domain.GuildProposal, error) {
+	if !c.running.Load() {
+		return nil, errors.New("component not running")
+	}
+
+	if _, ok := c.guilds.Load(guildID); !ok {
+		return nil, errors.New("guild not found")
+	}
+
+	var proposal domain.GuildProposal
+	var status domain.ProposalStatus
+	now := time.Now()
+	_, err := c.updateGuild(ctx, guildID, domain.PredicateGuildProposalOpened, func(guild *domain.Guild) error {
+		proposer := guild.Member(params.ProposerID)
+		if proposer == nil {
+			return ErrNotMember
+		}
+		if guild.Status == domain.GuildInactive {
+			return errors.New("guild is inactive")
+		}
+
+		proposal = domain.GuildProposal{
+			ID:            domain.GenerateInstance(),
+			GuildID:       guildID,
+			Kind:          params.Kind,
+			ProposerID:    proposer.AgentID,
+			ApplicationID: params.ApplicationID,
+			NewRank:       params.NewRank,
+			Reason:        params.Reason,
+			Status:        domain.ProposalOpen,
+			Quorum:        c.config.ProposalQuorum,
+			CreatedAt:     now,
+			Deadline:      now.Add(time.Duration(c.config.ProposalDurationSec) * time.Second),
+		}
+		if err := validateProposal(guild, &proposal, params.TargetID); err != nil {
+			return err
+		}
+
+		proposal.Votes = []domain.GuildVote{{
+			VoterID: proposer.AgentID,
+			Approve: true,
+			Weight:  proposer.Rank.VoteWeight(),
+			Reason:  "proposer",
+			At:      now,
+		}}
+		guild.Proposals = append(guild.Proposals, proposal)
+		status = proposal.Decide(guild.TotalVoteWeight(), now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.lastActivity.Store(now)
+	c.logger.Info("guild proposal opened",
+		"guild_id", guildID,
+		"proposal_id", proposal.ID,
+		"kind", proposal.Kind,
+		"proposer", proposal.ProposerID,
+		"target", proposal.TargetID)
+
+	if status != domain.ProposalOpen {
+		c.settleProposal(ctx, guildID, proposal.ID, status)
+	}
+	return c.proposalCopy(guildID, proposal.ID), nil
+}
+
+// CastVote records a member's vote and settles the proposal if the outcome is
+// now certain. Returns the proposal as it stands after the vote.
+func (c *Component) CastVote(ctx context.Context, guildID domain.GuildID, proposalID string, voterID domain.AgentID, approve bool, reason string) (*domain.GuildProposal, error) {
+	if !c.running.Load() {
+		return nil, errors.New("component not running")
+	}
+
+	if _, ok := c.guilds.Load(guildID); !ok {
+		return nil, errors.New("guild not found")
+	}
+
+	var status domain.ProposalStatus
+	now := time.Now()
+	_, err := c.updateGuild(ctx, guildID, domain.PredicateGuildProposalVoted, func(guild *domain.Guild) error {
+		voter := guild.Member(voterID)
+		if voter == nil {
+			return ErrNotMember
+		}
+		proposal := guild.Proposal(proposalID)
+		if proposal == nil {
+			return ErrProposalNotFound
+		}
+		if proposal.Status != domain.ProposalOpen {
+			return ErrProposalClosed
+		}
+		if proposal.VoteBy(voterID) != nil {
+			return ErrAlreadyVoted
+		}
+
+		proposal.Votes = append(proposal.Votes, domain.GuildVote{
+			VoterID: voter.AgentID,
+			Approve: approve,
+			Weight:  voter.Rank.VoteWeight(),
+			Reason:  reason,
+			At:      now,
+		})
+		status = proposal.Decide(guild.TotalVoteWeight(), now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.lastActivity.Store(now)
+	c.logger.Info("guild vote cast",
+		"guild_id", guildID,
+		"proposal_id", proposalID,
+		"voter", voterID,
+		"approve", approve)
+
+	if status != domain.ProposalOpen {
+		c.settleProposal(ctx, guildID, proposalID, status)
+	}
+	return c.proposalCopy(guildID, proposalID), nil
+}
+
+// ListProposals returns a copy of the guild's proposals, oldest first.
+func (c *Component) ListProposals(guildID domain.GuildID) []domain.GuildProposal {
+	val, ok := c.guilds.Load(guildID)
+	if !ok {
+		return nil
+	}
+	mu := c.guildMutex(guildID)
+	mu.Lock()
+	defer mu.Unlock()
+	return domain.CopyProposals(val.(*domain.Guild).Proposals)
+}
+
+// validateProposal checks the proposal against the guild's current state and
+// fills in the target.
+func validateProposal(guild *domain.Guild, p *domain.GuildProposal, targetID domain.AgentID) error {
+	switch p.Kind {
+	case domain.ProposalAcceptApplicant:
+		if guild.Status != domain.GuildPending {
+			return ErrGuildNotPending
+		}
+		var app *domain.GuildApplication
+		for i := range guild.Applications {
+			if guild.Applications[i].ID == p.ApplicationID {
+				app = &guild.Applications[i]
+				break
+			}
+		}
+		if app == nil {
+			return ErrApplicationNotFound
+		}
+		if app.Status != domain.ApplicationPending {
+			return errors.New("application already reviewed")
+		}
+		p.TargetID = app.ApplicantID
+
+	case domain.ProposalPromote, domain.ProposalDemote:
+		target := guild.Member(targetID)
+		if target == nil {
+			return fmt.Errorf("%w: target is %w", ErrInvalidProposal, ErrNotMember)
+		}
+		if target.Rank == domain.GuildRankMaster || p.NewRank == domain.GuildRankMaster {
+			return ErrGuildmasterRankLock
+		}
+		if p.NewRank.Order() < 0 {
+			return fmt.Errorf("%w: unknown rank %q", ErrInvalidProposal, p.NewRank)
+		}
+		if p.Kind == domain.ProposalPromote && p.NewRank.Order() <= target.Rank.Order() {
+			return fmt.Errorf("%w: %s is not above %s", ErrInvalidProposal, p.NewRank, target.Rank)
+		}
+		if p.Kind == domain.ProposalDemote && p.NewRank.Order() >= target.Rank.Order() {
+			return fmt.Errorf("%w: %s is not below %s", ErrInvalidProposal, p.NewRank, target.Rank)
+		}
+		p.TargetID = target.AgentID
+
+	case domain.ProposalDisband:
+		// No target.
+
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidProposal, p.Kind)
+	}
+
+	for _, existing := range guild.Proposals {
+		if existing.Status == domain.ProposalOpen && existing.Kind == p.Kind &&
+			existing.TargetID == p.TargetID && existing.ApplicationID == p.ApplicationID {
+			return ErrDuplicateProposal
+		}
+	}
+	return nil
+}
+
+// errProposalSettled aborts a settlement that another caller already made.
+var errProposalSettled = errors.New("proposal already settled")
+
+// settleProposal closes a proposal and executes its decision through the
+// existing guild handlers. Safe to call concurrently: the close is a CAS
+// write, so only the caller that moves the proposal out of open executes it.
+func (c *Component) settleProposal(ctx context.Context, guildID domain.GuildID, proposalID string, status domain.ProposalStatus) {
+	predicate := domain.PredicateGuildProposalRejected
+	switch status {
+	case domain.ProposalPassed:
+		predicate = domain.PredicateGuildProposalPassed
+	case domain.ProposalExpired:
+		predicate = domain.PredicateGuildProposalExpired
+	}
+
+	now := time.Now()
+	var settled domain.GuildProposal
+	var founder domain.AgentID
+	_, err := c.updateGuild(ctx, guildID, predicate, func(guild *domain.Guild) error {
+		proposal := guild.Proposal(proposalID)
+		if proposal == nil || proposal.Status != domain.ProposalOpen {
+			return errProposalSettled
+		}
+		proposal.Status = status
+		proposal.ResolvedAt = &now
+		settled = *proposal
+		founder = guild.FoundedBy
+		return nil
+	})
+	if errors.Is(err, errProposalSettled) {
+		return
+	}
+	if err != nil {
+		c.logger.Error("failed to persist proposal settlement", "guild_id", guildID, "error", err)
+		return
+	}
+
+	outcome := c.executeProposal(ctx, guildID, founder, &settled)
+
+	if _, err := c.updateGuild(ctx, guildID, predicate, func(guild *domain.Guild) error {
+		if p := guild.Proposal(proposalID); p != nil {
+			p.Outcome = outcome
+		}
+		guild.PruneProposals()
+		return nil
+	}); err != nil {
+		c.logger.Error("failed to persist proposal outcome", "guild_id", guildID, "error", err)
+	}
+
+	c.lastActivity.Store(now)
+	c.logger.Info("guild proposal settled",
+		"guild_id", guildID,
+		"proposal_id", proposalID,
+		"kind", settled.Kind,
+		"status", status,
+		"outcome", outcome)
+}
+
+// executeProposal applies a settled proposal and describes what happened.
+func (c *Component) executeProposal(ctx context.Context, guildID domain.GuildID, founder domain.AgentID, p *domain.GuildProposal) string {
+	if p.Status == domain.ProposalExpired {
+		return "expired without quorum"
+	}
+	passed := p.Status == domain.ProposalPassed
+	reason := fmt.Sprintf("guild vote %s (proposal %s)", p.Status, p.ID)
+
+	var err error
+	switch p.Kind {
+	case domain.ProposalAcceptApplicant:
+		// Rejections are executed too so the applicant gets an answer.
+		err = c.ReviewApplication(ctx, guildID, p.ApplicationID, founder, passed, reason)
+	case domain.ProposalPromote, domain.ProposalDemote:
+		if passed {
+			err = c.PromoteMember(ctx, guildID, p.TargetID, p.NewRank)
+		}
+	case domain.ProposalDisband:
+		if passed {
+			err = c.DisbandGuild(ctx, guildID, reason)
+		}
+	}
+	if err != nil {
+		c.logger.Warn("failed to execute guild proposal",
+			"guild_id", guildID,
+			"proposal_id", p.ID,
+			"error", err)
+		return "execution failed: " + err.Error()
+	}
+	if !passed {
+		return "no action"
+	}
+	return "executed"
+}
+
+// checkProposalDeadlines settles open proposals whose deadline has passed.
+func (c *Component) checkProposalDeadlines() {
+	now := time.Now()
+
+	type due struct {
+		guildID domain.GuildID
+		id      string
+		status  domain.ProposalStatus
+	}
+	var expired []due
+	c.guilds.Range(func(key, value any) bool {
+		guildID := key.(domain.GuildID)
+		mu := c.guildMutex(guildID)
+		mu.Lock()
+		guild := value.(*domain.Guild)
+		total := guild.TotalVoteWeight()
+		for i := range guild.Proposals {
+			p := &guild.Proposals[i]
+			if p.Status != domain.ProposalOpen || now.Before(p.Deadline) {
+				continue
+			}
+			expired = append(expired, due{guildID: guildID, id: p.ID, status: p.Decide(total, now)})
+		}
+		mu.Unlock()
+		return true
+	})
+
+	for _, d := range expired {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		c.settleProposal(ctx, d.guildID, d.id, d.status)
+		cancel()
+	}
+}
+
+// proposalCopy returns a copy of a proposal from the cached guild.
+func (c *Component) proposalCopy(guildID domain.GuildID, proposalID string) *domain.GuildProposal {
+	val, ok := c.guilds.Load(guildID)
+	if !ok {
+		return nil
+	}
+	mu := c.guildMutex(guildID)
+	mu.Lock()
+	defer mu.Unlock()
+	p := val.(*domain.Guild).Proposal(proposalID)
+	if p == nil {
+		return nil
+	}
+	cp := *p
+	cp.Votes = append([]domain.GuildVote(nil), p.Votes...)
+	return &cp
+}
@@ -0,0 +1,83 @@
+package guildformation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/c360studio/semdragons/domain"
+)
+
+func governanceTestGuild() *domain.Guild {
+	return &domain.Guild{
+		ID:        "guild-1",
+		Status:    domain.GuildActive,
+		FoundedBy: "founder",
+		Members: []domain.GuildMember{
+			{AgentID: "founder", Rank: domain.GuildRankMaster},
+			{AgentID: "vet", Rank: domain.GuildRankVeteran},
+			{AgentID: "new", Rank: domain.GuildRankInitiate},
+		},
+	}
+}
+
+func TestValidateProposal_RankChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    domain.ProposalKind
+		target  domain.AgentID
+		rank    domain.GuildRank
+		wantErr error
+	}{
+		{"promote", domain.ProposalPromote, "new", domain.GuildRankMember, nil},
+		{"demote", domain.ProposalDemote, "vet", domain.GuildRankMember, nil},
+		{"promote sideways", domain.ProposalPromote, "vet", domain.GuildRankVeteran, ErrInvalidProposal},
+		{"demote upward", domain.ProposalDemote, "new", domain.GuildRankOfficer, ErrInvalidProposal},
+		{"unknown rank", domain.ProposalPromote, "new", "emperor", ErrInvalidProposal},
+		{"to guildmaster", domain.ProposalPromote, "vet", domain.GuildRankMaster, ErrGuildmasterRankLock},
+		{"demote guildmaster", domain.ProposalDemote, "founder", domain.GuildRankOfficer, ErrGuildmasterRankLock},
+		{"non-member", domain.ProposalPromote, "stranger", domain.GuildRankMember, ErrNotMember},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &domain.GuildProposal{Kind: tt.kind, NewRank: tt.rank}
+			err := validateProposal(governanceTestGuild(), p, tt.target)
+			if tt.wantErr == nil {
+				if err != nil || p.TargetID != tt.target {
+					t.Errorf("err = %v, target = %q", err, p.TargetID)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateProposal_AcceptApplicant(t *testing.T) {
+	guild := governanceTestGuild()
+	guild.Status = domain.GuildPending
+	guild.Applications = []domain.GuildApplication{{ID: "app-1", ApplicantID: "applicant", Status: domain.ApplicationPending}}
+
+	p := &domain.GuildProposal{Kind: domain.ProposalAcceptApplicant, ApplicationID: "app-1"}
+	if err := validateProposal(guild, p, ""); err != nil || p.TargetID != "applicant" {
+		t.Fatalf("err = %v, target = %q", err, p.TargetID)
+	}
+
+	p.Status = domain.ProposalOpen
+	guild.Proposals = append(guild.Proposals, *p)
+	dup := &domain.GuildProposal{Kind: domain.ProposalAcceptApplicant, ApplicationID: "app-1"}
+	if err := validateProposal(guild, dup, ""); !errors.Is(err, ErrDuplicateProposal) {
+		t.Errorf("duplicate: err = %v", err)
+	}
+
+	missing := &domain.GuildProposal{Kind: domain.ProposalAcceptApplicant, ApplicationID: "nope"}
+	if err := validateProposal(guild, missing, ""); !errors.Is(err, ErrApplicationNotFound) {
+		t.Errorf("missing application: err = %v", err)
+	}
+
+	guild.Status = domain.GuildActive
+	if err := validateProposal(guild, &domain.GuildProposal{Kind: domain.ProposalAcceptApplicant, ApplicationID: "app-1"}, ""); !errors.Is(err, ErrGuildNotPending) {
+		t.Errorf("active guild: err = %v", err)
+	}
+}
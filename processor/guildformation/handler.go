@@ -138,6 +138,7 @@ func (c *Component) GetGuild(guildID domain.GuildID) (*domain.Guild, bool) {
 	cp := *original
 	cp.Members = append([]domain.GuildMember(nil), original.Members...)
 	cp.Applications = append([]domain.GuildApplication(nil), original.Applications...)
+	cp.Proposals = domain.CopyProposals(original.Proposals)
 	cp.QuestTypes = append([]string(nil), original.QuestTypes...)
 	mu.Unlock()
 	return &cp, true
@@ -149,8 +150,7 @@ func (c *Component) JoinGuild(ctx context.Context, guildID domain.GuildID, agent
 		return errors.New("component not running")
 	}
 
-	val, ok := c.guilds.Load(guildID)
-	if !ok {
+	if _, ok := c.guilds.Load(guildID); !ok {
 		return errors.New("guild not found")
 	}
 
@@ -162,40 +162,33 @@ func (c *Component) JoinGuild(ctx context.Context, guildID domain.GuildID, agent
 		return fmt.Errorf("agent already belongs to guild %s", existing)
 	}
 
-	mu := c.guildMutex(guildID)
-	mu.Lock()
-	guild := val.(*domain.Guild)
-
-	// Check if already a member
-	if isMember(guild, agentID) {
-		mu.Unlock()
-		return ErrAlreadyMember
-	}
-
-	// Check max size
-	if c.config.MaxGuildSize > 0 && len(guild.Members) >= c.config.MaxGuildSize {
-		mu.Unlock()
-		return ErrGuildFull
-	}
-
 	now := time.Now()
+	guild, err := c.updateGuild(ctx, guildID, "guild.member.joined", func(guild *domain.Guild) error {
+		// Check if already a member
+		if isMember(guild, agentID) {
+			return ErrAlreadyMember
+		}
+
+		// Check max size
+		if c.config.MaxGuildSize > 0 && len(guild.Members) >= c.config.MaxGuildSize {
+			return ErrGuildFull
+		}
 
-	// Add member
-	guild.Members = append(guild.Members, domain.GuildMember{
-		AgentID:  agentID,
-		Rank:     domain.GuildRankInitiate,
-		JoinedAt: now,
+		// Add member
+		guild.Members = append(guild.Members, domain.GuildMember{
+			AgentID:  agentID,
+			Rank:     domain.GuildRankInitiate,
+			JoinedAt: now,
+		})
+		return nil
 	})
-	mu.Unlock()
+	if err != nil {
+		return err
+	}
 
 	// Update agent guild mapping
 	c.addAgentGuild(agentID, guildID)
 
-	// Persist updated guild to KV
-	if err := c.graph.EmitEntity(ctx, guild, "guild.member.joined"); err != nil {
-		c.logger.Error("failed to persist guild update to KV", "guild_id", guildID, "error", err)
-	}
-
 	// Publish join event
 	if err := SubjectGuildJoined.Publish(ctx, c.deps.NATSClient, GuildJoinedPayload{
 		GuildID:   guildID,
@@ -227,47 +220,40 @@ func (c *Component) LeaveGuild(ctx context.Context, guildID domain.GuildID, agen
 		return errors.New("component not running")
 	}
 
-	val, ok := c.guilds.Load(guildID)
-	if !ok {
+	if _, ok := c.guilds.Load(guildID); !ok {
 		return errors.New("guild not found")
 	}
 
-	mu := c.guildMutex(guildID)
-	mu.Lock()
-	guild := val.(*domain.Guild)
-
-	// Check if a member
-	if !isMember(guild, agentID) {
-		mu.Unlock()
-		return errors.New("not a member")
-	}
+	guild, err := c.updateGuild(ctx, guildID, "guild.member.left", func(guild *domain.Guild) error {
+		// Check if a member
+		if !isMember(guild, agentID) {
+			return errors.New("not a member")
+		}
 
-	// Cannot leave if founder/guildmaster (must transfer first)
-	if guild.FoundedBy == agentID {
-		mu.Unlock()
-		return errors.New("guildmaster must transfer leadership before leaving")
-	}
+		// Cannot leave if founder/guildmaster (must transfer first)
+		if guild.FoundedBy == agentID {
+			return errors.New("guildmaster must transfer leadership before leaving")
+		}
 
-	// Remove member
-	newMembers := make([]domain.GuildMember, 0, len(guild.Members)-1)
-	for _, m := range guild.Members {
-		if m.AgentID != agentID {
-			newMembers = append(newMembers, m)
+		// Remove member
+		newMembers := make([]domain.GuildMember, 0, len(guild.Members)-1)
+		for _, m := range guild.Members {
+			if m.AgentID != agentID {
+				newMembers = append(newMembers, m)
+			}
 		}
+		guild.Members = newMembers
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	guild.Members = newMembers
-	mu.Unlock()
 
 	// Update agent guild mapping
 	c.removeAgentGuild(agentID, guildID)
 
 	now := time.Now()
 
-	// Persist updated guild to KV
-	if err := c.graph.EmitEntity(ctx, guild, "guild.member.left"); err != nil {
-		c.logger.Error("failed to persist guild update to KV", "guild_id", guildID, "error", err)
-	}
-
 	// Publish leave event
 	if err := SubjectGuildLeft.Publish(ctx, c.deps.NATSClient, GuildLeftPayload{
 		GuildID:   guildID,
@@ -299,32 +285,27 @@ func (c *Component) PromoteMember(ctx context.Context, guildID domain.GuildID, a
 		return errors.New("component not running")
 	}
 
-	val, ok := c.guilds.Load(guildID)
-	if !ok {
+	if _, ok := c.guilds.Load(guildID); !ok {
 		return errors.New("guild not found")
 	}
 
-	mu := c.guildMutex(guildID)
-	mu.Lock()
-	guild := val.(*domain.Guild)
+	var oldRank domain.GuildRank
+	guild, err := c.updateGuild(ctx, guildID, "guild.member.promoted", func(guild *domain.Guild) error {
+		member := getMember(guild, agentID)
+		if member == nil {
+			return errors.New("not a member")
+		}
 
-	member := getMember(guild, agentID)
-	if member == nil {
-		mu.Unlock()
-		return errors.New("not a member")
+		oldRank = member.Rank
+		member.Rank = newRank
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	oldRank := member.Rank
-	member.Rank = newRank
-	mu.Unlock()
-
 	now := time.Now()
 
-	// Persist updated guild to KV
-	if err := c.graph.EmitEntity(ctx, guild, "guild.member.promoted"); err != nil {
-		c.logger.Error("failed to persist guild update to KV", "guild_id", guildID, "error", err)
-	}
-
 	// Publish promotion event
 	if err := SubjectGuildPromoted.Publish(ctx, c.deps.NATSClient, GuildPromotedPayload{
 		GuildID:   guildID,
@@ -356,32 +337,25 @@ func (c *Component) DisbandGuild(ctx context.Context, guildID domain.GuildID, re
 		return errors.New("component not running")
 	}
 
-	val, ok := c.guilds.Load(guildID)
-	if !ok {
+	if _, ok := c.guilds.Load(guildID); !ok {
 		return errors.New("guild not found")
 	}
 
-	mu := c.guildMutex(guildID)
-	mu.Lock()
-	guild := val.(*domain.Guild)
-
 	now := time.Now()
-	guild.Status = domain.GuildInactive
-
-	// Snapshot members for cleanup outside the lock.
-	members := append([]domain.GuildMember(nil), guild.Members...)
-	mu.Unlock()
+	guild, err := c.updateGuild(ctx, guildID, "guild.disbanded", func(guild *domain.Guild) error {
+		guild.Status = domain.GuildInactive
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	members := guild.Members
 
 	// Remove all agent guild mappings
 	for _, member := range members {
 		c.removeAgentGuild(member.AgentID, guildID)
 	}
 
-	// Persist updated guild to KV
-	if err := c.graph.EmitEntity(ctx, guild, "guild.disbanded"); err != nil {
-		c.logger.Error("failed to persist guild disbandment to KV", "guild_id", guildID, "error", err)
-	}
-
 	// Publish disband event
 	if err := SubjectGuildDisbanded.Publish(ctx, c.deps.NATSClient, GuildDisbandedPayload{
 		GuildID:          guildID,
@@ -448,33 +422,10 @@ func (c *Component) SubmitApplication(ctx context.Context, guildID domain.GuildI
 		return errors.New("component not running")
 	}
 
-	val, ok := c.guilds.Load(guildID)
-	if !ok {
+	if _, ok := c.guilds.Load(guildID); !ok {
 		return errors.New("guild not found")
 	}
 
-	mu := c.guildMutex(guildID)
-	mu.Lock()
-	guild := val.(*domain.Guild)
-
-	if guild.Status != domain.GuildPending {
-		mu.Unlock()
-		return ErrGuildNotPending
-	}
-
-	if isMember(guild, agent.ID) {
-		mu.Unlock()
-		return ErrAlreadyMember
-	}
-
-	// Check for duplicate pending application
-	for _, app := range guild.Applications {
-		if app.ApplicantID == agent.ID && app.Status == domain.ApplicationPending {
-			mu.Unlock()
-			return ErrDuplicateApplication
-		}
-	}
-
 	now := time.Now()
 	appID := domain.GenerateInstance()
 
@@ -496,13 +447,26 @@ func (c *Component) SubmitApplication(ctx context.Context, guildID domain.GuildI
 		AppliedAt:   now,
 	}
 
-	guild.Applications = append(guild.Applications, app)
-	mu.Unlock()
+	if _, err := c.updateGuild(ctx, guildID, domain.PredicateGuildApplicationSubmitted, func(guild *domain.Guild) error {
+		if guild.Status != domain.GuildPending {
+			return ErrGuildNotPending
+		}
 
-	// Persist to KV
-	if err := c.graph.EmitEntity(ctx, guild, domain.PredicateGuildApplicationSubmitted); err != nil {
-		c.errorsCount.Add(1)
-		c.logger.Error("failed to persist guild application", "guild_id", guildID, "error", err)
+		if isMember(guild, agent.ID) {
+			return ErrAlreadyMember
+		}
+
+		// Check for duplicate pending application
+		for _, existing := range guild.Applications {
+			if existing.ApplicantID == agent.ID && existing.Status == domain.ApplicationPending {
+				return ErrDuplicateApplication
+			}
+		}
+
+		guild.Applications = append(guild.Applications, app)
+		return nil
+	}); err != nil {
+		return err
 	}
 
 	c.lastActivity.Store(now)
@@ -520,52 +484,50 @@ func (c *Component) ReviewApplication(ctx context.Context, guildID domain.GuildI
 		return errors.New("component not running")
 	}
 
-	val, ok := c.guilds.Load(guildID)
-	if !ok {
+	if _, ok := c.guilds.Load(guildID); !ok {
 		return errors.New("guild not found")
 	}
 
-	mu := c.guildMutex(guildID)
-	mu.Lock()
-	guild := val.(*domain.Guild)
-
-	if guild.Status != domain.GuildPending {
-		mu.Unlock()
-		return ErrGuildNotPending
+	now := time.Now()
+	var applicantID domain.AgentID
+	predicate := domain.PredicateGuildApplicationRejected
+	if accepted {
+		predicate = domain.PredicateGuildApplicationAccepted
 	}
 
-	if guild.FoundedBy != founderID {
-		mu.Unlock()
-		return ErrNotFounder
-	}
+	if _, err := c.updateGuild(ctx, guildID, predicate, func(guild *domain.Guild) error {
+		if guild.Status != domain.GuildPending {
+			return ErrGuildNotPending
+		}
 
-	// Find the application
-	var app *domain.GuildApplication
-	for i := range guild.Applications {
-		if guild.Applications[i].ID == applicationID {
-			app = &guild.Applications[i]
-			break
+		if guild.FoundedBy != founderID {
+			return ErrNotFounder
 		}
-	}
-	if app == nil {
-		mu.Unlock()
-		return ErrApplicationNotFound
-	}
-	if app.Status != domain.ApplicationPending {
-		mu.Unlock()
-		return errors.New("application already reviewed")
-	}
 
-	now := time.Now()
-	app.ReviewedBy = &founderID
-	app.Reason = reason
-	app.ReviewedAt = &now
+		// Find the application
+		var app *domain.GuildApplication
+		for i := range guild.Applications {
+			if guild.Applications[i].ID == applicationID {
+				app = &guild.Applications[i]
+				break
+			}
+		}
+		if app == nil {
+			return ErrApplicationNotFound
+		}
+		if app.Status != domain.ApplicationPending {
+			return errors.New("application already reviewed")
+		}
 
-	var applicantID domain.AgentID
-	predicate := domain.PredicateGuildApplicationRejected
-	if accepted {
+		app.ReviewedBy = &founderID
+		app.Reason = reason
+		app.ReviewedAt = &now
+
+		if !accepted {
+			app.Status = domain.ApplicationRejected
+			return nil
+		}
 		app.Status = domain.ApplicationAccepted
-		predicate = domain.PredicateGuildApplicationAccepted
 		applicantID = app.ApplicantID
 
 		// Add applicant as guild member
@@ -574,10 +536,10 @@ func (c *Component) ReviewApplication(ctx context.Context, guildID domain.GuildI
 			Rank:     domain.GuildRankInitiate,
 			JoinedAt: now,
 		})
-	} else {
-		app.Status = domain.ApplicationRejected
+		return nil
+	}); err != nil {
+		return err
 	}
-	mu.Unlock()
 
 	// Agent guild mapping and metrics outside lock
 	if accepted {
@@ -585,12 +547,6 @@ func (c *Component) ReviewApplication(ctx context.Context, guildID domain.GuildI
 		c.membersJoined.Add(1)
 	}
 
-	// Persist to KV
-	if err := c.graph.EmitEntity(ctx, guild, predicate); err != nil {
-		c.errorsCount.Add(1)
-		c.logger.Error("failed to persist application review", "guild_id", guildID, "error", err)
-	}
-
 	c.lastActivity.Store(now)
 
 	verb := "rejected"
@@ -606,35 +562,33 @@ func (c *Component) ReviewApplication(ctx context.Context, guildID domain.GuildI
 
 	// Check if quorum is reached after acceptance
 	if accepted {
-		c.checkQuorumLocked(ctx, guildID, guild)
+		c.checkQuorum(ctx, guildID)
 	}
 
 	return nil
 }
 
-// checkQuorumLocked transitions a pending guild to active if the quorum is met.
-// Acquires the per-guild mutex internally.
-func (c *Component) checkQuorumLocked(ctx context.Context, guildID domain.GuildID, guild *domain.Guild) {
-	mu := c.guildMutex(guildID)
-	mu.Lock()
-	if guild.Status != domain.GuildPending {
-		mu.Unlock()
+// errGuildUnchanged aborts a guild update that has nothing to change.
+var errGuildUnchanged = errors.New("guild unchanged")
+
+// checkQuorum transitions a pending guild to active if the quorum is met.
+func (c *Component) checkQuorum(ctx context.Context, guildID domain.GuildID) {
+	guild, err := c.updateGuild(ctx, guildID, domain.PredicateGuildActivated, func(guild *domain.Guild) error {
+		if guild.Status != domain.GuildPending || len(guild.Members) < guild.QuorumSize {
+			return errGuildUnchanged
+		}
+		guild.Status = domain.GuildActive
+		guild.FormationDeadline = nil
+		return nil
+	})
+	if errors.Is(err, errGuildUnchanged) {
 		return
 	}
-	if len(guild.Members) < guild.QuorumSize {
-		mu.Unlock()
+	if err != nil {
+		c.logger.Error("failed to persist guild activation", "guild_id", guildID, "error", err)
 		return
 	}
 
-	guild.Status = domain.GuildActive
-	guild.FormationDeadline = nil
-	mu.Unlock()
-
-	if err := c.graph.EmitEntity(ctx, guild, domain.PredicateGuildActivated); err != nil {
-		c.errorsCount.Add(1)
-		c.logger.Error("failed to persist guild activation", "guild_id", guild.ID, "error", err)
-	}
-
 	c.logger.Info("guild reached quorum and activated",
 		"guild_id", guild.ID,
 		"guild_name", guild.Name,
@@ -643,36 +597,38 @@ func (c *Component) checkQuorumLocked(ctx context.Context, guildID domain.GuildI
 }
 
 // dissolveGuild dissolves a pending guild that failed to reach quorum.
-func (c *Component) dissolveGuild(ctx context.Context, guild *domain.Guild, reason string) {
-	mu := c.guildMutex(guild.ID)
-	mu.Lock()
-
-	guild.Status = domain.GuildInactive
-
-	// Reject all remaining pending applications
+func (c *Component) dissolveGuild(ctx context.Context, guildID domain.GuildID, reason string) {
 	now := time.Now()
-	for i := range guild.Applications {
-		if guild.Applications[i].Status == domain.ApplicationPending {
-			guild.Applications[i].Status = domain.ApplicationRejected
-			guild.Applications[i].Reason = reason
-			guild.Applications[i].ReviewedAt = &now
+	guild, err := c.updateGuild(ctx, guildID, domain.PredicateGuildDissolved, func(guild *domain.Guild) error {
+		// Quorum may have been reached since the timeout sweep read the cache.
+		if guild.Status != domain.GuildPending {
+			return errGuildUnchanged
+		}
+		guild.Status = domain.GuildInactive
+
+		// Reject all remaining pending applications
+		for i := range guild.Applications {
+			if guild.Applications[i].Status == domain.ApplicationPending {
+				guild.Applications[i].Status = domain.ApplicationRejected
+				guild.Applications[i].Reason = reason
+				guild.Applications[i].ReviewedAt = &now
+			}
 		}
+		return nil
+	})
+	if errors.Is(err, errGuildUnchanged) {
+		return
+	}
+	if err != nil {
+		c.logger.Error("failed to persist guild dissolution", "guild_id", guildID, "error", err)
+		return
 	}
-
-	// Snapshot members for cleanup outside the lock.
-	members := append([]domain.GuildMember(nil), guild.Members...)
-	mu.Unlock()
 
 	// Remove all agent guild mappings
-	for _, member := range members {
+	for _, member := range guild.Members {
 		c.removeAgentGuild(member.AgentID, guild.ID)
 	}
 
-	if err := c.graph.EmitEntity(ctx, guild, domain.PredicateGuildDissolved); err != nil {
-		c.errorsCount.Add(1)
-		c.logger.Error("failed to persist guild dissolution", "guild_id", guild.ID, "error", err)
-	}
-
 	c.lastActivity.Store(now)
 	c.logger.Info("pending guild dissolved",
 		"guild_id", guild.ID,
@@ -681,7 +637,8 @@ func (c *Component) dissolveGuild(ctx context.Context, guild *domain.Guild, reas
 }
 
 // runFormationTimeoutLoop periodically checks pending guilds and dissolves
-// any that have passed their formation deadline.
+// any that have passed their formation deadline, and settles guild proposals
+// whose voting deadline has passed.
 func (c *Component) runFormationTimeoutLoop() {
 	defer close(c.timeoutDoneCh)
 
@@ -694,6 +651,7 @@ func (c *Component) runFormationTimeoutLoop() {
 			return
 		case <-ticker.C:
 			c.checkFormationTimeouts()
+			c.checkProposalDeadlines()
 		}
 	}
 }
@@ -704,7 +662,7 @@ func (c *Component) checkFormationTimeouts() {
 
 	// Collect expired guilds first, then dissolve outside the Range to avoid
 	// holding sync.Map's internal lock during dissolveGuild's I/O.
-	var expired []*domain.Guild
+	var expired []domain.GuildID
 	c.guilds.Range(func(key, value any) bool {
 		guildID := key.(domain.GuildID)
 		mu := c.guildMutex(guildID)
@@ -715,14 +673,14 @@ func (c *Component) checkFormationTimeouts() {
 			now.After(*guild.FormationDeadline)
 		mu.Unlock()
 		if isExpired {
-			expired = append(expired, guild)
+			expired = append(expired, guildID)
 		}
 		return true
 	})
 
-	for _, guild := range expired {
+	for _, guildID := range expired {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		c.dissolveGuild(ctx, guild, "formation timeout: quorum not met")
+		c.dissolveGuild(ctx, guildID, "formation timeout: quorum not met")
 		cancel()
 	}
 }
@@ -758,6 +716,7 @@ func (c *Component) ListPendingGuilds() []*domain.Guild {
 			cp := *original
 			cp.Members = append([]domain.GuildMember(nil), original.Members...)
 			cp.Applications = append([]domain.GuildApplication(nil), original.Applications...)
+			cp.Proposals = domain.CopyProposals(original.Proposals)
 			cp.QuestTypes = append([]string(nil), original.QuestTypes...)
 			guilds = append(guilds, &cp)
 		}
@@ -780,6 +739,7 @@ func (c *Component) ListGuilds() []*domain.Guild {
 			cp := *original
 			cp.Members = append([]domain.GuildMember(nil), original.Members...)
 			cp.QuestTypes = append([]string(nil), original.QuestTypes...)
+			cp.Proposals = domain.CopyProposals(original.Proposals)
 			guilds = append(guilds, &cp)
 		}
 		mu.Unlock()
@@ -0,0 +1,380 @@
+package partycoord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/nats-io/nuid"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semstreams/natsclient"
+)
+
+// =============================================================================
+// PARTY BLACKBOARD - Shared scratchpad and member-to-member messages
+// =============================================================================
+// Members otherwise only talk through the lead and dependency outputs. The
+// blackboard gives parallel nodes a place to agree on shared interfaces:
+// keyed notes any member can read or overwrite, plus direct messages that are
+// delivered into the recipient's next loop iteration.
+//
+// Each party's blackboard is a single JSON document in the PARTY_BLACKBOARD
+// KV bucket, updated with compare-and-swap so concurrent tool calls from
+// different members never lose writes.
+// =============================================================================
+
+// BlackboardBucket is the KV bucket holding one blackboard per party.
+const BlackboardBucket = "PARTY_BLACKBOARD"
+
+// Blackboard limits. Notes and messages are injected into prompts, so every
+// dimension is bounded.
+const (
+	// MaxNoteKeyLength bounds a note key.
+	MaxNoteKeyLength = 64
+	// MaxNoteBytes bounds a single note's content.
+	MaxNoteBytes = 4000
+	// MaxNotes bounds the number of notes per party.
+	MaxNotes = 50
+	// MaxMessageBytes bounds a single direct message.
+	MaxMessageBytes = 2000
+	// MaxRetainedMessages bounds the message log per party. The oldest
+	// delivered messages are dropped first.
+	MaxRetainedMessages = 200
+	// MessageRateLimit is how many messages one member may send per
+	// MessageRateWindow.
+	MessageRateLimit = 5
+	// MessageRateWindow is the sliding window for MessageRateLimit.
+	MessageRateWindow = time.Minute
+)
+
+// blackboardMaxRetries bounds compare-and-swap retries on contended writes.
+const blackboardMaxRetries = 5
+
+// Blackboard errors.
+var (
+	// ErrNoteTooLarge is returned when a note or its key exceeds the limits.
+	ErrNoteTooLarge = errors.New("note too large")
+	// ErrTooManyNotes is returned when a new key would exceed MaxNotes.
+	ErrTooManyNotes = errors.New("party blackboard is full")
+	// ErrMessageTooLarge is returned when a message exceeds MaxMessageBytes.
+	ErrMessageTooLarge = errors.New("message too large")
+	// ErrRateLimited is returned when a member exceeds MessageRateLimit.
+	ErrRateLimited = errors.New("message rate limit exceeded")
+)
+
+// Blackboard is a party's shared scratchpad and message log.
+type Blackboard struct {
+	PartyID   domain.PartyID   `json:"party_id"`
+	Notes     []BlackboardNote `json:"notes"`
+	Messages  []PartyMessage   `json:"messages"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// BlackboardNote is a keyed entry on the blackboard. Writing an existing key
+// overwrites it.
+type BlackboardNote struct {
+	Key       string         `json:"key"`
+	Content   string         `json:"content"`
+	Author    domain.AgentID `json:"author"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// PartyMessage is a direct message between party members.
+type PartyMessage struct {
+	ID          string         `json:"id"`
+	From        domain.AgentID `json:"from"`
+	To          domain.AgentID `json:"to"`
+	Content     string         `json:"content"`
+	SentAt      time.Time      `json:"sent_at"`
+	DeliveredAt *time.Time     `json:"delivered_at,omitempty"`
+}
+
+// Note returns the note with the given key, or nil.
+func (b *Blackboard) Note(key string) *BlackboardNote {
+	for i := range b.Notes {
+		if b.Notes[i].Key == key {
+			return &b.Notes[i]
+		}
+	}
+	return nil
+}
+
+// Pending returns the undelivered messages addressed to agentID, oldest first.
+func (b *Blackboard) Pending(agentID domain.AgentID) []PartyMessage {
+	var pending []PartyMessage
+	for _, m := range b.Messages {
+		if m.To == agentID && m.DeliveredAt == nil {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// MemberIDs returns the party's lead followed by its other members.
+func (p *Party) MemberIDs() []domain.AgentID {
+	ids := make([]domain.AgentID, 0, len(p.Members)+1)
+	if p.Lead != "" {
+		ids = append(ids, p.Lead)
+	}
+	for _, m := range p.Members {
+		if m.AgentID != p.Lead {
+			ids = append(ids, m.AgentID)
+		}
+	}
+	return ids
+}
+
+// BlackboardStore reads and writes party blackboards in KV.
+type BlackboardStore struct {
+	kv  jetstream.KeyValue
+	now func() time.Time
+}
+
+// NewBlackboardStore creates a store over the PARTY_BLACKBOARD bucket.
+func NewBlackboardStore(kv jetstream.KeyValue) *BlackboardStore {
+	return &BlackboardStore{kv: kv, now: time.Now}
+}
+
+// EnsureBlackboardBucket creates the PARTY_BLACKBOARD KV bucket if it doesn't exist.
+func EnsureBlackboardBucket(ctx context.Context, nats *natsclient.Client) (jetstream.KeyValue, error) {
+	return nats.CreateKeyValueBucket(ctx, jetstream.KeyValueConfig{
+		Bucket:      BlackboardBucket,
+		Description: "Party shared notes and member messages",
+		History:     5,
+	})
+}
+
+// Get returns the party's blackboard. A party that has never written one
+// gets an empty blackboard.
+func (s *BlackboardStore) Get(ctx context.Context, partyID domain.PartyID) (*Blackboard, error) {
+	board, _, err := s.load(ctx, partyID)
+	return board, err
+}
+
+// WriteNote creates or overwrites a note.
+func (s *BlackboardStore) WriteNote(ctx context.Context, partyID domain.PartyID, author domain.AgentID, key, content string) (*BlackboardNote, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, errors.New("note key is required")
+	}
+	if len(key) > MaxNoteKeyLength || len(content) > MaxNoteBytes {
+		return nil, fmt.Errorf("%w: key max %d bytes, content max %d bytes", ErrNoteTooLarge, MaxNoteKeyLength, MaxNoteBytes)
+	}
+
+	var written BlackboardNote
+	err := s.update(ctx, partyID, func(b *Blackboard, now time.Time) error {
+		written = BlackboardNote{Key: key, Content: content, Author: author, UpdatedAt: now}
+		if existing := b.Note(key); existing != nil {
+			*existing = written
+			return nil
+		}
+		if len(b.Notes) >= MaxNotes {
+			return fmt.Errorf("%w: %d notes; overwrite an existing key", ErrTooManyNotes, MaxNotes)
+		}
+		b.Notes = append(b.Notes, written)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &written, nil
+}
+
+// SendMessage queues a direct message for delivery to another member.
+func (s *BlackboardStore) SendMessage(ctx context.Context, partyID domain.PartyID, from, to domain.AgentID, content string) (*PartyMessage, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New("message content is required")
+	}
+	if len(content) > MaxMessageBytes {
+		return nil, fmt.Errorf("%w: max %d bytes", ErrMessageTooLarge, MaxMessageBytes)
+	}
+
+	var sent PartyMessage
+	err := s.update(ctx, partyID, func(b *Blackboard, now time.Time) error {
+		recent := 0
+		for _, m := range b.Messages {
+			if m.From == from && now.Sub(m.SentAt) < MessageRateWindow {
+				recent++
+			}
+		}
+		if recent >= MessageRateLimit {
+			return fmt.Errorf("%w: %d messages per %s", ErrRateLimited, MessageRateLimit, MessageRateWindow)
+		}
+		sent = PartyMessage{ID: nuid.Next(), From: from, To: to, Content: content, SentAt: now}
+		b.Messages = append(b.Messages, sent)
+		b.Messages = trimMessages(b.Messages)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sent, nil
+}
+
+// TakeInbox returns agentID's undelivered messages and marks them delivered.
+// Returns nil without writing when there is nothing to deliver.
+func (s *BlackboardStore) TakeInbox(ctx context.Context, partyID domain.PartyID, agentID domain.AgentID) ([]PartyMessage, error) {
+	board, _, err := s.load(ctx, partyID)
+	if err != nil || len(board.Pending(agentID)) == 0 {
+		return nil, err
+	}
+
+	var delivered []PartyMessage
+	err = s.update(ctx, partyID, func(b *Blackboard, now time.Time) error {
+		delivered = nil
+		for i := range b.Messages {
+			m := &b.Messages[i]
+			if m.To == agentID && m.DeliveredAt == nil {
+				at := now
+				m.DeliveredAt = &at
+				delivered = append(delivered, *m)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delivered, nil
+}
+
+// load reads a blackboard and its KV revision. Revision 0 means the key
+// does not exist yet.
+func (s *BlackboardStore) load(ctx context.Context, partyID domain.PartyID) (*Blackboard, uint64, error) {
+	entry, err := s.kv.Get(ctx, string(partyID))
+	if err != nil {
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			return &Blackboard{PartyID: partyID}, 0, nil
+		}
+		return nil, 0, fmt.Errorf("read blackboard %s: %w", partyID, err)
+	}
+	var board Blackboard
+	if err := json.Unmarshal(entry.Value(), &board); err != nil {
+		return nil, 0, fmt.Errorf("decode blackboard %s: %w", partyID, err)
+	}
+	board.PartyID = partyID
+	return &board, entry.Revision(), nil
+}
+
+// update applies fn to the current blackboard and writes it back with
+// compare-and-swap, retrying when another member wrote in between. Errors
+// returned by fn abort the update without retrying.
+func (s *BlackboardStore) update(ctx context.Context, partyID domain.PartyID, fn func(b *Blackboard, now time.Time) error) error {
+	key := string(partyID)
+	for range blackboardMaxRetries {
+		board, revision, err := s.load(ctx, partyID)
+		if err != nil {
+			return err
+		}
+		now := s.now()
+		if err := fn(board, now); err != nil {
+			return err
+		}
+		board.UpdatedAt = now
+
+		data, err := json.Marshal(board)
+		if err != nil {
+			return fmt.Errorf("encode blackboard %s: %w", partyID, err)
+		}
+		if revision == 0 {
+			_, err = s.kv.Create(ctx, key, data)
+		} else {
+			_, err = s.kv.Update(ctx, key, data, revision)
+		}
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("write blackboard %s: exceeded %d CAS retries", partyID, blackboardMaxRetries)
+}
+
+// trimMessages drops the oldest delivered messages beyond MaxRetainedMessages.
+// Undelivered messages are only dropped when nothing else is left to drop.
+func trimMessages(msgs []PartyMessage) []PartyMessage {
+	excess := len(msgs) - MaxRetainedMessages
+	if excess <= 0 {
+		return msgs
+	}
+	kept := msgs[:0:0]
+	for _, m := range msgs {
+		if excess > 0 && m.DeliveredAt != nil {
+			excess--
+			continue
+		}
+		kept = append(kept, m)
+	}
+	if excess > 0 {
+		kept = kept[excess:]
+	}
+	return kept
+}
+
+// =============================================================================
+// PROMPT FORMATTING
+// =============================================================================
+
+// notePreviewChars bounds each note when the blackboard is summarized.
+const notePreviewChars = 300
+
+// FormatMessages renders delivered messages for an agent's context.
+func FormatMessages(msgs []PartyMessage) string {
+	if len(msgs) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("--- Party Messages ---\n")
+	for _, m := range msgs {
+		fmt.Fprintf(&sb, "- From %s: %s\n", m.From, m.Content)
+	}
+	return sb.String()
+}
+
+// PromptSummary renders the blackboard's notes for a member's system prompt,
+// most recently updated first. Each note is truncated and the summary stops
+// at maxChars; members read full notes with party_note_read.
+func (b *Blackboard) PromptSummary(maxChars int) string {
+	if len(b.Notes) == 0 {
+		return ""
+	}
+	notes := slices.Clone(b.Notes)
+	slices.SortStableFunc(notes, func(x, y BlackboardNote) int {
+		return y.UpdatedAt.Compare(x.UpdatedAt)
+	})
+
+	var sb strings.Builder
+	sb.WriteString("--- Party Blackboard ---\n")
+	sb.WriteString("Shared notes from your party. Read a full note with party_note_read; add or update one with party_note_write.\n\n")
+	for i, n := range notes {
+		line := fmt.Sprintf("- **%s** (%s): %s\n", n.Key, n.Author, previewText(n.Content, notePreviewChars))
+		if maxChars > 0 && sb.Len()+len(line) > maxChars {
+			fmt.Fprintf(&sb, "- … %d more notes\n", len(notes)-i)
+			break
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// previewText collapses whitespace and truncates s to n bytes on a rune
+// boundary.
+func previewText(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= n {
+		return s
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
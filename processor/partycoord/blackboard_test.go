@@ -0,0 +1,295 @@
+package partycoord
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/c360studio/semdragons/domain"
+)
+
+// =============================================================================
+// IN-MEMORY KV
+// =============================================================================
+
+// memKV implements the jetstream.KeyValue methods the blackboard store uses.
+// Unimplemented methods panic through the nil embedded interface.
+type memKV struct {
+	jetstream.KeyValue
+
+	mu      sync.Mutex
+	values  map[string][]byte
+	revs    map[string]uint64
+	updates int
+	// conflicts makes the next N Update calls fail as if another writer won.
+	conflicts int
+}
+
+func newMemKV() *memKV {
+	return &memKV{values: map[string][]byte{}, revs: map[string]uint64{}}
+}
+
+func (m *memKV) Get(_ context.Context, key string) (jetstream.KeyValueEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.values[key]
+	if !ok {
+		return nil, jetstream.ErrKeyNotFound
+	}
+	return memEntry{key: key, value: v, rev: m.revs[key]}, nil
+}
+
+func (m *memKV) Create(_ context.Context, key string, value []byte, _ ...jetstream.KVCreateOpt) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.values[key]; ok {
+		return 0, jetstream.ErrKeyExists
+	}
+	m.values[key] = value
+	m.revs[key] = 1
+	return 1, nil
+}
+
+func (m *memKV) Update(_ context.Context, key string, value []byte, last uint64) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.conflicts > 0 {
+		m.conflicts--
+		m.revs[key]++
+		return 0, errors.New("wrong last sequence")
+	}
+	if m.revs[key] != last {
+		return 0, errors.New("wrong last sequence")
+	}
+	m.updates++
+	m.values[key] = value
+	m.revs[key]++
+	return m.revs[key], nil
+}
+
+type memEntry struct {
+	jetstream.KeyValueEntry
+	key   string
+	value []byte
+	rev   uint64
+}
+
+func (e memEntry) Key() string      { return e.key }
+func (e memEntry) Value() []byte    { return e.value }
+func (e memEntry) Revision() uint64 { return e.rev }
+
+// newTestBlackboardStore returns a store with a controllable clock.
+func newTestBlackboardStore() (*BlackboardStore, *memKV, *time.Time) {
+	kv := newMemKV()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := NewBlackboardStore(kv)
+	store.now = func() time.Time { return now }
+	return store, kv, &now
+}
+
+// =============================================================================
+// BLACKBOARD TESTS
+// =============================================================================
+
+const testParty = domain.PartyID("local.dev.game.board1.party.p1")
+
+func TestBlackboardNotes(t *testing.T) {
+	store, _, _ := newTestBlackboardStore()
+	ctx := context.Background()
+
+	if _, err := store.WriteNote(ctx, testParty, "agent-a", "api", "GET /users returns []User"); err != nil {
+		t.Fatalf("WriteNote() error: %v", err)
+	}
+	if _, err := store.WriteNote(ctx, testParty, "agent-b", "api", "GET /users returns a page"); err != nil {
+		t.Fatalf("WriteNote() overwrite error: %v", err)
+	}
+
+	board, err := store.Get(ctx, testParty)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if len(board.Notes) != 1 {
+		t.Fatalf("notes = %d, want 1 after overwrite", len(board.Notes))
+	}
+	if n := board.Note("api"); n == nil || n.Author != "agent-b" || n.Content != "GET /users returns a page" {
+		t.Errorf("note = %+v", n)
+	}
+
+	if _, err := store.WriteNote(ctx, testParty, "agent-a", "big", strings.Repeat("x", MaxNoteBytes+1)); !errors.Is(err, ErrNoteTooLarge) {
+		t.Errorf("oversized note error = %v, want ErrNoteTooLarge", err)
+	}
+	if _, err := store.WriteNote(ctx, testParty, "agent-a", "  ", "x"); err == nil {
+		t.Error("expected error for empty key")
+	}
+}
+
+func TestBlackboardNoteLimit(t *testing.T) {
+	store, _, _ := newTestBlackboardStore()
+	ctx := context.Background()
+
+	for i := range MaxNotes {
+		if _, err := store.WriteNote(ctx, testParty, "agent-a", "k"+strings.Repeat("x", i), "v"); err != nil {
+			t.Fatalf("WriteNote(%d) error: %v", i, err)
+		}
+	}
+	if _, err := store.WriteNote(ctx, testParty, "agent-a", "one-too-many", "v"); !errors.Is(err, ErrTooManyNotes) {
+		t.Errorf("error = %v, want ErrTooManyNotes", err)
+	}
+	// Overwriting an existing key still works on a full board.
+	if _, err := store.WriteNote(ctx, testParty, "agent-a", "k", "updated"); err != nil {
+		t.Errorf("overwrite on full board error: %v", err)
+	}
+}
+
+func TestBlackboardMessages(t *testing.T) {
+	store, _, now := newTestBlackboardStore()
+	ctx := context.Background()
+
+	if _, err := store.SendMessage(ctx, testParty, "agent-a", "agent-b", "I renamed User.id to User.uuid"); err != nil {
+		t.Fatalf("SendMessage() error: %v", err)
+	}
+	if _, err := store.SendMessage(ctx, testParty, "agent-c", "agent-a", "unrelated"); err != nil {
+		t.Fatalf("SendMessage() error: %v", err)
+	}
+
+	*now = now.Add(time.Second)
+	inbox, err := store.TakeInbox(ctx, testParty, "agent-b")
+	if err != nil {
+		t.Fatalf("TakeInbox() error: %v", err)
+	}
+	if len(inbox) != 1 || inbox[0].From != "agent-a" || inbox[0].DeliveredAt == nil {
+		t.Fatalf("inbox = %+v", inbox)
+	}
+
+	again, err := store.TakeInbox(ctx, testParty, "agent-b")
+	if err != nil || len(again) != 0 {
+		t.Errorf("second TakeInbox() = %v, %v; want empty", again, err)
+	}
+
+	board, _ := store.Get(ctx, testParty)
+	if got := board.Pending("agent-a"); len(got) != 1 {
+		t.Errorf("agent-a pending = %d, want 1", len(got))
+	}
+}
+
+func TestBlackboardMessageRateLimit(t *testing.T) {
+	store, _, now := newTestBlackboardStore()
+	ctx := context.Background()
+
+	for i := range MessageRateLimit {
+		if _, err := store.SendMessage(ctx, testParty, "agent-a", "agent-b", "ping"); err != nil {
+			t.Fatalf("SendMessage(%d) error: %v", i, err)
+		}
+	}
+	if _, err := store.SendMessage(ctx, testParty, "agent-a", "agent-b", "ping"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("error = %v, want ErrRateLimited", err)
+	}
+	// Other members have their own budget.
+	if _, err := store.SendMessage(ctx, testParty, "agent-b", "agent-a", "pong"); err != nil {
+		t.Errorf("other sender error: %v", err)
+	}
+
+	*now = now.Add(MessageRateWindow)
+	if _, err := store.SendMessage(ctx, testParty, "agent-a", "agent-b", "ping"); err != nil {
+		t.Errorf("after window error: %v", err)
+	}
+
+	if _, err := store.SendMessage(ctx, testParty, "agent-c", "agent-b", strings.Repeat("x", MaxMessageBytes+1)); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("oversized message error = %v, want ErrMessageTooLarge", err)
+	}
+}
+
+func TestBlackboardRetriesOnConflict(t *testing.T) {
+	store, kv, _ := newTestBlackboardStore()
+	ctx := context.Background()
+
+	if _, err := store.WriteNote(ctx, testParty, "agent-a", "a", "1"); err != nil {
+		t.Fatal(err)
+	}
+	kv.conflicts = 2
+	if _, err := store.WriteNote(ctx, testParty, "agent-b", "b", "2"); err != nil {
+		t.Fatalf("WriteNote() after conflicts error: %v", err)
+	}
+	board, _ := store.Get(ctx, testParty)
+	if len(board.Notes) != 2 {
+		t.Errorf("notes = %d, want 2", len(board.Notes))
+	}
+
+	kv.conflicts = blackboardMaxRetries
+	if _, err := store.WriteNote(ctx, testParty, "agent-b", "c", "3"); err == nil {
+		t.Error("expected error after exhausting retries")
+	}
+}
+
+func TestTrimMessages(t *testing.T) {
+	delivered := time.Now()
+	msgs := make([]PartyMessage, 0, MaxRetainedMessages+2)
+	for i := range MaxRetainedMessages + 2 {
+		m := PartyMessage{ID: string(rune('a' + i%26))}
+		if i < 3 {
+			m.DeliveredAt = &delivered
+		}
+		msgs = append(msgs, m)
+	}
+
+	trimmed := trimMessages(msgs)
+	if len(trimmed) != MaxRetainedMessages {
+		t.Fatalf("len = %d, want %d", len(trimmed), MaxRetainedMessages)
+	}
+	delivCount := 0
+	for _, m := range trimmed {
+		if m.DeliveredAt != nil {
+			delivCount++
+		}
+	}
+	if delivCount != 1 {
+		t.Errorf("delivered kept = %d, want 1 (oldest delivered dropped first)", delivCount)
+	}
+}
+
+func TestBlackboardPromptSummary(t *testing.T) {
+	now := time.Now()
+	board := &Blackboard{Notes: []BlackboardNote{
+		{Key: "old", Content: "first", Author: "agent-a", UpdatedAt: now.Add(-time.Hour)},
+		{Key: "new", Content: "second\n\nline", Author: "agent-b", UpdatedAt: now},
+		{Key: "long", Content: strings.Repeat("y", 1000), Author: "agent-c", UpdatedAt: now.Add(-time.Minute)},
+	}}
+
+	summary := board.PromptSummary(0)
+	if !strings.HasPrefix(summary, "--- Party Blackboard ---") {
+		t.Errorf("summary missing heading:\n%s", summary)
+	}
+	if strings.Index(summary, "**new**") > strings.Index(summary, "**old**") {
+		t.Errorf("most recent note should come first:\n%s", summary)
+	}
+	if !strings.Contains(summary, "second line") {
+		t.Errorf("whitespace not collapsed:\n%s", summary)
+	}
+	if strings.Contains(summary, strings.Repeat("y", notePreviewChars+1)) {
+		t.Error("long note not truncated")
+	}
+
+	bounded := board.PromptSummary(250)
+	if !strings.Contains(bounded, "more notes") || len(bounded) > 400 {
+		t.Errorf("bounded summary = %d chars:\n%s", len(bounded), bounded)
+	}
+
+	if (&Blackboard{}).PromptSummary(100) != "" {
+		t.Error("empty blackboard should render nothing")
+	}
+}
+
+func TestFormatMessages(t *testing.T) {
+	out := FormatMessages([]PartyMessage{{From: "agent-a", Content: "hello"}})
+	if !strings.Contains(out, "--- Party Messages ---") || !strings.Contains(out, "From agent-a: hello") {
+		t.Errorf("FormatMessages() = %q", out)
+	}
+	if FormatMessages(nil) != "" {
+		t.Error("FormatMessages(nil) should be empty")
+	}
+}
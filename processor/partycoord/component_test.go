@@ -571,6 +571,58 @@ func TestComponent_AssignTaskPartyNotFound(t *testing.T) {
 	}
 }
 
+func TestComponent_ReplaceMember(t *testing.T) {
+	testClient := natsclient.NewTestClient(t, natsclient.WithKV(), natsclient.WithFileStorage(), natsclient.WithKVBuckets(graph.BucketEntityStates))
+	client := testClient.Client
+	ctx := context.Background()
+
+	comp := setupPartyComponent(t, client, "replacemember")
+	defer comp.Stop(5 * time.Second)
+
+	leadID := makePartyAgentID(t, comp.boardConfig, "swap-lead")
+	departedID := makePartyAgentID(t, comp.boardConfig, "swap-departed")
+	replacementID := makePartyAgentID(t, comp.boardConfig, "swap-replacement")
+	questID := makePartyQuestID(t, comp.boardConfig, "swap-quest")
+	sq := makePartyQuestID(t, comp.boardConfig, "swap-sub1")
+
+	party, err := comp.FormParty(ctx, questID, leadID)
+	if err != nil {
+		t.Fatalf("FormParty failed: %v", err)
+	}
+	if err := comp.JoinParty(ctx, party.ID, departedID, domain.RoleExecutor); err != nil {
+		t.Fatalf("JoinParty failed: %v", err)
+	}
+	if err := comp.AssignTask(ctx, party.ID, sq, departedID, "first pick"); err != nil {
+		t.Fatalf("AssignTask failed: %v", err)
+	}
+
+	if err := comp.ReplaceMember(ctx, party.ID, leadID, replacementID, sq, "stalled"); err == nil {
+		t.Error("ReplaceMember should refuse to replace the lead")
+	}
+	if err := comp.ReplaceMember(ctx, party.ID, departedID, replacementID, sq, "stalled"); err != nil {
+		t.Fatalf("ReplaceMember failed: %v", err)
+	}
+
+	updated, ok := comp.GetParty(party.ID)
+	if !ok {
+		t.Fatal("GetParty: party not found after swap")
+	}
+	for _, m := range updated.Members {
+		if m.AgentID == departedID {
+			t.Error("departed member should be removed from the party")
+		}
+	}
+	if len(updated.Members) != 2 {
+		t.Errorf("Members count = %d, want 2 (lead + replacement)", len(updated.Members))
+	}
+	if updated.SubQuestMap[sq] != replacementID {
+		t.Errorf("SubQuestMap[sq] = %v, want %v", updated.SubQuestMap[sq], replacementID)
+	}
+	if len(updated.Swaps) != 1 || updated.Swaps[0].Reason != "stalled" {
+		t.Errorf("Swaps = %+v, want one stalled swap", updated.Swaps)
+	}
+}
+
 func TestComponent_SubmitResult(t *testing.T) {
 	testClient := natsclient.NewTestClient(t, natsclient.WithKV(), natsclient.WithFileStorage(), natsclient.WithKVBuckets(graph.BucketEntityStates))
 	client := testClient.Client
@@ -117,6 +117,87 @@ func (c *Component) JoinParty(ctx context.Context, partyID domain.PartyID, agent
 	return nil
 }
 
+// ReplaceMember swaps a departed member for a replacement agent. The
+// replacement joins as an executor unless already a member, takes over the
+// departed member's sub-quest assignment, and the swap is appended to the
+// party's history.
+func (c *Component) ReplaceMember(ctx context.Context, partyID domain.PartyID, departed, replacement domain.AgentID, subQuestID domain.QuestID, reason string) error {
+	if departed == replacement {
+		return errors.New("replacement must differ from departed member")
+	}
+	val, ok := c.activeParties.Load(partyID)
+	if !ok {
+		return errors.New("party not found")
+	}
+
+	party := val.(*Party)
+	if departed == party.Lead {
+		return errors.New("cannot replace the party lead")
+	}
+	now := time.Now()
+
+	members := make([]PartyMember, 0, len(party.Members)+1)
+	present := false
+	for _, m := range party.Members {
+		if m.AgentID == departed {
+			continue
+		}
+		if m.AgentID == replacement {
+			present = true
+		}
+		members = append(members, m)
+	}
+	if !present {
+		members = append(members, PartyMember{
+			AgentID:  replacement,
+			Role:     domain.RoleExecutor,
+			JoinedAt: now,
+		})
+	}
+	party.Members = members
+
+	if subQuestID != "" {
+		party.SubQuestMap[subQuestID] = replacement
+	}
+	party.Swaps = append(party.Swaps, MemberSwap{
+		SubQuestID:  subQuestID,
+		Departed:    departed,
+		Replacement: replacement,
+		Reason:      reason,
+		SwappedAt:   now,
+	})
+
+	// Emit updated party state
+	if err := c.graph.EmitEntity(ctx, party, domain.PredicatePartySwapped); err != nil {
+		c.errorsCount.Add(1)
+		return errs.Wrap(err, "PartyCoord", "ReplaceMember", "emit party entity")
+	}
+
+	// Publish swap event
+	if err := SubjectPartyMemberSwapped.Publish(ctx, c.deps.NATSClient, PartyMemberSwappedPayload{
+		PartyID:     partyID,
+		SubQuestID:  subQuestID,
+		Departed:    departed,
+		Replacement: replacement,
+		Reason:      reason,
+		SwappedAt:   now,
+	}); err != nil {
+		c.errorsCount.Add(1)
+		return errs.Wrap(err, "PartyCoord", "ReplaceMember", "publish member swapped")
+	}
+
+	c.lastActivity.Store(now)
+
+	c.logger.Info("party member replaced",
+		"party_id", partyID,
+		"departed", departed,
+		"replacement", replacement,
+		"sub_quest_id", subQuestID,
+		"reason", reason)
+
+	return nil
+}
+
 // DisbandParty disbands a party.
 func (c *Component) DisbandParty(ctx context.Context, partyID domain.PartyID, reason string) error {
 	val, ok := c.activeParties.Load(partyID)
@@ -181,6 +262,56 @@ func (c *Component) ListActiveParties() []*Party {
 	return parties
 }
 
+// FormSubParty forms a sub-party to run a nested DAG decomposed from a party
+// sub-quest. The lead is the Master-tier member who decomposed the sub-quest;
+// members are recruited from idle agents that are not already in an active
+// party, up to the larger of the quest's MinPartySize and width+1 (lead plus
+// one executor per parallel node). The sub-party is disbanded again if no
+// executor could be recruited, since a party lead never works DAG nodes itself.
+func (c *Component) FormSubParty(ctx context.Context, quest *domain.Quest, leadID domain.AgentID, width int) (*Party, error) {
+	agents, err := c.findIdleAgents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	busy := make(map[domain.AgentID]struct{})
+	for _, p := range c.ListActiveParties() {
+		for _, m := range p.Members {
+			busy[m.AgentID] = struct{}{}
+		}
+	}
+	candidates := make([]agentprogression.Agent, 0, len(agents))
+	for _, agent := range agents {
+		if _, inParty := busy[agent.ID]; !inParty {
+			candidates = append(candidates, agent)
+		}
+	}
+
+	party, err := c.FormParty(ctx, quest.ID, leadID)
+	if err != nil {
+		return nil, err
+	}
+
+	target := *quest
+	target.MinPartySize = max(quest.MinPartySize, width+1)
+	recruited := c.recruitMembers(ctx, party.ID, leadID, candidates, &target)
+	if recruited == 0 {
+		if disbandErr := c.DisbandParty(ctx, party.ID, "no idle agents for sub-party"); disbandErr != nil {
+			c.logger.Warn("failed to disband empty sub-party",
+				"party_id", party.ID, "error", disbandErr)
+		}
+		return nil, fmt.Errorf("no idle agents available to join sub-party for quest %s", quest.ID)
+	}
+
+	c.logger.Info("sub-party formed for nested DAG",
+		"quest_id", quest.ID,
+		"party_id", party.ID,
+		"lead", leadID,
+		"members_recruited", recruited)
+
+	return party, nil
+}
+
 // =============================================================================
 // COORDINATION HANDLERS
 // =============================================================================
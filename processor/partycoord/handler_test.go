@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semstreams/graph"
 	"github.com/c360studio/semstreams/message"
 )
 
@@ -404,6 +405,47 @@ func TestParty_Triples_SubResultsCount(t *testing.T) {
 	})
 }
 
+func TestParty_Triples_SwapsRoundTrip(t *testing.T) {
+	departed := domain.AgentID("c360.prod.game.board1.agent.a001")
+	replacement := domain.AgentID("c360.prod.game.board1.agent.a002")
+	party := &Party{
+		ID:       "c360.prod.game.board1.party.p010",
+		Lead:     "c360.prod.game.board1.agent.lead",
+		FormedAt: time.Now(),
+		Members: []PartyMember{
+			{AgentID: "c360.prod.game.board1.agent.lead", Role: domain.RoleLead},
+			{AgentID: replacement, Role: domain.RoleExecutor},
+		},
+		Swaps: []MemberSwap{{
+			SubQuestID:  "c360.prod.game.board1.quest.sq1",
+			Departed:    departed,
+			Replacement: replacement,
+			Reason:      "stalled",
+			SwappedAt:   time.Now(),
+		}},
+	}
+
+	triples := party.Triples()
+	tr, ok := tripleForPredicate(triples, "party.member."+string(departed)+".replaced_by")
+	if !ok {
+		t.Fatal("missing replaced_by triple for departed member")
+	}
+	if tr.Object != string(replacement) {
+		t.Errorf("replaced_by = %v, want %q", tr.Object, replacement)
+	}
+
+	got := PartyFromEntityState(&graph.EntityState{ID: string(party.ID), Triples: triples})
+	if len(got.Swaps) != 1 {
+		t.Fatalf("reconstructed swaps = %d, want 1", len(got.Swaps))
+	}
+	if got.Swaps[0].Departed != departed || got.Swaps[0].Replacement != replacement {
+		t.Errorf("reconstructed swap = %+v", got.Swaps[0])
+	}
+	if len(got.Members) != 2 {
+		t.Errorf("reconstructed members = %d, want 2 (departed member excluded)", len(got.Members))
+	}
+}
+
 // =============================================================================
 // PAYLOAD VALIDATE TESTS
 // =============================================================================
@@ -1239,6 +1281,75 @@ func TestPartyJoinedPayload_EntityID(t *testing.T) {
 	}
 }
 
+func TestPartyMemberSwappedPayload_Validate(t *testing.T) {
+	valid := PartyMemberSwappedPayload{
+		PartyID:     "c360.prod.game.board1.party.p001",
+		Departed:    "c360.prod.game.board1.agent.a001",
+		Replacement: "c360.prod.game.board1.agent.a002",
+		Reason:      "stalled",
+		SwappedAt:   time.Now(),
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(p *PartyMemberSwappedPayload)
+		errMsg string
+	}{
+		{name: "valid payload", mutate: func(*PartyMemberSwappedPayload) {}},
+		{name: "missing party_id", mutate: func(p *PartyMemberSwappedPayload) { p.PartyID = "" }, errMsg: "party_id required"},
+		{name: "missing departed", mutate: func(p *PartyMemberSwappedPayload) { p.Departed = "" }, errMsg: "departed required"},
+		{name: "missing replacement", mutate: func(p *PartyMemberSwappedPayload) { p.Replacement = "" }, errMsg: "replacement required"},
+		{name: "self swap", mutate: func(p *PartyMemberSwappedPayload) { p.Replacement = p.Departed }, errMsg: "must differ"},
+		{name: "zero swapped_at", mutate: func(p *PartyMemberSwappedPayload) { p.SwappedAt = time.Time{} }, errMsg: "swapped_at required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.mutate(&p)
+			err := p.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestPartyMemberSwappedPayload_Triples(t *testing.T) {
+	partyID := domain.PartyID("c360.prod.game.board1.party.p001")
+	departed := domain.AgentID("c360.prod.game.board1.agent.a001")
+	replacement := domain.AgentID("c360.prod.game.board1.agent.a002")
+	subQuestID := domain.QuestID("c360.prod.game.board1.quest.sq1")
+
+	payload := &PartyMemberSwappedPayload{
+		PartyID:     partyID,
+		SubQuestID:  subQuestID,
+		Departed:    departed,
+		Replacement: replacement,
+		SwappedAt:   time.Now(),
+	}
+	if payload.EntityID() != string(partyID) {
+		t.Errorf("EntityID() = %q, want %q", payload.EntityID(), partyID)
+	}
+
+	triples := payload.Triples()
+	if tr, ok := tripleForPredicate(triples, "party.member."+string(departed)+".replaced_by"); !ok || tr.Object != string(replacement) {
+		t.Errorf("replaced_by triple = %+v, found %v", tr, ok)
+	}
+	if tr, ok := tripleForPredicate(triples, "party.assignment."+string(subQuestID)); !ok || tr.Object != string(replacement) {
+		t.Errorf("assignment triple = %+v, found %v", tr, ok)
+	}
+	if s := payload.Schema(); s.Category != "party.swapped" {
+		t.Errorf("Schema().Category = %q, want %q", s.Category, "party.swapped")
+	}
+}
+
 func TestPartyQuestDecomposedPayload_Triples(t *testing.T) {
 	partyID := domain.PartyID("c360.prod.game.board1.party.p001")
 	leadID := domain.AgentID("c360.prod.game.board1.agent.lead")
@@ -37,6 +37,9 @@ type Party struct {
 	SubResults   map[domain.QuestID]any `json:"sub_results,omitempty"`   // Collected sub-quest outputs
 	RollupResult any                    `json:"rollup_result,omitempty"` // Lead's combined result
 
+	// History
+	Swaps []MemberSwap `json:"swaps,omitempty"` // Members replaced mid-quest, oldest first
+
 	FormedAt    time.Time  `json:"formed_at"`
 	DisbandedAt *time.Time `json:"disbanded_at,omitempty"`
 }
@@ -49,6 +52,16 @@ type PartyMember struct {
 	JoinedAt time.Time         `json:"joined_at"`
 }
 
+// MemberSwap records a member replaced mid-quest, typically because they
+// stalled on a sub-quest or retired, and the agent who took over their work.
+type MemberSwap struct {
+	SubQuestID  domain.QuestID `json:"sub_quest_id,omitempty"`
+	Departed    domain.AgentID `json:"departed"`
+	Replacement domain.AgentID `json:"replacement"`
+	Reason      string         `json:"reason"`
+	SwappedAt   time.Time      `json:"swapped_at"`
+}
+
 // ContextItem represents a piece of shared knowledge in a party.
 type ContextItem struct {
 	Key     string         `json:"key"`
@@ -120,6 +133,14 @@ func (p *Party) Triples() []message.Triple {
 		})
 	}
 
+	// Member swaps
+	for _, swap := range p.Swaps {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "party.member." + string(swap.Departed) + ".replaced_by", Object: string(swap.Replacement),
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+
 	// Disbanded time if set
 	if p.DisbandedAt != nil {
 		triples = append(triples, message.Triple{
@@ -160,6 +181,7 @@ func PartyFromEntityState(entity *graph.EntityState) *Party {
 
 	// Track member data by agent ID for reconstruction
 	memberRoles := make(map[domain.AgentID]domain.PartyRole)
+	var swapped []MemberSwap
 
 	for _, triple := range entity.Triples {
 		switch triple.Predicate {
@@ -201,8 +223,14 @@ func PartyFromEntityState(entity *graph.EntityState) *Party {
 					agentID := domain.AgentID(rest[:i])
 					suffix := rest[i+1:]
 
-					if suffix == "role" {
+					switch suffix {
+					case "role":
 						memberRoles[agentID] = domain.PartyRole(domain.AsString(triple.Object))
+					case "replaced_by":
+						swapped = append(swapped, MemberSwap{
+							Departed:    agentID,
+							Replacement: domain.AgentID(domain.AsString(triple.Object)),
+						})
 					}
 					break
 				}
@@ -217,6 +245,7 @@ func PartyFromEntityState(entity *graph.EntityState) *Party {
 			Role:    role,
 		})
 	}
+	p.Swaps = swapped
 
 	return p
 }
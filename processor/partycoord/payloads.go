@@ -26,6 +26,7 @@ var (
 	_ graph.Graphable = (*PartyFormedPayload)(nil)
 	_ graph.Graphable = (*PartyDisbandedPayload)(nil)
 	_ graph.Graphable = (*PartyJoinedPayload)(nil)
+	_ graph.Graphable = (*PartyMemberSwappedPayload)(nil)
 	_ graph.Graphable = (*PartyQuestDecomposedPayload)(nil)
 	_ graph.Graphable = (*PartyTaskAssignedPayload)(nil)
 	_ graph.Graphable = (*PartyResultSubmittedPayload)(nil)
@@ -38,6 +39,7 @@ var (
 	SubjectPartyFormed          = natsclient.NewSubject[PartyFormedPayload](domain.PredicatePartyFormed)
 	SubjectPartyDisbanded       = natsclient.NewSubject[PartyDisbandedPayload](domain.PredicatePartyDisbanded)
 	SubjectPartyJoined          = natsclient.NewSubject[PartyJoinedPayload](domain.PredicatePartyJoined)
+	SubjectPartyMemberSwapped   = natsclient.NewSubject[PartyMemberSwappedPayload](domain.PredicatePartySwapped)
 	SubjectPartyQuestDecomposed = natsclient.NewSubject[PartyQuestDecomposedPayload](domain.PredicatePartyQuestDecomposed)
 	SubjectPartyTaskAssigned    = natsclient.NewSubject[PartyTaskAssignedPayload](domain.PredicatePartyTaskAssigned)
 	SubjectPartyResultSubmitted = natsclient.NewSubject[PartyResultSubmittedPayload](domain.PredicatePartyResultSubmitted)
@@ -180,6 +182,70 @@ func (p *PartyJoinedPayload) Validate() error {
 	return nil
 }
 
+// =============================================================================
+// PARTY MEMBER SWAPPED PAYLOAD
+// =============================================================================
+
+// PartyMemberSwappedPayload contains data for party.membership.swapped events.
+// Emitted when a stalled or departed member is replaced on a sub-quest.
+type PartyMemberSwappedPayload struct {
+	PartyID     domain.PartyID `json:"party_id"`
+	SubQuestID  domain.QuestID `json:"sub_quest_id,omitempty"`
+	Departed    domain.AgentID `json:"departed"`
+	Replacement domain.AgentID `json:"replacement"`
+	Reason      string         `json:"reason"`
+	SwappedAt   time.Time      `json:"swapped_at"`
+	Trace       TraceInfo      `json:"trace,omitempty"`
+}
+
+// EntityID returns the entity ID for this event.
+func (p *PartyMemberSwappedPayload) EntityID() string { return string(p.PartyID) }
+
+// Triples returns semantic triples for this event.
+func (p *PartyMemberSwappedPayload) Triples() []message.Triple {
+	source := "partycoord"
+	entityID := string(p.PartyID)
+
+	triples := []message.Triple{
+		{Subject: entityID, Predicate: "party.member." + string(p.Departed) + ".replaced_by", Object: string(p.Replacement), Source: source, Timestamp: p.SwappedAt, Confidence: 1.0},
+		{Subject: entityID, Predicate: "party.membership.member", Object: string(p.Replacement), Source: source, Timestamp: p.SwappedAt, Confidence: 1.0},
+		{Subject: string(p.Replacement), Predicate: "agent.membership.party", Object: string(p.PartyID), Source: source, Timestamp: p.SwappedAt, Confidence: 1.0},
+	}
+	if p.SubQuestID != "" {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "party.assignment." + string(p.SubQuestID), Object: string(p.Replacement),
+			Source: source, Timestamp: p.SwappedAt, Confidence: 1.0,
+		})
+	}
+
+	return triples
+}
+
+// Schema returns the type schema for this payload.
+func (p *PartyMemberSwappedPayload) Schema() types.Type {
+	return types.Type{Domain: "semdragons", Category: "party.swapped", Version: "v1"}
+}
+
+// Validate checks the payload for required fields.
+func (p *PartyMemberSwappedPayload) Validate() error {
+	if p.PartyID == "" {
+		return errors.New("party_id required")
+	}
+	if p.Departed == "" {
+		return errors.New("departed required")
+	}
+	if p.Replacement == "" {
+		return errors.New("replacement required")
+	}
+	if p.Departed == p.Replacement {
+		return errors.New("replacement must differ from departed")
+	}
+	if p.SwappedAt.IsZero() {
+		return errors.New("swapped_at required")
+	}
+	return nil
+}
+
 // =============================================================================
 // PARTY QUEST DECOMPOSED PAYLOAD
 // =============================================================================
@@ -1,6 +1,7 @@
 package promptmanager
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 )
@@ -138,6 +139,7 @@ func (a *PromptAssembler) AssembleSystemPrompt(ctx AssemblyContext) AssembledPro
 					deps.WriteString(dep.RawOutput + "\n")
 				}
 			}
+			writeTypedOutput(&deps, dep.TypedOutput)
 		}
 		sections = append(sections, formatSection("Dependency Outputs", deps.String(), style))
 		usedIDs = append(usedIDs, "dependency-contexts")
@@ -147,6 +149,7 @@ func (a *PromptAssembler) AssembleSystemPrompt(ctx AssemblyContext) AssembledPro
 		deps.WriteString("The following predecessor tasks have been completed. Use their outputs as context for your work:\n")
 		for _, dep := range ctx.DependencyOutputs {
 			deps.WriteString(fmt.Sprintf("\n--- %s: %s ---\n%s\n", dep.NodeID, dep.Objective, dep.Output))
+			writeTypedOutput(&deps, dep.TypedOutput)
 		}
 		sections = append(sections, formatSection("Dependency Outputs", deps.String(), style))
 		usedIDs = append(usedIDs, "dependency-outputs")
@@ -165,6 +168,21 @@ func (a *PromptAssembler) AssembleSystemPrompt(ctx AssemblyContext) AssembledPro
 		usedIDs = append(usedIDs, "structural-checklist")
 	}
 
+	// Inject the DAG node's output contract so the agent submits structured
+	// output that downstream nodes can consume.
+	if len(ctx.OutputSchema) > 0 {
+		schema, err := json.MarshalIndent(ctx.OutputSchema, "", "  ")
+		if err == nil {
+			var contract strings.Builder
+			contract.WriteString("Your result feeds later steps of the party plan, so it must be structured. ")
+			contract.WriteString("Pass it as the `output` object of submit_work, matching this JSON Schema. ")
+			contract.WriteString("Submissions that do not match are rejected with the validation errors:\n")
+			contract.WriteString("```json\n" + string(schema) + "\n```\n")
+			sections = append(sections, formatSection("Output Contract", contract.String(), style))
+			usedIDs = append(usedIDs, "output-contract")
+		}
+	}
+
 	// Inject clarification answers from previous interactions (party lead or DM).
 	// These appear before agent overrides so the agent has context for its retry.
 	if len(ctx.ClarificationAnswers) > 0 {
@@ -228,6 +246,22 @@ DELIVERABLE RULES:
 // INTERNAL HELPERS
 // =============================================================================
 
+// writeTypedOutput appends a predecessor's contract-validated structured
+// output as a JSON block. Downstream agents should read fields from it rather
+// than re-parsing the free-text output.
+func writeTypedOutput(sb *strings.Builder, typed any) {
+	if typed == nil {
+		return
+	}
+	data, err := json.MarshalIndent(typed, "", "  ")
+	if err != nil {
+		return
+	}
+	sb.WriteString("Typed output (validated against the node's output contract):\n```json\n")
+	sb.Write(data)
+	sb.WriteString("\n```\n")
+}
+
 // failuresMentionNoWork returns true if any failure reason indicates the agent
 // submitted a question or empty response instead of actual work. Used to inject
 // explicit tool guidance (use ask_clarification) into the retry prompt.
@@ -302,10 +336,14 @@ func categoryLabel(cat FragmentCategory) string {
 		return "Tool Guidance"
 	case CategoryGuildKnowledge:
 		return "Guild Knowledge"
+	case CategoryAgentMemory:
+		return "Past Quests"
 	case CategoryReviewBrief:
 		return "Review"
 	case CategoryPersona:
 		return "Persona"
+	case CategoryActiveEffects:
+		return "Active Effects"
 	case CategoryQuestContext:
 		return "Quest"
 	default:
@@ -551,6 +551,38 @@ func TestAssembly_WithDependencyOutputs(t *testing.T) {
 	}
 }
 
+func TestAssembly_TypedDependencyOutputAndContract(t *testing.T) {
+	assembler, _ := newTestAssembler()
+
+	result := assembler.AssembleSystemPrompt(AssemblyContext{
+		Tier: domain.TierApprentice,
+		DependencyOutputs: []DependencyOutput{{
+			NodeID:      "extract",
+			Objective:   "List the APIs",
+			Output:      `{"apis":[{"name":"billing"}]}`,
+			TypedOutput: map[string]any{"apis": []any{map[string]any{"name": "billing"}}},
+		}},
+		OutputSchema: map[string]any{"type": "object", "required": []any{"client"}},
+	})
+
+	if !strings.Contains(result.SystemMessage, "Typed output (validated") {
+		t.Error("expected typed output block for the predecessor")
+	}
+	if !strings.Contains(result.SystemMessage, "Output Contract") ||
+		!strings.Contains(result.SystemMessage, `"client"`) {
+		t.Error("expected Output Contract section with the node's schema")
+	}
+	found := false
+	for _, id := range result.FragmentsUsed {
+		if id == "output-contract" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected 'output-contract' in FragmentsUsed")
+	}
+}
+
 func TestAssembly_WithoutDependencyOutputs(t *testing.T) {
 	assembler, _ := newTestAssembler()
 
@@ -49,6 +49,8 @@ func TestRegisterBuiltinFragments_FragmentsRegistered(t *testing.T) {
 	//   - party lead provider hint
 	//   - sub-quest executor directive
 	//   - sub-quest executor provider hint (Gemini/OpenAI workspace exploration)
+	//   - nested decomposition directive (decomposable sub-quests)
+	//   - DAG blueprint directive
 	//   - solo agent scenario directive
 	//   - solo agent work output directive
 	//   - research output directive
@@ -59,9 +61,12 @@ func TestRegisterBuiltinFragments_FragmentsRegistered(t *testing.T) {
 	//   - shared product directive
 	//   - party cooperation directive
 	//   - red-team directive
+	//   - untrusted content rule (fetch tools, red-team)
 	//   - guild lessons directive
-	if got := reg.FragmentCount(); got != 19 {
-		t.Errorf("RegisterBuiltinFragments registered %d fragments, want 19", got)
+	//   - agent memory directive
+	//   - active effects directive
+	if got := reg.FragmentCount(); got != 25 {
+		t.Errorf("RegisterBuiltinFragments registered %d fragments, want 25", got)
 	}
 }
 
@@ -920,3 +925,160 @@ func TestToolGuidance_AppearsBeforeGuildKnowledge(t *testing.T) {
 		t.Error("tool guidance should appear before quest context")
 	}
 }
+
+// =============================================================================
+// Agent memory fragment tests
+// =============================================================================
+
+func TestAgentMemory_IncludedWhenMemoriesPresent(t *testing.T) {
+	assembler, _ := newTestAssemblerWithBuiltins()
+
+	result := assembler.AssembleSystemPrompt(AssemblyContext{
+		Tier:       domain.TierJourneyman,
+		Provider:   "anthropic",
+		QuestTitle: "Add retry to HTTP client",
+		AgentMemories: []domain.QuestMemory{
+			{
+				ID: "mem-1", Objective: "Add backoff to webhook sender", Outcome: domain.MemoryOutcomeFailure,
+				KeyFiles: []string{"webhook/sender.go"}, JudgeFeedback: "No tests for jitter",
+			},
+		},
+	})
+
+	for _, want := range []string{"YOUR PAST QUESTS", "[FAILED] Add backoff to webhook sender", "webhook/sender.go", "No tests for jitter"} {
+		if !strings.Contains(result.SystemMessage, want) {
+			t.Errorf("expected %q in prompt", want)
+		}
+	}
+	memIdx := strings.Index(result.SystemMessage, "YOUR PAST QUESTS")
+	questIdx := strings.Index(result.SystemMessage, "Add retry to HTTP client")
+	if memIdx > questIdx {
+		t.Error("agent memories should appear before quest context")
+	}
+}
+
+func TestAgentMemory_ExcludedWhenNoMemories(t *testing.T) {
+	assembler, _ := newTestAssemblerWithBuiltins()
+
+	result := assembler.AssembleSystemPrompt(AssemblyContext{
+		Tier:       domain.TierJourneyman,
+		Provider:   "anthropic",
+		QuestTitle: "Simple task",
+	})
+
+	if strings.Contains(result.SystemMessage, "YOUR PAST QUESTS") {
+		t.Error("agent memory fragment should not appear without memories")
+	}
+}
+
+func TestAgentMemory_RespectsTokenBudget(t *testing.T) {
+	assembler, _ := newTestAssemblerWithBuiltins()
+
+	memories := []domain.QuestMemory{
+		{ID: "mem-1", Objective: "First relevant quest", Outcome: domain.MemoryOutcomeSuccess, Approach: strings.Repeat("a", 80)},
+		{ID: "mem-2", Objective: "Second relevant quest", Outcome: domain.MemoryOutcomeSuccess, Approach: strings.Repeat("b", 400)},
+	}
+
+	result := assembler.AssembleSystemPrompt(AssemblyContext{
+		Tier:              domain.TierJourneyman,
+		Provider:          "anthropic",
+		QuestTitle:        "Budgeted task",
+		AgentMemories:     memories,
+		MemoryTokenBudget: 60,
+	})
+
+	if !strings.Contains(result.SystemMessage, "First relevant quest") {
+		t.Error("expected first memory within budget")
+	}
+	if strings.Contains(result.SystemMessage, "Second relevant quest") {
+		t.Error("second memory should be dropped once budget is exhausted")
+	}
+}
+
+// =============================================================================
+// DAG blueprint fragment tests
+// =============================================================================
+
+func TestBlueprintDirective_RecommendedForPartyLead(t *testing.T) {
+	assembler, _ := newTestAssemblerWithBuiltins()
+
+	result := assembler.AssembleSystemPrompt(AssemblyContext{
+		Tier:          domain.TierMaster,
+		Provider:      "anthropic",
+		PartyRequired: true,
+		IsPartyLead:   true,
+		QuestTitle:    "Ship rate limiting",
+		Blueprints: []BlueprintSuggestion{
+			{
+				ID: "feature", Name: "Feature delivery", SuccessRate: 0.75, Uses: 6,
+				Recommended: true, Reason: "chosen by the DM for this quest",
+				Args:      map[string]string{"goal": "Ship rate limiting"},
+				NodesJSON: `[{"id":"design","objective":"Design the change for: Ship rate limiting"}]`,
+			},
+			{ID: "research_report", Name: "Parallel research report", SuccessRate: 0.5},
+		},
+	})
+
+	for _, want := range []string{
+		"DAG BLUEPRINTS", "feature (Feature delivery)", "success rate 75%", "research_report",
+		"RECOMMENDED: feature — chosen by the DM", "Design the change for: Ship rate limiting",
+		`blueprint_args: {"goal":"Ship rate limiting"}`,
+	} {
+		if !strings.Contains(result.SystemMessage, want) {
+			t.Errorf("expected %q in prompt", want)
+		}
+	}
+}
+
+func TestBlueprintDirective_ExcludedForNonDecomposer(t *testing.T) {
+	assembler, _ := newTestAssemblerWithBuiltins()
+
+	result := assembler.AssembleSystemPrompt(AssemblyContext{
+		Tier:       domain.TierJourneyman,
+		Provider:   "anthropic",
+		QuestTitle: "Solo task",
+		Blueprints: []BlueprintSuggestion{{ID: "feature", Name: "Feature delivery"}},
+	})
+
+	if strings.Contains(result.SystemMessage, "DAG BLUEPRINTS") {
+		t.Error("blueprint fragment should only reach agents that can decompose")
+	}
+}
+
+// =============================================================================
+// Active effects directive fragment tests
+// =============================================================================
+
+func TestActiveEffects_IncludedWhenDirectivesPresent(t *testing.T) {
+	assembler, _ := newTestAssemblerWithBuiltins()
+
+	result := assembler.AssembleSystemPrompt(AssemblyContext{
+		Tier:             domain.TierJourneyman,
+		Provider:         "anthropic",
+		QuestTitle:       "Add retry to HTTP client",
+		EffectDirectives: []string{"INSIGHT SCROLL — Quest intel:\n- Difficulty: Hard (3/5)"},
+	})
+
+	if !strings.Contains(result.SystemMessage, "INSIGHT SCROLL") {
+		t.Error("expected effect directive in prompt")
+	}
+	if strings.Index(result.SystemMessage, "INSIGHT SCROLL") > strings.Index(result.SystemMessage, "Add retry to HTTP client") {
+		t.Error("effect directives should appear before quest context")
+	}
+}
+
+func TestActiveEffects_ExcludedWhenNoDirectives(t *testing.T) {
+	assembler, _ := newTestAssemblerWithBuiltins()
+
+	result := assembler.AssembleSystemPrompt(AssemblyContext{
+		Tier:       domain.TierJourneyman,
+		Provider:   "anthropic",
+		QuestTitle: "Simple task",
+	})
+
+	for _, f := range result.FragmentsUsed {
+		if f == "builtin.active-effects" {
+			t.Error("active effects fragment should not appear without directives")
+		}
+	}
+}
@@ -42,6 +42,9 @@ const (
 	CategoryToolGuidance FragmentCategory = 325
 	// CategoryGuildKnowledge contains guild library knowledge fragments.
 	CategoryGuildKnowledge FragmentCategory = 400
+	// CategoryAgentMemory contains the agent's own memories of past quests
+	// relevant to the current one. Bounded by AssemblyContext.MemoryTokenBudget.
+	CategoryAgentMemory FragmentCategory = 420
 	// CategoryReviewBrief contains a compact summary of how the agent's work
 	// will be evaluated — review level, scoring criteria, and peer review
 	// dimensions. Placed after guild knowledge so agents see it before
@@ -49,6 +52,9 @@ const (
 	CategoryReviewBrief FragmentCategory = 450
 	// CategoryPersona contains agent character/personality overrides.
 	CategoryPersona FragmentCategory = 500
+	// CategoryActiveEffects contains prompt text from the agent's active
+	// consumable effects (e.g., insight_scroll quest intel).
+	CategoryActiveEffects FragmentCategory = 550
 	// CategoryQuestContext contains quest title, description, and constraints.
 	CategoryQuestContext FragmentCategory = 600
 )
@@ -140,6 +146,7 @@ type AssemblyContext struct {
 	PartyRequired bool // Quest requires party collaboration
 	IsPartyLead   bool // This agent is the party lead (Master+ tier)
 	IsSubQuest    bool // This quest is a sub-quest within a party DAG
+	CanDecompose  bool // Sub-quest is decomposable and the agent may split it into a nested DAG
 
 	// ClarificationAnswers carries previous Q&A exchanges between the member
 	// agent and the party lead. Populated by questbridge from the sub-quest
@@ -165,6 +172,11 @@ type AssemblyContext struct {
 	// renders it in place of DependencyOutputs.
 	DependencyContexts []DependencyContext `json:"dependency_contexts,omitempty"`
 
+	// OutputSchema is the sub-quest's DAG output contract. When set the
+	// assembler tells the agent to pass matching structured output to
+	// submit_work, which rejects submissions that do not conform.
+	OutputSchema map[string]any `json:"output_schema,omitempty"`
+
 	// StructuralChecklist carries domain-specific pass/fail requirements that
 	// agents should self-check before submitting. These same items are enforced
 	// during boss battle review — any failure is automatic defeat.
@@ -199,6 +211,20 @@ type AssemblyContext struct {
 	// quest required skills. Injected by questbridge context builder.
 	GuildLessons []domain.Lesson `json:"guild_lessons,omitempty"`
 
+	// Agent memories — the agent's own past quests ranked by relevance to
+	// this quest (most relevant first). Injected by questbridge. Rendering
+	// stops once MemoryTokenBudget estimated tokens are used (0 = unbounded).
+	AgentMemories     []domain.QuestMemory `json:"agent_memories,omitempty"`
+	MemoryTokenBudget int                  `json:"memory_token_budget,omitempty"`
+
+	// DAG blueprints — proven decompositions offered to a decomposing lead,
+	// recommended one first. Injected by questbridge.
+	Blueprints []BlueprintSuggestion `json:"blueprints,omitempty"`
+
+	// Effect directives — prompt text contributed by the agent's active
+	// consumable effects. Injected by questbridge.
+	EffectDirectives []string `json:"effect_directives,omitempty"`
+
 	// Red-team context — when QuestType == QuestTypeRedTeam, this carries
 	// the target quest's output for the red-team agent to review.
 	RedTeamTargetOutput any    `json:"red_team_target_output,omitempty"`
@@ -221,6 +247,10 @@ type DependencyOutput struct {
 	NodeID    string `json:"node_id"`
 	Objective string `json:"objective"`
 	Output    string `json:"output"`
+
+	// TypedOutput is the predecessor's contract-validated structured output,
+	// when its DAG node declared an output contract.
+	TypedOutput any `json:"typed_output,omitempty"`
 }
 
 // DependencyContext provides structured context from a predecessor quest.
@@ -233,7 +263,8 @@ type DependencyContext struct {
 	Summary        string   `json:"summary"`
 	EntityRefs     []string `json:"entity_refs"`
 	RawOutput      string   `json:"raw_output"`
-	ResolutionMode string   `json:"resolution_mode"` // "structured", "summary", "raw"
+	ResolutionMode string   `json:"resolution_mode"`        // "structured", "summary", "raw"
+	TypedOutput    any      `json:"typed_output,omitempty"` // Contract-validated structured output
 }
 
 // PeerFeedbackSummary describes a single peer-review question on which the agent
@@ -254,6 +285,22 @@ type FailureHistorySummary struct {
 	TriageVerdict string `json:"triage_verdict,omitempty"`
 }
 
+// BlueprintSuggestion is a reusable DAG blueprint offered to a lead that is
+// about to decompose. Only the recommended blueprint carries instantiated
+// nodes; the others are listed by name so the lead knows they exist.
+type BlueprintSuggestion struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Params      []string          `json:"params,omitempty"` // Rendered "name (default x)" hints
+	SuccessRate float64           `json:"success_rate"`
+	Uses        int               `json:"uses"`
+	Recommended bool              `json:"recommended,omitempty"`
+	Reason      string            `json:"reason,omitempty"`     // Why it was recommended
+	Args        map[string]string `json:"args,omitempty"`       // Arguments used to instantiate NodesJSON
+	NodesJSON   string            `json:"nodes_json,omitempty"` // Instantiated nodes, recommended only
+}
+
 // =============================================================================
 // ASSEMBLED PROMPT - Output of prompt assembly
 // =============================================================================
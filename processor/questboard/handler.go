@@ -782,10 +782,11 @@ func (c *Component) RepostForRetry(ctx context.Context, questID domain.QuestID)
 	c.logger.Info("reposting sub-quest for DAG retry",
 		"quest_id", questID, "previous_status", quest.Status)
 
-	// Reset agent assignment if present
+	// Reset agent assignment if present. A retired agent stays retired —
+	// questdagexec reposts a retiree's sub-quest to hand it to a replacement.
 	if quest.ClaimedBy != nil {
 		retryAgent, agentErr := c.getAgentByID(ctx, *quest.ClaimedBy)
-		if agentErr == nil {
+		if agentErr == nil && retryAgent.Status != domain.AgentRetired {
 			retryAgent.Status = domain.AgentIdle
 			retryAgent.CurrentQuest = nil
 			retryAgent.UpdatedAt = time.Now()
@@ -801,6 +802,7 @@ func (c *Component) RepostForRetry(ctx context.Context, questID domain.QuestID)
 	quest.ClaimedAt = nil
 	quest.StartedAt = nil
 	quest.FailureReason = ""
+	quest.Escalated = false
 
 	if err := c.graph.EmitEntityUpdate(ctx, quest, "quest.reposted_for_retry"); err != nil {
 		c.errorsCount.Add(1)
@@ -963,6 +965,14 @@ func (c *Component) FailQuest(ctx context.Context, questID domain.QuestID, reaso
 	if quest.ClaimedBy != nil {
 		repostAgent, agentErr := c.getAgentByID(ctx, *quest.ClaimedBy)
 		if agentErr == nil {
+			// A retry-granting effect (retry_token) turns a terminal solo
+			// failure into one more attempt, which skips the XP penalty.
+			if quest.PartyID == nil && quest.Attempts >= quest.MaxAttempts && repostAgent.HasRetryGrant() {
+				repostAgent.ConsumeEffects(domain.EffectOnFailure)
+				quest.MaxAttempts++
+				c.logger.Info("retry effect absorbed quest failure",
+					"quest", questID, "agent", repostAgent.ID, "max_attempts", quest.MaxAttempts)
+			}
 			repostAgent.Status = domain.AgentIdle
 			repostAgent.CurrentQuest = nil
 			repostAgent.UpdatedAt = time.Now()
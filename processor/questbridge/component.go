@@ -12,6 +12,7 @@ import (
 	semdragons "github.com/c360studio/semdragons"
 	"github.com/c360studio/semdragons/processor/boardcontrol"
 	"github.com/c360studio/semdragons/processor/executor"
+	"github.com/c360studio/semdragons/processor/partycoord"
 	"github.com/c360studio/semdragons/processor/promptmanager"
 	"github.com/c360studio/semdragons/processor/tokenbudget"
 	"github.com/c360studio/semdragons/semsource"
@@ -38,6 +39,13 @@ type QuestFailer interface {
 	FailQuest(ctx context.Context, questID domain.QuestID, reason string) error
 }
 
+// SubPartyFormer is the narrow interface questbridge needs from partycoord to
+// form the sub-party that runs a nested DAG.
+type SubPartyFormer interface {
+	FormSubParty(ctx context.Context, quest *domain.Quest, leadID domain.AgentID, width int) (*partycoord.Party, error)
+	DisbandParty(ctx context.Context, partyID domain.PartyID, reason string) error
+}
+
 // ClarificationAnswerer abstracts LLM inference for auto-DM clarification answering.
 // The default implementation uses the model registry's "dm-chat" capability
 // with a simple OpenAI-compatible HTTP call.
@@ -98,6 +106,10 @@ type Component struct {
 	// QUEST_LOOPS KV bucket for crash recovery
 	questLoopsBucket jetstream.KeyValue
 
+	// Party blackboard for injecting shared notes and pending member
+	// messages into party members' prompts. Optional: nil omits the section.
+	blackboard *partycoord.BlackboardStore
+
 	// clarificationAnswerer auto-answers agent clarification questions when DMMode
 	// is full_auto. Optional: nil means escalated quests wait for human DM response.
 	clarificationAnswerer ClarificationAnswerer
@@ -365,6 +377,14 @@ func (c *Component) Start(ctx context.Context) error {
 	}
 	c.questLoopsBucket = bucket
 
+	// Get or create the PARTY_BLACKBOARD bucket. Best-effort: without it,
+	// party members start without blackboard notes or pending messages.
+	if bbBucket, bbErr := partycoord.EnsureBlackboardBucket(ctx, c.deps.NATSClient); bbErr != nil {
+		c.logger.Warn("party blackboard unavailable", "error", bbErr)
+	} else {
+		c.blackboard = partycoord.NewBlackboardStore(bbBucket)
+	}
+
 	// Subscribe to board resume notifications for reconciliation.
 	if c.pauseChecker != nil {
 		sub, subErr := c.deps.NATSClient.Subscribe(ctx, boardcontrol.ResumeSubject(), func(msgCtx context.Context, _ *nats.Msg) {
@@ -470,6 +490,25 @@ func (c *Component) resolveQuestFailer() QuestFailer {
 	return ref
 }
 
+// resolveSubPartyFormer resolves partycoord's SubPartyFormer from the ComponentRegistry.
+// Returns nil when registry is unavailable or partycoord doesn't implement the interface.
+func (c *Component) resolveSubPartyFormer() SubPartyFormer {
+	if c.deps.ComponentRegistry == nil {
+		return nil
+	}
+	comp := c.deps.ComponentRegistry.Component("partycoord")
+	if comp == nil {
+		return nil
+	}
+	ref, ok := comp.(SubPartyFormer)
+	if !ok {
+		c.logger.Warn("partycoord component does not implement SubPartyFormer",
+			"type", comp.Meta().Type)
+		return nil
+	}
+	return ref
+}
+
 // resolveToolRegistrySource resolves questtools' ToolRegistrySource from the ComponentRegistry.
 // Returns nil when registry is unavailable or questtools doesn't implement the interface.
 func (c *Component) resolveToolRegistrySource() ToolRegistrySource {
@@ -74,6 +74,23 @@ type Config struct {
 	// When false (default), loadDependencyOutputs is used for backward compat.
 	EnableStructuredDeps bool `json:"enable_structured_deps,omitempty"`
 
+	// MaxInjectedMemories caps how many of the agent's past-quest memories are
+	// ranked into the prompt. 0 disables memory injection. Default: 5.
+	MaxInjectedMemories int `json:"max_injected_memories,omitempty"`
+
+	// MemoryTokenBudget bounds the estimated tokens spent on injected memories.
+	// 0 means unbounded (only MaxInjectedMemories applies). Default: 600.
+	MemoryTokenBudget int `json:"memory_token_budget,omitempty"`
+
+	// MaxDAGDepth is the deepest DAG level whose quests may be decomposed
+	// further. Level 0 is the top-level party quest; decomposable sub-quests
+	// at level 1+ spawn nested DAGs. 0 uses questdagexec.DefaultMaxDAGDepth.
+	MaxDAGDepth int `json:"max_dag_depth,omitempty"`
+
+	// MaxTotalDAGNodes caps the sub-quests posted across every nesting level
+	// of one top-level party quest. 0 uses questdagexec.DefaultMaxTotalDAGNodes.
+	MaxTotalDAGNodes int `json:"max_total_dag_nodes,omitempty"`
+
 	// DefaultRepo is the repo name used when a quest has no explicit repo set.
 	// Single-repo MVP: set this and all quests target it automatically.
 	// When empty, quests without an explicit repo get a plain workspace (no worktree).
@@ -110,6 +127,8 @@ func DefaultConfig() Config {
 		EntityContextBudget:       2000,
 		DependencyContextBudget:   800,
 		KnowledgeReadyTimeout:     300,
+		MaxInjectedMemories:       5,
+		MemoryTokenBudget:         600,
 	}
 }
 
@@ -53,13 +53,14 @@ func (b *entityKnowledgeBuilder) build(ctx context.Context, quest *domain.Quest,
 	// runtimes are available without calling inspect_environment (saves 1 tool call).
 	if b.hasSandbox {
 		sections = append(sections, `--- Workspace Environment ---
-Python 3.11, Git available. Use bash for EVERYTHING:
-  Read: bash("cat file.py") or bash("head -50 file.py")
-  Write: bash("cat <<'EOF' > file.py\n...\nEOF")
-  Search: bash("grep -rn 'pattern' .") or bash("find . -name '*.py'")
-  List: bash("ls -la")
+Python 3.11, Git available. File tools work on your quest workspace:
+  Read: read_file("file.py") or read_file("file.py", start_line=1, end_line=50)
+  Write: write_file("file.py", content); edit: apply_patch(unified diff)
+  Search: search_code("pattern", file_glob="*.py")
+  List: list_files(recursive=true)
+Use bash for the rest:
   Deps: bash("python3 -m venv .venv && .venv/bin/pip install -r requirements.txt")
-  Tests: bash(".venv/bin/python3 -m pytest")
+  Tests: run_tests() for a structured report, or bash(".venv/bin/python3 -m pytest")
   Git: bash("git add -A && git commit -m 'message'")
 When done, call submit_work with a summary.`)
 	}
@@ -300,3 +301,35 @@ func (b *entityKnowledgeBuilder) formatGuildContext(ctx context.Context, agent *
 
 	return sb.String(), []string{string(guildID)}
 }
+
+// partyBoardPromptChars bounds the blackboard summary in a member's prompt.
+// Members read full notes with party_note_read.
+const partyBoardPromptChars = 2000
+
+// partyBoardContext summarizes the party blackboard and delivers the agent's
+// pending member messages for a party sub-quest. The party lead's own quest
+// gets nothing: leads coordinate through the DAG tools.
+func (c *Component) partyBoardContext(ctx context.Context, quest *domain.Quest, agent *agentprogression.Agent) string {
+	if c.blackboard == nil || quest.PartyID == nil || quest.PartyRequired {
+		return ""
+	}
+	board, err := c.blackboard.Get(ctx, *quest.PartyID)
+	if err != nil {
+		c.logger.Debug("failed to load party blackboard", "party_id", *quest.PartyID, "error", err)
+		return ""
+	}
+
+	var sections []string
+	if s := board.PromptSummary(partyBoardPromptChars); s != "" {
+		sections = append(sections, s)
+	}
+	if len(board.Pending(agent.ID)) > 0 {
+		msgs, err := c.blackboard.TakeInbox(ctx, *quest.PartyID, agent.ID)
+		if err != nil {
+			c.logger.Warn("failed to deliver party messages", "party_id", *quest.PartyID, "agent_id", agent.ID, "error", err)
+		} else if s := partycoord.FormatMessages(msgs); s != "" {
+			sections = append(sections, s)
+		}
+	}
+	return strings.Join(sections, "\n")
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -287,6 +289,10 @@ func (c *Component) handleQuestStarted(ctx context.Context, entityState *graph.E
 	if entityKnowledgeContent != "" {
 		contextContent = assembled.SystemMessage + "\n\n" + entityKnowledgeContent
 	}
+	// Party members see the blackboard and messages sent since their last loop.
+	if board := c.partyBoardContext(ctx, quest, agent); board != "" {
+		contextContent += "\n\n" + board
+	}
 	tokenCount := pkgcontext.EstimateTokens(contextContent)
 
 	// Create workspace for the agent's file operations via sandbox container.
@@ -336,6 +342,39 @@ func (c *Component) handleQuestStarted(ctx context.Context, entityState *graph.E
 			"board":       c.config.Board,
 		},
 	}
+	// Carry the sub-quest's output contract so submit_work can validate
+	// against it without loading the quest entity.
+	if quest.DAGOutputSchema != nil {
+		taskMsg.Metadata["output_schema"] = quest.DAGOutputSchema
+	}
+	// Scope the party tools to the quest's party.
+	if quest.PartyID != nil {
+		taskMsg.Metadata["party_id"] = string(*quest.PartyID)
+	}
+	// Carry the danger allowlist and store purchases so questtools can
+	// enforce the same gates toolsForQuest applied.
+	if len(quest.AllowedTools) > 0 {
+		taskMsg.Metadata["allowed_tools"] = quest.AllowedTools
+	}
+	if owned := agentOwnedToolIDs(agent); len(owned) > 0 {
+		taskMsg.Metadata["owned_tools"] = owned
+	}
+	// Scope secrets to the quest's target repo.
+	if repo != "" {
+		taskMsg.Metadata["repo"] = repo
+	}
+	// Carry the fields tool-call policy rules match on.
+	taskMsg.Metadata["difficulty"] = int(quest.Difficulty)
+	if agent.Guild != "" {
+		taskMsg.Metadata["guild_id"] = string(agent.Guild)
+	}
+	if len(quest.RequiredSkills) > 0 {
+		names := make([]string, len(quest.RequiredSkills))
+		for i, s := range quest.RequiredSkills {
+			names[i] = string(s)
+		}
+		taskMsg.Metadata["required_skills"] = names
+	}
 
 	// Write context metadata to quest entity for UI visibility.
 	// Must happen BEFORE publishing TaskMessage — a fast-completing task could
@@ -539,6 +578,11 @@ func (c *Component) handleLoopCompleted(ctx context.Context, data []byte) {
 			"task_id", event.TaskID, "loop_id", event.LoopID)
 		return
 	}
+	if supersededLoop(mapping, event.LoopID) {
+		c.logger.Info("ignoring completed event from superseded loop",
+			"task_id", event.TaskID, "loop_id", event.LoopID, "current_loop_id", mapping.LoopID)
+		return
+	}
 
 	// Record token usage from the completed loop.
 	if c.tokenLedger != nil && (event.TokensIn > 0 || event.TokensOut > 0) {
@@ -618,6 +662,11 @@ func (c *Component) handleLoopFailed(ctx context.Context, data []byte) {
 			"task_id", event.TaskID, "loop_id", event.LoopID)
 		return
 	}
+	if supersededLoop(mapping, event.LoopID) {
+		c.logger.Info("ignoring failed event from superseded loop",
+			"task_id", event.TaskID, "loop_id", event.LoopID, "current_loop_id", mapping.LoopID)
+		return
+	}
 
 	// Record token usage from the failed loop.
 	if c.tokenLedger != nil && (event.TokensIn > 0 || event.TokensOut > 0) {
@@ -681,6 +730,11 @@ func (c *Component) handleLoopCancelled(ctx context.Context, event *agentic.Loop
 			"task_id", event.TaskID, "loop_id", event.LoopID)
 		return
 	}
+	if supersededLoop(mapping, event.LoopID) {
+		c.logger.Info("ignoring cancelled event from superseded loop",
+			"task_id", event.TaskID, "loop_id", event.LoopID, "current_loop_id", mapping.LoopID)
+		return
+	}
 
 	reason := "loop cancelled"
 	if event.CancelledBy != "" {
@@ -723,6 +777,24 @@ func (c *Component) handleLoopCancelled(ctx context.Context, event *agentic.Loop
 		"cancelled_by", event.CancelledBy)
 }
 
+// supersededLoop reports whether an execution-loop event comes from a loop
+// that no longer owns its quest: a party hot-swap cancels the departed
+// member's loop and dispatches the replacement on the same quest, so the
+// mapping may already point at the new loop. Such events must neither
+// transition the quest nor clean up the live mapping.
+func supersededLoop(mapping *QuestLoopMapping, loopID string) bool {
+	if mapping.LoopType == LoopTypeReview || mapping.LoopType == LoopTypeClarify || mapping.LoopType == LoopTypeExplore {
+		return false
+	}
+	return loopID != "" && mapping.LoopID != "" && mapping.LoopID != loopID
+}
+
+// handedOver reports whether the quest has been claimed by a different agent
+// than the one whose loop the mapping tracks.
+func handedOver(quest *domain.Quest, mapping *QuestLoopMapping) bool {
+	return mapping.AgentID != "" && quest.ClaimedBy != nil && *quest.ClaimedBy != mapping.AgentID
+}
+
 // storeLoopIDOnQuest persists the loop ID on the quest entity so that the
 // cancel API can find the active loop for an in-progress quest.
 func (c *Component) storeLoopIDOnQuest(ctx context.Context, questID domain.QuestID, loopID string) {
@@ -778,25 +850,38 @@ func (c *Component) completeQuest(ctx context.Context, questID domain.QuestID, m
 			"quest_id", questID, "current_status", quest.Status, "loop_id", mapping.LoopID)
 		return
 	}
+	if handedOver(quest, mapping) {
+		c.logger.Warn("skipping quest completion — quest was handed to another agent",
+			"quest_id", questID, "claimed_by", *quest.ClaimedBy, "loop_agent", mapping.AgentID, "loop_id", mapping.LoopID)
+		return
+	}
 
 	// When a party quest completes and we have a questboard reference, check
 	// whether the lead's output contains a DAG decomposition. A valid DAG
 	// triggers sub-quest posting and DAG state initialization instead of the
-	// normal in_review transition.
+	// normal in_review transition. Decomposable sub-quests take the same path
+	// when their Master-tier assignee chose to split them into a nested DAG.
 	qb := c.resolveQuestBoard()
-	if quest.PartyRequired && qb != nil {
+	if (quest.PartyRequired || quest.DAGDecomposable) && qb != nil {
 		dag, ok := extractDAGFromOutput(output)
 		if ok {
 			c.logger.Info("detected DAG output from party quest lead",
 				"quest_id", questID,
 				"nodes", len(dag.Nodes))
 
+			quest.DAGBlueprint = extractDAGBlueprint(output)
 			if err := c.handleDAGDecomposition(ctx, quest, mapping, dag, qb); err != nil {
 				c.logger.Error("DAG decomposition failed, failing quest",
 					"quest_id", questID, "error", err)
 				quest.Status = domain.QuestFailed
 				quest.LoopID = mapping.LoopID
 				quest.FailureReason = fmt.Sprintf("DAG decomposition failed: %s", err)
+				if !quest.PartyRequired {
+					// Nested decomposition was refused — retry the node
+					// as ordinary work rather than looping on the limit.
+					quest.DAGDecomposable = false
+					quest.DAGPartyID = ""
+				}
 				if emitErr := c.graph.EmitEntityUpdate(ctx, quest, "quest.failed"); emitErr != nil {
 					c.logger.Error("failed to emit quest failure after DAG error",
 						"quest_id", questID, "error", emitErr)
@@ -822,9 +907,13 @@ func (c *Component) completeQuest(ctx context.Context, questID domain.QuestID, m
 	// Try tool-based JSON output first (submit_work / ask_clarification).
 	// Falls back to legacy intent tags and heuristic detection for non-compliant models.
 	isClarification := false
+	quest.DAGTypedOutput = nil
 	if outputType, content, ok := parseToolOutput(output); ok {
 		switch outputType {
 		case "work_product":
+			// Keep the contract-validated structured output for downstream
+			// DAG nodes before stripping the envelope.
+			quest.DAGTypedOutput = parseTypedOutput(output)
 			// Extract deliverable as the actual quest output (strip JSON envelope).
 			output = content
 			// Safety net: some models submit questions via submit_work
@@ -988,6 +1077,19 @@ func parseToolOutput(output string) (outputType, content string, ok bool) {
 	return "", "", false
 }
 
+// parseTypedOutput returns the structured output carried by a submit_work
+// envelope, or nil when the submission has none. submit_work only includes
+// it after validating against the quest's output contract.
+func parseTypedOutput(output string) any {
+	var envelope struct {
+		Output any `json:"output"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &envelope); err != nil {
+		return nil
+	}
+	return envelope.Output
+}
+
 // isOutputClarificationRequest returns true when the agent's output is a
 // clarification request rather than a work product.
 // This is a fallback for models that ignore the tool-calling instructions.
@@ -1060,13 +1162,49 @@ func parseOutputIntent(text string) string {
 // Called only when quest.PartyRequired is true and the lead's loop output
 // contains a valid DAG structure.
 func (c *Component) handleDAGDecomposition(ctx context.Context, quest *domain.Quest, mapping *QuestLoopMapping, dag *questdagexec.QuestDAG, qb SubQuestPoster) error {
+	// A decomposable sub-quest spawns a nested DAG: check the hierarchy limits
+	// and form the sub-party that will execute it before posting anything.
+	partyID := quest.PartyID
+	var subParty SubPartyFormer
+	if !quest.PartyRequired {
+		if err := c.checkNestedDAGBudget(ctx, quest, len(dag.Nodes)); err != nil {
+			return err
+		}
+		subParty = c.resolveSubPartyFormer()
+		if subParty == nil {
+			return fmt.Errorf("partycoord unavailable, cannot form sub-party")
+		}
+		width := len(questdagexec.DAGReadyNodes(*dag, pendingNodeStates(dag.Nodes)))
+		party, err := subParty.FormSubParty(ctx, quest, mapping.AgentID, width)
+		if err != nil {
+			return fmt.Errorf("form sub-party: %w", err)
+		}
+		partyID = &party.ID
+		quest.DAGPartyID = string(party.ID)
+	}
+
 	// Convert DAG nodes to domain.Quest values for PostSubQuests.
 	subQuests := dagNodesToQuests(dag.Nodes, quest)
+	maxDepth := c.config.MaxDAGDepth
+	if maxDepth <= 0 {
+		maxDepth = questdagexec.DefaultMaxDAGDepth
+	}
+	for i := range subQuests {
+		if subQuests[i].DAGLevel > maxDepth {
+			subQuests[i].DAGDecomposable = false
+		}
+	}
 
 	// Post sub-quests via questboard. This validates the decomposer's tier
 	// (Master+), sets ParentQuest on each sub-quest, and writes them to KV.
 	posted, err := qb.PostSubQuests(ctx, quest.ID, subQuests, mapping.AgentID)
 	if err != nil {
+		if subParty != nil {
+			if disbandErr := subParty.DisbandParty(ctx, *partyID, "nested DAG posting failed"); disbandErr != nil {
+				c.logger.Warn("failed to disband sub-party after posting failure",
+					"party_id", *partyID, "error", disbandErr)
+			}
+		}
 		return fmt.Errorf("post sub-quests: %w", err)
 	}
 
@@ -1098,8 +1236,8 @@ func (c *Component) handleDAGDecomposition(ctx context.Context, quest *domain.Qu
 	// Party assignment makes sub-quests invisible to the general board;
 	// DAG node ID enables questdagexec to correlate sub-quest transitions.
 	for i, node := range dag.Nodes {
-		if quest.PartyID != nil {
-			posted[i].PartyID = quest.PartyID
+		if partyID != nil {
+			posted[i].PartyID = partyID
 		}
 		posted[i].DAGNodeID = node.ID
 
@@ -1165,19 +1303,9 @@ func extractDAGFromOutput(output string) (*questdagexec.QuestDAG, bool) {
 		return nil, false
 	}
 
-	// Try to unmarshal the full output as the tool response JSON.
-	// Use json.NewDecoder so trailing prose after a valid JSON object is
-	// tolerated (LLMs often wrap tool output in markdown code fences).
-	var raw map[string]any
-	if err := json.NewDecoder(strings.NewReader(output)).Decode(&raw); err != nil {
-		// Output is not top-level JSON — scan for a JSON object within it.
-		start := strings.Index(output, "{")
-		if start == -1 {
-			return nil, false
-		}
-		if err2 := json.NewDecoder(strings.NewReader(output[start:])).Decode(&raw); err2 != nil {
-			return nil, false
-		}
+	raw, ok := decodeToolOutput(output)
+	if !ok {
+		return nil, false
 	}
 
 	// Must have both "goal" and "dag" keys per decompose_quest tool contract.
@@ -1208,6 +1336,38 @@ func extractDAGFromOutput(output string) (*questdagexec.QuestDAG, bool) {
 	return &dag, true
 }
 
+// extractDAGBlueprint returns the blueprint ID the lead named in its
+// decompose_quest call, or "" when the DAG was designed ad hoc.
+func extractDAGBlueprint(output string) string {
+	raw, ok := decodeToolOutput(output)
+	if !ok {
+		return ""
+	}
+	id, _ := raw["blueprint"].(string)
+	return id
+}
+
+// decodeToolOutput decodes the first JSON object in a loop's output.
+// Uses json.NewDecoder so trailing prose after a valid JSON object is
+// tolerated (LLMs often wrap tool output in markdown code fences).
+func decodeToolOutput(output string) (map[string]any, bool) {
+	if output == "" {
+		return nil, false
+	}
+	var raw map[string]any
+	if err := json.NewDecoder(strings.NewReader(output)).Decode(&raw); err != nil {
+		// Output is not top-level JSON — scan for a JSON object within it.
+		start := strings.Index(output, "{")
+		if start == -1 {
+			return nil, false
+		}
+		if err2 := json.NewDecoder(strings.NewReader(output[start:])).Decode(&raw); err2 != nil {
+			return nil, false
+		}
+	}
+	return raw, true
+}
+
 // dagNodesToQuests converts a slice of QuestNode values from a DAG into
 // domain.Quest values suitable for PostSubQuests. Skills are mapped from
 // string tags to domain.SkillTag. Difficulty inherits from the parent when
@@ -1230,17 +1390,84 @@ func dagNodesToQuests(nodes []questdagexec.QuestNode, parent *domain.Quest) []do
 			skills = append(skills, domain.SkillTag(s))
 		}
 
+		level := 1
+		if parent != nil {
+			level = parent.DAGLevel + 1
+		}
+
 		quests = append(quests, domain.Quest{
-			Title:          title,
-			Description:    node.Objective,
-			Difficulty:     difficulty,
-			RequiredSkills: skills,
-			Acceptance:     node.Acceptance,
+			Title:           title,
+			Description:     node.Objective,
+			Difficulty:      difficulty,
+			RequiredSkills:  skills,
+			Acceptance:      node.Acceptance,
+			DAGDecomposable: node.Decomposable,
+			DAGLevel:        level,
+			DAGOutputSchema: node.SubQuestOutputSchema(),
 		})
 	}
 	return quests
 }
 
+// pendingNodeStates returns a node state map with every node pending, for
+// computing a DAG's initial ready frontier.
+func pendingNodeStates(nodes []questdagexec.QuestNode) map[string]string {
+	states := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		states[node.ID] = questdagexec.NodePending
+	}
+	return states
+}
+
+// checkNestedDAGBudget enforces the hierarchy limits before a decomposable
+// sub-quest is split into a nested DAG: the sub-quest's level must not exceed
+// MaxDAGDepth, and the sub-quests already posted across the whole tree plus
+// the new ones must fit in MaxTotalDAGNodes. The tree is found by walking
+// ParentQuest up to the top-level party quest and counting down through each
+// level's quest.dag.node_quest_ids.
+func (c *Component) checkNestedDAGBudget(ctx context.Context, quest *domain.Quest, newNodes int) error {
+	maxDepth := c.config.MaxDAGDepth
+	if maxDepth <= 0 {
+		maxDepth = questdagexec.DefaultMaxDAGDepth
+	}
+
+	root := quest
+	for hops := 0; root.ParentQuest != nil && hops <= maxDepth; hops++ {
+		entity, err := c.graph.GetQuest(ctx, *root.ParentQuest)
+		if err != nil {
+			return fmt.Errorf("load parent quest %s: %w", *root.ParentQuest, err)
+		}
+		parent := domain.QuestFromEntityState(entity)
+		if parent == nil {
+			return fmt.Errorf("reconstruct parent quest %s", *root.ParentQuest)
+		}
+		root = parent
+	}
+
+	existing := c.countDAGSubQuests(ctx, root, maxDepth+1)
+	return questdagexec.CheckNestedBudget(quest.DAGLevel, existing, newNodes, maxDepth, c.config.MaxTotalDAGNodes)
+}
+
+// countDAGSubQuests counts the sub-quests posted by quest's DAG and, up to
+// depth further levels, by any nested DAGs beneath it.
+func (c *Component) countDAGSubQuests(ctx context.Context, quest *domain.Quest, depth int) int {
+	_, nodeQuestIDs := c.parseDAGFromParent(quest)
+	count := len(nodeQuestIDs)
+	if depth <= 0 {
+		return count
+	}
+	for _, subQuestID := range nodeQuestIDs {
+		entity, err := c.graph.GetQuest(ctx, domain.QuestID(subQuestID))
+		if err != nil {
+			continue
+		}
+		if sq := domain.QuestFromEntityState(entity); sq != nil && sq.DAGExecutionID != "" {
+			count += c.countDAGSubQuests(ctx, sq, depth-1)
+		}
+	}
+	return count
+}
+
 // failQuest transitions the quest to failed and releases the agent.
 func (c *Component) failQuest(ctx context.Context, questID domain.QuestID, mapping *QuestLoopMapping, reason string, metrics loopMetrics) {
 	questEntity, err := c.graph.GetQuest(ctx, questID)
@@ -1264,6 +1491,11 @@ func (c *Component) failQuest(ctx context.Context, questID domain.QuestID, mappi
 			"quest_id", questID, "current_status", quest.Status, "loop_id", mapping.LoopID)
 		return
 	}
+	if handedOver(quest, mapping) {
+		c.logger.Warn("skipping quest failure — quest was handed to another agent",
+			"quest_id", questID, "claimed_by", *quest.ClaimedBy, "loop_agent", mapping.AgentID, "loop_id", mapping.LoopID)
+		return
+	}
 
 	// Persist execution metrics from agentic-loop (partial metrics for failed quests).
 	quest.TurnsUsed = metrics.TurnsUsed
@@ -1811,9 +2043,10 @@ func (c *Component) loadDependencyOutputs(ctx context.Context, quest *domain.Que
 		nodesByID[dagDef.Nodes[i].ID] = &dagDef.Nodes[i]
 	}
 
-	// Load each predecessor's output.
+	// Load each predecessor's output. A fan-out dependency contributes every
+	// instance's output, which is how join nodes collect them.
 	var outputs []promptmanager.DependencyOutput
-	for _, depNodeID := range thisNode.DependsOn {
+	for _, depNodeID := range predecessorNodes(dagDef, thisNode) {
 		depQuestID, ok := nodeQuestIDs[depNodeID]
 		if !ok {
 			continue
@@ -1836,9 +2069,10 @@ func (c *Component) loadDependencyOutputs(ctx context.Context, quest *domain.Que
 		}
 
 		outputs = append(outputs, promptmanager.DependencyOutput{
-			NodeID:    depNodeID,
-			Objective: objective,
-			Output:    outputStr,
+			NodeID:      depNodeID,
+			Objective:   objective,
+			Output:      outputStr,
+			TypedOutput: depQuest.DAGTypedOutput,
 		})
 	}
 
@@ -1849,6 +2083,16 @@ func (c *Component) loadDependencyOutputs(ctx context.Context, quest *domain.Que
 	return outputs
 }
 
+// predecessorNodes returns the nodes whose outputs a DAG node receives as
+// dependency context, resolving each fan-out dependency to its instances.
+func predecessorNodes(dag *questdagexec.QuestDAG, node *questdagexec.QuestNode) []string {
+	var ids []string
+	for _, dep := range node.DependsOn {
+		ids = append(ids, dag.DependencyNodes(dep)...)
+	}
+	return ids
+}
+
 // parseDAGFromParent extracts the QuestDAG and node-quest ID mapping from a
 // parent quest's DAG fields. Returns (nil, nil) if the parent has no DAG data.
 func (c *Component) parseDAGFromParent(parent *domain.Quest) (*questdagexec.QuestDAG, map[string]string) {
@@ -1938,7 +2182,7 @@ func (c *Component) loadDependencyContext(ctx context.Context, quest *domain.Que
 	}
 
 	var contexts []promptmanager.DependencyContext
-	for _, depNodeID := range thisNode.DependsOn {
+	for _, depNodeID := range predecessorNodes(dagDef, thisNode) {
 		depQuestID, ok := nodeQuestIDs[depNodeID]
 		if !ok {
 			continue
@@ -1974,8 +2218,9 @@ func (c *Component) loadDependencyContext(ctx context.Context, quest *domain.Que
 // predecessor quest. The result always has a valid ResolutionMode.
 func (c *Component) resolveDepContext(ctx context.Context, depQuest *domain.Quest, nodeID, objective string, budget int) promptmanager.DependencyContext {
 	base := promptmanager.DependencyContext{
-		NodeID:    nodeID,
-		Objective: objective,
+		NodeID:      nodeID,
+		Objective:   objective,
+		TypedOutput: depQuest.DAGTypedOutput,
 	}
 
 	// Tier 1: structured — artifacts have been indexed by semsource.
@@ -2148,7 +2393,9 @@ func (c *Component) fetchStructuredSummary(ctx context.Context, entityIDs []stri
 // buildSystemPrompt builds the system prompt using the assembler when available,
 // falling back to the legacy string concatenation path. Returns the full
 // AssembledPrompt so callers can access FragmentsUsed for context metadata.
+// The guild lessons shown to the agent are recorded on quest.InjectedLessons.
 func (c *Component) buildSystemPrompt(ctx context.Context, agent *agentprogression.Agent, quest *domain.Quest, toolNames []string) promptmanager.AssembledPrompt {
+	quest.InjectedLessons = nil
 	if c.promptAssembler != nil {
 		return c.buildAssembledSystemPrompt(ctx, agent, quest, toolNames)
 	}
@@ -2199,6 +2446,8 @@ func (c *Component) buildAssembledSystemPrompt(ctx context.Context, agent *agent
 		}
 	}
 
+	lessons := c.loadGuildLessons(ctx, agent, quest)
+
 	assemblyCtx := promptmanager.AssemblyContext{
 		AgentID:               agent.ID,
 		Tier:                  agent.Tier,
@@ -2219,10 +2468,12 @@ func (c *Component) buildAssembledSystemPrompt(ctx context.Context, agent *agent
 		PartyRequired:         quest.PartyRequired,
 		IsPartyLead:           quest.PartyRequired && agent.Tier >= domain.TierMaster,
 		IsSubQuest:            quest.ParentQuest != nil,
+		CanDecompose:          canDecomposeNested(quest, agent),
 		ClarificationAnswers:  c.loadClarificationAnswers(quest),
 		ClarificationSource:   c.clarificationSource(quest),
 		DependencyOutputs:     c.resolveDependencyOutputs(ctx, quest),
 		DependencyContexts:    c.resolveDependencyContexts(ctx, quest),
+		OutputSchema:          questdagexec.OutputSchemaFromAny(quest.DAGOutputSchema),
 		StructuralChecklist:   checklist,
 		ReviewLevel:           reviewLevel,
 		ReviewCriteria:        reviewCriteria,
@@ -2233,10 +2484,14 @@ func (c *Component) buildAssembledSystemPrompt(ctx context.Context, agent *agent
 		AvailableToolNames:    toolNames,
 		MaxIterations:         maxIterationsForDifficulty(c.config.MaxIterations, quest.Difficulty),
 		QuestType:             quest.QuestType,
-		GuildLessons:          c.loadGuildLessons(ctx, agent, quest),
+		GuildLessons:          lessons,
+		Blueprints:            c.suggestBlueprints(ctx, agent, quest),
+		AgentMemories:         agentprogression.RelevantMemories(agent.Memories, quest, c.config.MaxInjectedMemories),
+		MemoryTokenBudget:     c.config.MemoryTokenBudget,
+		EffectDirectives:      agent.EffectPromptInjections(quest),
 		RedTeamTargetOutput:   nil, // Set below after single target load.
 		RedTeamTargetTitle:    "",
-		WorkspaceHasPriorWork: quest.Attempts > 1 && quest.ParentQuest == nil,
+		WorkspaceHasPriorWork: (quest.Attempts > 1 && quest.ParentQuest == nil) || quest.DAGHandoverFrom != "",
 		FailureHistory:        convertFailureHistory(quest.FailureHistory),
 		SalvagedOutput:        domain.AsString(quest.SalvagedOutput),
 		FailureAnalysis:       quest.FailureAnalysis,
@@ -2255,7 +2510,17 @@ func (c *Component) buildAssembledSystemPrompt(ctx context.Context, agent *agent
 		}
 	}
 
-	return c.promptAssembler.AssembleSystemPrompt(assemblyCtx)
+	assembled := c.promptAssembler.AssembleSystemPrompt(assemblyCtx)
+
+	// Remember exactly which lessons the agent saw; redteam credits the
+	// review outcome to these and no others.
+	if slices.Contains(assembled.FragmentsUsed, guildLessonsFragmentID) {
+		quest.InjectedLessons = make([]string, len(lessons))
+		for i, l := range lessons {
+			quest.InjectedLessons[i] = l.ID
+		}
+	}
+	return assembled
 }
 
 // convertFailureHistory maps domain.FailureRecord to promptmanager.FailureHistorySummary
@@ -2276,8 +2541,11 @@ func convertFailureHistory(records []domain.FailureRecord) []promptmanager.Failu
 	return summaries
 }
 
-// loadGuildLessons returns relevant guild lessons for the agent's guild,
-// filtered to only include lessons matching the quest's required skills.
+// guildLessonsFragmentID is the prompt fragment that renders guild lessons.
+const guildLessonsFragmentID = "builtin.guild-lessons"
+
+// loadGuildLessons returns the best-ranked active guild lessons for the
+// agent's guild that match the quest's required skills.
 func (c *Component) loadGuildLessons(ctx context.Context, agent *agentprogression.Agent, quest *domain.Quest) []domain.Lesson {
 	if agent.Guild == "" {
 		return nil
@@ -2292,26 +2560,109 @@ func (c *Component) loadGuildLessons(ctx context.Context, agent *agentprogressio
 		return nil
 	}
 
-	// Build skill set for quick lookup.
-	questSkills := make(map[domain.SkillTag]struct{}, len(quest.RequiredSkills))
-	for _, s := range quest.RequiredSkills {
-		questSkills[s] = struct{}{}
+	// Best-ranked lessons for the quest's skills, capped to stay within
+	// context budget. Retired lessons are never injected.
+	return domain.SelectLessons(guild.Lessons, quest.RequiredSkills, time.Now(), domain.MaxPromptLessons)
+}
+
+// maxBlueprintSuggestions caps how many blueprints a decomposing lead sees.
+const maxBlueprintSuggestions = 3
+
+// suggestBlueprints offers DAG blueprints to an agent about to decompose.
+// The DM's pick (quest.DAGBlueprint) is recommended when set; otherwise the
+// best-ranked blueprint for the quest's decomposability class is. Only the
+// recommended blueprint is instantiated — the rest are listed by name.
+func (c *Component) suggestBlueprints(ctx context.Context, agent *agentprogression.Agent, quest *domain.Quest) []promptmanager.BlueprintSuggestion {
+	isLead := quest.PartyRequired && agent.Tier >= domain.TierMaster
+	if !isLead && !canDecomposeNested(quest, agent) {
+		return nil
+	}
+
+	blueprints, err := questdagexec.NewBlueprintLibrary(c.graph).List(ctx)
+	if err != nil {
+		c.logger.Debug("blueprint list incomplete", "quest_id", quest.ID, "error", err)
 	}
 
-	// Filter lessons to those matching the quest's skills.
-	var relevant []domain.Lesson
-	for _, lesson := range guild.Lessons {
-		if _, match := questSkills[lesson.Skill]; match {
-			relevant = append(relevant, lesson)
+	recommended := -1
+	reason := ""
+	for i := range blueprints {
+		if quest.DAGBlueprint != "" && blueprints[i].ID == quest.DAGBlueprint {
+			recommended, reason = i, "chosen by the DM for this quest"
+			break
+		}
+		if quest.DAGBlueprint == "" && blueprints[i].SuitsClass(quest.DecomposabilityClass) &&
+			quest.DecomposabilityClass != domain.DecomposableTrivial {
+			recommended, reason = i, fmt.Sprintf("best-ranked blueprint for %s quests", quest.DecomposabilityClass)
+			break
 		}
 	}
 
-	// Cap at 10 lessons to stay within context budget.
-	if len(relevant) > 10 {
-		relevant = relevant[len(relevant)-10:]
+	suggestions := make([]promptmanager.BlueprintSuggestion, 0, maxBlueprintSuggestions)
+	if recommended >= 0 {
+		bp := &blueprints[recommended]
+		s := blueprintSuggestion(bp)
+		s.Recommended = true
+		s.Reason = reason
+		s.Args = blueprintArgsForQuest(quest)
+		if dag, instErr := bp.Instantiate(s.Args); instErr == nil {
+			if nodes, mErr := json.MarshalIndent(dag.Nodes, "", "  "); mErr == nil {
+				s.NodesJSON = string(nodes)
+			}
+		} else {
+			c.logger.Debug("recommended blueprint not instantiable",
+				"quest_id", quest.ID, "blueprint", bp.ID, "error", instErr)
+		}
+		suggestions = append(suggestions, s)
+	}
+	for i := range blueprints {
+		if len(suggestions) >= maxBlueprintSuggestions {
+			break
+		}
+		if i != recommended {
+			suggestions = append(suggestions, blueprintSuggestion(&blueprints[i]))
+		}
 	}
+	return suggestions
+}
 
-	return relevant
+// blueprintSuggestion renders a blueprint's listing for the prompt.
+func blueprintSuggestion(bp *questdagexec.Blueprint) promptmanager.BlueprintSuggestion {
+	params := make([]string, 0, len(bp.Params))
+	for _, p := range bp.Params {
+		switch {
+		case p.Required:
+			params = append(params, p.Name+" (required)")
+		case p.Default != "":
+			params = append(params, fmt.Sprintf("%s (default %q)", p.Name, p.Default))
+		default:
+			params = append(params, p.Name)
+		}
+	}
+	return promptmanager.BlueprintSuggestion{
+		ID:          bp.ID,
+		Name:        bp.Name,
+		Description: bp.Description,
+		Params:      params,
+		SuccessRate: bp.SuccessRate(),
+		Uses:        bp.Uses,
+	}
+}
+
+// blueprintArgsForQuest returns the DM's blueprint arguments with the goal
+// defaulted from the quest.
+func blueprintArgsForQuest(quest *domain.Quest) map[string]string {
+	args := make(map[string]string)
+	for k, v := range questdagexec.BlueprintArgsFromAny(quest.DAGBlueprintArgs) {
+		args[k] = v
+	}
+	if args[questdagexec.BlueprintGoalParam] == "" {
+		goal := quest.Goal
+		if goal == "" {
+			goal = quest.Title
+		}
+		args[questdagexec.BlueprintGoalParam] = goal
+	}
+	return args
 }
 
 // buildLegacySystemPrompt is the fallback string concatenation path.
@@ -2536,6 +2887,17 @@ func (c *Component) toolsForQuest(quest *domain.Quest, agent *agentprogression.A
 		if tool.Category != "" && !allowedCats[tool.Category] {
 			continue
 		}
+		// Nested decomposers lead only after decomposing; review and
+		// clarification tools are dispatched by questdagexec, not here.
+		if tool.Category == executor.ToolCategoryPartyLead && !quest.PartyRequired &&
+			tool.Definition.Name != "decompose_quest" {
+			continue
+		}
+		// amend_dag re-plans a running DAG; questdagexec offers it in lead
+		// review and clarification loops once a DAG exists.
+		if tool.Definition.Name == "amend_dag" {
+			continue
+		}
 
 		// Omit graph_search when no knowledge sources are indexed.
 		if tool.Definition.Name == "graph_search" && !hasKnowledgeSources {
@@ -2557,6 +2919,11 @@ func (c *Component) toolsForQuest(quest *domain.Quest, agent *agentprogression.A
 			continue
 		}
 
+		// Enforce danger flag and store ownership.
+		if !tool.Unlocked(quest, agent) {
+			continue
+		}
+
 		result = append(result, tool.Definition)
 	}
 	return result
@@ -2583,6 +2950,17 @@ func categoriesForQuest(quest *domain.Quest, agent *agentprogression.Agent) map[
 		executor.ToolCategoryKnowledge: true,
 	}
 
+	// Party members coordinate through the blackboard and direct messages.
+	if quest.PartyID != nil {
+		cats[executor.ToolCategoryParty] = true
+	}
+
+	// A Master-tier member on a decomposable sub-quest may also split it
+	// into a nested DAG; toolsForQuest narrows party_lead to decompose_quest.
+	if canDecomposeNested(quest, agent) {
+		cats[executor.ToolCategoryPartyLead] = true
+	}
+
 	// Research-only quests (no CodeGen skill required) don't need
 	// inspect tools (bash) — saves tool definitions.
 	// Write tools are kept so scholars can save findings to markdown files.
@@ -2593,6 +2971,14 @@ func categoriesForQuest(quest *domain.Quest, agent *agentprogression.Agent) map[
 	return cats
 }
 
+// canDecomposeNested reports whether the agent may split this sub-quest into
+// a nested DAG. Decomposition is offered, not forced: toolChoiceForQuest only
+// forces decompose_quest for top-level party leads.
+func canDecomposeNested(quest *domain.Quest, agent *agentprogression.Agent) bool {
+	return quest.DAGDecomposable && quest.ParentQuest != nil && !quest.PartyRequired &&
+		agent.Tier >= domain.TierMaster
+}
+
 // questRequiresCodeGen returns true if the quest needs code generation tools.
 // Quests with no required skills default to true (assume implementation).
 func questRequiresCodeGen(quest *domain.Quest) bool {
@@ -2670,6 +3056,18 @@ func agentSkillNames(agent *agentprogression.Agent) []string {
 	return names
 }
 
+// agentOwnedToolIDs returns the store item IDs of the agent's usable tools.
+func agentOwnedToolIDs(agent *agentprogression.Agent) []string {
+	ids := make([]string, 0, len(agent.OwnedTools))
+	for id := range agent.OwnedTools {
+		if agent.OwnsTool(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 // resolveQuestEndpoint returns the best-effort endpoint name for quest execution.
 // Used for cost estimation when recording token usage from completed/failed loops.
 func (c *Component) resolveQuestEndpoint() string {
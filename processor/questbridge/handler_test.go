@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"sort"
 	"strings"
 	"testing"
@@ -854,6 +856,70 @@ func TestExtractDAGFromOutput(t *testing.T) {
 	})
 }
 
+func TestExtractDAGBlueprint(t *testing.T) {
+	withBlueprint := `{"goal":"g","blueprint":"feature","dag":{"nodes":[{"id":"a","objective":"x"}]}}`
+	adHoc := `{"goal":"g","dag":{"nodes":[{"id":"a","objective":"x"}]}}`
+
+	if got := extractDAGBlueprint(withBlueprint); got != "feature" {
+		t.Errorf("extractDAGBlueprint(blueprint) = %q, want feature", got)
+	}
+	if got := extractDAGBlueprint("Plan: " + withBlueprint); got != "feature" {
+		t.Errorf("extractDAGBlueprint(prose+JSON) = %q, want feature", got)
+	}
+	if got := extractDAGBlueprint(adHoc); got != "" {
+		t.Errorf("extractDAGBlueprint(ad hoc) = %q, want empty", got)
+	}
+}
+
+func TestSuggestBlueprints(t *testing.T) {
+	c := &Component{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	master := &agentprogression.Agent{Tier: domain.TierMaster}
+
+	t.Run("DM pick is recommended and instantiated", func(t *testing.T) {
+		quest := &domain.Quest{
+			Title:            "Ship rate limiting",
+			Goal:             "Add rate limiting to the API",
+			PartyRequired:    true,
+			DAGBlueprint:     "api_clients",
+			DAGBlueprintArgs: map[string]any{"language": "Rust"},
+		}
+		got := c.suggestBlueprints(context.Background(), master, quest)
+		if len(got) != maxBlueprintSuggestions {
+			t.Fatalf("suggestions = %d, want %d", len(got), maxBlueprintSuggestions)
+		}
+		rec := got[0]
+		if rec.ID != "api_clients" || !rec.Recommended {
+			t.Fatalf("first suggestion = %+v, want recommended api_clients", rec)
+		}
+		if rec.Args["goal"] != quest.Goal || rec.Args["language"] != "Rust" {
+			t.Errorf("args = %v", rec.Args)
+		}
+		if !strings.Contains(rec.NodesJSON, "Rust client") {
+			t.Errorf("nodes not instantiated with args: %s", rec.NodesJSON)
+		}
+		for _, s := range got[1:] {
+			if s.Recommended || s.NodesJSON != "" {
+				t.Errorf("only the recommended blueprint carries nodes: %+v", s)
+			}
+		}
+	})
+
+	t.Run("class selects blueprint", func(t *testing.T) {
+		quest := &domain.Quest{Title: "Compare databases", PartyRequired: true, DecomposabilityClass: domain.DecomposableParallel}
+		got := c.suggestBlueprints(context.Background(), master, quest)
+		if len(got) == 0 || got[0].ID != "research_report" || !got[0].Recommended {
+			t.Fatalf("suggestions = %+v, want research_report recommended", got)
+		}
+	})
+
+	t.Run("non-decomposer gets none", func(t *testing.T) {
+		quest := &domain.Quest{Title: "Solo", DecomposabilityClass: domain.DecomposableParallel}
+		if got := c.suggestBlueprints(context.Background(), master, quest); got != nil {
+			t.Errorf("suggestions = %+v, want nil", got)
+		}
+	})
+}
+
 // =============================================================================
 // dagNodesToQuests
 // =============================================================================
@@ -896,6 +962,19 @@ func TestDagNodesToQuests(t *testing.T) {
 		}
 	})
 
+	t.Run("output contract is carried to the sub-quest", func(t *testing.T) {
+		quests := dagNodesToQuests([]questdagexec.QuestNode{
+			{ID: "n1", Objective: "List APIs", OutputContract: "api_list"},
+			{ID: "n2", Objective: "Free-form notes"},
+		}, parent)
+		if questdagexec.OutputSchemaFromAny(quests[0].DAGOutputSchema) == nil {
+			t.Error("DAGOutputSchema not set for node with a contract")
+		}
+		if quests[1].DAGOutputSchema != nil {
+			t.Errorf("DAGOutputSchema = %v; want nil for node without a contract", quests[1].DAGOutputSchema)
+		}
+	})
+
 	t.Run("node with zero difficulty inherits parent difficulty", func(t *testing.T) {
 		nodes := []questdagexec.QuestNode{
 			{ID: "n1", Objective: "Task", Difficulty: 0},
@@ -947,6 +1026,22 @@ func TestDagNodesToQuests(t *testing.T) {
 			t.Errorf("dagNodesToQuests(nil) len = %d; want 0", len(quests))
 		}
 	})
+
+	t.Run("nested sub-quests are one level below their parent", func(t *testing.T) {
+		nested := &domain.Quest{ID: "c360.test.game.board1.quest.sub", DAGLevel: 1}
+		nodes := []questdagexec.QuestNode{
+			{ID: "n1", Objective: "Design schema", Decomposable: true},
+			{ID: "n2", Objective: "Write migration"},
+		}
+		quests := dagNodesToQuests(nodes, nested)
+		if quests[0].DAGLevel != 2 || quests[1].DAGLevel != 2 {
+			t.Errorf("DAGLevel = %d, %d; want 2", quests[0].DAGLevel, quests[1].DAGLevel)
+		}
+		if !quests[0].DAGDecomposable || quests[1].DAGDecomposable {
+			t.Errorf("DAGDecomposable = %v, %v; want true, false",
+				quests[0].DAGDecomposable, quests[1].DAGDecomposable)
+		}
+	})
 }
 
 // =============================================================================
@@ -1304,6 +1399,19 @@ func TestIsOutputClarificationRequest(t *testing.T) {
 // parseToolOutput
 // =============================================================================
 
+func TestParseTypedOutput(t *testing.T) {
+	typed := parseTypedOutput(`{"type":"work_product","deliverable":"{}","output":{"apis":["billing"]}}`)
+	m, ok := typed.(map[string]any)
+	if !ok || m["apis"] == nil {
+		t.Errorf("parseTypedOutput() = %v, want output object", typed)
+	}
+	for _, in := range []string{`{"type":"work_product","summary":"done"}`, "plain text"} {
+		if got := parseTypedOutput(in); got != nil {
+			t.Errorf("parseTypedOutput(%q) = %v, want nil", in, got)
+		}
+	}
+}
+
 func TestParseToolOutput(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -1658,6 +1766,7 @@ func TestMaxIterationsForDifficulty(t *testing.T) {
 func TestCategoriesForQuest(t *testing.T) {
 	masterAgent := &agentprogression.Agent{Tier: domain.TierMaster}
 	journeymanAgent := &agentprogression.Agent{Tier: domain.TierJourneyman}
+	parentQuestID := domain.QuestID("c360.test.game.board1.quest.parent")
 
 	tests := []struct {
 		name        string
@@ -1724,6 +1833,34 @@ func TestCategoriesForQuest(t *testing.T) {
 				executor.ToolCategoryPartyLead,
 			},
 		},
+		{
+			name: "master on decomposable sub-quest also gets party_lead",
+			quest: &domain.Quest{
+				ParentQuest:     &parentQuestID,
+				DAGDecomposable: true,
+			},
+			agent: masterAgent,
+			wantCats: []executor.ToolCategory{
+				executor.ToolCategoryCore,
+				executor.ToolCategoryWrite,
+				executor.ToolCategoryPartyLead,
+			},
+		},
+		{
+			name: "journeyman on decomposable sub-quest does not get party_lead",
+			quest: &domain.Quest{
+				ParentQuest:     &parentQuestID,
+				DAGDecomposable: true,
+			},
+			agent: journeymanAgent,
+			wantCats: []executor.ToolCategory{
+				executor.ToolCategoryCore,
+				executor.ToolCategoryWrite,
+			},
+			notWantCats: []executor.ToolCategory{
+				executor.ToolCategoryPartyLead,
+			},
+		},
 		{
 			name:  "mixed skills with CodeGen gets full set",
 			quest: &domain.Quest{RequiredSkills: []domain.SkillTag{domain.SkillResearch, domain.SkillCodeGen}},
@@ -1798,3 +1935,46 @@ func TestQuestRequiresCodeGen(t *testing.T) {
 		})
 	}
 }
+
+func TestSupersededLoop(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		mapping QuestLoopMapping
+		loopID  string
+		want    bool
+	}{
+		{"same loop", QuestLoopMapping{LoopID: "quest-a-1", LoopType: LoopTypeExecution}, "quest-a-1", false},
+		{"replaced loop", QuestLoopMapping{LoopID: "quest-a-2", LoopType: LoopTypeExecution}, "quest-a-1", true},
+		{"legacy mapping without type", QuestLoopMapping{LoopID: "quest-a-2"}, "quest-a-1", true},
+		{"review mapping", QuestLoopMapping{LoopID: "review-a-2", LoopType: LoopTypeReview}, "review-a-1", false},
+		{"event without loop ID", QuestLoopMapping{LoopID: "quest-a-2", LoopType: LoopTypeExecution}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := supersededLoop(&tt.mapping, tt.loopID); got != tt.want {
+				t.Errorf("supersededLoop() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandedOver(t *testing.T) {
+	t.Parallel()
+
+	replacement := domain.AgentID("agent-new")
+	departed := domain.AgentID("agent-old")
+	mapping := &QuestLoopMapping{AgentID: departed}
+
+	if !handedOver(&domain.Quest{ClaimedBy: &replacement}, mapping) {
+		t.Error("quest claimed by replacement should count as handed over")
+	}
+	if handedOver(&domain.Quest{ClaimedBy: &departed}, mapping) {
+		t.Error("quest still claimed by loop agent should not count as handed over")
+	}
+	if handedOver(&domain.Quest{}, mapping) {
+		t.Error("unclaimed quest should not count as handed over")
+	}
+}
@@ -0,0 +1,204 @@
+package questdagexec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/c360studio/semstreams/agentic"
+)
+
+const amendToolName = "amend_dag"
+
+// AmendExecutor implements the amend_dag tool.
+// The party lead calls it from a review or clarification loop when a node
+// reveals that the plan itself is wrong. Like decompose_quest it is a
+// validation passthrough: the executor checks the amendment's structure and
+// returns it as JSON; the questdagexec event loop validates it against the
+// live DAGExecutionState, optionally asks the DM, and applies it.
+//
+// All public methods are safe for concurrent use — the struct holds no mutable state.
+type AmendExecutor struct{}
+
+// NewAmendExecutor constructs an AmendExecutor.
+func NewAmendExecutor() *AmendExecutor {
+	return &AmendExecutor{}
+}
+
+// Execute validates the amendment arguments and returns an amendment JSON envelope.
+//
+// Argument validation errors are surfaced as non-nil ToolResult.Error strings
+// rather than Go errors so the LLM can correct its call.
+func (e *AmendExecutor) Execute(_ context.Context, call agentic.ToolCall) (agentic.ToolResult, error) {
+	reason, ok := stringArg(call.Arguments, "reason")
+	if !ok || reason == "" {
+		return decomposeErrorResult(call, `missing required argument "reason"`), nil
+	}
+
+	am := DAGAmendment{Reason: reason}
+
+	if rawAdd, exists := call.Arguments["add"]; exists && rawAdd != nil {
+		if sl, isSlice := rawAdd.([]any); !isSlice || len(sl) > 0 {
+			added, err := parseQuestNodes(rawAdd)
+			if err != nil {
+				return decomposeErrorResult(call, fmt.Sprintf("invalid add argument: %s", err)), nil
+			}
+			am.Add = added.Nodes
+		}
+	}
+
+	remove, _, err := stringSliceField(call.Arguments, "remove")
+	if err != nil {
+		return decomposeErrorResult(call, err.Error()), nil
+	}
+	am.Remove = remove
+
+	if rawUpdate, exists := call.Arguments["update"]; exists && rawUpdate != nil {
+		updates, err := parseNodeAmendments(rawUpdate)
+		if err != nil {
+			return decomposeErrorResult(call, fmt.Sprintf("invalid update argument: %s", err)), nil
+		}
+		am.Update = updates
+	}
+
+	if len(am.Add) == 0 && len(am.Remove) == 0 && len(am.Update) == 0 {
+		return decomposeErrorResult(call, "amendment must add, remove, or update at least one node"), nil
+	}
+
+	return decomposeJSONResult(call, map[string]any{"amendment": am})
+}
+
+// ListTools returns the single tool definition for amend_dag.
+func (e *AmendExecutor) ListTools() []agentic.ToolDefinition {
+	return []agentic.ToolDefinition{{
+		Name:        amendToolName,
+		Description: "Re-plan the party quest DAG mid-execution. Add new nodes, remove nodes that have not started, or edit the objective, acceptance criteria, and dependencies of nodes that have not started. Completed and in-progress nodes cannot be changed. You will be asked to finish the current review or clarification afterwards.",
+		Parameters: map[string]any{
+			"type":     "object",
+			"required": []string{"reason"},
+			"properties": map[string]any{
+				"reason": map[string]any{
+					"type":        "string",
+					"description": "Why the plan must change — what this node revealed",
+				},
+				"add": map[string]any{
+					"type":        "array",
+					"description": "New sub-quest nodes. IDs must be new; depends_on may reference any node, including completed ones.",
+					"items": map[string]any{
+						"type":     "object",
+						"required": []string{"id", "objective"},
+						"properties": map[string]any{
+							"id":              map[string]any{"type": "string"},
+							"objective":       map[string]any{"type": "string"},
+							"skills":          map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+							"difficulty":      map[string]any{"type": "integer"},
+							"acceptance":      map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+							"depends_on":      map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+							"output_schema":   map[string]any{"type": "object"},
+							"output_contract": map[string]any{"type": "string", "enum": OutputContractNames()},
+							"condition":       conditionSchema(),
+							"fan_out":         fanOutSchema(),
+						},
+					},
+				},
+				"remove": map[string]any{
+					"type":        "array",
+					"description": "IDs of pending or ready nodes to drop",
+					"items":       map[string]any{"type": "string"},
+				},
+				"update": map[string]any{
+					"type":        "array",
+					"description": "Edits to pending or ready nodes. Omitted fields are unchanged; an empty depends_on clears dependencies.",
+					"items": map[string]any{
+						"type":     "object",
+						"required": []string{"id"},
+						"properties": map[string]any{
+							"id":         map[string]any{"type": "string"},
+							"objective":  map[string]any{"type": "string"},
+							"acceptance": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+							"depends_on": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+						},
+					},
+				},
+			},
+		},
+	}}
+}
+
+// -- helpers --
+
+// parseNodeAmendments converts the raw "update" argument into NodeAmendments.
+func parseNodeAmendments(raw any) ([]NodeAmendment, error) {
+	slice, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("update must be an array, got %T", raw)
+	}
+
+	updates := make([]NodeAmendment, 0, len(slice))
+	for i, item := range slice {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("update[%d] must be an object, got %T", i, item)
+		}
+		id, ok := stringField(m, "id")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("update[%d]: missing required field \"id\"", i)
+		}
+		objective, _ := stringField(m, "objective")
+
+		acceptance, _, err := stringSliceField(m, "acceptance")
+		if err != nil {
+			return nil, fmt.Errorf("update[%d]: %w", i, err)
+		}
+		dependsOn, present, err := stringSliceField(m, "depends_on")
+		if err != nil {
+			return nil, fmt.Errorf("update[%d]: %w", i, err)
+		}
+		// An explicit empty array clears dependencies; keep it non-nil.
+		if present && dependsOn == nil {
+			dependsOn = []string{}
+		}
+
+		updates = append(updates, NodeAmendment{
+			ID:         id,
+			Objective:  objective,
+			Acceptance: acceptance,
+			DependsOn:  dependsOn,
+		})
+	}
+	return updates, nil
+}
+
+// stringSliceField extracts a string array from an object field map by key.
+// present reports whether the key held a non-null value.
+func stringSliceField(m map[string]any, key string) (values []string, present bool, err error) {
+	raw, exists := m[key]
+	if !exists || raw == nil {
+		return nil, false, nil
+	}
+	sl, ok := raw.([]any)
+	if !ok {
+		return nil, true, fmt.Errorf("%s must be an array, got %T", key, raw)
+	}
+	for j, v := range sl {
+		s, ok := v.(string)
+		if !ok {
+			return nil, true, fmt.Errorf("%s[%d] must be a string, got %T", key, j, v)
+		}
+		values = append(values, s)
+	}
+	return values, true, nil
+}
+
+// parseAmendmentResult extracts a DAGAmendment from a lead loop's output when
+// the lead ended the loop with amend_dag. Returns false for any other output,
+// such as a review verdict or clarification answer.
+func parseAmendmentResult(result string) (DAGAmendment, bool) {
+	var envelope struct {
+		Amendment *DAGAmendment `json:"amendment"`
+	}
+	if err := json.Unmarshal([]byte(result), &envelope); err != nil || envelope.Amendment == nil {
+		return DAGAmendment{}, false
+	}
+	return *envelope.Amendment, true
+}
@@ -0,0 +1,181 @@
+package questdagexec
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semstreams/agentic"
+)
+
+func TestAmendExecutorListTools(t *testing.T) {
+	t.Parallel()
+
+	tools := NewAmendExecutor().ListTools()
+	if len(tools) != 1 {
+		t.Fatalf("ListTools() returned %d tools, want 1", len(tools))
+	}
+	if tools[0].Name != amendToolName {
+		t.Errorf("tool name = %q, want %q", tools[0].Name, amendToolName)
+	}
+	required, ok := tools[0].Parameters["required"].([]string)
+	if !ok || len(required) != 1 || required[0] != "reason" {
+		t.Errorf("required = %v, want [reason]", tools[0].Parameters["required"])
+	}
+}
+
+func TestAmendExecutorExecute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{
+			name: "valid amendment",
+			args: map[string]any{
+				"reason": "schema needs a migration step",
+				"add":    []any{map[string]any{"id": "migrate", "objective": "Write migration", "depends_on": []any{"schema"}}},
+				"remove": []any{"docs"},
+				"update": []any{map[string]any{"id": "api", "depends_on": []any{}}},
+			},
+		},
+		{
+			name:    "missing reason",
+			args:    map[string]any{"remove": []any{"docs"}},
+			wantErr: `"reason"`,
+		},
+		{
+			name:    "no changes",
+			args:    map[string]any{"reason": "r", "add": []any{}},
+			wantErr: "at least one node",
+		},
+		{
+			name:    "update without id",
+			args:    map[string]any{"reason": "r", "update": []any{map[string]any{"objective": "x"}}},
+			wantErr: `"id"`,
+		},
+		{
+			name:    "remove must be strings",
+			args:    map[string]any{"reason": "r", "remove": []any{1.0}},
+			wantErr: "remove[0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			call := agentic.ToolCall{ID: "call-1", Name: amendToolName, Arguments: tt.args}
+			result, err := NewAmendExecutor().Execute(context.Background(), call)
+			if err != nil {
+				t.Fatalf("Execute() Go error: %v", err)
+			}
+			if tt.wantErr != "" {
+				if !strings.Contains(result.Error, tt.wantErr) {
+					t.Errorf("result.Error = %q, want containing %q", result.Error, tt.wantErr)
+				}
+				return
+			}
+			if result.Error != "" {
+				t.Fatalf("result.Error = %q, want none", result.Error)
+			}
+
+			am, ok := parseAmendmentResult(result.Content)
+			if !ok {
+				t.Fatalf("parseAmendmentResult(%s) failed", result.Content)
+			}
+			if len(am.Add) != 1 || am.Add[0].ID != "migrate" || len(am.Remove) != 1 {
+				t.Errorf("amendment = %+v", am)
+			}
+			// An explicit empty depends_on survives the round trip as "clear".
+			if len(am.Update) != 1 || am.Update[0].DependsOn == nil || len(am.Update[0].DependsOn) != 0 {
+				t.Errorf("update = %+v, want depends_on cleared", am.Update)
+			}
+		})
+	}
+}
+
+func TestParseAmendmentResult_IgnoresOtherLeadOutputs(t *testing.T) {
+	t.Parallel()
+
+	for _, out := range []string{
+		`{"verdict":"accept","sub_quest_id":"q1"}`,
+		`{"sub_quest_id":"q1","answer":"use postgres"}`,
+		"I accept this work.",
+	} {
+		if _, ok := parseAmendmentResult(out); ok {
+			t.Errorf("parseAmendmentResult(%q) = true, want false", out)
+		}
+	}
+}
+
+func TestAmendmentPromptAndTools(t *testing.T) {
+	t.Parallel()
+
+	c := newTestComponent(nil, nil)
+	ds := makeFullDAGState("exec-1", "parent-1", "party-1", []QuestNode{{ID: "a", Objective: "A"}})
+
+	if tools := c.amendmentTools(ds); len(tools) != 1 || tools[0].Name != amendToolName {
+		t.Errorf("amendmentTools() = %v, want amend_dag", tools)
+	}
+	if prompt := c.amendmentPrompt(ds); !strings.Contains(prompt, "amend_dag") {
+		t.Errorf("amendmentPrompt() = %q, want amend_dag guidance", prompt)
+	}
+
+	for range c.config.MaxAmendments {
+		ds.Amendments = append(ds.Amendments, DAGAmendment{
+			Reason: "split work", Remove: []string{"x"}, Status: AmendmentRejected, Error: "unknown node",
+		})
+	}
+	if tools := c.amendmentTools(ds); len(tools) != 0 {
+		t.Errorf("amendmentTools() at limit = %v, want none", tools)
+	}
+	prompt := c.amendmentPrompt(ds)
+	if strings.Contains(prompt, "call amend_dag") {
+		t.Error("prompt offers amend_dag after the limit is reached")
+	}
+	if !strings.Contains(prompt, "[rejected] remove x: split work (unknown node)") {
+		t.Errorf("prompt missing amendment history:\n%s", prompt)
+	}
+
+	c.config.MaxAmendments = 0
+	if prompt := c.amendmentPrompt(makeFullDAGState("exec-2", "p", "party", nil)); prompt != "" {
+		t.Errorf("amendmentPrompt() with amendments disabled = %q, want empty", prompt)
+	}
+}
+
+// blockingApproval never answers; RequestApproval returns only when its
+// context is cancelled.
+type blockingApproval struct{}
+
+func (blockingApproval) RequestApproval(ctx context.Context, _ domain.ApprovalRequest) (*domain.ApprovalResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestAwaitAmendmentApproval_StopsOnShutdown(t *testing.T) {
+	t.Parallel()
+
+	c := newTestComponent(nil, nil)
+	c.stopChan = make(chan struct{})
+	c.events = make(chan dagEvent)
+
+	c.wg.Add(1)
+	go c.awaitAmendmentApproval(context.Background(), blockingApproval{}, "exec-1", "quest", "loop-1",
+		DAGAmendment{Reason: "split work", Remove: []string{"x"}})
+	close(c.stopChan)
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("awaitAmendmentApproval did not return after stopChan closed")
+	}
+}
@@ -0,0 +1,557 @@
+package questdagexec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semstreams/agentic"
+)
+
+// =============================================================================
+// DAG AMENDMENTS — mid-execution re-planning by the party lead
+// =============================================================================
+//
+// Review and clarification loops offer the lead the amend_dag tool alongside
+// their own tool while the DAG is under its amendment limit. When the lead
+// ends a loop with amend_dag instead of a verdict or answer, the event loop:
+//
+//  1. Validates the amendment against the live DAGExecutionState (AmendDAG).
+//  2. Optionally asks the DM via a quest_decomposition approval. The approval
+//     blocks, so it runs in its own goroutine and reports back to the event
+//     loop as a dagEventAmendmentDecided.
+//  3. Applies it: posts added nodes as party sub-quests, cancels removed
+//     ones, rewrites edited ones, and re-gates unstarted nodes.
+//  4. Records the outcome on the parent quest and re-dispatches the
+//     interrupted review or clarification so the node still gets its answer.
+// =============================================================================
+
+// onAmendmentProposed handles a lead loop that ended with amend_dag.
+// Called from onReviewCompleted and onClarificationAnswered on the event loop.
+func (c *Component) onAmendmentProposed(ctx context.Context, evt dagEvent, am DAGAmendment) {
+	subQuestID := c.extractSubQuestFromLeadLoopID(evt.LoopID)
+	if subQuestID == "" {
+		c.logger.Warn("amendment: cannot extract sub-quest ID from lead loop ID",
+			"loop_id", evt.LoopID)
+		return
+	}
+	dagState := c.findDAGForSubQuest(c.subQuestEntityKey(subQuestID))
+	if dagState == nil {
+		c.logger.Warn("amendment: sub-quest not part of any active DAG",
+			"loop_id", evt.LoopID, "sub_quest_id", subQuestID)
+		return
+	}
+	am.AmendedBy = c.findLeadAgentID(dagState)
+
+	c.logger.Info("party lead proposed DAG amendment",
+		"execution_id", dagState.ExecutionID, "loop_id", evt.LoopID,
+		"add", len(am.Add), "remove", len(am.Remove), "update", len(am.Update),
+		"reason", am.Reason)
+
+	if len(dagState.Amendments) >= c.config.MaxAmendments {
+		c.recordAmendment(ctx, dagState, am, AmendmentRejected, "amendment limit reached")
+		c.redispatchLeadLoop(ctx, dagState, evt.LoopID, subQuestID)
+		return
+	}
+
+	// Validate before asking the DM so invalid proposals never reach them.
+	if _, _, err := AmendDAG(dagState.DAG, dagState.NodeStates, am); err != nil {
+		c.recordAmendment(ctx, dagState, am, AmendmentRejected, err.Error())
+		c.redispatchLeadLoop(ctx, dagState, evt.LoopID, subQuestID)
+		return
+	}
+	if err := c.checkAmendmentBudget(ctx, dagState, am); err != nil {
+		c.recordAmendment(ctx, dagState, am, AmendmentRejected, err.Error())
+		c.redispatchLeadLoop(ctx, dagState, evt.LoopID, subQuestID)
+		return
+	}
+
+	if c.config.RequireAmendmentApproval {
+		if approval := c.resolveApproval(); approval != nil {
+			c.wg.Add(1)
+			go c.awaitAmendmentApproval(ctx, approval, dagState.ExecutionID, dagState.QuestTitle, evt.LoopID, am)
+			return
+		}
+	}
+
+	c.applyAmendment(ctx, dagState, am, evt.LoopID, subQuestID)
+}
+
+// awaitAmendmentApproval asks the DM to approve an amendment and sends the
+// decision back to the event loop. Runs in its own goroutine because
+// RequestApproval blocks; it must not touch event-loop-owned state.
+func (c *Component) awaitAmendmentApproval(ctx context.Context, approval DMApprovalRef, executionID, questTitle, loopID string, am DAGAmendment) {
+	defer c.wg.Done()
+
+	// The DM may never answer. Abandon the request on shutdown so Stop does
+	// not wait on it.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	decided := dagEvent{Type: dagEventAmendmentDecided, LoopID: loopID, Amendment: &am}
+
+	resp, err := approval.RequestApproval(ctx, domain.ApprovalRequest{
+		SessionID: c.config.ApprovalSessionID,
+		Type:      domain.ApprovalQuestDecomposition,
+		Title:     fmt.Sprintf("Party lead wants to amend the plan for %q", questTitle),
+		Details:   describeAmendment(am),
+		Payload:   am,
+		Options: []domain.ApprovalOption{
+			{ID: "approve", Label: "Approve", IsDefault: true},
+			{ID: "deny", Label: "Deny"},
+		},
+		Metadata: map[string]string{"execution_id": executionID},
+	})
+	switch {
+	case err != nil:
+		c.logger.Warn("amendment approval request failed, denying amendment",
+			"execution_id", executionID, "error", err)
+		decided.ErrorReason = fmt.Sprintf("approval request failed: %s", err)
+	case !resp.Approved:
+		decided.ErrorReason = "denied by DM"
+		if resp.Reason != "" {
+			decided.ErrorReason += ": " + resp.Reason
+		}
+	default:
+		decided.Approved = true
+	}
+
+	select {
+	case <-c.stopChan:
+		return
+	default:
+	}
+	select {
+	case c.events <- decided:
+	case <-c.stopChan:
+	case <-ctx.Done():
+	}
+}
+
+// onAmendmentDecided applies or records the DM's decision on an amendment.
+func (c *Component) onAmendmentDecided(ctx context.Context, evt dagEvent) {
+	if evt.Amendment == nil {
+		return
+	}
+	subQuestID := c.extractSubQuestFromLeadLoopID(evt.LoopID)
+	dagState := c.findDAGForSubQuest(c.subQuestEntityKey(subQuestID))
+	if subQuestID == "" || dagState == nil {
+		c.logger.Warn("amendment decision: DAG no longer active",
+			"loop_id", evt.LoopID, "approved", evt.Approved)
+		return
+	}
+
+	if !evt.Approved {
+		c.recordAmendment(ctx, dagState, *evt.Amendment, AmendmentDenied, evt.ErrorReason)
+		c.redispatchLeadLoop(ctx, dagState, evt.LoopID, subQuestID)
+		return
+	}
+	c.applyAmendment(ctx, dagState, *evt.Amendment, evt.LoopID, subQuestID)
+}
+
+// applyAmendment re-validates an amendment against the current DAG state
+// and node budget (either may have moved on while the DM decided), materializes it on the
+// questboard, swaps in the amended DAG, and resumes the lead loop.
+func (c *Component) applyAmendment(ctx context.Context, dagState *DAGExecutionState, am DAGAmendment, loopID, subQuestID string) {
+	dag, states, err := AmendDAG(dagState.DAG, dagState.NodeStates, am)
+	if err == nil {
+		err = c.checkAmendmentBudget(ctx, dagState, am)
+	}
+	if err != nil {
+		c.recordAmendment(ctx, dagState, am, AmendmentRejected, err.Error())
+		c.redispatchLeadLoop(ctx, dagState, loopID, subQuestID)
+		return
+	}
+
+	// Post added nodes first: it is the only step that can fail outright,
+	// and nothing has changed yet if it does.
+	var posted []domain.Quest
+	if len(am.Add) > 0 {
+		posted, err = c.postAmendedNodes(ctx, dagState, am)
+		if err != nil {
+			c.logger.Error("failed to post amended DAG nodes",
+				"execution_id", dagState.ExecutionID, "error", err)
+			c.errorsCount.Add(1)
+			c.recordAmendment(ctx, dagState, am, AmendmentRejected, err.Error())
+			c.redispatchLeadLoop(ctx, dagState, loopID, subQuestID)
+			return
+		}
+	}
+
+	for _, nodeID := range am.Remove {
+		c.removeAmendedNode(ctx, dagState, nodeID, am.Reason)
+	}
+	for i, node := range am.Add {
+		dagState.NodeQuestIDs[node.ID] = string(posted[i].ID)
+		dagState.NodeRetries[node.ID] = c.config.MaxRetriesPerNode
+	}
+
+	dagState.DAG = dag
+	dagState.NodeStates = states
+
+	// Wire the new and edited sub-quests to the party and their dependencies.
+	for i, node := range am.Add {
+		c.syncAmendedSubQuest(ctx, dagState, node.ID, &posted[i], "quest.dag.sub_quest_initialized")
+	}
+	for _, u := range am.Update {
+		c.syncAmendedSubQuest(ctx, dagState, u.ID, nil, "quest.dag.node_amended")
+	}
+
+	c.indexDAGState(dagState)
+	c.recordAmendment(ctx, dagState, am, AmendmentApplied, "")
+	c.assignReadyNodes(ctx, dagState)
+	c.redispatchLeadLoop(ctx, dagState, loopID, subQuestID)
+}
+
+// checkAmendmentBudget rejects an amendment whose added nodes would push the
+// DAG tree past MaxTotalDAGNodes, the same budget questbridge enforces on
+// nested decompositions. Removed nodes keep counting: their sub-quests were
+// posted already.
+func (c *Component) checkAmendmentBudget(ctx context.Context, dagState *DAGExecutionState, am DAGAmendment) error {
+	if len(am.Add) == 0 {
+		return nil
+	}
+	existing, err := c.countTreeSubQuests(ctx, dagState)
+	if err != nil {
+		return fmt.Errorf("count dag nodes: %w", err)
+	}
+	return CheckNodeBudget(existing, len(am.Add), c.config.MaxTotalDAGNodes)
+}
+
+// countTreeSubQuests counts the sub-quests posted across the whole DAG tree
+// dagState belongs to. It walks ParentQuest up to the top-level party quest,
+// then counts down through each level's quest.dag.node_quest_ids. The
+// amended DAG itself is counted from memory, which is ahead of its quest.
+func (c *Component) countTreeSubQuests(ctx context.Context, dagState *DAGExecutionState) (int, error) {
+	root := domain.QuestID(dagState.ParentQuestID)
+	for hops := 0; hops < dagState.Level; hops++ {
+		entity, err := c.graph.GetQuest(ctx, root)
+		if err != nil {
+			return 0, fmt.Errorf("load quest %s: %w", root, err)
+		}
+		quest := domain.QuestFromEntityState(entity)
+		if quest == nil || quest.ParentQuest == nil {
+			break
+		}
+		root = *quest.ParentQuest
+	}
+	return c.countSubQuests(ctx, root, dagState, make(map[domain.QuestID]bool))
+}
+
+// countSubQuests counts the sub-quests of questID's DAG and of every nested
+// DAG beneath it. seen guards against revisiting a quest.
+func (c *Component) countSubQuests(ctx context.Context, questID domain.QuestID, amended *DAGExecutionState, seen map[domain.QuestID]bool) (int, error) {
+	if seen[questID] {
+		return 0, nil
+	}
+	seen[questID] = true
+
+	var nodeQuestIDs map[string]string
+	if questID == domain.QuestID(amended.ParentQuestID) {
+		nodeQuestIDs = amended.NodeQuestIDs
+	} else {
+		entity, err := c.graph.GetQuest(ctx, questID)
+		if err != nil {
+			return 0, fmt.Errorf("load quest %s: %w", questID, err)
+		}
+		quest := domain.QuestFromEntityState(entity)
+		if quest == nil || quest.DAGExecutionID == "" {
+			return 0, nil
+		}
+		nodeQuestIDs = anyToStringMap(quest.DAGNodeQuestIDs)
+	}
+
+	count := len(nodeQuestIDs)
+	for _, subQuestID := range nodeQuestIDs {
+		n, err := c.countSubQuests(ctx, domain.QuestID(subQuestID), amended, seen)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+	return count, nil
+}
+
+// postAmendedNodes posts the amendment's added nodes as sub-quests of the
+// parent quest, decomposed by the lead.
+func (c *Component) postAmendedNodes(ctx context.Context, dagState *DAGExecutionState, am DAGAmendment) ([]domain.Quest, error) {
+	return c.postNodeSubQuests(ctx, dagState, am.Add, am.AmendedBy)
+}
+
+// postNodeSubQuests posts nodes added to a running DAG as sub-quests of the
+// parent quest, decomposed by the given lead. Used by amendments and fan-out
+// expansion.
+func (c *Component) postNodeSubQuests(ctx context.Context, dagState *DAGExecutionState, nodes []QuestNode, leadID string) ([]domain.Quest, error) {
+	qb := c.resolveQuestBoard()
+	if qb == nil {
+		return nil, fmt.Errorf("questboard unavailable, cannot post added nodes")
+	}
+	entity, err := c.graph.GetQuest(ctx, domain.QuestID(dagState.ParentQuestID))
+	if err != nil {
+		return nil, fmt.Errorf("load parent quest: %w", err)
+	}
+	parent := domain.QuestFromEntityState(entity)
+
+	posted, err := qb.PostSubQuests(ctx, domain.QuestID(dagState.ParentQuestID),
+		amendedNodeQuests(nodes, parent), domain.AgentID(leadID))
+	if err != nil {
+		return nil, fmt.Errorf("post sub-quests: %w", err)
+	}
+	if len(posted) != len(nodes) {
+		return nil, fmt.Errorf("posted %d sub-quests, expected %d", len(posted), len(nodes))
+	}
+	return posted, nil
+}
+
+// removeAmendedNode drops a node from the DAG state and cancels its
+// sub-quest.
+func (c *Component) removeAmendedNode(ctx context.Context, dagState *DAGExecutionState, nodeID, reason string) {
+	c.cancelNodeSubQuest(ctx, dagState, nodeID, "quest.dag.node_removed", "Removed from the party plan: "+reason)
+	delete(dagState.NodeQuestIDs, nodeID)
+	delete(dagState.NodeRetries, nodeID)
+	delete(dagState.NodeAssignees, nodeID)
+}
+
+// cancelNodeSubQuest cancels a node's sub-quest with the given reason. The
+// sub-quest is unindexed first so its cancellation is not mistaken for a
+// node failure.
+func (c *Component) cancelNodeSubQuest(ctx context.Context, dagState *DAGExecutionState, nodeID, predicate, reason string) {
+	subQuestID := dagState.NodeQuestIDs[nodeID]
+	if subQuestID == "" {
+		return
+	}
+	delete(c.dagBySubQuest, c.subQuestEntityKey(subQuestID))
+	if c.graph == nil {
+		return
+	}
+
+	entity, err := c.graph.GetQuest(ctx, domain.QuestID(subQuestID))
+	if err != nil {
+		c.logger.Warn("failed to load sub-quest to cancel",
+			"sub_quest_id", subQuestID, "node_id", nodeID, "error", err)
+		return
+	}
+	quest := domain.QuestFromEntityState(entity)
+	if quest == nil {
+		return
+	}
+	quest.Status = domain.QuestCancelled
+	quest.FailureReason = reason
+	if err := c.graph.EmitEntityUpdate(ctx, quest, predicate); err != nil {
+		c.logger.Warn("failed to cancel sub-quest",
+			"sub_quest_id", subQuestID, "node_id", nodeID, "error", err)
+	}
+}
+
+// syncAmendedSubQuest writes a node's party, node ID, objective, acceptance
+// and resolved dependencies onto its sub-quest. quest is loaded from the
+// graph when nil.
+func (c *Component) syncAmendedSubQuest(ctx context.Context, dagState *DAGExecutionState, nodeID string, quest *domain.Quest, predicate string) {
+	subQuestID := dagState.NodeQuestIDs[nodeID]
+	if quest == nil {
+		entity, err := c.graph.GetQuest(ctx, domain.QuestID(subQuestID))
+		if err != nil {
+			c.logger.Warn("amendment: failed to load sub-quest",
+				"sub_quest_id", subQuestID, "error", err)
+			return
+		}
+		if quest = domain.QuestFromEntityState(entity); quest == nil {
+			return
+		}
+	}
+
+	var node QuestNode
+	for _, n := range dagState.DAG.Nodes {
+		if n.ID == nodeID {
+			node = n
+			break
+		}
+	}
+
+	if dagState.PartyID != "" {
+		partyID := domain.PartyID(dagState.PartyID)
+		quest.PartyID = &partyID
+	}
+	quest.DAGNodeID = nodeID
+	quest.Title = subQuestTitle(node.Objective)
+	quest.Description = node.Objective
+	quest.Acceptance = node.Acceptance
+	quest.DAGOutputSchema = node.SubQuestOutputSchema()
+	quest.DependsOn = make([]domain.QuestID, 0, len(node.DependsOn))
+	for _, dep := range node.DependsOn {
+		if depQuestID, ok := dagState.NodeQuestIDs[dep]; ok {
+			quest.DependsOn = append(quest.DependsOn, domain.QuestID(depQuestID))
+		}
+	}
+
+	if err := c.graph.EmitEntityUpdate(ctx, quest, predicate); err != nil {
+		c.logger.Warn("amendment: failed to update sub-quest",
+			"sub_quest_id", subQuestID, "node_id", nodeID, "error", err)
+	}
+}
+
+// recordAmendment appends an amendment outcome to the DAG's history and
+// persists it on the parent quest.
+func (c *Component) recordAmendment(ctx context.Context, dagState *DAGExecutionState, am DAGAmendment, status, reason string) {
+	am.Status = status
+	am.Error = reason
+	am.AmendedAt = time.Now()
+	dagState.Amendments = append(dagState.Amendments, am)
+
+	c.logger.Info("DAG amendment recorded",
+		"execution_id", dagState.ExecutionID, "status", status, "error", reason,
+		"amendments", len(dagState.Amendments))
+
+	if err := c.persistDAGState(ctx, dagState); err != nil {
+		c.logger.Error("failed to persist DAG state after amendment",
+			"execution_id", dagState.ExecutionID, "error", err)
+		c.errorsCount.Add(1)
+	}
+}
+
+// redispatchLeadLoop re-sends the review or clarification the lead
+// interrupted with amend_dag, so the node still gets its verdict or answer.
+func (c *Component) redispatchLeadLoop(ctx context.Context, dagState *DAGExecutionState, loopID, subQuestID string) {
+	nodeID := c.findNodeForQuest(dagState, subQuestID)
+	if nodeID == "" {
+		return
+	}
+	entity, err := c.graph.GetQuest(ctx, domain.QuestID(subQuestID))
+	if err != nil {
+		c.logger.Error("amendment: failed to reload sub-quest for lead re-dispatch",
+			"sub_quest_id", subQuestID, "error", err)
+		c.errorsCount.Add(1)
+		return
+	}
+	switch {
+	case strings.HasPrefix(loopID, "review-"):
+		c.dispatchLeadReview(ctx, dagState, nodeID, entity)
+	case strings.HasPrefix(loopID, "clarify-"):
+		c.dispatchLeadClarification(ctx, dagState, nodeID, entity)
+	}
+}
+
+// amendmentTools returns the amend_dag tool while the DAG is under its
+// amendment limit, for inclusion in lead review and clarification loops.
+func (c *Component) amendmentTools(dagState *DAGExecutionState) []agentic.ToolDefinition {
+	if len(dagState.Amendments) >= c.config.MaxAmendments {
+		return nil
+	}
+	return NewAmendExecutor().ListTools()
+}
+
+// amendmentPrompt returns the lead-prompt section describing amend_dag and
+// the DAG's amendment history. Empty when amendments are disabled and none
+// were made.
+func (c *Component) amendmentPrompt(dagState *DAGExecutionState) string {
+	offered := len(dagState.Amendments) < c.config.MaxAmendments
+	if !offered && len(dagState.Amendments) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n## Re-planning\n")
+	if offered {
+		sb.WriteString("If this work shows the plan itself is wrong, call amend_dag instead to add, remove, " +
+			"or rewire nodes that have not started. You will be asked to finish this task afterwards.\n")
+	}
+	if len(dagState.Amendments) > 0 {
+		sb.WriteString("\nAmendments so far:\n")
+		for _, am := range dagState.Amendments {
+			fmt.Fprintf(&sb, "- [%s] %s", am.Status, describeAmendment(am))
+			if am.Error != "" {
+				fmt.Fprintf(&sb, " (%s)", am.Error)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// describeAmendment summarizes an amendment in one line.
+func describeAmendment(am DAGAmendment) string {
+	var parts []string
+	if len(am.Add) > 0 {
+		ids := make([]string, len(am.Add))
+		for i, n := range am.Add {
+			ids[i] = n.ID
+		}
+		parts = append(parts, "add "+strings.Join(ids, ", "))
+	}
+	if len(am.Remove) > 0 {
+		parts = append(parts, "remove "+strings.Join(am.Remove, ", "))
+	}
+	if len(am.Update) > 0 {
+		ids := make([]string, len(am.Update))
+		for i, u := range am.Update {
+			ids[i] = u.ID
+		}
+		parts = append(parts, "update "+strings.Join(ids, ", "))
+	}
+	return strings.Join(parts, "; ") + ": " + am.Reason
+}
+
+// amendedNodeQuests converts added DAG nodes into sub-quests, inheriting the
+// parent's difficulty when a node leaves it unset. Mirrors how questbridge
+// posts the nodes of the original decomposition. A fan-out instance's item
+// becomes its sub-quest's input.
+func amendedNodeQuests(nodes []QuestNode, parent *domain.Quest) []domain.Quest {
+	quests := make([]domain.Quest, 0, len(nodes))
+	for _, node := range nodes {
+		difficulty := domain.QuestDifficulty(node.Difficulty)
+		level := 1
+		if parent != nil {
+			if difficulty == 0 {
+				difficulty = parent.Difficulty
+			}
+			level = parent.DAGLevel + 1
+		}
+
+		skills := make([]domain.SkillTag, 0, len(node.Skills))
+		for _, s := range node.Skills {
+			skills = append(skills, domain.SkillTag(s))
+		}
+
+		quests = append(quests, domain.Quest{
+			Title:           subQuestTitle(node.Objective),
+			Description:     node.Objective,
+			Difficulty:      difficulty,
+			RequiredSkills:  skills,
+			Acceptance:      node.Acceptance,
+			DAGDecomposable: node.Decomposable,
+			DAGLevel:        level,
+			DAGOutputSchema: node.SubQuestOutputSchema(),
+			Input:           node.FanOutItem,
+		})
+	}
+	return quests
+}
+
+// subQuestTitle truncates a node objective to a sub-quest title, as
+// questbridge does for the original decomposition.
+func subQuestTitle(objective string) string {
+	if len(objective) > 100 {
+		return objective[:100]
+	}
+	return objective
+}
+
+// dagSubQuestIDs returns the DAG's sub-quest IDs in node order.
+func dagSubQuestIDs(dagState *DAGExecutionState) []domain.QuestID {
+	ids := make([]domain.QuestID, 0, len(dagState.DAG.Nodes))
+	for _, node := range dagState.DAG.Nodes {
+		if id := dagState.NodeQuestIDs[node.ID]; id != "" {
+			ids = append(ids, domain.QuestID(id))
+		}
+	}
+	return ids
+}
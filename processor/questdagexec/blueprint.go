@@ -0,0 +1,658 @@
+package questdagexec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	semdragons "github.com/c360studio/semdragons"
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semstreams/graph"
+	"github.com/c360studio/semstreams/message"
+	"github.com/c360studio/semstreams/natsclient"
+)
+
+// =============================================================================
+// DAG BLUEPRINTS
+// =============================================================================
+// A Blueprint is a named, parameterized QuestDAG for a recurring quest shape
+// (design → implement → test → docs, parallel research → report, ...).
+// Node objectives and acceptance criteria may reference parameters as
+// {{name}}; Instantiate substitutes them to produce a concrete DAG.
+//
+// Blueprints are selected three ways: the DM names one when posting a quest
+// (QuestHints.Blueprint), ClassifyDecomposability picks the best-ranked
+// blueprint for the quest's class, or the lead passes one to decompose_quest.
+// Either way the lead sees the instantiated nodes in its prompt and may adapt
+// them before submitting. Each DAG execution built from a blueprint records
+// its outcome on the blueprint entity, and the library ranks blueprints by
+// smoothed success rate so proven shapes surface first.
+//
+// Built-in blueprints ship with the binary; blueprints in KV (promoted from
+// successful DAGs via the API) override built-ins with the same ID.
+// =============================================================================
+
+// maxBlueprints bounds the blueprint entities read from KV.
+const maxBlueprints = 200
+
+// ErrBlueprintNotFound is returned when no blueprint has the requested ID.
+var ErrBlueprintNotFound = errors.New("blueprint not found")
+
+// ErrBlueprintExists is returned by Create when the ID is already stored.
+var ErrBlueprintExists = errors.New("blueprint already exists")
+
+// blueprintCASRetries bounds RecordOutcome's read-modify-write attempts.
+const blueprintCASRetries = 5
+
+// blueprintIDPattern restricts blueprint IDs to entity-ID-safe slugs.
+var blueprintIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// blueprintParamPattern matches {{param}} placeholders in node text.
+var blueprintParamPattern = regexp.MustCompile(`\{\{\s*([a-z0-9_]+)\s*\}\}`)
+
+// BlueprintGoalParam is the parameter filled from the quest goal when the
+// caller does not supply it.
+const BlueprintGoalParam = "goal"
+
+// Blueprint is a reusable QuestDAG template.
+type Blueprint struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Classes lists the decomposability classes this blueprint suits. Only
+	// blueprints with a matching class are selected automatically.
+	Classes []domain.DecomposabilityClass `json:"classes,omitempty"`
+
+	// Params declares the {{name}} placeholders used by Nodes.
+	Params []BlueprintParam `json:"params,omitempty"`
+
+	// Nodes is the DAG template. Node IDs and dependencies are fixed; text
+	// fields may contain placeholders.
+	Nodes []QuestNode `json:"nodes"`
+
+	// Outcome tracking. Uses counts finished executions.
+	Uses       int        `json:"uses"`
+	Successes  int        `json:"successes"`
+	Failures   int        `json:"failures"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	// SourceQuestID is the quest whose DAG was promoted into this blueprint.
+	SourceQuestID string    `json:"source_quest_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// BuiltIn marks blueprints shipped with the binary that have not been
+	// written to KV yet. Not persisted.
+	BuiltIn bool `json:"built_in,omitempty"`
+
+	BoardConfig *domain.BoardConfig `json:"-"`
+}
+
+// BlueprintParam is a blueprint placeholder.
+type BlueprintParam struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// DefaultBlueprints returns the built-in blueprint library.
+func DefaultBlueprints() []Blueprint {
+	goal := BlueprintParam{Name: BlueprintGoalParam, Description: "What the party quest must achieve", Required: true}
+	return []Blueprint{
+		{
+			ID:          "feature",
+			Name:        "Feature delivery",
+			Description: "Design, implement, test and document a feature. Tests and docs run in parallel once the implementation lands.",
+			Classes:     []domain.DecomposabilityClass{domain.DecomposableSequential, domain.DecomposableMixed},
+			Params: []BlueprintParam{goal, {
+				Name: "location", Description: "Where the code lives (package, directory or module)", Default: "the repository",
+			}},
+			Nodes: []QuestNode{
+				{ID: "design", Objective: "Design the change for: {{goal}}. Write the design to DESIGN.md covering the files to touch in {{location}}, the interfaces and the edge cases.",
+					Skills: []string{string(domain.SkillPlanning)}, Acceptance: []string{"DESIGN.md names every file to create or modify"}},
+				{ID: "implement", Objective: "Implement the design in DESIGN.md for: {{goal}}. Keep changes inside {{location}}.",
+					Skills: []string{string(domain.SkillCodeGen)}, DependsOn: []string{"design"},
+					Acceptance: []string{"Implementation matches DESIGN.md", "Code builds"}},
+				{ID: "test", Objective: "Write tests for the implementation of: {{goal}}. Cover the edge cases listed in DESIGN.md.",
+					Skills: []string{string(domain.SkillCodeGen)}, DependsOn: []string{"implement"},
+					Acceptance: []string{"Tests pass", "Edge cases from DESIGN.md are covered"}},
+				{ID: "docs", Objective: "Document the change for: {{goal}}. Update the README or user docs with usage examples.",
+					Skills: []string{string(domain.SkillSummarization)}, DependsOn: []string{"implement"},
+					Acceptance: []string{"Docs describe how to use the change"}},
+			},
+		},
+		{
+			ID:          "research_report",
+			Name:        "Parallel research report",
+			Description: "Research a topic from independent angles in parallel, then synthesize one report.",
+			Classes:     []domain.DecomposabilityClass{domain.DecomposableParallel},
+			Params:      []BlueprintParam{goal},
+			Nodes: []QuestNode{
+				{ID: "background", Objective: "Research the background and current state of: {{goal}}.",
+					Skills: []string{string(domain.SkillResearch)}, OutputContract: "findings"},
+				{ID: "options", Objective: "Research the alternative approaches to: {{goal}}, with trade-offs.",
+					Skills: []string{string(domain.SkillResearch)}, OutputContract: "findings"},
+				{ID: "risks", Objective: "Research the risks and open questions for: {{goal}}.",
+					Skills: []string{string(domain.SkillAnalysis)}, OutputContract: "findings"},
+				{ID: "report", Objective: "Write a report on: {{goal}}, combining the background, options and risks findings into a recommendation.",
+					Skills: []string{string(domain.SkillSummarization)}, DependsOn: []string{"background", "options", "risks"},
+					Acceptance: []string{"Report ends with a clear recommendation"}},
+			},
+		},
+		{
+			ID:          "api_clients",
+			Name:        "Client per API",
+			Description: "Extract the APIs a system exposes, generate one client per API in parallel, then integrate them.",
+			Params: []BlueprintParam{goal, {
+				Name: "language", Description: "Client language", Default: "Go",
+			}},
+			Nodes: []QuestNode{
+				{ID: "extract", Objective: "List the APIs needed for: {{goal}}. Return each API's name, base URL and endpoints.",
+					Skills: []string{string(domain.SkillAnalysis)}, OutputContract: "api_list"},
+				{ID: "generate", Objective: "Generate a {{language}} client for the API described below, with tests.",
+					Skills: []string{string(domain.SkillCodeGen)}, DependsOn: []string{"extract"}, OutputContract: "file_list",
+					FanOut: &FanOutSpec{From: "extract", Field: "apis"}},
+				{ID: "integrate", Objective: "Integrate the generated {{language}} clients for: {{goal}} behind one package.",
+					Skills: []string{string(domain.SkillCodeGen)}, DependsOn: []string{"generate"},
+					Acceptance: []string{"Every generated client is reachable from the package", "Code builds"}},
+			},
+		},
+	}
+}
+
+// Validate checks the blueprint's identity, parameters and DAG template.
+func (b *Blueprint) Validate() error {
+	if !blueprintIDPattern.MatchString(b.ID) {
+		return fmt.Errorf("blueprint id %q must be a lowercase slug (letters, digits, '-' or '_')", b.ID)
+	}
+	if b.Name == "" {
+		return fmt.Errorf("blueprint %q: name is required", b.ID)
+	}
+
+	declared := make(map[string]bool, len(b.Params))
+	for _, p := range b.Params {
+		if !blueprintParamPattern.MatchString("{{" + p.Name + "}}") {
+			return fmt.Errorf("blueprint %q: invalid parameter name %q", b.ID, p.Name)
+		}
+		if declared[p.Name] {
+			return fmt.Errorf("blueprint %q: duplicate parameter %q", b.ID, p.Name)
+		}
+		declared[p.Name] = true
+	}
+	for _, name := range b.placeholders() {
+		if !declared[name] {
+			return fmt.Errorf("blueprint %q: placeholder {{%s}} has no parameter", b.ID, name)
+		}
+	}
+
+	dag := QuestDAG{Nodes: b.Nodes}
+	if err := dag.Validate(); err != nil {
+		return fmt.Errorf("blueprint %q: %w", b.ID, err)
+	}
+	return nil
+}
+
+// placeholders returns the parameter names referenced by the node templates.
+func (b *Blueprint) placeholders() []string {
+	seen := make(map[string]bool)
+	var names []string
+	collect := func(text string) {
+		for _, m := range blueprintParamPattern.FindAllStringSubmatch(text, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				names = append(names, m[1])
+			}
+		}
+	}
+	for _, n := range b.Nodes {
+		collect(n.Objective)
+		for _, a := range n.Acceptance {
+			collect(a)
+		}
+	}
+	return names
+}
+
+// Instantiate substitutes args into the template and returns a validated
+// DAG. Missing arguments take the parameter default; a required parameter
+// without a value or an argument naming no parameter is an error. The goal
+// argument is always accepted, since callers fill it from the quest without
+// knowing whether the blueprint uses it.
+func (b *Blueprint) Instantiate(args map[string]string) (QuestDAG, error) {
+	values := make(map[string]string, len(b.Params))
+	for _, p := range b.Params {
+		v := strings.TrimSpace(args[p.Name])
+		if v == "" {
+			v = p.Default
+		}
+		if v == "" && p.Required {
+			return QuestDAG{}, fmt.Errorf("blueprint %q: missing required parameter %q", b.ID, p.Name)
+		}
+		values[p.Name] = v
+	}
+	for name := range args {
+		if _, ok := values[name]; !ok && name != BlueprintGoalParam {
+			return QuestDAG{}, fmt.Errorf("blueprint %q: unknown parameter %q", b.ID, name)
+		}
+	}
+
+	fill := func(text string) string {
+		return blueprintParamPattern.ReplaceAllStringFunc(text, func(m string) string {
+			return values[blueprintParamPattern.FindStringSubmatch(m)[1]]
+		})
+	}
+
+	dag := QuestDAG{Nodes: make([]QuestNode, len(b.Nodes))}
+	for i, n := range b.Nodes {
+		n.Objective = fill(n.Objective)
+		n.Acceptance = slices.Clone(n.Acceptance)
+		for j := range n.Acceptance {
+			n.Acceptance[j] = fill(n.Acceptance[j])
+		}
+		n.Skills = slices.Clone(n.Skills)
+		n.DependsOn = slices.Clone(n.DependsOn)
+		dag.Nodes[i] = n
+	}
+	if err := dag.Validate(); err != nil {
+		return QuestDAG{}, fmt.Errorf("blueprint %q: %w", b.ID, err)
+	}
+	return dag, nil
+}
+
+// SuccessRate returns the Laplace-smoothed success rate, so an unused
+// blueprint ranks at 0.5 rather than at either extreme.
+func (b *Blueprint) SuccessRate() float64 {
+	return float64(b.Successes+1) / float64(b.Successes+b.Failures+2)
+}
+
+// SuitsClass reports whether the blueprint is eligible for automatic
+// selection on quests of the given class.
+func (b *Blueprint) SuitsClass(class domain.DecomposabilityClass) bool {
+	return class != "" && slices.Contains(b.Classes, class)
+}
+
+// RankBlueprints sorts blueprints best first: by success rate, then by use
+// count (proven beats untried at the same rate), then by ID.
+func RankBlueprints(blueprints []Blueprint) {
+	sort.SliceStable(blueprints, func(i, j int) bool {
+		ri, rj := blueprints[i].SuccessRate(), blueprints[j].SuccessRate()
+		if ri != rj {
+			return ri > rj
+		}
+		if blueprints[i].Uses != blueprints[j].Uses {
+			return blueprints[i].Uses > blueprints[j].Uses
+		}
+		return blueprints[i].ID < blueprints[j].ID
+	})
+}
+
+// BlueprintFromDAG turns an executed DAG into a blueprint template. Fan-out
+// instances are dropped in favour of their templates, so the blueprint
+// expands afresh each time it runs.
+func BlueprintFromDAG(id, name string, dag QuestDAG) Blueprint {
+	bp := Blueprint{ID: id, Name: name}
+	for _, n := range dag.Nodes {
+		if n.ExpandedFrom != "" {
+			continue
+		}
+		n.FanOutItem = nil
+		bp.Nodes = append(bp.Nodes, n)
+	}
+	return bp
+}
+
+// DAGFromAny decodes a quest's stored DAG definition (quest.DAGDefinition).
+// Returns an empty DAG when v is nil or malformed.
+func DAGFromAny(v any) QuestDAG {
+	return anyToQuestDAG(v)
+}
+
+// =============================================================================
+// GRAPHABLE IMPLEMENTATION
+// =============================================================================
+
+// EntityID returns the 6-part entity ID for this blueprint.
+// Format: org.platform.game.board.blueprint.instance
+func (b *Blueprint) EntityID() string {
+	if b.BoardConfig != nil {
+		return b.BoardConfig.BlueprintEntityID(b.ID)
+	}
+	return "blueprint." + b.ID
+}
+
+// Triples returns all semantic facts about this blueprint.
+func (b *Blueprint) Triples() []message.Triple {
+	now := time.Now()
+	source := "questdagexec"
+	entityID := b.EntityID()
+
+	classes := make([]string, 0, len(b.Classes))
+	for _, c := range b.Classes {
+		classes = append(classes, string(c))
+	}
+
+	triples := []message.Triple{
+		{Subject: entityID, Predicate: "blueprint.name", Object: b.Name, Source: source, Timestamp: now, Confidence: 1.0},
+		{Subject: entityID, Predicate: "blueprint.description", Object: b.Description, Source: source, Timestamp: now, Confidence: 1.0},
+		{Subject: entityID, Predicate: "blueprint.classes", Object: classes, Source: source, Timestamp: now, Confidence: 1.0},
+		{Subject: entityID, Predicate: "blueprint.params", Object: b.Params, Source: source, Timestamp: now, Confidence: 1.0},
+		{Subject: entityID, Predicate: "blueprint.nodes", Object: b.Nodes, Source: source, Timestamp: now, Confidence: 1.0},
+		{Subject: entityID, Predicate: "blueprint.uses", Object: b.Uses, Source: source, Timestamp: now, Confidence: 1.0},
+		{Subject: entityID, Predicate: "blueprint.successes", Object: b.Successes, Source: source, Timestamp: now, Confidence: 1.0},
+		{Subject: entityID, Predicate: "blueprint.failures", Object: b.Failures, Source: source, Timestamp: now, Confidence: 1.0},
+		{Subject: entityID, Predicate: "blueprint.created_at", Object: b.CreatedAt.Format(time.RFC3339), Source: source, Timestamp: now, Confidence: 1.0},
+	}
+	if b.LastUsedAt != nil {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "blueprint.last_used_at", Object: b.LastUsedAt.Format(time.RFC3339),
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+	if b.SourceQuestID != "" {
+		triples = append(triples, message.Triple{
+			Subject: entityID, Predicate: "blueprint.source_quest_id", Object: b.SourceQuestID,
+			Source: source, Timestamp: now, Confidence: 1.0,
+		})
+	}
+	return triples
+}
+
+// BlueprintFromEntityState reconstructs a Blueprint from graph entity state.
+func BlueprintFromEntityState(entity *graph.EntityState) *Blueprint {
+	if entity == nil {
+		return nil
+	}
+
+	b := &Blueprint{ID: domain.ExtractInstance(entity.ID)}
+	for _, triple := range entity.Triples {
+		switch triple.Predicate {
+		case "blueprint.name":
+			b.Name = domain.AsString(triple.Object)
+		case "blueprint.description":
+			b.Description = domain.AsString(triple.Object)
+		case "blueprint.classes":
+			for _, c := range anyToStringSlice(triple.Object) {
+				b.Classes = append(b.Classes, domain.DecomposabilityClass(c))
+			}
+		case "blueprint.params":
+			_ = roundTripJSON(triple.Object, &b.Params)
+		case "blueprint.nodes":
+			_ = roundTripJSON(triple.Object, &b.Nodes)
+		case "blueprint.uses":
+			b.Uses = domain.AsInt(triple.Object)
+		case "blueprint.successes":
+			b.Successes = domain.AsInt(triple.Object)
+		case "blueprint.failures":
+			b.Failures = domain.AsInt(triple.Object)
+		case "blueprint.created_at":
+			b.CreatedAt = domain.AsTime(triple.Object)
+		case "blueprint.last_used_at":
+			if t := domain.AsTime(triple.Object); !t.IsZero() {
+				b.LastUsedAt = &t
+			}
+		case "blueprint.source_quest_id":
+			b.SourceQuestID = domain.AsString(triple.Object)
+		}
+	}
+	return b
+}
+
+// BlueprintArgsFromAny converts blueprint arguments that round-tripped
+// through KV as map[string]any back to strings. Non-string values are
+// rendered with fmt. Returns nil for nil or non-map input.
+func BlueprintArgsFromAny(v any) map[string]string {
+	switch args := v.(type) {
+	case map[string]string:
+		return args
+	case map[string]any:
+		out := make(map[string]string, len(args))
+		for k, val := range args {
+			if s, ok := val.(string); ok {
+				out[k] = s
+			} else {
+				out[k] = fmt.Sprint(val)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// roundTripJSON decodes a KV-round-tripped triple object into out.
+func roundTripJSON(v any, out any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// =============================================================================
+// LIBRARY
+// =============================================================================
+
+// BlueprintSource looks up a blueprint by ID. decompose_quest uses it to
+// instantiate the blueprint a lead names.
+type BlueprintSource interface {
+	Get(ctx context.Context, id string) (*Blueprint, error)
+}
+
+// BlueprintGraph is the subset of GraphClient the blueprint library uses.
+// *semdragons.GraphClient satisfies it.
+type BlueprintGraph interface {
+	Config() *domain.BoardConfig
+	GetBlueprint(ctx context.Context, blueprintID string) (*graph.EntityState, error)
+	GetBlueprintWithRevision(ctx context.Context, blueprintID string) (*graph.EntityState, uint64, error)
+	ListBlueprintsByPrefix(ctx context.Context, limit int) ([]graph.EntityState, error)
+	EmitEntity(ctx context.Context, entity graph.Graphable, eventType string) error
+	EmitEntityCAS(ctx context.Context, entity graph.Graphable, eventType string, revision uint64) error
+}
+
+// BlueprintLibrary reads and writes blueprints in the graph, falling back to
+// the built-ins. A library without a graph serves the built-ins only.
+//
+// The library holds no state of its own and is safe for concurrent use.
+type BlueprintLibrary struct {
+	graph BlueprintGraph
+}
+
+// NewBlueprintLibrary constructs a BlueprintLibrary over the graph client.
+// A nil client yields a built-ins-only library.
+func NewBlueprintLibrary(gc *semdragons.GraphClient) *BlueprintLibrary {
+	if gc == nil {
+		// Avoid storing a typed nil in the interface field.
+		return &BlueprintLibrary{}
+	}
+	return &BlueprintLibrary{graph: gc}
+}
+
+// NewBlueprintLibraryWithGraph constructs a BlueprintLibrary over any
+// BlueprintGraph implementation, such as a test double.
+func NewBlueprintLibraryWithGraph(g BlueprintGraph) *BlueprintLibrary {
+	return &BlueprintLibrary{graph: g}
+}
+
+// List returns every blueprint, best ranked first. When KV cannot be read
+// the built-ins are still returned alongside the error.
+func (l *BlueprintLibrary) List(ctx context.Context) ([]Blueprint, error) {
+	byID := make(map[string]Blueprint)
+	for _, bp := range DefaultBlueprints() {
+		bp.BuiltIn = true
+		byID[bp.ID] = bp
+	}
+
+	var listErr error
+	if l.graph != nil {
+		entities, err := l.graph.ListBlueprintsByPrefix(ctx, maxBlueprints)
+		if err != nil {
+			listErr = fmt.Errorf("list blueprints: %w", err)
+		}
+		for i := range entities {
+			if bp := BlueprintFromEntityState(&entities[i]); bp != nil && bp.Validate() == nil {
+				byID[bp.ID] = *bp
+			}
+		}
+	}
+
+	blueprints := make([]Blueprint, 0, len(byID))
+	for _, bp := range byID {
+		blueprints = append(blueprints, bp)
+	}
+	RankBlueprints(blueprints)
+	return blueprints, listErr
+}
+
+// Get returns the blueprint with the given ID. KV entities take precedence
+// over built-ins. Returns ErrBlueprintNotFound when neither exists.
+func (l *BlueprintLibrary) Get(ctx context.Context, id string) (*Blueprint, error) {
+	if l.graph != nil && blueprintIDPattern.MatchString(id) {
+		if entity, err := l.graph.GetBlueprint(ctx, id); err == nil {
+			if bp := BlueprintFromEntityState(entity); bp != nil && bp.Name != "" {
+				return bp, nil
+			}
+		}
+	}
+	for _, bp := range DefaultBlueprints() {
+		if bp.ID == id {
+			bp.BuiltIn = true
+			return &bp, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrBlueprintNotFound, id)
+}
+
+// Select returns the best-ranked blueprint suited to the class, or nil when
+// none fits. Trivial quests never get one.
+func (l *BlueprintLibrary) Select(ctx context.Context, class domain.DecomposabilityClass) (*Blueprint, error) {
+	if class == "" || class == domain.DecomposableTrivial {
+		return nil, nil
+	}
+	blueprints, err := l.List(ctx)
+	for i := range blueprints {
+		if blueprints[i].SuitsClass(class) {
+			return &blueprints[i], err
+		}
+	}
+	return nil, err
+}
+
+// Save validates and writes a blueprint to KV.
+func (l *BlueprintLibrary) Save(ctx context.Context, bp *Blueprint) error {
+	if err := l.prepare(bp); err != nil {
+		return err
+	}
+	return l.graph.EmitEntity(ctx, bp, "blueprint.saved")
+}
+
+// Create validates and writes a new blueprint to KV. It returns
+// ErrBlueprintExists when the ID is already stored, so a promotion never
+// overwrites a blueprint and the outcomes recorded against it.
+func (l *BlueprintLibrary) Create(ctx context.Context, bp *Blueprint) error {
+	if err := l.prepare(bp); err != nil {
+		return err
+	}
+	if err := l.graph.EmitEntityCAS(ctx, bp, "blueprint.saved", 0); err != nil {
+		if errors.Is(err, natsclient.ErrKVRevisionMismatch) {
+			return fmt.Errorf("%w: %q", ErrBlueprintExists, bp.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// prepare validates bp and stamps it for writing to KV.
+func (l *BlueprintLibrary) prepare(bp *Blueprint) error {
+	if err := bp.Validate(); err != nil {
+		return err
+	}
+	if l.graph == nil {
+		return errors.New("blueprint library has no graph client")
+	}
+	if bp.CreatedAt.IsZero() {
+		bp.CreatedAt = time.Now()
+	}
+	bp.BuiltIn = false
+	bp.BoardConfig = l.graph.Config()
+	return nil
+}
+
+// RecordOutcome counts a finished execution against a blueprint. A built-in
+// is written to KV on its first recorded outcome. The counters are written
+// with CAS, re-reading and retrying when another outcome or a promotion
+// changed the blueprint in between.
+func (l *BlueprintLibrary) RecordOutcome(ctx context.Context, id string, success bool, now time.Time) error {
+	if l.graph == nil {
+		return errors.New("blueprint library has no graph client")
+	}
+	if !blueprintIDPattern.MatchString(id) {
+		return fmt.Errorf("%w: %q", ErrBlueprintNotFound, id)
+	}
+	for range blueprintCASRetries {
+		bp, revision, err := l.getWithRevision(ctx, id)
+		if err != nil {
+			return err
+		}
+		bp.Uses++
+		if success {
+			bp.Successes++
+		} else {
+			bp.Failures++
+		}
+		bp.LastUsedAt = &now
+		if err := l.prepare(bp); err != nil {
+			return err
+		}
+		err = l.graph.EmitEntityCAS(ctx, bp, "blueprint.saved", revision)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, natsclient.ErrKVRevisionMismatch) {
+			return fmt.Errorf("CAS write blueprint: %w", err)
+		}
+	}
+	return fmt.Errorf("blueprint %s outcome exhausted %d CAS retries", id, blueprintCASRetries)
+}
+
+// getWithRevision reads a stored blueprint and its KV revision. A built-in
+// that was never stored is returned with revision 0, so writing it back
+// creates it.
+func (l *BlueprintLibrary) getWithRevision(ctx context.Context, id string) (*Blueprint, uint64, error) {
+	entity, revision, err := l.graph.GetBlueprintWithRevision(ctx, id)
+	if err == nil {
+		if bp := BlueprintFromEntityState(entity); bp != nil && bp.Name != "" {
+			return bp, revision, nil
+		}
+		return nil, 0, fmt.Errorf("reconstruct blueprint %s from entity state", id)
+	}
+	if !errors.Is(err, natsclient.ErrKVKeyNotFound) {
+		return nil, 0, err
+	}
+	for _, bp := range DefaultBlueprints() {
+		if bp.ID == id {
+			return &bp, 0, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("%w: %q", ErrBlueprintNotFound, id)
+}
+
+// recordBlueprintOutcome counts a finished DAG execution against the
+// blueprint it was decomposed from, if any.
+//
+// Called only from the event loop goroutine.
+func (c *Component) recordBlueprintOutcome(ctx context.Context, dagState *DAGExecutionState, success bool) {
+	if dagState.Blueprint == "" || c.graph == nil {
+		return
+	}
+	if err := NewBlueprintLibrary(c.graph).RecordOutcome(ctx, dagState.Blueprint, success, time.Now()); err != nil {
+		c.logger.Warn("failed to record blueprint outcome",
+			"execution_id", dagState.ExecutionID, "blueprint", dagState.Blueprint,
+			"success", success, "error", err)
+	}
+}
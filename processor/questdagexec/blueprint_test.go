@@ -0,0 +1,424 @@
+package questdagexec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semstreams/agentic"
+	"github.com/c360studio/semstreams/graph"
+	"github.com/c360studio/semstreams/message"
+	"github.com/c360studio/semstreams/natsclient"
+)
+
+func TestDefaultBlueprintsValid(t *testing.T) {
+	t.Parallel()
+
+	seen := make(map[string]bool)
+	for _, bp := range DefaultBlueprints() {
+		if err := bp.Validate(); err != nil {
+			t.Errorf("built-in blueprint %q invalid: %v", bp.ID, err)
+		}
+		if seen[bp.ID] {
+			t.Errorf("duplicate built-in blueprint ID %q", bp.ID)
+		}
+		seen[bp.ID] = true
+	}
+}
+
+func TestBlueprintValidate(t *testing.T) {
+	t.Parallel()
+
+	node := QuestNode{ID: "a", Objective: "Do {{goal}}"}
+	goal := BlueprintParam{Name: "goal", Required: true}
+
+	tests := []struct {
+		name        string
+		bp          Blueprint
+		errContains string
+	}{
+		{name: "valid", bp: Blueprint{ID: "one", Name: "One", Params: []BlueprintParam{goal}, Nodes: []QuestNode{node}}},
+		{name: "bad id", bp: Blueprint{ID: "Has Spaces", Name: "x", Params: []BlueprintParam{goal}, Nodes: []QuestNode{node}}, errContains: "slug"},
+		{name: "no name", bp: Blueprint{ID: "one", Params: []BlueprintParam{goal}, Nodes: []QuestNode{node}}, errContains: "name"},
+		{name: "undeclared placeholder", bp: Blueprint{ID: "one", Name: "One", Nodes: []QuestNode{node}}, errContains: "{{goal}}"},
+		{name: "duplicate param", bp: Blueprint{ID: "one", Name: "One", Params: []BlueprintParam{goal, goal}, Nodes: []QuestNode{node}}, errContains: "duplicate"},
+		{name: "invalid dag", bp: Blueprint{ID: "one", Name: "One", Params: []BlueprintParam{goal}}, errContains: "at least one node"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.bp.Validate()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestBlueprintInstantiate(t *testing.T) {
+	t.Parallel()
+
+	bp := Blueprint{
+		ID:   "clients",
+		Name: "Clients",
+		Params: []BlueprintParam{
+			{Name: "goal", Required: true},
+			{Name: "language", Default: "Go"},
+		},
+		Nodes: []QuestNode{
+			{ID: "a", Objective: "Plan {{goal}}", Acceptance: []string{"Covers {{ goal }}"}},
+			{ID: "b", Objective: "Write {{language}} code for {{goal}}", DependsOn: []string{"a"}},
+		},
+	}
+
+	t.Run("fills args and defaults", func(t *testing.T) {
+		t.Parallel()
+		dag, err := bp.Instantiate(map[string]string{"goal": "billing"})
+		if err != nil {
+			t.Fatalf("Instantiate() error = %v", err)
+		}
+		if got := dag.Nodes[0].Objective; got != "Plan billing" {
+			t.Errorf("node a objective = %q", got)
+		}
+		if got := dag.Nodes[0].Acceptance[0]; got != "Covers billing" {
+			t.Errorf("node a acceptance = %q", got)
+		}
+		if got := dag.Nodes[1].Objective; got != "Write Go code for billing" {
+			t.Errorf("node b objective = %q", got)
+		}
+		// The template must not be mutated.
+		if bp.Nodes[0].Acceptance[0] != "Covers {{ goal }}" {
+			t.Errorf("template acceptance mutated: %q", bp.Nodes[0].Acceptance[0])
+		}
+	})
+
+	t.Run("missing required", func(t *testing.T) {
+		t.Parallel()
+		if _, err := bp.Instantiate(map[string]string{"language": "Rust"}); err == nil || !strings.Contains(err.Error(), "goal") {
+			t.Fatalf("Instantiate() error = %v, want missing goal", err)
+		}
+	})
+
+	t.Run("unknown param", func(t *testing.T) {
+		t.Parallel()
+		if _, err := bp.Instantiate(map[string]string{"goal": "x", "colour": "red"}); err == nil || !strings.Contains(err.Error(), "colour") {
+			t.Fatalf("Instantiate() error = %v, want unknown colour", err)
+		}
+	})
+
+	t.Run("goal accepted without param", func(t *testing.T) {
+		t.Parallel()
+		plain := Blueprint{ID: "plain", Name: "Plain", Nodes: []QuestNode{{ID: "a", Objective: "Do it"}}}
+		if _, err := plain.Instantiate(map[string]string{"goal": "anything"}); err != nil {
+			t.Fatalf("Instantiate() error = %v", err)
+		}
+	})
+}
+
+func TestRankBlueprints(t *testing.T) {
+	t.Parallel()
+
+	blueprints := []Blueprint{
+		{ID: "untried"},
+		{ID: "flaky", Uses: 4, Successes: 1, Failures: 3},
+		{ID: "proven", Uses: 4, Successes: 4},
+		{ID: "even", Uses: 2, Successes: 1, Failures: 1},
+	}
+	RankBlueprints(blueprints)
+
+	var got []string
+	for _, bp := range blueprints {
+		got = append(got, bp.ID)
+	}
+	want := "proven,even,untried,flaky"
+	if strings.Join(got, ",") != want {
+		t.Errorf("RankBlueprints() order = %v, want %s", got, want)
+	}
+}
+
+func TestBlueprintFromDAG(t *testing.T) {
+	t.Parallel()
+
+	dag := QuestDAG{Nodes: []QuestNode{
+		{ID: "extract", Objective: "List APIs"},
+		{ID: "gen", Objective: "Generate", DependsOn: []string{"extract"}, FanOut: &FanOutSpec{From: "extract", Field: "apis"}},
+		{ID: "gen__1", Objective: "Generate", DependsOn: []string{"extract"}, ExpandedFrom: "gen", FanOutItem: "billing"},
+	}}
+
+	bp := BlueprintFromDAG("gen_clients", "Generate clients", dag)
+	if len(bp.Nodes) != 2 {
+		t.Fatalf("BlueprintFromDAG() nodes = %d, want 2 (expanded instance dropped)", len(bp.Nodes))
+	}
+	if err := bp.Validate(); err != nil {
+		t.Fatalf("promoted blueprint invalid: %v", err)
+	}
+}
+
+func TestBlueprintEntityRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	cfg := domain.DefaultBoardConfig()
+	used := time.Now().Truncate(time.Second)
+	bp := DefaultBlueprints()[0]
+	bp.BoardConfig = &cfg
+	bp.Uses, bp.Successes, bp.Failures = 3, 2, 1
+	bp.LastUsedAt = &used
+	bp.SourceQuestID = "q-1"
+
+	state := &graph.EntityState{ID: bp.EntityID(), Triples: roundTripTriples(t, bp.Triples())}
+	got := BlueprintFromEntityState(state)
+	if got == nil {
+		t.Fatal("BlueprintFromEntityState() = nil")
+	}
+	if got.ID != bp.ID || got.Name != bp.Name || got.SourceQuestID != "q-1" {
+		t.Errorf("identity = %q/%q/%q", got.ID, got.Name, got.SourceQuestID)
+	}
+	if got.Uses != 3 || got.Successes != 2 || got.Failures != 1 {
+		t.Errorf("outcomes = %d/%d/%d, want 3/2/1", got.Uses, got.Successes, got.Failures)
+	}
+	if len(got.Nodes) != len(bp.Nodes) || len(got.Params) != len(bp.Params) || len(got.Classes) != len(bp.Classes) {
+		t.Errorf("template lost in round trip: %+v", got)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("round-tripped blueprint invalid: %v", err)
+	}
+}
+
+// roundTripTriples simulates KV storage by JSON-encoding triple objects.
+func roundTripTriples(t *testing.T, triples []message.Triple) []message.Triple {
+	t.Helper()
+	data, err := json.Marshal(triples)
+	if err != nil {
+		t.Fatalf("marshal triples: %v", err)
+	}
+	var out []message.Triple
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unmarshal triples: %v", err)
+	}
+	return out
+}
+
+func TestBlueprintLibraryWithoutGraph(t *testing.T) {
+	t.Parallel()
+
+	lib := NewBlueprintLibrary(nil)
+	ctx := context.Background()
+
+	all, err := lib.List(ctx)
+	if err != nil || len(all) != len(DefaultBlueprints()) {
+		t.Fatalf("List() = %d blueprints, %v", len(all), err)
+	}
+
+	bp, err := lib.Get(ctx, "feature")
+	if err != nil || !bp.BuiltIn {
+		t.Fatalf("Get(feature) = %+v, %v", bp, err)
+	}
+	if _, err := lib.Get(ctx, "nope"); !errors.Is(err, ErrBlueprintNotFound) {
+		t.Errorf("Get(nope) error = %v, want ErrBlueprintNotFound", err)
+	}
+
+	sel, err := lib.Select(ctx, domain.DecomposableParallel)
+	if err != nil || sel == nil || sel.ID != "research_report" {
+		t.Errorf("Select(parallel) = %+v, %v; want research_report", sel, err)
+	}
+	if sel, _ := lib.Select(ctx, domain.DecomposableTrivial); sel != nil {
+		t.Errorf("Select(trivial) = %q, want nil", sel.ID)
+	}
+
+	if err := lib.Save(ctx, bp); err == nil {
+		t.Error("Save() without a graph succeeded, want error")
+	}
+}
+
+// casBlueprintGraph is an in-memory BlueprintGraph with KV revisions.
+// beforeCAS runs before each CAS write, so a test can slip in a competing
+// writer.
+type casBlueprintGraph struct {
+	cfg       domain.BoardConfig
+	entities  map[string]*graph.EntityState
+	revisions map[string]uint64
+	beforeCAS func()
+}
+
+func newCASBlueprintGraph() *casBlueprintGraph {
+	return &casBlueprintGraph{
+		cfg:       domain.DefaultBoardConfig(),
+		entities:  make(map[string]*graph.EntityState),
+		revisions: make(map[string]uint64),
+	}
+}
+
+func (g *casBlueprintGraph) Config() *domain.BoardConfig { return &g.cfg }
+
+func (g *casBlueprintGraph) GetBlueprint(ctx context.Context, id string) (*graph.EntityState, error) {
+	entity, _, err := g.GetBlueprintWithRevision(ctx, id)
+	return entity, err
+}
+
+func (g *casBlueprintGraph) GetBlueprintWithRevision(_ context.Context, id string) (*graph.EntityState, uint64, error) {
+	entity, ok := g.entities[g.cfg.BlueprintEntityID(id)]
+	if !ok {
+		return nil, 0, natsclient.ErrKVKeyNotFound
+	}
+	return entity, g.revisions[entity.ID], nil
+}
+
+func (g *casBlueprintGraph) ListBlueprintsByPrefix(context.Context, int) ([]graph.EntityState, error) {
+	return nil, nil
+}
+
+func (g *casBlueprintGraph) EmitEntity(_ context.Context, entity graph.Graphable, _ string) error {
+	g.put(entity)
+	return nil
+}
+
+func (g *casBlueprintGraph) EmitEntityCAS(_ context.Context, entity graph.Graphable, _ string, revision uint64) error {
+	if g.beforeCAS != nil {
+		g.beforeCAS()
+	}
+	if g.revisions[entity.EntityID()] != revision {
+		return natsclient.ErrKVRevisionMismatch
+	}
+	g.put(entity)
+	return nil
+}
+
+func (g *casBlueprintGraph) put(entity graph.Graphable) {
+	g.entities[entity.EntityID()] = &graph.EntityState{ID: entity.EntityID(), Triples: entity.Triples()}
+	g.revisions[entity.EntityID()]++
+}
+
+func TestBlueprintLibraryRecordOutcome(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	g := newCASBlueprintGraph()
+	lib := NewBlueprintLibraryWithGraph(g)
+
+	// A built-in is created on its first outcome.
+	if err := lib.RecordOutcome(ctx, "feature", true, time.Now()); err != nil {
+		t.Fatalf("RecordOutcome(feature) = %v", err)
+	}
+
+	// A concurrent outcome lands between this one's read and write; both
+	// must be counted.
+	competing := true
+	g.beforeCAS = func() {
+		if competing {
+			competing = false
+			if err := lib.RecordOutcome(ctx, "feature", false, time.Now()); err != nil {
+				t.Errorf("competing RecordOutcome = %v", err)
+			}
+		}
+	}
+	if err := lib.RecordOutcome(ctx, "feature", true, time.Now()); err != nil {
+		t.Fatalf("RecordOutcome(feature) after conflict = %v", err)
+	}
+
+	bp, err := lib.Get(ctx, "feature")
+	if err != nil {
+		t.Fatalf("Get(feature) = %v", err)
+	}
+	if bp.Uses != 3 || bp.Successes != 2 || bp.Failures != 1 {
+		t.Errorf("outcomes = %d/%d/%d, want 3/2/1", bp.Uses, bp.Successes, bp.Failures)
+	}
+
+	if err := lib.RecordOutcome(ctx, "nope", true, time.Now()); !errors.Is(err, ErrBlueprintNotFound) {
+		t.Errorf("RecordOutcome(nope) = %v, want ErrBlueprintNotFound", err)
+	}
+	if err := lib.Create(ctx, bp); !errors.Is(err, ErrBlueprintExists) {
+		t.Errorf("Create over a stored blueprint = %v, want ErrBlueprintExists", err)
+	}
+}
+
+// fakeBlueprints is an in-memory BlueprintSource.
+type fakeBlueprints map[string]Blueprint
+
+func (f fakeBlueprints) Get(_ context.Context, id string) (*Blueprint, error) {
+	bp, ok := f[id]
+	if !ok {
+		return nil, ErrBlueprintNotFound
+	}
+	return &bp, nil
+}
+
+func TestDecomposeToolBlueprint(t *testing.T) {
+	t.Parallel()
+
+	src := fakeBlueprints{"feature": DefaultBlueprints()[0]}
+	call := func(args map[string]any) agentic.ToolCall {
+		return agentic.ToolCall{ID: "call-1", Name: "decompose_quest", Arguments: args}
+	}
+
+	t.Run("instantiates named blueprint", func(t *testing.T) {
+		t.Parallel()
+		result, err := NewDecomposeExecutor().WithBlueprints(src).Execute(context.Background(), call(map[string]any{
+			"goal":           "Add rate limiting",
+			"blueprint":      "feature",
+			"blueprint_args": map[string]any{"location": "service/api"},
+		}))
+		if err != nil || result.Error != "" {
+			t.Fatalf("Execute() = %v / %q", err, result.Error)
+		}
+		var resp struct {
+			Blueprint string   `json:"blueprint"`
+			DAG       QuestDAG `json:"dag"`
+		}
+		if err := json.Unmarshal([]byte(result.Content), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if resp.Blueprint != "feature" || len(resp.DAG.Nodes) != 4 {
+			t.Fatalf("response = %+v", resp)
+		}
+		if !strings.Contains(resp.DAG.Nodes[0].Objective, "Add rate limiting") ||
+			!strings.Contains(resp.DAG.Nodes[0].Objective, "service/api") {
+			t.Errorf("objective not instantiated: %q", resp.DAG.Nodes[0].Objective)
+		}
+	})
+
+	t.Run("adapted nodes keep blueprint credit", func(t *testing.T) {
+		t.Parallel()
+		result, err := NewDecomposeExecutor().WithBlueprints(src).Execute(context.Background(), call(map[string]any{
+			"goal":      "Add rate limiting",
+			"blueprint": "feature",
+			"nodes":     []any{map[string]any{"id": "only", "objective": "Do it all"}},
+		}))
+		if err != nil || result.Error != "" {
+			t.Fatalf("Execute() = %v / %q", err, result.Error)
+		}
+		if !strings.Contains(result.Content, `"blueprint":"feature"`) || !strings.Contains(result.Content, "Do it all") {
+			t.Errorf("content = %s", result.Content)
+		}
+	})
+
+	t.Run("unknown blueprint", func(t *testing.T) {
+		t.Parallel()
+		result, _ := NewDecomposeExecutor().WithBlueprints(src).Execute(context.Background(), call(map[string]any{
+			"goal": "x", "blueprint": "nope",
+		}))
+		if !strings.Contains(result.Error, "invalid blueprint") {
+			t.Errorf("Error = %q, want invalid blueprint", result.Error)
+		}
+	})
+
+	t.Run("no library and no nodes", func(t *testing.T) {
+		t.Parallel()
+		result, _ := NewDecomposeExecutor().Execute(context.Background(), call(map[string]any{
+			"goal": "x", "blueprint": "feature",
+		}))
+		if !strings.Contains(result.Error, "unavailable") {
+			t.Errorf("Error = %q, want library unavailable", result.Error)
+		}
+	})
+}
@@ -24,6 +24,7 @@ import (
 
 	semdragons "github.com/c360studio/semdragons"
 	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/dmapproval"
 	"github.com/c360studio/semdragons/processor/partycoord"
 	"github.com/c360studio/semstreams/component"
 	"github.com/c360studio/semstreams/message"
@@ -52,6 +53,9 @@ type QuestBoardRef interface {
 	// RepostForRetry resets a sub-quest back to posted status for DAG retry,
 	// preserving the PartyID so it stays within the party's closed system.
 	RepostForRetry(ctx context.Context, questID domain.QuestID) error
+	// PostSubQuests posts additional sub-quests under a parent quest. Used
+	// when an amend_dag amendment adds nodes to a running DAG.
+	PostSubQuests(ctx context.Context, parentID domain.QuestID, subQuests []domain.Quest, decomposer domain.AgentID) ([]domain.Quest, error)
 }
 
 // PartyCoordRef is the narrow interface questdagexec needs from partycoord.
@@ -64,6 +68,15 @@ type PartyCoordRef interface {
 	GetParty(partyID domain.PartyID) (*partycoord.Party, bool)
 	// DisbandParty dissolves the party.
 	DisbandParty(ctx context.Context, partyID domain.PartyID, reason string) error
+	// ReplaceMember swaps a departed member for a replacement on a sub-quest
+	// and records the swap in the party's history.
+	ReplaceMember(ctx context.Context, partyID domain.PartyID, departed, replacement domain.AgentID, subQuestID domain.QuestID, reason string) error
+}
+
+// DMApprovalRef is the narrow interface questdagexec needs from dmapproval.
+// The concrete *dmapproval.Component satisfies this interface.
+type DMApprovalRef interface {
+	RequestApproval(ctx context.Context, req domain.ApprovalRequest) (*domain.ApprovalResponse, error)
 }
 
 // =============================================================================
@@ -96,6 +109,10 @@ type Component struct {
 	// loop mappings that questbridge uses to track loop completion.
 	questLoopsBucket jetstream.KeyValue
 
+	// agents overrides the graph-backed source of replacement candidates
+	// for member hot-swaps. Nil in production.
+	agents AgentLister
+
 	// events is the unified channel all producers write to. The event loop
 	// is the sole reader. Buffered to absorb bursts without blocking producers.
 	events chan dagEvent
@@ -180,6 +197,23 @@ func (c *Component) resolvePartyCoord() PartyCoordRef {
 	return ref
 }
 
+// resolveApproval resolves dmapproval from the ComponentRegistry at call time.
+// Returns nil when absent — amendments are then applied without approval.
+func (c *Component) resolveApproval() DMApprovalRef {
+	if c.deps.ComponentRegistry == nil {
+		return nil
+	}
+	comp := c.deps.ComponentRegistry.Component(dmapproval.ComponentName)
+	if comp == nil {
+		return nil
+	}
+	ref, ok := comp.(DMApprovalRef)
+	if !ok {
+		return nil
+	}
+	return ref
+}
+
 // =============================================================================
 // DISCOVERABLE INTERFACE
 // =============================================================================
@@ -229,14 +263,22 @@ func (c *Component) OutputPorts() []component.Port {
 func (c *Component) ConfigSchema() component.ConfigSchema {
 	return component.ConfigSchema{
 		Properties: map[string]component.PropertySchema{
-			"org":                  {Type: "string", Description: "Organization namespace", Default: "default", Category: "basic"},
-			"platform":             {Type: "string", Description: "Platform/environment name", Default: "local", Category: "basic"},
-			"board":                {Type: "string", Description: "Quest board name", Default: "main", Category: "basic"},
-			"dag_timeout":          {Type: "duration", Description: "Maximum wall-clock time for a DAG to complete", Default: "30m", Category: "advanced"},
-			"recruitment_timeout":  {Type: "duration", Description: "Maximum time to wait for recruitment", Default: "5m", Category: "advanced"},
-			"recruitment_interval": {Type: "duration", Description: "Retry interval for recruitment", Default: "30s", Category: "advanced"},
-			"max_retries_per_node": {Type: "int", Description: "Maximum retries before NodeFailed", Default: 2, Category: "advanced"},
-			"stream_name":          {Type: "string", Description: "AGENT stream name for review task publishing", Default: "AGENT", Category: "basic"},
+			"org":                        {Type: "string", Description: "Organization namespace", Default: "default", Category: "basic"},
+			"platform":                   {Type: "string", Description: "Platform/environment name", Default: "local", Category: "basic"},
+			"board":                      {Type: "string", Description: "Quest board name", Default: "main", Category: "basic"},
+			"dag_timeout":                {Type: "duration", Description: "Maximum wall-clock time for a DAG to complete", Default: "30m", Category: "advanced"},
+			"recruitment_timeout":        {Type: "duration", Description: "Maximum time to wait for recruitment", Default: "5m", Category: "advanced"},
+			"recruitment_interval":       {Type: "duration", Description: "Retry interval for recruitment", Default: "30s", Category: "advanced"},
+			"max_retries_per_node":       {Type: "int", Description: "Maximum retries before NodeFailed", Default: 2, Category: "advanced"},
+			"stream_name":                {Type: "string", Description: "AGENT stream name for review task publishing", Default: "AGENT", Category: "basic"},
+			"max_amendments":             {Type: "int", Description: "Maximum amend_dag proposals per DAG execution (0 disables)", Default: DefaultMaxAmendments, Category: "advanced"},
+			"max_total_dag_nodes":        {Type: "int", Description: "Maximum sub-quests across all DAG levels, including amendments (0 uses the default)", Default: DefaultMaxTotalDAGNodes, Category: "advanced"},
+			"require_amendment_approval": {Type: "bool", Description: "Require DM approval before applying amend_dag proposals", Default: false, Category: "advanced"},
+			"stall_timeout":              {Type: "duration", Description: "Idle time before a stalled node's assignee is swapped (0 disables)", Default: "10m", Category: "advanced"},
+			"clarification_timeout":      {Type: "duration", Description: "Time in awaiting_clarification before the assignee is swapped (0 disables)", Default: "10m", Category: "advanced"},
+			"swap_after_failures":        {Type: "int", Description: "Consecutive loop failures before the assignee is swapped (0 disables)", Default: 2, Category: "advanced"},
+			"attach_dag_diagram":         {Type: "bool", Description: "Store a Mermaid diagram of the executed DAG on the parent quest (quest.dag.diagram) at rollup", Default: false, Category: "advanced"},
+			"peer_reviews_360":           {Type: "bool", Description: "Open member-to-member peer reviews at party disband for members on dependent nodes", Default: false, Category: "advanced"},
 		},
 		Required: []string{"org", "platform", "board"},
 	}
@@ -306,9 +348,10 @@ func (c *Component) Initialize() error {
 
 // Start begins component operation with the given context. It:
 //  1. Ensures the graph bucket (ENTITY_STATES) is open
-//  2. Launches 3 goroutines: 2 producers (quest KV watcher, AGENT stream review
-//     consumer) + 1 event loop. The quest watcher handles both bootstrap replay
-//     (priming questCache and dagCache from existing entities) and live events.
+//  2. Launches 5 goroutines: 3 producers (quest KV watcher, AGENT stream review
+//     consumer, AGENT stream progress consumer), 1 event loop, and 1 sweep.
+//     The quest watcher handles both bootstrap replay (priming questCache and
+//     dagCache from existing entities) and live events.
 func (c *Component) Start(ctx context.Context) error {
 	if c.running.Load() {
 		return errors.New("component already running")
@@ -357,10 +400,11 @@ func (c *Component) Start(ctx context.Context) error {
 	// Launch producers, event loop, and sweep goroutine. produceQuestEvents
 	// handles both bootstrap and live events — DAG detection and sub-quest
 	// status transitions in one pass.
-	c.wg.Add(4)
+	c.wg.Add(5)
 	go c.runEventLoop(ctx)
 	go c.produceQuestEvents(ctx, c.events)
 	go c.produceReviewEvents(ctx, c.events)
+	go c.produceProgressEvents(ctx, c.events)
 	go c.sweepStaleDags(ctx)
 
 	c.logger.Info("questdagexec component started",
@@ -47,20 +47,64 @@ type Config struct {
 	// QuestLoopsBucket is the NATS KV bucket name for quest-loop mappings.
 	// Review and clarify loops are written here so questbridge can track them.
 	QuestLoopsBucket string `json:"quest_loops_bucket"`
+
+	// MaxAmendments caps the amend_dag proposals a lead may make per DAG
+	// execution. Zero disables amend_dag.
+	MaxAmendments int `json:"max_amendments"`
+
+	// MaxTotalDAGNodes caps the sub-quests posted across every nesting level
+	// of one top-level party quest, including nodes added by amendments.
+	// Set it to questbridge's max_total_dag_nodes. 0 uses
+	// DefaultMaxTotalDAGNodes.
+	MaxTotalDAGNodes int `json:"max_total_dag_nodes,omitempty"`
+
+	// RequireAmendmentApproval routes amend_dag proposals through the DM as
+	// quest_decomposition approvals before they are applied. Amendments are
+	// applied directly when the dmapproval component is not running.
+	RequireAmendmentApproval bool `json:"require_amendment_approval"`
+
+	// ApprovalSessionID is the DM session used for amendment approvals.
+	ApprovalSessionID string `json:"approval_session_id,omitempty"`
+
+	// StallTimeout is how long an assigned or in-progress node may go without
+	// sub-quest progress before its assignee is swapped for a replacement.
+	// Zero disables stall detection.
+	StallTimeout time.Duration `json:"stall_timeout"`
+
+	// ClarificationTimeout is how long a node may sit in awaiting_clarification
+	// before its assignee is swapped. Zero disables the check.
+	ClarificationTimeout time.Duration `json:"clarification_timeout"`
+
+	// SwapAfterFailures hands a node to a replacement once its assignee's
+	// loop has failed this many times in a row. Zero disables the check.
+	SwapAfterFailures int `json:"swap_after_failures"`
+
+	// AttachDAGDiagram stores a Mermaid rendering of the executed DAG, with
+	// node states, spend and the critical path, on the parent quest as
+	// quest.dag.diagram when the DAG rolls up.
+	AttachDAGDiagram bool `json:"attach_dag_diagram"`
+
+	// PeerReviews360 opens pending member-to-member peer reviews at party
+	// disband for every pair of members who worked on dependent nodes.
+	PeerReviews360 bool `json:"peer_reviews_360"`
 }
 
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() Config {
 	return Config{
-		Org:                 "default",
-		Platform:            "local",
-		Board:               "main",
-		DAGTimeout:          30 * time.Minute,
-		RecruitmentTimeout:  5 * time.Minute,
-		RecruitmentInterval: 30 * time.Second,
-		MaxRetriesPerNode:   2,
-		StreamName:          "AGENT",
-		QuestLoopsBucket:    "QUEST_LOOPS",
+		Org:                  "default",
+		Platform:             "local",
+		Board:                "main",
+		DAGTimeout:           30 * time.Minute,
+		RecruitmentTimeout:   5 * time.Minute,
+		RecruitmentInterval:  30 * time.Second,
+		MaxRetriesPerNode:    2,
+		StreamName:           "AGENT",
+		QuestLoopsBucket:     "QUEST_LOOPS",
+		MaxAmendments:        DefaultMaxAmendments,
+		StallTimeout:         10 * time.Minute,
+		ClarificationTimeout: 10 * time.Minute,
+		SwapAfterFailures:    2,
 	}
 }
 
@@ -96,6 +140,21 @@ func (c *Config) Validate() error {
 	if c.MaxRetriesPerNode < 0 {
 		return errors.New("max_retries_per_node must be non-negative")
 	}
+	if c.MaxAmendments < 0 {
+		return errors.New("max_amendments must be non-negative")
+	}
+	if c.MaxTotalDAGNodes < 0 {
+		return errors.New("max_total_dag_nodes must be non-negative")
+	}
+	if c.StallTimeout < 0 {
+		return errors.New("stall_timeout must be non-negative")
+	}
+	if c.ClarificationTimeout < 0 {
+		return errors.New("clarification_timeout must be non-negative")
+	}
+	if c.SwapAfterFailures < 0 {
+		return errors.New("swap_after_failures must be non-negative")
+	}
 	if c.StreamName == "" {
 		return errors.New("stream_name is required")
 	}
@@ -0,0 +1,355 @@
+package questdagexec
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// =============================================================================
+// OUTPUT CONTRACTS
+// =============================================================================
+// A QuestNode may declare the shape of its output, either inline as a JSON
+// Schema (OutputSchema) or by naming a built-in contract (OutputContract).
+// submit_work validates the member's structured output against the schema
+// before the loop ends, so malformed output bounces straight back to the
+// member instead of spending a lead review. Downstream nodes then receive the
+// validated fields in their dependency context.
+//
+// The validator implements the subset of JSON Schema that LLM-authored
+// contracts actually use: type, properties, required, items, enum, minItems,
+// maxItems, minLength and additionalProperties (boolean only).
+// =============================================================================
+
+// outputContracts holds the built-in named contracts. Nodes reference them
+// through QuestNode.OutputContract so leads do not have to write a schema for
+// the common pipeline shapes.
+var outputContracts = map[string]map[string]any{
+	// api_list: an extraction step listing the APIs a later step fans out over.
+	"api_list": {
+		"type":     "object",
+		"required": []any{"apis"},
+		"properties": map[string]any{
+			"apis": map[string]any{
+				"type":     "array",
+				"minItems": float64(1),
+				"items": map[string]any{
+					"type":     "object",
+					"required": []any{"name"},
+					"properties": map[string]any{
+						"name":        map[string]any{"type": "string", "minLength": float64(1)},
+						"description": map[string]any{"type": "string"},
+						"base_url":    map[string]any{"type": "string"},
+						"endpoints":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					},
+				},
+			},
+		},
+	},
+	// file_list: the files a node created or modified.
+	"file_list": {
+		"type":     "object",
+		"required": []any{"files"},
+		"properties": map[string]any{
+			"files": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string", "minLength": float64(1)},
+			},
+		},
+	},
+	// findings: research or analysis results with a one-line summary.
+	"findings": {
+		"type":     "object",
+		"required": []any{"summary", "findings"},
+		"properties": map[string]any{
+			"summary": map[string]any{"type": "string", "minLength": float64(1)},
+			"findings": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+		},
+	},
+}
+
+// OutputContractNames returns the built-in contract names in sorted order.
+func OutputContractNames() []string {
+	names := make([]string, 0, len(outputContracts))
+	for name := range outputContracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveOutputSchema returns the JSON Schema a node's output must satisfy,
+// or nil when the node declares no contract. An inline OutputSchema takes
+// precedence over a named OutputContract.
+func (n QuestNode) ResolveOutputSchema() (map[string]any, error) {
+	if len(n.OutputSchema) > 0 {
+		return n.OutputSchema, nil
+	}
+	if n.OutputContract == "" {
+		return nil, nil
+	}
+	schema, ok := outputContracts[n.OutputContract]
+	if !ok {
+		return nil, fmt.Errorf("unknown output contract %q (known: %s)",
+			n.OutputContract, strings.Join(OutputContractNames(), ", "))
+	}
+	return schema, nil
+}
+
+// SubQuestOutputSchema returns the node's resolved schema for the sub-quest
+// entity's DAGOutputSchema field. It returns an untyped nil when the node has
+// no contract so the quest.dag.output_schema triple is omitted.
+func (n QuestNode) SubQuestOutputSchema() any {
+	schema, err := n.ResolveOutputSchema()
+	if err != nil || schema == nil {
+		return nil
+	}
+	return schema
+}
+
+// OutputSchemaFromAny converts a schema stored as any on a quest entity back
+// into a map. Entity fields round-trip through JSON, so the value may arrive
+// as map[string]any or as an equivalent JSON-decoded structure. Returns nil
+// when v holds no schema.
+func OutputSchemaFromAny(v any) map[string]any {
+	if v == nil {
+		return nil
+	}
+	if m, ok := v.(map[string]any); ok {
+		return m
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var schema map[string]any
+	if json.Unmarshal(data, &schema) != nil {
+		return nil
+	}
+	return schema
+}
+
+// checkSchema verifies that a schema only uses keywords the validator
+// understands with values of the right kind, so a lead's typo is caught at
+// decomposition time rather than when a member submits.
+func checkSchema(schema map[string]any, path string) error {
+	if t, exists := schema["type"]; exists {
+		name, ok := t.(string)
+		if !ok || !knownSchemaType(name) {
+			return fmt.Errorf("%s: unsupported type %v", path, t)
+		}
+	}
+	if req, exists := schema["required"]; exists {
+		if _, err := schemaStrings(req); err != nil {
+			return fmt.Errorf("%s.required: %w", path, err)
+		}
+	}
+	if props, exists := schema["properties"]; exists {
+		pm, ok := props.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s.properties must be an object", path)
+		}
+		for name, sub := range pm {
+			sm, ok := sub.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%s.properties.%s must be an object", path, name)
+			}
+			if err := checkSchema(sm, path+".properties."+name); err != nil {
+				return err
+			}
+		}
+	}
+	if items, exists := schema["items"]; exists {
+		im, ok := items.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s.items must be an object", path)
+		}
+		if err := checkSchema(im, path+".items"); err != nil {
+			return err
+		}
+	}
+	if enum, exists := schema["enum"]; exists {
+		if _, ok := enum.([]any); !ok {
+			return fmt.Errorf("%s.enum must be an array", path)
+		}
+	}
+	return nil
+}
+
+// ValidateOutput checks value against schema and returns every violation as
+// a human-readable message prefixed with its JSON path ("$.apis[0].name").
+// An empty result means the value conforms. value is expected to be
+// JSON-decoded data (map[string]any, []any, float64, string, bool, nil).
+func ValidateOutput(schema map[string]any, value any) []string {
+	var errs []string
+	validateValue(schema, value, "$", &errs)
+	return errs
+}
+
+func validateValue(schema map[string]any, value any, path string, errs *[]string) {
+	if t, ok := schema["type"].(string); ok && !matchesType(t, value) {
+		*errs = append(*errs, fmt.Sprintf("%s: expected %s, got %s", path, t, jsonTypeName(value)))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !enumContains(enum, value) {
+		*errs = append(*errs, fmt.Sprintf("%s: must be one of %s", path, formatEnum(enum)))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		validateObject(schema, v, path, errs)
+	case []any:
+		if minItems, ok := schemaInt(schema["minItems"]); ok && len(v) < minItems {
+			*errs = append(*errs, fmt.Sprintf("%s: must have at least %d items, got %d", path, minItems, len(v)))
+		}
+		if maxItems, ok := schemaInt(schema["maxItems"]); ok && len(v) > maxItems {
+			*errs = append(*errs, fmt.Sprintf("%s: must have at most %d items, got %d", path, maxItems, len(v)))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				validateValue(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case string:
+		if minLength, ok := schemaInt(schema["minLength"]); ok && len(v) < minLength {
+			*errs = append(*errs, fmt.Sprintf("%s: must be at least %d characters", path, minLength))
+		}
+	}
+}
+
+func validateObject(schema, obj map[string]any, path string, errs *[]string) {
+	required, _ := schemaStrings(schema["required"])
+	for _, name := range required {
+		if _, exists := obj[name]; !exists {
+			*errs = append(*errs, fmt.Sprintf("%s: missing required field %q", path, name))
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]any)
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		sub, declared := props[name].(map[string]any)
+		if !declared {
+			if allowed, ok := schema["additionalProperties"].(bool); ok && !allowed {
+				*errs = append(*errs, fmt.Sprintf("%s: unexpected field %q", path, name))
+			}
+			continue
+		}
+		validateValue(sub, obj[name], path+"."+name, errs)
+	}
+}
+
+func knownSchemaType(t string) bool {
+	switch t {
+	case "object", "array", "string", "number", "integer", "boolean", "null":
+		return true
+	}
+	return false
+}
+
+func matchesType(t string, value any) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func enumContains(enum []any, value any) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(enum []any) string {
+	data, err := json.Marshal(enum)
+	if err != nil {
+		return fmt.Sprintf("%v", enum)
+	}
+	return string(data)
+}
+
+// schemaInt reads a numeric schema keyword. JSON numbers decode as float64;
+// built-in contracts use float64 literals for the same reason.
+func schemaInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	}
+	return 0, false
+}
+
+// schemaStrings reads a string-array keyword such as "required", accepting
+// both JSON-decoded []any and Go-literal []string.
+func schemaStrings(v any) ([]string, error) {
+	switch s := v.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return s, nil
+	case []any:
+		out := make([]string, 0, len(s))
+		for i, item := range s {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("[%d] must be a string, got %T", i, item)
+			}
+			out = append(out, str)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("must be an array, got %T", v)
+}
@@ -0,0 +1,189 @@
+package questdagexec
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateOutput(t *testing.T) {
+	t.Parallel()
+
+	schema := outputContracts["api_list"]
+
+	tests := []struct {
+		name     string
+		output   string
+		wantErrs []string
+	}{
+		{
+			name:   "conforming output",
+			output: `{"apis":[{"name":"billing","endpoints":["GET /invoices"]},{"name":"users"}]}`,
+		},
+		{
+			name:     "missing required field",
+			output:   `{"services":[]}`,
+			wantErrs: []string{`$: missing required field "apis"`},
+		},
+		{
+			name:     "wrong top-level type",
+			output:   `["billing"]`,
+			wantErrs: []string{"$: expected object, got array"},
+		},
+		{
+			name:     "too few items",
+			output:   `{"apis":[]}`,
+			wantErrs: []string{"$.apis: must have at least 1 items, got 0"},
+		},
+		{
+			name:   "errors are reported per path",
+			output: `{"apis":[{"name":""},{"description":"no name"},{"name":"x","endpoints":[1]}]}`,
+			wantErrs: []string{
+				"$.apis[0].name: must be at least 1 characters",
+				`$.apis[1]: missing required field "name"`,
+				"$.apis[2].endpoints[0]: expected string, got number",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var value any
+			if err := json.Unmarshal([]byte(tt.output), &value); err != nil {
+				t.Fatalf("bad test input: %v", err)
+			}
+			errs := ValidateOutput(schema, value)
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("ValidateOutput() = %q, want %q", errs, tt.wantErrs)
+			}
+			for i := range errs {
+				if errs[i] != tt.wantErrs[i] {
+					t.Errorf("error[%d] = %q, want %q", i, errs[i], tt.wantErrs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateOutput_Keywords(t *testing.T) {
+	t.Parallel()
+
+	// Schemas arrive JSON-decoded from the lead's tool call, so build them
+	// the same way.
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"lang":  {"type": "string", "enum": ["go", "python"]},
+			"count": {"type": "integer"},
+			"tags":  {"type": "array", "maxItems": 2, "items": {"type": "string"}}
+		}
+	}`), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	var value any
+	_ = json.Unmarshal([]byte(`{"lang":"rust","count":1.5,"tags":["a","b","c"],"extra":true}`), &value)
+
+	errs := strings.Join(ValidateOutput(schema, value), "\n")
+	for _, want := range []string{
+		"$.lang: must be one of",
+		"$.count: expected integer, got number",
+		"$.tags: must have at most 2 items",
+		`$: unexpected field "extra"`,
+	} {
+		if !strings.Contains(errs, want) {
+			t.Errorf("errors missing %q:\n%s", want, errs)
+		}
+	}
+}
+
+func TestResolveOutputSchema(t *testing.T) {
+	t.Parallel()
+
+	if schema, err := (QuestNode{ID: "a"}).ResolveOutputSchema(); schema != nil || err != nil {
+		t.Errorf("no contract: got (%v, %v), want (nil, nil)", schema, err)
+	}
+	if (QuestNode{ID: "a"}).SubQuestOutputSchema() != nil {
+		t.Error("SubQuestOutputSchema() without a contract must be an untyped nil")
+	}
+
+	named, err := (QuestNode{ID: "a", OutputContract: "file_list"}).ResolveOutputSchema()
+	if err != nil || named["required"] == nil {
+		t.Errorf("named contract: got (%v, %v)", named, err)
+	}
+
+	inline := map[string]any{"type": "object"}
+	got, _ := (QuestNode{ID: "a", OutputSchema: inline, OutputContract: "file_list"}).ResolveOutputSchema()
+	if len(got) != 1 {
+		t.Errorf("inline schema should take precedence, got %v", got)
+	}
+
+	if _, err := (QuestNode{ID: "a", OutputContract: "nope"}).ResolveOutputSchema(); err == nil ||
+		!strings.Contains(err.Error(), "api_list") {
+		t.Errorf("unknown contract error = %v, want list of known contracts", err)
+	}
+}
+
+func TestQuestDAGValidate_OutputContracts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		node    QuestNode
+		wantErr string
+	}{
+		{name: "named contract", node: QuestNode{ID: "a", Objective: "A", OutputContract: "api_list"}},
+		{name: "inline schema", node: QuestNode{ID: "a", Objective: "A", OutputSchema: map[string]any{
+			"type": "object", "properties": map[string]any{"n": map[string]any{"type": "integer"}},
+		}}},
+		{name: "unknown contract", node: QuestNode{ID: "a", Objective: "A", OutputContract: "nope"}, wantErr: "unknown output contract"},
+		{name: "unsupported type", node: QuestNode{ID: "a", Objective: "A", OutputSchema: map[string]any{
+			"type": "object", "properties": map[string]any{"n": map[string]any{"type": "int"}},
+		}}, wantErr: "output_schema.properties.n: unsupported type int"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dag := QuestDAG{Nodes: []QuestNode{tt.node}}
+			err := dag.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseQuestNodes_OutputContract(t *testing.T) {
+	t.Parallel()
+
+	dag, err := parseQuestNodes([]any{
+		map[string]any{"id": "extract", "objective": "List APIs", "output_contract": "api_list"},
+		map[string]any{"id": "gen", "objective": "Generate clients", "depends_on": []any{"extract"},
+			"output_schema": map[string]any{"type": "object"}},
+	})
+	if err != nil {
+		t.Fatalf("parseQuestNodes() error: %v", err)
+	}
+	if dag.Nodes[0].OutputContract != "api_list" {
+		t.Errorf("OutputContract = %q, want api_list", dag.Nodes[0].OutputContract)
+	}
+	if dag.Nodes[1].OutputSchema["type"] != "object" {
+		t.Errorf("OutputSchema = %v, want inline schema", dag.Nodes[1].OutputSchema)
+	}
+
+	if _, err := parseQuestNodes([]any{
+		map[string]any{"id": "a", "objective": "A", "output_schema": "object"},
+	}); err == nil || !strings.Contains(err.Error(), "output_schema must be an object") {
+		t.Errorf("string output_schema error = %v", err)
+	}
+}
@@ -16,35 +16,72 @@ const decomposeToolName = "decompose_quest"
 // DAG as JSON in ToolResult.Content. No sub-quests are posted here — that
 // happens in questbridge when the lead's agentic loop completes.
 //
+// When the lead names a blueprint without nodes, the executor instantiates
+// the blueprint from its BlueprintSource instead.
+//
 // All public methods are safe for concurrent use — the struct holds no mutable
 // state.
-type DecomposeExecutor struct{}
+type DecomposeExecutor struct {
+	blueprints BlueprintSource
+}
 
 // NewDecomposeExecutor constructs a DecomposeExecutor.
 func NewDecomposeExecutor() *DecomposeExecutor {
 	return &DecomposeExecutor{}
 }
 
+// WithBlueprints returns a copy of the executor that resolves blueprints from
+// src. A nil src disables blueprint instantiation.
+func (e *DecomposeExecutor) WithBlueprints(src BlueprintSource) *DecomposeExecutor {
+	return &DecomposeExecutor{blueprints: src}
+}
+
 // Execute validates the QuestDAG provided by the LLM and returns it as JSON.
 //
 // Argument validation errors are surfaced as non-nil ToolResult.Error strings
 // rather than Go errors. Go errors are reserved for infrastructure failures
 // that the dispatcher should treat as fatal — none arise in this passthrough
 // implementation.
-func (e *DecomposeExecutor) Execute(_ context.Context, call agentic.ToolCall) (agentic.ToolResult, error) {
+func (e *DecomposeExecutor) Execute(ctx context.Context, call agentic.ToolCall) (agentic.ToolResult, error) {
 	goal, ok := stringArg(call.Arguments, "goal")
 	if !ok || goal == "" {
 		return decomposeErrorResult(call, `missing required argument "goal"`), nil
 	}
 
-	rawNodes, ok := call.Arguments["nodes"]
-	if !ok {
+	blueprintID, _ := stringArg(call.Arguments, "blueprint")
+	rawNodes, hasNodes := call.Arguments["nodes"]
+	if !hasNodes && blueprintID == "" {
 		return decomposeErrorResult(call, `missing required argument "nodes"`), nil
 	}
 
-	dag, err := parseQuestNodes(rawNodes)
-	if err != nil {
-		return decomposeErrorResult(call, fmt.Sprintf("invalid nodes argument: %s", err)), nil
+	var dag QuestDAG
+	if hasNodes {
+		parsed, err := parseQuestNodes(rawNodes)
+		if err != nil {
+			return decomposeErrorResult(call, fmt.Sprintf("invalid nodes argument: %s", err)), nil
+		}
+		dag = parsed
+	}
+
+	if blueprintID != "" && e.blueprints != nil {
+		bp, err := e.blueprints.Get(ctx, blueprintID)
+		if err != nil {
+			return decomposeErrorResult(call, fmt.Sprintf("invalid blueprint: %s", err)), nil
+		}
+		if !hasNodes {
+			args, err := parseBlueprintArgs(call.Arguments["blueprint_args"])
+			if err != nil {
+				return decomposeErrorResult(call, fmt.Sprintf("invalid blueprint_args: %s", err)), nil
+			}
+			if args[BlueprintGoalParam] == "" {
+				args[BlueprintGoalParam] = goal
+			}
+			if dag, err = bp.Instantiate(args); err != nil {
+				return decomposeErrorResult(call, err.Error()), nil
+			}
+		}
+	} else if !hasNodes {
+		return decomposeErrorResult(call, "blueprint library unavailable — provide nodes"), nil
 	}
 
 	if err := dag.Validate(); err != nil {
@@ -55,6 +92,9 @@ func (e *DecomposeExecutor) Execute(_ context.Context, call agentic.ToolCall) (a
 		"goal": goal,
 		"dag":  dag,
 	}
+	if blueprintID != "" {
+		response["blueprint"] = blueprintID
+	}
 
 	return decomposeJSONResult(call, response)
 }
@@ -63,15 +103,24 @@ func (e *DecomposeExecutor) Execute(_ context.Context, call agentic.ToolCall) (a
 func (e *DecomposeExecutor) ListTools() []agentic.ToolDefinition {
 	return []agentic.ToolDefinition{{
 		Name:        decomposeToolName,
-		Description: "Decompose a complex party quest into a DAG of sub-quests. Provide the goal and a list of quest nodes with their dependencies. The validated DAG is returned for execution by the party.",
+		Description: "Decompose a complex party quest into a DAG of sub-quests. Provide the goal and a list of quest nodes with their dependencies, or name a blueprint to use it as-is. The validated DAG is returned for execution by the party.",
 		Parameters: map[string]any{
 			"type":     "object",
-			"required": []string{"goal", "nodes"},
+			"required": []string{"goal"},
 			"properties": map[string]any{
 				"goal": map[string]any{
 					"type":        "string",
 					"description": "High-level decomposition rationale for the party quest",
 				},
+				"blueprint": map[string]any{
+					"type":        "string",
+					"description": "ID of the blueprint this DAG is based on. Without nodes, the blueprint is instantiated as-is; with nodes, your adapted nodes are used and the blueprint is credited with the outcome.",
+				},
+				"blueprint_args": map[string]any{
+					"type":                 "object",
+					"description":          "Values for the blueprint's parameters when instantiating it without nodes. goal defaults to the goal argument.",
+					"additionalProperties": map[string]any{"type": "string"},
+				},
 				"nodes": map[string]any{
 					"type":        "array",
 					"description": "Sub-quest nodes forming the DAG",
@@ -106,6 +155,21 @@ func (e *DecomposeExecutor) ListTools() []agentic.ToolDefinition {
 								"description": "IDs of nodes that must complete before this one",
 								"items":       map[string]any{"type": "string"},
 							},
+							"decomposable": map[string]any{
+								"type":        "boolean",
+								"description": "Allow a Master-tier assignee to decompose this node into its own nested DAG with a sub-party. Use only for large nodes that need further structure.",
+							},
+							"output_schema": map[string]any{
+								"type":        "object",
+								"description": "Optional JSON Schema (type, properties, required, items, enum, minItems) the member's structured output must match. Use it when downstream nodes consume this node's result.",
+							},
+							"output_contract": map[string]any{
+								"type":        "string",
+								"description": "Optional named output contract used instead of output_schema",
+								"enum":        OutputContractNames(),
+							},
+							"condition": conditionSchema(),
+							"fan_out":   fanOutSchema(),
 						},
 					},
 				},
@@ -116,6 +180,31 @@ func (e *DecomposeExecutor) ListTools() []agentic.ToolDefinition {
 
 // -- helpers --
 
+// parseBlueprintArgs converts the raw "blueprint_args" argument into a map of
+// parameter values. Non-string scalars are formatted, since models sometimes
+// send numbers unquoted.
+func parseBlueprintArgs(raw any) (map[string]string, error) {
+	args := make(map[string]string)
+	if raw == nil {
+		return args, nil
+	}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("must be an object, got %T", raw)
+	}
+	for name, v := range m {
+		switch val := v.(type) {
+		case string:
+			args[name] = val
+		case float64, bool:
+			args[name] = fmt.Sprint(val)
+		default:
+			return nil, fmt.Errorf("%s must be a string, got %T", name, v)
+		}
+	}
+	return args, nil
+}
+
 // parseQuestNodes converts the raw "nodes" argument (a []any from JSON
 // unmarshalling into map[string]any) into a QuestDAG.
 // Each element must be a map[string]any with at least "id" and "objective".
@@ -197,19 +286,117 @@ func parseQuestNodes(raw any) (QuestDAG, error) {
 			}
 		}
 
+		decomposable, _ := m["decomposable"].(bool)
+
+		var outputSchema map[string]any
+		if rawSchema, exists := m["output_schema"]; exists && rawSchema != nil {
+			outputSchema, ok = rawSchema.(map[string]any)
+			if !ok {
+				return QuestDAG{}, fmt.Errorf("nodes[%d]: output_schema must be an object, got %T", i, rawSchema)
+			}
+		}
+		outputContract, _ := stringField(m, "output_contract")
+
+		condition, err := parseNodeCondition(m["condition"])
+		if err != nil {
+			return QuestDAG{}, fmt.Errorf("nodes[%d]: %w", i, err)
+		}
+		fanOut, err := parseFanOut(m["fan_out"])
+		if err != nil {
+			return QuestDAG{}, fmt.Errorf("nodes[%d]: %w", i, err)
+		}
+
 		nodes = append(nodes, QuestNode{
-			ID:         id,
-			Objective:  objective,
-			Skills:     skills,
-			Difficulty: difficulty,
-			Acceptance: acceptance,
-			DependsOn:  dependsOn,
+			ID:             id,
+			Objective:      objective,
+			Skills:         skills,
+			Difficulty:     difficulty,
+			Acceptance:     acceptance,
+			DependsOn:      dependsOn,
+			Decomposable:   decomposable,
+			OutputSchema:   outputSchema,
+			OutputContract: outputContract,
+			Condition:      condition,
+			FanOut:         fanOut,
 		})
 	}
 
 	return QuestDAG{Nodes: nodes}, nil
 }
 
+// conditionSchema is the JSON Schema of a node's "condition" property.
+func conditionSchema() map[string]any {
+	return map[string]any{
+		"type":        "object",
+		"description": "Run this node only if a predicate holds over an upstream node's structured output; otherwise it is skipped (not failed). The upstream node must be in depends_on and should declare an output contract.",
+		"required":    []string{"node", "op"},
+		"properties": map[string]any{
+			"node":  map[string]any{"type": "string", "description": "Upstream node ID"},
+			"field": map[string]any{"type": "string", "description": "Dot path into its output, e.g. tests.failed (empty = whole output)"},
+			"op": map[string]any{
+				"type": "string",
+				"enum": []string{ConditionEquals, ConditionNotEquals, ConditionExists, ConditionNotExists, ConditionEmpty, ConditionNotEmpty},
+			},
+			"value": map[string]any{"description": "Compared by equals and not_equals"},
+		},
+	}
+}
+
+// fanOutSchema is the JSON Schema of a node's "fan_out" property.
+func fanOutSchema() map[string]any {
+	return map[string]any{
+		"type":        "object",
+		"description": "Expand this node at runtime into one parallel sub-quest per item of an upstream output list. Nodes that depend on it run after all instances and receive every instance's output.",
+		"required":    []string{"from"},
+		"properties": map[string]any{
+			"from":      map[string]any{"type": "string", "description": "Upstream node ID whose output holds the list (must be in depends_on)"},
+			"field":     map[string]any{"type": "string", "description": "Dot path to the list in its output, e.g. apis (empty = output is the list)"},
+			"max_width": map[string]any{"type": "integer", "description": fmt.Sprintf("Maximum instances (default %d, max %d)", DefaultFanOutWidth, maxFanOutWidth)},
+		},
+	}
+}
+
+// parseNodeCondition converts a node's raw "condition" field. Returns nil
+// when absent.
+func parseNodeCondition(raw any) (*NodeCondition, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("condition must be an object, got %T", raw)
+	}
+	node, _ := stringField(m, "node")
+	op, _ := stringField(m, "op")
+	if node == "" || op == "" {
+		return nil, fmt.Errorf(`condition requires "node" and "op"`)
+	}
+	field, _ := stringField(m, "field")
+	return &NodeCondition{Node: node, Field: field, Op: op, Value: m["value"]}, nil
+}
+
+// parseFanOut converts a node's raw "fan_out" field. Returns nil when absent.
+func parseFanOut(raw any) (*FanOutSpec, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("fan_out must be an object, got %T", raw)
+	}
+	from, _ := stringField(m, "from")
+	if from == "" {
+		return nil, fmt.Errorf(`fan_out requires "from"`)
+	}
+	field, _ := stringField(m, "field")
+	spec := &FanOutSpec{From: from, Field: field}
+	// JSON numbers unmarshal as float64 in map[string]any.
+	if w, ok := m["max_width"].(float64); ok {
+		spec.MaxWidth = int(w)
+	}
+	return spec, nil
+}
+
 // decomposeJSONResult marshals v to JSON and returns a successful ToolResult.
 func decomposeJSONResult(call agentic.ToolCall, v any) (agentic.ToolResult, error) {
 	data, err := json.Marshal(v)
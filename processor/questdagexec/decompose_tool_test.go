@@ -264,6 +264,24 @@ func TestDecomposeToolExecute(t *testing.T) {
 	})
 }
 
+func TestParseQuestNodes_Decomposable(t *testing.T) {
+	t.Parallel()
+
+	dag, err := parseQuestNodes([]any{
+		map[string]any{"id": "a", "objective": "Design the storage layer", "decomposable": true},
+		map[string]any{"id": "b", "objective": "Write the README"},
+	})
+	if err != nil {
+		t.Fatalf("parseQuestNodes() error: %v", err)
+	}
+	if !dag.Nodes[0].Decomposable {
+		t.Error("node a should be decomposable")
+	}
+	if dag.Nodes[1].Decomposable {
+		t.Error("node b should not be decomposable")
+	}
+}
+
 func TestDecomposeToolListTools(t *testing.T) {
 	t.Parallel()
 	exec := NewDecomposeExecutor()
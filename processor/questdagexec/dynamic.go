@@ -0,0 +1,235 @@
+package questdagexec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/c360studio/semdragons/domain"
+)
+
+// =============================================================================
+// CONDITIONAL AND FAN-OUT NODES — runtime resolution
+// =============================================================================
+//
+// Conditional and fan-out nodes cannot be dispatched as-is: their shape
+// depends on upstream output that only exists once their dependencies have
+// completed. assignReadyNodes resolves them first, whenever they reach
+// NodeReady:
+//
+//   - A conditional node whose predicate fails is skipped: its sub-quest is
+//     cancelled and it counts as a satisfied dependency.
+//   - A fan-out node is expanded into one instance node (and sub-quest) per
+//     upstream list item. The template's own sub-quest is cancelled and the
+//     template waits in NodeExpanded until every instance has completed.
+//
+// Skipping and completing templates can unblock further nodes, so resolution
+// repeats until nothing changes. A node whose upstream output cannot be read
+// is neither skipped nor expanded: it goes back to NodePending and the stall
+// sweep resolves it again.
+// =============================================================================
+
+// resolveDynamicNodes resolves every ready conditional and fan-out node.
+// A non-nil error means a fan-out could not be expanded; the node has been
+// failed and the caller must escalate.
+//
+// Called only from the event loop goroutine.
+func (c *Component) resolveDynamicNodes(ctx context.Context, dagState *DAGExecutionState) error {
+	passed := make(map[string]bool)
+	deferred := make(map[string]bool)
+	defer func() {
+		// Deferred nodes must not be dispatched unresolved.
+		for id := range deferred {
+			if dagState.NodeStates[id] == NodeReady {
+				dagState.NodeStates[id] = NodePending
+			}
+		}
+	}()
+	for {
+		changed := c.completeExpandedTemplates(dagState)
+
+		// Iterate over a snapshot: expansion appends to DAG.Nodes.
+		nodes := append([]QuestNode(nil), dagState.DAG.Nodes...)
+		for _, node := range nodes {
+			if dagState.NodeStates[node.ID] != NodeReady || passed[node.ID] || deferred[node.ID] {
+				continue
+			}
+			switch {
+			case node.Condition != nil:
+				output, err := c.nodeTypedOutput(ctx, dagState, node.Condition.Node)
+				if err != nil {
+					c.deferDynamicNode(dagState, node.ID, deferred, err)
+					continue
+				}
+				if EvaluateCondition(*node.Condition, output) {
+					passed[node.ID] = true
+					continue
+				}
+				c.skipNode(ctx, dagState, node.ID, fmt.Sprintf("condition on %s not met", node.Condition.Node))
+				changed = true
+
+			case node.FanOut != nil:
+				output, err := c.nodeTypedOutput(ctx, dagState, node.FanOut.From)
+				if err != nil {
+					c.deferDynamicNode(dagState, node.ID, deferred, err)
+					continue
+				}
+				if err := c.expandFanOutNode(ctx, dagState, node, output); err != nil {
+					dagState.NodeStates[node.ID] = NodeFailed
+					dagState.FailedNodes = append(dagState.FailedNodes, node.ID)
+					c.nodesFailed.Add(1)
+					return fmt.Errorf("fan-out node %s failed to expand: %w", node.ID, err)
+				}
+				changed = true
+			}
+		}
+
+		if !changed {
+			return nil
+		}
+		c.promoteReadyNodes(dagState)
+	}
+}
+
+// deferDynamicNode records that a node's upstream output could not be read,
+// so it is resolved again later instead of being skipped on missing data.
+func (c *Component) deferDynamicNode(dagState *DAGExecutionState, nodeID string, deferred map[string]bool, err error) {
+	deferred[nodeID] = true
+	c.logger.Warn("DAG node resolution deferred: upstream output unavailable",
+		"execution_id", dagState.ExecutionID, "node_id", nodeID, "error", err)
+}
+
+// retryDeferredNodes resolves nodes that were deferred because their
+// upstream output could not be read. They sit in NodePending with every
+// dependency satisfied. Reports whether any node was retried. Called only
+// from the event loop goroutine.
+func (c *Component) retryDeferredNodes(ctx context.Context, dagState *DAGExecutionState) bool {
+	if len(DAGReadyNodes(dagState.DAG, dagState.NodeStates)) == 0 {
+		return false
+	}
+	c.promoteReadyNodes(dagState)
+	c.assignReadyNodes(ctx, dagState)
+	return true
+}
+
+// skipNode marks a node skipped and cancels its sub-quest.
+func (c *Component) skipNode(ctx context.Context, dagState *DAGExecutionState, nodeID, reason string) {
+	dagState.NodeStates[nodeID] = NodeSkipped
+	c.cancelNodeSubQuest(ctx, dagState, nodeID, "quest.dag.node_skipped", "Skipped: "+reason)
+	c.logger.Info("DAG node skipped",
+		"execution_id", dagState.ExecutionID, "node_id", nodeID, "reason", reason)
+}
+
+// expandFanOutNode expands a ready fan-out template into instance nodes and
+// posts their sub-quests. output is the upstream node's typed output. An
+// empty upstream list skips the template.
+func (c *Component) expandFanOutNode(ctx context.Context, dagState *DAGExecutionState, node QuestNode, output any) error {
+	items, err := FanOutItems(*node.FanOut, output)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		c.skipNode(ctx, dagState, node.ID, fmt.Sprintf("%s produced no items to fan out over", node.FanOut.From))
+		return nil
+	}
+
+	dag, states, instances, err := ExpandFanOut(dagState.DAG, dagState.NodeStates, node.ID, items)
+	if err != nil {
+		return err
+	}
+	if len(instances) < len(items) {
+		c.logger.Warn("fan-out truncated to its maximum width",
+			"execution_id", dagState.ExecutionID, "node_id", node.ID,
+			"items", len(items), "instances", len(instances))
+	}
+
+	posted, err := c.postNodeSubQuests(ctx, dagState, instances, c.findLeadAgentID(dagState))
+	if err != nil {
+		return err
+	}
+	for i, inst := range instances {
+		dagState.NodeQuestIDs[inst.ID] = string(posted[i].ID)
+		dagState.NodeRetries[inst.ID] = c.config.MaxRetriesPerNode
+	}
+	dagState.DAG = dag
+	dagState.NodeStates = states
+
+	for i, inst := range instances {
+		c.syncAmendedSubQuest(ctx, dagState, inst.ID, &posted[i], "quest.dag.sub_quest_initialized")
+	}
+	c.cancelNodeSubQuest(ctx, dagState, node.ID, "quest.dag.node_expanded",
+		fmt.Sprintf("Expanded into %d parallel sub-quests", len(instances)))
+	c.indexDAGState(dagState)
+
+	c.logger.Info("DAG fan-out node expanded",
+		"execution_id", dagState.ExecutionID, "node_id", node.ID,
+		"instances", len(instances))
+	return nil
+}
+
+// completeExpandedTemplates completes every expanded fan-out template whose
+// instances have all finished, and reports whether any did.
+func (c *Component) completeExpandedTemplates(dagState *DAGExecutionState) bool {
+	changed := false
+	for _, node := range dagState.DAG.Nodes {
+		if dagState.NodeStates[node.ID] != NodeExpanded {
+			continue
+		}
+		done := true
+		for _, id := range dagState.DAG.FanOutInstances(node.ID) {
+			if !dependencySatisfied(dagState.NodeStates[id]) {
+				done = false
+				break
+			}
+		}
+		if !done {
+			continue
+		}
+		dagState.NodeStates[node.ID] = NodeCompleted
+		dagState.CompletedNodes = append(dagState.CompletedNodes, node.ID)
+		changed = true
+		c.logger.Info("DAG fan-out joined: all instances completed",
+			"execution_id", dagState.ExecutionID, "node_id", node.ID)
+	}
+	return changed
+}
+
+// nodeTypedOutput returns a node's contract-validated structured output. For
+// an expanded fan-out it returns the list of its instances' outputs, so a
+// condition or nested fan-out can read a join. Returns nil when the node
+// produced no output, and an error when the output could not be read.
+func (c *Component) nodeTypedOutput(ctx context.Context, dagState *DAGExecutionState, nodeID string) (any, error) {
+	if c.graph == nil {
+		return nil, nil
+	}
+	load := func(id string) (any, error) {
+		subQuestID := dagState.NodeQuestIDs[id]
+		if subQuestID == "" {
+			return nil, nil
+		}
+		entity, err := c.graph.GetQuest(ctx, domain.QuestID(subQuestID))
+		if err != nil {
+			return nil, fmt.Errorf("load output of node %s: %w", id, err)
+		}
+		quest := domain.QuestFromEntityState(entity)
+		if quest == nil {
+			return nil, nil
+		}
+		return quest.DAGTypedOutput, nil
+	}
+
+	instances := dagState.DAG.FanOutInstances(nodeID)
+	if len(instances) == 0 {
+		return load(nodeID)
+	}
+	outputs := make([]any, 0, len(instances))
+	for _, id := range instances {
+		out, err := load(id)
+		if err != nil {
+			return nil, err
+		}
+		if out != nil {
+			outputs = append(outputs, out)
+		}
+	}
+	return outputs, nil
+}
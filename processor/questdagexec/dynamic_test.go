@@ -0,0 +1,339 @@
+package questdagexec
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	semdragons "github.com/c360studio/semdragons"
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semstreams/natsclient"
+)
+
+func decodeJSON(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("bad test input: %v", err)
+	}
+	return v
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	t.Parallel()
+
+	output := decodeJSON(t, `{"tests":{"failed":2,"passed":10},"apis":[{"name":"billing"}],"notes":"","ok":true}`)
+
+	tests := []struct {
+		name   string
+		cond   NodeCondition
+		output any
+		want   bool
+	}{
+		{name: "equals number", cond: NodeCondition{Field: "tests.failed", Op: ConditionEquals, Value: float64(2)}, output: output, want: true},
+		{name: "equals mismatch", cond: NodeCondition{Field: "tests.failed", Op: ConditionEquals, Value: float64(0)}, output: output, want: false},
+		{name: "not_equals", cond: NodeCondition{Field: "ok", Op: ConditionNotEquals, Value: false}, output: output, want: true},
+		{name: "list index", cond: NodeCondition{Field: "apis.0.name", Op: ConditionEquals, Value: "billing"}, output: output, want: true},
+		{name: "exists", cond: NodeCondition{Field: "tests.passed", Op: ConditionExists}, output: output, want: true},
+		{name: "not_exists", cond: NodeCondition{Field: "tests.skipped", Op: ConditionNotExists}, output: output, want: true},
+		{name: "empty string", cond: NodeCondition{Field: "notes", Op: ConditionEmpty}, output: output, want: true},
+		{name: "not_empty list", cond: NodeCondition{Field: "apis", Op: ConditionNotEmpty}, output: output, want: true},
+		{name: "missing output fails not_empty", cond: NodeCondition{Op: ConditionNotEmpty}, output: nil, want: false},
+		{name: "missing output fails not_equals", cond: NodeCondition{Field: "x", Op: ConditionNotEquals, Value: "y"}, output: nil, want: false},
+		{name: "missing output is empty", cond: NodeCondition{Field: "x", Op: ConditionEmpty}, output: nil, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := EvaluateCondition(tt.cond, tt.output); got != tt.want {
+				t.Errorf("EvaluateCondition(%+v) = %v, want %v", tt.cond, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFanOutItems(t *testing.T) {
+	t.Parallel()
+
+	output := decodeJSON(t, `{"apis":[{"name":"billing"},{"name":"users"}],"count":2}`)
+
+	items, err := FanOutItems(FanOutSpec{From: "extract", Field: "apis"}, output)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("FanOutItems() = (%v, %v), want 2 items", items, err)
+	}
+	if _, err := FanOutItems(FanOutSpec{From: "extract", Field: "count"}, output); err == nil ||
+		!strings.Contains(err.Error(), "not a list") {
+		t.Errorf("non-list field error = %v", err)
+	}
+	if _, err := FanOutItems(FanOutSpec{From: "extract", Field: "apis"}, nil); err == nil ||
+		!strings.Contains(err.Error(), "has no output") {
+		t.Errorf("missing output error = %v", err)
+	}
+}
+
+func TestExpandFanOut(t *testing.T) {
+	t.Parallel()
+
+	dag := QuestDAG{Nodes: []QuestNode{
+		{ID: "extract", Objective: "List APIs"},
+		{ID: "gen", Objective: "Generate a client", DependsOn: []string{"extract"}, Skills: []string{"code_generation"},
+			OutputContract: "file_list", FanOut: &FanOutSpec{From: "extract", Field: "apis", MaxWidth: 2}},
+		{ID: "join", Objective: "Publish clients", DependsOn: []string{"gen"}},
+	}}
+	states := map[string]string{"extract": NodeCompleted, "gen": NodeReady, "join": NodePending}
+	items := []any{"billing", "users", "orders"}
+
+	expanded, newStates, instances, err := ExpandFanOut(dag, states, "gen", items)
+	if err != nil {
+		t.Fatalf("ExpandFanOut() error: %v", err)
+	}
+
+	// MaxWidth bounds the expansion.
+	if len(instances) != 2 || instances[0].ID != "gen#1" || instances[1].ID != "gen#2" {
+		t.Fatalf("instances = %+v, want gen#1 and gen#2", instances)
+	}
+	inst := instances[1]
+	if inst.ExpandedFrom != "gen" || inst.FanOutItem != "users" || inst.OutputContract != "file_list" ||
+		len(inst.DependsOn) != 1 || inst.DependsOn[0] != "extract" || inst.FanOut != nil {
+		t.Errorf("instance = %+v", inst)
+	}
+	if !strings.Contains(inst.Objective, "Item 2 of 2: users") {
+		t.Errorf("instance objective = %q", inst.Objective)
+	}
+
+	if newStates["gen"] != NodeExpanded || newStates["gen#1"] != NodeReady || newStates["gen#2"] != NodeReady {
+		t.Errorf("states = %v", newStates)
+	}
+	if len(dag.Nodes) != 3 || states["gen"] != NodeReady {
+		t.Error("ExpandFanOut mutated its inputs")
+	}
+	if err := expanded.Validate(); err != nil {
+		t.Errorf("expanded DAG invalid: %v", err)
+	}
+	if got := expanded.DependencyNodes("gen"); len(got) != 2 || got[0] != "gen#1" {
+		t.Errorf("DependencyNodes(gen) = %v, want instances", got)
+	}
+	if got := expanded.DependencyNodes("extract"); len(got) != 1 || got[0] != "extract" {
+		t.Errorf("DependencyNodes(extract) = %v, want itself", got)
+	}
+
+	if _, _, _, err := ExpandFanOut(dag, states, "join", items); err == nil {
+		t.Error("ExpandFanOut() on a non-fan-out node should fail")
+	}
+}
+
+func TestDAGReadyNodes_SkippedDependency(t *testing.T) {
+	t.Parallel()
+
+	dag := QuestDAG{Nodes: []QuestNode{
+		{ID: "a", Objective: "A"},
+		{ID: "b", Objective: "B", DependsOn: []string{"a"}},
+		{ID: "c", Objective: "C", DependsOn: []string{"a", "b"}},
+	}}
+	got := DAGReadyNodes(dag, map[string]string{"a": NodeCompleted, "b": NodeSkipped, "c": NodePending})
+	if len(got) != 1 || got[0] != "c" {
+		t.Errorf("DAGReadyNodes() = %v, want [c]", got)
+	}
+}
+
+func TestQuestDAGValidate_DynamicNodes(t *testing.T) {
+	t.Parallel()
+
+	base := QuestNode{ID: "a", Objective: "A"}
+	tests := []struct {
+		name    string
+		node    QuestNode
+		wantErr string
+	}{
+		{name: "valid condition", node: QuestNode{ID: "b", Objective: "B", DependsOn: []string{"a"},
+			Condition: &NodeCondition{Node: "a", Field: "failed", Op: ConditionNotEmpty}}},
+		{name: "valid fan-out", node: QuestNode{ID: "b", Objective: "B", DependsOn: []string{"a"},
+			FanOut: &FanOutSpec{From: "a", MaxWidth: 3}}},
+		{name: "condition on non-dependency", node: QuestNode{ID: "b", Objective: "B",
+			Condition: &NodeCondition{Node: "a", Op: ConditionExists}}, wantErr: "not a dependency"},
+		{name: "unknown op", node: QuestNode{ID: "b", Objective: "B", DependsOn: []string{"a"},
+			Condition: &NodeCondition{Node: "a", Op: "greater"}}, wantErr: "unknown condition op"},
+		{name: "fan-out width too large", node: QuestNode{ID: "b", Objective: "B", DependsOn: []string{"a"},
+			FanOut: &FanOutSpec{From: "a", MaxWidth: 50}}, wantErr: "max_width"},
+		{name: "both conditional and fan-out", node: QuestNode{ID: "b", Objective: "B", DependsOn: []string{"a"},
+			Condition: &NodeCondition{Node: "a", Op: ConditionExists}, FanOut: &FanOutSpec{From: "a"}},
+			wantErr: "both conditional and fan-out"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dag := QuestDAG{Nodes: []QuestNode{base, tt.node}}
+			err := dag.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseQuestNodes_DynamicNodes(t *testing.T) {
+	t.Parallel()
+
+	dag, err := parseQuestNodes([]any{
+		map[string]any{"id": "test", "objective": "Run tests", "output_contract": "findings"},
+		map[string]any{"id": "fix", "objective": "Fix failures", "depends_on": []any{"test"},
+			"condition": map[string]any{"node": "test", "field": "findings", "op": "not_empty"}},
+		map[string]any{"id": "gen", "objective": "Generate", "depends_on": []any{"test"},
+			"fan_out": map[string]any{"from": "test", "field": "findings", "max_width": float64(3)}},
+	})
+	if err != nil {
+		t.Fatalf("parseQuestNodes() error: %v", err)
+	}
+	if c := dag.Nodes[1].Condition; c == nil || c.Node != "test" || c.Op != ConditionNotEmpty || c.Field != "findings" {
+		t.Errorf("Condition = %+v", c)
+	}
+	if f := dag.Nodes[2].FanOut; f == nil || f.From != "test" || f.MaxWidth != 3 {
+		t.Errorf("FanOut = %+v", f)
+	}
+
+	if _, err := parseQuestNodes([]any{
+		map[string]any{"id": "a", "objective": "A", "condition": "yes"},
+	}); err == nil || !strings.Contains(err.Error(), "nodes[0]") {
+		t.Errorf("string condition error = %v", err)
+	}
+}
+
+// =============================================================================
+// Component-level resolution
+// =============================================================================
+
+func TestResolveDynamicNodes_SkipsFailedCondition(t *testing.T) {
+	t.Parallel()
+
+	c := newTestComponent(nil, nil)
+	boardConfig := domain.DefaultBoardConfig()
+	c.boardConfig = &boardConfig
+	ds := makeFullDAGState("exec-1", "parent-1", "party-1", []QuestNode{
+		{ID: "test", Objective: "Run tests"},
+		{ID: "fix", Objective: "Fix failures", DependsOn: []string{"test"},
+			Condition: &NodeCondition{Node: "test", Field: "failed", Op: ConditionNotEmpty}},
+		{ID: "report", Objective: "Write report", DependsOn: []string{"fix"}},
+	})
+	ds.NodeStates["test"] = NodeCompleted
+	ds.CompletedNodes = []string{"test"}
+	ds.NodeStates["fix"] = NodeReady
+
+	// The test component has no graph, so the upstream output is missing and
+	// the not_empty condition fails.
+	if err := c.resolveDynamicNodes(context.Background(), ds); err != nil {
+		t.Fatalf("resolveDynamicNodes() error: %v", err)
+	}
+	if ds.NodeStates["fix"] != NodeSkipped {
+		t.Errorf("fix state = %q, want skipped", ds.NodeStates["fix"])
+	}
+	if ds.NodeStates["report"] != NodeReady {
+		t.Errorf("report state = %q, want ready after its dependency was skipped", ds.NodeStates["report"])
+	}
+
+	ds.NodeStates["report"] = NodeCompleted
+	if !c.isDAGComplete(ds) {
+		t.Error("isDAGComplete() = false, want true with a skipped node")
+	}
+
+	prompt := buildLeadSynthesisPrompt(ds, map[string]any{"test": "all green"})
+	if !strings.Contains(prompt, "SKIPPED SUB-QUESTS") || !strings.Contains(prompt, "- fix (Fix failures)") {
+		t.Errorf("synthesis prompt missing skipped node:\n%s", prompt)
+	}
+}
+
+func TestResolveDynamicNodes_PassingConditionStaysReady(t *testing.T) {
+	t.Parallel()
+
+	c := newTestComponent(nil, nil)
+	ds := makeFullDAGState("exec-1", "parent-1", "party-1", []QuestNode{
+		{ID: "test", Objective: "Run tests"},
+		{ID: "fix", Objective: "Fix failures", DependsOn: []string{"test"},
+			Condition: &NodeCondition{Node: "test", Field: "failed", Op: ConditionEmpty}},
+	})
+	ds.NodeStates["test"] = NodeCompleted
+	ds.NodeStates["fix"] = NodeReady
+
+	if err := c.resolveDynamicNodes(context.Background(), ds); err != nil {
+		t.Fatalf("resolveDynamicNodes() error: %v", err)
+	}
+	if ds.NodeStates["fix"] != NodeReady {
+		t.Errorf("fix state = %q, want ready", ds.NodeStates["fix"])
+	}
+}
+
+func TestResolveDynamicNodes_UnreadableOutputDefersNode(t *testing.T) {
+	t.Parallel()
+
+	c := newTestComponent(nil, nil)
+	boardConfig := domain.DefaultBoardConfig()
+	c.boardConfig = &boardConfig
+	// A client that never connected: every quest read fails.
+	nats, err := natsclient.NewClient("nats://127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.graph = semdragons.NewGraphClient(nats, &boardConfig)
+	ds := makeFullDAGState("exec-1", "parent-1", "party-1", []QuestNode{
+		{ID: "test", Objective: "Run tests"},
+		{ID: "fix", Objective: "Fix failures", DependsOn: []string{"test"},
+			Condition: &NodeCondition{Node: "test", Field: "failed", Op: ConditionNotEmpty}},
+		{ID: "gen", Objective: "Generate", DependsOn: []string{"test"}, FanOut: &FanOutSpec{From: "test"}},
+	})
+	ds.NodeStates["test"] = NodeCompleted
+	ds.NodeStates["fix"] = NodeReady
+	ds.NodeStates["gen"] = NodeReady
+
+	if err := c.resolveDynamicNodes(context.Background(), ds); err != nil {
+		t.Fatalf("resolveDynamicNodes() error: %v", err)
+	}
+	for _, id := range []string{"fix", "gen"} {
+		if ds.NodeStates[id] != NodePending {
+			t.Errorf("%s state = %q, want pending until its input can be read", id, ds.NodeStates[id])
+		}
+	}
+	if got := DAGReadyNodes(ds.DAG, ds.NodeStates); len(got) != 2 {
+		t.Errorf("DAGReadyNodes = %v, want both deferred nodes for the next sweep", got)
+	}
+}
+
+func TestCompleteExpandedTemplates(t *testing.T) {
+	t.Parallel()
+
+	c := newTestComponent(nil, nil)
+	ds := makeFullDAGState("exec-1", "parent-1", "party-1", []QuestNode{
+		{ID: "extract", Objective: "List APIs"},
+		{ID: "gen", Objective: "Generate", DependsOn: []string{"extract"}, FanOut: &FanOutSpec{From: "extract"}},
+		{ID: "gen#1", Objective: "Generate 1", DependsOn: []string{"extract"}, ExpandedFrom: "gen"},
+		{ID: "gen#2", Objective: "Generate 2", DependsOn: []string{"extract"}, ExpandedFrom: "gen"},
+		{ID: "join", Objective: "Publish", DependsOn: []string{"gen"}},
+	})
+	ds.NodeStates["extract"] = NodeCompleted
+	ds.NodeStates["gen"] = NodeExpanded
+	ds.NodeStates["gen#1"] = NodeCompleted
+	ds.NodeStates["gen#2"] = NodeInProgress
+
+	if c.completeExpandedTemplates(ds) {
+		t.Fatal("template completed while an instance is still in progress")
+	}
+	if c.isDAGComplete(ds) {
+		t.Error("isDAGComplete() = true with an expanded template outstanding")
+	}
+
+	ds.NodeStates["gen#2"] = NodeCompleted
+	if !c.completeExpandedTemplates(ds) || ds.NodeStates["gen"] != NodeCompleted {
+		t.Fatalf("gen state = %q, want completed once all instances finish", ds.NodeStates["gen"])
+	}
+	c.promoteReadyNodes(ds)
+	if ds.NodeStates["join"] != NodeReady {
+		t.Errorf("join state = %q, want ready", ds.NodeStates["join"])
+	}
+}
@@ -19,8 +19,9 @@ import (
 // dagBySubQuest, and questCache. Because all DAG mutations flow through this
 // function sequentially, no mutexes are needed on those plain maps.
 //
-// The two producer goroutines (produceQuestEvents, produceReviewEvents) only
-// read from NATS and write to the c.events channel. They never touch the maps.
+// The producer goroutines (produceQuestEvents, produceReviewEvents,
+// produceProgressEvents) only read from NATS and write to the c.events
+// channel. They never touch the maps.
 //
 // Metrics atomics (nodesCompleted, nodesFailed, etc.) are always safe to write
 // from any goroutine; no additional synchronisation needed.
@@ -70,6 +71,12 @@ func (c *Component) handleEvent(ctx context.Context, evt dagEvent) {
 		c.onClarificationFailed(ctx, evt)
 	case dagEventDAGTimedOut:
 		c.onDAGTimedOut(ctx, evt)
+	case dagEventAmendmentDecided:
+		c.onAmendmentDecided(ctx, evt)
+	case dagEventStallSweep:
+		c.onStallSweep(ctx)
+	case dagEventNodeProgress:
+		c.onNodeProgress(evt)
 	}
 }
 
@@ -192,6 +199,11 @@ func (c *Component) onReviewCompleted(ctx context.Context, evt dagEvent) {
 	c.logger.Info("event loop: processing review completion",
 		"loop_id", evt.LoopID, "result_length", len(evt.Result))
 
+	if am, ok := parseAmendmentResult(evt.Result); ok {
+		c.onAmendmentProposed(ctx, evt, am)
+		return
+	}
+
 	// Parse the verdict JSON from the review_sub_quest tool output.
 	// Fall back to heuristic extraction when the LLM responded with prose.
 	var verdict struct {
@@ -609,6 +621,8 @@ func (c *Component) handleSubQuestTransition(
 	oldStatus, newStatus domain.QuestStatus,
 	entity *graph.EntityState,
 ) {
+	dagState.touchNode(nodeID, time.Now())
+
 	switch newStatus {
 	case domain.QuestInProgress:
 		dagState.NodeStates[nodeID] = NodeInProgress
@@ -71,6 +71,21 @@ const (
 	// dagEventDAGTimedOut is emitted by the sweep goroutine when a DAG exceeds
 	// its configured timeout. The event loop escalates the parent and cleans up.
 	dagEventDAGTimedOut
+
+	// dagEventAmendmentDecided is emitted by an approval goroutine when the DM
+	// approves or denies a lead's amend_dag proposal. The event loop applies
+	// approved amendments and re-dispatches the interrupted lead loop.
+	dagEventAmendmentDecided
+
+	// dagEventStallSweep is emitted by the sweep goroutine on every tick. The
+	// event loop checks active nodes for stalled or departed assignees and
+	// hot-swaps them for replacements.
+	dagEventStallSweep
+
+	// dagEventNodeProgress is emitted when the AGENT stream delivers a tool
+	// result for a sub-quest execution loop. The event loop refreshes the
+	// node's heartbeat so active members are not mistaken for stalled ones.
+	dagEventNodeProgress
 )
 
 // dagEvent carries all data the event loop needs to process one DAG lifecycle
@@ -92,6 +107,14 @@ const (
 // For dagEventReviewCompleted and dagEventClarificationAnswered:
 //   - LoopID is the lead loop ID from LoopCompletedEvent
 //   - Result is the raw result string (JSON envelope or free-form text)
+//
+// For dagEventNodeProgress:
+//   - LoopID is the execution loop that reported a tool result
+//
+// For dagEventAmendmentDecided:
+//   - LoopID is the lead loop that proposed the amendment
+//   - Amendment is the proposal; Approved carries the DM's decision
+//   - ErrorReason explains a denial
 type dagEvent struct {
 	Type dagEventType
 
@@ -111,6 +134,10 @@ type dagEvent struct {
 
 	// Error reason for failure events.
 	ErrorReason string
+
+	// Amendment decision fields.
+	Amendment *DAGAmendment
+	Approved  bool
 }
 
 // =============================================================================
@@ -447,3 +474,122 @@ func classifyCancelledEvent(evt *agentic.LoopCancelledEvent) dagEvent {
 	}
 	return dagEvent{}
 }
+
+// =============================================================================
+// PRODUCER: AGENT STREAM PROGRESS CONSUMER
+// =============================================================================
+
+// progressInterval throttles heartbeat events per execution loop. Stall
+// timeouts are minutes long, so one heartbeat per loop per interval is plenty.
+const progressInterval = 30 * time.Second
+
+// produceProgressEvents subscribes to tool.result.* on the AGENT stream and
+// emits dagEventNodeProgress for results belonging to sub-quest execution
+// loops (LoopID prefixed "quest-"). Heartbeats are best-effort: they are
+// dropped rather than blocking when the event channel is full.
+//
+// Producer constraint: must NOT access dagCache, dagBySubQuest, or questCache.
+// It only reads from NATS and sends events.
+func (c *Component) produceProgressEvents(ctx context.Context, events chan<- dagEvent) {
+	defer c.wg.Done()
+
+	js, err := c.deps.NATSClient.JetStream()
+	if err != nil {
+		c.logger.Error("progress producer: failed to get JetStream", "error", err)
+		c.errorsCount.Add(1)
+		return
+	}
+
+	stream, err := js.Stream(ctx, c.config.StreamName)
+	if err != nil {
+		c.logger.Error("progress producer: failed to get AGENT stream", "error", err)
+		c.errorsCount.Add(1)
+		return
+	}
+
+	consumerName := fmt.Sprintf("questdagexec-progress-%s-%s-%s", c.config.Org, c.config.Platform, c.config.Board)
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		FilterSubjects: []string{"tool.result.*"},
+		DeliverPolicy:  jetstream.DeliverNewPolicy,
+		AckPolicy:      jetstream.AckNonePolicy,
+		Name:           consumerName,
+	})
+	if err != nil {
+		c.logger.Error("progress producer: failed to create consumer", "error", err)
+		c.errorsCount.Add(1)
+		return
+	}
+
+	// lastSent is local to this goroutine.
+	lastSent := make(map[string]time.Time)
+
+	for {
+		select {
+		case <-c.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		msgs, fetchErr := consumer.Fetch(32, jetstream.FetchMaxWait(2*time.Second))
+		if fetchErr != nil {
+			select {
+			case <-time.After(500 * time.Millisecond):
+			case <-c.stopChan:
+				return
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		now := time.Now()
+		for msg := range msgs.Messages() {
+			loopID := parseToolResultLoopID(c, msg)
+			if !strings.HasPrefix(loopID, "quest-") {
+				continue
+			}
+			if last, ok := lastSent[loopID]; ok && now.Sub(last) < progressInterval {
+				continue
+			}
+			select {
+			case events <- dagEvent{Type: dagEventNodeProgress, LoopID: loopID}:
+				lastSent[loopID] = now
+			default:
+			}
+		}
+
+		// Forget loops that have gone quiet so the map stays bounded.
+		for loopID, last := range lastSent {
+			if now.Sub(last) > 10*progressInterval {
+				delete(lastSent, loopID)
+			}
+		}
+	}
+}
+
+// parseToolResultLoopID returns the loop ID of a tool result message, or ""
+// when the message cannot be parsed.
+//
+// Takes *Component only for the decoder — must not access any state maps.
+func parseToolResultLoopID(c *Component, msg jetstream.Msg) string {
+	baseMsg, err := c.decoder.Decode(msg.Data())
+	if err != nil {
+		return ""
+	}
+	if result, ok := baseMsg.Payload().(*agentic.ToolResult); ok {
+		return result.LoopID
+	}
+
+	// Re-marshal when the decoder returned a different concrete type.
+	payloadBytes, err := json.Marshal(baseMsg.Payload())
+	if err != nil {
+		return ""
+	}
+	var result agentic.ToolResult
+	if json.Unmarshal(payloadBytes, &result) != nil {
+		return ""
+	}
+	return result.LoopID
+}
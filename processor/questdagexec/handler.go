@@ -9,7 +9,9 @@ import (
 	"strings"
 	"time"
 
+	semdragons "github.com/c360studio/semdragons"
 	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
 	"github.com/c360studio/semdragons/processor/partycoord"
 	"github.com/c360studio/semstreams/agentic"
 	"github.com/c360studio/semstreams/graph"
@@ -172,6 +174,73 @@ func (c *Component) createAcceptReviewEntity(ctx context.Context, dagState *DAGE
 		"member_id", memberID)
 }
 
+// collaboratorPairs returns each pair of members who worked on a node and
+// one of its dependencies, excluding the lead. Pairs are unordered, listed
+// once, with the lexicographically smaller agent ID first.
+func collaboratorPairs(dagState *DAGExecutionState, leaderID domain.AgentID) [][2]domain.AgentID {
+	seen := make(map[[2]domain.AgentID]bool)
+	var pairs [][2]domain.AgentID
+	for _, node := range dagState.DAG.Nodes {
+		a := domain.AgentID(dagState.NodeAssignees[node.ID])
+		for _, dep := range node.DependsOn {
+			b := domain.AgentID(dagState.NodeAssignees[dep])
+			if a == "" || b == "" || a == b || a == leaderID || b == leaderID {
+				continue
+			}
+			pair := [2]domain.AgentID{min(a, b), max(a, b)}
+			if !seen[pair] {
+				seen[pair] = true
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	return pairs
+}
+
+// createPeerReviews360 opens a pending member-to-member review for every
+// pair of members who collaborated on dependent nodes. Called at party
+// disband when peer_reviews_360 is enabled; agentprogression aggregates the
+// party's reviews once all of them are submitted.
+func (c *Component) createPeerReviews360(ctx context.Context, dagState *DAGExecutionState) {
+	if !c.config.PeerReviews360 || c.graph == nil || c.boardConfig == nil || dagState.PartyID == "" {
+		return
+	}
+
+	leaderID := domain.AgentID(c.findLeadAgentID(dagState))
+	partyID := domain.PartyID(dagState.PartyID)
+	now := time.Now()
+	created := 0
+	for _, pair := range collaboratorPairs(dagState, leaderID) {
+		reviewID := domain.PeerReviewID(c.boardConfig.PeerReviewEntityID("pr-" + nuid.Next()))
+		pr := &domain.PeerReview{
+			ID:               reviewID,
+			Status:           domain.PeerReviewPending,
+			QuestID:          domain.QuestID(dagState.ParentQuestID),
+			PartyID:          &partyID,
+			LeaderID:         pair[0],
+			MemberID:         pair[1],
+			IsMemberToMember: true,
+			CreatedAt:        now,
+		}
+		if err := c.graph.EmitEntity(ctx, pr, domain.PredicateReviewPending); err != nil {
+			c.logger.Error("failed to open member-to-member peer review",
+				"execution_id", dagState.ExecutionID,
+				"review_id", reviewID,
+				"error", err)
+			c.errorsCount.Add(1)
+			continue
+		}
+		created++
+	}
+
+	if created > 0 {
+		c.logger.Info("opened 360 peer reviews",
+			"execution_id", dagState.ExecutionID,
+			"party_id", dagState.PartyID,
+			"reviews", created)
+	}
+}
+
 // =============================================================================
 // INDEX — maps sub-quest entity keys to their DAGExecutionState
 // =============================================================================
@@ -224,6 +293,9 @@ func (c *Component) handleNodeCompleted(ctx context.Context, dagState *DAGExecut
 		"completed", len(dagState.CompletedNodes),
 		"total", len(dagState.DAG.Nodes))
 
+	// A fan-out instance may be the last one its template was waiting on.
+	c.completeExpandedTemplates(dagState)
+
 	if c.isDAGComplete(dagState) {
 		c.dispatchLeadSynthesis(ctx, dagState)
 		return
@@ -252,8 +324,22 @@ func (c *Component) handleNodeFailed(ctx context.Context, dagState *DAGExecution
 		"node_id", nodeID,
 		"retries_remaining", retries)
 
+	if dagState.NodeLoopFailures == nil {
+		dagState.NodeLoopFailures = make(map[string]int)
+	}
+	dagState.NodeLoopFailures[nodeID]++
+
 	if retries > 0 {
 		dagState.NodeRetries[nodeID] = retries - 1
+
+		// The same member keeps failing — hand the node to someone else.
+		// Falls through to a plain retry when no replacement is available.
+		if c.config.SwapAfterFailures > 0 && dagState.NodeLoopFailures[nodeID] >= c.config.SwapAfterFailures {
+			if c.swapNodeAssignee(ctx, dagState, nodeID, SwapReasonRepeatedFailures) {
+				return
+			}
+		}
+
 		// Reset to NodeAssigned so the lead can re-dispatch to the same member
 		// or the boid engine can reassign.
 		dagState.NodeStates[nodeID] = NodeAssigned
@@ -309,6 +395,7 @@ func (c *Component) dispatchLeadReview(ctx context.Context, dagState *DAGExecuti
 	subQuestOutput := c.extractQuestOutput(entity)
 
 	systemPrompt := buildLeadReviewSystemPrompt(nodeObjective, nodeAcceptance, subQuestOutput)
+	systemPrompt += c.amendmentPrompt(dagState)
 
 	userPrompt := fmt.Sprintf(
 		"Review the party member's work for sub-quest %q.\n\nObjective: %s\n\n"+
@@ -320,7 +407,7 @@ func (c *Component) dispatchLeadReview(ctx context.Context, dagState *DAGExecuti
 	subjectSafeID := strings.ReplaceAll(subQuestID, ".", "-")
 	loopID := fmt.Sprintf("review-%s-%s", subjectSafeID, nuid.Next())
 
-	reviewTools := NewReviewExecutor().ListTools()
+	reviewTools := append(NewReviewExecutor().ListTools(), c.amendmentTools(dagState)...)
 
 	taskMsg := agentic.TaskMessage{
 		TaskID: subQuestID,
@@ -396,6 +483,7 @@ func (c *Component) dispatchLeadClarification(ctx context.Context, dagState *DAG
 	clarificationQuestion := c.extractQuestOutput(entity)
 
 	systemPrompt := buildLeadClarificationPrompt(nodeObjective, memberID, clarificationQuestion)
+	systemPrompt += c.amendmentPrompt(dagState)
 
 	userPrompt := fmt.Sprintf(
 		"A party member working on sub-quest %q needs clarification.\n\n"+
@@ -407,7 +495,7 @@ func (c *Component) dispatchLeadClarification(ctx context.Context, dagState *DAG
 	subjectSafeID := strings.ReplaceAll(subQuestID, ".", "-")
 	loopID := fmt.Sprintf("clarify-%s-%s", subjectSafeID, nuid.Next())
 
-	clarifyTools := NewClarificationExecutor().ListTools()
+	clarifyTools := append(NewClarificationExecutor().ListTools(), c.amendmentTools(dagState)...)
 
 	taskMsg := agentic.TaskMessage{
 		TaskID: subQuestID,
@@ -541,6 +629,11 @@ func (c *Component) onClarificationAnswered(ctx context.Context, evt dagEvent) {
 	c.logger.Info("event loop: processing clarification answer",
 		"loop_id", evt.LoopID, "result_length", len(evt.Result))
 
+	if am, ok := parseAmendmentResult(evt.Result); ok {
+		c.onAmendmentProposed(ctx, evt, am)
+		return
+	}
+
 	// Parse the answer JSON from the answer_clarification tool output.
 	// Fall back to using the raw result text as the answer when parsing fails.
 	var answer struct {
@@ -847,6 +940,18 @@ func buildLeadSynthesisPrompt(dagState *DAGExecutionState, outputs map[string]an
 		fmt.Fprintf(&sb, "%v\n\n", output)
 	}
 
+	var skipped []string
+	for _, node := range dagState.DAG.Nodes {
+		if dagState.NodeStates[node.ID] == NodeSkipped {
+			skipped = append(skipped, fmt.Sprintf("- %s (%s)\n", node.ID, node.Objective))
+		}
+	}
+	if len(skipped) > 0 {
+		sb.WriteString("SKIPPED SUB-QUESTS (their conditions did not hold — this is expected, not a failure):\n")
+		sb.WriteString(strings.Join(skipped, ""))
+		sb.WriteString("\n")
+	}
+
 	sb.WriteString("INSTRUCTIONS:\n")
 	sb.WriteString("1. Combine these outputs into a single coherent result that fulfills the original quest.\n")
 	sb.WriteString("2. Do not simply concatenate — integrate, organize, and ensure consistency.\n")
@@ -862,6 +967,7 @@ func buildLeadSynthesisPrompt(dagState *DAGExecutionState, outputs map[string]an
 // output. Called by onSynthesisCompleted after the lead combines sub-quest outputs.
 func (c *Component) triggerRollupWithResult(ctx context.Context, dagState *DAGExecutionState, result string) {
 	c.rollupsTriggered.Add(1)
+	c.recordBlueprintOutcome(ctx, dagState, true)
 
 	c.logger.Info("triggering DAG rollup with synthesized result",
 		"execution_id", dagState.ExecutionID,
@@ -896,6 +1002,8 @@ func (c *Component) triggerRollupWithResult(ctx context.Context, dagState *DAGEx
 	}
 	c.aggregateMetricsToParent(ctx, dagState, totalTurns, totalTokensIn, totalTokensOut, totalDuration)
 
+	c.attachDAGDiagram(ctx, dagState)
+
 	if qb := c.resolveQuestBoard(); qb != nil {
 		parentID := domain.QuestID(dagState.ParentQuestID)
 		if err := qb.SubmitResult(ctx, parentID, result); err != nil {
@@ -905,6 +1013,8 @@ func (c *Component) triggerRollupWithResult(ctx context.Context, dagState *DAGEx
 		}
 	}
 
+	c.createPeerReviews360(ctx, dagState)
+
 	if pc := c.resolvePartyCoord(); pc != nil && dagState.PartyID != "" {
 		partyID := domain.PartyID(dagState.PartyID)
 		if err := pc.DisbandParty(ctx, partyID, "DAG completed"); err != nil {
@@ -934,6 +1044,7 @@ func (c *Component) triggerRollupWithResult(ctx context.Context, dagState *DAGEx
 // Used as fallback when synthesis dispatch fails.
 func (c *Component) triggerRollup(ctx context.Context, dagState *DAGExecutionState) {
 	c.rollupsTriggered.Add(1)
+	c.recordBlueprintOutcome(ctx, dagState, true)
 
 	c.logger.Info("triggering DAG rollup",
 		"execution_id", dagState.ExecutionID,
@@ -988,6 +1099,8 @@ func (c *Component) triggerRollup(ctx context.Context, dagState *DAGExecutionSta
 	// Both writes happen in this goroutine sequentially — no CAS needed.
 	c.aggregateMetricsToParent(ctx, dagState, totalTurns, totalTokensIn, totalTokensOut, totalDuration)
 
+	c.attachDAGDiagram(ctx, dagState)
+
 	if qb := c.resolveQuestBoard(); qb != nil {
 		parentID := domain.QuestID(dagState.ParentQuestID)
 		if err := qb.SubmitResult(ctx, parentID, outputs); err != nil {
@@ -998,6 +1111,8 @@ func (c *Component) triggerRollup(ctx context.Context, dagState *DAGExecutionSta
 		}
 	}
 
+	c.createPeerReviews360(ctx, dagState)
+
 	if pc := c.resolvePartyCoord(); pc != nil && dagState.PartyID != "" {
 		partyID := domain.PartyID(dagState.PartyID)
 		if err := pc.DisbandParty(ctx, partyID, "DAG completed"); err != nil {
@@ -1076,7 +1191,22 @@ func (c *Component) promoteReadyNodes(dagState *DAGExecutionState) {
 // assignReadyNodes calls AssignReadyNodes using the partyCoord and questBoard
 // references. It wraps the questboard and partycoord interfaces into the
 // narrow interfaces expected by AssignReadyNodes.
+//
+// Ready conditional and fan-out nodes are resolved first. Skips can finish
+// the DAG, in which case synthesis is dispatched instead; a fan-out that
+// cannot expand escalates the parent.
 func (c *Component) assignReadyNodes(ctx context.Context, dagState *DAGExecutionState) {
+	if err := c.resolveDynamicNodes(ctx, dagState); err != nil {
+		c.logger.Warn("DAG dynamic node resolution failed — escalating parent",
+			"execution_id", dagState.ExecutionID, "error", err)
+		c.escalateParent(ctx, dagState, err.Error())
+		return
+	}
+	if c.isDAGComplete(dagState) {
+		c.dispatchLeadSynthesis(ctx, dagState)
+		return
+	}
+
 	pc := c.resolvePartyCoord()
 	if pc == nil {
 		c.logger.Warn("partyCoord not available — skipping ready node assignment")
@@ -1153,10 +1283,14 @@ func (c *Component) retryNodeAssignment(ctx context.Context, dagState *DAGExecut
 
 // escalateParent transitions the parent quest to escalated state via the
 // questboard. Called when a node fails permanently.
+//
+// A nested DAG (Level > 0) fails its parent sub-quest instead, so the
+// enclosing DAG's retry and triage path decides what happens next.
 func (c *Component) escalateParent(ctx context.Context, dagState *DAGExecutionState, reason string) {
 	// Clean up active sub-quest loops before escalating — prevents sibling
 	// sub-quests from running indefinitely after the parent is escalated.
 	c.cleanupDAGSubQuests(ctx, dagState)
+	c.recordBlueprintOutcome(ctx, dagState, false)
 
 	qb := c.resolveQuestBoard()
 	if qb == nil {
@@ -1166,6 +1300,21 @@ func (c *Component) escalateParent(ctx context.Context, dagState *DAGExecutionSt
 	}
 
 	parentID := domain.QuestID(dagState.ParentQuestID)
+	if dagState.Level > 0 {
+		c.logger.Warn("nested DAG failed — failing parent sub-quest",
+			"execution_id", dagState.ExecutionID,
+			"parent_quest_id", dagState.ParentQuestID,
+			"level", dagState.Level,
+			"reason", reason)
+		if err := qb.FailQuest(ctx, parentID, reason); err != nil {
+			c.logger.Error("failed to fail nested DAG parent sub-quest",
+				"parent_quest_id", dagState.ParentQuestID, "error", err)
+			c.errorsCount.Add(1)
+		}
+		c.releaseDAG(ctx, dagState, "nested DAG failed")
+		return
+	}
+
 	if err := qb.EscalateQuest(ctx, parentID, reason); err != nil {
 		c.logger.Error("failed to escalate parent quest",
 			"parent_quest_id", dagState.ParentQuestID, "error", err)
@@ -1215,6 +1364,10 @@ func (c *Component) sendCancelSignal(ctx context.Context, loopID string) {
 
 // cleanupDAGSubQuests cancels active agentic loops for in-progress sub-quests
 // and fails all incomplete sub-quests. Called from onDAGTimedOut and escalateParent.
+//
+// Sub-quests that were decomposed into nested DAGs are cleaned up depth-first:
+// the nested DAG's own sub-quests are cancelled and its sub-party disbanded
+// before the sub-quest itself is failed.
 func (c *Component) cleanupDAGSubQuests(ctx context.Context, dagState *DAGExecutionState) {
 	qb := c.resolveQuestBoard()
 
@@ -1224,6 +1377,13 @@ func (c *Component) cleanupDAGSubQuests(ctx context.Context, dagState *DAGExecut
 		}
 
 		state := dagState.NodeStates[nodeID]
+		if state != NodeCompleted && state != NodeFailed {
+			if nested := c.findNestedDAG(subQuestID); nested != nil {
+				c.cleanupDAGSubQuests(ctx, nested)
+				c.releaseDAG(ctx, nested, "parent DAG cancelled")
+			}
+		}
+
 		switch state {
 		case NodeInProgress, NodePendingReview, NodeAwaitingClarification:
 			// Active loop — send cancel signal. Look up the quest to find LoopID.
@@ -1255,12 +1415,51 @@ func (c *Component) cleanupDAGSubQuests(ctx context.Context, dagState *DAGExecut
 			dagState.NodeStates[nodeID] = NodeFailed
 		}
 		// NodeCompleted, NodeFailed — already terminal, skip.
+		// NodeSkipped, NodeExpanded — sub-quest already cancelled, skip.
+	}
+}
+
+// findNestedDAG returns the active DAG decomposed from the given sub-quest,
+// or nil when the sub-quest was not decomposed further.
+// Called only from the event loop goroutine.
+func (c *Component) findNestedDAG(subQuestID string) *DAGExecutionState {
+	instance := domain.ExtractInstance(subQuestID)
+	for _, ds := range c.dagCache {
+		if domain.ExtractInstance(ds.ParentQuestID) == instance {
+			return ds
+		}
+	}
+	return nil
+}
+
+// releaseDAG disbands the DAG's party and drops it from the in-memory caches
+// without touching the parent quest. Used for nested DAGs whose parent
+// sub-quest is resolved by the enclosing DAG.
+// Called only from the event loop goroutine.
+func (c *Component) releaseDAG(ctx context.Context, dagState *DAGExecutionState, reason string) {
+	if pc := c.resolvePartyCoord(); pc != nil && dagState.PartyID != "" {
+		partyID := domain.PartyID(dagState.PartyID)
+		if err := pc.DisbandParty(ctx, partyID, reason); err != nil {
+			c.logger.Warn("failed to disband nested DAG party",
+				"party_id", dagState.PartyID, "error", err)
+		}
 	}
+
+	c.completedDAGKeys.Store(dagState.ParentQuestID, true)
+	c.dagStartTimes.Delete(dagState.ExecutionID)
+	delete(c.dagCache, dagState.ExecutionID)
+	for key, ds := range c.dagBySubQuest {
+		if ds.ExecutionID == dagState.ExecutionID {
+			delete(c.dagBySubQuest, key)
+		}
+	}
+	c.pruneReviewRetries(dagState.ExecutionID)
 }
 
 // sweepStaleDags periodically checks dagStartTimes for DAGs that have exceeded
 // the configured timeout. When found, it sends a dagEventDAGTimedOut event
-// to the event loop and removes the entry to avoid re-sending.
+// to the event loop and removes the entry to avoid re-sending. Each tick also
+// sends a dagEventStallSweep so the event loop can swap stalled assignees.
 //
 // Follows the sweepStaleEscalations pattern from questbridge/handler.go.
 func (c *Component) sweepStaleDags(ctx context.Context) {
@@ -1308,6 +1507,16 @@ func (c *Component) sweepStaleDags(ctx context.Context) {
 
 				return true
 			})
+
+			// Ask the event loop to check node heartbeats for stalled or
+			// departed assignees.
+			select {
+			case c.events <- dagEvent{Type: dagEventStallSweep}:
+			case <-c.stopChan:
+				return
+			case <-ctx.Done():
+				return
+			}
 		}
 	}
 }
@@ -1460,13 +1669,13 @@ func (c *Component) findNodeForQuest(dagState *DAGExecutionState, questID string
 	return ""
 }
 
-// isDAGComplete returns true when every node has reached NodeCompleted.
-// Nodes in NodeFailed, NodeRejected, or other non-terminal states mean the
-// DAG has not yet reached a final state (failure escalation handles the error
-// path separately).
+// isDAGComplete returns true when every node has reached NodeCompleted or
+// NodeSkipped. Nodes in NodeFailed, NodeRejected, or other non-terminal states
+// mean the DAG has not yet reached a final state (failure escalation handles
+// the error path separately).
 func (c *Component) isDAGComplete(dagState *DAGExecutionState) bool {
 	for _, node := range dagState.DAG.Nodes {
-		if dagState.NodeStates[node.ID] != NodeCompleted {
+		if !dependencySatisfied(dagState.NodeStates[node.ID]) {
 			return false
 		}
 	}
@@ -1557,7 +1766,12 @@ func (c *Component) extractSubQuestFromLeadLoopID(loopID string) string {
 	default:
 		return ""
 	}
+	return entityIDFromLoopToken(trimmed)
+}
 
+// entityIDFromLoopToken restores an entity ID from the part of a loop ID
+// following its prefix: "{entity-id-with-dashes}-{nuid}".
+func entityIDFromLoopToken(trimmed string) string {
 	lastDash := strings.LastIndex(trimmed, "-")
 	if lastDash < 0 {
 		return ""
@@ -1668,8 +1882,10 @@ func dagStateFromQuest(quest *domain.Quest, defaultRetries int) *DAGExecutionSta
 		failedNodes = []string{}
 	}
 
-	partyID := ""
-	if quest.PartyID != nil {
+	// A nested DAG runs under its own sub-party; the quest's PartyID is the
+	// enclosing party that assigned it.
+	partyID := quest.DAGPartyID
+	if partyID == "" && quest.PartyID != nil {
 		partyID = string(*quest.PartyID)
 	}
 
@@ -1677,6 +1893,7 @@ func dagStateFromQuest(quest *domain.Quest, defaultRetries int) *DAGExecutionSta
 		ExecutionID:    quest.DAGExecutionID,
 		ParentQuestID:  string(quest.ID),
 		PartyID:        partyID,
+		Level:          quest.DAGLevel,
 		QuestTitle:     quest.Title,
 		DAG:            dag,
 		NodeStates:     nodeStates,
@@ -1685,6 +1902,9 @@ func dagStateFromQuest(quest *domain.Quest, defaultRetries int) *DAGExecutionSta
 		CompletedNodes: completedNodes,
 		FailedNodes:    failedNodes,
 		NodeRetries:    nodeRetries,
+		Swaps:          anyToNodeSwaps(quest.DAGSwaps),
+		Amendments:     anyToAmendments(quest.DAGAmendments),
+		Blueprint:      quest.DAGBlueprint,
 	}
 }
 
@@ -1713,6 +1933,46 @@ func anyToQuestDAG(v any) QuestDAG {
 	return dag
 }
 
+// anyToAmendments converts an any value (from JSON round-trip) to the
+// amendment history.
+func anyToAmendments(v any) []DAGAmendment {
+	if v == nil {
+		return nil
+	}
+	if a, ok := v.([]DAGAmendment); ok {
+		return a
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out []DAGAmendment
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// anyToNodeSwaps converts an any value (from JSON round-trip) to the
+// member swap history.
+func anyToNodeSwaps(v any) []NodeSwap {
+	if v == nil {
+		return nil
+	}
+	if s, ok := v.([]NodeSwap); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out []NodeSwap
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
 // anyToStringMap converts an any value (from JSON round-trip) to map[string]string.
 // After JSON round-trip the map arrives as map[string]any with string values.
 func anyToStringMap(v any) map[string]string {
@@ -1821,7 +2081,8 @@ func anyToStringSlice(v any) []string {
 // =============================================================================
 
 // persistDAGState writes the mutable DAG fields (NodeStates, NodeAssignees,
-// CompletedNodes, FailedNodes, NodeRetries) back onto the parent quest entity
+// CompletedNodes, FailedNodes, NodeRetries, and after an amend_dag the
+// definition, sub-quest map and amendment history) back onto the parent quest entity
 // as quest.dag.* predicates. It uses a CAS read-modify-write loop to avoid
 // overwriting concurrent questboard status transitions.
 //
@@ -1850,6 +2111,19 @@ func (c *Component) persistDAGState(ctx context.Context, state *DAGExecutionStat
 		quest.DAGCompletedNodes = state.CompletedNodes
 		quest.DAGFailedNodes = state.FailedNodes
 		quest.DAGNodeRetries = state.NodeRetries
+		if len(state.Swaps) > 0 {
+			quest.DAGSwaps = state.Swaps
+		}
+
+		// Amendments and fan-out expansions rewrite the definition and
+		// sub-quest map, and posting added nodes replaces the parent's
+		// sub-quest list.
+		if len(state.Amendments) > 0 || state.DAG.hasExpansions() {
+			quest.DAGDefinition = state.DAG
+			quest.DAGNodeQuestIDs = state.NodeQuestIDs
+			quest.DAGAmendments = state.Amendments
+			quest.SubQuests = dagSubQuestIDs(state)
+		}
 
 		// 3. CAS write — returns ErrKVRevisionMismatch if questboard wrote concurrently.
 		if err := c.graph.EmitEntityCAS(ctx, quest, "quest.dag.state_updated", revision); err != nil {
@@ -1886,6 +2160,25 @@ func (a *partyCoordTaskAssigner) AssignTask(ctx context.Context, partyID domain.
 	return a.ref.AssignTask(ctx, partyID, subQuestID, assignedTo, rationale)
 }
 
+// graphAgentLister lists idle agents from the graph to satisfy AgentLister.
+// Mirrors partycoord's idle agent query.
+type graphAgentLister struct{ graph *semdragons.GraphClient }
+
+func (a *graphAgentLister) ListIdleAgents(ctx context.Context) ([]*agentprogression.Agent, error) {
+	entities, err := a.graph.ListEntitiesByType(ctx, "agent", 1000)
+	if err != nil {
+		return nil, fmt.Errorf("list agents: %w", err)
+	}
+	var idle []*agentprogression.Agent
+	for i := range entities {
+		agent := agentprogression.AgentFromEntityState(&entities[i])
+		if agent != nil && agent.Status == domain.AgentIdle && agent.CurrentQuest == nil {
+			idle = append(idle, agent)
+		}
+	}
+	return idle, nil
+}
+
 // questBoardClaimer wraps QuestBoardRef to satisfy QuestClaimerAndStarter.
 type questBoardClaimer struct{ ref QuestBoardRef }
 
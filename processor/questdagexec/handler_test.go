@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sync"
 	"testing"
 
@@ -24,10 +25,13 @@ type mockQuestBoardRef struct {
 	failCalls          []failCall
 	escalateCalls      []escalateCall
 	claimAndStartCalls []claimForPartyCall
+	postCalls          []domain.Quest
+	repostCalls        []domain.QuestID
 	submitErr          error
 	failErr            error
 	escalateErr        error
 	claimAndStartErr   error
+	postErr            error
 }
 
 type submitCall struct {
@@ -79,10 +83,25 @@ func (m *mockQuestBoardRef) ClaimAndStartForParty(_ context.Context, questID dom
 	return m.claimAndStartErr
 }
 
-func (m *mockQuestBoardRef) RepostForRetry(_ context.Context, _ domain.QuestID) error {
+func (m *mockQuestBoardRef) RepostForRetry(_ context.Context, questID domain.QuestID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.repostCalls = append(m.repostCalls, questID)
 	return nil
 }
 
+func (m *mockQuestBoardRef) PostSubQuests(_ context.Context, _ domain.QuestID, subQuests []domain.Quest, _ domain.AgentID) ([]domain.Quest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	posted := make([]domain.Quest, len(subQuests))
+	for i, sq := range subQuests {
+		sq.ID = domain.QuestID(fmt.Sprintf("posted-%d", len(m.postCalls)+i))
+		posted[i] = sq
+	}
+	m.postCalls = append(m.postCalls, subQuests...)
+	return posted, m.postErr
+}
+
 // SubmitCallCount returns the number of submit calls (thread-safe).
 func (m *mockQuestBoardRef) SubmitCallCount() int {
 	m.mu.Lock()
@@ -110,9 +129,11 @@ type mockPartyCoordRef struct {
 	assignCalls  []partyAssignCall
 	parties      map[domain.PartyID]*partycoord.Party
 	disbandCalls []disbandCall
+	replaceCalls []replaceCall
 	joinErr      error
 	assignErr    error
 	disbandErr   error
+	replaceErr   error
 }
 
 type partyJoinCall struct {
@@ -133,6 +154,14 @@ type disbandCall struct {
 	reason  string
 }
 
+type replaceCall struct {
+	partyID     domain.PartyID
+	departed    domain.AgentID
+	replacement domain.AgentID
+	subQuestID  domain.QuestID
+	reason      string
+}
+
 func (m *mockPartyCoordRef) JoinParty(_ context.Context, partyID domain.PartyID, agentID domain.AgentID, role domain.PartyRole) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -164,6 +193,13 @@ func (m *mockPartyCoordRef) DisbandParty(_ context.Context, partyID domain.Party
 	return m.disbandErr
 }
 
+func (m *mockPartyCoordRef) ReplaceMember(_ context.Context, partyID domain.PartyID, departed, replacement domain.AgentID, subQuestID domain.QuestID, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replaceCalls = append(m.replaceCalls, replaceCall{partyID, departed, replacement, subQuestID, reason})
+	return m.replaceErr
+}
+
 // DisbandCallCount returns the number of disband calls (thread-safe).
 func (m *mockPartyCoordRef) DisbandCallCount() int {
 	m.mu.Lock()
@@ -892,6 +928,84 @@ func TestFallbackToEscalate(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// NESTED DAG TESTS
+// =============================================================================
+
+func TestEscalateParent_NestedDAGFailsParentSubQuest(t *testing.T) {
+	t.Parallel()
+
+	qb := &mockQuestBoardRef{}
+	pc := &mockPartyCoordRef{}
+	c := newTestComponentWithRetries(qb, pc)
+
+	nested := makeFullDAGState("exec-nested", "quest-outer-n1", "sub-party", []QuestNode{
+		makeNode("a", 0),
+	})
+	nested.Level = 1
+	nested.NodeStates["a"] = NodeFailed
+	c.indexDAGState(nested)
+
+	c.escalateParent(context.Background(), nested, "DAG node a failed after exhausting retries")
+
+	if len(qb.escalateCalls) != 0 {
+		t.Errorf("EscalateQuest called %d times, want 0 for a nested DAG", len(qb.escalateCalls))
+	}
+	if len(qb.failCalls) != 1 || qb.failCalls[0].questID != "quest-outer-n1" {
+		t.Errorf("failCalls = %+v, want the parent sub-quest failed", qb.failCalls)
+	}
+	if len(pc.disbandCalls) != 1 || pc.disbandCalls[0].partyID != "sub-party" {
+		t.Errorf("disbandCalls = %+v, want sub-party disbanded", pc.disbandCalls)
+	}
+	if _, ok := c.dagCache[nested.ExecutionID]; ok {
+		t.Error("nested DAG still cached after failure")
+	}
+}
+
+func TestCleanupDAGSubQuests_CascadesToNestedDAG(t *testing.T) {
+	t.Parallel()
+
+	qb := &mockQuestBoardRef{}
+	pc := &mockPartyCoordRef{}
+	c := newTestComponentWithRetries(qb, pc)
+
+	outer := makeFullDAGState("exec-outer", "parent-outer", "party-outer", []QuestNode{
+		makeNode("n1", 0),
+	})
+	outer.NodeStates["n1"] = NodeAssigned
+	c.indexDAGState(outer)
+
+	// n1's sub-quest was decomposed into a nested DAG run by its own sub-party.
+	nested := makeFullDAGState("exec-nested", outer.NodeQuestIDs["n1"], "sub-party", []QuestNode{
+		makeNode("a", 0),
+		makeNode("b", 0),
+	})
+	nested.Level = 1
+	nested.NodeStates["a"] = NodeAssigned
+	c.indexDAGState(nested)
+
+	c.cleanupDAGSubQuests(context.Background(), outer)
+
+	failed := make(map[domain.QuestID]bool)
+	for _, fc := range qb.failCalls {
+		failed[fc.questID] = true
+	}
+	for _, id := range []string{"quest-a", "quest-b", "quest-n1"} {
+		if !failed[domain.QuestID(id)] {
+			t.Errorf("sub-quest %s not failed; failCalls = %+v", id, qb.failCalls)
+		}
+	}
+	if len(pc.disbandCalls) != 1 || pc.disbandCalls[0].partyID != "sub-party" {
+		t.Errorf("disbandCalls = %+v, want sub-party disbanded", pc.disbandCalls)
+	}
+	if _, ok := c.dagCache[nested.ExecutionID]; ok {
+		t.Error("nested DAG still cached after parent cancellation")
+	}
+	if _, ok := c.dagCache[outer.ExecutionID]; !ok {
+		t.Error("outer DAG removed by cleanup; onDAGTimedOut owns that")
+	}
+}
+
 // =============================================================================
 // handleSubQuestTransition TRIAGE TESTS
 // =============================================================================
@@ -1359,6 +1473,30 @@ func TestCreateReviewEntity(t *testing.T) {
 	})
 }
 
+func TestCollaboratorPairs(t *testing.T) {
+	t.Parallel()
+
+	n2 := makeNode("n2", 0)
+	n2.DependsOn = []string{"n1"}
+	n3 := makeNode("n3", 0)
+	n3.DependsOn = []string{"n1", "n2"}
+	n4 := makeNode("n4", 0)
+	n4.DependsOn = []string{"n3"}
+	dag := makeFullDAGState("exec-pairs", "parent-pairs", "party-pairs", []QuestNode{
+		makeNode("n1", 0), n2, n3, n4,
+	})
+	dag.NodeAssignees["n1"] = "agent-b"
+	dag.NodeAssignees["n2"] = "agent-a"
+	dag.NodeAssignees["n3"] = "agent-a" // same member as n2: no self pair
+	dag.NodeAssignees["n4"] = "agent-lead"
+
+	got := collaboratorPairs(dag, "agent-lead")
+	want := [][2]domain.AgentID{{"agent-a", "agent-b"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("collaboratorPairs = %v, want %v", got, want)
+	}
+}
+
 // =============================================================================
 // promoteReadyNodes TESTS
 // =============================================================================
@@ -1577,6 +1715,55 @@ func TestDagStateFromQuest(t *testing.T) {
 		}
 	})
 
+	t.Run("nested DAG uses its sub-party and level", func(t *testing.T) {
+		t.Parallel()
+
+		outerParty := domain.PartyID("party-outer")
+		quest := &domain.Quest{
+			ID:              domain.QuestID("sub-q-3"),
+			PartyID:         &outerParty,
+			DAGPartyID:      "party-sub",
+			DAGLevel:        1,
+			DAGExecutionID:  "exec-3",
+			DAGDefinition:   twoNodeDAG,
+			DAGNodeQuestIDs: map[string]string{"n1": "sq-1", "n2": "sq-2"},
+		}
+
+		state := dagStateFromQuest(quest, 2)
+		if state == nil {
+			t.Fatal("dagStateFromQuest returned nil")
+		}
+		if state.PartyID != "party-sub" {
+			t.Errorf("PartyID = %q, want party-sub", state.PartyID)
+		}
+		if state.Level != 1 {
+			t.Errorf("Level = %d, want 1", state.Level)
+		}
+	})
+
+	t.Run("amendment history survives a JSON round-trip", func(t *testing.T) {
+		t.Parallel()
+
+		quest := &domain.Quest{
+			ID:              domain.QuestID("parent-q-4"),
+			DAGExecutionID:  "exec-4",
+			DAGDefinition:   twoNodeDAG,
+			DAGNodeQuestIDs: map[string]string{"n1": "sq-1", "n2": "sq-2"},
+			DAGAmendments: []any{map[string]any{
+				"reason": "split n2", "remove": []any{"n3"}, "status": AmendmentApplied,
+			}},
+		}
+
+		state := dagStateFromQuest(quest, 2)
+		if state == nil {
+			t.Fatal("dagStateFromQuest returned nil")
+		}
+		if len(state.Amendments) != 1 || state.Amendments[0].Status != AmendmentApplied ||
+			state.Amendments[0].Remove[0] != "n3" {
+			t.Errorf("Amendments = %+v", state.Amendments)
+		}
+	})
+
 	t.Run("nil quest returns nil", func(t *testing.T) {
 		t.Parallel()
 		state := dagStateFromQuest(nil, 2)
@@ -35,6 +35,18 @@ type RecruitmentDeps struct {
 	PartyJoins PartyJoiner
 }
 
+// MemberReplacer swaps a departed party member for a replacement on a
+// sub-quest. The signature mirrors partycoord.Component.ReplaceMember.
+type MemberReplacer interface {
+	ReplaceMember(ctx context.Context, partyID domain.PartyID, departed, replacement domain.AgentID, subQuestID domain.QuestID, reason string) error
+}
+
+// ReplacementDeps bundles the dependencies required by RecruitReplacement.
+type ReplacementDeps struct {
+	Agents   AgentLister
+	Replacer MemberReplacer
+}
+
 // PartyMemberLister returns the current members of a party.
 type PartyMemberLister interface {
 	GetParty(partyID domain.PartyID) (*partycoord.Party, bool)
@@ -186,6 +198,66 @@ func selectCandidate(agents []*agentprogression.Agent, node QuestNode, recruited
 	return best, bestScore
 }
 
+// =============================================================================
+// RECRUIT REPLACEMENT
+// =============================================================================
+
+// RecruitReplacement recruits an idle agent to take over a node whose
+// assignee stalled or left, using the same scoring as RecruitMembers. The
+// departed assignee and agents working other active nodes of the DAG are
+// never chosen. The replacement is swapped into the party in place of the
+// departed member; DAG state is left for the caller to update.
+//
+// Returns an error when no eligible idle agent exists. Callers should retry
+// on a later sweep.
+func RecruitReplacement(ctx context.Context, dagState *DAGExecutionState, nodeID, reason string, deps ReplacementDeps) (domain.AgentID, error) {
+	departed := domain.AgentID(dagState.NodeAssignees[nodeID])
+	if departed == "" {
+		return "", fmt.Errorf("node %s has no assignee to replace", nodeID)
+	}
+
+	var node QuestNode
+	found := false
+	for _, n := range dagState.DAG.Nodes {
+		if n.ID == nodeID {
+			node, found = n, true
+			break
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("node %s not in DAG", nodeID)
+	}
+
+	idleAgents, err := deps.Agents.ListIdleAgents(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list idle agents: %w", err)
+	}
+
+	excluded := map[domain.AgentID]struct{}{departed: {}}
+	for otherID, agentID := range dagState.NodeAssignees {
+		state := dagState.NodeStates[otherID]
+		if otherID != nodeID && state != NodeCompleted && state != NodeFailed {
+			excluded[domain.AgentID(agentID)] = struct{}{}
+		}
+	}
+
+	best, score := selectCandidate(idleAgents, node, excluded)
+	if best == nil || score < 0 {
+		return "", fmt.Errorf("no eligible idle agent to replace %s on node %s", departed, nodeID)
+	}
+
+	subQuestID := domain.QuestID(dagState.NodeQuestIDs[nodeID])
+	if err := deps.Replacer.ReplaceMember(ctx, domain.PartyID(dagState.PartyID), departed, best.ID, subQuestID, reason); err != nil {
+		return "", fmt.Errorf("replace %s with %s (node %s): %w", departed, best.ID, nodeID, err)
+	}
+
+	slog.Default().Debug("recruitment: replacement selected",
+		"execution_id", dagState.ExecutionID, "node_id", nodeID,
+		"departed", departed, "replacement", best.ID, "score", score)
+
+	return best.ID, nil
+}
+
 // =============================================================================
 // ASSIGN READY NODES
 // =============================================================================
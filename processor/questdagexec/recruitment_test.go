@@ -369,6 +369,96 @@ func TestRecruitMembersPrefersBestSkillMatch(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// RecruitReplacement TESTS
+// =============================================================================
+
+func TestRecruitReplacement(t *testing.T) {
+	t.Parallel()
+
+	newState := func() *DAGExecutionState {
+		dagState := makeDAGState("party-swap", []QuestNode{
+			makeNode("n1", 0, string(domain.SkillCodeGen), string(domain.SkillCodeReview)),
+			makeNode("n2", 0),
+		})
+		dagState.NodeStates["n1"] = NodeInProgress
+		dagState.NodeStates["n2"] = NodeInProgress
+		dagState.NodeAssignees["n1"] = "agent-departed"
+		dagState.NodeAssignees["n2"] = "agent-busy"
+		return dagState
+	}
+
+	t.Run("picks best fit excluding departed and busy", func(t *testing.T) {
+		t.Parallel()
+		dagState := newState()
+		replacer := &mockPartyCoordRef{}
+		deps := ReplacementDeps{
+			Agents: &mockAgentLister{agents: []*agentprogression.Agent{
+				makeAgent("agent-departed", 1, domain.SkillCodeGen, domain.SkillCodeReview),
+				makeAgent("agent-busy", 1, domain.SkillCodeGen, domain.SkillCodeReview),
+				makeAgent("agent-partial", 1, domain.SkillCodeGen),
+				makeAgent("agent-expert", 1, domain.SkillCodeGen, domain.SkillCodeReview),
+			}},
+			Replacer: replacer,
+		}
+
+		got, err := RecruitReplacement(context.Background(), dagState, "n1", SwapReasonStalled, deps)
+		if err != nil {
+			t.Fatalf("RecruitReplacement() unexpected error: %v", err)
+		}
+		if got != "agent-expert" {
+			t.Errorf("replacement = %q, want agent-expert", got)
+		}
+		if len(replacer.replaceCalls) != 1 {
+			t.Fatalf("expected 1 replace call, got %d", len(replacer.replaceCalls))
+		}
+		call := replacer.replaceCalls[0]
+		if call.departed != "agent-departed" || call.replacement != "agent-expert" ||
+			call.subQuestID != "quest-n1" || call.reason != SwapReasonStalled {
+			t.Errorf("replace call = %+v", call)
+		}
+	})
+
+	t.Run("no eligible agent", func(t *testing.T) {
+		t.Parallel()
+		replacer := &mockPartyCoordRef{}
+		deps := ReplacementDeps{
+			Agents:   &mockAgentLister{agents: []*agentprogression.Agent{makeAgent("agent-busy", 1)}},
+			Replacer: replacer,
+		}
+		if _, err := RecruitReplacement(context.Background(), newState(), "n1", SwapReasonStalled, deps); err == nil {
+			t.Fatal("expected error when only busy agents are idle")
+		}
+		if len(replacer.replaceCalls) != 0 {
+			t.Errorf("expected no replace calls, got %d", len(replacer.replaceCalls))
+		}
+	})
+
+	t.Run("node without assignee", func(t *testing.T) {
+		t.Parallel()
+		dagState := newState()
+		delete(dagState.NodeAssignees, "n1")
+		deps := ReplacementDeps{
+			Agents:   &mockAgentLister{agents: []*agentprogression.Agent{makeAgent("agent-free", 1)}},
+			Replacer: &mockPartyCoordRef{},
+		}
+		if _, err := RecruitReplacement(context.Background(), dagState, "n1", SwapReasonStalled, deps); err == nil {
+			t.Fatal("expected error for node without assignee")
+		}
+	})
+
+	t.Run("replacer error", func(t *testing.T) {
+		t.Parallel()
+		deps := ReplacementDeps{
+			Agents:   &mockAgentLister{agents: []*agentprogression.Agent{makeAgent("agent-free", 1)}},
+			Replacer: &mockPartyCoordRef{replaceErr: errors.New("party gone")},
+		}
+		if _, err := RecruitReplacement(context.Background(), newState(), "n1", SwapReasonStalled, deps); err == nil {
+			t.Fatal("expected replacer error to propagate")
+		}
+	})
+}
+
 // =============================================================================
 // AssignReadyNodes TESTS
 // =============================================================================
@@ -0,0 +1,534 @@
+package questdagexec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semstreams/graph"
+)
+
+// =============================================================================
+// DAG VISUALIZATION
+// =============================================================================
+//
+// A DAGView is a snapshot of one DAG execution: the node graph joined with
+// live node states, assignees, retry budgets, and per-node duration and token
+// spend read from the sub-quests. It renders as a Mermaid flowchart, a
+// Graphviz DOT digraph, or JSON. The API serves it at /quests/{id}/dag, the
+// CLI writes it to a file, and rollups can attach the Mermaid form to the
+// parent quest's result.
+// =============================================================================
+
+// Render formats accepted by RenderDAGView.
+const (
+	DAGFormatJSON    = "json"
+	DAGFormatMermaid = "mermaid"
+	DAGFormatDOT     = "dot"
+)
+
+// Edge kinds in a DAGView.
+const (
+	EdgeDependsOn = "depends_on" // From must complete before To starts
+	EdgeFanOut    = "fan_out"    // To is an instance expanded from template From
+)
+
+// ErrNoDAG is returned by LoadDAGView when the quest was never decomposed.
+var ErrNoDAG = errors.New("quest has no DAG")
+
+// DAGView is a renderable snapshot of a DAG execution.
+type DAGView struct {
+	ExecutionID   string     `json:"execution_id"`
+	ParentQuestID string     `json:"parent_quest_id"`
+	Title         string     `json:"title,omitempty"`
+	Level         int        `json:"level,omitempty"`
+	Nodes         []NodeView `json:"nodes"`
+	Edges         []DAGEdge  `json:"edges"`
+	// CriticalPath lists node IDs from the first node to the last along the
+	// longest chain of dependencies, weighted by node duration.
+	CriticalPath []string  `json:"critical_path,omitempty"`
+	DurationMS   int64     `json:"duration_ms"`
+	TokensIn     int       `json:"tokens_in"`
+	TokensOut    int       `json:"tokens_out"`
+	GeneratedAt  time.Time `json:"generated_at"`
+}
+
+// NodeView is one node of a DAGView.
+type NodeView struct {
+	ID               string   `json:"id"`
+	Objective        string   `json:"objective"`
+	State            string   `json:"state"`
+	Assignee         string   `json:"assignee,omitempty"`
+	SubQuestID       string   `json:"sub_quest_id,omitempty"`
+	DependsOn        []string `json:"depends_on,omitempty"`
+	RetriesRemaining int      `json:"retries_remaining"`
+	DurationMS       int64    `json:"duration_ms,omitempty"`
+	TokensIn         int      `json:"tokens_in,omitempty"`
+	TokensOut        int      `json:"tokens_out,omitempty"`
+	Critical         bool     `json:"critical,omitempty"`
+}
+
+// DAGEdge is a directed edge of a DAGView.
+type DAGEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// QuestGetter loads quest entities. *semdragons.GraphClient satisfies it.
+type QuestGetter interface {
+	GetQuest(ctx context.Context, questID domain.QuestID) (*graph.EntityState, error)
+}
+
+// LoadDAGView loads a parent quest and its sub-quests and builds the view.
+// Sub-quests that fail to load are rendered without metrics.
+func LoadDAGView(ctx context.Context, quests QuestGetter, questID domain.QuestID) (*DAGView, error) {
+	entity, err := quests.GetQuest(ctx, questID)
+	if err != nil {
+		return nil, err
+	}
+	quest := domain.QuestFromEntityState(entity)
+	if quest == nil {
+		return nil, fmt.Errorf("reconstruct quest %s", questID)
+	}
+	state := dagStateFromQuest(quest, DefaultConfig().MaxRetriesPerNode)
+	if state == nil || len(state.DAG.Nodes) == 0 {
+		return nil, ErrNoDAG
+	}
+
+	subQuests := make(map[string]*domain.Quest, len(state.NodeQuestIDs))
+	for nodeID, subQuestID := range state.NodeQuestIDs {
+		if subQuestID == "" {
+			continue
+		}
+		sqEntity, err := quests.GetQuest(ctx, domain.QuestID(subQuestID))
+		if err != nil {
+			continue
+		}
+		if sq := domain.QuestFromEntityState(sqEntity); sq != nil {
+			subQuests[nodeID] = sq
+		}
+	}
+	return BuildDAGView(state, subQuests, time.Now()), nil
+}
+
+// attachDAGDiagram stores a Mermaid rendering of the DAG on the parent quest
+// as quest.dag.diagram. It is kept apart from the rollup result so it never
+// collides with a node's output. Does nothing when attach_dag_diagram is off
+// or the graph is unavailable. Called before SubmitResult transitions the
+// parent, like aggregateMetricsToParent.
+func (c *Component) attachDAGDiagram(ctx context.Context, dagState *DAGExecutionState) {
+	if !c.config.AttachDAGDiagram || c.graph == nil {
+		return
+	}
+	parentID := domain.QuestID(dagState.ParentQuestID)
+	parentEntity, err := c.graph.GetQuest(ctx, parentID)
+	if err != nil {
+		c.logger.Warn("failed to load parent quest for DAG diagram",
+			"parent_quest_id", dagState.ParentQuestID, "error", err)
+		return
+	}
+	parent := domain.QuestFromEntityState(parentEntity)
+	if parent == nil {
+		return
+	}
+	parent.DAGDiagram = c.dagDiagram(ctx, dagState)
+	if err := c.graph.EmitEntityUpdate(ctx, parent, "quest.dag.diagram"); err != nil {
+		c.logger.Warn("failed to emit DAG diagram on parent quest",
+			"parent_quest_id", dagState.ParentQuestID, "error", err)
+	}
+}
+
+// dagDiagram renders the DAG as a Mermaid markdown section.
+func (c *Component) dagDiagram(ctx context.Context, dagState *DAGExecutionState) string {
+	subQuests := make(map[string]*domain.Quest, len(dagState.NodeQuestIDs))
+	for nodeID, subQuestID := range dagState.NodeQuestIDs {
+		entity, err := c.graph.GetQuest(ctx, domain.QuestID(subQuestID))
+		if err != nil {
+			continue
+		}
+		if sq := domain.QuestFromEntityState(entity); sq != nil {
+			subQuests[nodeID] = sq
+		}
+	}
+	return MermaidMarkdown(BuildDAGView(dagState, subQuests, time.Now()))
+}
+
+// BuildDAGView joins DAG execution state with its sub-quests, keyed by node
+// ID. A running node's duration is measured up to now.
+func BuildDAGView(state *DAGExecutionState, subQuests map[string]*domain.Quest, now time.Time) *DAGView {
+	view := &DAGView{
+		ExecutionID:   state.ExecutionID,
+		ParentQuestID: state.ParentQuestID,
+		Title:         state.QuestTitle,
+		Level:         state.Level,
+		Nodes:         make([]NodeView, 0, len(state.DAG.Nodes)),
+		Edges:         []DAGEdge{},
+		GeneratedAt:   now,
+	}
+
+	durations := make(map[string]time.Duration, len(state.DAG.Nodes))
+	for _, node := range state.DAG.Nodes {
+		nv := NodeView{
+			ID:               node.ID,
+			Objective:        node.Objective,
+			State:            state.NodeStates[node.ID],
+			Assignee:         state.NodeAssignees[node.ID],
+			SubQuestID:       state.NodeQuestIDs[node.ID],
+			DependsOn:        node.DependsOn,
+			RetriesRemaining: state.NodeRetries[node.ID],
+		}
+		if sq := subQuests[node.ID]; sq != nil {
+			d := subQuestDuration(sq, now)
+			durations[node.ID] = d
+			nv.DurationMS = d.Milliseconds()
+			nv.TokensIn = sq.TokensPrompt
+			nv.TokensOut = sq.TokensCompletion
+			view.TokensIn += sq.TokensPrompt
+			view.TokensOut += sq.TokensCompletion
+		}
+		view.Nodes = append(view.Nodes, nv)
+
+		for _, dep := range node.DependsOn {
+			view.Edges = append(view.Edges, DAGEdge{From: dep, To: node.ID, Kind: EdgeDependsOn})
+		}
+		if node.ExpandedFrom != "" {
+			view.Edges = append(view.Edges, DAGEdge{From: node.ExpandedFrom, To: node.ID, Kind: EdgeFanOut})
+		}
+	}
+
+	var total time.Duration
+	view.CriticalPath, total = criticalPath(state.DAG, durations)
+	view.DurationMS = total.Milliseconds()
+	onPath := make(map[string]bool, len(view.CriticalPath))
+	for _, id := range view.CriticalPath {
+		onPath[id] = true
+	}
+	for i := range view.Nodes {
+		view.Nodes[i].Critical = onPath[view.Nodes[i].ID]
+	}
+	return view
+}
+
+// subQuestDuration returns how long a sub-quest has run: its recorded
+// duration once finished, or time since start while it is still running.
+func subQuestDuration(sq *domain.Quest, now time.Time) time.Duration {
+	switch {
+	case sq.Duration > 0:
+		return sq.Duration
+	case sq.StartedAt != nil && sq.CompletedAt != nil:
+		return sq.CompletedAt.Sub(*sq.StartedAt)
+	case sq.StartedAt != nil && sq.Status == domain.QuestInProgress:
+		return now.Sub(*sq.StartedAt)
+	}
+	return 0
+}
+
+// criticalPath returns the longest dependency chain through the DAG and its
+// total duration. Nodes are weighted by duration; before any node has run,
+// every node weighs the same and the path is the longest chain by count. A
+// fan-out template depends on its instances, since it finishes only when
+// they all have.
+func criticalPath(dag QuestDAG, durations map[string]time.Duration) ([]string, time.Duration) {
+	if len(dag.Nodes) == 0 {
+		return nil, 0
+	}
+
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	weight := func(id string) time.Duration {
+		if total == 0 {
+			return 1
+		}
+		return durations[id]
+	}
+
+	deps := make(map[string][]string, len(dag.Nodes))
+	for _, node := range dag.Nodes {
+		deps[node.ID] = append(append([]string(nil), node.DependsOn...), dag.FanOutInstances(node.ID)...)
+	}
+
+	// Longest path ending at each node, memoized. A validated DAG is acyclic;
+	// the visiting set guards against malformed stored definitions.
+	length := make(map[string]time.Duration, len(dag.Nodes))
+	prev := make(map[string]string, len(dag.Nodes))
+	visiting := make(map[string]bool)
+	var visit func(id string) time.Duration
+	visit = func(id string) time.Duration {
+		if l, ok := length[id]; ok {
+			return l
+		}
+		if visiting[id] {
+			return 0
+		}
+		visiting[id] = true
+		var best time.Duration
+		bestDep := ""
+		for _, dep := range deps[id] {
+			if _, known := deps[dep]; !known {
+				continue
+			}
+			if l := visit(dep); bestDep == "" || l > best {
+				best, bestDep = l, dep
+			}
+		}
+		visiting[id] = false
+		length[id] = best + weight(id)
+		prev[id] = bestDep
+		return length[id]
+	}
+
+	// Ties go to the later node so a path extends through pending
+	// zero-duration successors to the DAG's sink.
+	end := ""
+	var endLen time.Duration
+	for _, node := range dag.Nodes {
+		if l := visit(node.ID); end == "" || l >= endLen {
+			end, endLen = node.ID, l
+		}
+	}
+
+	var path []string
+	for id := end; id != ""; id = prev[id] {
+		path = append(path, id)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	if total == 0 {
+		return path, 0
+	}
+	return path, endLen
+}
+
+// =============================================================================
+// RENDERERS
+// =============================================================================
+
+// RenderDAGView renders the view in the given format and returns the body
+// with its content type. An empty format means JSON.
+func RenderDAGView(view *DAGView, format string) ([]byte, string, error) {
+	switch format {
+	case "", DAGFormatJSON:
+		data, err := json.MarshalIndent(view, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("marshal DAG view: %w", err)
+		}
+		return data, "application/json", nil
+	case DAGFormatMermaid:
+		return []byte(RenderMermaid(view)), "text/vnd.mermaid; charset=utf-8", nil
+	case DAGFormatDOT:
+		return []byte(RenderDOT(view)), "text/vnd.graphviz; charset=utf-8", nil
+	}
+	return nil, "", fmt.Errorf("unknown DAG format %q (want %s, %s or %s)", format, DAGFormatJSON, DAGFormatMermaid, DAGFormatDOT)
+}
+
+// nodeStateColors maps node states to fill colors shared by both renderers.
+var nodeStateColors = map[string]string{
+	NodePending:               "#eeeeee",
+	NodeReady:                 "#d6eaf8",
+	NodeAssigned:              "#d6eaf8",
+	NodeInProgress:            "#fff3cd",
+	NodePendingReview:         "#ffe5b4",
+	NodeAwaitingClarification: "#f5cba7",
+	NodePendingTriage:         "#f5cba7",
+	NodeRejected:              "#f8d7da",
+	NodeCompleted:             "#d4edda",
+	NodeFailed:                "#f5b7b1",
+	NodeSkipped:               "#f4f4f4",
+	NodeExpanded:              "#e8daef",
+}
+
+// RenderMermaid renders the view as a Mermaid flowchart. Critical-path nodes
+// get a thick border; fan-out edges are dotted.
+func RenderMermaid(view *DAGView) string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+
+	ids := make(map[string]string, len(view.Nodes))
+	for _, n := range view.Nodes {
+		ids[n.ID] = mermaidID(n.ID)
+	}
+
+	for _, n := range view.Nodes {
+		fmt.Fprintf(&b, "  %s[\"%s\"]", ids[n.ID], mermaidEscape(strings.Join(nodeLabelLines(n), "<br/>")))
+		if n.State != "" {
+			fmt.Fprintf(&b, ":::%s", mermaidClass(n.State))
+		}
+		b.WriteString("\n")
+	}
+	for _, e := range view.Edges {
+		from, ok := ids[e.From]
+		if !ok {
+			continue
+		}
+		arrow := "-->"
+		if e.Kind == EdgeFanOut {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", from, arrow, ids[e.To])
+	}
+
+	for _, state := range sortedStates(view) {
+		fmt.Fprintf(&b, "  classDef %s fill:%s,stroke:#555\n", mermaidClass(state), stateColor(state))
+	}
+	if len(view.CriticalPath) > 0 {
+		critical := make([]string, 0, len(view.CriticalPath))
+		for _, id := range view.CriticalPath {
+			critical = append(critical, ids[id])
+		}
+		b.WriteString("  classDef critical stroke:#c0392b,stroke-width:3px\n")
+		fmt.Fprintf(&b, "  class %s critical\n", strings.Join(critical, ","))
+	}
+	return b.String()
+}
+
+// MermaidMarkdown wraps the Mermaid rendering in a fenced markdown section
+// with a one-line summary, for attaching to quest results.
+func MermaidMarkdown(view *DAGView) string {
+	var b strings.Builder
+	b.WriteString("## Execution DAG\n\n")
+	fmt.Fprintf(&b, "%d nodes · critical path %s · %s · %s tokens\n\n",
+		len(view.Nodes),
+		strings.Join(view.CriticalPath, " → "),
+		formatDurationMS(view.DurationMS),
+		formatTokens(view.TokensIn+view.TokensOut))
+	b.WriteString("```mermaid\n")
+	b.WriteString(RenderMermaid(view))
+	b.WriteString("```\n")
+	return b.String()
+}
+
+// RenderDOT renders the view as a Graphviz digraph. Critical-path nodes and
+// the edges between them are drawn heavier; fan-out edges are dashed.
+func RenderDOT(view *DAGView) string {
+	var b strings.Builder
+	b.WriteString("digraph dag {\n")
+	b.WriteString("  rankdir=TB;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	if view.Title != "" {
+		fmt.Fprintf(&b, "  label=%s;\n  labelloc=t;\n", dotQuote(view.Title))
+	}
+
+	critical := make(map[string]bool, len(view.CriticalPath))
+	for _, id := range view.CriticalPath {
+		critical[id] = true
+	}
+
+	for _, n := range view.Nodes {
+		attrs := []string{
+			"label=" + dotQuote(strings.Join(nodeLabelLines(n), "\n")),
+			"fillcolor=" + dotQuote(stateColor(n.State)),
+		}
+		if critical[n.ID] {
+			attrs = append(attrs, "penwidth=3", `color="#c0392b"`)
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.ID), strings.Join(attrs, ", "))
+	}
+	for _, e := range view.Edges {
+		var attrs []string
+		if e.Kind == EdgeFanOut {
+			attrs = append(attrs, "style=dashed")
+		}
+		if critical[e.From] && critical[e.To] {
+			attrs = append(attrs, "penwidth=3", `color="#c0392b"`)
+		}
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(e.From), dotQuote(e.To))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// nodeLabelLines returns the label text for a node: ID and state, the
+// objective, then assignee and spend when known.
+func nodeLabelLines(n NodeView) []string {
+	lines := []string{n.ID + " (" + n.State + ")", truncate(n.Objective, 60)}
+
+	var details []string
+	if n.Assignee != "" {
+		details = append(details, domain.ExtractInstance(n.Assignee))
+	}
+	if n.DurationMS > 0 {
+		details = append(details, formatDurationMS(n.DurationMS))
+	}
+	if tokens := n.TokensIn + n.TokensOut; tokens > 0 {
+		details = append(details, formatTokens(tokens)+" tok")
+	}
+	details = append(details, fmt.Sprintf("retries %d", n.RetriesRemaining))
+	return append(lines, strings.Join(details, " · "))
+}
+
+// sortedStates returns the distinct node states in the view, sorted, so the
+// rendered class definitions are stable.
+func sortedStates(view *DAGView) []string {
+	seen := make(map[string]bool)
+	var states []string
+	for _, n := range view.Nodes {
+		if n.State != "" && !seen[n.State] {
+			seen[n.State] = true
+			states = append(states, n.State)
+		}
+	}
+	sort.Strings(states)
+	return states
+}
+
+func stateColor(state string) string {
+	if c, ok := nodeStateColors[state]; ok {
+		return c
+	}
+	return "#ffffff"
+}
+
+// mermaidID turns a node ID into a Mermaid-safe identifier. Fan-out
+// instance IDs contain '#', which Mermaid treats as an entity marker.
+func mermaidID(id string) string {
+	var b strings.Builder
+	b.WriteString("n_")
+	for _, r := range id {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		} else {
+			fmt.Fprintf(&b, "_%x_", r)
+		}
+	}
+	return b.String()
+}
+
+// mermaidClass turns a node state into a class name.
+func mermaidClass(state string) string {
+	return "state_" + strings.ReplaceAll(state, "-", "_")
+}
+
+// mermaidEscape escapes text for a double-quoted Mermaid label.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s)
+}
+
+// dotQuote returns s as a double-quoted DOT string.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func formatDurationMS(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).Round(time.Second).String()
+}
+
+func formatTokens(n int) string {
+	if n >= 1000 {
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
@@ -0,0 +1,242 @@
+package questdagexec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semstreams/graph"
+)
+
+// makeRenderDAGState builds a diamond DAG: design → (api, docs) → release.
+func makeRenderDAGState() *DAGExecutionState {
+	state := makeFullDAGState("exec-render", "parent-render", "party-render", []QuestNode{
+		{ID: "design", Objective: "Design the \"service\""},
+		{ID: "api", Objective: "Build the API", DependsOn: []string{"design"}},
+		{ID: "docs", Objective: "Write docs", DependsOn: []string{"design"}},
+		{ID: "release", Objective: "Release", DependsOn: []string{"api", "docs"}},
+	})
+	state.QuestTitle = "Ship service"
+	state.NodeStates["design"] = NodeCompleted
+	state.NodeStates["api"] = NodeInProgress
+	state.NodeStates["docs"] = NodeCompleted
+	state.NodeAssignees["api"] = "local.dev.game.board1.agent.smith"
+	state.NodeRetries["api"] = 1
+	return state
+}
+
+func TestBuildDAGView(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-10 * time.Minute)
+	subQuests := map[string]*domain.Quest{
+		"design": {Status: domain.QuestCompleted, Duration: 2 * time.Minute, TokensPrompt: 1000, TokensCompletion: 500},
+		"api":    {Status: domain.QuestInProgress, StartedAt: &started, TokensPrompt: 2000},
+		"docs":   {Status: domain.QuestCompleted, Duration: time.Minute, TokensPrompt: 300, TokensCompletion: 100},
+	}
+
+	view := BuildDAGView(makeRenderDAGState(), subQuests, now)
+
+	if want := []string{"design", "api", "release"}; !reflect.DeepEqual(view.CriticalPath, want) {
+		t.Errorf("CriticalPath = %v, want %v", view.CriticalPath, want)
+	}
+	if want := (12 * time.Minute).Milliseconds(); view.DurationMS != want {
+		t.Errorf("DurationMS = %d, want %d", view.DurationMS, want)
+	}
+	if view.TokensIn != 3300 || view.TokensOut != 600 {
+		t.Errorf("tokens = %d/%d, want 3300/600", view.TokensIn, view.TokensOut)
+	}
+	if len(view.Edges) != 4 {
+		t.Errorf("edges = %d, want 4", len(view.Edges))
+	}
+
+	api := view.Nodes[1]
+	if api.ID != "api" || api.State != NodeInProgress || api.RetriesRemaining != 1 || !api.Critical {
+		t.Errorf("api node = %+v", api)
+	}
+	if api.DurationMS != (10 * time.Minute).Milliseconds() {
+		t.Errorf("running node duration = %d, want 10m", api.DurationMS)
+	}
+	if view.Nodes[2].Critical {
+		t.Error("docs should not be on the critical path")
+	}
+}
+
+func TestCriticalPathWithoutDurations(t *testing.T) {
+	t.Parallel()
+
+	dag := QuestDAG{Nodes: []QuestNode{
+		{ID: "a"},
+		{ID: "b", DependsOn: []string{"a"}},
+		{ID: "c", DependsOn: []string{"b"}},
+		{ID: "d", DependsOn: []string{"a"}},
+	}}
+	path, total := criticalPath(dag, nil)
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(path, want) {
+		t.Errorf("path = %v, want %v", path, want)
+	}
+	if total != 0 {
+		t.Errorf("total = %v, want 0 without durations", total)
+	}
+}
+
+func TestCriticalPathThroughFanOut(t *testing.T) {
+	t.Parallel()
+
+	dag := QuestDAG{Nodes: []QuestNode{
+		{ID: "list"},
+		{ID: "gen", DependsOn: []string{"list"}, FanOut: &FanOutSpec{From: "list", Field: "items"}},
+		{ID: "gen#1", DependsOn: []string{"list"}, ExpandedFrom: "gen"},
+		{ID: "gen#2", DependsOn: []string{"list"}, ExpandedFrom: "gen"},
+		{ID: "join", DependsOn: []string{"gen"}},
+	}}
+	durations := map[string]time.Duration{
+		"list":  time.Minute,
+		"gen#1": time.Minute,
+		"gen#2": 5 * time.Minute,
+		"join":  time.Minute,
+	}
+	path, total := criticalPath(dag, durations)
+	if want := []string{"list", "gen#2", "gen", "join"}; !reflect.DeepEqual(path, want) {
+		t.Errorf("path = %v, want %v", path, want)
+	}
+	if total != 7*time.Minute {
+		t.Errorf("total = %v, want 7m", total)
+	}
+}
+
+func TestRenderMermaid(t *testing.T) {
+	t.Parallel()
+
+	state := makeRenderDAGState()
+	state.DAG.Nodes = append(state.DAG.Nodes, QuestNode{ID: "gen#1", Objective: "instance", ExpandedFrom: "release"})
+	out := RenderMermaid(BuildDAGView(state, nil, time.Now()))
+
+	for _, want := range []string{
+		"flowchart TD\n",
+		`n_design["design (completed)<br/>Design the #quot;service#quot;`,
+		":::state_in_progress",
+		"n_design --> n_api",
+		"n_release -.-> n_gen_23_1",
+		"classDef state_completed fill:#d4edda",
+		"smith",
+		"class n_design,",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("mermaid output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderDOT(t *testing.T) {
+	t.Parallel()
+
+	out := RenderDOT(BuildDAGView(makeRenderDAGState(), nil, time.Now()))
+	for _, want := range []string{
+		"digraph dag {\n",
+		`label="Ship service";`,
+		`"design" [label="design (completed)\nDesign the \"service\"`,
+		`fillcolor="#d4edda"`,
+		`"design" -> "api" [penwidth=3, color="#c0392b"];`,
+		`"design" -> "docs";`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dot output missing %q:\n%s", want, out)
+		}
+	}
+	if !strings.HasSuffix(out, "}\n") {
+		t.Errorf("dot output not closed:\n%s", out)
+	}
+}
+
+func TestRenderDAGView(t *testing.T) {
+	t.Parallel()
+
+	view := BuildDAGView(makeRenderDAGState(), nil, time.Now())
+
+	data, contentType, err := RenderDAGView(view, "")
+	if err != nil || contentType != "application/json" {
+		t.Fatalf("RenderDAGView(json) = %q, %v", contentType, err)
+	}
+	var decoded DAGView
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json output does not round-trip: %v", err)
+	}
+	if len(decoded.Nodes) != 4 || len(decoded.CriticalPath) == 0 {
+		t.Errorf("decoded view = %+v", decoded)
+	}
+
+	if _, contentType, err := RenderDAGView(view, DAGFormatMermaid); err != nil || !strings.HasPrefix(contentType, "text/vnd.mermaid") {
+		t.Errorf("RenderDAGView(mermaid) = %q, %v", contentType, err)
+	}
+	if _, contentType, err := RenderDAGView(view, DAGFormatDOT); err != nil || !strings.HasPrefix(contentType, "text/vnd.graphviz") {
+		t.Errorf("RenderDAGView(dot) = %q, %v", contentType, err)
+	}
+	if _, _, err := RenderDAGView(view, "svg"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestMermaidMarkdown(t *testing.T) {
+	t.Parallel()
+
+	md := MermaidMarkdown(BuildDAGView(makeRenderDAGState(), nil, time.Now()))
+	if !strings.HasPrefix(md, "## Execution DAG\n") || !strings.Contains(md, "```mermaid\nflowchart TD\n") || !strings.HasSuffix(md, "```\n") {
+		t.Errorf("unexpected markdown:\n%s", md)
+	}
+	if !strings.Contains(md, "design → api → release") {
+		t.Errorf("markdown missing critical path summary:\n%s", md)
+	}
+}
+
+// stubQuestGetter serves quests from a map.
+type stubQuestGetter map[domain.QuestID]*domain.Quest
+
+func (s stubQuestGetter) GetQuest(_ context.Context, id domain.QuestID) (*graph.EntityState, error) {
+	q, ok := s[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &graph.EntityState{ID: string(id), Triples: q.Triples()}, nil
+}
+
+func TestLoadDAGView(t *testing.T) {
+	t.Parallel()
+
+	boardConfig := &domain.BoardConfig{Org: "local", Platform: "dev", Board: "board1"}
+	parentID := domain.QuestID(boardConfig.QuestEntityID("parent"))
+	subID := domain.QuestID(boardConfig.QuestEntityID("sub"))
+	plainID := domain.QuestID(boardConfig.QuestEntityID("plain"))
+
+	quests := stubQuestGetter{
+		parentID: {
+			ID:              parentID,
+			Title:           "Parent",
+			Status:          domain.QuestInProgress,
+			DAGExecutionID:  "exec-1",
+			DAGDefinition:   QuestDAG{Nodes: []QuestNode{{ID: "n1", Objective: "do it"}}},
+			DAGNodeQuestIDs: map[string]string{"n1": string(subID)},
+			DAGNodeStates:   map[string]string{"n1": NodeCompleted},
+		},
+		subID:   {ID: subID, Status: domain.QuestCompleted, Duration: time.Minute, TokensPrompt: 42},
+		plainID: {ID: plainID, Status: domain.QuestPosted},
+	}
+
+	view, err := LoadDAGView(context.Background(), quests, parentID)
+	if err != nil {
+		t.Fatalf("LoadDAGView() error: %v", err)
+	}
+	if len(view.Nodes) != 1 || view.Nodes[0].State != NodeCompleted || view.Nodes[0].TokensIn != 42 {
+		t.Errorf("view nodes = %+v", view.Nodes)
+	}
+
+	if _, err := LoadDAGView(context.Background(), quests, plainID); !errors.Is(err, ErrNoDAG) {
+		t.Errorf("LoadDAGView(plain) error = %v, want ErrNoDAG", err)
+	}
+}
@@ -0,0 +1,375 @@
+package questdagexec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/nats-io/nuid"
+)
+
+// =============================================================================
+// MEMBER HOT-SWAP
+// =============================================================================
+//
+// Every active node carries a heartbeat: the last time its sub-quest changed
+// status or its execution loop reported a tool result. The sweep goroutine
+// asks the event loop to check heartbeats once a minute. A node whose
+// assignee retired, sat in awaiting_clarification past ClarificationTimeout,
+// or made no progress for StallTimeout is handed to a replacement recruited
+// with the same scoring as RecruitMembers. Repeated loop failures trigger the
+// same swap from handleNodeFailed.
+//
+// The replacement keeps the node's sub-quest, so the sandbox worktree (keyed
+// by quest ID) and the clarification history on the sub-quest carry over.
+// The sub-quest records who it was handed over from so the replacement's
+// prompt tells it to build on the existing work.
+// =============================================================================
+
+// touchNode records progress on a node. Called only from the event loop
+// goroutine.
+func (s *DAGExecutionState) touchNode(nodeID string, now time.Time) {
+	if s.NodeHeartbeats == nil {
+		s.NodeHeartbeats = make(map[string]time.Time)
+	}
+	s.NodeHeartbeats[nodeID] = now
+}
+
+// stalledNode is a node whose assignee should be swapped out.
+type stalledNode struct {
+	NodeID string
+	Reason string
+}
+
+// findStalledNodes returns the active nodes whose assignee should be replaced,
+// in DAG order. retired reports whether an agent has left the game.
+//
+// Heartbeats are not persisted, so a node first seen after a restart is
+// seeded with now rather than treated as stalled.
+func findStalledNodes(dagState *DAGExecutionState, cfg *Config, now time.Time, retired func(agentID string) bool) []stalledNode {
+	var stalled []stalledNode
+	for _, node := range dagState.DAG.Nodes {
+		state := dagState.NodeStates[node.ID]
+		if state != NodeAssigned && state != NodeInProgress && state != NodeAwaitingClarification {
+			continue
+		}
+		assignee := dagState.NodeAssignees[node.ID]
+		if assignee == "" {
+			continue
+		}
+
+		if retired != nil && retired(assignee) {
+			stalled = append(stalled, stalledNode{NodeID: node.ID, Reason: SwapReasonRetired})
+			continue
+		}
+
+		last, ok := dagState.NodeHeartbeats[node.ID]
+		if !ok {
+			dagState.touchNode(node.ID, now)
+			continue
+		}
+		idle := now.Sub(last)
+
+		if state == NodeAwaitingClarification {
+			if cfg.ClarificationTimeout > 0 && idle > cfg.ClarificationTimeout {
+				stalled = append(stalled, stalledNode{NodeID: node.ID, Reason: SwapReasonClarificationTimeout})
+			}
+			continue
+		}
+		if cfg.StallTimeout > 0 && idle > cfg.StallTimeout {
+			stalled = append(stalled, stalledNode{NodeID: node.ID, Reason: SwapReasonStalled})
+		}
+	}
+	return stalled
+}
+
+// onStallSweep retries deferred dynamic nodes and checks every active DAG for
+// stalled or departed assignees and swaps them out. Called only from the event loop goroutine.
+func (c *Component) onStallSweep(ctx context.Context) {
+	now := time.Now()
+	for _, dagState := range c.dagCache {
+		if c.retryDeferredNodes(ctx, dagState) {
+			if err := c.persistDAGState(ctx, dagState); err != nil {
+				c.logger.Error("failed to persist DAG state after deferred node retry",
+					"execution_id", dagState.ExecutionID, "error", err)
+				c.errorsCount.Add(1)
+			}
+		}
+
+		// A node decomposed into a nested DAG makes progress through the
+		// nested DAG's own nodes, which are swept separately.
+		for nodeID, subQuestID := range dagState.NodeQuestIDs {
+			if c.findNestedDAG(subQuestID) != nil {
+				dagState.touchNode(nodeID, now)
+			}
+		}
+
+		stalled := findStalledNodes(dagState, c.config, now, func(agentID string) bool {
+			return c.agentRetired(ctx, agentID)
+		})
+		if len(stalled) == 0 {
+			continue
+		}
+
+		swapped := false
+		for _, s := range stalled {
+			if c.swapNodeAssignee(ctx, dagState, s.NodeID, s.Reason) {
+				swapped = true
+			}
+		}
+		if !swapped {
+			continue
+		}
+		if err := c.persistDAGState(ctx, dagState); err != nil {
+			c.logger.Error("failed to persist DAG state after member swap",
+				"execution_id", dagState.ExecutionID, "error", err)
+			c.errorsCount.Add(1)
+		}
+	}
+}
+
+// onNodeProgress refreshes the heartbeat of the node whose execution loop
+// reported progress. Called only from the event loop goroutine.
+func (c *Component) onNodeProgress(evt dagEvent) {
+	subQuestID := extractSubQuestFromExecutionLoopID(evt.LoopID)
+	if subQuestID == "" {
+		return
+	}
+	dagState := c.findDAGForSubQuest(c.subQuestEntityKey(subQuestID))
+	if dagState == nil {
+		return
+	}
+	if nodeID := c.findNodeForQuest(dagState, subQuestID); nodeID != "" {
+		dagState.touchNode(nodeID, time.Now())
+	}
+}
+
+// agentRetired reports whether the agent has retired. Lookup failures count
+// as not retired so a flaky read never triggers a swap.
+func (c *Component) agentRetired(ctx context.Context, agentID string) bool {
+	if c.graph == nil {
+		return false
+	}
+	entity, err := c.graph.GetAgent(ctx, domain.AgentID(agentID))
+	if err != nil {
+		return false
+	}
+	agent := agentprogression.AgentFromEntityState(entity)
+	return agent != nil && agent.Status == domain.AgentRetired
+}
+
+// swapNodeAssignee hands a node to a freshly recruited replacement. The
+// departed member's loop is cancelled, the sub-quest is marked as handed
+// over and reposted, and the replacement claims it. Returns false when no
+// swap happened; the node keeps its assignee and the next sweep retries.
+//
+// Called only from the event loop goroutine.
+func (c *Component) swapNodeAssignee(ctx context.Context, dagState *DAGExecutionState, nodeID, reason string) bool {
+	pc := c.resolvePartyCoord()
+	qb := c.resolveQuestBoard()
+	agents := c.idleAgentLister()
+	if pc == nil || qb == nil || agents == nil {
+		c.logger.Warn("cannot swap DAG node assignee — dependencies unavailable",
+			"execution_id", dagState.ExecutionID, "node_id", nodeID, "reason", reason)
+		return false
+	}
+
+	departed := dagState.NodeAssignees[nodeID]
+	subQuestID := dagState.NodeQuestIDs[nodeID]
+
+	replacement, err := RecruitReplacement(ctx, dagState, nodeID, reason, ReplacementDeps{
+		Agents:   agents,
+		Replacer: pc,
+	})
+	if err != nil {
+		c.logger.Warn("failed to recruit replacement for DAG node",
+			"execution_id", dagState.ExecutionID,
+			"node_id", nodeID,
+			"departed", departed,
+			"reason", reason,
+			"error", err)
+		return false
+	}
+
+	c.handOverSubQuest(ctx, subQuestID, departed)
+
+	if err := qb.RepostForRetry(ctx, domain.QuestID(subQuestID)); err != nil {
+		c.logger.Error("failed to repost sub-quest for member swap",
+			"execution_id", dagState.ExecutionID,
+			"node_id", nodeID,
+			"sub_quest_id", subQuestID,
+			"error", err)
+		c.errorsCount.Add(1)
+	}
+
+	if reason != SwapReasonRetired {
+		c.createSwapReviewEntity(ctx, dagState, nodeID, domain.AgentID(departed), reason)
+	}
+
+	now := time.Now()
+	dagState.Swaps = append(dagState.Swaps, NodeSwap{
+		NodeID:     nodeID,
+		SubQuestID: subQuestID,
+		From:       departed,
+		To:         string(replacement),
+		Reason:     reason,
+		SwappedAt:  now,
+	})
+	delete(dagState.NodeLoopFailures, nodeID)
+
+	c.logger.Info("swapped DAG node assignee",
+		"execution_id", dagState.ExecutionID,
+		"node_id", nodeID,
+		"sub_quest_id", subQuestID,
+		"departed", departed,
+		"replacement", replacement,
+		"reason", reason)
+
+	if err := qb.ClaimAndStartForParty(ctx, domain.QuestID(subQuestID), domain.PartyID(dagState.PartyID), replacement); err != nil {
+		// The replacement is in the party; let normal assignment retry the node.
+		c.logger.Warn("replacement failed to claim sub-quest — reassigning node",
+			"execution_id", dagState.ExecutionID,
+			"node_id", nodeID,
+			"replacement", replacement,
+			"error", err)
+		dagState.NodeStates[nodeID] = NodePending
+		delete(dagState.NodeAssignees, nodeID)
+		c.promoteReadyNodes(dagState)
+		c.assignReadyNodes(ctx, dagState)
+		return true
+	}
+
+	dagState.NodeStates[nodeID] = NodeAssigned
+	dagState.NodeAssignees[nodeID] = string(replacement)
+	dagState.touchNode(nodeID, now)
+	return true
+}
+
+// handOverSubQuest cancels the departed member's loop and records the
+// handover on the sub-quest so the replacement's prompt surfaces the prior
+// work left in the worktree.
+func (c *Component) handOverSubQuest(ctx context.Context, subQuestID, departed string) {
+	if c.graph == nil || subQuestID == "" {
+		return
+	}
+	entity, err := c.graph.GetQuest(ctx, domain.QuestID(subQuestID))
+	if err != nil {
+		c.logger.Warn("failed to load sub-quest for handover",
+			"sub_quest_id", subQuestID, "error", err)
+		return
+	}
+	quest := domain.QuestFromEntityState(entity)
+	if quest == nil {
+		return
+	}
+
+	if quest.LoopID != "" {
+		c.sendCancelSignal(ctx, quest.LoopID)
+	}
+
+	quest.DAGHandoverFrom = departed
+	if err := c.graph.EmitEntityUpdate(ctx, quest, "quest.dag.handover"); err != nil {
+		c.logger.Warn("failed to record handover on sub-quest",
+			"sub_quest_id", subQuestID, "error", err)
+		c.errorsCount.Add(1)
+	}
+}
+
+// createSwapReviewEntity persists a PeerReview entity recording that the
+// departed member was swapped off a node. Ratings are synthetic: the member
+// did not finish, but the node was not failed on their watch either, so the
+// review is scored like a failed node with the swap reason as explanation.
+// Retirements are not reviewed — leaving the game is not a performance issue.
+func (c *Component) createSwapReviewEntity(ctx context.Context, dagState *DAGExecutionState, nodeID string, memberID domain.AgentID, reason string) {
+	if c.graph == nil || c.boardConfig == nil {
+		return
+	}
+
+	subQuestID := dagState.NodeQuestIDs[nodeID]
+	leaderID := domain.AgentID(c.findLeadAgentID(dagState))
+	if memberID == "" || leaderID == "" {
+		return
+	}
+
+	reviewInstance := "pr-" + nuid.Next()
+	reviewID := domain.PeerReviewID(c.boardConfig.PeerReviewEntityID(reviewInstance))
+
+	now := time.Now()
+	ratings := domain.ReviewRatings{Q1: 2, Q2: 2, Q3: 2}
+	explanation := fmt.Sprintf("Replaced on DAG node %s (sub-quest %q): %s.", nodeID, subQuestID, swapReasonText(reason))
+
+	var partyIDPtr *domain.PartyID
+	if dagState.PartyID != "" {
+		pid := domain.PartyID(dagState.PartyID)
+		partyIDPtr = &pid
+	}
+
+	pr := &domain.PeerReview{
+		ID:         reviewID,
+		Status:     domain.PeerReviewCompleted,
+		QuestID:    domain.QuestID(subQuestID),
+		PartyID:    partyIDPtr,
+		LeaderID:   leaderID,
+		MemberID:   memberID,
+		IsSoloTask: false,
+		LeaderReview: &domain.ReviewSubmission{
+			ReviewerID:  leaderID,
+			RevieweeID:  memberID,
+			Direction:   domain.ReviewDirectionLeaderToMember,
+			Ratings:     ratings,
+			Explanation: explanation,
+			SubmittedAt: now,
+		},
+		LeaderAvgRating: ratings.Average(),
+		CreatedAt:       now,
+		CompletedAt:     &now,
+	}
+
+	if err := c.graph.EmitEntity(ctx, pr, domain.PredicateReviewCompleted); err != nil {
+		c.logger.Error("failed to emit peer review entity for swapped member",
+			"execution_id", dagState.ExecutionID,
+			"node_id", nodeID,
+			"review_id", reviewID,
+			"error", err)
+		c.errorsCount.Add(1)
+	}
+}
+
+// swapReasonText renders a swap reason for review explanations.
+func swapReasonText(reason string) string {
+	switch reason {
+	case SwapReasonStalled:
+		return "made no progress before the stall timeout"
+	case SwapReasonClarificationTimeout:
+		return "waited on a clarification past the timeout"
+	case SwapReasonRepeatedFailures:
+		return "execution loop failed repeatedly"
+	default:
+		return reason
+	}
+}
+
+// idleAgentLister returns the source of replacement candidates, or nil when
+// none is available.
+func (c *Component) idleAgentLister() AgentLister {
+	if c.agents != nil {
+		return c.agents
+	}
+	if c.graph == nil {
+		return nil
+	}
+	return &graphAgentLister{graph: c.graph}
+}
+
+// extractSubQuestFromExecutionLoopID extracts the sub-quest entity ID from a
+// questbridge execution loop ID ("quest-{entity-id-with-dashes}-{nuid}").
+func extractSubQuestFromExecutionLoopID(loopID string) string {
+	trimmed, ok := strings.CutPrefix(loopID, "quest-")
+	if !ok {
+		return ""
+	}
+	return entityIDFromLoopToken(trimmed)
+}
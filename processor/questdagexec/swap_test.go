@@ -0,0 +1,260 @@
+package questdagexec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semdragons/processor/partycoord"
+)
+
+// makeSwapDAGState builds a two-node DAG with both nodes in progress.
+func makeSwapDAGState() *DAGExecutionState {
+	dagState := makeFullDAGState("exec-swap", "parent-swap", "party-swap", []QuestNode{
+		{ID: "n1", Objective: "first"},
+		{ID: "n2", Objective: "second"},
+	})
+	dagState.NodeQuestIDs["n1"] = "local.dev.game.board1.quest.n1"
+	dagState.NodeQuestIDs["n2"] = "local.dev.game.board1.quest.n2"
+	dagState.NodeStates["n1"] = NodeInProgress
+	dagState.NodeStates["n2"] = NodeInProgress
+	dagState.NodeAssignees["n1"] = "agent-a"
+	dagState.NodeAssignees["n2"] = "agent-b"
+	return dagState
+}
+
+func TestFindStalledNodes(t *testing.T) {
+	t.Parallel()
+
+	cfg := DefaultConfig()
+	cfg.StallTimeout = 10 * time.Minute
+	cfg.ClarificationTimeout = 5 * time.Minute
+	now := time.Now()
+
+	t.Run("seeds missing heartbeats", func(t *testing.T) {
+		t.Parallel()
+		dagState := makeSwapDAGState()
+		if got := findStalledNodes(dagState, &cfg, now, nil); len(got) != 0 {
+			t.Fatalf("findStalledNodes() = %v, want none on first sight", got)
+		}
+		if !dagState.NodeHeartbeats["n1"].Equal(now) {
+			t.Errorf("heartbeat n1 = %v, want seeded to now", dagState.NodeHeartbeats["n1"])
+		}
+	})
+
+	t.Run("stalled and clarification timeout", func(t *testing.T) {
+		t.Parallel()
+		dagState := makeSwapDAGState()
+		dagState.NodeStates["n2"] = NodeAwaitingClarification
+		dagState.touchNode("n1", now.Add(-11*time.Minute))
+		dagState.touchNode("n2", now.Add(-6*time.Minute))
+
+		got := findStalledNodes(dagState, &cfg, now, nil)
+		want := []stalledNode{
+			{NodeID: "n1", Reason: SwapReasonStalled},
+			{NodeID: "n2", Reason: SwapReasonClarificationTimeout},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("findStalledNodes() = %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("stalled[%d] = %v, want %v", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("recent progress is not stalled", func(t *testing.T) {
+		t.Parallel()
+		dagState := makeSwapDAGState()
+		dagState.touchNode("n1", now.Add(-time.Minute))
+		dagState.touchNode("n2", now.Add(-9*time.Minute))
+		if got := findStalledNodes(dagState, &cfg, now, nil); len(got) != 0 {
+			t.Errorf("findStalledNodes() = %v, want none", got)
+		}
+	})
+
+	t.Run("retired assignee swapped immediately", func(t *testing.T) {
+		t.Parallel()
+		dagState := makeSwapDAGState()
+		dagState.touchNode("n1", now)
+		dagState.touchNode("n2", now)
+		got := findStalledNodes(dagState, &cfg, now, func(agentID string) bool { return agentID == "agent-b" })
+		if len(got) != 1 || got[0] != (stalledNode{NodeID: "n2", Reason: SwapReasonRetired}) {
+			t.Errorf("findStalledNodes() = %v, want n2 retired", got)
+		}
+	})
+
+	t.Run("zero timeouts disable detection", func(t *testing.T) {
+		t.Parallel()
+		disabled := DefaultConfig()
+		disabled.StallTimeout = 0
+		disabled.ClarificationTimeout = 0
+		dagState := makeSwapDAGState()
+		dagState.NodeStates["n2"] = NodeAwaitingClarification
+		dagState.touchNode("n1", now.Add(-time.Hour))
+		dagState.touchNode("n2", now.Add(-time.Hour))
+		if got := findStalledNodes(dagState, &disabled, now, nil); len(got) != 0 {
+			t.Errorf("findStalledNodes() = %v, want none", got)
+		}
+	})
+
+	t.Run("inactive nodes ignored", func(t *testing.T) {
+		t.Parallel()
+		dagState := makeSwapDAGState()
+		dagState.NodeStates["n1"] = NodePendingReview
+		dagState.NodeStates["n2"] = NodeCompleted
+		dagState.touchNode("n1", now.Add(-time.Hour))
+		dagState.touchNode("n2", now.Add(-time.Hour))
+		if got := findStalledNodes(dagState, &cfg, now, nil); len(got) != 0 {
+			t.Errorf("findStalledNodes() = %v, want none", got)
+		}
+	})
+}
+
+func TestHandleNodeFailedSwapsAfterRepeatedFailures(t *testing.T) {
+	t.Parallel()
+
+	qb := &mockQuestBoardRef{}
+	pc := &mockPartyCoordRef{parties: map[domain.PartyID]*partycoord.Party{
+		"party-swap": {ID: "party-swap", Lead: "agent-lead"},
+	}}
+	c := newTestComponent(qb, pc)
+	c.config.SwapAfterFailures = 2
+	c.agents = &mockAgentLister{agents: []*agentprogression.Agent{
+		makeAgent("agent-b", 1),
+		makeAgent("agent-new", 1),
+	}}
+
+	dagState := makeSwapDAGState()
+	c.indexDAGState(dagState)
+	ctx := context.Background()
+
+	// First failure retries the same member.
+	c.handleNodeFailed(ctx, dagState, "n1")
+	if len(pc.replaceCalls) != 0 {
+		t.Fatalf("expected no swap after first failure, got %d", len(pc.replaceCalls))
+	}
+
+	// The retry reassigns n1 to a party member; restore the original assignee
+	// to model the same member failing again.
+	dagState.NodeStates["n1"] = NodeInProgress
+	dagState.NodeAssignees["n1"] = "agent-a"
+
+	c.handleNodeFailed(ctx, dagState, "n1")
+	if len(pc.replaceCalls) != 1 {
+		t.Fatalf("expected 1 swap after second failure, got %d", len(pc.replaceCalls))
+	}
+	call := pc.replaceCalls[0]
+	if call.departed != "agent-a" || call.replacement != "agent-new" || call.reason != SwapReasonRepeatedFailures {
+		t.Errorf("replace call = %+v", call)
+	}
+
+	if got := dagState.NodeAssignees["n1"]; got != "agent-new" {
+		t.Errorf("assignee = %q, want agent-new", got)
+	}
+	if got := dagState.NodeStates["n1"]; got != NodeAssigned {
+		t.Errorf("state = %q, want %q", got, NodeAssigned)
+	}
+	if len(dagState.Swaps) != 1 || dagState.Swaps[0].From != "agent-a" || dagState.Swaps[0].To != "agent-new" {
+		t.Errorf("swaps = %+v", dagState.Swaps)
+	}
+	if dagState.NodeLoopFailures["n1"] != 0 {
+		t.Errorf("loop failures = %d, want reset to 0", dagState.NodeLoopFailures["n1"])
+	}
+	if dagState.NodeRetries["n1"] != 0 {
+		t.Errorf("retries = %d, want 0", dagState.NodeRetries["n1"])
+	}
+
+	last := qb.claimAndStartCalls[len(qb.claimAndStartCalls)-1]
+	if last.assignedTo != "agent-new" || last.questID != "local.dev.game.board1.quest.n1" {
+		t.Errorf("last claim = %+v, want replacement claiming n1", last)
+	}
+	if len(qb.repostCalls) != 2 {
+		t.Errorf("repost calls = %d, want 2 (retry + swap)", len(qb.repostCalls))
+	}
+}
+
+func TestSwapNodeAssigneeNoCandidateKeepsAssignee(t *testing.T) {
+	t.Parallel()
+
+	qb := &mockQuestBoardRef{}
+	pc := &mockPartyCoordRef{}
+	c := newTestComponent(qb, pc)
+	c.agents = &mockAgentLister{}
+
+	dagState := makeSwapDAGState()
+	if c.swapNodeAssignee(context.Background(), dagState, "n1", SwapReasonStalled) {
+		t.Fatal("swapNodeAssignee() = true, want false without candidates")
+	}
+	if dagState.NodeAssignees["n1"] != "agent-a" || dagState.NodeStates["n1"] != NodeInProgress {
+		t.Errorf("node changed: assignee=%q state=%q", dagState.NodeAssignees["n1"], dagState.NodeStates["n1"])
+	}
+	if len(qb.repostCalls) != 0 || len(dagState.Swaps) != 0 {
+		t.Errorf("unexpected side effects: reposts=%d swaps=%d", len(qb.repostCalls), len(dagState.Swaps))
+	}
+}
+
+func TestOnNodeProgressRefreshesHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	c := newTestComponent(&mockQuestBoardRef{}, &mockPartyCoordRef{})
+	dagState := makeSwapDAGState()
+	c.indexDAGState(dagState)
+
+	c.onNodeProgress(dagEvent{
+		Type:   dagEventNodeProgress,
+		LoopID: "quest-local-dev-game-board1-quest-n2-ABCDEFGHIJKLMNOPQRSTUV",
+	})
+
+	if _, ok := dagState.NodeHeartbeats["n2"]; !ok {
+		t.Error("expected heartbeat for n2")
+	}
+	if _, ok := dagState.NodeHeartbeats["n1"]; ok {
+		t.Error("unexpected heartbeat for n1")
+	}
+}
+
+func TestExtractSubQuestFromExecutionLoopID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		loopID string
+		want   string
+	}{
+		{"quest-local-dev-game-board1-quest-abc-ABCDEFGHIJKLMNOPQRSTUV", "local.dev.game.board1.quest.abc"},
+		{"review-local-dev-game-board1-quest-abc-ABCDEFGHIJKLMNOPQRSTUV", ""},
+		{"quest-short", ""},
+	}
+	for _, tt := range tests {
+		if got := extractSubQuestFromExecutionLoopID(tt.loopID); got != tt.want {
+			t.Errorf("extractSubQuestFromExecutionLoopID(%q) = %q, want %q", tt.loopID, got, tt.want)
+		}
+	}
+}
+
+func TestDAGStateFromQuestRestoresSwaps(t *testing.T) {
+	t.Parallel()
+
+	swappedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	quest := &domain.Quest{
+		ID:             "parent",
+		DAGExecutionID: "exec-1",
+		DAGDefinition:  map[string]any{"nodes": []any{map[string]any{"id": "n1", "objective": "x"}}},
+		DAGSwaps: []any{map[string]any{
+			"node_id": "n1", "sub_quest_id": "sq1", "from": "agent-a", "to": "agent-b",
+			"reason": SwapReasonRetired, "swapped_at": swappedAt.Format(time.RFC3339),
+		}},
+	}
+
+	state := dagStateFromQuest(quest, 2)
+	if state == nil || len(state.Swaps) != 1 {
+		t.Fatalf("swaps = %+v, want 1", state)
+	}
+	got := state.Swaps[0]
+	if got.From != "agent-a" || got.To != "agent-b" || got.Reason != SwapReasonRetired || !got.SwappedAt.Equal(swappedAt) {
+		t.Errorf("swap = %+v", got)
+	}
+}
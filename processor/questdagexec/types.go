@@ -12,7 +12,13 @@
 package questdagexec
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -69,6 +75,15 @@ const (
 	// the sub-quest (→ posted transition triggers reassignment), escalate or
 	// terminal causes parent escalation.
 	NodePendingTriage = "pending_triage"
+
+	// NodeSkipped means a conditional node's predicate did not hold over its
+	// upstream output. The sub-quest is cancelled and the node counts as a
+	// satisfied dependency; it is not a failure.
+	NodeSkipped = "skipped"
+
+	// NodeExpanded means a fan-out node has been expanded into one instance
+	// node per upstream item. It completes when every instance has.
+	NodeExpanded = "expanded"
 )
 
 // =============================================================================
@@ -89,6 +104,35 @@ type ClarificationExchange struct {
 	AskedAt  time.Time `json:"asked_at"`
 }
 
+// =============================================================================
+// MEMBER SWAP TYPES
+// =============================================================================
+
+// Reasons a node's assignee is swapped for a replacement.
+const (
+	// SwapReasonStalled: no sub-quest progress within StallTimeout.
+	SwapReasonStalled = "stalled"
+	// SwapReasonClarificationTimeout: the node sat in awaiting_clarification
+	// longer than ClarificationTimeout.
+	SwapReasonClarificationTimeout = "clarification_timeout"
+	// SwapReasonRetired: the assignee retired mid-quest.
+	SwapReasonRetired = "retired"
+	// SwapReasonRepeatedFailures: the assignee's loop failed SwapAfterFailures
+	// times in a row on the node.
+	SwapReasonRepeatedFailures = "repeated_failures"
+)
+
+// NodeSwap records one hot-swap of a node's assignee. The replacement picks
+// up the same sub-quest, so its worktree and clarification history carry over.
+type NodeSwap struct {
+	NodeID     string    `json:"node_id"`
+	SubQuestID string    `json:"sub_quest_id,omitempty"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Reason     string    `json:"reason"`
+	SwappedAt  time.Time `json:"swapped_at"`
+}
+
 // =============================================================================
 // DAG TYPES
 // =============================================================================
@@ -99,6 +143,21 @@ type ClarificationExchange struct {
 // to prevent resource exhaustion from malformed LLM-generated DAGs.
 const maxQuestDAGNodes = 20
 
+// Hierarchical decomposition limits. A node marked Decomposable may be split
+// into its own nested DAG by a Master-tier assignee, run by a sub-party. The
+// per-DAG cap above still applies at every level; these bound the tree as a
+// whole so recursive decomposition cannot grow without limit.
+const (
+	// DefaultMaxDAGDepth is the deepest DAG level that may be decomposed.
+	// Level 0 is the top-level party quest, so the default allows a party DAG
+	// plus two nested levels beneath it.
+	DefaultMaxDAGDepth = 2
+
+	// DefaultMaxTotalDAGNodes caps the sub-quests across every level of one
+	// top-level party quest.
+	DefaultMaxTotalDAGNodes = 60
+)
+
 // QuestDAG represents a directed acyclic graph of sub-quests within a party quest.
 // The lead agent proposes the DAG via the decompose_quest tool; the tool validates
 // it and the questdagexec processor drives execution reactively as deps resolve.
@@ -136,6 +195,39 @@ type QuestNode struct {
 	// DependsOn lists node IDs that must reach NodeCompleted state before
 	// this node becomes NodeReady. Zero-length means immediately ready.
 	DependsOn []string `json:"depends_on,omitempty"`
+
+	// Decomposable marks the node as eligible for nested decomposition. When
+	// a Master-tier member is assigned the sub-quest they may call
+	// decompose_quest to split it into a nested DAG run by their own
+	// sub-party, subject to the depth and total-node limits.
+	Decomposable bool `json:"decomposable,omitempty"`
+
+	// OutputSchema is an optional JSON Schema the member's structured output
+	// must satisfy. submit_work rejects non-conforming output before the
+	// lead review, and downstream nodes receive the validated fields.
+	OutputSchema map[string]any `json:"output_schema,omitempty"`
+
+	// OutputContract names a built-in schema (see OutputContractNames).
+	// Ignored when OutputSchema is set.
+	OutputContract string `json:"output_contract,omitempty"`
+
+	// Condition makes the node conditional: once its dependencies are met it
+	// runs only if the predicate holds over an upstream node's structured
+	// output, and is otherwise skipped.
+	Condition *NodeCondition `json:"condition,omitempty"`
+
+	// FanOut makes the node a template that expands at runtime into one
+	// parallel instance per item of an upstream output list. Nodes that
+	// depend on it join the instances: they wait for all of them and
+	// receive every instance's output.
+	FanOut *FanOutSpec `json:"fan_out,omitempty"`
+
+	// ExpandedFrom is set on fan-out instances to the template node's ID.
+	ExpandedFrom string `json:"expanded_from,omitempty"`
+
+	// FanOutItem is the upstream list item a fan-out instance works on. It
+	// becomes the sub-quest's input.
+	FanOutItem any `json:"fan_out_item,omitempty"`
 }
 
 // Validate checks the DAG for structural correctness. It returns an error if:
@@ -146,6 +238,8 @@ type QuestNode struct {
 //   - Any node depends on itself (self-reference)
 //   - The graph contains a cycle (detected via DFS three-color marking)
 //   - Any node has an empty Objective
+//   - Any node names an unknown output contract or an unsupported output schema
+//   - A condition or fan-out is malformed or reads a node it does not depend on
 //
 // All returned errors include the offending node ID for debuggability.
 // The algorithm is ported directly from semspec/tools/decompose/types.go.
@@ -171,6 +265,18 @@ func (d *QuestDAG) Validate() error {
 		if n.Objective == "" {
 			return fmt.Errorf("node %q: objective must not be empty", n.ID)
 		}
+		schema, err := n.ResolveOutputSchema()
+		if err != nil {
+			return fmt.Errorf("node %q: %w", n.ID, err)
+		}
+		if schema != nil {
+			if err := checkSchema(schema, "output_schema"); err != nil {
+				return fmt.Errorf("node %q: %w", n.ID, err)
+			}
+		}
+		if err := n.validateDynamic(nodeIndex); err != nil {
+			return fmt.Errorf("node %q: %w", n.ID, err)
+		}
 		for _, dep := range n.DependsOn {
 			if dep == n.ID {
 				return fmt.Errorf("node %q depends on itself", n.ID)
@@ -226,13 +332,486 @@ func (d *QuestDAG) Validate() error {
 	return nil
 }
 
+// CheckNestedBudget validates a nested decomposition against the hierarchy
+// limits. level is the DAG level of the quest being decomposed (0 for the
+// top-level party quest), existingNodes is the number of sub-quests already
+// posted across the whole tree, and newNodes is the size of the proposed DAG.
+// Non-positive limits fall back to the package defaults.
+func CheckNestedBudget(level, existingNodes, newNodes, maxDepth, maxTotal int) error {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDAGDepth
+	}
+	if level > maxDepth {
+		return fmt.Errorf("dag nesting depth exceeded (level %d > max %d)", level, maxDepth)
+	}
+	return CheckNodeBudget(existingNodes, newNodes, maxTotal)
+}
+
+// CheckNodeBudget validates that newNodes more sub-quests fit in the tree's
+// node budget. A non-positive maxTotal falls back to DefaultMaxTotalDAGNodes.
+func CheckNodeBudget(existingNodes, newNodes, maxTotal int) error {
+	if maxTotal <= 0 {
+		maxTotal = DefaultMaxTotalDAGNodes
+	}
+	if existingNodes+newNodes > maxTotal {
+		return fmt.Errorf("dag node budget exceeded (%d existing + %d new > %d)", existingNodes, newNodes, maxTotal)
+	}
+	return nil
+}
+
+// =============================================================================
+// DAG AMENDMENTS
+// =============================================================================
+
+// Amendment status values recorded in DAGExecutionState.Amendments.
+const (
+	// AmendmentApplied means the amendment was validated and applied.
+	AmendmentApplied = "applied"
+
+	// AmendmentRejected means the amendment failed validation against the
+	// live DAG state, or the per-DAG amendment limit was reached.
+	AmendmentRejected = "rejected"
+
+	// AmendmentDenied means the DM denied the amendment.
+	AmendmentDenied = "denied"
+)
+
+// DefaultMaxAmendments is how many amendments the lead may propose per DAG
+// execution when the config leaves MaxAmendments unset.
+const DefaultMaxAmendments = 3
+
+// DAGAmendment is a lead's mid-execution re-plan of a party quest DAG,
+// proposed via the amend_dag tool. Only nodes that have not started
+// (NodePending or NodeReady) may be removed, edited, or rewired; completed
+// and in-flight work is never touched. Every proposal is recorded on the
+// parent quest, whether applied or not, as the DAG's amendment history.
+type DAGAmendment struct {
+	// Reason explains why the plan changed. Required.
+	Reason string `json:"reason"`
+
+	// Add lists new nodes. IDs must not collide with existing nodes; their
+	// DependsOn may reference any node, including completed ones.
+	Add []QuestNode `json:"add,omitempty"`
+
+	// Remove lists IDs of unstarted nodes to drop from the DAG.
+	Remove []string `json:"remove,omitempty"`
+
+	// Update lists edits to unstarted nodes.
+	Update []NodeAmendment `json:"update,omitempty"`
+
+	// AmendedBy is the lead agent that proposed the amendment.
+	AmendedBy string `json:"amended_by,omitempty"`
+
+	// AmendedAt is when the amendment was processed.
+	AmendedAt time.Time `json:"amended_at"`
+
+	// Status is AmendmentApplied, AmendmentRejected, or AmendmentDenied.
+	Status string `json:"status,omitempty"`
+
+	// Error explains a rejection or denial.
+	Error string `json:"error,omitempty"`
+}
+
+// NodeAmendment edits an unstarted node. Empty or nil fields are left
+// unchanged; a non-nil empty DependsOn clears the node's dependencies.
+type NodeAmendment struct {
+	ID         string   `json:"id"`
+	Objective  string   `json:"objective,omitempty"`
+	Acceptance []string `json:"acceptance,omitempty"`
+	DependsOn  []string `json:"depends_on"`
+}
+
+// amendableNode reports whether a node in the given state may be amended.
+func amendableNode(state string) bool {
+	return state == NodePending || state == NodeReady
+}
+
+// AmendDAG applies an amendment to copies of the DAG and its node states and
+// returns them. It refuses changes to nodes that have started, re-runs
+// QuestDAG.Validate on the result, and recomputes the ready frontier: every
+// unstarted node is re-gated on its (possibly rewired) dependencies, and
+// added nodes start pending. The inputs are never modified.
+func AmendDAG(dag QuestDAG, nodeStates map[string]string, am DAGAmendment) (QuestDAG, map[string]string, error) {
+	if am.Reason == "" {
+		return QuestDAG{}, nil, fmt.Errorf("amendment reason must not be empty")
+	}
+	if len(am.Add) == 0 && len(am.Remove) == 0 && len(am.Update) == 0 {
+		return QuestDAG{}, nil, fmt.Errorf("amendment must add, remove, or update at least one node")
+	}
+
+	index := make(map[string]int, len(dag.Nodes))
+	for i, n := range dag.Nodes {
+		index[n.ID] = i
+	}
+
+	removed := make(map[string]bool, len(am.Remove))
+	for _, id := range am.Remove {
+		if _, ok := index[id]; !ok {
+			return QuestDAG{}, nil, fmt.Errorf("cannot remove unknown node %q", id)
+		}
+		if !amendableNode(nodeStates[id]) {
+			return QuestDAG{}, nil, fmt.Errorf("cannot remove node %q in state %q", id, nodeStates[id])
+		}
+		removed[id] = true
+	}
+
+	nodes := make([]QuestNode, len(dag.Nodes))
+	copy(nodes, dag.Nodes)
+	for _, u := range am.Update {
+		i, ok := index[u.ID]
+		if !ok {
+			return QuestDAG{}, nil, fmt.Errorf("cannot update unknown node %q", u.ID)
+		}
+		if removed[u.ID] {
+			return QuestDAG{}, nil, fmt.Errorf("node %q is both updated and removed", u.ID)
+		}
+		if !amendableNode(nodeStates[u.ID]) {
+			return QuestDAG{}, nil, fmt.Errorf("cannot update node %q in state %q", u.ID, nodeStates[u.ID])
+		}
+		if u.Objective != "" {
+			nodes[i].Objective = u.Objective
+		}
+		if u.Acceptance != nil {
+			nodes[i].Acceptance = u.Acceptance
+		}
+		if u.DependsOn != nil {
+			nodes[i].DependsOn = u.DependsOn
+		}
+	}
+
+	amended := QuestDAG{Nodes: make([]QuestNode, 0, len(nodes)+len(am.Add))}
+	for _, n := range nodes {
+		if !removed[n.ID] {
+			amended.Nodes = append(amended.Nodes, n)
+		}
+	}
+	for _, n := range am.Add {
+		if n.ID == "" {
+			return QuestDAG{}, nil, fmt.Errorf("added node must have an id")
+		}
+		if _, exists := index[n.ID]; exists {
+			return QuestDAG{}, nil, fmt.Errorf("added node %q collides with an existing node", n.ID)
+		}
+		amended.Nodes = append(amended.Nodes, n)
+	}
+
+	if err := amended.Validate(); err != nil {
+		return QuestDAG{}, nil, fmt.Errorf("amended dag is invalid: %w", err)
+	}
+
+	states := make(map[string]string, len(amended.Nodes))
+	for _, n := range amended.Nodes {
+		state, ok := nodeStates[n.ID]
+		if !ok || amendableNode(state) {
+			state = NodePending
+		}
+		states[n.ID] = state
+	}
+	for _, id := range DAGReadyNodes(amended, states) {
+		states[id] = NodeReady
+	}
+	return amended, states, nil
+}
+
+// =============================================================================
+// CONDITIONAL AND FAN-OUT NODES
+// =============================================================================
+
+// Condition operators for NodeCondition.Op.
+const (
+	ConditionEquals    = "equals"
+	ConditionNotEquals = "not_equals"
+	ConditionExists    = "exists"
+	ConditionNotExists = "not_exists"
+	ConditionEmpty     = "empty"
+	ConditionNotEmpty  = "not_empty"
+)
+
+// Fan-out width limits. DefaultFanOutWidth applies when a FanOutSpec leaves
+// MaxWidth unset; maxFanOutWidth caps it. Expansion is further bounded by
+// the DAG's node cap, so a fan-out never grows the DAG past maxQuestDAGNodes.
+const (
+	DefaultFanOutWidth = 5
+	maxFanOutWidth     = 10
+)
+
+// NodeCondition is a predicate over an upstream node's structured output
+// (its contract-validated typed output).
+type NodeCondition struct {
+	// Node is the upstream node whose output is tested. It must be one of
+	// the conditional node's dependencies.
+	Node string `json:"node"`
+
+	// Field is a dot-separated path into the output ("tests.failed",
+	// "apis.0.name"). Empty tests the whole output.
+	Field string `json:"field,omitempty"`
+
+	// Op is one of the Condition* operators.
+	Op string `json:"op"`
+
+	// Value is compared by ConditionEquals and ConditionNotEquals.
+	Value any `json:"value,omitempty"`
+}
+
+// FanOutSpec describes how a fan-out node expands.
+type FanOutSpec struct {
+	// From is the upstream node whose output holds the list. It must be one
+	// of the fan-out node's dependencies.
+	From string `json:"from"`
+
+	// Field is a dot-separated path to the list in From's output. Empty means
+	// the output itself is the list.
+	Field string `json:"field,omitempty"`
+
+	// MaxWidth bounds how many instances are created. Items beyond it are
+	// dropped. Defaults to DefaultFanOutWidth.
+	MaxWidth int `json:"max_width,omitempty"`
+}
+
+// validateDynamic checks a node's condition and fan-out spec against the
+// DAG's node index.
+func (n QuestNode) validateDynamic(nodeIndex map[string]struct{}) error {
+	if n.Condition != nil && n.FanOut != nil {
+		return fmt.Errorf("node cannot be both conditional and fan-out")
+	}
+	if c := n.Condition; c != nil {
+		if !slices.Contains(n.DependsOn, c.Node) {
+			return fmt.Errorf("condition reads node %q, which is not a dependency", c.Node)
+		}
+		switch c.Op {
+		case ConditionEquals, ConditionNotEquals, ConditionExists,
+			ConditionNotExists, ConditionEmpty, ConditionNotEmpty:
+		default:
+			return fmt.Errorf("unknown condition op %q", c.Op)
+		}
+	}
+	if f := n.FanOut; f != nil {
+		if !slices.Contains(n.DependsOn, f.From) {
+			return fmt.Errorf("fan_out reads node %q, which is not a dependency", f.From)
+		}
+		if f.MaxWidth < 0 || f.MaxWidth > maxFanOutWidth {
+			return fmt.Errorf("fan_out max_width must be between 0 (default %d) and %d", DefaultFanOutWidth, maxFanOutWidth)
+		}
+	}
+	if n.ExpandedFrom != "" {
+		if _, ok := nodeIndex[n.ExpandedFrom]; !ok {
+			return fmt.Errorf("expanded from unknown node %q", n.ExpandedFrom)
+		}
+	}
+	return nil
+}
+
+// Width returns the fan-out's effective maximum width.
+func (f FanOutSpec) Width() int {
+	if f.MaxWidth <= 0 {
+		return DefaultFanOutWidth
+	}
+	return min(f.MaxWidth, maxFanOutWidth)
+}
+
+// EvaluateCondition reports whether cond holds over an upstream output.
+// A missing output or field fails every operator except ConditionNotExists
+// and ConditionEmpty, so a condition on a skipped node skips too.
+func EvaluateCondition(cond NodeCondition, output any) bool {
+	value, found := lookupField(output, cond.Field)
+	switch cond.Op {
+	case ConditionExists:
+		return found
+	case ConditionNotExists:
+		return !found
+	case ConditionEmpty:
+		return !found || isEmptyValue(value)
+	case ConditionNotEmpty:
+		return found && !isEmptyValue(value)
+	case ConditionEquals:
+		return found && reflect.DeepEqual(value, cond.Value)
+	case ConditionNotEquals:
+		return found && !reflect.DeepEqual(value, cond.Value)
+	}
+	return false
+}
+
+// FanOutItems returns the list a fan-out expands over, read from the
+// upstream output at spec.Field.
+func FanOutItems(spec FanOutSpec, output any) ([]any, error) {
+	value, found := lookupField(output, spec.Field)
+	if !found {
+		return nil, fmt.Errorf("node %q has no output at %q", spec.From, spec.Field)
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("node %q output at %q is %T, not a list", spec.From, spec.Field, value)
+	}
+	return items, nil
+}
+
+// ExpandFanOut returns copies of the DAG and node states with the template
+// node expanded into one instance per item. Instances inherit the
+// template's dependencies, skills, difficulty, acceptance and output
+// contract, and start ready; the template moves to NodeExpanded. The number
+// of instances is bounded by the spec's width and the DAG's node cap; the
+// returned instances say how many were actually created.
+func ExpandFanOut(dag QuestDAG, nodeStates map[string]string, templateID string, items []any) (QuestDAG, map[string]string, []QuestNode, error) {
+	var template *QuestNode
+	index := make(map[string]struct{}, len(dag.Nodes))
+	for i := range dag.Nodes {
+		index[dag.Nodes[i].ID] = struct{}{}
+		if dag.Nodes[i].ID == templateID {
+			template = &dag.Nodes[i]
+		}
+	}
+	if template == nil || template.FanOut == nil {
+		return QuestDAG{}, nil, nil, fmt.Errorf("node %q is not a fan-out node", templateID)
+	}
+
+	width := min(len(items), template.FanOut.Width(), maxQuestDAGNodes-len(dag.Nodes))
+	if width <= 0 {
+		return QuestDAG{}, nil, nil, fmt.Errorf("no room to expand node %q (%d items, %d nodes)", templateID, len(items), len(dag.Nodes))
+	}
+
+	instances := make([]QuestNode, 0, width)
+	for i, item := range items[:width] {
+		id := fmt.Sprintf("%s#%d", templateID, i+1)
+		if _, exists := index[id]; exists {
+			return QuestDAG{}, nil, nil, fmt.Errorf("fan-out instance %q collides with an existing node", id)
+		}
+		instances = append(instances, QuestNode{
+			ID:             id,
+			Objective:      fanOutObjective(template.Objective, item, i, width),
+			Skills:         template.Skills,
+			Difficulty:     template.Difficulty,
+			Acceptance:     template.Acceptance,
+			DependsOn:      slices.Clone(template.DependsOn),
+			Decomposable:   template.Decomposable,
+			OutputSchema:   template.OutputSchema,
+			OutputContract: template.OutputContract,
+			ExpandedFrom:   templateID,
+			FanOutItem:     item,
+		})
+	}
+
+	expanded := QuestDAG{Nodes: make([]QuestNode, 0, len(dag.Nodes)+len(instances))}
+	expanded.Nodes = append(expanded.Nodes, dag.Nodes...)
+	expanded.Nodes = append(expanded.Nodes, instances...)
+
+	states := maps.Clone(nodeStates)
+	states[templateID] = NodeExpanded
+	for _, inst := range instances {
+		states[inst.ID] = NodeReady
+	}
+	return expanded, states, instances, nil
+}
+
+// FanOutInstances returns the IDs of the instances expanded from a node, in
+// creation order. Empty for nodes that are not expanded fan-outs.
+func (d QuestDAG) FanOutInstances(nodeID string) []string {
+	var ids []string
+	for _, n := range d.Nodes {
+		if n.ExpandedFrom == nodeID {
+			ids = append(ids, n.ID)
+		}
+	}
+	return ids
+}
+
+// DependencyNodes resolves a dependency to the nodes that produced its
+// output: a fan-out's instances, or the node itself. Join nodes use it to
+// collect every instance's output.
+func (d QuestDAG) DependencyNodes(nodeID string) []string {
+	if instances := d.FanOutInstances(nodeID); len(instances) > 0 {
+		return instances
+	}
+	return []string{nodeID}
+}
+
+// hasExpansions reports whether any fan-out has been expanded, meaning the
+// DAG definition differs from the one the lead proposed.
+func (d QuestDAG) hasExpansions() bool {
+	for _, n := range d.Nodes {
+		if n.ExpandedFrom != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// fanOutObjective appends the instance's item to the template objective.
+func fanOutObjective(objective string, item any, i, width int) string {
+	rendered, ok := item.(string)
+	if !ok {
+		data, err := json.Marshal(item)
+		if err != nil {
+			rendered = fmt.Sprintf("%v", item)
+		} else {
+			rendered = string(data)
+		}
+	}
+	return fmt.Sprintf("%s\n\nItem %d of %d: %s", objective, i+1, width, rendered)
+}
+
+// lookupField walks a dot-separated path through JSON-decoded data. Numeric
+// segments index into lists. An empty path returns the value itself.
+func lookupField(value any, path string) (any, bool) {
+	if value == nil {
+		return nil, false
+	}
+	if path == "" {
+		return value, true
+	}
+	for _, seg := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[seg]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// isEmptyValue reports whether a JSON value is null, false, zero, or an
+// empty string, list or object.
+func isEmptyValue(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return x == ""
+	case bool:
+		return !x
+	case float64:
+		return x == 0
+	case []any:
+		return len(x) == 0
+	case map[string]any:
+		return len(x) == 0
+	}
+	return false
+}
+
+// dependencySatisfied reports whether a dependency in the given state lets
+// downstream nodes proceed. Skipped nodes count: skipping is not failure.
+func dependencySatisfied(state string) bool {
+	return state == NodeCompleted || state == NodeSkipped
+}
+
 // =============================================================================
 // READY-NODE DETECTION
 // =============================================================================
 
 // DAGReadyNodes returns the IDs of nodes that are in NodePending state and have
-// all their dependencies in NodeCompleted state. Nodes with zero dependencies are
-// immediately ready when their state is NodePending.
+// all their dependencies in NodeCompleted (or NodeSkipped) state. Nodes with
+// zero dependencies are immediately ready when their state is NodePending.
 //
 // This function is the authoritative gate for party sub-quest dispatch: the DAG
 // executor only assigns sub-quests whose dependencies have all been accepted by
@@ -245,7 +824,7 @@ func DAGReadyNodes(dag QuestDAG, nodeStates map[string]string) []string {
 		}
 		allDepsComplete := true
 		for _, dep := range node.DependsOn {
-			if nodeStates[dep] != NodeCompleted {
+			if !dependencySatisfied(nodeStates[dep]) {
 				allDepsComplete = false
 				break
 			}
@@ -291,6 +870,12 @@ type DAGExecutionState struct {
 	// Used when calling partycoord.AssignTask and partycoord.DisbandParty.
 	PartyID string `json:"party_id"`
 
+	// Level is the nesting depth of the parent quest: 0 for a top-level party
+	// quest, 1+ for a decomposable sub-quest running a nested DAG. Nested DAGs
+	// fail their parent sub-quest on terminal failure instead of escalating,
+	// so the enclosing DAG's retry and triage path handles it.
+	Level int `json:"level,omitempty"`
+
 	// QuestTitle is the parent quest title, stored for synthesis prompt context.
 	QuestTitle string `json:"quest_title,omitempty"`
 
@@ -330,4 +915,27 @@ type DAGExecutionState struct {
 	// Appended when the lead answers a clarification; injected into the
 	// member's prompt on the next dispatch so they have context for the retry.
 	NodeClarifications map[string][]ClarificationExchange `json:"node_clarifications,omitempty"`
+
+	// NodeHeartbeats records when each node last showed progress — a
+	// sub-quest transition, a tool result from its execution loop, a
+	// reassignment, or activity in a nested DAG beneath it. The stall sweep
+	// compares it against StallTimeout and ClarificationTimeout. Kept in
+	// memory only; a restarted processor starts every node's clock afresh.
+	NodeHeartbeats map[string]time.Time `json:"-"`
+
+	// NodeLoopFailures counts consecutive failed loops by each node's current
+	// assignee. Reset when the node is handed to a replacement.
+	NodeLoopFailures map[string]int `json:"node_loop_failures,omitempty"`
+
+	// Swaps is the history of hot-swapped assignees for this execution,
+	// oldest first. Persisted as quest.dag.swaps.
+	Swaps []NodeSwap `json:"swaps,omitempty"`
+
+	// Amendments is the history of amend_dag proposals for this execution,
+	// applied or not, oldest first. Persisted as quest.dag.amendments.
+	Amendments []DAGAmendment `json:"amendments,omitempty"`
+
+	// Blueprint is the ID of the blueprint the lead decomposed from, if any.
+	// The execution's outcome is recorded against it.
+	Blueprint string `json:"blueprint,omitempty"`
 }
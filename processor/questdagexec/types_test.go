@@ -367,6 +367,169 @@ func TestDAGReadyNodes(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// CheckNestedBudget tests
+// =============================================================================
+
+func TestCheckNestedBudget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		level    int
+		existing int
+		newNodes int
+		maxDepth int
+		maxTotal int
+		wantErr  bool
+	}{
+		{name: "first nested level within budget", level: 1, existing: 5, newNodes: 4},
+		{name: "deepest allowed level", level: DefaultMaxDAGDepth, existing: 10, newNodes: 3},
+		{name: "too deep", level: DefaultMaxDAGDepth + 1, existing: 1, newNodes: 1, wantErr: true},
+		{name: "budget exactly full", level: 1, existing: DefaultMaxTotalDAGNodes - 5, newNodes: 5},
+		{name: "budget exceeded", level: 1, existing: DefaultMaxTotalDAGNodes - 5, newNodes: 6, wantErr: true},
+		{name: "custom depth", level: 2, existing: 1, newNodes: 1, maxDepth: 1, wantErr: true},
+		{name: "custom total", level: 1, existing: 8, newNodes: 3, maxTotal: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := CheckNestedBudget(tt.level, tt.existing, tt.newNodes, tt.maxDepth, tt.maxTotal)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckNestedBudget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckNodeBudget(t *testing.T) {
+	t.Parallel()
+
+	if err := CheckNodeBudget(DefaultMaxTotalDAGNodes-2, 2, 0); err != nil {
+		t.Errorf("full default budget: unexpected error %v", err)
+	}
+	if err := CheckNodeBudget(DefaultMaxTotalDAGNodes-2, 3, 0); err == nil {
+		t.Error("exceeded default budget: expected error")
+	}
+	if err := CheckNodeBudget(4, 2, 5); err == nil {
+		t.Error("exceeded custom budget: expected error")
+	}
+}
+
+func TestAmendDAG(t *testing.T) {
+	t.Parallel()
+
+	// a (completed) → b (in progress); c depends on a (ready); d depends on c (pending).
+	dag := QuestDAG{Nodes: []QuestNode{
+		{ID: "a", Objective: "Design schema"},
+		{ID: "b", Objective: "Write migrations", DependsOn: []string{"a"}},
+		{ID: "c", Objective: "Build API", DependsOn: []string{"a"}},
+		{ID: "d", Objective: "Write docs", DependsOn: []string{"c"}},
+	}}
+	states := map[string]string{
+		"a": NodeCompleted,
+		"b": NodeInProgress,
+		"c": NodeReady,
+		"d": NodePending,
+	}
+
+	tests := []struct {
+		name       string
+		am         DAGAmendment
+		wantErr    string
+		wantStates map[string]string
+	}{
+		{
+			name: "add a node between ready and pending work",
+			am: DAGAmendment{
+				Reason: "API needs auth first",
+				Add:    []QuestNode{{ID: "auth", Objective: "Add auth", DependsOn: []string{"a"}}},
+				Update: []NodeAmendment{{ID: "c", DependsOn: []string{"auth"}}},
+			},
+			wantStates: map[string]string{
+				"a": NodeCompleted, "b": NodeInProgress,
+				"auth": NodeReady, "c": NodePending, "d": NodePending,
+			},
+		},
+		{
+			name: "remove pending node and clear a dependency",
+			am: DAGAmendment{
+				Reason: "docs are out of scope",
+				Remove: []string{"d"},
+				Update: []NodeAmendment{{ID: "c", Objective: "Build REST API", DependsOn: []string{}}},
+			},
+			wantStates: map[string]string{"a": NodeCompleted, "b": NodeInProgress, "c": NodeReady},
+		},
+		{
+			name:    "in-progress node cannot be updated",
+			am:      DAGAmendment{Reason: "r", Update: []NodeAmendment{{ID: "b", Objective: "x"}}},
+			wantErr: `cannot update node "b"`,
+		},
+		{
+			name:    "completed node cannot be removed",
+			am:      DAGAmendment{Reason: "r", Remove: []string{"a"}},
+			wantErr: `cannot remove node "a"`,
+		},
+		{
+			name:    "removing a dependency still in use fails validation",
+			am:      DAGAmendment{Reason: "r", Remove: []string{"c"}},
+			wantErr: "amended dag is invalid",
+		},
+		{
+			name:    "added node id collides",
+			am:      DAGAmendment{Reason: "r", Add: []QuestNode{{ID: "a", Objective: "again"}}},
+			wantErr: "collides",
+		},
+		{
+			name: "rewiring into a cycle fails validation",
+			am: DAGAmendment{Reason: "r", Update: []NodeAmendment{
+				{ID: "c", DependsOn: []string{"d"}},
+			}},
+			wantErr: "cycle",
+		},
+		{
+			name:    "reason required",
+			am:      DAGAmendment{Remove: []string{"d"}},
+			wantErr: "reason",
+		},
+		{
+			name:    "empty amendment",
+			am:      DAGAmendment{Reason: "r"},
+			wantErr: "at least one node",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			gotDAG, gotStates, err := AmendDAG(dag, states, tt.am)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("AmendDAG() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AmendDAG() unexpected error: %v", err)
+			}
+			if len(gotDAG.Nodes) != len(tt.wantStates) {
+				t.Errorf("amended DAG has %d nodes, want %d", len(gotDAG.Nodes), len(tt.wantStates))
+			}
+			for id, want := range tt.wantStates {
+				if gotStates[id] != want {
+					t.Errorf("state[%s] = %q, want %q", id, gotStates[id], want)
+				}
+			}
+		})
+	}
+
+	// Inputs are never modified.
+	if len(dag.Nodes) != 4 || len(dag.Nodes[2].DependsOn) != 1 || states["c"] != NodeReady {
+		t.Error("AmendDAG mutated its inputs")
+	}
+}
+
 // stringSlicesEqualAsSet returns true if a and b contain the same elements
 // (ignoring order). Both nil and empty slice are treated as equal.
 func stringSlicesEqualAsSet(a, b []string) bool {
@@ -0,0 +1,50 @@
+package questtools
+
+import (
+	"context"
+	"errors"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/dmapproval"
+)
+
+// DMApprovalRef is the narrow interface questtools needs from dmapproval.
+// The concrete *dmapproval.Component satisfies this interface.
+type DMApprovalRef interface {
+	RequestApproval(ctx context.Context, req domain.ApprovalRequest) (*domain.ApprovalResponse, error)
+}
+
+// dmToolApprover routes require_approval tool calls to dmapproval. The
+// component is resolved at call time so start order doesn't matter.
+type dmToolApprover struct {
+	c *Component
+}
+
+// RequestApproval implements executor.ToolCallApprover.
+func (a *dmToolApprover) RequestApproval(ctx context.Context, req domain.ApprovalRequest) (*domain.ApprovalResponse, error) {
+	ref := a.c.resolveApproval()
+	if ref == nil {
+		return nil, errors.New("dmapproval component not available")
+	}
+	if req.SessionID == "" {
+		req.SessionID = a.c.config.ApprovalSessionID
+	}
+	return ref.RequestApproval(ctx, req)
+}
+
+// resolveApproval resolves dmapproval from the ComponentRegistry at call time.
+// Returns nil when absent — require_approval calls are then refused.
+func (c *Component) resolveApproval() DMApprovalRef {
+	if c.deps.ComponentRegistry == nil {
+		return nil
+	}
+	comp := c.deps.ComponentRegistry.Component(dmapproval.ComponentName)
+	if comp == nil {
+		return nil
+	}
+	ref, ok := comp.(DMApprovalRef)
+	if !ok {
+		return nil
+	}
+	return ref
+}
@@ -12,7 +12,10 @@ import (
 	semdragons "github.com/c360studio/semdragons"
 	"github.com/c360studio/semdragons/domain"
 	"github.com/c360studio/semdragons/processor/executor"
+	"github.com/c360studio/semdragons/processor/partycoord"
 	"github.com/c360studio/semdragons/processor/questbridge"
+	"github.com/c360studio/semdragons/processor/questdagexec"
+	"github.com/c360studio/semdragons/processor/tokenbudget"
 	"github.com/c360studio/semstreams/component"
 	"github.com/c360studio/semstreams/message"
 	"github.com/nats-io/nats.go/jetstream"
@@ -35,6 +38,34 @@ type Component struct {
 	// singleton, so each consumer holds its own decoder.
 	decoder *message.Decoder
 
+	// blackboard backs the party tools and delivers member messages into
+	// tool results. Nil when the PARTY_BLACKBOARD bucket is unavailable.
+	blackboard *partycoord.BlackboardStore
+
+	// mcpClients are the connected MCP tool servers, closed on Stop.
+	mcpClients []*executor.MCPClient
+
+	// search is the web_search provider. Nil when web_search is not
+	// registered.
+	search executor.SearchProvider
+
+	// policyWatcher hot-reloads the tool-call policy from the TOOL_POLICY
+	// bucket. Nil when the bucket is unavailable.
+	policyWatcher *executor.PolicyWatcher
+
+	// tokenLedger receives the cost of priced tool calls. Nil until the
+	// API service wires the shared ledger.
+	tokenLedger *tokenbudget.TokenLedger
+
+	// approvalWg tracks tool calls waiting on DM approval so Stop() can
+	// wait for them to finish.
+	approvalWg sync.WaitGroup
+
+	// questWatch drops a quest's per-quest tool quotas once it finishes.
+	// Nil when the watch could not start.
+	questWatch     jetstream.KeyWatcher
+	questWatchDone chan struct{}
+
 	// questLoopsBucket persists explore loop mappings for crash recovery.
 	// Shared with questbridge (same bucket name). Nil before Start.
 	questLoopsBucket jetstream.KeyValue
@@ -205,14 +236,45 @@ func (c *Component) Start(ctx context.Context) error {
 			c.logger.Warn("web_search tool disabled", "reason", err.Error())
 		} else {
 			c.toolRegistry.RegisterWebSearch(sp)
+			c.search = sp
 			c.logger.Info("web_search tool registered", "provider", c.config.Search.Provider)
 		}
 	}
+	if c.config.Secrets != nil {
+		vault, err := executor.OpenSecretVault(ctx, c.deps.NATSClient, c.config.Secrets.KeyEnv)
+		if err != nil {
+			c.logger.Warn("secret placeholders disabled", "reason", err.Error())
+		} else {
+			c.toolRegistry.SetSecrets(vault)
+		}
+	}
+	if c.config.WebCache != nil {
+		cache, err := executor.OpenWebCache(ctx, c.deps.NATSClient, *c.config.WebCache)
+		if err != nil {
+			c.logger.Warn("web cache disabled", "reason", err.Error())
+		} else {
+			executor.SetWebCache(cache)
+		}
+	}
 
 	// Register graph_query tool backed by the board KV bucket.
 	gc := semdragons.NewGraphClient(c.deps.NATSClient, c.boardConfig)
 	c.toolRegistry.RegisterGraphQuery(c.buildGraphQueryFunc(gc))
 
+	// A local search index also covers web pages persisted to the graph,
+	// both those already there and those fetched from now on.
+	if index := executor.LocalSearchIndexOf(c.search); index != nil {
+		executor.SetWebContentIndex(index)
+		go func() {
+			n, err := index.IndexWebContent(ctx, gc)
+			if err != nil {
+				c.logger.Warn("local search: persisted web content not indexed", "error", err)
+				return
+			}
+			c.logger.Info("local search index loaded", "documents", index.Len(), "web_pages", n)
+		}()
+	}
+
 	// Register graph_search tool — use global registry for multi-source routing,
 	// single-URL fallback when registry is not configured.
 	if reg := questbridge.GlobalGraphSources(); reg != nil {
@@ -228,6 +290,49 @@ func (c *Component) Start(ctx context.Context) error {
 		c.toolRegistry.RegisterGraphSummary(reg)
 	}
 
+	// Register party blackboard and messaging tools backed by the
+	// PARTY_BLACKBOARD bucket.
+	if bucket, bbErr := partycoord.EnsureBlackboardBucket(ctx, c.deps.NATSClient); bbErr != nil {
+		c.logger.Warn("party tools disabled: PARTY_BLACKBOARD bucket unavailable", "error", bbErr)
+	} else {
+		c.blackboard = partycoord.NewBlackboardStore(bucket)
+		c.toolRegistry.RegisterPartyTools(c.blackboard, buildPartyMembersFunc(gc))
+	}
+
+	// Apply the configured tool-call policy before any tools are reachable.
+	if c.config.ToolPolicy != nil {
+		if err := c.toolRegistry.SetPolicy(c.config.ToolPolicy); err != nil {
+			return fmt.Errorf("tool_policy: %w", err)
+		}
+	}
+
+	if err := c.toolRegistry.SetToolLimits(c.config.ToolLimits); err != nil {
+		return err
+	}
+	if err := c.toolRegistry.SetContentSafety(c.config.ContentSafety); err != nil {
+		return err
+	}
+	if c.tokenLedger != nil {
+		c.toolRegistry.SetCostRecorder(c.tokenLedger)
+	}
+
+	// Register tools discovered from configured MCP servers.
+	if len(c.config.MCPServers) > 0 {
+		c.mcpClients = c.toolRegistry.RegisterMCPServers(ctx, c.config.MCPServers, c.logger)
+	}
+
+	// Route require_approval calls to the DM and hot-reload the policy.
+	c.toolRegistry.SetApprover(&dmToolApprover{c: c})
+	if bucket, polErr := executor.EnsurePolicyBucket(ctx, c.deps.NATSClient); polErr != nil {
+		c.logger.Warn("tool policy hot-reload disabled: TOOL_POLICY bucket unavailable", "error", polErr)
+	} else {
+		c.policyWatcher = executor.NewPolicyWatcher(bucket, c.toolRegistry, c.config.ToolPolicy, c.logger)
+		if err := c.policyWatcher.Start(ctx); err != nil {
+			c.logger.Warn("tool policy hot-reload disabled", "error", err)
+			c.policyWatcher = nil
+		}
+	}
+
 	// Register explore tool — spawns a read-only sub-agent for discovery work.
 	// Actual execution is intercepted in handleToolExecute before reaching the registry.
 	c.toolRegistry.RegisterExplore()
@@ -260,6 +365,7 @@ func (c *Component) Start(ctx context.Context) error {
 		graphClient := semdragons.NewGraphClient(c.deps.NATSClient, c.boardConfig)
 		executor.SetHTTPGraphPersist(graphClient, c.boardConfig)
 	}
+	executor.SetDAGBlueprints(questdagexec.NewBlueprintLibrary(gc))
 
 	c.startTime = time.Now()
 	c.running.Store(true)
@@ -271,6 +377,16 @@ func (c *Component) Start(ctx context.Context) error {
 		return fmt.Errorf("start tool execute consumer: %w", err)
 	}
 
+	// Failure is non-fatal: per-quest quota counts are then kept until
+	// the component restarts.
+	if watcher, err := gc.WatchEntityType(ctx, domain.EntityTypeQuest); err != nil {
+		c.logger.Warn("quest watch failed — per-quest tool quotas will not be pruned", "error", err)
+	} else {
+		c.questWatch = watcher
+		c.questWatchDone = make(chan struct{})
+		go c.processQuestWatchUpdates()
+	}
+
 	c.logger.Info("questtools component started",
 		"org", c.config.Org,
 		"platform", c.config.Platform,
@@ -288,6 +404,35 @@ func (c *Component) ToolRegistry() *executor.ToolRegistry {
 	return c.toolRegistry
 }
 
+// SetTokenLedger injects the shared token ledger that priced tool calls are
+// charged to. Takes effect immediately when the component is running.
+func (c *Component) SetTokenLedger(l *tokenbudget.TokenLedger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokenLedger = l
+	if c.toolRegistry != nil && l != nil {
+		c.toolRegistry.SetCostRecorder(l)
+	}
+}
+
+// ToolUsage returns per-tool usage counters. Nil before Start.
+func (c *Component) ToolUsage() []executor.ToolUsageStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.toolRegistry == nil {
+		return nil
+	}
+	return c.toolRegistry.Usage()
+}
+
+// SearchHealth reports the health of each provider behind a multi
+// web_search provider. Nil when web_search does not fan out.
+func (c *Component) SearchHealth() []executor.SearchProviderHealth {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return executor.SearchHealth(c.search)
+}
+
 // Stop signals the consumer goroutine and marks the component as stopped.
 func (c *Component) Stop(_ time.Duration) error {
 	c.mu.Lock()
@@ -304,6 +449,31 @@ func (c *Component) Stop(_ time.Duration) error {
 	// passed to startConsumer is cancelled by the service manager before
 	// Stop() is called, which cancels all in-flight explore contexts.
 	c.exploreWg.Wait()
+	c.approvalWg.Wait()
+
+	if c.questWatch != nil {
+		if err := c.questWatch.Stop(); err != nil {
+			c.logger.Debug("stop quest watch", "error", err)
+		}
+		select {
+		case <-c.questWatchDone:
+		case <-time.After(5 * time.Second):
+			c.logger.Warn("stop timed out waiting for quest watch")
+		}
+		c.questWatch = nil
+	}
+
+	if c.policyWatcher != nil {
+		c.policyWatcher.Stop()
+		c.policyWatcher = nil
+	}
+
+	for _, client := range c.mcpClients {
+		if err := client.Close(); err != nil {
+			c.logger.Warn("close mcp server failed", "server", client.Name(), "error", err)
+		}
+	}
+	c.mcpClients = nil
 
 	c.logger.Info("questtools component stopped")
 	return nil
@@ -320,3 +490,19 @@ func (c *Component) buildGraphQueryFunc(gc *semdragons.GraphClient) executor.Ent
 		return executor.FormatEntitySummary(entities, entityType), nil
 	}
 }
+
+// buildPartyMembersFunc returns a PartyMembersFunc that reads the party
+// entity from the board KV bucket.
+func buildPartyMembersFunc(gc *semdragons.GraphClient) executor.PartyMembersFunc {
+	return func(ctx context.Context, partyID domain.PartyID) ([]domain.AgentID, error) {
+		entity, err := gc.GetParty(ctx, partyID)
+		if err != nil {
+			return nil, err
+		}
+		party := partycoord.PartyFromEntityState(entity)
+		if party == nil {
+			return nil, fmt.Errorf("party %s not found", partyID)
+		}
+		return party.MemberIDs(), nil
+	}
+}
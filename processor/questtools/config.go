@@ -24,10 +24,35 @@ type Config struct {
 	// Search configures the web_search tool. When nil/empty provider, web_search
 	// is not registered. Supports "brave" (more providers can be added).
 	Search *executor.SearchConfig `json:"search,omitempty"`
+	// Secrets enables {{secret:name}} placeholders in http_request headers and
+	// bash env, resolved from the encrypted TOOL_SECRETS bucket. Nil disables
+	// them.
+	Secrets *executor.SecretsConfig `json:"secrets,omitempty"`
+	// WebCache shares http_request and web_search results across agents
+	// through the WEB_CACHE bucket. Nil disables caching.
+	WebCache *executor.WebCacheConfig `json:"web_cache,omitempty"`
+	// ContentSafety scans http_request, web_search and explore results for
+	// prompt-injection attempts and wraps them in untrusted-content blocks.
+	// Nil disables the stage.
+	ContentSafety *executor.ContentSafetyConfig `json:"content_safety,omitempty"`
 	// SandboxURL is the HTTP base URL for the sandbox container.
 	// When set, file/exec tools proxy through the sandbox instead of operating
 	// on the local filesystem. Example: "http://sandbox:8090"
 	SandboxURL string `json:"sandbox_url,omitempty"`
+	// MCPServers are Model Context Protocol tool servers whose tools are
+	// registered as mcp_<server>_<tool>. A server that fails to connect at
+	// startup is logged and skipped.
+	MCPServers []executor.MCPServerConfig `json:"mcp_servers,omitempty"`
+	// ToolPolicy is the tool-call policy evaluated before every call. A
+	// policy stored under "policy" in the TOOL_POLICY KV bucket replaces it
+	// at runtime; deleting the key restores this one.
+	ToolPolicy *executor.ToolPolicy `json:"tool_policy,omitempty"`
+	// ApprovalSessionID is the DM session that receives require_approval
+	// tool calls through dmapproval.
+	ApprovalSessionID string `json:"approval_session_id,omitempty"`
+	// ToolLimits sets rate limits, quotas and per-call costs by tool name or
+	// glob, overriding the defaults tools are registered with.
+	ToolLimits map[string]executor.ToolLimits `json:"tool_limits,omitempty"`
 	// ConsumerNameSuffix disambiguates multiple instances consuming the same stream.
 	ConsumerNameSuffix   string `json:"consumer_name_suffix,omitempty"`
 	DeleteConsumerOnStop bool   `json:"delete_consumer_on_stop,omitempty"`
@@ -66,6 +91,16 @@ func DefaultConfig() Config {
 	}
 }
 
+// TimeoutDuration parses Timeout as a Go duration.
+// Returns 60s on parse failure or zero/negative value.
+func (c *Config) TimeoutDuration() time.Duration {
+	d, err := time.ParseDuration(c.Timeout)
+	if err != nil || d <= 0 {
+		return 60 * time.Second
+	}
+	return d
+}
+
 // ExploreTimeoutDuration parses ExploreTimeout as a Go duration.
 // Returns 120s on parse failure or zero/negative value.
 func (c *Config) ExploreTimeoutDuration() time.Duration {
@@ -8,8 +8,11 @@ import (
 	"strings"
 	"time"
 
+	semdragons "github.com/c360studio/semdragons"
 	"github.com/c360studio/semdragons/domain"
 	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semdragons/processor/executor"
+	"github.com/c360studio/semdragons/processor/partycoord"
 	"github.com/c360studio/semstreams/agentic"
 	"github.com/c360studio/semstreams/message"
 	"github.com/c360studio/semstreams/natsclient"
@@ -114,6 +117,9 @@ func (c *Component) handleToolExecute(lifecycleCtx context.Context, msgCtx conte
 			defer exploreCancel()
 
 			result := c.handleExplore(exploreCtx, call, agent, quest)
+			// The sub-agent summarizes fetched pages, so its result is
+			// quarantined like http_request and web_search results.
+			c.toolRegistry.QuarantineResult(call, &result)
 			// Ensure Content is non-empty per the same invariant as the sync path.
 			if result.Content == "" && result.Error != "" {
 				result.Content = fmt.Sprintf("Tool error: %s", result.Error)
@@ -144,8 +150,60 @@ func (c *Component) handleToolExecute(lifecycleCtx context.Context, msgCtx conte
 		return
 	}
 
-	// Execute the tool through the registry, which enforces tier and skill gates.
-	result := c.toolRegistry.Execute(msgCtx, call, quest, agent)
+	// Calls that the policy holds for DM approval can wait minutes for a decision,
+	// far past the 30s msgCtx. Run them like explore: off the consumer
+	// goroutine, on a context derived from lifecycleCtx.
+	if c.toolRegistry.EvaluatePolicy(call, quest, agent).Effect == executor.PolicyRequireApproval {
+		c.approvalWg.Add(1)
+		go func() {
+			defer c.approvalWg.Done()
+			timeout := c.toolRegistry.Policy().ApprovalTimeoutDuration() + c.config.TimeoutDuration()
+			approvalCtx, cancel := context.WithTimeout(lifecycleCtx, timeout)
+			defer cancel()
+			c.executeAndPublish(approvalCtx, call, quest, agent)
+		}()
+		return
+	}
+
+	c.executeAndPublish(msgCtx, call, quest, agent)
+}
+
+// processQuestWatchUpdates releases per-quest tool quotas as quests finish.
+// It returns when the watch is stopped.
+func (c *Component) processQuestWatchUpdates() {
+	defer close(c.questWatchDone)
+	for entry := range c.questWatch.Updates() {
+		if entry == nil || entry.Operation() != jetstream.KeyValuePut {
+			continue
+		}
+		entityState, err := semdragons.DecodeEntityState(entry)
+		if err != nil || entityState == nil {
+			continue
+		}
+		if quest := domain.QuestFromEntityState(entityState); quest != nil && questFinished(quest) {
+			c.toolRegistry.ForgetQuest(quest.ID)
+		}
+	}
+}
+
+// questFinished reports whether quest will make no more tool calls. A failed
+// party sub-quest is not finished: questdagexec reposts it for retry.
+func questFinished(quest *domain.Quest) bool {
+	switch quest.Status {
+	case domain.QuestCompleted, domain.QuestCancelled:
+		return true
+	case domain.QuestFailed:
+		return quest.PartyID == nil
+	}
+	return false
+}
+
+// executeAndPublish runs a tool call through the registry and publishes the
+// result for the agentic loop.
+func (c *Component) executeAndPublish(ctx context.Context, call agentic.ToolCall, quest *domain.Quest, agent *agentprogression.Agent) {
+	// Execute the tool through the registry, which enforces tier, skill and
+	// policy gates.
+	result := c.toolRegistry.Execute(ctx, call, quest, agent)
 
 	// Ensure Content is non-empty. The agentic-loop converts ToolResult.Content
 	// into the ChatMessage.Content for role=tool messages. Gemini (and other
@@ -158,6 +216,10 @@ func (c *Component) handleToolExecute(lifecycleCtx context.Context, msgCtx conte
 		result.Content = "(no output)"
 	}
 
+	// Deliver messages from other party members into this loop's next
+	// iteration by appending them to the tool result.
+	c.deliverPartyMessages(ctx, &result, quest, agent)
+
 	// Classify bash commands for trajectory analytics. Since we consolidated
 	// specialized tools (run_tests, lint_check, etc.) into bash, tag the result
 	// metadata with what the command was actually doing.
@@ -201,6 +263,31 @@ func (c *Component) handleToolExecute(lifecycleCtx context.Context, msgCtx conte
 		"error", result.Error)
 }
 
+// deliverPartyMessages appends the agent's undelivered party messages to a
+// tool result and marks them delivered. The loop feeds the result back to the
+// model, so messages arrive on the recipient's next iteration and are
+// recorded in its trajectory. Terminal results are skipped: the loop ends
+// and the messages wait for the agent's next loop.
+func (c *Component) deliverPartyMessages(ctx context.Context, result *agentic.ToolResult, quest *domain.Quest, agent *agentprogression.Agent) {
+	if c.blackboard == nil || quest.PartyID == nil || agent.ID == "" || result.StopLoop {
+		return
+	}
+	msgs, err := c.blackboard.TakeInbox(ctx, *quest.PartyID, agent.ID)
+	if err != nil {
+		c.logger.Warn("failed to read party inbox",
+			"party_id", *quest.PartyID, "agent_id", agent.ID, "error", err)
+		return
+	}
+	if len(msgs) == 0 {
+		return
+	}
+	result.Content += "\n\n" + partycoord.FormatMessages(msgs)
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]any)
+	}
+	result.Metadata["party_messages"] = len(msgs)
+}
+
 // publishResult wraps a ToolResult in a BaseMessage envelope and publishes it
 // to tool.result.{callID}. The agentic-loop consumer expects BaseMessage wrapping.
 func (c *Component) publishResult(ctx context.Context, callID string, result *agentic.ToolResult) error {
@@ -248,6 +335,14 @@ func (c *Component) publishResultDetached(callID string, result *agentic.ToolRes
 //	"skills"      – []any of string → Agent.SkillProficiencies (level 1 each)
 //	"quest_id"    – string  → Quest.ID
 //	"sandbox_dir" – string  → overrides the component-level sandbox directory
+//	"output_schema" – object → Quest.DAGOutputSchema (DAG node output contract)
+//	"party_id"    – string  → Quest.PartyID (scopes the party tools)
+//	"allowed_tools" – []any of string → Quest.AllowedTools (danger allowlist)
+//	"owned_tools" – []any of string → Agent.OwnedTools (usable store items)
+//	"repo"        – string  → Quest.Repo (secret scoping)
+//	"guild_id"    – string  → Agent.Guild (tool policy matching)
+//	"difficulty"  – float64 or int → Quest.Difficulty (tool policy matching)
+//	"required_skills" – []any of string → Quest.RequiredSkills (tool policy matching)
 func (c *Component) buildContextFromMetadata(call *agentic.ToolCall) (*agentprogression.Agent, *domain.Quest) {
 	agent := &agentprogression.Agent{
 		// Default to the most-restricted tier so unidentified callers cannot
@@ -298,6 +393,56 @@ func (c *Component) buildContextFromMetadata(call *agentic.ToolCall) (*agentprog
 		quest.ID = domain.QuestID(id)
 	}
 
+	if schema, ok := call.Metadata["output_schema"].(map[string]any); ok {
+		quest.DAGOutputSchema = schema
+	}
+
+	if id, ok := call.Metadata["party_id"].(string); ok && id != "" {
+		partyID := domain.PartyID(id)
+		quest.PartyID = &partyID
+	}
+
+	if names, ok := call.Metadata["allowed_tools"].([]any); ok {
+		for _, n := range names {
+			if name, ok := n.(string); ok {
+				quest.AllowedTools = append(quest.AllowedTools, name)
+			}
+		}
+	}
+
+	if items, ok := call.Metadata["owned_tools"].([]any); ok {
+		agent.OwnedTools = make(map[string]agentprogression.OwnedTool, len(items))
+		for _, it := range items {
+			if id, ok := it.(string); ok {
+				// questbridge sends only usable purchases, so uses are not tracked here.
+				agent.OwnedTools[id] = agentprogression.OwnedTool{StoreItemID: id, UsesRemaining: -1}
+			}
+		}
+	}
+
+	if repo, ok := call.Metadata["repo"].(string); ok {
+		quest.Repo = repo
+	}
+
+	if guild, ok := call.Metadata["guild_id"].(string); ok {
+		agent.Guild = domain.GuildID(guild)
+	}
+
+	switch v := call.Metadata["difficulty"].(type) {
+	case float64:
+		quest.Difficulty = domain.QuestDifficulty(int(v))
+	case int:
+		quest.Difficulty = domain.QuestDifficulty(v)
+	}
+
+	if skills, ok := call.Metadata["required_skills"].([]any); ok {
+		for _, s := range skills {
+			if name, ok := s.(string); ok {
+				quest.RequiredSkills = append(quest.RequiredSkills, domain.SkillTag(name))
+			}
+		}
+	}
+
 	// Per-call sandbox: inject directly into arguments so ToolRegistry.Execute reads it.
 	// This avoids mutating the shared ToolRegistry state (race condition).
 	sandboxDir := c.config.SandboxDir
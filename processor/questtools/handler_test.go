@@ -394,6 +394,65 @@ func TestBuildContextFromMetadata_QuestIDWrongType(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// buildContextFromMetadata — danger allowlist and store purchases
+// =============================================================================
+
+func TestBuildContextFromMetadata_AllowedAndOwnedTools(t *testing.T) {
+	c := newTestComponent(DefaultConfig())
+
+	call := &agentic.ToolCall{
+		ID:   "call-gates",
+		Name: "mcp_docs_search",
+		Metadata: map[string]any{
+			"allowed_tools": []any{"bash", "mcp_docs_search"},
+			"owned_tools":   []any{"docs_pass", 42},
+		},
+	}
+
+	agent, quest := c.buildContextFromMetadata(call)
+
+	if len(quest.AllowedTools) != 2 || quest.AllowedTools[1] != "mcp_docs_search" {
+		t.Errorf("AllowedTools = %v; want [bash mcp_docs_search]", quest.AllowedTools)
+	}
+	if !agent.OwnsTool("docs_pass") {
+		t.Error("OwnsTool(docs_pass) = false; want true")
+	}
+	if len(agent.OwnedTools) != 1 {
+		t.Errorf("OwnedTools len = %d; want 1 (non-string entries skipped)", len(agent.OwnedTools))
+	}
+}
+
+func TestBuildContextFromMetadata_ScopeFields(t *testing.T) {
+	c := newTestComponent(DefaultConfig())
+
+	call := &agentic.ToolCall{
+		ID:   "call-policy",
+		Name: "bash",
+		Metadata: map[string]any{
+			"guild_id":        "guild-ops",
+			"repo":            "webapp",
+			"difficulty":      float64(domain.DifficultyHard),
+			"required_skills": []any{"code_generation", 7},
+		},
+	}
+
+	agent, quest := c.buildContextFromMetadata(call)
+
+	if agent.Guild != "guild-ops" {
+		t.Errorf("Guild = %q; want guild-ops", agent.Guild)
+	}
+	if quest.Repo != "webapp" {
+		t.Errorf("Repo = %q; want webapp", quest.Repo)
+	}
+	if quest.Difficulty != domain.DifficultyHard {
+		t.Errorf("Difficulty = %d; want %d", quest.Difficulty, domain.DifficultyHard)
+	}
+	if len(quest.RequiredSkills) != 1 || quest.RequiredSkills[0] != "code_generation" {
+		t.Errorf("RequiredSkills = %v; want [code_generation]", quest.RequiredSkills)
+	}
+}
+
 // =============================================================================
 // buildContextFromMetadata — sandbox_dir injection and path escape checks
 // =============================================================================
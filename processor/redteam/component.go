@@ -41,13 +41,19 @@ type Component struct {
 	// QuestBoard reference for posting red-team quests.
 	questBoard QuestBoardRef
 
-	// KV watcher for quest entity state changes.
+	// KV watchers for quest state changes and for the battle and peer review
+	// outcomes credited to guild lessons.
 	questWatch  jetstream.KeyWatcher
+	battleWatch jetstream.KeyWatcher
+	reviewWatch jetstream.KeyWatcher
 	watchDoneCh chan struct{}
 
 	// Quest state cache for detecting transitions.
 	questCache sync.Map // map[entityID]domain.QuestStatus
 
+	// Battle and peer review cache: whether the entity was last seen finished.
+	outcomeCache sync.Map // map[entityID]bool
+
 	// Track pending red-team quests: original quest ID → red-team quest ID.
 	pendingReviews sync.Map // map[domain.QuestID]*pendingRedTeam
 
@@ -237,6 +243,20 @@ func (c *Component) Start(ctx context.Context) error {
 		return errs.Wrap(err, "redteam", "Start", "watch quest entity type")
 	}
 	c.questWatch = watcher
+
+	// Battle verdicts and lead reviews feed lesson outcomes. Without them
+	// red-team reviews still run, so a failed watch is not fatal.
+	if battleWatcher, err := c.graph.WatchEntityType(ctx, domain.EntityTypeBattle); err != nil {
+		c.logger.Warn("failed to watch battles for lesson outcomes", "error", err)
+	} else {
+		c.battleWatch = battleWatcher
+	}
+	if reviewWatcher, err := c.graph.WatchEntityType(ctx, domain.EntityTypePeerReview); err != nil {
+		c.logger.Warn("failed to watch peer reviews for lesson outcomes", "error", err)
+	} else {
+		c.reviewWatch = reviewWatcher
+	}
+
 	c.watchDoneCh = make(chan struct{})
 	go c.processQuestWatchUpdates()
 
@@ -269,6 +289,12 @@ func (c *Component) Stop(timeout time.Duration) error {
 	if c.questWatch != nil {
 		c.questWatch.Stop()
 	}
+	if c.battleWatch != nil {
+		c.battleWatch.Stop()
+	}
+	if c.reviewWatch != nil {
+		c.reviewWatch.Stop()
+	}
 
 	if c.watchDoneCh != nil {
 		select {
@@ -36,6 +36,14 @@ type Config struct {
 	// PartyTimeoutMultiplier scales the execution timeout for party red-team
 	// quests which need multiple LLM calls (decompose + sub-quests + review + synthesis).
 	PartyTimeoutMultiplier int `json:"party_timeout_multiplier,omitempty" schema:"type:int,description:Timeout multiplier for party red-team quests (default 3)"`
+
+	// MaxGuildLessons caps the lessons stored per guild. When exceeded, the
+	// lowest-ranked lessons are evicted rather than the oldest.
+	MaxGuildLessons int `json:"max_guild_lessons,omitempty" schema:"type:int,description:Max lessons kept per guild (default 100)"`
+
+	// LessonRetireAfter is how many contradicting quest outcomes (or net
+	// member downvotes) retire a lesson. Zero disables retirement.
+	LessonRetireAfter int `json:"lesson_retire_after,omitempty" schema:"type:int,description:Contradictions that retire a guild lesson (default 3)"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults.
@@ -49,6 +57,8 @@ func DefaultConfig() Config {
 		ExecutionTimeoutSec: 300,
 		PreferCrossGuild:       true,
 		PartyTimeoutMultiplier: 3,
+		MaxGuildLessons:        domain.MaxGuildLessons,
+		LessonRetireAfter:      domain.DefaultLessonRetireAfter,
 	}
 }
 
@@ -71,6 +81,19 @@ func (c *Config) ExecutionTimeout() time.Duration {
 	return time.Duration(c.ExecutionTimeoutSec) * time.Second
 }
 
+// LessonPolicy returns the guild lesson curation policy. A zero lesson cap
+// falls back to the domain default.
+func (c *Config) LessonPolicy() domain.LessonCurationPolicy {
+	policy := domain.LessonCurationPolicy{
+		MaxLessons:  c.MaxGuildLessons,
+		RetireAfter: c.LessonRetireAfter,
+	}
+	if policy.MaxLessons == 0 {
+		policy.MaxLessons = domain.MaxGuildLessons
+	}
+	return policy
+}
+
 // Validate checks the configuration for required fields and valid values.
 func (c *Config) Validate() error {
 	if c.Org == "" {
@@ -88,6 +111,12 @@ func (c *Config) Validate() error {
 	if c.ExecutionTimeoutSec <= 0 {
 		return errors.New("execution_timeout_seconds must be positive")
 	}
+	if c.MaxGuildLessons < 0 {
+		return errors.New("max_guild_lessons must not be negative")
+	}
+	if c.LessonRetireAfter < 0 {
+		return errors.New("lesson_retire_after must not be negative")
+	}
 	if c.ExecutionTimeoutSec <= c.ClaimTimeoutSec {
 		return errors.New("execution_timeout_seconds must be greater than claim_timeout_seconds")
 	}
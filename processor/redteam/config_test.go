@@ -146,3 +146,31 @@ func TestToBoardConfig_MapsFields(t *testing.T) {
 		t.Errorf("Board = %q, want %q", bc.Board, "board2")
 	}
 }
+
+// =============================================================================
+// LessonPolicy tests
+// =============================================================================
+
+func TestLessonPolicy_DefaultsZeroCap(t *testing.T) {
+	cfg := Config{LessonRetireAfter: 5}
+	policy := cfg.LessonPolicy()
+	if policy.MaxLessons != domain.MaxGuildLessons {
+		t.Errorf("MaxLessons = %d, want %d", policy.MaxLessons, domain.MaxGuildLessons)
+	}
+	if policy.RetireAfter != 5 {
+		t.Errorf("RetireAfter = %d, want 5", policy.RetireAfter)
+	}
+}
+
+func TestValidate_RejectsNegativeLessonSettings(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxGuildLessons = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative max_guild_lessons")
+	}
+	cfg = DefaultConfig()
+	cfg.LessonRetireAfter = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative lesson_retire_after")
+	}
+}
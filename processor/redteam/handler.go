@@ -11,6 +11,8 @@ import (
 	semdragons "github.com/c360studio/semdragons"
 	"github.com/c360studio/semdragons/domain"
 	"github.com/c360studio/semdragons/processor/agentprogression"
+	"github.com/c360studio/semdragons/processor/bossbattle"
+	"github.com/c360studio/semstreams/graph"
 	"github.com/c360studio/semstreams/natsclient"
 )
 
@@ -18,9 +20,22 @@ import (
 // Detects two transitions:
 //   - Normal quest → in_review: posts a red-team review quest.
 //   - Red-team quest → completed/failed: signals findings to original quest.
+//
+// The same goroutine handles battle and peer review updates, whose outcomes
+// are credited to the guild lessons the reviewed agent was shown.
 func (c *Component) processQuestWatchUpdates() {
 	defer close(c.watchDoneCh)
 
+	var battleUpdates, reviewUpdates <-chan jetstream.KeyValueEntry
+	if c.battleWatch != nil {
+		battleUpdates = c.battleWatch.Updates()
+	}
+	if c.reviewWatch != nil {
+		reviewUpdates = c.reviewWatch.Updates()
+	}
+	// Outcomes replayed before the nil sentinel were credited by an earlier run.
+	battleLive, reviewLive := false, false
+
 	for {
 		select {
 		case <-c.stopChan:
@@ -33,6 +48,26 @@ func (c *Component) processQuestWatchUpdates() {
 				continue
 			}
 			c.handleQuestStateChange(entry)
+		case entry, ok := <-battleUpdates:
+			if !ok {
+				battleUpdates = nil
+				continue
+			}
+			if entry == nil {
+				battleLive = true
+				continue
+			}
+			c.handleBattleStateChange(entry, battleLive)
+		case entry, ok := <-reviewUpdates:
+			if !ok {
+				reviewUpdates = nil
+				continue
+			}
+			if entry == nil {
+				reviewLive = true
+				continue
+			}
+			c.handleReviewStateChange(entry, reviewLive)
 		}
 	}
 }
@@ -104,6 +139,71 @@ func (c *Component) handleQuestStateChange(entry jetstream.KeyValueEntry) {
 	}
 }
 
+// handleBattleStateChange credits a boss battle's verdict to guild lessons
+// the first time the battle is seen finished.
+func (c *Component) handleBattleStateChange(entry jetstream.KeyValueEntry, live bool) {
+	entityState, ok := c.decodeOutcomeEntry(entry)
+	if !ok {
+		return
+	}
+	battle := bossbattle.BattleFromEntityState(entityState)
+	if battle == nil {
+		return
+	}
+	finished := battle.Status == domain.BattleVictory || battle.Status == domain.BattleDefeat
+	if !c.newlyFinished(entry.Key(), finished) || !live {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	c.recordBattleOutcome(ctx, battle)
+}
+
+// handleReviewStateChange credits a lead's review of a sub-quest to guild
+// lessons the first time the review is seen completed.
+func (c *Component) handleReviewStateChange(entry jetstream.KeyValueEntry, live bool) {
+	entityState, ok := c.decodeOutcomeEntry(entry)
+	if !ok {
+		return
+	}
+	review := domain.PeerReviewFromEntityState(entityState)
+	if review == nil {
+		return
+	}
+	if !c.newlyFinished(entry.Key(), review.Status == domain.PeerReviewCompleted) || !live {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	c.recordLeadReviewOutcome(ctx, review)
+}
+
+// decodeOutcomeEntry decodes a battle or peer review update, dropping
+// deleted entities from the outcome cache.
+func (c *Component) decodeOutcomeEntry(entry jetstream.KeyValueEntry) (*graph.EntityState, bool) {
+	if !c.running.Load() {
+		return nil, false
+	}
+	if entry.Operation() == jetstream.KeyValueDelete {
+		c.outcomeCache.Delete(entry.Key())
+		return nil, false
+	}
+	entityState, err := semdragons.DecodeEntityState(entry)
+	if err != nil || entityState == nil {
+		return nil, false
+	}
+	return entityState, true
+}
+
+// newlyFinished records whether an outcome entity is finished and reports
+// true only on the update that first finishes it, so each battle or review
+// is credited once.
+func (c *Component) newlyFinished(key string, finished bool) bool {
+	prev, loaded := c.outcomeCache.Swap(key, finished)
+	wasFinished := loaded && prev.(bool)
+	return finished && !wasFinished
+}
+
 // handleNormalQuestInReview posts a red-team review quest for a submitted quest.
 func (c *Component) handleNormalQuestInReview(quest *domain.Quest) {
 	// Eligibility checks.
@@ -3,9 +3,13 @@ package redteam
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/bossbattle"
 )
 
 // extractAndStoreLessons parses red-team findings and the battle verdict to
@@ -96,18 +100,12 @@ func (c *Component) extractAndStoreLessons(ctx context.Context, originalQuest *d
 		"red_guild", redGuildID)
 }
 
-// appendGuildLessons loads a guild, appends lessons, and persists.
+// appendGuildLessons curates the new lessons into a guild's existing ones
+// and persists with CAS, so lessons written concurrently by the other team,
+// outcome recording or member votes are not lost. Near-duplicates reinforce
+// existing lessons and the lowest-ranked lessons are evicted once the guild
+// is over its cap.
 func (c *Component) appendGuildLessons(ctx context.Context, guildID domain.GuildID, lessons []domain.Lesson) {
-	guildEntity, err := c.graph.GetGuild(ctx, guildID)
-	if err != nil {
-		c.logger.Debug("failed to load guild for lessons", "guild", guildID, "error", err)
-		return
-	}
-	guild := domain.GuildFromEntityState(guildEntity)
-	if guild == nil {
-		return
-	}
-
 	// Tag lessons with this guild.
 	tagged := make([]domain.Lesson, len(lessons))
 	copy(tagged, lessons)
@@ -115,18 +113,156 @@ func (c *Component) appendGuildLessons(ctx context.Context, guildID domain.Guild
 		tagged[i].GuildID = guildID
 	}
 
-	guild.Lessons = append(guild.Lessons, tagged...)
+	var result domain.LessonCurationResult
+	_, err := c.graph.UpdateGuild(ctx, guildID, domain.PredicateGuildLessonAdded, func(guild *domain.Guild) error {
+		guild.Lessons, result = domain.CurateLessons(guild.Lessons, tagged, time.Now(), c.config.LessonPolicy())
+		return nil
+	})
+	if err != nil {
+		c.logger.Error("failed to persist guild lessons", "guild", guildID, "error", err)
+		return
+	}
+	c.logger.Debug("curated guild lessons",
+		"guild", guildID,
+		"added", result.Added,
+		"reinforced", result.Reinforced,
+		"contradicted", result.Contradicted,
+		"retired", result.Retired,
+		"evicted", result.Evicted)
+}
 
-	// Cap total lessons to prevent unbounded growth.
-	if len(guild.Lessons) > 100 {
-		guild.Lessons = guild.Lessons[len(guild.Lessons)-100:]
+// lessonCriteria maps a lesson category to the review criteria that judge
+// it. Categories without an entry are judged by the criterion of the same
+// name, which domain catalogs can define (e.g. "security").
+var lessonCriteria = map[domain.LessonCategory][]string{
+	domain.LessonQuality:      {"quality", "style"},
+	domain.LessonBestPractice: {"style", "quality"},
+}
+
+// battleConfirmsLesson reports whether a finished boss battle confirms a
+// lesson. The lesson is judged on the criteria matching its category; when
+// the judges scored none of them, the overall verdict decides.
+func battleConfirmsLesson(battle *bossbattle.BossBattle, lesson *domain.Lesson) bool {
+	names, ok := lessonCriteria[lesson.Category]
+	if !ok {
+		names = []string{string(lesson.Category)}
+	}
+	judged := false
+	for _, r := range battle.Results {
+		if !slices.Contains(names, r.CriterionName) {
+			continue
+		}
+		if !r.Passed {
+			return false
+		}
+		judged = true
 	}
+	return judged || battle.Status == domain.BattleVictory
+}
 
-	if err := c.graph.EmitEntityUpdate(ctx, guild, domain.PredicateGuildLessonAdded); err != nil {
-		c.logger.Error("failed to persist guild lessons", "guild", guildID, "error", err)
+// peerConfirmRating is the lowest task-quality rating (1-5) from a lead's
+// review that still confirms the lessons the member was shown.
+const peerConfirmRating = 3
+
+// leadReviewOutcome extracts a party lead's verdict on a member's sub-quest
+// from a completed peer review. Only leader-to-member reviews judge the
+// member's own quest: member-to-member reviews are filed against the parent
+// quest, and DM reviews repeat the boss battle that is credited already.
+func leadReviewOutcome(pr *domain.PeerReview) (confirmed, ok bool) {
+	if pr.Status != domain.PeerReviewCompleted || pr.IsMemberToMember || pr.LeaderReview == nil {
+		return false, false
+	}
+	if pr.LeaderReview.Direction != domain.ReviewDirectionLeaderToMember {
+		return false, false
+	}
+	return pr.LeaderReview.Ratings.Q1 >= peerConfirmRating, true
+}
+
+// recordBattleOutcome credits a finished boss battle to the lessons its agent
+// was shown, lesson by lesson on the criteria relevant to each.
+func (c *Component) recordBattleOutcome(ctx context.Context, battle *bossbattle.BossBattle) {
+	c.recordLessonOutcomes(ctx, battle.QuestID, battle.AgentID, battle.Status == domain.BattleVictory,
+		func(l *domain.Lesson) bool { return battleConfirmsLesson(battle, l) })
+}
+
+// recordLeadReviewOutcome credits a lead's review of a sub-quest to the
+// lessons the member was shown for it. Sub-quests skip the boss battle, so
+// this is the only review their lessons get.
+func (c *Component) recordLeadReviewOutcome(ctx context.Context, pr *domain.PeerReview) {
+	confirmed, ok := leadReviewOutcome(pr)
+	if !ok {
+		return
+	}
+	c.recordLessonOutcomes(ctx, pr.QuestID, pr.MemberID, confirmed,
+		func(*domain.Lesson) bool { return confirmed })
+}
+
+// recordLessonOutcomes feeds a review outcome back into exactly the lessons
+// questbridge injected into the quest's prompt (quest.InjectedLessons).
+// confirms decides per lesson; passed only labels the guild update. Lessons
+// the guild has since evicted are skipped.
+func (c *Component) recordLessonOutcomes(ctx context.Context, questID domain.QuestID, agentID domain.AgentID, passed bool, confirms func(*domain.Lesson) bool) {
+	questEntity, err := c.graph.GetQuest(ctx, questID)
+	if err != nil {
+		c.logger.Debug("failed to load quest for lesson outcomes", "quest", questID, "error", err)
+		return
+	}
+	quest := domain.QuestFromEntityState(questEntity)
+	if quest == nil || len(quest.InjectedLessons) == 0 {
+		return
+	}
+
+	guildID := c.resolveAgentGuild(agentID)
+	if guildID == nil {
+		return
+	}
+
+	predicate := domain.PredicateGuildLessonConfirmed
+	if !passed {
+		predicate = domain.PredicateGuildLessonContradicted
+	}
+	retireAfter := c.config.LessonPolicy().RetireAfter
+	var confirmed, contradicted, retired int
+	_, err = c.graph.UpdateGuild(ctx, *guildID, predicate, func(guild *domain.Guild) error {
+		confirmed, contradicted, retired = 0, 0, 0
+		for _, id := range quest.InjectedLessons {
+			l := guild.Lesson(id)
+			if l == nil {
+				continue
+			}
+			ok := confirms(l)
+			if ok {
+				confirmed++
+			} else {
+				contradicted++
+			}
+			if l.RecordOutcome(ok, retireAfter) {
+				retired++
+			}
+		}
+		if confirmed+contradicted == 0 {
+			return errNoInjectedLessons
+		}
+		return nil
+	})
+	if errors.Is(err, errNoInjectedLessons) {
+		return
+	}
+	if err != nil {
+		c.logger.Error("failed to persist lesson outcomes", "guild", *guildID, "error", err)
+		return
 	}
+	c.logger.Debug("recorded lesson outcomes",
+		"quest", questID,
+		"guild", *guildID,
+		"confirmed", confirmed,
+		"contradicted", contradicted,
+		"retired", retired)
 }
 
+// errNoInjectedLessons aborts a guild update when none of a quest's injected
+// lessons remain in the guild.
+var errNoInjectedLessons = errors.New("no injected lessons left in guild")
 
 // finding is a parsed entry from red-team output.
 type finding struct {
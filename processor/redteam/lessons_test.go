@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/bossbattle"
 )
 
 // =============================================================================
@@ -550,3 +551,86 @@ func TestParseFindings_StructuredObjectEmptyRisksAndStrengths(t *testing.T) {
 		t.Fatalf("got %d findings, want 0 (empty sections produce no findings)", len(findings))
 	}
 }
+
+// =============================================================================
+// lesson outcome tests
+// =============================================================================
+
+func TestBattleConfirmsLesson_JudgesRelevantCriteria(t *testing.T) {
+	battle := &bossbattle.BossBattle{
+		Status: domain.BattleDefeat,
+		Results: []domain.ReviewResult{
+			{CriterionName: "correctness", Passed: true, JudgeID: "judge-llm-1"},
+			{CriterionName: "completeness", Passed: false, JudgeID: "judge-llm-1"},
+			{CriterionName: "style", Passed: true, JudgeID: "judge-llm-1"},
+		},
+	}
+
+	tests := []struct {
+		category domain.LessonCategory
+		want     bool
+	}{
+		{domain.LessonCorrectness, true},
+		{domain.LessonCompleteness, false},
+		{domain.LessonBestPractice, true},
+		{domain.LessonQuality, true},
+		// No security criterion was scored: the overall defeat decides.
+		{domain.LessonSecurity, false},
+	}
+	for _, tt := range tests {
+		lesson := &domain.Lesson{ID: "l", Category: tt.category}
+		if got := battleConfirmsLesson(battle, lesson); got != tt.want {
+			t.Errorf("battleConfirmsLesson(%s) = %v, want %v", tt.category, got, tt.want)
+		}
+	}
+}
+
+func TestBattleConfirmsLesson_AnyFailingJudgeContradicts(t *testing.T) {
+	battle := &bossbattle.BossBattle{
+		Status: domain.BattleVictory,
+		Results: []domain.ReviewResult{
+			{CriterionName: "correctness", Passed: true, JudgeID: "judge-auto"},
+			{CriterionName: "correctness", Passed: false, JudgeID: "judge-llm-1"},
+		},
+	}
+	if battleConfirmsLesson(battle, &domain.Lesson{Category: domain.LessonCorrectness}) {
+		t.Error("lesson confirmed although a judge failed its criterion")
+	}
+	if !battleConfirmsLesson(battle, &domain.Lesson{Category: domain.LessonDocumentation}) {
+		t.Error("unjudged lesson not confirmed by a victory")
+	}
+}
+
+func TestLeadReviewOutcome(t *testing.T) {
+	leadReview := func(q1 int) *domain.ReviewSubmission {
+		return &domain.ReviewSubmission{
+			Direction: domain.ReviewDirectionLeaderToMember,
+			Ratings:   domain.ReviewRatings{Q1: q1, Q2: 5, Q3: 5},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		review        domain.PeerReview
+		wantConfirmed bool
+		wantOK        bool
+	}{
+		{"accepted", domain.PeerReview{Status: domain.PeerReviewCompleted, LeaderReview: leadReview(5)}, true, true},
+		{"borderline", domain.PeerReview{Status: domain.PeerReviewCompleted, LeaderReview: leadReview(3)}, true, true},
+		{"rejected", domain.PeerReview{Status: domain.PeerReviewCompleted, LeaderReview: leadReview(2)}, false, true},
+		{"pending", domain.PeerReview{Status: domain.PeerReviewPending, LeaderReview: leadReview(5)}, false, false},
+		{"member to member", domain.PeerReview{Status: domain.PeerReviewCompleted, IsMemberToMember: true, LeaderReview: leadReview(5)}, false, false},
+		{"dm review", domain.PeerReview{Status: domain.PeerReviewCompleted, LeaderReview: &domain.ReviewSubmission{
+			Direction: domain.ReviewDirectionDMToAgent, Ratings: domain.ReviewRatings{Q1: 5, Q2: 5, Q3: 5},
+		}}, false, false},
+		{"no lead review", domain.PeerReview{Status: domain.PeerReviewCompleted}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confirmed, ok := leadReviewOutcome(&tt.review)
+			if confirmed != tt.wantConfirmed || ok != tt.wantOK {
+				t.Errorf("leadReviewOutcome = (%v, %v), want (%v, %v)", confirmed, ok, tt.wantConfirmed, tt.wantOK)
+			}
+		})
+	}
+}
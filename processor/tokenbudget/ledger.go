@@ -201,6 +201,23 @@ func (l *TokenLedger) Record(ctx context.Context, promptTokens, completionTokens
 	l.persistTotalAsync(ctx)
 }
 
+// RecordCost adds a non-token cost, such as a paid search API call, to the
+// hourly and total cost counters. It does not count against the token budget.
+func (l *TokenLedger) RecordCost(ctx context.Context, costUSD float64, source string) {
+	if costUSD <= 0 {
+		return
+	}
+	l.rollEpochIfNeeded()
+
+	costMicro := int64(costUSD * 1_000_000)
+	l.hourlyCostMicro.Add(costMicro)
+	l.totalCostMicro.Add(costMicro)
+
+	l.logger.Debug("cost recorded", "cost_usd", costUSD, "source", source)
+
+	l.persistTotalAsync(ctx)
+}
+
 // Check returns an error if the hourly budget is exceeded.
 func (l *TokenLedger) Check() error {
 	l.rollEpochIfNeeded()
@@ -276,6 +276,26 @@ func TestRecord_WithPricing_AccumulatesCost(t *testing.T) {
 	}
 }
 
+func TestRecordCost_AddsToCostTotalsOnly(t *testing.T) {
+	l := NewTokenLedger(nil, testLogger())
+	ctx := context.Background()
+
+	l.RecordCost(ctx, 0.005, "tool:web_search")
+	l.RecordCost(ctx, 0.005, "tool:web_search")
+	l.RecordCost(ctx, -1, "tool:web_search") // ignored
+
+	stats := l.Stats()
+	if math.Abs(stats.HourlyCostUSD-0.01) > 0.0001 {
+		t.Errorf("hourly cost = %f, want ~0.01", stats.HourlyCostUSD)
+	}
+	if math.Abs(stats.TotalCostUSD-0.01) > 0.0001 {
+		t.Errorf("total cost = %f, want ~0.01", stats.TotalCostUSD)
+	}
+	if stats.HourlyUsage.TotalTokens != 0 {
+		t.Errorf("hourly tokens = %d, want 0", stats.HourlyUsage.TotalTokens)
+	}
+}
+
 func TestRecord_UnknownEndpoint_NoCost(t *testing.T) {
 	cfg := &BudgetConfig{
 		GlobalHourlyLimit: 10_000_000,
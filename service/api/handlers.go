@@ -24,8 +24,10 @@ import (
 	"github.com/c360studio/semdragons/domain"
 	"github.com/c360studio/semdragons/processor/agentprogression"
 	"github.com/c360studio/semdragons/processor/bossbattle"
+	"github.com/c360studio/semdragons/processor/executor"
 	"github.com/c360studio/semdragons/processor/partycoord"
 	"github.com/c360studio/semdragons/processor/questbridge"
+	"github.com/c360studio/semdragons/processor/questtools"
 )
 
 const maxRequestBodySize = 1 << 20 // 1 MB
@@ -194,16 +196,18 @@ func (s *Service) handleCreateQuest(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Objective string `json:"objective"`
 		Hints     *struct {
-			SuggestedDifficulty *int     `json:"suggested_difficulty,omitempty"`
-			SuggestedSkills     []string `json:"suggested_skills,omitempty"`
-			PreferGuild         *string  `json:"prefer_guild,omitempty"`
-			RequireHumanReview  bool     `json:"require_human_review"`
-			ReviewLevel         *int     `json:"review_level,omitempty"`
-			Budget              float64  `json:"budget"`
-			Deadline            string   `json:"deadline,omitempty"`
-			PartyRequired       bool     `json:"party_required"`
-			MinPartySize        *int     `json:"min_party_size,omitempty"`
-			Repo                string   `json:"repo,omitempty"`
+			SuggestedDifficulty *int              `json:"suggested_difficulty,omitempty"`
+			SuggestedSkills     []string          `json:"suggested_skills,omitempty"`
+			PreferGuild         *string           `json:"prefer_guild,omitempty"`
+			RequireHumanReview  bool              `json:"require_human_review"`
+			ReviewLevel         *int              `json:"review_level,omitempty"`
+			Budget              float64           `json:"budget"`
+			Deadline            string            `json:"deadline,omitempty"`
+			PartyRequired       bool              `json:"party_required"`
+			MinPartySize        *int              `json:"min_party_size,omitempty"`
+			Repo                string            `json:"repo,omitempty"`
+			Blueprint           string            `json:"blueprint,omitempty"`
+			BlueprintArgs       map[string]string `json:"blueprint_args,omitempty"`
 		} `json:"hints,omitempty"`
 	}
 
@@ -272,6 +276,13 @@ func (s *Service) handleCreateQuest(w http.ResponseWriter, r *http.Request) {
 		if req.Hints.Repo != "" {
 			quest.Repo = req.Hints.Repo
 		}
+		if req.Hints.Blueprint != "" {
+			if err := s.validateBlueprintHint(r.Context(), req.Hints.Blueprint); err != nil {
+				s.writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			applyBlueprintHint(quest, req.Hints.Blueprint, req.Hints.BlueprintArgs)
+		}
 	}
 
 	s.upgradeToPartyIfNeeded(r.Context(), quest)
@@ -303,6 +314,18 @@ func (s *Service) handlePostQuestChain(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
+	// Check blueprint hints up front so a bad one cannot leave the chain
+	// half-posted.
+	for i, entry := range chain.Quests {
+		if entry.Hints == nil || entry.Hints.Blueprint == "" {
+			continue
+		}
+		if err := s.validateBlueprintHint(ctx, entry.Hints.Blueprint); err != nil {
+			s.writeError(w, fmt.Sprintf("quest %d: %v", i, err), http.StatusBadRequest)
+			return
+		}
+	}
+
 	now := time.Now()
 
 	// First pass: post each quest (no DependsOn yet — we need real IDs first)
@@ -381,6 +404,7 @@ func (s *Service) handlePostQuestChain(w http.ResponseWriter, r *http.Request) {
 					quest.MinPartySize = *entry.Hints.MinPartySize
 				}
 			}
+			applyBlueprintHint(&quest, entry.Hints.Blueprint, entry.Hints.BlueprintArgs)
 		}
 
 		// Set PartyRequired from classification if not explicitly set via hints.
@@ -2159,6 +2183,17 @@ func (s *Service) handleGetStoreItem(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, item)
 }
 
+// handleStoreEconomy reports XP supply versus store sinks over time, with
+// current list prices.
+func (s *Service) handleStoreEconomy(w http.ResponseWriter, _ *http.Request) {
+	store := s.getStore()
+	if store == nil {
+		s.writeError(w, "store service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	s.writeJSON(w, store.EconomyReport())
+}
+
 func (s *Service) handlePurchase(w http.ResponseWriter, r *http.Request) {
 	if s.getStore() == nil {
 		s.writeError(w, "store service unavailable", http.StatusServiceUnavailable)
@@ -2488,6 +2523,12 @@ func (s *Service) handleSubmitReview(w http.ResponseWriter, r *http.Request) {
 	reviewerID := domain.AgentID(req.ReviewerID)
 	now := time.Now()
 
+	// Member-to-member reviews pair two peers; both sides review the same way.
+	leaderDirection, memberDirection := domain.ReviewDirectionLeaderToMember, domain.ReviewDirectionMemberToLeader
+	if review.IsMemberToMember {
+		leaderDirection, memberDirection = domain.ReviewDirectionMemberToMember, domain.ReviewDirectionMemberToMember
+	}
+
 	switch reviewerID {
 	case review.LeaderID:
 		if review.LeaderReview != nil {
@@ -2497,7 +2538,7 @@ func (s *Service) handleSubmitReview(w http.ResponseWriter, r *http.Request) {
 		review.LeaderReview = &domain.ReviewSubmission{
 			ReviewerID:  reviewerID,
 			RevieweeID:  review.MemberID,
-			Direction:   domain.ReviewDirectionLeaderToMember,
+			Direction:   leaderDirection,
 			Ratings:     ratings,
 			Explanation: req.Explanation,
 			SubmittedAt: now,
@@ -2514,7 +2555,7 @@ func (s *Service) handleSubmitReview(w http.ResponseWriter, r *http.Request) {
 		review.MemberReview = &domain.ReviewSubmission{
 			ReviewerID:  reviewerID,
 			RevieweeID:  review.LeaderID,
-			Direction:   domain.ReviewDirectionMemberToLeader,
+			Direction:   memberDirection,
 			Ratings:     ratings,
 			Explanation: req.Explanation,
 			SubmittedAt: now,
@@ -2809,6 +2850,51 @@ func (s *Service) handleSetTokenBudget(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, s.tokenLedger.Stats())
 }
 
+// =============================================================================
+// TOOL USAGE
+// =============================================================================
+
+// toolUsageReporter is implemented by components that run tool calls.
+type toolUsageReporter interface {
+	ToolUsage() []executor.ToolUsageStats
+}
+
+// toolUsageComponents are the components whose tool usage is reported.
+var toolUsageComponents = []string{questtools.ComponentName, executor.ComponentName}
+
+// handleToolUsage returns per-tool call, error, latency, byte and cost
+// counters, merged across the components that execute tools.
+func (s *Service) handleToolUsage(w http.ResponseWriter, _ *http.Request) {
+	var sets [][]executor.ToolUsageStats
+	if s.componentDeps != nil && s.componentDeps.ComponentRegistry != nil {
+		for _, name := range toolUsageComponents {
+			if reporter, ok := s.componentDeps.ComponentRegistry.Component(name).(toolUsageReporter); ok {
+				sets = append(sets, reporter.ToolUsage())
+			}
+		}
+	}
+	s.writeJSON(w, executor.MergeToolUsage(sets...))
+}
+
+// searchHealthReporter is implemented by components that register web_search.
+type searchHealthReporter interface {
+	SearchHealth() []executor.SearchProviderHealth
+}
+
+// handleSearchHealth returns the health of each provider behind a multi
+// web_search provider, across the components that execute tools.
+func (s *Service) handleSearchHealth(w http.ResponseWriter, _ *http.Request) {
+	health := []executor.SearchProviderHealth{}
+	if s.componentDeps != nil && s.componentDeps.ComponentRegistry != nil {
+		for _, name := range toolUsageComponents {
+			if reporter, ok := s.componentDeps.ComponentRegistry.Component(name).(searchHealthReporter); ok {
+				health = append(health, reporter.SearchHealth()...)
+			}
+		}
+	}
+	s.writeJSON(w, health)
+}
+
 // =============================================================================
 // MODEL REGISTRY
 // =============================================================================
@@ -65,6 +65,15 @@ func (s *Service) handleGetQuestArtifacts(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	// The latest test report lives beside the workspace, not in it.
+	if report, reportErr := s.sandboxClient.TestReport(r.Context(), workspaceID); reportErr == nil && report != nil {
+		if data, marshalErr := json.MarshalIndent(report, "", "  "); marshalErr == nil {
+			if fw, createErr := zw.Create(testReportArtifact); createErr == nil {
+				fw.Write(data) //nolint:errcheck
+			}
+		}
+	}
+
 	for _, f := range files {
 		data, readErr := s.sandboxClient.ReadFile(r.Context(), workspaceID, f.Path)
 		if readErr != nil {
@@ -114,6 +123,38 @@ func (s *Service) handleGetQuestArtifactFile(w http.ResponseWriter, r *http.Requ
 	w.Write(data) //nolint:errcheck
 }
 
+// testReportArtifact is the archive path of the quest's latest test report.
+const testReportArtifact = "test-report.json"
+
+// handleGetQuestTestReport returns the quest's latest structured test report:
+// pass/fail counts and each failure's name, message and file:line.
+//
+// GET /api/game/quests/{id}/artifacts/test-report
+func (s *Service) handleGetQuestTestReport(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !isValidPathID(id) {
+		s.writeError(w, "invalid quest ID", http.StatusBadRequest)
+		return
+	}
+
+	if s.sandboxClient == nil {
+		s.writeError(w, "sandbox not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	report, err := s.sandboxClient.TestReport(r.Context(), s.resolveQuestWorkspaceID(id))
+	if err != nil {
+		s.logger.Debug("test report get failed", "quest_id", id, "error", err)
+		s.writeError(w, "failed to load test report", http.StatusBadGateway)
+		return
+	}
+	if report == nil {
+		s.writeError(w, "no test report for quest", http.StatusNotFound)
+		return
+	}
+	s.writeJSON(w, report)
+}
+
 // handleListQuestArtifacts returns a JSON list of artifact files for a quest.
 //
 // GET /api/game/quests/{id}/artifacts/list
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/partycoord"
+	"github.com/c360studio/semstreams/natsclient"
+)
+
+// =============================================================================
+// PARTY BLACKBOARD — shared notes and member messages
+// =============================================================================
+
+// handleGetPartyBlackboard returns a party's shared notes and the messages
+// its members exchanged, with delivery timestamps.
+//
+// GET /api/game/parties/{id}/blackboard
+func (s *Service) handleGetPartyBlackboard(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !isValidPathID(id) {
+		s.writeError(w, "invalid entity ID", http.StatusBadRequest)
+		return
+	}
+
+	// Resolve the party first: blackboards are keyed by the full entity ID.
+	entity, err := s.graph.GetParty(r.Context(), domain.PartyID(id))
+	if err != nil {
+		if isBucketNotFound(err) || isKeyNotFound(err) {
+			http.NotFound(w, r)
+			return
+		}
+		s.writeError(w, "failed to retrieve party", http.StatusInternalServerError)
+		s.logger.Error("Failed to get party", "id", id, "error", err)
+		return
+	}
+	party := partycoord.PartyFromEntityState(entity)
+	if party == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if s.partyBoards == nil {
+		s.writeError(w, "party blackboard unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	board, err := s.partyBoards.GetBlackboard(r.Context(), party.ID)
+	if err != nil {
+		s.writeError(w, "failed to retrieve party blackboard", http.StatusInternalServerError)
+		s.logger.Error("Failed to get party blackboard", "id", id, "error", err)
+		return
+	}
+	s.writeJSON(w, board)
+}
+
+// natsPartyBoardReader reads blackboards from the PARTY_BLACKBOARD bucket.
+// The bucket is created by questtools; until it exists every party has an
+// empty blackboard.
+type natsPartyBoardReader struct {
+	nats *natsclient.Client
+
+	mu    sync.Mutex
+	store *partycoord.BlackboardStore // cached after the bucket is found; guarded by mu
+}
+
+func (b *natsPartyBoardReader) GetBlackboard(ctx context.Context, partyID domain.PartyID) (*partycoord.Blackboard, error) {
+	store, err := b.getStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return &partycoord.Blackboard{PartyID: partyID}, nil
+	}
+	return store.Get(ctx, partyID)
+}
+
+// getStore returns the blackboard store, or nil, nil while the bucket is missing.
+func (b *natsPartyBoardReader) getStore(ctx context.Context) (*partycoord.BlackboardStore, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.store != nil {
+		return b.store, nil
+	}
+	bucket, err := b.nats.GetKeyValueBucket(ctx, partycoord.BlackboardBucket)
+	if err != nil {
+		if isBucketNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get party blackboard bucket: %w", err)
+	}
+	b.store = partycoord.NewBlackboardStore(bucket)
+	return b.store, nil
+}
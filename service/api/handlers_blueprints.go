@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/questdagexec"
+)
+
+// =============================================================================
+// DAG BLUEPRINTS — browse the library and promote successful DAGs
+// =============================================================================
+
+// blueprints returns a blueprint library over the service's graph client.
+func (s *Service) blueprints() *questdagexec.BlueprintLibrary {
+	return questdagexec.NewBlueprintLibraryWithGraph(s.graph)
+}
+
+// validateBlueprintHint checks that a DM-selected blueprint exists.
+func (s *Service) validateBlueprintHint(ctx context.Context, id string) error {
+	if _, err := s.blueprints().Get(ctx, id); err != nil {
+		return fmt.Errorf("unknown blueprint %q", id)
+	}
+	return nil
+}
+
+// applyBlueprintHint records the DM's blueprint choice on a quest. Blueprints
+// only apply to decomposed work, so naming one makes the quest a party quest.
+func applyBlueprintHint(quest *domain.Quest, id string, args map[string]string) {
+	if id == "" {
+		return
+	}
+	quest.DAGBlueprint = id
+	if len(args) > 0 {
+		quest.DAGBlueprintArgs = args
+	}
+	quest.PartyRequired = true
+	if quest.MinPartySize < 2 {
+		quest.MinPartySize = 2
+	}
+}
+
+// handleListBlueprints returns every blueprint, best ranked first.
+//
+// GET /api/game/blueprints
+func (s *Service) handleListBlueprints(w http.ResponseWriter, r *http.Request) {
+	blueprints, err := s.blueprints().List(r.Context())
+	if err != nil {
+		// Built-ins are still returned; KV blueprints may be missing.
+		s.logger.Warn("Failed to list stored blueprints", "error", err)
+	}
+
+	ranked := make([]RankedBlueprint, 0, len(blueprints))
+	for _, bp := range blueprints {
+		ranked = append(ranked, RankedBlueprint{Blueprint: bp, SuccessRate: bp.SuccessRate()})
+	}
+	s.writeJSON(w, ranked)
+}
+
+// handleGetBlueprint returns a single blueprint.
+//
+// GET /api/game/blueprints/{id}
+func (s *Service) handleGetBlueprint(w http.ResponseWriter, r *http.Request) {
+	bp, err := s.blueprints().Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	s.writeJSON(w, RankedBlueprint{Blueprint: *bp, SuccessRate: bp.SuccessRate()})
+}
+
+// handlePromoteBlueprint turns a completed party quest's DAG into a blueprint.
+//
+// POST /api/game/blueprints/promote
+func (s *Service) handlePromoteBlueprint(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	var req PromoteBlueprintRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.QuestID == "" || req.ID == "" || req.Name == "" {
+		s.writeError(w, "quest_id, id and name are required", http.StatusBadRequest)
+		return
+	}
+	if !isValidPathID(req.QuestID) {
+		s.writeError(w, "invalid quest ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	entity, err := s.graph.GetQuest(ctx, domain.QuestID(req.QuestID))
+	if err != nil {
+		if isBucketNotFound(err) || isKeyNotFound(err) {
+			http.NotFound(w, r)
+			return
+		}
+		s.writeError(w, "failed to retrieve quest", http.StatusInternalServerError)
+		s.logger.Error("Failed to get quest for blueprint promotion", "id", req.QuestID, "error", err)
+		return
+	}
+	quest := domain.QuestFromEntityState(entity)
+	if quest == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if quest.Status != domain.QuestCompleted {
+		s.writeError(w, "only completed quests can be promoted", http.StatusConflict)
+		return
+	}
+	dag := questdagexec.DAGFromAny(quest.DAGDefinition)
+	if len(dag.Nodes) == 0 {
+		s.writeError(w, "quest has no DAG to promote", http.StatusConflict)
+		return
+	}
+
+	lib := s.blueprints()
+	if _, err := lib.Get(ctx, req.ID); err == nil {
+		s.writeError(w, fmt.Sprintf("blueprint %q already exists", req.ID), http.StatusConflict)
+		return
+	} else if !errors.Is(err, questdagexec.ErrBlueprintNotFound) {
+		s.writeError(w, "failed to check blueprint", http.StatusInternalServerError)
+		return
+	}
+
+	bp := questdagexec.BlueprintFromDAG(req.ID, req.Name, dag)
+	bp.Description = req.Description
+	bp.SourceQuestID = string(quest.ID)
+	for _, c := range req.Classes {
+		bp.Classes = append(bp.Classes, domain.DecomposabilityClass(c))
+	}
+	if len(bp.Classes) == 0 && quest.DecomposabilityClass != "" && quest.DecomposabilityClass != domain.DecomposableTrivial {
+		bp.Classes = []domain.DecomposabilityClass{quest.DecomposabilityClass}
+	}
+
+	if err := bp.Validate(); err != nil {
+		s.writeError(w, "invalid blueprint: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := lib.Create(ctx, &bp); err != nil {
+		if errors.Is(err, questdagexec.ErrBlueprintExists) {
+			s.writeError(w, fmt.Sprintf("blueprint %q already exists", req.ID), http.StatusConflict)
+			return
+		}
+		s.writeError(w, "failed to save blueprint", http.StatusInternalServerError)
+		s.logger.Error("Failed to save blueprint", "id", req.ID, "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(RankedBlueprint{Blueprint: bp, SuccessRate: bp.SuccessRate()})
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/questdagexec"
+)
+
+// =============================================================================
+// DAG VISUALIZATION — render a party quest's execution DAG
+// =============================================================================
+
+// handleGetQuestDAG renders a party quest's DAG with live node states,
+// assignees, retries, durations, token spend and the critical path. The
+// format query parameter selects json (default), mermaid or dot.
+//
+// GET /api/game/quests/{id}/dag
+func (s *Service) handleGetQuestDAG(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !isValidPathID(id) {
+		s.writeError(w, "invalid quest ID", http.StatusBadRequest)
+		return
+	}
+
+	view, err := questdagexec.LoadDAGView(r.Context(), s.graph, domain.QuestID(id))
+	if err != nil {
+		if errors.Is(err, questdagexec.ErrNoDAG) || isBucketNotFound(err) || isKeyNotFound(err) {
+			http.NotFound(w, r)
+			return
+		}
+		s.writeError(w, "failed to retrieve quest DAG", http.StatusInternalServerError)
+		s.logger.Error("Failed to load quest DAG", "id", id, "error", err)
+		return
+	}
+
+	data, contentType, err := questdagexec.RenderDAGView(view, r.URL.Query().Get("format"))
+	if err != nil {
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(data) //nolint:errcheck
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/guildformation"
+)
+
+// =============================================================================
+// GUILD GOVERNANCE — proposals and rank-weighted votes
+// =============================================================================
+
+// handleListGuildProposals returns a guild's proposals, newest first.
+//
+// GET /api/game/guilds/{id}/proposals
+func (s *Service) handleListGuildProposals(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !isValidPathID(id) {
+		s.writeError(w, "invalid entity ID", http.StatusBadRequest)
+		return
+	}
+	gov := s.getGovernance()
+	if gov == nil {
+		s.writeError(w, "guild service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	proposals := gov.ListProposals(domain.GuildID(id))
+	out := make([]domain.GuildProposal, 0, len(proposals))
+	for i := len(proposals) - 1; i >= 0; i-- {
+		out = append(out, proposals[i])
+	}
+	s.writeJSON(w, out)
+}
+
+// handleOpenGuildProposal puts a decision to a guild vote.
+//
+// POST /api/game/guilds/{id}/proposals
+func (s *Service) handleOpenGuildProposal(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !isValidPathID(id) {
+		s.writeError(w, "invalid entity ID", http.StatusBadRequest)
+		return
+	}
+	gov := s.getGovernance()
+	if gov == nil {
+		s.writeError(w, "guild service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	var req OpenProposalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ProposerID == "" || req.Kind == "" {
+		s.writeError(w, "proposer_id and kind are required", http.StatusBadRequest)
+		return
+	}
+
+	proposal, err := gov.OpenProposal(r.Context(), domain.GuildID(id), guildformation.OpenProposalParams{
+		Kind:          domain.ProposalKind(req.Kind),
+		ProposerID:    domain.AgentID(req.ProposerID),
+		TargetID:      domain.AgentID(req.TargetID),
+		ApplicationID: req.ApplicationID,
+		NewRank:       domain.GuildRank(req.NewRank),
+		Reason:        req.Reason,
+	})
+	if err != nil {
+		s.writeGovernanceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(proposal)
+}
+
+// handleCastGuildVote records a member's vote on a proposal.
+//
+// POST /api/game/guilds/{id}/proposals/{proposalId}/votes
+func (s *Service) handleCastGuildVote(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	proposalID := r.PathValue("proposalId")
+	if !isValidPathID(id) || !isValidPathID(proposalID) {
+		s.writeError(w, "invalid entity ID", http.StatusBadRequest)
+		return
+	}
+	gov := s.getGovernance()
+	if gov == nil {
+		s.writeError(w, "guild service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	var req CastVoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.VoterID == "" {
+		s.writeError(w, "voter_id is required", http.StatusBadRequest)
+		return
+	}
+
+	proposal, err := gov.CastVote(r.Context(), domain.GuildID(id), proposalID,
+		domain.AgentID(req.VoterID), req.Approve, req.Reason)
+	if err != nil {
+		s.writeGovernanceError(w, err)
+		return
+	}
+	s.writeJSON(w, proposal)
+}
+
+// writeGovernanceError maps guild governance errors to HTTP status codes.
+func (s *Service) writeGovernanceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, guildformation.ErrProposalNotFound):
+		s.writeError(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, guildformation.ErrNotMember):
+		s.writeError(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, guildformation.ErrProposalClosed),
+		errors.Is(err, guildformation.ErrAlreadyVoted),
+		errors.Is(err, guildformation.ErrDuplicateProposal):
+		s.writeError(w, err.Error(), http.StatusConflict)
+	default:
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+)
+
+// =============================================================================
+// GUILD LESSONS — ranked knowledge base and member votes
+// =============================================================================
+
+// handleListGuildLessons returns a guild's lessons ranked best first.
+// Optional query params: skill, include_retired.
+//
+// GET /api/game/guilds/{id}/lessons
+func (s *Service) handleListGuildLessons(w http.ResponseWriter, r *http.Request) {
+	guild, ok := s.loadGuildForLessons(w, r)
+	if !ok {
+		return
+	}
+
+	skill := domain.SkillTag(r.URL.Query().Get("skill"))
+	includeRetired := r.URL.Query().Get("include_retired") == "true"
+
+	now := time.Now()
+	ranked := make([]RankedLesson, 0, len(guild.Lessons))
+	for _, l := range guild.Lessons {
+		if l.Retired && !includeRetired {
+			continue
+		}
+		if skill != "" && l.Skill != skill {
+			continue
+		}
+		ranked = append(ranked, RankedLesson{Lesson: l, Score: l.Score(now)})
+	}
+	// Newest first on ties.
+	for i, j := 0, len(ranked)-1; i < j; i, j = i+1, j-1 {
+		ranked[i], ranked[j] = ranked[j], ranked[i]
+	}
+	sort.SliceStable(ranked, func(a, b int) bool {
+		return ranked[a].Score > ranked[b].Score
+	})
+
+	s.writeJSON(w, ranked)
+}
+
+// handleVoteGuildLesson records a member's up- or down-vote on a lesson.
+//
+// POST /api/game/guilds/{id}/lessons/{lessonId}/votes
+func (s *Service) handleVoteGuildLesson(w http.ResponseWriter, r *http.Request) {
+	lessonID := r.PathValue("lessonId")
+	if lessonID == "" {
+		s.writeError(w, "invalid lesson ID", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	var req LessonVoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.VoterID == "" {
+		s.writeError(w, "voter_id is required", http.StatusBadRequest)
+		return
+	}
+
+	guild, ok := s.loadGuildForLessons(w, r)
+	if !ok {
+		return
+	}
+	if guild.Member(domain.AgentID(req.VoterID)) == nil {
+		s.writeError(w, "voter is not a member of the guild", http.StatusForbidden)
+		return
+	}
+	if guild.Lesson(lessonID) == nil {
+		s.writeError(w, domain.ErrLessonNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Vote on a freshly read guild so concurrent lesson curation, outcome
+	// recording and other votes are not overwritten.
+	voterID := domain.AgentID(domain.ExtractInstance(req.VoterID))
+	var lesson domain.Lesson
+	_, err := s.graph.UpdateGuild(r.Context(), guild.ID, domain.PredicateGuildLessonVoted, func(g *domain.Guild) error {
+		l := g.Lesson(lessonID)
+		if l == nil {
+			return domain.ErrLessonNotFound
+		}
+		if err := l.Vote(voterID, req.Up, domain.DefaultLessonRetireAfter); err != nil {
+			return err
+		}
+		lesson = *l
+		return nil
+	})
+	switch {
+	case errors.Is(err, domain.ErrLessonNotFound):
+		s.writeError(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, domain.ErrLessonAlreadyVoted):
+		s.writeError(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		s.writeError(w, "failed to record vote", http.StatusInternalServerError)
+		s.logger.Error("Failed to persist lesson vote", "guild", guild.ID, "lesson", lessonID, "error", err)
+		return
+	}
+
+	s.writeJSON(w, RankedLesson{Lesson: lesson, Score: lesson.Score(time.Now())})
+}
+
+// loadGuildForLessons resolves the {id} path value to a guild, writing the
+// error response and returning false when it cannot.
+func (s *Service) loadGuildForLessons(w http.ResponseWriter, r *http.Request) (*domain.Guild, bool) {
+	id := r.PathValue("id")
+	if !isValidPathID(id) {
+		s.writeError(w, "invalid entity ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	entity, err := s.graph.GetGuild(r.Context(), domain.GuildID(id))
+	if err != nil {
+		if isBucketNotFound(err) || isKeyNotFound(err) {
+			http.NotFound(w, r)
+			return nil, false
+		}
+		s.writeError(w, "failed to retrieve guild", http.StatusInternalServerError)
+		s.logger.Error("Failed to get guild lessons", "id", id, "error", err)
+		return nil, false
+	}
+
+	guild := domain.GuildFromEntityState(entity)
+	if guild == nil {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	return guild, true
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentprogression"
+)
+
+// =============================================================================
+// AGENT MEMORY — inspect and prune per-agent quest memories
+// =============================================================================
+
+// loadAgentForMemory reads and decodes an agent, writing the error response
+// itself. Returns nil when the caller should stop.
+func (s *Service) loadAgentForMemory(w http.ResponseWriter, r *http.Request) *agentprogression.Agent {
+	id := r.PathValue("id")
+	if !isValidPathID(id) {
+		s.writeError(w, "invalid entity ID", http.StatusBadRequest)
+		return nil
+	}
+
+	entity, err := s.graph.GetAgent(r.Context(), domain.AgentID(id))
+	if err != nil {
+		if isBucketNotFound(err) || isKeyNotFound(err) {
+			http.NotFound(w, r)
+			return nil
+		}
+		s.writeError(w, "failed to retrieve agent", http.StatusInternalServerError)
+		s.logger.Error("Failed to get agent for memories", "id", id, "error", err)
+		return nil
+	}
+
+	agent := agentprogression.AgentFromEntityState(entity)
+	if agent == nil {
+		http.NotFound(w, r)
+		return nil
+	}
+	return agent
+}
+
+// handleListAgentMemories returns the agent's stored quest memories, newest first.
+//
+// GET /api/game/agents/{id}/memories
+func (s *Service) handleListAgentMemories(w http.ResponseWriter, r *http.Request) {
+	agent := s.loadAgentForMemory(w, r)
+	if agent == nil {
+		return
+	}
+
+	memories := make([]domain.QuestMemory, 0, len(agent.Memories))
+	for i := len(agent.Memories) - 1; i >= 0; i-- {
+		memories = append(memories, agent.Memories[i])
+	}
+	s.writeJSON(w, memories)
+}
+
+// handleDeleteAgentMemory removes a single memory from the agent.
+//
+// DELETE /api/game/agents/{id}/memories/{memoryId}
+func (s *Service) handleDeleteAgentMemory(w http.ResponseWriter, r *http.Request) {
+	memoryID := r.PathValue("memoryId")
+	if memoryID == "" {
+		s.writeError(w, "memory ID is required", http.StatusBadRequest)
+		return
+	}
+
+	agent := s.loadAgentForMemory(w, r)
+	if agent == nil {
+		return
+	}
+
+	if !agent.ForgetMemory(memoryID) {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := s.graph.EmitEntityUpdate(r.Context(), agent, "agent.memory.deleted"); err != nil {
+		s.writeError(w, "failed to delete memory", http.StatusInternalServerError)
+		s.logger.Error("Failed to delete agent memory", "agent", agent.ID, "memory", memoryID, "error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePruneAgentMemories bulk-removes memories by age, outcome or count.
+//
+// POST /api/game/agents/{id}/memories/prune
+func (s *Service) handlePruneAgentMemories(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	var req PruneMemoriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	opts := agentprogression.MemoryPruneOptions{
+		Outcome:    domain.MemoryOutcome(req.Outcome),
+		KeepLatest: req.KeepLatest,
+	}
+	if req.Outcome != "" && opts.Outcome != domain.MemoryOutcomeSuccess && opts.Outcome != domain.MemoryOutcomeFailure {
+		s.writeError(w, "outcome must be success or failure", http.StatusBadRequest)
+		return
+	}
+	if req.KeepLatest < 0 {
+		s.writeError(w, "keep_latest must be non-negative", http.StatusBadRequest)
+		return
+	}
+	if req.OlderThan != "" {
+		t, err := time.Parse(time.RFC3339, req.OlderThan)
+		if err != nil {
+			s.writeError(w, "older_than must be an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		opts.OlderThan = t
+	}
+	if opts.OlderThan.IsZero() && opts.Outcome == "" && opts.KeepLatest == 0 {
+		s.writeError(w, "at least one of older_than, outcome or keep_latest is required", http.StatusBadRequest)
+		return
+	}
+
+	agent := s.loadAgentForMemory(w, r)
+	if agent == nil {
+		return
+	}
+
+	removed := agent.PruneMemories(opts)
+	if removed > 0 {
+		if err := s.graph.EmitEntityUpdate(r.Context(), agent, "agent.memory.pruned"); err != nil {
+			s.writeError(w, "failed to prune memories", http.StatusInternalServerError)
+			s.logger.Error("Failed to prune agent memories", "agent", agent.ID, "error", err)
+			return
+		}
+	}
+
+	s.writeJSON(w, PruneMemoriesResponse{Removed: removed, Remaining: len(agent.Memories)})
+}
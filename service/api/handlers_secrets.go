@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/c360studio/semdragons/processor/executor"
+)
+
+// =============================================================================
+// TOOL SECRETS
+// =============================================================================
+// Secrets are write-only through the API: they can be stored, listed by name
+// and scope, and deleted, but their values are never returned. Agents use
+// them as {{secret:name}} placeholders in http_request headers and bash env.
+// =============================================================================
+
+// handleListSecrets returns every secret's name, description and scope.
+//
+// GET /game/board/secrets
+func (s *Service) handleListSecrets(w http.ResponseWriter, r *http.Request) {
+	if s.secrets == nil {
+		s.writeError(w, "secrets vault not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	infos, err := s.secrets.List(r.Context())
+	if err != nil {
+		s.writeError(w, "failed to list secrets", http.StatusInternalServerError)
+		s.logger.Error("Failed to list secrets", "error", err)
+		return
+	}
+	s.writeJSON(w, infos)
+}
+
+// handlePutSecret creates or replaces a secret.
+//
+// PUT /game/board/secrets/{name}
+func (s *Service) handlePutSecret(w http.ResponseWriter, r *http.Request) {
+	if s.secrets == nil {
+		s.writeError(w, "secrets vault not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	name := r.PathValue("name")
+	if !executor.ValidSecretName(name) {
+		s.writeError(w, "invalid secret name: use letters, digits, '_' or '-'", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	var req PutSecretRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Value == "" {
+		s.writeError(w, "value is required", http.StatusBadRequest)
+		return
+	}
+
+	info, err := s.secrets.Put(r.Context(), name, req.Value, req.Description, req.Scope)
+	if err != nil {
+		s.writeError(w, "failed to store secret", http.StatusInternalServerError)
+		s.logger.Error("Failed to store secret", "name", name, "error", err)
+		return
+	}
+
+	s.logger.Info("Secret stored", "name", name)
+	s.writeJSON(w, info)
+}
+
+// handleDeleteSecret removes a secret.
+//
+// DELETE /game/board/secrets/{name}
+func (s *Service) handleDeleteSecret(w http.ResponseWriter, r *http.Request) {
+	if s.secrets == nil {
+		s.writeError(w, "secrets vault not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	name := r.PathValue("name")
+	if err := s.secrets.Delete(r.Context(), name); err != nil {
+		if errors.Is(err, executor.ErrSecretNotFound) {
+			s.writeError(w, "secret not found", http.StatusNotFound)
+			return
+		}
+		s.writeError(w, "failed to delete secret", http.StatusInternalServerError)
+		s.logger.Error("Failed to delete secret", "name", name, "error", err)
+		return
+	}
+
+	s.logger.Info("Secret deleted", "name", name)
+	w.WriteHeader(http.StatusNoContent)
+}
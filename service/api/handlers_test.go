@@ -19,6 +19,9 @@ import (
 	"github.com/c360studio/semdragons/processor/agentstore"
 	"github.com/c360studio/semdragons/processor/boardcontrol"
 	"github.com/c360studio/semdragons/processor/bossbattle"
+	"github.com/c360studio/semdragons/processor/executor"
+	"github.com/c360studio/semdragons/processor/guildformation"
+	"github.com/c360studio/semdragons/processor/questdagexec"
 	"github.com/c360studio/semstreams/graph"
 	"github.com/c360studio/semstreams/message"
 	"github.com/nats-io/nats.go/jetstream"
@@ -42,8 +45,11 @@ type mockGraph struct {
 	listAgentsFn         func(ctx context.Context, limit int) ([]graph.EntityState, error)
 	listPeerReviewsFn    func(ctx context.Context, limit int) ([]graph.EntityState, error)
 	listEntitiesByTypeFn func(ctx context.Context, entityType string, limit int) ([]graph.EntityState, error)
+	getBlueprintFn       func(ctx context.Context, blueprintID string) (*graph.EntityState, error)
+	listBlueprintsFn     func(ctx context.Context, limit int) ([]graph.EntityState, error)
 	emitEntityFn         func(ctx context.Context, entity graph.Graphable, eventType string) error
 	emitEntityUpdateFn   func(ctx context.Context, entity graph.Graphable, eventType string) error
+	updateGuildFn        func(ctx context.Context, guildID domain.GuildID, eventType string, mutate func(*domain.Guild) error) (*domain.Guild, error)
 }
 
 func (m *mockGraph) Config() *domain.BoardConfig {
@@ -123,6 +129,29 @@ func (m *mockGraph) ListEntitiesByType(ctx context.Context, entityType string, l
 	return nil, nil
 }
 
+func (m *mockGraph) GetBlueprint(ctx context.Context, blueprintID string) (*graph.EntityState, error) {
+	if m.getBlueprintFn != nil {
+		return m.getBlueprintFn(ctx, blueprintID)
+	}
+	return nil, jetstream.ErrKeyNotFound
+}
+
+// GetBlueprintWithRevision defaults to GetBlueprint at revision 1.
+func (m *mockGraph) GetBlueprintWithRevision(ctx context.Context, blueprintID string) (*graph.EntityState, uint64, error) {
+	entity, err := m.GetBlueprint(ctx, blueprintID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entity, 1, nil
+}
+
+func (m *mockGraph) ListBlueprintsByPrefix(ctx context.Context, limit int) ([]graph.EntityState, error) {
+	if m.listBlueprintsFn != nil {
+		return m.listBlueprintsFn(ctx, limit)
+	}
+	return nil, nil
+}
+
 func (m *mockGraph) EmitEntity(ctx context.Context, entity graph.Graphable, eventType string) error {
 	if m.emitEntityFn != nil {
 		return m.emitEntityFn(ctx, entity, eventType)
@@ -130,6 +159,11 @@ func (m *mockGraph) EmitEntity(ctx context.Context, entity graph.Graphable, even
 	return nil
 }
 
+// EmitEntityCAS defaults to EmitEntity, without revision checks.
+func (m *mockGraph) EmitEntityCAS(ctx context.Context, entity graph.Graphable, eventType string, _ uint64) error {
+	return m.EmitEntity(ctx, entity, eventType)
+}
+
 func (m *mockGraph) EmitEntityUpdate(ctx context.Context, entity graph.Graphable, eventType string) error {
 	if m.emitEntityUpdateFn != nil {
 		return m.emitEntityUpdateFn(ctx, entity, eventType)
@@ -137,6 +171,23 @@ func (m *mockGraph) EmitEntityUpdate(ctx context.Context, entity graph.Graphable
 	return nil
 }
 
+// UpdateGuild defaults to a read-mutate-write through GetGuild and
+// EmitEntityUpdate, without revision checks.
+func (m *mockGraph) UpdateGuild(ctx context.Context, guildID domain.GuildID, eventType string, mutate func(*domain.Guild) error) (*domain.Guild, error) {
+	if m.updateGuildFn != nil {
+		return m.updateGuildFn(ctx, guildID, eventType, mutate)
+	}
+	entity, err := m.GetGuild(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+	guild := domain.GuildFromEntityState(entity)
+	if err := mutate(guild); err != nil {
+		return nil, err
+	}
+	return guild, m.EmitEntityUpdate(ctx, guild, eventType)
+}
+
 // mockWorld implements WorldStateProvider.
 type mockWorld struct {
 	worldStateFn func(ctx context.Context) (*domain.WorldState, error)
@@ -165,6 +216,9 @@ type mockStore struct {
 	getInventoryFn     func(agentID domain.AgentID) *agentstore.AgentInventory
 	useConsumableFn    func(ctx context.Context, agentID domain.AgentID, consumableID string, questID *domain.QuestID) error
 	getActiveEffectsFn func(agentID domain.AgentID) []agentstore.ActiveEffect
+	economyReportFn    func() agentstore.EconomyReport
+	buyForGuildFn      func(ctx context.Context, guildID domain.GuildID, officerID domain.AgentID, itemID string) (*domain.TreasuryTransaction, error)
+	sponsorFn          func(ctx context.Context, guildID domain.GuildID, officerID, memberID domain.AgentID, itemID string) (*domain.TreasuryTransaction, error)
 }
 
 func (m *mockStore) ListItems(agentTier domain.TrustTier) []agentstore.StoreItem {
@@ -223,6 +277,27 @@ func (m *mockStore) GetActiveEffects(agentID domain.AgentID) []agentstore.Active
 	return nil
 }
 
+func (m *mockStore) EconomyReport() agentstore.EconomyReport {
+	if m.economyReportFn != nil {
+		return m.economyReportFn()
+	}
+	return agentstore.EconomyReport{}
+}
+
+func (m *mockStore) BuyForGuild(ctx context.Context, guildID domain.GuildID, officerID domain.AgentID, itemID string) (*domain.TreasuryTransaction, error) {
+	if m.buyForGuildFn != nil {
+		return m.buyForGuildFn(ctx, guildID, officerID, itemID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockStore) SponsorConsumable(ctx context.Context, guildID domain.GuildID, officerID, memberID domain.AgentID, itemID string) (*domain.TreasuryTransaction, error) {
+	if m.sponsorFn != nil {
+		return m.sponsorFn(ctx, guildID, officerID, memberID, itemID)
+	}
+	return nil, errors.New("not implemented")
+}
+
 // =============================================================================
 // TEST HELPER
 // =============================================================================
@@ -1003,6 +1078,35 @@ func TestHandleCreateQuest(t *testing.T) {
 			},
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name: "blueprint hint makes a party quest",
+			body: map[string]any{
+				"objective": "Ship rate limiting",
+				"hints": map[string]any{
+					"blueprint":      "feature",
+					"blueprint_args": map[string]any{"location": "service/api"},
+				},
+			},
+			wantStatus: http.StatusCreated,
+			checkBody: func(t *testing.T, body []byte) {
+				var q domain.Quest
+				decodeJSON(t, body, &q)
+				if q.DAGBlueprint != "feature" {
+					t.Errorf("dag_blueprint: got %q, want feature", q.DAGBlueprint)
+				}
+				if !q.PartyRequired || q.MinPartySize < 2 {
+					t.Errorf("party: got required=%v size=%d, want party quest", q.PartyRequired, q.MinPartySize)
+				}
+			},
+		},
+		{
+			name: "unknown blueprint hint returns 400",
+			body: map[string]any{
+				"objective": "A quest",
+				"hints":     map[string]any{"blueprint": "nope"},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
 		{
 			name: "valid difficulty hint is applied",
 			body: map[string]any{
@@ -1865,6 +1969,239 @@ func TestHandleRetireAgent(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// AGENT MEMORY HANDLER TESTS
+// =============================================================================
+
+// agentWithMemories returns a sample agent holding three quest memories.
+func agentWithMemories() *agentprogression.Agent {
+	a := sampleAgent()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	a.Memories = []domain.QuestMemory{
+		{ID: "mem-q1", QuestID: "q1", Objective: "Parse config", Outcome: domain.MemoryOutcomeSuccess, RecordedAt: base},
+		{ID: "mem-q2", QuestID: "q2", Objective: "Fix flaky test", Outcome: domain.MemoryOutcomeFailure, RecordedAt: base.Add(time.Hour)},
+		{ID: "mem-q3", QuestID: "q3", Objective: "Add metrics", Outcome: domain.MemoryOutcomeSuccess, RecordedAt: base.Add(2 * time.Hour)},
+	}
+	return a
+}
+
+func TestHandleListAgentMemories(t *testing.T) {
+	es := makeAgentEntityState(agentWithMemories())
+	g := &mockGraph{
+		getAgentFn: func(_ context.Context, _ domain.AgentID) (*graph.EntityState, error) { return &es, nil },
+	}
+	svc := newTestService(g, &mockWorld{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /agents/{id}/memories", svc.handleListAgentMemories)
+
+	req := httptest.NewRequest(http.MethodGet, "/agents/a1/memories", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rr.Code, http.StatusOK)
+	}
+	var got []domain.QuestMemory
+	decodeJSON(t, rr.Body.Bytes(), &got)
+	if len(got) != 3 {
+		t.Fatalf("memories: got %d, want 3", len(got))
+	}
+	if got[0].ID != "mem-q3" {
+		t.Errorf("first memory: got %q, want newest mem-q3", got[0].ID)
+	}
+}
+
+func TestHandleListBlueprints(t *testing.T) {
+	stored := questdagexec.Blueprint{
+		ID: "feature", Name: "Feature delivery", Uses: 5, Successes: 5,
+		Nodes: []questdagexec.QuestNode{{ID: "a", Objective: "Do it"}},
+	}
+	g := &mockGraph{
+		listBlueprintsFn: func(_ context.Context, _ int) ([]graph.EntityState, error) {
+			return []graph.EntityState{{ID: stored.EntityID(), Triples: stored.Triples()}}, nil
+		},
+	}
+	svc := newTestService(g, &mockWorld{})
+
+	req := httptest.NewRequest(http.MethodGet, "/blueprints", nil)
+	rr := httptest.NewRecorder()
+	svc.handleListBlueprints(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rr.Code, http.StatusOK)
+	}
+	var got []RankedBlueprint
+	decodeJSON(t, rr.Body.Bytes(), &got)
+	if len(got) != len(questdagexec.DefaultBlueprints()) {
+		t.Fatalf("blueprints: got %d, want %d", len(got), len(questdagexec.DefaultBlueprints()))
+	}
+	if got[0].Blueprint.ID != "feature" || got[0].Blueprint.BuiltIn {
+		t.Errorf("first blueprint: got %q (built_in=%v), want stored feature override", got[0].Blueprint.ID, got[0].Blueprint.BuiltIn)
+	}
+}
+
+func TestHandlePromoteBlueprint(t *testing.T) {
+	completedDAG := &domain.Quest{
+		ID:                   "test.dev.game.board1.quest.q1",
+		Status:               domain.QuestCompleted,
+		DecomposabilityClass: domain.DecomposableParallel,
+		DAGDefinition: questdagexec.QuestDAG{Nodes: []questdagexec.QuestNode{
+			{ID: "a", Objective: "Research A"},
+			{ID: "b", Objective: "Summarize", DependsOn: []string{"a"}},
+		}},
+	}
+	inProgress := &domain.Quest{ID: "test.dev.game.board1.quest.q2", Status: domain.QuestInProgress}
+
+	tests := []struct {
+		name       string
+		body       PromoteBlueprintRequest
+		quest      *domain.Quest
+		wantStatus int
+		wantSaved  bool
+	}{
+		{name: "completed DAG is promoted", body: PromoteBlueprintRequest{QuestID: "q1", ID: "research_pair", Name: "Research pair"}, quest: completedDAG, wantStatus: http.StatusCreated, wantSaved: true},
+		{name: "missing name returns 400", body: PromoteBlueprintRequest{QuestID: "q1", ID: "research_pair"}, quest: completedDAG, wantStatus: http.StatusBadRequest},
+		{name: "invalid id returns 400", body: PromoteBlueprintRequest{QuestID: "q1", ID: "Bad ID", Name: "x"}, quest: completedDAG, wantStatus: http.StatusBadRequest},
+		{name: "existing id returns 409", body: PromoteBlueprintRequest{QuestID: "q1", ID: "feature", Name: "x"}, quest: completedDAG, wantStatus: http.StatusConflict},
+		{name: "unfinished quest returns 409", body: PromoteBlueprintRequest{QuestID: "q2", ID: "new_one", Name: "x"}, quest: inProgress, wantStatus: http.StatusConflict},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			es := makeQuestEntityState(tc.quest)
+			var saved *questdagexec.Blueprint
+			g := &mockGraph{
+				getQuestFn: func(_ context.Context, _ domain.QuestID) (*graph.EntityState, error) { return &es, nil },
+				emitEntityFn: func(_ context.Context, e graph.Graphable, _ string) error {
+					saved = e.(*questdagexec.Blueprint)
+					return nil
+				},
+			}
+			svc := newTestService(g, &mockWorld{})
+
+			bodyBytes, _ := json.Marshal(tc.body)
+			req := httptest.NewRequest(http.MethodPost, "/blueprints/promote", bytes.NewReader(bodyBytes))
+			rr := httptest.NewRecorder()
+			svc.handlePromoteBlueprint(rr, req)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status: got %d, want %d (%s)", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if !tc.wantSaved {
+				if saved != nil {
+					t.Error("blueprint should not be saved")
+				}
+				return
+			}
+			if saved == nil {
+				t.Fatal("expected blueprint to be saved")
+			}
+			if len(saved.Nodes) != 2 || saved.SourceQuestID != string(completedDAG.ID) {
+				t.Errorf("saved: got %d nodes from %q", len(saved.Nodes), saved.SourceQuestID)
+			}
+			if len(saved.Classes) != 1 || saved.Classes[0] != domain.DecomposableParallel {
+				t.Errorf("classes: got %v, want quest class", saved.Classes)
+			}
+		})
+	}
+}
+
+func TestHandleDeleteAgentMemory(t *testing.T) {
+	tests := []struct {
+		name       string
+		memoryID   string
+		wantStatus int
+		wantEmit   bool
+	}{
+		{name: "existing memory returns 204", memoryID: "mem-q2", wantStatus: http.StatusNoContent, wantEmit: true},
+		{name: "unknown memory returns 404", memoryID: "mem-nope", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			es := makeAgentEntityState(agentWithMemories())
+			var emitted *agentprogression.Agent
+			g := &mockGraph{
+				getAgentFn: func(_ context.Context, _ domain.AgentID) (*graph.EntityState, error) { return &es, nil },
+				emitEntityUpdateFn: func(_ context.Context, e graph.Graphable, _ string) error {
+					emitted = e.(*agentprogression.Agent)
+					return nil
+				},
+			}
+			svc := newTestService(g, &mockWorld{})
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("DELETE /agents/{id}/memories/{memoryId}", svc.handleDeleteAgentMemory)
+
+			req := httptest.NewRequest(http.MethodDelete, "/agents/a1/memories/"+tc.memoryID, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if rr.Code != tc.wantStatus {
+				t.Errorf("status: got %d, want %d", rr.Code, tc.wantStatus)
+			}
+			if tc.wantEmit {
+				if emitted == nil {
+					t.Fatal("expected agent update to be emitted")
+				}
+				if len(emitted.Memories) != 2 {
+					t.Errorf("remaining memories: got %d, want 2", len(emitted.Memories))
+				}
+			} else if emitted != nil {
+				t.Error("agent should not be updated when memory is missing")
+			}
+		})
+	}
+}
+
+func TestHandlePruneAgentMemories(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantStatus    int
+		wantRemoved   int
+		wantRemaining int
+	}{
+		{name: "prune by outcome", body: `{"outcome":"failure"}`, wantStatus: http.StatusOK, wantRemoved: 1, wantRemaining: 2},
+		{name: "keep latest", body: `{"keep_latest":1}`, wantStatus: http.StatusOK, wantRemoved: 2, wantRemaining: 1},
+		{name: "older than", body: `{"older_than":"2026-01-01T01:30:00Z"}`, wantStatus: http.StatusOK, wantRemoved: 2, wantRemaining: 1},
+		{name: "no criteria returns 400", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "bad outcome returns 400", body: `{"outcome":"meh"}`, wantStatus: http.StatusBadRequest},
+		{name: "bad timestamp returns 400", body: `{"older_than":"yesterday"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			es := makeAgentEntityState(agentWithMemories())
+			g := &mockGraph{
+				getAgentFn: func(_ context.Context, _ domain.AgentID) (*graph.EntityState, error) { return &es, nil },
+			}
+			svc := newTestService(g, &mockWorld{})
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /agents/{id}/memories/prune", svc.handlePruneAgentMemories)
+
+			req := httptest.NewRequest(http.MethodPost, "/agents/a1/memories/prune", strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status: got %d, want %d (body: %s)", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var resp PruneMemoriesResponse
+			decodeJSON(t, rr.Body.Bytes(), &resp)
+			if resp.Removed != tc.wantRemoved || resp.Remaining != tc.wantRemaining {
+				t.Errorf("got removed=%d remaining=%d, want removed=%d remaining=%d",
+					resp.Removed, resp.Remaining, tc.wantRemoved, tc.wantRemaining)
+			}
+		})
+	}
+}
+
 // =============================================================================
 // STUB HANDLER TESTS (501 Not Implemented)
 // =============================================================================
@@ -2088,6 +2425,28 @@ func TestHandleGetStoreItem(t *testing.T) {
 	}
 }
 
+func TestHandleStoreEconomy(t *testing.T) {
+	store := &mockStore{
+		economyReportFn: func() agentstore.EconomyReport {
+			return agentstore.EconomyReport{TotalSupplied: 1000, TotalSpent: 250, NetFlow: 750, SinkRatio: 0.25}
+		},
+	}
+	svc := newTestServiceWithStore(&mockGraph{}, &mockWorld{}, store)
+
+	req := httptest.NewRequest(http.MethodGet, "/store/economy", nil)
+	rr := httptest.NewRecorder()
+	svc.handleStoreEconomy(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d\nbody: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var report agentstore.EconomyReport
+	decodeJSON(t, rr.Body.Bytes(), &report)
+	if report.NetFlow != 750 || report.SinkRatio != 0.25 {
+		t.Errorf("report: got net %d ratio %v, want 750 / 0.25", report.NetFlow, report.SinkRatio)
+	}
+}
+
 func TestHandlePurchase(t *testing.T) {
 	tool := sampleStoreItem()
 	agent := sampleAgent()
@@ -2918,6 +3277,11 @@ func TestHandleSubmitReview(t *testing.T) {
 	soloReview.IsSoloTask = true
 	soloES := makePeerReviewEntityState(soloReview)
 
+	// Member-to-member pending review
+	peerReview := samplePeerReview()
+	peerReview.IsMemberToMember = true
+	peerES := makePeerReviewEntityState(peerReview)
+
 	tests := []struct {
 		name               string
 		pathID             string
@@ -2988,6 +3352,28 @@ func TestHandleSubmitReview(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:   "member-to-member — submission uses peer direction",
+			pathID: "r1",
+			body: map[string]any{
+				"reviewer_id": string(peerReview.MemberID),
+				"ratings":     map[string]any{"q1": 4, "q2": 4, "q3": 4},
+			},
+			getPeerReviewFn: func(_ context.Context, _ domain.PeerReviewID) (*graph.EntityState, error) {
+				return &peerES, nil
+			},
+			wantStatus: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var review domain.PeerReview
+				decodeJSON(t, body, &review)
+				if review.Status != domain.PeerReviewPartial {
+					t.Errorf("status: got %q, want partial", review.Status)
+				}
+				if review.MemberReview == nil || review.MemberReview.Direction != domain.ReviewDirectionMemberToMember {
+					t.Errorf("member_review: got %+v, want member_to_member direction", review.MemberReview)
+				}
+			},
+		},
 		{
 			name:   "solo task — leader submits and completes immediately",
 			pathID: "r1",
@@ -4108,3 +4494,549 @@ func TestHandleDMIntervene(t *testing.T) {
 		}
 	})
 }
+
+func TestHandleGetGuildTreasury(t *testing.T) {
+	guild := &domain.Guild{ID: "c360.prod.game.board1.guild.g1", Name: "Crafters", Status: domain.GuildActive}
+	guild.Deposit(domain.TreasuryTransaction{Kind: domain.TreasuryTxTax, Amount: 40, AgentID: "a1"})
+	guild.Deposit(domain.TreasuryTransaction{Kind: domain.TreasuryTxTax, Amount: 10, AgentID: "a2"})
+	entity := &graph.EntityState{ID: string(guild.ID), Triples: guild.Triples()}
+
+	svc := newTestService(&mockGraph{
+		getGuildFn: func(_ context.Context, _ domain.GuildID) (*graph.EntityState, error) {
+			return entity, nil
+		},
+	}, &mockWorld{})
+
+	req := httptest.NewRequest(http.MethodGet, "/guilds/g1/treasury", nil)
+	req.SetPathValue("id", "g1")
+	rr := httptest.NewRecorder()
+	svc.handleGetGuildTreasury(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d\nbody: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var resp GuildTreasuryResponse
+	decodeJSON(t, rr.Body.Bytes(), &resp)
+	if resp.Balance != 50 || len(resp.Transactions) != 2 {
+		t.Fatalf("treasury: got balance %d with %d txs, want 50 / 2", resp.Balance, len(resp.Transactions))
+	}
+	if resp.Transactions[0].AgentID != "a2" {
+		t.Errorf("transactions should be newest first, got %+v", resp.Transactions[0])
+	}
+}
+
+func TestHandleGuildPurchase(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"success", `{"officer_id":"c360.prod.game.board1.agent.o1","item_id":"web_search"}`, nil, http.StatusOK},
+		{"missing fields", `{"officer_id":"o1"}`, nil, http.StatusBadRequest},
+		{"not officer", `{"officer_id":"o1","item_id":"web_search"}`, agentstore.ErrNotGuildOfficer, http.StatusForbidden},
+		{"insufficient", `{"officer_id":"o1","item_id":"web_search"}`, domain.ErrInsufficientTreasury, http.StatusConflict},
+		{"already shared", `{"officer_id":"o1","item_id":"web_search"}`, agentstore.ErrAlreadyShared, http.StatusConflict},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotOfficer domain.AgentID
+			store := &mockStore{
+				buyForGuildFn: func(_ context.Context, _ domain.GuildID, officerID domain.AgentID, itemID string) (*domain.TreasuryTransaction, error) {
+					gotOfficer = officerID
+					if tc.err != nil {
+						return nil, tc.err
+					}
+					return &domain.TreasuryTransaction{Kind: domain.TreasuryTxToolBuy, Amount: -80, ItemID: itemID}, nil
+				},
+			}
+			svc := newTestServiceWithStore(&mockGraph{}, &mockWorld{}, store)
+
+			req := httptest.NewRequest(http.MethodPost, "/guilds/g1/treasury/purchase", strings.NewReader(tc.body))
+			req.SetPathValue("id", "g1")
+			rr := httptest.NewRecorder()
+			svc.handleGuildPurchase(rr, req)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status: got %d, want %d\nbody: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if tc.wantStatus == http.StatusOK && gotOfficer != "o1" {
+				t.Errorf("officer ID should be normalized to instance, got %q", gotOfficer)
+			}
+		})
+	}
+}
+
+func TestHandleGuildSponsor(t *testing.T) {
+	store := &mockStore{
+		sponsorFn: func(_ context.Context, _ domain.GuildID, _, memberID domain.AgentID, itemID string) (*domain.TreasuryTransaction, error) {
+			if memberID != "m1" {
+				return nil, agentstore.ErrNotGuildMember
+			}
+			return &domain.TreasuryTransaction{Kind: domain.TreasuryTxSponsorship, Beneficiary: memberID, ItemID: itemID}, nil
+		},
+	}
+	svc := newTestServiceWithStore(&mockGraph{}, &mockWorld{}, store)
+
+	for _, tc := range []struct {
+		member     string
+		wantStatus int
+	}{
+		{"m1", http.StatusOK},
+		{"stranger", http.StatusForbidden},
+	} {
+		body := `{"officer_id":"o1","member_id":"` + tc.member + `","item_id":"cooldown_skip"}`
+		req := httptest.NewRequest(http.MethodPost, "/guilds/g1/treasury/sponsor", strings.NewReader(body))
+		req.SetPathValue("id", "g1")
+		rr := httptest.NewRecorder()
+		svc.handleGuildSponsor(rr, req)
+		if rr.Code != tc.wantStatus {
+			t.Errorf("member %s: status %d, want %d\nbody: %s", tc.member, rr.Code, tc.wantStatus, rr.Body.String())
+		}
+	}
+}
+
+// mockGovernance implements GovernanceProvider for handler tests.
+type mockGovernance struct {
+	proposals []domain.GuildProposal
+	openFn    func(params guildformation.OpenProposalParams) (*domain.GuildProposal, error)
+	voteFn    func(proposalID string, voterID domain.AgentID, approve bool) (*domain.GuildProposal, error)
+}
+
+func (m *mockGovernance) ListProposals(_ domain.GuildID) []domain.GuildProposal {
+	return m.proposals
+}
+
+func (m *mockGovernance) OpenProposal(_ context.Context, _ domain.GuildID, params guildformation.OpenProposalParams) (*domain.GuildProposal, error) {
+	return m.openFn(params)
+}
+
+func (m *mockGovernance) CastVote(_ context.Context, _ domain.GuildID, proposalID string, voterID domain.AgentID, approve bool, _ string) (*domain.GuildProposal, error) {
+	return m.voteFn(proposalID, voterID, approve)
+}
+
+func TestHandleListGuildProposals(t *testing.T) {
+	svc := newTestService(&mockGraph{}, &mockWorld{})
+	svc.governance = &mockGovernance{proposals: []domain.GuildProposal{{ID: "old"}, {ID: "new"}}}
+
+	req := httptest.NewRequest(http.MethodGet, "/guilds/g1/proposals", nil)
+	req.SetPathValue("id", "g1")
+	rr := httptest.NewRecorder()
+	svc.handleListGuildProposals(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rr.Code, http.StatusOK)
+	}
+	var got []domain.GuildProposal
+	decodeJSON(t, rr.Body.Bytes(), &got)
+	if len(got) != 2 || got[0].ID != "new" {
+		t.Errorf("proposals should be newest first, got %+v", got)
+	}
+}
+
+func TestHandleListGuildProposals_Unavailable(t *testing.T) {
+	svc := newTestService(&mockGraph{}, &mockWorld{})
+	req := httptest.NewRequest(http.MethodGet, "/guilds/g1/proposals", nil)
+	req.SetPathValue("id", "g1")
+	rr := httptest.NewRecorder()
+	svc.handleListGuildProposals(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("status: got %d, want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestHandleOpenGuildProposal(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"success", `{"proposer_id":"a1","kind":"promote","target_id":"a2","new_rank":"member"}`, nil, http.StatusCreated},
+		{"missing kind", `{"proposer_id":"a1"}`, nil, http.StatusBadRequest},
+		{"not member", `{"proposer_id":"x","kind":"disband"}`, guildformation.ErrNotMember, http.StatusForbidden},
+		{"duplicate", `{"proposer_id":"a1","kind":"disband"}`, guildformation.ErrDuplicateProposal, http.StatusConflict},
+		{"invalid", `{"proposer_id":"a1","kind":"coup"}`, guildformation.ErrInvalidProposal, http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got guildformation.OpenProposalParams
+			svc := newTestService(&mockGraph{}, &mockWorld{})
+			svc.governance = &mockGovernance{openFn: func(params guildformation.OpenProposalParams) (*domain.GuildProposal, error) {
+				got = params
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				return &domain.GuildProposal{ID: "p1", Kind: params.Kind, Status: domain.ProposalOpen}, nil
+			}}
+
+			req := httptest.NewRequest(http.MethodPost, "/guilds/g1/proposals", strings.NewReader(tc.body))
+			req.SetPathValue("id", "g1")
+			rr := httptest.NewRecorder()
+			svc.handleOpenGuildProposal(rr, req)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status: got %d, want %d\nbody: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if tc.wantStatus == http.StatusCreated && (got.NewRank != domain.GuildRankMember || got.TargetID != "a2") {
+				t.Errorf("params not passed through: %+v", got)
+			}
+		})
+	}
+}
+
+func TestHandleCastGuildVote(t *testing.T) {
+	svc := newTestService(&mockGraph{}, &mockWorld{})
+	svc.governance = &mockGovernance{voteFn: func(proposalID string, voterID domain.AgentID, approve bool) (*domain.GuildProposal, error) {
+		switch {
+		case proposalID != "p1":
+			return nil, guildformation.ErrProposalNotFound
+		case voterID == "dup":
+			return nil, guildformation.ErrAlreadyVoted
+		}
+		return &domain.GuildProposal{ID: proposalID, Status: domain.ProposalPassed,
+			Votes: []domain.GuildVote{{VoterID: voterID, Approve: approve}}}, nil
+	}}
+
+	for _, tc := range []struct {
+		proposal, voter string
+		wantStatus      int
+	}{
+		{"p1", "a1", http.StatusOK},
+		{"p2", "a1", http.StatusNotFound},
+		{"p1", "dup", http.StatusConflict},
+	} {
+		body := `{"voter_id":"` + tc.voter + `","approve":true}`
+		req := httptest.NewRequest(http.MethodPost, "/guilds/g1/proposals/"+tc.proposal+"/votes", strings.NewReader(body))
+		req.SetPathValue("id", "g1")
+		req.SetPathValue("proposalId", tc.proposal)
+		rr := httptest.NewRecorder()
+		svc.handleCastGuildVote(rr, req)
+		if rr.Code != tc.wantStatus {
+			t.Errorf("%s/%s: status %d, want %d\nbody: %s", tc.proposal, tc.voter, rr.Code, tc.wantStatus, rr.Body.String())
+		}
+	}
+}
+
+func lessonTestGuild() *domain.Guild {
+	now := time.Now()
+	return &domain.Guild{
+		ID:      "c360.prod.game.board1.guild.g1",
+		Name:    "Crafters",
+		Status:  domain.GuildActive,
+		Members: []domain.GuildMember{{AgentID: "c360.prod.game.board1.agent.a1", Rank: domain.GuildRankMember}},
+		Lessons: []domain.Lesson{
+			{ID: "info", Skill: domain.SkillCodeGen, Summary: "prefer short names", Severity: domain.LessonSeverityInfo, LastSeenAt: now},
+			{ID: "critical", Skill: domain.SkillCodeGen, Summary: "never log secrets", Severity: domain.LessonSeverityCritical, LastSeenAt: now},
+			{ID: "retired", Skill: domain.SkillCodeGen, Summary: "globals are fine", Retired: true},
+		},
+	}
+}
+
+func TestHandleListGuildLessons(t *testing.T) {
+	guild := lessonTestGuild()
+	svc := newTestService(&mockGraph{
+		getGuildFn: func(_ context.Context, _ domain.GuildID) (*graph.EntityState, error) {
+			return &graph.EntityState{ID: string(guild.ID), Triples: guild.Triples()}, nil
+		},
+	}, &mockWorld{})
+
+	for _, tc := range []struct {
+		query   string
+		wantIDs []string
+	}{
+		{"", []string{"critical", "info"}},
+		{"?include_retired=true", []string{"critical", "info", "retired"}},
+		{"?skill=analysis", nil},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/guilds/g1/lessons"+tc.query, nil)
+		req.SetPathValue("id", "g1")
+		rr := httptest.NewRecorder()
+		svc.handleListGuildLessons(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%q: status %d\nbody: %s", tc.query, rr.Code, rr.Body.String())
+		}
+		var resp []RankedLesson
+		decodeJSON(t, rr.Body.Bytes(), &resp)
+		var got []string
+		for _, l := range resp {
+			got = append(got, l.Lesson.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.wantIDs, ",") {
+			t.Errorf("%q: lessons = %v, want %v", tc.query, got, tc.wantIDs)
+		}
+	}
+}
+
+func TestHandleVoteGuildLesson(t *testing.T) {
+	for _, tc := range []struct {
+		name, lesson, body string
+		wantStatus         int
+	}{
+		{"upvote", "info", `{"voter_id":"a1","up":true}`, http.StatusOK},
+		{"non-member", "info", `{"voter_id":"x9","up":true}`, http.StatusForbidden},
+		{"unknown lesson", "nope", `{"voter_id":"a1","up":true}`, http.StatusNotFound},
+		{"missing voter", "info", `{"up":true}`, http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			guild := lessonTestGuild()
+			var saved *domain.Guild
+			svc := newTestService(&mockGraph{
+				getGuildFn: func(_ context.Context, _ domain.GuildID) (*graph.EntityState, error) {
+					return &graph.EntityState{ID: string(guild.ID), Triples: guild.Triples()}, nil
+				},
+				emitEntityUpdateFn: func(_ context.Context, entity graph.Graphable, _ string) error {
+					saved, _ = entity.(*domain.Guild)
+					return nil
+				},
+			}, &mockWorld{})
+
+			req := httptest.NewRequest(http.MethodPost, "/guilds/g1/lessons/"+tc.lesson+"/votes", strings.NewReader(tc.body))
+			req.SetPathValue("id", "g1")
+			req.SetPathValue("lessonId", tc.lesson)
+			rr := httptest.NewRecorder()
+			svc.handleVoteGuildLesson(rr, req)
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status %d, want %d\nbody: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			if saved == nil || saved.Lesson("info").Upvotes != 1 {
+				t.Errorf("vote not persisted: %+v", saved)
+			}
+		})
+	}
+}
+
+func TestHandleVoteGuildLesson_VotesOnFreshGuild(t *testing.T) {
+	stale := lessonTestGuild()
+	// Another request recorded the same member's vote after the handler's
+	// first read; the CAS update must see it and reject the duplicate.
+	fresh := lessonTestGuild()
+	fresh.Lesson("info").Voters = []domain.AgentID{"a1"}
+	svc := newTestService(&mockGraph{
+		getGuildFn: func(_ context.Context, _ domain.GuildID) (*graph.EntityState, error) {
+			return &graph.EntityState{ID: string(stale.ID), Triples: stale.Triples()}, nil
+		},
+		updateGuildFn: func(_ context.Context, _ domain.GuildID, _ string, mutate func(*domain.Guild) error) (*domain.Guild, error) {
+			if err := mutate(fresh); err != nil {
+				return nil, err
+			}
+			return fresh, nil
+		},
+	}, &mockWorld{})
+
+	req := httptest.NewRequest(http.MethodPost, "/guilds/g1/lessons/info/votes", strings.NewReader(`{"voter_id":"a1","up":true}`))
+	req.SetPathValue("id", "g1")
+	req.SetPathValue("lessonId", "info")
+	rr := httptest.NewRecorder()
+	svc.handleVoteGuildLesson(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status %d, want %d\nbody: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+}
+
+// =============================================================================
+// DAG VISUALIZATION TESTS
+// =============================================================================
+
+func TestHandleGetQuestDAG(t *testing.T) {
+	parent := sampleQuest()
+	parent.Status = domain.QuestInProgress
+	parent.DAGExecutionID = "exec-1"
+	parent.DAGDefinition = questdagexec.QuestDAG{Nodes: []questdagexec.QuestNode{
+		{ID: "design", Objective: "Design it"},
+		{ID: "build", Objective: "Build it", DependsOn: []string{"design"}},
+	}}
+	parent.DAGNodeStates = map[string]string{"design": questdagexec.NodeCompleted, "build": questdagexec.NodeInProgress}
+	parentES := makeQuestEntityState(parent)
+	plainES := makeQuestEntityState(sampleQuest())
+
+	tests := []struct {
+		name        string
+		query       string
+		entity      *graph.EntityState
+		wantStatus  int
+		wantType    string
+		wantContain string
+	}{
+		{name: "default json", entity: &parentES, wantStatus: http.StatusOK, wantType: "application/json", wantContain: `"critical_path"`},
+		{name: "mermaid", query: "?format=mermaid", entity: &parentES, wantStatus: http.StatusOK, wantType: "text/vnd.mermaid", wantContain: "n_design --> n_build"},
+		{name: "dot", query: "?format=dot", entity: &parentES, wantStatus: http.StatusOK, wantType: "text/vnd.graphviz", wantContain: `"design" -> "build"`},
+		{name: "unknown format", query: "?format=svg", entity: &parentES, wantStatus: http.StatusBadRequest},
+		{name: "quest without DAG", entity: &plainES, wantStatus: http.StatusNotFound},
+		{name: "missing quest", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := &mockGraph{getQuestFn: func(_ context.Context, id domain.QuestID) (*graph.EntityState, error) {
+				if tc.entity == nil || id != domain.QuestID("q1") {
+					return nil, jetstream.ErrKeyNotFound
+				}
+				return tc.entity, nil
+			}}
+			svc := newTestService(g, &mockWorld{})
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /quests/{id}/dag", svc.handleGetQuestDAG)
+
+			req := httptest.NewRequest(http.MethodGet, "/quests/q1/dag"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status: got %d, want %d (body %s)", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if tc.wantType != "" && !strings.HasPrefix(rr.Header().Get("Content-Type"), tc.wantType) {
+				t.Errorf("content type: got %q, want %q", rr.Header().Get("Content-Type"), tc.wantType)
+			}
+			if tc.wantContain != "" && !strings.Contains(rr.Body.String(), tc.wantContain) {
+				t.Errorf("body missing %q:\n%s", tc.wantContain, rr.Body.String())
+			}
+		})
+	}
+}
+
+// =============================================================================
+// TOOL SECRETS HANDLER TESTS
+// =============================================================================
+
+// secretEntry is a minimal jetstream.KeyValueEntry carrying a stored value.
+type secretEntry struct {
+	value []byte
+}
+
+func (e *secretEntry) Bucket() string                  { return executor.SecretsBucket }
+func (e *secretEntry) Key() string                     { return "" }
+func (e *secretEntry) Value() []byte                   { return e.value }
+func (e *secretEntry) Revision() uint64                { return 1 }
+func (e *secretEntry) Created() time.Time              { return time.Time{} }
+func (e *secretEntry) Delta() uint64                   { return 0 }
+func (e *secretEntry) Operation() jetstream.KeyValueOp { return jetstream.KeyValuePut }
+
+// newTestSecretsService returns a Service whose vault is backed by an
+// in-memory map, so put and delete round-trip through real encryption.
+func newTestSecretsService(t *testing.T) *Service {
+	t.Helper()
+	stored := map[string][]byte{}
+	bucket := &mockKeyValue{
+		getFn: func(_ context.Context, key string) (jetstream.KeyValueEntry, error) {
+			v, ok := stored[key]
+			if !ok {
+				return nil, jetstream.ErrKeyNotFound
+			}
+			return &secretEntry{value: v}, nil
+		},
+		putFn: func(_ context.Context, key string, value []byte) (uint64, error) {
+			stored[key] = value
+			return 1, nil
+		},
+	}
+	vault, err := executor.NewSecretVault(bucket, bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("NewSecretVault: %v", err)
+	}
+	svc := newTestService(&mockGraph{}, &mockWorld{})
+	svc.secrets = vault
+	return svc
+}
+
+func TestHandleSecrets(t *testing.T) {
+	tests := []struct {
+		name       string
+		noVault    bool
+		method     string
+		secret     string
+		body       string
+		wantStatus int
+	}{
+		{name: "list without vault", noVault: true, method: http.MethodGet, wantStatus: http.StatusServiceUnavailable},
+		{name: "put without vault", noVault: true, method: http.MethodPut, secret: "npm_token", body: `{"value":"x"}`, wantStatus: http.StatusServiceUnavailable},
+		{name: "list empty", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "put", method: http.MethodPut, secret: "npm_token", body: `{"value":"s3cret","description":"publish"}`, wantStatus: http.StatusOK},
+		{name: "put invalid name", method: http.MethodPut, secret: "bad.name", body: `{"value":"x"}`, wantStatus: http.StatusBadRequest},
+		{name: "put missing value", method: http.MethodPut, secret: "npm_token", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "put bad body", method: http.MethodPut, secret: "npm_token", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "delete missing", method: http.MethodDelete, secret: "npm_token", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := newTestService(&mockGraph{}, &mockWorld{})
+			if !tc.noVault {
+				svc = newTestSecretsService(t)
+			}
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /board/secrets", svc.handleListSecrets)
+			mux.HandleFunc("PUT /board/secrets/{name}", svc.handlePutSecret)
+			mux.HandleFunc("DELETE /board/secrets/{name}", svc.handleDeleteSecret)
+
+			path := "/board/secrets"
+			if tc.secret != "" {
+				path += "/" + tc.secret
+			}
+			req := httptest.NewRequest(tc.method, path, strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status: got %d, want %d (body %s)", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if strings.Contains(rr.Body.String(), "s3cret") {
+				t.Errorf("response leaked the secret value: %s", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleSecrets_PutThenDelete(t *testing.T) {
+	svc := newTestSecretsService(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /board/secrets/{name}", svc.handlePutSecret)
+	mux.HandleFunc("DELETE /board/secrets/{name}", svc.handleDeleteSecret)
+
+	body := `{"value":"s3cret","scope":{"repos":["web-app"]}}`
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/board/secrets/npm_token", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("put status: got %d (body %s)", rr.Code, rr.Body.String())
+	}
+	var info executor.SecretInfo
+	decodeJSON(t, rr.Body.Bytes(), &info)
+	if info.Name != "npm_token" || len(info.Scope.Repos) != 1 || info.Scope.Repos[0] != "web-app" {
+		t.Errorf("unexpected secret info: %+v", info)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/board/secrets/npm_token", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("delete status: got %d (body %s)", rr.Code, rr.Body.String())
+	}
+}
+
+func TestHandleToolUsage_NoComponents(t *testing.T) {
+	svc := newTestService(&mockGraph{}, &mockWorld{})
+	rr := httptest.NewRecorder()
+	svc.handleToolUsage(rr, httptest.NewRequest(http.MethodGet, "/board/tools/usage", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d", rr.Code)
+	}
+	if got := strings.TrimSpace(rr.Body.String()); got != "[]" {
+		t.Errorf("body = %s; want []", got)
+	}
+}
+
+func TestHandleSearchHealth_NoComponents(t *testing.T) {
+	svc := newTestService(&mockGraph{}, &mockWorld{})
+	rr := httptest.NewRecorder()
+	svc.handleSearchHealth(rr, httptest.NewRequest(http.MethodGet, "/board/tools/search/health", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d", rr.Code)
+	}
+	if got := strings.TrimSpace(rr.Body.String()); got != "[]" {
+		t.Errorf("body = %s; want []", got)
+	}
+}
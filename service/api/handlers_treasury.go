@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/agentstore"
+)
+
+// =============================================================================
+// GUILD TREASURY — inspect the ledger and spend shared XP
+// =============================================================================
+
+// handleGetGuildTreasury returns a guild's treasury balance, shared inventory
+// and ledger (newest first).
+//
+// GET /api/game/guilds/{id}/treasury
+func (s *Service) handleGetGuildTreasury(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !isValidPathID(id) {
+		s.writeError(w, "invalid entity ID", http.StatusBadRequest)
+		return
+	}
+
+	entity, err := s.graph.GetGuild(r.Context(), domain.GuildID(id))
+	if err != nil {
+		if isBucketNotFound(err) || isKeyNotFound(err) {
+			http.NotFound(w, r)
+			return
+		}
+		s.writeError(w, "failed to retrieve guild", http.StatusInternalServerError)
+		s.logger.Error("Failed to get guild treasury", "id", id, "error", err)
+		return
+	}
+
+	guild := domain.GuildFromEntityState(entity)
+	if guild == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	resp := GuildTreasuryResponse{
+		GuildID:      string(guild.ID),
+		Balance:      guild.Treasury,
+		SharedTools:  append([]string{}, guild.SharedTools...),
+		Transactions: make([]domain.TreasuryTransaction, 0, len(guild.TreasuryLedger)),
+	}
+	for i := len(guild.TreasuryLedger) - 1; i >= 0; i-- {
+		resp.Transactions = append(resp.Transactions, guild.TreasuryLedger[i])
+	}
+	s.writeJSON(w, resp)
+}
+
+// handleGuildPurchase spends treasury XP on a permanent tool for the guild's
+// shared inventory.
+//
+// POST /api/game/guilds/{id}/treasury/purchase
+func (s *Service) handleGuildPurchase(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !isValidPathID(id) {
+		s.writeError(w, "invalid entity ID", http.StatusBadRequest)
+		return
+	}
+	store := s.getStore()
+	if store == nil {
+		s.writeError(w, "store service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	var req GuildPurchaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.OfficerID == "" || req.ItemID == "" {
+		s.writeError(w, "officer_id and item_id are required", http.StatusBadRequest)
+		return
+	}
+	if !isValidPathID(req.ItemID) {
+		s.writeError(w, "invalid item_id", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := store.BuyForGuild(r.Context(), domain.GuildID(id),
+		domain.AgentID(domain.ExtractInstance(req.OfficerID)), req.ItemID)
+	if err != nil {
+		s.writeTreasuryError(w, err)
+		return
+	}
+	s.writeJSON(w, tx)
+}
+
+// handleGuildSponsor spends treasury XP on a consumable for a guild member.
+//
+// POST /api/game/guilds/{id}/treasury/sponsor
+func (s *Service) handleGuildSponsor(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !isValidPathID(id) {
+		s.writeError(w, "invalid entity ID", http.StatusBadRequest)
+		return
+	}
+	store := s.getStore()
+	if store == nil {
+		s.writeError(w, "store service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	var req GuildSponsorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.OfficerID == "" || req.MemberID == "" || req.ItemID == "" {
+		s.writeError(w, "officer_id, member_id and item_id are required", http.StatusBadRequest)
+		return
+	}
+	if !isValidPathID(req.ItemID) {
+		s.writeError(w, "invalid item_id", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := store.SponsorConsumable(r.Context(), domain.GuildID(id),
+		domain.AgentID(domain.ExtractInstance(req.OfficerID)),
+		domain.AgentID(domain.ExtractInstance(req.MemberID)), req.ItemID)
+	if err != nil {
+		s.writeTreasuryError(w, err)
+		return
+	}
+	s.writeJSON(w, tx)
+}
+
+// writeTreasuryError maps treasury spending errors to HTTP status codes.
+func (s *Service) writeTreasuryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, agentstore.ErrNotGuildMember), errors.Is(err, agentstore.ErrNotGuildOfficer):
+		s.writeError(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, domain.ErrInsufficientTreasury), errors.Is(err, agentstore.ErrAlreadyShared):
+		s.writeError(w, err.Error(), http.StatusConflict)
+	case isBucketNotFound(err) || isKeyNotFound(err):
+		s.writeError(w, "guild not found", http.StatusNotFound)
+	default:
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+	}
+}
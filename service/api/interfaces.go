@@ -5,6 +5,8 @@ import (
 
 	"github.com/c360studio/semdragons/domain"
 	"github.com/c360studio/semdragons/processor/agentstore"
+	"github.com/c360studio/semdragons/processor/guildformation"
+	"github.com/c360studio/semdragons/processor/partycoord"
 	"github.com/c360studio/semstreams/graph"
 	"github.com/c360studio/semstreams/model"
 )
@@ -23,8 +25,13 @@ type GraphQuerier interface {
 	ListAgentsByPrefix(ctx context.Context, limit int) ([]graph.EntityState, error)
 	ListPeerReviewsByPrefix(ctx context.Context, limit int) ([]graph.EntityState, error)
 	ListEntitiesByType(ctx context.Context, entityType string, limit int) ([]graph.EntityState, error)
+	GetBlueprint(ctx context.Context, blueprintID string) (*graph.EntityState, error)
+	GetBlueprintWithRevision(ctx context.Context, blueprintID string) (*graph.EntityState, uint64, error)
+	ListBlueprintsByPrefix(ctx context.Context, limit int) ([]graph.EntityState, error)
 	EmitEntity(ctx context.Context, entity graph.Graphable, eventType string) error
 	EmitEntityUpdate(ctx context.Context, entity graph.Graphable, eventType string) error
+	EmitEntityCAS(ctx context.Context, entity graph.Graphable, eventType string, revision uint64) error
+	UpdateGuild(ctx context.Context, guildID domain.GuildID, eventType string, mutate func(*domain.Guild) error) (*domain.Guild, error)
 }
 
 // WorldStateProvider abstracts WorldStateAggregator for testing.
@@ -54,6 +61,11 @@ type DMSessionReader interface {
 	GetSession(ctx context.Context, sessionID string) (*DMChatSession, error)
 }
 
+// PartyBoardReader abstracts party blackboard KV reads for handler testing.
+type PartyBoardReader interface {
+	GetBlackboard(ctx context.Context, partyID domain.PartyID) (*partycoord.Blackboard, error)
+}
+
 // StoreProvider abstracts agentstore.Component for handler testing.
 // The concrete *agentstore.Component satisfies this interface.
 type StoreProvider interface {
@@ -67,4 +79,20 @@ type StoreProvider interface {
 	UseConsumable(ctx context.Context, agentID domain.AgentID,
 		consumableID string, questID *domain.QuestID) error
 	GetActiveEffects(agentID domain.AgentID) []agentstore.ActiveEffect
+	EconomyReport() agentstore.EconomyReport
+	BuyForGuild(ctx context.Context, guildID domain.GuildID, officerID domain.AgentID,
+		itemID string) (*domain.TreasuryTransaction, error)
+	SponsorConsumable(ctx context.Context, guildID domain.GuildID, officerID, memberID domain.AgentID,
+		itemID string) (*domain.TreasuryTransaction, error)
+}
+
+// GovernanceProvider abstracts guildformation.Component's proposal and voting
+// operations for handler testing.
+// The concrete *guildformation.Component satisfies this interface.
+type GovernanceProvider interface {
+	ListProposals(guildID domain.GuildID) []domain.GuildProposal
+	OpenProposal(ctx context.Context, guildID domain.GuildID,
+		params guildformation.OpenProposalParams) (*domain.GuildProposal, error)
+	CastVote(ctx context.Context, guildID domain.GuildID, proposalID string,
+		voterID domain.AgentID, approve bool, reason string) (*domain.GuildProposal, error)
 }
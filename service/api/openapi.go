@@ -7,7 +7,9 @@ import (
 	"github.com/c360studio/semdragons/processor/agentprogression"
 	"github.com/c360studio/semdragons/processor/agentstore"
 	"github.com/c360studio/semdragons/processor/bossbattle"
+	"github.com/c360studio/semdragons/processor/executor"
 	"github.com/c360studio/semdragons/processor/partycoord"
+	"github.com/c360studio/semdragons/processor/questdagexec"
 	"github.com/c360studio/semdragons/processor/tokenbudget"
 	"github.com/c360studio/semdragons/service/agentsheet"
 	"github.com/c360studio/semstreams/agentic"
@@ -18,6 +20,13 @@ func init() {
 	service.RegisterOpenAPISpec("game", semdragonsOpenAPISpec())
 }
 
+// secretNameParam is reused across tool secret endpoints.
+var secretNameParam = service.ParameterSpec{
+	Name: "name", In: "path", Required: true,
+	Description: "Secret name (letters, digits, '_' or '-')",
+	Schema:      service.Schema{Type: "string"},
+}
+
 // questIDParam is reused across quest lifecycle endpoints.
 var questIDParam = service.ParameterSpec{
 	Name: "id", In: "path", Required: true,
@@ -229,7 +238,7 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 			"/quests/{id}/artifacts": {
 				GET: &service.OperationSpec{
 					Summary:     "Download quest artifacts",
-					Description: "Downloads all artifact files for a quest as a zip archive. Includes a manifest.json with quest metadata at the root of the archive.",
+					Description: "Downloads all artifact files for a quest as a zip archive. Includes a manifest.json with quest metadata and, when tests have been run, a test-report.json at the root of the archive.",
 					Tags:        []string{"Quest Artifacts"},
 					Parameters:  []service.ParameterSpec{questIDParam},
 					Responses: map[string]service.ResponseSpec{
@@ -251,6 +260,19 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 					},
 				},
 			},
+			"/quests/{id}/artifacts/test-report": {
+				GET: &service.OperationSpec{
+					Summary:     "Get quest test report",
+					Description: "Returns the latest structured test report for a quest's workspace: framework, passed/failed/skipped counts and each failure's name, message and file:line. Written by the run_tests tool and the boss battle's automated test run.",
+					Tags:        []string{"Quest Artifacts"},
+					Parameters:  []service.ParameterSpec{questIDParam},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Test report", ContentType: "application/json"},
+						"404": {Description: "No tests have been run for the quest"},
+						"503": {Description: "Artifact storage not available"},
+					},
+				},
+			},
 			"/quests/{id}/artifacts/{path}": {
 				GET: &service.OperationSpec{
 					Summary:     "Get single artifact file",
@@ -267,6 +289,22 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 					},
 				},
 			},
+			"/quests/{id}/dag": {
+				GET: &service.OperationSpec{
+					Summary:     "Render quest DAG",
+					Description: "Renders a party quest's execution DAG with live node states, assignees, retries, durations, token spend and the critical path.",
+					Tags:        []string{"Quests"},
+					Parameters: []service.ParameterSpec{
+						questIDParam,
+						{Name: "format", In: "query", Description: "Output format: json (default), mermaid or dot", Schema: service.Schema{Type: "string"}},
+					},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Rendered DAG", ContentType: "application/json", SchemaRef: "#/components/schemas/DAGView"},
+						"400": {Description: "Invalid quest ID or unknown format"},
+						"404": {Description: "Quest not found or has no DAG"},
+					},
+				},
+			},
 
 			// ── Agents ───────────────────────────────────────────
 			"/agents": {
@@ -318,6 +356,51 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 					},
 				},
 			},
+			"/agents/{id}/memories": {
+				GET: &service.OperationSpec{
+					Summary:     "List agent memories",
+					Description: "Returns the agent's long-term quest memories, newest first. The most relevant are injected into prompts for new quests.",
+					Tags:        []string{"Agents"},
+					Parameters:  []service.ParameterSpec{agentIDParam},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Quest memories", ContentType: "application/json", SchemaRef: "#/components/schemas/QuestMemory", IsArray: true},
+						"404": {Description: "Agent not found"},
+					},
+				},
+			},
+			"/agents/{id}/memories/prune": {
+				POST: &service.OperationSpec{
+					Summary:     "Prune agent memories",
+					Description: "Removes memories older than a timestamp, with a given outcome, or beyond the N most recent.",
+					Tags:        []string{"Agents"},
+					Parameters:  []service.ParameterSpec{agentIDParam},
+					RequestBody: &service.RequestBodySpec{
+						Description: "Prune criteria",
+						SchemaRef:   "#/components/schemas/PruneMemoriesRequest",
+						Required:    true,
+					},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Memories pruned", ContentType: "application/json", SchemaRef: "#/components/schemas/PruneMemoriesResponse"},
+						"400": {Description: "Invalid or missing prune criteria"},
+						"404": {Description: "Agent not found"},
+					},
+				},
+			},
+			"/agents/{id}/memories/{memoryId}": {
+				DELETE: &service.OperationSpec{
+					Summary:     "Delete agent memory",
+					Description: "Removes a single quest memory from the agent.",
+					Tags:        []string{"Agents"},
+					Parameters: []service.ParameterSpec{
+						agentIDParam,
+						{Name: "memoryId", In: "path", Required: true, Description: "Memory ID", Schema: service.Schema{Type: "string"}},
+					},
+					Responses: map[string]service.ResponseSpec{
+						"204": {Description: "Memory deleted"},
+						"404": {Description: "Agent or memory not found"},
+					},
+				},
+			},
 			"/agents/{id}/reviews": {
 				GET: &service.OperationSpec{
 					Summary:     "List agent reviews",
@@ -386,6 +469,17 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 					},
 				},
 			},
+			"/store/economy": {
+				GET: &service.OperationSpec{
+					Summary:     "Store economy report",
+					Description: "Returns XP supplied to agents versus XP spent in the store, bucketed over time, with current list prices and recent price changes.",
+					Tags:        []string{"Store"},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Economy report", ContentType: "application/json", SchemaRef: "#/components/schemas/EconomyReport"},
+						"503": {Description: "Store component unavailable"},
+					},
+				},
+			},
 			"/store/{id}": {
 				GET: &service.OperationSpec{
 					Summary:     "Get store item",
@@ -471,6 +565,21 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 					},
 				},
 			},
+			"/parties/{id}/blackboard": {
+				GET: &service.OperationSpec{
+					Summary:     "Get party blackboard",
+					Description: "Returns the party's shared notes and the direct messages its members exchanged, with delivery timestamps.",
+					Tags:        []string{"Parties"},
+					Parameters: []service.ParameterSpec{
+						{Name: "id", In: "path", Required: true, Description: "Party ID", Schema: service.Schema{Type: "string"}},
+					},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Party blackboard", ContentType: "application/json", SchemaRef: "#/components/schemas/Blackboard"},
+						"404": {Description: "Party not found"},
+						"503": {Description: "Party blackboard unavailable"},
+					},
+				},
+			},
 
 			// ── Guilds ───────────────────────────────────────────
 			"/guilds": {
@@ -497,6 +606,204 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 					},
 				},
 			},
+			"/guilds/{id}/treasury": {
+				GET: &service.OperationSpec{
+					Summary:     "Get guild treasury",
+					Description: "Returns the guild's treasury balance, shared tool inventory, and transaction ledger (newest first).",
+					Tags:        []string{"Guilds"},
+					Parameters: []service.ParameterSpec{
+						{Name: "id", In: "path", Required: true, Description: "Guild ID", Schema: service.Schema{Type: "string"}},
+					},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Treasury balance and ledger", ContentType: "application/json", SchemaRef: "#/components/schemas/GuildTreasuryResponse"},
+						"404": {Description: "Guild not found"},
+					},
+				},
+			},
+			"/guilds/{id}/treasury/purchase": {
+				POST: &service.OperationSpec{
+					Summary:     "Buy tool for guild",
+					Description: "An officer or the guildmaster spends treasury XP on a permanent tool for the guild's shared inventory. Every member can use shared tools.",
+					Tags:        []string{"Guilds"},
+					Parameters: []service.ParameterSpec{
+						{Name: "id", In: "path", Required: true, Description: "Guild ID", Schema: service.Schema{Type: "string"}},
+					},
+					RequestBody: &service.RequestBodySpec{
+						Description: "Spending officer and tool",
+						SchemaRef:   "#/components/schemas/GuildPurchaseRequest",
+						Required:    true,
+					},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Recorded treasury transaction", ContentType: "application/json", SchemaRef: "#/components/schemas/TreasuryTransaction"},
+						"400": {Description: "Missing fields or item not a permanent tool"},
+						"403": {Description: "Spender is not an officer of the guild"},
+						"409": {Description: "Insufficient treasury or tool already shared"},
+						"503": {Description: "Store component unavailable"},
+					},
+				},
+			},
+			"/guilds/{id}/treasury/sponsor": {
+				POST: &service.OperationSpec{
+					Summary:     "Sponsor consumable",
+					Description: "An officer or the guildmaster spends treasury XP on a consumable granted to a guild member.",
+					Tags:        []string{"Guilds"},
+					Parameters: []service.ParameterSpec{
+						{Name: "id", In: "path", Required: true, Description: "Guild ID", Schema: service.Schema{Type: "string"}},
+					},
+					RequestBody: &service.RequestBodySpec{
+						Description: "Spending officer, member, and consumable",
+						SchemaRef:   "#/components/schemas/GuildSponsorRequest",
+						Required:    true,
+					},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Recorded treasury transaction", ContentType: "application/json", SchemaRef: "#/components/schemas/TreasuryTransaction"},
+						"400": {Description: "Missing fields or item not a consumable"},
+						"403": {Description: "Spender is not an officer, or beneficiary is not a member"},
+						"409": {Description: "Insufficient treasury"},
+						"503": {Description: "Store component unavailable"},
+					},
+				},
+			},
+			"/guilds/{id}/proposals": {
+				GET: &service.OperationSpec{
+					Summary:     "List guild proposals",
+					Description: "Returns the guild's governance proposals with their votes, newest first.",
+					Tags:        []string{"Guilds"},
+					Parameters: []service.ParameterSpec{
+						{Name: "id", In: "path", Required: true, Description: "Guild ID", Schema: service.Schema{Type: "string"}},
+					},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Guild proposals", ContentType: "application/json", SchemaRef: "#/components/schemas/GuildProposal", IsArray: true},
+						"503": {Description: "Guild formation component unavailable"},
+					},
+				},
+				POST: &service.OperationSpec{
+					Summary:     "Open guild proposal",
+					Description: "A member proposes to accept an applicant, promote or demote a member, or disband the guild. Votes are weighted by guild rank; the proposer's approval is cast automatically. Passed proposals execute immediately.",
+					Tags:        []string{"Guilds"},
+					Parameters: []service.ParameterSpec{
+						{Name: "id", In: "path", Required: true, Description: "Guild ID", Schema: service.Schema{Type: "string"}},
+					},
+					RequestBody: &service.RequestBodySpec{
+						Description: "Proposal parameters",
+						SchemaRef:   "#/components/schemas/OpenProposalRequest",
+						Required:    true,
+					},
+					Responses: map[string]service.ResponseSpec{
+						"201": {Description: "Proposal opened", ContentType: "application/json", SchemaRef: "#/components/schemas/GuildProposal"},
+						"400": {Description: "Invalid proposal"},
+						"403": {Description: "Proposer is not a guild member"},
+						"409": {Description: "An open proposal already covers this decision"},
+						"503": {Description: "Guild formation component unavailable"},
+					},
+				},
+			},
+			"/guilds/{id}/proposals/{proposalId}/votes": {
+				POST: &service.OperationSpec{
+					Summary:     "Vote on guild proposal",
+					Description: "Casts a member's rank-weighted vote. The proposal settles as soon as the outcome is certain, or at its deadline.",
+					Tags:        []string{"Guilds"},
+					Parameters: []service.ParameterSpec{
+						{Name: "id", In: "path", Required: true, Description: "Guild ID", Schema: service.Schema{Type: "string"}},
+						{Name: "proposalId", In: "path", Required: true, Description: "Proposal ID", Schema: service.Schema{Type: "string"}},
+					},
+					RequestBody: &service.RequestBodySpec{
+						Description: "Vote",
+						SchemaRef:   "#/components/schemas/CastVoteRequest",
+						Required:    true,
+					},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Proposal after the vote", ContentType: "application/json", SchemaRef: "#/components/schemas/GuildProposal"},
+						"403": {Description: "Voter is not a guild member"},
+						"404": {Description: "Proposal not found"},
+						"409": {Description: "Proposal closed or already voted"},
+						"503": {Description: "Guild formation component unavailable"},
+					},
+				},
+			},
+			"/guilds/{id}/lessons": {
+				GET: &service.OperationSpec{
+					Summary:     "List guild lessons",
+					Description: "Returns the guild's curated lessons ranked by severity, reinforcement, recency, member votes and later quest outcomes, best first. Retired lessons are omitted unless include_retired=true.",
+					Tags:        []string{"Guilds"},
+					Parameters: []service.ParameterSpec{
+						{Name: "id", In: "path", Required: true, Description: "Guild ID", Schema: service.Schema{Type: "string"}},
+						{Name: "skill", In: "query", Description: "Only lessons for this skill", Schema: service.Schema{Type: "string"}},
+						{Name: "include_retired", In: "query", Description: "Include retired lessons", Schema: service.Schema{Type: "boolean"}},
+					},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Ranked guild lessons", ContentType: "application/json", SchemaRef: "#/components/schemas/RankedLesson", IsArray: true},
+						"404": {Description: "Guild not found"},
+					},
+				},
+			},
+			"/guilds/{id}/lessons/{lessonId}/votes": {
+				POST: &service.OperationSpec{
+					Summary:     "Vote on guild lesson",
+					Description: "A guild member up- or down-votes a lesson, once per lesson. Votes adjust the lesson's ranking; enough net downvotes retire it.",
+					Tags:        []string{"Guilds"},
+					Parameters: []service.ParameterSpec{
+						{Name: "id", In: "path", Required: true, Description: "Guild ID", Schema: service.Schema{Type: "string"}},
+						{Name: "lessonId", In: "path", Required: true, Description: "Lesson ID", Schema: service.Schema{Type: "string"}},
+					},
+					RequestBody: &service.RequestBodySpec{
+						Description: "Vote",
+						SchemaRef:   "#/components/schemas/LessonVoteRequest",
+						Required:    true,
+					},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Lesson after the vote", ContentType: "application/json", SchemaRef: "#/components/schemas/RankedLesson"},
+						"400": {Description: "Missing voter_id"},
+						"403": {Description: "Voter is not a guild member"},
+						"404": {Description: "Guild or lesson not found"},
+						"409": {Description: "Voter already voted on this lesson"},
+					},
+				},
+			},
+
+			// ── Blueprints ───────────────────────────────────────
+			"/blueprints": {
+				GET: &service.OperationSpec{
+					Summary:     "List DAG blueprints",
+					Description: "Returns the built-in and stored DAG blueprints ranked by smoothed success rate, best first.",
+					Tags:        []string{"Blueprints"},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Ranked blueprints", ContentType: "application/json", SchemaRef: "#/components/schemas/RankedBlueprint", IsArray: true},
+					},
+				},
+			},
+			"/blueprints/{id}": {
+				GET: &service.OperationSpec{
+					Summary:     "Get DAG blueprint",
+					Description: "Returns a single blueprint with its node template, parameters and outcome counts.",
+					Tags:        []string{"Blueprints"},
+					Parameters: []service.ParameterSpec{
+						{Name: "id", In: "path", Required: true, Description: "Blueprint ID", Schema: service.Schema{Type: "string"}},
+					},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Blueprint", ContentType: "application/json", SchemaRef: "#/components/schemas/RankedBlueprint"},
+						"404": {Description: "Blueprint not found"},
+					},
+				},
+			},
+			"/blueprints/promote": {
+				POST: &service.OperationSpec{
+					Summary:     "Promote DAG to blueprint",
+					Description: "Saves the DAG of a completed party quest as a new blueprint so future leads can reuse it.",
+					Tags:        []string{"Blueprints"},
+					RequestBody: &service.RequestBodySpec{
+						Description: "Source quest and blueprint metadata",
+						SchemaRef:   "#/components/schemas/PromoteBlueprintRequest",
+						Required:    true,
+					},
+					Responses: map[string]service.ResponseSpec{
+						"201": {Description: "Blueprint created", ContentType: "application/json", SchemaRef: "#/components/schemas/RankedBlueprint"},
+						"400": {Description: "Missing fields or invalid blueprint"},
+						"404": {Description: "Quest not found"},
+						"409": {Description: "Quest not completed, has no DAG, or blueprint ID already exists"},
+					},
+				},
+			},
 
 			// ── Peer Reviews ─────────────────────────────────────
 			"/reviews": {
@@ -625,6 +932,70 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 				},
 			},
 
+			// ── Tool Usage ──────────────────────────────────────
+			"/board/tools/usage": {
+				GET: &service.OperationSpec{
+					Summary:     "Get tool usage",
+					Description: "Returns per-tool call counts, error rate, average latency, bytes returned, calls refused by rate limits or quotas, and accumulated cost since the tool components started.",
+					Tags:        []string{"Board Control"},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Tool usage by tool name", ContentType: "application/json", SchemaRef: "#/components/schemas/ToolUsageStats", IsArray: true},
+					},
+				},
+			},
+			"/board/tools/search/health": {
+				GET: &service.OperationSpec{
+					Summary:     "Get search provider health",
+					Description: "Returns success and failure counts, last error and latency for each provider behind a multi web_search provider. Providers that keep failing are skipped until retry_at. Empty when web_search does not fan out.",
+					Tags:        []string{"Board Control"},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Health by provider", ContentType: "application/json", SchemaRef: "#/components/schemas/SearchProviderHealth", IsArray: true},
+					},
+				},
+			},
+
+			// ── Tool Secrets ────────────────────────────────────
+			"/board/secrets": {
+				GET: &service.OperationSpec{
+					Summary:     "List tool secrets",
+					Description: "Returns the name, description, and scope of every stored secret. Values are never returned.",
+					Tags:        []string{"Board Control"},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "List of secrets", ContentType: "application/json", SchemaRef: "#/components/schemas/SecretInfo", IsArray: true},
+						"503": {Description: "Secrets vault not configured"},
+					},
+				},
+			},
+			"/board/secrets/{name}": {
+				PUT: &service.OperationSpec{
+					Summary:     "Store a tool secret",
+					Description: "Creates or replaces an encrypted secret. Agents reference it as {{secret:name}} in http_request headers and bash env; the value is substituted at execution time and redacted from tool output.",
+					Tags:        []string{"Board Control"},
+					Parameters:  []service.ParameterSpec{secretNameParam},
+					RequestBody: &service.RequestBodySpec{
+						Description: "Secret value, description, and scope",
+						SchemaRef:   "#/components/schemas/PutSecretRequest",
+						Required:    true,
+					},
+					Responses: map[string]service.ResponseSpec{
+						"200": {Description: "Secret stored", ContentType: "application/json", SchemaRef: "#/components/schemas/SecretInfo"},
+						"400": {Description: "Invalid name or missing value"},
+						"503": {Description: "Secrets vault not configured"},
+					},
+				},
+				DELETE: &service.OperationSpec{
+					Summary:     "Delete a tool secret",
+					Description: "Removes a stored secret. Quests referencing it fail their tool calls afterwards.",
+					Tags:        []string{"Board Control"},
+					Parameters:  []service.ParameterSpec{secretNameParam},
+					Responses: map[string]service.ResponseSpec{
+						"204": {Description: "Secret deleted"},
+						"404": {Description: "Secret not found"},
+						"503": {Description: "Secrets vault not configured"},
+					},
+				},
+			},
+
 			// ── Model Registry ───────────────────────────────────
 			"/models": {
 				GET: &service.OperationSpec{
@@ -801,6 +1172,7 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 			{Name: "Battles", Description: "Boss battle (automated review) operations"},
 			{Name: "Parties", Description: "Party formation and management"},
 			{Name: "Guilds", Description: "Guild formation and management"},
+			{Name: "Blueprints", Description: "Reusable DAG blueprints for party quests"},
 			{Name: "DM", Description: "Dungeon Master interaction and chat sessions"},
 			{Name: "Store", Description: "Agent store, inventory, and consumable effects"},
 			{Name: "Observability", Description: "Trajectory and event tracing"},
@@ -815,6 +1187,7 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 			reflect.TypeOf(domain.Guild{}),
 			reflect.TypeOf(domain.GuildMember{}),
 			reflect.TypeOf(domain.Lesson{}),
+			reflect.TypeOf(domain.QuestMemory{}),
 			reflect.TypeOf(domain.PeerReview{}),
 			reflect.TypeOf(domain.ReviewSubmission{}),
 			reflect.TypeOf(domain.ReviewRatings{}),
@@ -831,6 +1204,9 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 			reflect.TypeOf(domain.ReviewResult{}),
 			reflect.TypeOf(partycoord.Party{}),
 			reflect.TypeOf(partycoord.PartyMember{}),
+			reflect.TypeOf(partycoord.Blackboard{}),
+			reflect.TypeOf(partycoord.BlackboardNote{}),
+			reflect.TypeOf(partycoord.PartyMessage{}),
 
 			// Store types
 			reflect.TypeOf(agentstore.StoreItem{}),
@@ -838,6 +1214,22 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 			reflect.TypeOf(agentstore.AgentInventory{}),
 			reflect.TypeOf(agentstore.ActiveEffect{}),
 			reflect.TypeOf(agentstore.ConsumableEffect{}),
+			reflect.TypeOf(agentstore.PriceQuote{}),
+			reflect.TypeOf(agentstore.PricePoint{}),
+			reflect.TypeOf(agentstore.EconomyBucket{}),
+			reflect.TypeOf(agentstore.EconomyReport{}),
+			reflect.TypeOf(domain.TreasuryTransaction{}),
+			reflect.TypeOf(GuildTreasuryResponse{}),
+			reflect.TypeOf(domain.GuildProposal{}),
+			reflect.TypeOf(domain.GuildVote{}),
+			reflect.TypeOf(RankedLesson{}),
+			reflect.TypeOf(RankedBlueprint{}),
+			reflect.TypeOf(questdagexec.Blueprint{}),
+			reflect.TypeOf(questdagexec.BlueprintParam{}),
+			reflect.TypeOf(questdagexec.QuestNode{}),
+			reflect.TypeOf(questdagexec.DAGView{}),
+			reflect.TypeOf(questdagexec.NodeView{}),
+			reflect.TypeOf(questdagexec.DAGEdge{}),
 
 			// Trajectory types
 			reflect.TypeOf(agentic.Trajectory{}),
@@ -866,6 +1258,7 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 			reflect.TypeOf(PurchaseResponse{}),
 			reflect.TypeOf(UseConsumableResponse{}),
 			reflect.TypeOf(BoardStatusResponse{}),
+			reflect.TypeOf(PruneMemoriesResponse{}),
 
 			// Model registry types
 			reflect.TypeOf(ModelResolveResponse{}),
@@ -876,6 +1269,14 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 			reflect.TypeOf(tokenbudget.TokenStats{}),
 			reflect.TypeOf(tokenbudget.UsageSnapshot{}),
 
+			// Tool usage types
+			reflect.TypeOf(executor.ToolUsageStats{}),
+			reflect.TypeOf(executor.SearchProviderHealth{}),
+
+			// Tool secret types
+			reflect.TypeOf(executor.SecretInfo{}),
+			reflect.TypeOf(executor.SecretScope{}),
+
 			// Settings types
 			reflect.TypeOf(SettingsResponse{}),
 			reflect.TypeOf(PlatformInfo{}),
@@ -905,12 +1306,20 @@ func semdragonsOpenAPISpec() *service.OpenAPISpec {
 			reflect.TypeOf(RecruitAgentRequest{}),
 			reflect.TypeOf(PurchaseItemRequest{}),
 			reflect.TypeOf(UseConsumableRequest{}),
+			reflect.TypeOf(PruneMemoriesRequest{}),
+			reflect.TypeOf(GuildPurchaseRequest{}),
+			reflect.TypeOf(GuildSponsorRequest{}),
+			reflect.TypeOf(OpenProposalRequest{}),
+			reflect.TypeOf(CastVoteRequest{}),
+			reflect.TypeOf(LessonVoteRequest{}),
+			reflect.TypeOf(PromoteBlueprintRequest{}),
 			reflect.TypeOf(CreateReviewRequest{}),
 			reflect.TypeOf(SubmitReviewRequest{}),
 			reflect.TypeOf(DMChatRequest{}),
 			reflect.TypeOf(DMChatContextRef{}),
 			reflect.TypeOf(DMChatHistoryItem{}),
 			reflect.TypeOf(SetTokenBudgetRequest{}),
+			reflect.TypeOf(PutSecretRequest{}),
 
 			// Settings request types
 			reflect.TypeOf(UpdateSettingsRequest{}),
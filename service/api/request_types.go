@@ -1,6 +1,10 @@
 package api
 
-import "github.com/c360studio/semdragons/domain"
+import (
+	"github.com/c360studio/semdragons/domain"
+	"github.com/c360studio/semdragons/processor/executor"
+	"github.com/c360studio/semdragons/processor/questdagexec"
+)
 
 // =============================================================================
 // REQUEST BODY TYPES — Named structs for OpenAPI schema generation
@@ -16,15 +20,17 @@ type CreateQuestRequest struct {
 
 // CreateQuestHints provides optional configuration hints when creating a quest.
 type CreateQuestHints struct {
-	SuggestedDifficulty *int     `json:"suggested_difficulty,omitempty" description:"Difficulty level 0-5"`
-	SuggestedSkills     []string `json:"suggested_skills,omitempty" description:"Skill tags to require"`
-	PreferGuild         *string  `json:"prefer_guild,omitempty" description:"Guild ID for priority routing"`
-	RequireHumanReview  bool     `json:"require_human_review" description:"Whether to require human review"`
-	ReviewLevel         *int     `json:"review_level,omitempty" description:"Review level 0-3"`
-	Budget              float64  `json:"budget" description:"Cost budget for the quest"`
-	Deadline            string   `json:"deadline,omitempty" description:"ISO 8601 deadline"`
-	PartyRequired       bool     `json:"party_required" description:"Whether the quest requires a party"`
-	MinPartySize        *int     `json:"min_party_size,omitempty" description:"Minimum party size (2-5)"`
+	SuggestedDifficulty *int              `json:"suggested_difficulty,omitempty" description:"Difficulty level 0-5"`
+	SuggestedSkills     []string          `json:"suggested_skills,omitempty" description:"Skill tags to require"`
+	PreferGuild         *string           `json:"prefer_guild,omitempty" description:"Guild ID for priority routing"`
+	RequireHumanReview  bool              `json:"require_human_review" description:"Whether to require human review"`
+	ReviewLevel         *int              `json:"review_level,omitempty" description:"Review level 0-3"`
+	Budget              float64           `json:"budget" description:"Cost budget for the quest"`
+	Deadline            string            `json:"deadline,omitempty" description:"ISO 8601 deadline"`
+	PartyRequired       bool              `json:"party_required" description:"Whether the quest requires a party"`
+	MinPartySize        *int              `json:"min_party_size,omitempty" description:"Minimum party size (2-5)"`
+	Blueprint           string            `json:"blueprint,omitempty" description:"DAG blueprint ID for the party lead to start from; implies party_required"`
+	BlueprintArgs       map[string]string `json:"blueprint_args,omitempty" description:"Blueprint parameter values"`
 }
 
 // CreateQuestChainRequest is the request body for POST /quests/chain.
@@ -77,6 +83,49 @@ type UseConsumableRequest struct {
 	QuestID      string `json:"quest_id,omitempty" description:"Quest to apply the effect to"`
 }
 
+// PruneMemoriesRequest is the request body for POST /agents/{id}/memories/prune.
+type PruneMemoriesRequest struct {
+	OlderThan  string `json:"older_than,omitempty" description:"Remove memories recorded before this RFC 3339 timestamp"`
+	Outcome    string `json:"outcome,omitempty" description:"Remove memories with this outcome: success or failure"`
+	KeepLatest int    `json:"keep_latest,omitempty" description:"Keep only the N most recent memories"`
+}
+
+// GuildPurchaseRequest is the request body for POST /guilds/{id}/treasury/purchase.
+type GuildPurchaseRequest struct {
+	OfficerID string `json:"officer_id" description:"Officer or guildmaster spending the treasury"`
+	ItemID    string `json:"item_id" description:"Permanent tool to add to the guild inventory"`
+}
+
+// GuildSponsorRequest is the request body for POST /guilds/{id}/treasury/sponsor.
+type GuildSponsorRequest struct {
+	OfficerID string `json:"officer_id" description:"Officer or guildmaster spending the treasury"`
+	MemberID  string `json:"member_id" description:"Guild member receiving the consumable"`
+	ItemID    string `json:"item_id" description:"Consumable to grant"`
+}
+
+// OpenProposalRequest is the request body for POST /guilds/{id}/proposals.
+type OpenProposalRequest struct {
+	ProposerID    string `json:"proposer_id" description:"Guild member opening the proposal"`
+	Kind          string `json:"kind" description:"Proposal kind: accept_applicant, promote, demote, or disband"`
+	TargetID      string `json:"target_id,omitempty" description:"Member to promote or demote"`
+	ApplicationID string `json:"application_id,omitempty" description:"Application to accept (accept_applicant only)"`
+	NewRank       string `json:"new_rank,omitempty" description:"Rank to promote or demote to"`
+	Reason        string `json:"reason,omitempty" description:"Why the proposal is being made"`
+}
+
+// CastVoteRequest is the request body for POST /guilds/{id}/proposals/{proposalId}/votes.
+type CastVoteRequest struct {
+	VoterID string `json:"voter_id" description:"Guild member casting the vote"`
+	Approve bool   `json:"approve" description:"true to approve, false to reject"`
+	Reason  string `json:"reason,omitempty" description:"Optional explanation for the vote"`
+}
+
+// LessonVoteRequest is the request body for POST /guilds/{id}/lessons/{lessonId}/votes.
+type LessonVoteRequest struct {
+	VoterID string `json:"voter_id" description:"Guild member casting the vote"`
+	Up      bool   `json:"up" description:"true to upvote, false to downvote"`
+}
+
 // CreateReviewRequest is the request body for POST /reviews.
 type CreateReviewRequest struct {
 	QuestID    string  `json:"quest_id" description:"Quest being reviewed"`
@@ -119,6 +168,13 @@ type SetTokenBudgetRequest struct {
 	GlobalHourlyLimit int64 `json:"global_hourly_limit" description:"New hourly token limit (0 = unlimited)"`
 }
 
+// PutSecretRequest is the request body for PUT /board/secrets/{name}.
+type PutSecretRequest struct {
+	Value       string               `json:"value" description:"Secret value; stored encrypted and never returned"`
+	Description string               `json:"description,omitempty" description:"What the secret is for"`
+	Scope       executor.SecretScope `json:"scope,omitempty" description:"Repos, guilds, minimum tier and quests allowed to use the secret; empty allows all"`
+}
+
 // =============================================================================
 // RESPONSE TYPES — Named structs for responses that use anonymous types in handlers
 // =============================================================================
@@ -170,6 +226,41 @@ type UseConsumableResponse struct {
 	Error         string `json:"error,omitempty" description:"Error message if failed"`
 }
 
+// PruneMemoriesResponse is the response body for POST /agents/{id}/memories/prune.
+type PruneMemoriesResponse struct {
+	Removed   int `json:"removed" description:"Number of memories removed"`
+	Remaining int `json:"remaining" description:"Number of memories left on the agent"`
+}
+
+// GuildTreasuryResponse is the response body for GET /guilds/{id}/treasury.
+type GuildTreasuryResponse struct {
+	GuildID      string                       `json:"guild_id" description:"Guild ID"`
+	Balance      int64                        `json:"balance" description:"Treasury balance in XP"`
+	SharedTools  []string                     `json:"shared_tools" description:"Tool IDs in the guild's shared inventory"`
+	Transactions []domain.TreasuryTransaction `json:"transactions" description:"Treasury ledger, newest first"`
+}
+
+// PromoteBlueprintRequest is the request body for POST /blueprints/promote.
+type PromoteBlueprintRequest struct {
+	QuestID     string   `json:"quest_id" description:"Completed party quest whose DAG becomes the blueprint"`
+	ID          string   `json:"id" description:"Blueprint ID (lowercase slug)"`
+	Name        string   `json:"name" description:"Human-readable blueprint name"`
+	Description string   `json:"description,omitempty" description:"When to use the blueprint"`
+	Classes     []string `json:"classes,omitempty" description:"Decomposability classes the blueprint suits; defaults to the quest's class"`
+}
+
+// RankedBlueprint is a DAG blueprint with its smoothed success rate.
+type RankedBlueprint struct {
+	Blueprint   questdagexec.Blueprint `json:"blueprint" description:"DAG blueprint"`
+	SuccessRate float64                `json:"success_rate" description:"Laplace-smoothed success rate used for ranking"`
+}
+
+// RankedLesson is a guild lesson with its current curation score.
+type RankedLesson struct {
+	Lesson domain.Lesson `json:"lesson" description:"Guild lesson"`
+	Score  float64       `json:"score" description:"Ranking score (severity x reinforcement x recency x trust); 0 when retired"`
+}
+
 // BoardStatusResponse is the response body for board control endpoints.
 type BoardStatusResponse struct {
 	Paused   bool    `json:"paused" description:"Whether the board is currently paused"`
@@ -20,6 +20,7 @@ import (
 	"github.com/c360studio/semdragons/processor/boardcontrol"
 	"github.com/c360studio/semdragons/processor/dmworldstate"
 	"github.com/c360studio/semdragons/processor/executor"
+	"github.com/c360studio/semdragons/processor/guildformation"
 	"github.com/c360studio/semdragons/processor/tokenbudget"
 	"github.com/c360studio/semstreams/natsclient"
 	"github.com/c360studio/semstreams/service"
@@ -47,11 +48,13 @@ type Service struct {
 	graph           GraphQuerier       // concrete type is *semdragons.GraphClient
 	world           WorldStateProvider // concrete type is *dmworldstate.WorldStateAggregator
 	store           StoreProvider      // concrete type is *agentstore.Component; nil if set directly (tests)
+	governance      GovernanceProvider // concrete type is *guildformation.Component; nil if set directly (tests)
 	componentDeps   *service.Dependencies // retained for lazy component resolution
 	models          ModelResolver      // concrete type is *model.Registry; nil if unavailable
 	nats            *natsclient.Client // direct NATS access for KV buckets outside graph
 	trajectories    TrajectoryQuerier  // trajectory KV lookups; nil before init
 	dmSessionReader DMSessionReader    // session reads (used by GET handler); nil before init
+	partyBoards     PartyBoardReader   // party blackboard KV reads; nil before init
 	board           *boardcontrol.Controller // board play/pause control; nil before init
 	tokenLedger     *tokenbudget.TokenLedger // token budget tracking; nil before init
 	boardConfig     *domain.BoardConfig      // board identity for bucket name, entity IDs
@@ -68,6 +71,10 @@ type Service struct {
 	// gracefully by falling back to the plain callLLM path.
 	dmTools *executor.ToolRegistry
 
+	// secrets is the encrypted tool-secrets vault, opened during Start when
+	// SEMDRAGONS_SECRETS_KEY is set. Nil disables the secrets endpoints.
+	secrets *executor.SecretVault
+
 	// DM session persistence — persists chat turns to NATS KV for server restart recovery.
 	dmSessions *dmSessionStore
 
@@ -149,6 +156,7 @@ func New(rawConfig json.RawMessage, deps *service.Dependencies) (service.Service
 		nats:            deps.NATSClient,
 		trajectories:    &natsTrajectoryQuerier{nats: deps.NATSClient},
 		dmSessionReader: sessions,
+		partyBoards:     &natsPartyBoardReader{nats: deps.NATSClient},
 		dmSessions:      sessions,
 		boardConfig:     boardConfig,
 		config:          cfg,
@@ -191,6 +199,27 @@ func resolveStoreComponent(deps *service.Dependencies, logger *slog.Logger) Stor
 	return sp
 }
 
+// getGovernance resolves the guildformation component from the registry on
+// every call, for the same restart reasons as getStore. Returns nil when the
+// component is unavailable; handlers respond 503.
+func (s *Service) getGovernance() GovernanceProvider {
+	if s.governance != nil {
+		return s.governance
+	}
+	if s.componentDeps == nil || s.componentDeps.ComponentRegistry == nil {
+		return nil
+	}
+	comp := s.componentDeps.ComponentRegistry.Component(guildformation.ComponentName)
+	if comp == nil {
+		return nil
+	}
+	gp, ok := comp.(GovernanceProvider)
+	if !ok {
+		return nil
+	}
+	return gp
+}
+
 // resolveModelRegistry retrieves the model registry from the config manager when
 // available, falling back to the default dev registry (local Ollama). This ensures
 // production deployments use provider endpoints defined in semdragons.json rather
@@ -220,6 +249,16 @@ func (s *Service) Start(ctx context.Context) error {
 		s.logger.Info("sandbox client initialized", "url", sandboxURL)
 	}
 
+	// Open the tool-secrets vault for the secrets endpoints.
+	if os.Getenv(executor.DefaultSecretsKeyEnv) != "" {
+		vault, vaultErr := executor.OpenSecretVault(ctx, s.nats, "")
+		if vaultErr != nil {
+			s.logger.Warn("secrets vault unavailable; secrets endpoints disabled", "error", vaultErr)
+		} else {
+			s.secrets = vault
+		}
+	}
+
 	// Initialize board control (play/pause).
 	bucket, err := boardcontrol.EnsureBucket(ctx, s.nats)
 	if err != nil {
@@ -325,7 +364,7 @@ func (s *Service) wireTokenLedgerToComponents() {
 	}
 
 	// Component names that should receive the token ledger.
-	names := []string{"questbridge", "bossbattle"}
+	names := []string{"questbridge", "bossbattle", "questtools", "executor"}
 	for _, name := range names {
 		comp := s.componentDeps.ComponentRegistry.Component(name)
 		if comp == nil {
@@ -388,9 +427,13 @@ func (s *Service) RegisterHTTPHandlers(prefix string, mux *http.ServeMux) {
 
 	// Quest artifacts
 	mux.HandleFunc("GET "+prefix+"quests/{id}/artifacts/list", cors(s.handleListQuestArtifacts))
+	mux.HandleFunc("GET "+prefix+"quests/{id}/artifacts/test-report", cors(s.handleGetQuestTestReport))
 	mux.HandleFunc("GET "+prefix+"quests/{id}/artifacts/{path...}", cors(s.handleGetQuestArtifactFile))
 	mux.HandleFunc("GET "+prefix+"quests/{id}/artifacts", cors(s.handleGetQuestArtifacts))
 
+	// Quest DAG visualization
+	mux.HandleFunc("GET "+prefix+"quests/{id}/dag", cors(s.handleGetQuestDAG))
+
 	// Agents
 	mux.HandleFunc("GET "+prefix+"agents", cors(s.handleListAgents))
 	mux.HandleFunc("GET "+prefix+"agents/{id}/inventory", cors(s.handleGetInventory))
@@ -398,6 +441,9 @@ func (s *Service) RegisterHTTPHandlers(prefix string, mux *http.ServeMux) {
 	mux.HandleFunc("GET "+prefix+"agents/{id}/effects", cors(s.handleGetEffects))
 	mux.HandleFunc("GET "+prefix+"agents/{id}", cors(s.handleGetAgent))
 	mux.HandleFunc("POST "+prefix+"agents/{id}/retire", cors(requireAuth(apiKey, s.handleRetireAgent)))
+	mux.HandleFunc("GET "+prefix+"agents/{id}/memories", cors(s.handleListAgentMemories))
+	mux.HandleFunc("POST "+prefix+"agents/{id}/memories/prune", cors(requireAuth(apiKey, s.handlePruneAgentMemories)))
+	mux.HandleFunc("DELETE "+prefix+"agents/{id}/memories/{memoryId}", cors(requireAuth(apiKey, s.handleDeleteAgentMemory)))
 	mux.HandleFunc("POST "+prefix+"agents", cors(requireAuth(apiKey, s.handleRecruitAgent)))
 
 	// Battles
@@ -407,10 +453,24 @@ func (s *Service) RegisterHTTPHandlers(prefix string, mux *http.ServeMux) {
 	// Parties
 	mux.HandleFunc("GET "+prefix+"parties", cors(s.handleListParties))
 	mux.HandleFunc("GET "+prefix+"parties/{id}", cors(s.handleGetParty))
+	mux.HandleFunc("GET "+prefix+"parties/{id}/blackboard", cors(s.handleGetPartyBlackboard))
 
 	// Guilds
 	mux.HandleFunc("GET "+prefix+"guilds", cors(s.handleListGuilds))
 	mux.HandleFunc("GET "+prefix+"guilds/{id}", cors(s.handleGetGuild))
+	mux.HandleFunc("GET "+prefix+"guilds/{id}/treasury", cors(s.handleGetGuildTreasury))
+	mux.HandleFunc("POST "+prefix+"guilds/{id}/treasury/purchase", cors(requireAuth(apiKey, s.handleGuildPurchase)))
+	mux.HandleFunc("POST "+prefix+"guilds/{id}/treasury/sponsor", cors(requireAuth(apiKey, s.handleGuildSponsor)))
+	mux.HandleFunc("GET "+prefix+"guilds/{id}/proposals", cors(s.handleListGuildProposals))
+	mux.HandleFunc("POST "+prefix+"guilds/{id}/proposals", cors(requireAuth(apiKey, s.handleOpenGuildProposal)))
+	mux.HandleFunc("POST "+prefix+"guilds/{id}/proposals/{proposalId}/votes", cors(requireAuth(apiKey, s.handleCastGuildVote)))
+	mux.HandleFunc("GET "+prefix+"guilds/{id}/lessons", cors(s.handleListGuildLessons))
+	mux.HandleFunc("POST "+prefix+"guilds/{id}/lessons/{lessonId}/votes", cors(requireAuth(apiKey, s.handleVoteGuildLesson)))
+
+	// Blueprints
+	mux.HandleFunc("GET "+prefix+"blueprints", cors(s.handleListBlueprints))
+	mux.HandleFunc("GET "+prefix+"blueprints/{id}", cors(s.handleGetBlueprint))
+	mux.HandleFunc("POST "+prefix+"blueprints/promote", cors(requireAuth(apiKey, s.handlePromoteBlueprint)))
 
 	// Trajectories
 	mux.HandleFunc("GET "+prefix+"trajectories/{id}", cors(s.handleGetTrajectory))
@@ -430,6 +490,7 @@ func (s *Service) RegisterHTTPHandlers(prefix string, mux *http.ServeMux) {
 
 	// Store
 	mux.HandleFunc("GET "+prefix+"store", cors(s.handleListStore))
+	mux.HandleFunc("GET "+prefix+"store/economy", cors(s.handleStoreEconomy))
 	mux.HandleFunc("GET "+prefix+"store/{id}", cors(s.handleGetStoreItem))
 	mux.HandleFunc("POST "+prefix+"store/purchase", cors(requireAuth(apiKey, s.handlePurchase)))
 
@@ -442,6 +503,15 @@ func (s *Service) RegisterHTTPHandlers(prefix string, mux *http.ServeMux) {
 	mux.HandleFunc("GET "+prefix+"board/tokens", cors(s.handleTokenStats))
 	mux.HandleFunc("POST "+prefix+"board/tokens/budget", cors(requireAuth(apiKey, s.handleSetTokenBudget)))
 
+	// Tool usage
+	mux.HandleFunc("GET "+prefix+"board/tools/usage", cors(s.handleToolUsage))
+	mux.HandleFunc("GET "+prefix+"board/tools/search/health", cors(s.handleSearchHealth))
+
+	// Tool secrets — values are write-only
+	mux.HandleFunc("GET "+prefix+"board/secrets", cors(requireAuth(apiKey, s.handleListSecrets)))
+	mux.HandleFunc("PUT "+prefix+"board/secrets/{name}", cors(requireAuth(apiKey, s.handlePutSecret)))
+	mux.HandleFunc("DELETE "+prefix+"board/secrets/{name}", cors(requireAuth(apiKey, s.handleDeleteSecret)))
+
 	// Settings
 	mux.HandleFunc("GET "+prefix+"settings", cors(s.handleGetSettings))
 	mux.HandleFunc("GET "+prefix+"settings/health", cors(s.handleSettingsHealth))